@@ -33,6 +33,18 @@ For GoSublime, follow the steps described here:
 
 For other editors, you probably know what to do.
 
+The -local flag puts the imports of packages whose paths begin with one
+of its comma-separated prefixes in a group after those of third-party
+packages. Semicolons separate further groups, which follow in order;
+each import belongs to the group of its longest matching prefix. For
+example, with
+
+	$ goimports -local 'example.com;example.com/mod' -w file.go
+
+the imports of file.go are grouped into standard library packages,
+third-party packages, the rest of example.com, and finally the packages
+of module example.com/mod.
+
 To exclude directories in your $GOPATH from being scanned for Go
 files, goimports respects a configuration file at
 $GOPATH/src/.goimportsignore which may contain blank lines, comment
@@ -73,8 +73,9 @@ func TestEndToEnd(t *testing.T) {
 			t.Errorf("%s is not a Go file", name)
 			continue
 		}
-		if strings.HasPrefix(name, "tag_") || strings.HasPrefix(name, "vary_") {
-			// This file is used for tag processing in TestTags or TestConstValueChange, below.
+		if strings.HasPrefix(name, "tag_") || strings.HasPrefix(name, "vary_") || strings.HasPrefix(name, "parse_") {
+			// This file is used for tag processing in TestTags, TestConstValueChange,
+			// or TestParse, below.
 			continue
 		}
 		t.Run(name, func(t *testing.T) {
@@ -139,6 +140,26 @@ func TestTags(t *testing.T) {
 	}
 }
 
+// TestParse verifies that the -text flag generates Parse functions and
+// text methods that are the inverse of the String method.
+func TestParse(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	stringer := stringerPath(t)
+	dir := t.TempDir()
+	source := filepath.Join(dir, "parse_color.go")
+	if err := copy(source, filepath.Join("testdata", "parse_color.go")); err != nil {
+		t.Fatal(err)
+	}
+	stringSource := filepath.Join(dir, "color_string.go")
+	if err := run(t, stringer, "-type", "Color", "-text", "-output", stringSource, source); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(t, "go", "run", stringSource, source); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestConstValueChange verifies that if a constant value changes and
 // the stringer code is not regenerated, we'll get a compiler error.
 func TestConstValueChange(t *testing.T) {
@@ -70,6 +70,18 @@
 //	PillAspirin // Aspirin
 //
 // to suppress it in the output.
+//
+// The -parse flag tells stringer to also generate the inverse of the String method,
+//
+//	func ParsePill(s string) (Pill, error)
+//
+// which returns the value whose String method returns s. It also accepts the
+// "Pill(42)" form that String uses for values that have no constant.
+// The function is unexported if the type is.
+//
+// The -text flag implies -parse, and tells stringer to also generate
+// MarshalText and UnmarshalText methods, so that the values of the type are
+// encoded by packages such as encoding/json using their names.
 package main // import "golang.org/x/tools/cmd/stringer"
 
 import (
@@ -86,6 +98,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -96,6 +110,8 @@ var (
 	trimprefix  = flag.String("trimprefix", "", "trim the `prefix` from the generated constant names")
 	linecomment = flag.Bool("linecomment", false, "use line comment text as printed text when present")
 	buildTags   = flag.String("tags", "", "comma-separated list of build tags to apply")
+	parse       = flag.Bool("parse", false, "also generate a Parse function for each type")
+	text        = flag.Bool("text", false, "also generate MarshalText and UnmarshalText methods; implies -parse")
 )
 
 // Usage is a replacement usage function for the flags package.
@@ -167,7 +183,9 @@ func main() {
 	})
 	for _, pkg := range pkgs {
 		g := Generator{
-			pkg: pkg,
+			pkg:   pkg,
+			parse: *parse || *text,
+			text:  *text,
 		}
 
 		// Print the header and package clause.
@@ -175,7 +193,14 @@ func main() {
 		g.Printf("\n")
 		g.Printf("package %s", g.pkg.name)
 		g.Printf("\n")
-		g.Printf("import \"strconv\"\n") // Used by all methods.
+		if g.parse {
+			g.Printf("import (\n")
+			g.Printf("\t\"fmt\"\n") // Used by the Parse functions.
+			g.Printf("\t\"strconv\"\n")
+			g.Printf(")\n")
+		} else {
+			g.Printf("import \"strconv\"\n") // Used by all methods.
+		}
 
 		// Run generate for types that can be found. Keep the rest for the remainingTypes iteration.
 		var foundTypes, remainingTypes []string
@@ -244,6 +269,9 @@ type Generator struct {
 	buf bytes.Buffer // Accumulated output.
 	pkg *Package     // Package we are scanning.
 
+	parse bool // Also generate a Parse function.
+	text  bool // Also generate MarshalText and UnmarshalText methods.
+
 	logf func(format string, args ...any) // test logging hook; nil when not testing
 }
 
@@ -375,6 +403,12 @@ func (g *Generator) generate(typeName string, values []Value) {
 	default:
 		g.buildMap(runs, typeName)
 	}
+	if g.parse {
+		g.buildParse(runs, typeName)
+	}
+	if g.text {
+		g.Printf(textMethods, typeName, parseFuncName(typeName))
+	}
 }
 
 // splitIntoRuns breaks the values into runs of contiguous sequences.
@@ -734,3 +768,80 @@ const stringMap = `func (i %[1]s) String() string {
 	return "%[1]s(" + strconv.FormatInt(int64(i), 10) + ")"
 }
 `
+
+// parseFuncName returns the name of the Parse function for the named type.
+// It is exported only if the type is.
+func parseFuncName(typeName string) string {
+	if token.IsExported(typeName) {
+		return "Parse" + typeName
+	}
+	r, size := utf8.DecodeRuneInString(typeName)
+	return "parse" + string(unicode.ToUpper(r)) + typeName[size:]
+}
+
+// buildParse generates the Parse function, the inverse of the String method.
+func (g *Generator) buildParse(runs [][]Value, typeName string) {
+	g.Printf("\nvar _%s_value = map[string]%s{\n", typeName, typeName)
+	seen := make(map[string]bool)
+	for _, values := range runs {
+		for _, value := range values {
+			// Distinct values may have the same line comment.
+			// Like the map literal, prefer the first.
+			if seen[value.name] {
+				continue
+			}
+			seen[value.name] = true
+			g.Printf("\t%q: %s,\n", value.name, &value)
+		}
+	}
+	g.Printf("}\n\n")
+	parseInt := "strconv.ParseInt(s[len(prefix):n], 10, 64)"
+	intType := "int64"
+	if !runs[0][0].signed {
+		parseInt = "strconv.ParseUint(s[len(prefix):n], 10, 64)"
+		intType = "uint64"
+	}
+	g.Printf(parseFunc, typeName, parseFuncName(typeName), parseInt, intType)
+}
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: Parse function name
+//	[3]: call to parse the integer form
+//	[4]: type of the integer form (int64 or uint64)
+const parseFunc = `// %[2]s returns the %[1]s whose String method returns s.
+func %[2]s(s string) (%[1]s, error) {
+	if i, ok := _%[1]s_value[s]; ok {
+		return i, nil
+	}
+	const prefix = "%[1]s("
+	if n := len(s) - 1; n > len(prefix) && s[:len(prefix)] == prefix && s[n] == ')' {
+		if v, err := %[3]s; err == nil && %[4]s(%[1]s(v)) == v {
+			return %[1]s(v), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid %[1]s: %%q", s)
+}
+`
+
+// Arguments to format are:
+//
+//	[1]: type name
+//	[2]: Parse function name
+const textMethods = `
+// MarshalText implements [encoding.TextMarshaler] using the String method.
+func (i %[1]s) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler] using %[2]s.
+func (i *%[1]s) UnmarshalText(text []byte) error {
+	v, err := %[2]s(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
+`
@@ -0,0 +1,64 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Parse functions and text methods, generated with -text.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Color uint8
+
+const (
+	Red Color = iota + 1
+	Green
+	Blue
+	Crimson       = Red
+	White   Color = 10
+)
+
+func main() {
+	ck(Red, "Red")
+	ck(Green, "Green")
+	ck(Blue, "Blue")
+	ck(White, "White")
+	ck(200, "Color(200)")
+
+	// Unknown names and out of range values are rejected.
+	for _, s := range []string{"", "Crimson", "Color()", "Color(-1)", "Color(256)", "Color(7"} {
+		if c, err := ParseColor(s); err == nil {
+			panic(fmt.Sprintf("parse_color.go: ParseColor(%q) = %v, want error", s, c))
+		}
+	}
+
+	// JSON round trip.
+	type palette struct{ Colors []Color }
+	data, err := json.Marshal(palette{[]Color{Red, White, 200}})
+	if err != nil {
+		panic(err)
+	}
+	if got, want := string(data), `{"Colors":["Red","White","Color(200)"]}`; got != want {
+		panic("parse_color.go: json.Marshal: got " + got + ", want " + want)
+	}
+	var p palette
+	if err := json.Unmarshal(data, &p); err != nil {
+		panic(err)
+	}
+	if fmt.Sprint(p.Colors) != "[Red White Color(200)]" {
+		panic("parse_color.go: json.Unmarshal: got " + fmt.Sprint(p.Colors))
+	}
+}
+
+// ck checks that String and ParseColor are inverses for c.
+func ck(c Color, str string) {
+	if fmt.Sprint(c) != str {
+		panic("parse_color.go: " + str)
+	}
+	if got, err := ParseColor(str); err != nil || got != c {
+		panic(fmt.Sprintf("parse_color.go: ParseColor(%q) = %v, %v", str, got, err))
+	}
+}
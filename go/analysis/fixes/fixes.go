@@ -0,0 +1,249 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fixes merges the suggested fixes of analyzers and applies
+// them to files.
+//
+// It is intended for analysis drivers and other tools that apply the
+// [analysis.SuggestedFix] values of the diagnostics reported by one or
+// more analyzers over the same files, such as those of the primary
+// and test variants of a package, or of several analyzers.
+//
+// Each fix is treated as an independent change; a [Merger] merges
+// fixes in the order they are added, as if by a three-way diff tool
+// such as the UNIX diff3 command or 'git merge'. Any fix that cannot
+// be cleanly merged is discarded, in which case the tool should tell
+// the user to re-run it.
+//
+// A common reason for overlapping fixes is duplicate additions of the
+// same import. The merge algorithm may often cleanly resolve such
+// fixes, coalescing identical edits, but the merge may sometimes be
+// confused by nearby changes.
+//
+// Even when merging succeeds, there is no guarantee that the
+// composition of the two fixes is semantically correct. Coalescing
+// identical edits is appropriate for imports, but not for, say,
+// increments to a counter variable; the correct resolution in that
+// case might be to increment it twice. Or consider two fixes that
+// each delete the penultimate reference to an import or local
+// variable: each fix is sound individually, and they may be textually
+// distant from each other, but when both are applied, the program is
+// no longer valid because it has an unreferenced import or local
+// variable.
+//
+// Merging depends on both the order of fixes and the order of edits
+// within them. For example, if three fixes add import "a" twice and
+// import "b" once, the two imports of "a" may be combined if they
+// appear in order [a, a, b], or not if they appear as [a, b, a].
+//
+// Example:
+//
+//	var m fixes.Merger
+//	m.Format = true
+//	for _, diag := range diags {
+//		if len(diag.SuggestedFixes) > 0 {
+//			if _, err := m.Add(fset, &diag.SuggestedFixes[0]); err != nil {
+//				return err
+//			}
+//		}
+//	}
+//	changes, err := m.Changes()
+//	if err != nil {
+//		return err
+//	}
+//	if _, err := fixes.Write(changes); err != nil {
+//		return err
+//	}
+package fixes
+
+// TODO(adonovan): investigate replacing the final "gofmt" step with a
+// formatter that applies the unused-import deletion logic of
+// "goimports".
+//
+// TODO(adonovan): investigate an algebraic approach to imports;
+// that is, for fixes to Go source files, convert changes within the
+// import(...) portion of the file into semantic edits, compose those
+// edits algebraically, then convert the result back to edits.
+//
+// TODO(adonovan): handle file-system level aliases such as symbolic
+// links using robustio.FileID.
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"os"
+	"slices"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/diff"
+)
+
+// ErrModified is the error returned by [Merger.Add] when a file has
+// changed since it was analyzed, as indicated by a change in its size.
+// It indicates a concurrent write to at least one file, and possibly
+// others (consider a git checkout, for example).
+var ErrModified = errors.New("concurrent file modification detected")
+
+// A Merger merges the edits of suggested fixes, discarding fixes that
+// conflict with earlier ones. The zero value is ready to use.
+type Merger struct {
+	// ReadFile, if non-nil, returns the content of the named file,
+	// as it was when the file was analyzed. By default, files are
+	// read from the file system using [os.ReadFile].
+	//
+	// The Merger reads each file at most once, and assumes that
+	// all successful reads of the same file return the same content.
+	ReadFile func(filename string) ([]byte, error)
+
+	// Format causes [Merger.Changes] to format each changed file
+	// with [format.Source]. A file that is not valid Go source is
+	// left unformatted.
+	Format bool
+
+	baseline map[string][]byte      // original content of each file
+	edits    map[string][]diff.Edit // accumulated edits of each file
+}
+
+// Add merges the edits of fix, whose positions are relative to fset,
+// atop those of the fixes previously added. It reports whether the fix
+// was merged: a fix that conflicts with an earlier one in any file is
+// discarded as a whole.
+//
+// Add returns an error if the fix is invalid, if a file could not be
+// read, or, wrapping [ErrModified], if a file has changed since it was
+// analyzed. The fix is discarded in each case.
+//
+// Add may sort the edits of fix, and sets the End of each insertion.
+func (m *Merger) Add(fset *token.FileSet, fix *analysis.SuggestedFix) (bool, error) {
+	if err := analysisinternal.ValidateFix(fset, fix); err != nil {
+		return false, fmt.Errorf("invalid fix (%s): %v", fix.Message, err)
+	}
+
+	// Convert analysis.TextEdits to diff.Edits, grouped by file.
+	fileEdits := make(map[string][]diff.Edit)
+	for _, edit := range fix.TextEdits {
+		file := fset.File(edit.Pos)
+
+		baseline, err := m.content(file.Name())
+		if err != nil {
+			return false, err
+		}
+		if file.Size() != len(baseline) {
+			return false, fmt.Errorf("%w in file %s (size changed from %d -> %d bytes)",
+				ErrModified, file.Name(), file.Size(), len(baseline))
+		}
+
+		fileEdits[file.Name()] = append(fileEdits[file.Name()], diff.Edit{
+			Start: file.Offset(edit.Pos),
+			End:   file.Offset(edit.End),
+			New:   string(edit.NewText),
+		})
+	}
+
+	// Apply each set of edits by merging atop
+	// the previous accumulated state.
+	after := make(map[string][]diff.Edit)
+	for file, edits := range fileEdits {
+		if prev := m.edits[file]; len(prev) > 0 {
+			merged, ok := diff.Merge(prev, edits)
+			if !ok {
+				return false, nil // conflict
+			}
+			edits = merged
+		}
+		after[file] = edits
+	}
+
+	// The entire fix applied cleanly; commit it.
+	if m.edits == nil {
+		m.edits = make(map[string][]diff.Edit)
+	}
+	maps.Copy(m.edits, after)
+	return true, nil
+}
+
+// content returns the original content of the named file.
+func (m *Merger) content(filename string) ([]byte, error) {
+	content, ok := m.baseline[filename]
+	if !ok {
+		readFile := m.ReadFile
+		if readFile == nil {
+			readFile = os.ReadFile
+		}
+		var err error
+		content, err = readFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if m.baseline == nil {
+			m.baseline = make(map[string][]byte)
+		}
+		m.baseline[filename] = content
+	}
+	return content, nil
+}
+
+// A FileChange is the change to a file made by the merged fixes.
+type FileChange struct {
+	Filename string
+	Before   []byte // original content
+	After    []byte // content after the fixes, formatted if requested
+}
+
+// Diff returns the change in the form of a unified diff.
+func (c *FileChange) Diff() string {
+	return diff.Unified(c.Filename+" (old)", c.Filename+" (new)", string(c.Before), string(c.After))
+}
+
+// Changes returns the change to each file made by the fixes merged so
+// far, ordered by file name.
+func (m *Merger) Changes() ([]FileChange, error) {
+	var changes []FileChange
+	for _, file := range slices.Sorted(maps.Keys(m.edits)) {
+		edits := m.edits[file]
+		if len(edits) == 0 {
+			continue // the diffs annihilated (a miracle?)
+		}
+
+		// Apply accumulated fixes.
+		baseline := m.baseline[file]
+		final, err := diff.ApplyBytes(baseline, edits)
+		if err != nil {
+			return nil, fmt.Errorf("internal error in diff.ApplyBytes: %v", err)
+		}
+
+		// Attempt to format each file.
+		if m.Format {
+			if formatted, err := format.Source(final); err == nil {
+				final = formatted
+			}
+		}
+		changes = append(changes, FileChange{Filename: file, Before: baseline, After: final})
+	}
+	return changes, nil
+}
+
+// Write writes the new content of each changed file. It returns the
+// number of files written, and an error describing each failed write.
+//
+// The files are written independently, so a failure may leave some of
+// the fixes partially applied.
+func Write(changes []FileChange) (int, error) {
+	var (
+		n    int
+		errs []error
+	)
+	for _, change := range changes {
+		if err := os.WriteFile(change.Filename, change.After, 0644); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
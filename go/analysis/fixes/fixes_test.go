@@ -0,0 +1,116 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fixes_test
+
+import (
+	"errors"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/fixes"
+)
+
+func TestMerger(t *testing.T) {
+	dir := t.TempDir()
+	aGo := filepath.Join(dir, "a.go")
+	bGo := filepath.Join(dir, "b.go")
+	const (
+		a = "package a\n\nvar x = 1\n"
+		b = "package b\n\nvar y = 2\n"
+	)
+	for name, content := range map[string]string{aGo: a, bGo: b} {
+		if err := os.WriteFile(name, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fset := token.NewFileSet()
+	aFile := fset.AddFile(aGo, -1, len(a))
+	bFile := fset.AddFile(bGo, -1, len(b))
+
+	// replace returns a fix that replaces the specified text of file.
+	replace := func(file *token.File, content, old, new string) *analysis.SuggestedFix {
+		start := strings.Index(content, old)
+		return &analysis.SuggestedFix{
+			Message: "replace " + old,
+			TextEdits: []analysis.TextEdit{{
+				Pos:     file.Pos(start),
+				End:     file.Pos(start + len(old)),
+				NewText: []byte(new),
+			}},
+		}
+	}
+
+	m := fixes.Merger{Format: true}
+	for _, test := range []struct {
+		fix  *analysis.SuggestedFix
+		want bool
+	}{
+		{replace(aFile, a, "x", "xx"), true},
+		{replace(aFile, a, "x", "xx"), true},  // identical edits are coalesced
+		{replace(aFile, a, "x", "z"), false},  // conflict
+		{replace(bFile, b, "2", "2+0"), true}, // other file
+	} {
+		ok, err := m.Add(fset, test.fix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != test.want {
+			t.Errorf("Add(%s) = %t, want %t", test.fix.Message, ok, test.want)
+		}
+	}
+
+	changes, err := m.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Changes returned %d changes, want 2", len(changes))
+	}
+	for i, want := range []fixes.FileChange{
+		{Filename: aGo, Before: []byte(a), After: []byte("package a\n\nvar xx = 1\n")},
+		{Filename: bGo, Before: []byte(b), After: []byte("package b\n\nvar y = 2 + 0\n")}, // formatted
+	} {
+		got := changes[i]
+		if got.Filename != want.Filename || string(got.Before) != string(want.Before) || string(got.After) != string(want.After) {
+			t.Errorf("change %d = {%s, %q, %q}, want {%s, %q, %q}",
+				i, got.Filename, got.Before, got.After, want.Filename, want.Before, want.After)
+		}
+	}
+	if diff := changes[0].Diff(); !strings.Contains(diff, "-var x = 1\n+var xx = 1\n") {
+		t.Errorf("Diff() = %q, lacks change", diff)
+	}
+
+	n, err := fixes.Write(changes)
+	if n != 2 || err != nil {
+		t.Fatalf("Write() = (%d, %v), want (2, nil)", n, err)
+	}
+	for _, change := range changes {
+		data, err := os.ReadFile(change.Filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != string(change.After) {
+			t.Errorf("after Write, %s = %q, want %q", change.Filename, data, change.After)
+		}
+	}
+
+	// A file whose size has changed since it was analyzed.
+	m = fixes.Merger{}
+	if _, err := m.Add(fset, replace(aFile, a, "x", "xx")); !errors.Is(err, fixes.ErrModified) {
+		t.Errorf("Add to modified file returned %v, want ErrModified", err)
+	}
+
+	// An invalid fix.
+	m = fixes.Merger{ReadFile: func(string) ([]byte, error) { return []byte(a), nil }}
+	invalid := replace(aFile, a, "x", "xx")
+	invalid.TextEdits[0].Pos, invalid.TextEdits[0].End = invalid.TextEdits[0].End, invalid.TextEdits[0].Pos
+	if _, err := m.Add(fset, invalid); err == nil {
+		t.Errorf("Add of invalid fix succeeded unexpectedly")
+	}
+}
@@ -14,11 +14,10 @@ package checker
 // TODO(adonovan): publish the JSON schema in go/analysis or analysisjson.
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"go/format"
 	"io"
-	"maps"
 
 	"log"
 	"os"
@@ -31,11 +30,10 @@ import (
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/analysis/fixes"
 	"golang.org/x/tools/go/analysis/internal"
 	"golang.org/x/tools/go/analysis/internal/analysisflags"
 	"golang.org/x/tools/go/packages"
-	"golang.org/x/tools/internal/analysisinternal"
-	"golang.org/x/tools/internal/diff"
 )
 
 var (
@@ -322,9 +320,7 @@ func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
 // with each diagnostic reported by the specified actions.
 // All fixes must have been validated by [analysisinternal.ValidateFixes].
 //
-// Each fix is treated as an independent change; fixes are merged in
-// an arbitrary deterministic order as if by a three-way diff tool
-// such as the UNIX diff3 command or 'git merge'. Any fix that cannot be
+// The fixes are merged by a [fixes.Merger]: any fix that cannot be
 // cleanly merged is discarded, in which case the final summary tells
 // the user to re-run the tool.
 // TODO(adonovan): make the checker tool re-run the analysis itself.
@@ -337,42 +333,11 @@ func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
 // happened to the other ones.
 // TODO(adonovan): consider pre-filtering completely identical fixes.
 //
-// A common reason for overlapping fixes is duplicate additions of the
-// same import. The merge algorithm may often cleanly resolve such
-// fixes, coalescing identical edits, but the merge may sometimes be
-// confused by nearby changes.
-//
-// Even when merging succeeds, there is no guarantee that the
-// composition of the two fixes is semantically correct. Coalescing
-// identical edits is appropriate for imports, but not for, say,
-// increments to a counter variable; the correct resolution in that
-// case might be to increment it twice. Or consider two fixes that
-// each delete the penultimate reference to an import or local
-// variable: each fix is sound individually, and they may be textually
-// distant from each other, but when both are applied, the program is
-// no longer valid because it has an unreferenced import or local
-// variable.
-// TODO(adonovan): investigate replacing the final "gofmt" step with a
-// formatter that applies the unused-import deletion logic of
-// "goimports".
-//
-// Merging depends on both the order of fixes and they order of edits
-// within them. For example, if three fixes add import "a" twice and
-// import "b" once, the two imports of "a" may be combined if they
-// appear in order [a, a, b], or not if they appear as [a, b, a].
-// TODO(adonovan): investigate an algebraic approach to imports;
-// that is, for fixes to Go source files, convert changes within the
-// import(...) portion of the file into semantic edits, compose those
-// edits algebraically, then convert the result back to edits.
-//
 // applyFixes returns success if all fixes are valid, could be cleanly
 // merged, and the corresponding files were successfully updated.
 //
 // If showDiff, instead of updating the files it display the final
 // patch composed of all the cleanly merged fixes.
-//
-// TODO(adonovan): handle file-system level aliases such as symbolic
-// links using robustio.FileID.
 func applyFixes(actions []*checker.Action, showDiff bool) error {
 
 	// Select fixes to apply.
@@ -383,13 +348,13 @@ func applyFixes(actions []*checker.Action, showDiff bool) error {
 		fix *analysis.SuggestedFix
 		act *checker.Action
 	}
-	var fixes []*fixact
+	var selected []*fixact
 	for _, act := range actions {
 		for _, diag := range act.Diagnostics {
 			for i := range diag.SuggestedFixes {
 				fix := &diag.SuggestedFixes[i]
 				if i == 0 {
-					fixes = append(fixes, &fixact{fix, act})
+					selected = append(selected, &fixact{fix, act})
 				} else {
 					// TODO(adonovan): abstract the logger.
 					log.Printf("%s: ignoring alternative fix %q", act, fix.Message)
@@ -398,133 +363,64 @@ func applyFixes(actions []*checker.Action, showDiff bool) error {
 		}
 	}
 
-	// Read file content on demand, from the virtual
-	// file system that fed the analyzer (see #62292).
-	//
-	// This cache assumes that all successful reads for the same
-	// file name return the same content.
-	// (It is tempting to group fixes by package and do the
-	// merge/apply/format steps one package at a time, but
-	// packages are not disjoint, due to test variants, so this
-	// would not really address the issue.)
-	baselineContent := make(map[string][]byte)
-	getBaseline := func(readFile analysisinternal.ReadFileFunc, filename string) ([]byte, error) {
-		content, ok := baselineContent[filename]
-		if !ok {
-			var err error
-			content, err = readFile(filename)
-			if err != nil {
-				return nil, err
-			}
-			baselineContent[filename] = content
-		}
-		return content, nil
-	}
-
 	// Apply each fix, updating the current state
 	// only if the entire fix can be cleanly merged.
-	accumulatedEdits := make(map[string][]diff.Edit)
+	merger := &fixes.Merger{Format: true}
 	goodFixes := 0
-fixloop:
-	for _, fixact := range fixes {
-		readFile := internal.Pass(fixact.act).ReadFile
-
-		// Convert analysis.TextEdits to diff.Edits, grouped by file.
-		// Precondition: a prior call to validateFix succeeded.
-		fileEdits := make(map[string][]diff.Edit)
-		fset := fixact.act.Package.Fset
-		for _, edit := range fixact.fix.TextEdits {
-			file := fset.File(edit.Pos)
-
-			baseline, err := getBaseline(readFile, file.Name())
-			if err != nil {
-				log.Printf("skipping fix to file %s: %v", file.Name(), err)
-				continue fixloop
-			}
-
+	for _, fixact := range selected {
+		// Read file content on demand, from the virtual
+		// file system that fed the analyzer (see #62292).
+		//
+		// The merger assumes that all successful reads for the
+		// same file name return the same content.
+		// (It is tempting to group fixes by package and do the
+		// merge/apply/format steps one package at a time, but
+		// packages are not disjoint, due to test variants, so this
+		// would not really address the issue.)
+		merger.ReadFile = internal.Pass(fixact.act).ReadFile
+
+		ok, err := merger.Add(fixact.act.Package.Fset, fixact.fix)
+		if err != nil {
 			// We choose to treat size mismatch as a serious error,
-			// as it indicates a concurrent write to at least one file,
-			// and possibly others (consider a git checkout, for example).
-			if file.Size() != len(baseline) {
-				return fmt.Errorf("concurrent file modification detected in file %s (size changed from %d -> %d bytes); aborting fix",
-					file.Name(), file.Size(), len(baseline))
+			// as it indicates a concurrent write to at least one file.
+			if errors.Is(err, fixes.ErrModified) {
+				return fmt.Errorf("%v; aborting fix", err)
 			}
-
-			fileEdits[file.Name()] = append(fileEdits[file.Name()], diff.Edit{
-				Start: file.Offset(edit.Pos),
-				End:   file.Offset(edit.End),
-				New:   string(edit.NewText),
-			})
+			log.Printf("skipping fix: %v", err)
+			continue
 		}
-
-		// Apply each set of edits by merging atop
-		// the previous accumulated state.
-		after := make(map[string][]diff.Edit)
-		for file, edits := range fileEdits {
-			if prev := accumulatedEdits[file]; len(prev) > 0 {
-				merged, ok := diff.Merge(prev, edits)
-				if !ok {
-					// debugging
-					if false {
-						log.Printf("%s: fix %s conflicts", fixact.act, fixact.fix.Message)
-					}
-					continue fixloop // conflict
-				}
-				edits = merged
+		if !ok {
+			// debugging
+			if false {
+				log.Printf("%s: fix %s conflicts", fixact.act, fixact.fix.Message)
 			}
-			after[file] = edits
+			continue // conflict
 		}
-
-		// The entire fix applied cleanly; commit it.
 		goodFixes++
-		maps.Copy(accumulatedEdits, after)
 		// debugging
 		if false {
 			log.Printf("%s: fix %s applied", fixact.act, fixact.fix.Message)
 		}
 	}
-	badFixes := len(fixes) - goodFixes
+	badFixes := len(selected) - goodFixes
 
 	// Show diff or update files to final state.
-	var files []string
-	for file := range accumulatedEdits {
-		files = append(files, file)
+	changes, err := merger.Changes()
+	if err != nil {
+		log.Fatal(err)
 	}
-	sort.Strings(files) // for deterministic -diff
 	var filesUpdated, totalFiles int
-	for _, file := range files {
-		edits := accumulatedEdits[file]
-		if len(edits) == 0 {
-			continue // the diffs annihilated (a miracle?)
+	if showDiff {
+		for _, change := range changes {
+			// TODO(adonovan): abstract the I/O.
+			os.Stdout.WriteString(change.Diff())
 		}
-
-		// Apply accumulated fixes.
-		baseline := baselineContent[file] // (cache hit)
-		final, err := diff.ApplyBytes(baseline, edits)
+	} else {
+		// TODO(adonovan): abstract the I/O.
+		totalFiles = len(changes)
+		filesUpdated, err = fixes.Write(changes)
 		if err != nil {
-			log.Fatalf("internal error in diff.ApplyBytes: %v", err)
-		}
-
-		// Attempt to format each file.
-		if formatted, err := format.Source(final); err == nil {
-			final = formatted
-		}
-
-		if showDiff {
-			// Since we formatted the file, we need to recompute the diff.
-			unified := diff.Unified(file+" (old)", file+" (new)", string(baseline), string(final))
-			// TODO(adonovan): abstract the I/O.
-			os.Stdout.WriteString(unified)
-
-		} else {
-			// write
-			totalFiles++
-			// TODO(adonovan): abstract the I/O.
-			if err := os.WriteFile(file, final, 0644); err != nil {
-				log.Println(err)
-				continue
-			}
-			filesUpdated++
+			log.Println(err)
 		}
 	}
 
@@ -561,15 +457,15 @@ fixloop:
 	// TODO(adonovan): should we log that n files were updated in case of total victory?
 	if badFixes > 0 || filesUpdated < totalFiles {
 		if showDiff {
-			return fmt.Errorf("%d of %d fixes skipped (e.g. due to conflicts)", badFixes, len(fixes))
+			return fmt.Errorf("%d of %d fixes skipped (e.g. due to conflicts)", badFixes, len(selected))
 		} else {
 			return fmt.Errorf("applied %d of %d fixes; %d files updated. (Re-run the command to apply more.)",
-				goodFixes, len(fixes), filesUpdated)
+				goodFixes, len(selected), filesUpdated)
 		}
 	}
 
 	if dbg('v') {
-		log.Printf("applied %d fixes, updated %d files", len(fixes), filesUpdated)
+		log.Printf("applied %d fixes, updated %d files", len(selected), filesUpdated)
 	}
 
 	return nil
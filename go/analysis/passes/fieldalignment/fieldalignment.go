@@ -7,12 +7,11 @@
 package fieldalignment
 
 import (
-	"bytes"
 	"fmt"
 	"go/ast"
-	"go/format"
 	"go/token"
 	"go/types"
+	"slices"
 	"sort"
 
 	"golang.org/x/tools/go/analysis"
@@ -23,7 +22,9 @@ import (
 const Doc = `find structs that would use less memory if their fields were sorted
 
 This analyzer find structs that can be rearranged to use less memory, and provides
-a suggested edit with the most compact order.
+a suggested edit with the most compact order. The edit preserves the comments
+of each field, and only reorders fields within each group of fields not
+separated by blank lines.
 
 Note that there are two different diagnostics reported. One checks struct size,
 and the other reports "pointer bytes" used. Pointer bytes is how many bytes of the
@@ -49,8 +50,9 @@ known as "false sharing" that slows down both goroutines.
 
 Unlike most analyzers, which report likely mistakes, the diagnostics
 produced by fieldanalyzer very rarely indicate a significant problem,
-so the analyzer is not included in typical suites such as vet or
-gopls. Use this standalone command to run it on your code:
+so the analyzer is not included in typical suites such as vet, and
+it is disabled by default in gopls. Use this standalone command to run
+it on your code:
 
    $ go install golang.org/x/tools/go/analysis/passes/fieldalignment/cmd/fieldalignment@latest
    $ fieldalignment [packages]
@@ -90,148 +92,166 @@ func fieldalignment(pass *analysis.Pass, node *ast.StructType, typ *types.Struct
 	maxAlign := pass.TypesSizes.Alignof(unsafePointerTyp)
 
 	s := gcSizes{wordSize, maxAlign}
-	optimal, indexes := optimalOrder(typ, &s)
-	optsz, optptrs := s.Sizeof(optimal), s.ptrdata(optimal)
-
-	var message string
-	if sz := s.Sizeof(typ); sz != optsz {
-		message = fmt.Sprintf("struct of size %d could be %d", sz, optsz)
-	} else if ptrs := s.ptrdata(typ); ptrs != optptrs {
-		message = fmt.Sprintf("struct with %d pointer bytes could be %d", ptrs, optptrs)
-	} else {
-		// Already optimal order.
-		return
-	}
 
-	// Flatten the ast node since it could have multiple field names per list item while
-	// *types.Struct only have one item per field.
-	// TODO: Preserve multi-named fields instead of flattening.
-	var flat []*ast.Field
+	// Fields separated by blank lines form groups, which are
+	// preserved: fields are reordered only within their group.
+	// A field with several names (a, b T) is moved as a unit.
+	var (
+		groups [][]*ast.Field
+		elems  = make(map[*ast.Field]elem)
+		index  = 0
+		prev   *ast.Field
+	)
 	for _, f := range node.Fields.List {
-		// TODO: Preserve comment, for now get rid of them.
-		//       See https://github.com/golang/go/issues/20744
-		f.Comment = nil
-		f.Doc = nil
-		if len(f.Names) <= 1 {
-			flat = append(flat, f)
-			continue
-		}
-		for _, name := range f.Names {
-			flat = append(flat, &ast.Field{
-				Names: []*ast.Ident{name},
-				Type:  f.Type,
-			})
+		elems[f] = s.elem(index, typ.Field(index).Type())
+		index += max(1, len(f.Names))
+		if prev == nil || pass.Fset.Position(fieldStart(f)).Line > pass.Fset.Position(fieldEnd(prev)).Line+1 {
+			groups = append(groups, nil)
 		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], f)
+		prev = f
 	}
-
-	// Sort fields according to the optimal order.
-	var reordered []*ast.Field
-	for _, index := range indexes {
-		reordered = append(reordered, flat[index])
+	if index != typ.NumFields() {
+		return // ill-typed
 	}
 
-	newStr := &ast.StructType{
-		Fields: &ast.FieldList{
-			List: reordered,
-		},
+	var (
+		order     []int           // new order of the fields of typ
+		reordered [][2]*ast.Field // (old, new) field pairs
+	)
+	for _, group := range groups {
+		sorted := slices.Clone(group)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return less(elems[sorted[i]], elems[sorted[j]])
+		})
+		for k, f := range sorted {
+			for i := range max(1, len(f.Names)) {
+				order = append(order, elems[f].index+i)
+			}
+			if f != group[k] {
+				reordered = append(reordered, [2]*ast.Field{group[k], f})
+			}
+		}
+	}
+	fields := make([]*types.Var, len(order))
+	for i, index := range order {
+		fields[i] = typ.Field(index)
 	}
+	optimal := types.NewStruct(fields, nil)
 
-	// Write the newly aligned struct node to get the content for suggested fixes.
-	var buf bytes.Buffer
-	if err := format.Node(&buf, token.NewFileSet(), newStr); err != nil {
+	sz, ptrs := s.Sizeof(typ), s.ptrdata(typ)
+	optsz, optptrs := s.Sizeof(optimal), s.ptrdata(optimal)
+	var message string
+	if optsz < sz {
+		message = fmt.Sprintf("struct of size %d could be %d (saving %d bytes)", sz, optsz, sz-optsz)
+	} else if optsz == sz && optptrs < ptrs {
+		message = fmt.Sprintf("struct with %d pointer bytes could be %d", ptrs, optptrs)
+	} else {
+		// Already optimal order.
 		return
 	}
 
-	pass.Report(analysis.Diagnostic{
+	diag := analysis.Diagnostic{
 		Pos:     node.Pos(),
 		End:     node.Pos() + token.Pos(len("struct")),
 		Message: message,
-		SuggestedFixes: []analysis.SuggestedFix{{
-			Message: "Rearrange fields",
-			TextEdits: []analysis.TextEdit{{
-				Pos:     node.Pos(),
-				End:     node.End(),
-				NewText: buf.Bytes(),
-			}},
-		}},
-	})
-}
+	}
 
-func optimalOrder(str *types.Struct, sizes *gcSizes) (*types.Struct, []int) {
-	nf := str.NumFields()
+	// Replace the text of each moved field, including its doc and
+	// line comments, by the text of the field that takes its place.
+	tokFile := pass.Fset.File(node.Pos())
+	if content, err := pass.ReadFile(tokFile.Name()); err == nil {
+		text := func(f *ast.Field) []byte {
+			return content[tokFile.Offset(fieldStart(f)):tokFile.Offset(fieldEnd(f))]
+		}
+		var edits []analysis.TextEdit
+		for _, pair := range reordered {
+			old, new := pair[0], pair[1]
+			edits = append(edits, analysis.TextEdit{
+				Pos:     fieldStart(old),
+				End:     fieldEnd(old),
+				NewText: text(new),
+			})
+		}
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Rearrange fields",
+			TextEdits: edits,
+		}}
+	}
+	pass.Report(diag)
+}
 
-	type elem struct {
-		index   int
-		alignof int64
-		sizeof  int64
-		ptrdata int64
+// fieldStart returns the start of field f, including its doc comment.
+func fieldStart(f *ast.Field) token.Pos {
+	if f.Doc != nil {
+		return f.Doc.Pos()
 	}
+	return f.Pos()
+}
 
-	elems := make([]elem, nf)
-	for i := 0; i < nf; i++ {
-		field := str.Field(i)
-		ft := field.Type()
-		elems[i] = elem{
-			i,
-			sizes.Alignof(ft),
-			sizes.Sizeof(ft),
-			sizes.ptrdata(ft),
-		}
+// fieldEnd returns the end of field f, including its line comment.
+func fieldEnd(f *ast.Field) token.Pos {
+	if f.Comment != nil {
+		return f.Comment.End()
 	}
+	return f.End()
+}
 
-	sort.Slice(elems, func(i, j int) bool {
-		ei := &elems[i]
-		ej := &elems[j]
+// An elem holds the layout properties of the type of a struct field.
+type elem struct {
+	index   int
+	alignof int64
+	sizeof  int64
+	ptrdata int64
+}
 
-		// Place zero sized objects before non-zero sized objects.
-		zeroi := ei.sizeof == 0
-		zeroj := ej.sizeof == 0
-		if zeroi != zeroj {
-			return zeroi
-		}
+func (s *gcSizes) elem(index int, t types.Type) elem {
+	return elem{index, s.Alignof(t), s.Sizeof(t), s.ptrdata(t)}
+}
 
-		// Next, place more tightly aligned objects before less tightly aligned objects.
-		if ei.alignof != ej.alignof {
-			return ei.alignof > ej.alignof
-		}
+// less reports whether a field with layout ei should precede one with
+// layout ej in the most compact order.
+func less(ei, ej elem) bool {
+	// Place zero sized objects before non-zero sized objects.
+	zeroi := ei.sizeof == 0
+	zeroj := ej.sizeof == 0
+	if zeroi != zeroj {
+		return zeroi
+	}
 
-		// Place pointerful objects before pointer-free objects.
-		noptrsi := ei.ptrdata == 0
-		noptrsj := ej.ptrdata == 0
-		if noptrsi != noptrsj {
-			return noptrsj
-		}
+	// Next, place more tightly aligned objects before less tightly aligned objects.
+	if ei.alignof != ej.alignof {
+		return ei.alignof > ej.alignof
+	}
 
-		if !noptrsi {
-			// If both have pointers...
-
-			// ... then place objects with less trailing
-			// non-pointer bytes earlier. That is, place
-			// the field with the most trailing
-			// non-pointer bytes at the end of the
-			// pointerful section.
-			traili := ei.sizeof - ei.ptrdata
-			trailj := ej.sizeof - ej.ptrdata
-			if traili != trailj {
-				return traili < trailj
-			}
-		}
+	// Place pointerful objects before pointer-free objects.
+	noptrsi := ei.ptrdata == 0
+	noptrsj := ej.ptrdata == 0
+	if noptrsi != noptrsj {
+		return noptrsj
+	}
 
-		// Lastly, order by size.
-		if ei.sizeof != ej.sizeof {
-			return ei.sizeof > ej.sizeof
+	if !noptrsi {
+		// If both have pointers...
+
+		// ... then place objects with less trailing
+		// non-pointer bytes earlier. That is, place
+		// the field with the most trailing
+		// non-pointer bytes at the end of the
+		// pointerful section.
+		traili := ei.sizeof - ei.ptrdata
+		trailj := ej.sizeof - ej.ptrdata
+		if traili != trailj {
+			return traili < trailj
 		}
+	}
 
-		return false
-	})
-
-	fields := make([]*types.Var, nf)
-	indexes := make([]int, nf)
-	for i, e := range elems {
-		fields[i] = str.Field(e.index)
-		indexes[i] = e.index
+	// Lastly, order by size.
+	if ei.sizeof != ej.sizeof {
+		return ei.sizeof > ej.sizeof
 	}
-	return types.NewStruct(fields, nil), indexes
+
+	return false
 }
 
 // Code below based on go/types.StdSizes.
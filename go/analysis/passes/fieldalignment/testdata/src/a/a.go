@@ -44,3 +44,20 @@ type WithComments struct { // want "struct of size 8 could be 4"
 
 	// and a last comment
 }
+
+type Grouped struct { // want "struct of size 12 could be 8 \\(saving 4 bytes\\)"
+	a bool  // a comment
+	b int32 /* b comment */
+	c bool
+
+	// d doc
+	d int16
+}
+
+type GroupedGood struct {
+	a bool
+
+	b int32
+
+	c bool
+}
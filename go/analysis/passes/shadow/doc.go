@@ -30,4 +30,9 @@
 //		}
 //		return err
 //	}
+//
+// The analyzer suggests two fixes: to rename the inner variable, and,
+// when every variable declared by a short variable declaration
+// shadows one of the same type, to replace := by = so that the
+// statement assigns to the outer variables instead.
 package shadow
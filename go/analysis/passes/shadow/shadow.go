@@ -6,9 +6,11 @@ package shadow
 
 import (
 	_ "embed"
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
+	"strconv"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
@@ -142,7 +144,7 @@ func checkShadowAssignment(pass *analysis.Pass, spans map[types.Object]span, a *
 			pass.ReportRangef(expr, "invalid AST: short variable declaration of non-identifier")
 			return
 		}
-		checkShadowing(pass, spans, ident)
+		checkShadowing(pass, spans, ident, a)
 	}
 }
 
@@ -218,13 +220,14 @@ func checkShadowDecl(pass *analysis.Pass, spans map[types.Object]span, d *ast.Ge
 			return
 		}
 		for _, ident := range valueSpec.Names {
-			checkShadowing(pass, spans, ident)
+			checkShadowing(pass, spans, ident, nil)
 		}
 	}
 }
 
 // checkShadowing checks whether the identifier shadows an identifier in an outer scope.
-func checkShadowing(pass *analysis.Pass, spans map[types.Object]span, ident *ast.Ident) {
+// If the identifier is declared by a short variable declaration, assign is that statement.
+func checkShadowing(pass *analysis.Pass, spans map[types.Object]span, ident *ast.Ident, assign *ast.AssignStmt) {
 	if ident.Name == "_" {
 		// Can't shadow the blank identifier.
 		return
@@ -263,6 +266,83 @@ func checkShadowing(pass *analysis.Pass, spans map[types.Object]span, ident *ast
 	// Don't complain if the types differ: that implies the programmer really wants two different things.
 	if types.Identical(obj.Type(), shadowed.Type()) {
 		line := pass.Fset.Position(shadowed.Pos()).Line
-		pass.ReportRangef(ident, "declaration of %q shadows declaration at line %d", obj.Name(), line)
+		var fixes []analysis.SuggestedFix
+		if assign != nil && canAssign(pass.TypesInfo, assign) {
+			fixes = append(fixes, analysis.SuggestedFix{
+				Message: "Use = to assign to the outer variable",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     assign.TokPos,
+					End:     assign.TokPos + token.Pos(len(":=")),
+					NewText: []byte("="),
+				}},
+			})
+		}
+		if fix, ok := renameFix(pass.TypesInfo, obj); ok {
+			fixes = append(fixes, fix)
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:            ident.Pos(),
+			End:            ident.End(),
+			Message:        fmt.Sprintf("declaration of %q shadows declaration at line %d", obj.Name(), line),
+			SuggestedFixes: fixes,
+		})
+	}
+}
+
+// canAssign reports whether the short variable declaration a may be
+// turned into an assignment: that is, whether each variable it
+// declares shadows a variable of identical type in an outer scope.
+func canAssign(info *types.Info, a *ast.AssignStmt) bool {
+	for _, expr := range a.Lhs {
+		ident := expr.(*ast.Ident) // checked by checkShadowAssignment
+		obj := info.Defs[ident]
+		if obj == nil || ident.Name == "_" {
+			continue // blank, or a redeclaration of a variable in the same scope
+		}
+		_, shadowed := obj.Parent().Parent().LookupParent(obj.Name(), obj.Pos())
+		if v, ok := shadowed.(*types.Var); !ok || v.Parent() == types.Universe || !types.Identical(v.Type(), obj.Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// renameFix returns a fix that renames the shadowing variable obj and
+// its uses to a fresh name, formed by appending a number to its name,
+// that is neither declared in an enclosing scope nor visible at any
+// of its uses.
+func renameFix(info *types.Info, obj types.Object) (analysis.SuggestedFix, bool) {
+	var uses []*ast.Ident
+	for id, o := range info.Uses {
+		if o == obj {
+			uses = append(uses, id)
+		}
+	}
+	for i := 2; i < 10; i++ {
+		name := obj.Name() + strconv.Itoa(i)
+		if _, prev := obj.Parent().LookupParent(name, token.NoPos); prev != nil {
+			continue
+		}
+		conflict := false
+		for _, id := range uses {
+			if scope := obj.Parent().Innermost(id.Pos()); scope != nil {
+				if _, prev := scope.LookupParent(name, id.Pos()); prev != nil {
+					conflict = true
+					break
+				}
+			}
+		}
+		if conflict {
+			continue
+		}
+		edits := []analysis.TextEdit{{Pos: obj.Pos(), End: obj.Pos() + token.Pos(len(obj.Name())), NewText: []byte(name)}}
+		for _, id := range uses {
+			edits = append(edits, analysis.TextEdit{Pos: id.Pos(), End: id.End(), NewText: []byte(name)})
+		}
+		return analysis.SuggestedFix{
+			Message:   fmt.Sprintf("Rename %s to %s", obj.Name(), name),
+			TextEdits: edits,
+		}, true
 	}
+	return analysis.SuggestedFix{}, false
 }
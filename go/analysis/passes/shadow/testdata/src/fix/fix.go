@@ -0,0 +1,43 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the suggested fixes of the shadowed
+// variable checker.
+
+package fix
+
+import "os"
+
+func Read(f *os.File, buf []byte) (err error) {
+	for {
+		n, err := f.Read(buf) // want "declaration of .err. shadows declaration at line 12"
+		if err != nil {
+			break
+		}
+		_ = n
+	}
+	return err
+}
+
+func Names(f *os.File, buf []byte) (err error) {
+	var err2 error
+	_ = err2
+	if f != nil {
+		var err = os.ErrClosed // want "declaration of .err. shadows declaration at line 23"
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func Close(f *os.File) (err error) {
+	if f != nil {
+		err := f.Close() // want "declaration of .err. shadows declaration at line 35"
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
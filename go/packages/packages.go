@@ -273,6 +273,17 @@ func Load(cfg *Config, patterns ...string) ([]*Package, error) {
 		return nil, err
 	}
 
+	if err := ld.setSizes(response, external); err != nil {
+		return nil, err
+	}
+
+	return ld.refine(response)
+}
+
+// setSizes sets the type sizes of the loader from the driver's
+// response. The boolean indicates that an external driver handled the
+// request.
+func (ld *loader) setSizes(response *DriverResponse, external bool) error {
 	ld.sizes = types.SizesFor(response.Compiler, response.Arch)
 	if ld.sizes == nil && ld.Config.Mode&(NeedTypes|NeedTypesSizes|NeedTypesInfo) != 0 {
 		// Type size information is needed but unavailable.
@@ -287,12 +298,11 @@ func Load(cfg *Config, patterns ...string) ([]*Package, error) {
 		} else {
 			// Go list should never fail to deliver accurate size information.
 			// Reject the whole Load since the error is the same for every package.
-			return nil, fmt.Errorf("can't determine type sizes for compiler %q on GOARCH %q",
+			return fmt.Errorf("can't determine type sizes for compiler %q on GOARCH %q",
 				response.Compiler, response.Arch)
 		}
 	}
-
-	return ld.refine(response)
+	return nil
 }
 
 // defaultDriver is a driver that implements go/packages' fallback behavior.
@@ -699,6 +709,7 @@ type loaderPackage struct {
 	needsrc         bool             // load from source (Mode >= LoadTypes)
 	needtypes       bool             // type information is either requested or depended on
 	initial         bool             // package was matched by a pattern
+	reused          bool             // package is from a previous load (see Reload)
 	goVersion       int              // minor version number of go command on PATH
 }
 
@@ -706,7 +717,8 @@ type loaderPackage struct {
 type loader struct {
 	pkgs map[string]*loaderPackage // keyed by Package.ID
 	Config
-	sizes        types.Sizes // non-nil if needed by mode
+	reused       map[string]*Package // complete packages to reuse, keyed by ID (see Reload)
+	sizes        types.Sizes         // non-nil if needed by mode
 	parseCache   map[string]*parseValue
 	parseCacheMu sync.Mutex
 	exportMu     sync.Mutex // enforces mutual exclusion of exportdata operations
@@ -791,6 +803,13 @@ func (ld *loader) refine(response *DriverResponse) ([]*Package, error) {
 		rootMap[root] = i
 	}
 	ld.pkgs = make(map[string]*loaderPackage)
+	// Packages of a previous load that are unaffected by changes
+	// are complete; they are neither visited nor loaded.
+	for id, pkg := range ld.reused {
+		if _, found := rootMap[id]; !found {
+			ld.pkgs[id] = &loaderPackage{Package: pkg, reused: true}
+		}
+	}
 	// first pass, fixup and build the map and roots
 	var initial = make([]*loaderPackage, len(roots))
 	for _, pkg := range response.Packages {
@@ -798,6 +817,9 @@ func (ld *loader) refine(response *DriverResponse) ([]*Package, error) {
 		if i, found := rootMap[pkg.ID]; found {
 			rootIndex = i
 		}
+		if lpkg, ok := ld.pkgs[pkg.ID]; ok && lpkg.reused {
+			continue
+		}
 
 		// Overlays can invalidate export data.
 		// TODO(matloob): make this check fine-grained based on dependencies on overlaid files
@@ -877,6 +899,10 @@ func (ld *loader) refine(response *DriverResponse) ([]*Package, error) {
 						lpkg.importErrors[importPath] = importErr
 						continue
 					}
+					if imp.reused {
+						lpkg.Imports[importPath] = imp.Package
+						continue // already loaded
+					}
 
 					if visit(lpkg, imp) {
 						lpkg.needsrc = true
@@ -900,8 +926,9 @@ func (ld *loader) refine(response *DriverResponse) ([]*Package, error) {
 					lpkg.TypesSizes = ld.sizes
 				}
 
-				// Add packages with no imports directly to the queue of leaves.
-				if len(lpkg.Imports) == 0 {
+				// Add packages with no imports that are yet to be
+				// loaded directly to the queue of leaves.
+				if lpkg.unfinishedSuccs.Load() == 0 {
 					leaves = append(leaves, lpkg)
 				}
 
@@ -985,6 +1012,9 @@ func (ld *loader) refine(response *DriverResponse) ([]*Package, error) {
 		result[i] = lpkg.Package
 	}
 	for i := range ld.pkgs {
+		if ld.pkgs[i].reused {
+			continue // already cleared by the previous load
+		}
 		// Clear all unrequested fields,
 		// to catch programs that use more than they request.
 		if ld.requestedMode&NeedName == 0 {
@@ -1231,6 +1261,11 @@ func (ld *loader) loadPackage(lpkg *loaderPackage) {
 		if ipkg.Types != nil && ipkg.Types.Complete() {
 			return ipkg.Types, nil
 		}
+		if imp := ld.pkgs[ipkg.ID]; imp != nil && imp.reused {
+			// An indirect dependency of the packages
+			// of the previous load (see Reload).
+			return nil, fmt.Errorf("package %s was not loaded with complete types", path)
+		}
 		log.Fatalf("internal error: package %q without types was imported from %q", path, lpkg)
 		panic("unreachable")
 	})
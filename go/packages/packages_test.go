@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
@@ -3400,3 +3401,216 @@ func writeTree(t *testing.T, archive string) string {
 	}
 	return root
 }
+
+func TestReload(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := writeTree(t, `
+-- go.mod --
+module example.com
+
+go 1.18
+
+-- a/a.go --
+package a
+
+import "example.com/b"
+
+var A = b.B
+
+-- b/b.go --
+package b
+
+var B = 1
+
+-- c/c.go --
+package c
+
+var C = 1
+`)
+	cfg := &packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedImports |
+			packages.NeedDeps |
+			packages.NeedTypes |
+			packages.NeedSyntax |
+			packages.NeedTypesInfo,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages contain errors")
+	}
+	// byPath returns the packages of the specified paths.
+	byPath := func(pkgs []*packages.Package, paths ...string) []*packages.Package {
+		var result []*packages.Package
+		for _, path := range paths {
+			i := slices.IndexFunc(pkgs, func(pkg *packages.Package) bool { return pkg.PkgPath == path })
+			if i < 0 {
+				t.Fatalf("no package %s in %v", path, pkgs)
+			}
+			result = append(result, pkgs[i])
+		}
+		return result
+	}
+	initial := byPath(pkgs, "example.com/a", "example.com/b", "example.com/c")
+	a, b, c := initial[0], initial[1], initial[2]
+
+	// No changes: nothing is reloaded.
+	reloaded, err := packages.Reload(cfg, pkgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(reloaded, pkgs) {
+		t.Errorf("Reload with no changes returned %v, want %v", reloaded, pkgs)
+	}
+
+	// Change the type of b.B, and add a file to package b.
+	if err := os.WriteFile(filepath.Join(dir, "b/b.go"), []byte("package b\n\nvar B = \"one\"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b/b2.go"), []byte("package b\n\nvar B2 = 2\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err = packages.Reload(cfg, pkgs, "b/b.go", filepath.Join(dir, "b/b2.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(reloaded) > 0 {
+		t.Fatal("reloaded packages contain errors")
+	}
+	if len(reloaded) != 3 {
+		t.Fatalf("Reload returned %d packages, want 3", len(reloaded))
+	}
+	if !slices.EqualFunc(reloaded, pkgs, func(x, y *packages.Package) bool { return x.ID == y.ID }) {
+		t.Errorf("Reload returned %v, want counterparts of %v", reloaded, pkgs)
+	}
+	initial = byPath(reloaded, "example.com/a", "example.com/b", "example.com/c")
+	a2, b2, c2 := initial[0], initial[1], initial[2]
+
+	// a and b are reloaded, and a sees the changes to b.
+	if a2 == a || b2 == b {
+		t.Errorf("Reload did not reload the affected packages a and b")
+	}
+	if got := len(b2.GoFiles); got != 2 {
+		t.Errorf("reloaded b has %d files, want 2", got)
+	}
+	if b2.Types.Scope().Lookup("B2") == nil {
+		t.Errorf("reloaded b lacks B2")
+	}
+	if a2.Imports["example.com/b"] != b2 {
+		t.Errorf("reloaded a does not import reloaded b")
+	}
+	if got := a2.Types.Scope().Lookup("A").Type().String(); got != "string" {
+		t.Errorf("type of reloaded a.A is %s, want string", got)
+	}
+
+	// c is unaffected, and shares its types and file set.
+	if c2 != c {
+		t.Errorf("Reload reloaded the unaffected package c")
+	}
+	if a2.Fset != a.Fset {
+		t.Errorf("reloaded packages do not share the file set of the initial packages")
+	}
+}
+
+func TestReloadDependency(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := writeTree(t, `
+-- go.mod --
+module example.com
+
+go 1.18
+
+-- a/a.go --
+package a
+
+import "example.com/b"
+
+var A = b.F()
+
+-- b/b.go --
+package b
+
+import "example.com/c"
+
+func F() c.T { return 0 }
+
+-- c/c.go --
+package c
+
+type T int
+`)
+	// Without NeedDeps, b is loaded from export data,
+	// and c only as far as b refers to it.
+	cfg := &packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedImports |
+			packages.NeedTypes |
+			packages.NeedSyntax |
+			packages.NeedTypesInfo,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages contain errors")
+	}
+	a := pkgs[0]
+	b := a.Imports["example.com/b"]
+	c := b.Imports["example.com/c"]
+
+	// Type information requires the types of the reused packages.
+	noTypes := *cfg
+	noTypes.Mode &^= packages.NeedTypes
+	if _, err := packages.Reload(&noTypes, pkgs, "b/b.go"); err == nil {
+		t.Errorf("Reload without NeedTypes succeeded, want error")
+	}
+
+	// Change the result type of b.F.
+	if err := os.WriteFile(filepath.Join(dir, "b/b.go"), []byte("package b\n\nimport \"example.com/c\"\n\nfunc F() *c.T { return nil }\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := packages.Reload(cfg, pkgs, "b/b.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(reloaded) > 0 {
+		t.Fatal("reloaded packages contain errors")
+	}
+	a2 := reloaded[0]
+	b2 := a2.Imports["example.com/b"]
+	if a2 == a || b2 == b {
+		t.Errorf("Reload did not reload the affected packages a and b")
+	}
+	if got := a2.Types.Scope().Lookup("A").Type().String(); got != "*example.com/c.T" {
+		t.Errorf("type of reloaded a.A is %s, want *example.com/c.T", got)
+	}
+	if b2.Imports["example.com/c"] != c {
+		t.Errorf("Reload did not reuse the unaffected package c")
+	}
+
+	// a cannot import c directly, since c lacks complete types.
+	if err := os.WriteFile(filepath.Join(dir, "a/a.go"), []byte("package a\n\nimport \"example.com/c\"\n\nvar A c.T\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err = packages.Reload(cfg, reloaded, "a/a.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, err := range reloaded[0].Errors {
+		msgs = append(msgs, err.Msg)
+	}
+	if got := strings.Join(msgs, "\n"); !strings.Contains(got, "not loaded with complete types") {
+		t.Errorf("reloaded a has errors %q, want an error about the types of c", got)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Reload reloads the packages affected by changes to the specified
+// files since the initial packages were loaded by a call to [Load] with
+// the same configuration, and returns the counterparts of the initial
+// packages, in the same order. It is intended for long-running tools
+// that would otherwise reload all their packages after each change.
+//
+// The changed files are those whose content has changed, and those
+// that were created or deleted. Relative file names are interpreted
+// relative to cfg.Dir. A package is affected if it contains a changed
+// file, if it is in the directory of a changed Go file, or if it
+// imports an affected package, directly or indirectly. Only the
+// affected packages are queried anew and loaded as requested by
+// cfg.Mode; in particular, only they are parsed and type-checked.
+// Unaffected packages are reused: the results share the [Package]
+// values of unaffected packages, and their types, with the initial
+// packages, and the reloaded packages use their file set.
+//
+// Unlike Load, Reload does not discover packages that did not exist
+// before, such as a new package that the patterns of the original
+// call would match. An initial package that no longer exists is
+// omitted from the result. Packages named by the files they contain
+// (see [Load]) cannot be reloaded.
+//
+// Reload requires that cfg.Mode include NeedName, NeedFiles, and
+// NeedImports, which it uses to determine the affected packages, and
+// NeedTypes if it includes NeedTypesInfo, since the reloaded packages
+// are type-checked against the types of the reused ones. Without
+// NeedDeps, the reused packages that the initial packages import only
+// indirectly have incomplete types: a reloaded package that imports
+// one of them directly reports an error.
+func Reload(cfg *Config, initial []*Package, changed ...string) ([]*Package, error) {
+	ld := newLoader(cfg)
+	const required = NeedName | NeedFiles | NeedImports
+	if ld.requestedMode&required != required {
+		return nil, fmt.Errorf("packages.Reload requires NeedName, NeedFiles, and NeedImports")
+	}
+	if ld.requestedMode&NeedTypesInfo != 0 && ld.requestedMode&NeedTypes == 0 {
+		return nil, fmt.Errorf("packages.Reload requires NeedTypes with NeedTypesInfo")
+	}
+
+	// Index the changed files, and the directories of changed Go
+	// files, whose packages may have gained or lost a file.
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, name := range changed {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(ld.Dir, name)
+		}
+		name = filepath.Clean(name)
+		files[name] = true
+		if strings.HasSuffix(name, ".go") {
+			dirs[filepath.Dir(name)] = true
+		}
+	}
+	containsChange := func(pkg *Package) bool {
+		for _, list := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.IgnoredFiles} {
+			for _, f := range list {
+				if files[f] || dirs[filepath.Dir(f)] {
+					return true
+				}
+			}
+		}
+		for _, list := range [][]string{pkg.OtherFiles, pkg.EmbedFiles} {
+			for _, f := range list {
+				if files[f] {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// Find the affected packages: those containing a change,
+	// and their reverse dependencies.
+	var (
+		preds    = make(map[*Package][]*Package) // packages that import each one
+		affected = make(map[*Package]bool)
+		queue    []*Package
+	)
+	Visit(initial, nil, func(pkg *Package) {
+		for _, imp := range pkg.Imports {
+			preds[imp] = append(preds[imp], pkg)
+		}
+		if containsChange(pkg) {
+			affected[pkg] = true
+			queue = append(queue, pkg)
+		}
+	})
+	for len(queue) > 0 {
+		pkg := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, pred := range preds[pkg] {
+			if !affected[pred] {
+				affected[pred] = true
+				queue = append(queue, pred)
+			}
+		}
+	}
+	if len(affected) == 0 {
+		return slices.Clone(initial), nil
+	}
+
+	// Reuse the unaffected packages, and their file set.
+	ld.reused = make(map[string]*Package)
+	for pkg := range preds {
+		if !affected[pkg] {
+			ld.reused[pkg.ID] = pkg
+		}
+	}
+	for _, pkg := range initial {
+		if !affected[pkg] {
+			ld.reused[pkg.ID] = pkg
+		}
+		if pkg.Fset != nil {
+			ld.Fset = pkg.Fset
+		}
+	}
+
+	// Query the affected packages by their package paths, which also
+	// yields their test variants, if requested.
+	var patterns []string
+	for pkg := range affected {
+		path := pkg.PkgPath
+		if i := strings.Index(pkg.ID, " ["); i >= 0 {
+			// A test variant, "p [p.test]" or "p_test [p.test]".
+			path = strings.TrimSuffix(strings.TrimSuffix(pkg.ID[i+len(" ["):], "]"), ".test")
+		} else if pkg.Name == "main" && strings.HasSuffix(path, ".test") {
+			path = strings.TrimSuffix(path, ".test") // a test executable
+		}
+		if path == "command-line-arguments" {
+			return nil, fmt.Errorf("cannot reload package %s, which is named by its files", pkg.ID)
+		}
+		patterns = append(patterns, path)
+	}
+	slices.Sort(patterns)
+	patterns = slices.Compact(patterns)
+
+	response, external, err := defaultDriver(&ld.Config, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ld.setSizes(response, external); err != nil {
+		return nil, err
+	}
+
+	// The roots are the affected initial packages that still exist.
+	ids := make(map[string]bool)
+	for _, pkg := range response.Packages {
+		ids[pkg.ID] = true
+	}
+	response.Roots = nil
+	for _, pkg := range initial {
+		if affected[pkg] && ids[pkg.ID] {
+			response.Roots = append(response.Roots, pkg.ID)
+		}
+	}
+	roots, err := ld.refine(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Package
+	for _, pkg := range initial {
+		if !affected[pkg] {
+			result = append(result, pkg)
+		} else if ids[pkg.ID] {
+			result = append(result, roots[0])
+			roots = roots[1:]
+		}
+	}
+	return result, nil
+}
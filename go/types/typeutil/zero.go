@@ -0,0 +1,254 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typeutil
+
+// This file defines utilities for tools that generate Go code
+// containing values of a given type.
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// A ValueConfig configures the expressions formed by [ZeroValue] and
+// [DefaultValue]. The zero value is ready to use.
+type ValueConfig struct {
+	// Package is the package in which the expression will appear.
+	// A type declared unexported by another package cannot be named
+	// there, so its composite literals are avoided: references to an
+	// alias of such a type are replaced by its target, and the zero
+	// value of such a struct or array type is reported as invalid.
+	// If Package is nil, all types are assumed to be accessible.
+	Package *types.Package
+
+	// Qualifier controls how references to package-level names are
+	// qualified, as by [types.TypeString]. If nil, names are
+	// qualified relative to Package, as by [types.RelativeTo].
+	//
+	// The qualifier may be used to record the imports required by
+	// the expression; it is called only for packages that a valid
+	// expression refers to.
+	Qualifier types.Qualifier
+
+	// EmptyLiterals causes DefaultValue to express values of slice
+	// and map types as empty composite literals, such as []T{},
+	// rather than nil.
+	EmptyLiterals bool
+}
+
+// ZeroValue returns the source text of an expression that denotes
+// the zero value of type t, for use where the expected type is t,
+// such as the right-hand side of an assignment to a variable of
+// type t. The result reports whether the expression is valid; it is
+// false if t is or contains an invalid type or a non-basic (constraint)
+// interface, or if it is a struct or array type that cannot be named
+// in cfg.Package. Even then, the expression may be partially correct.
+//
+// The zero value of a type parameter T is expressed as *new(T), which
+// assumes that the built-in new function is not shadowed.
+//
+// A nil cfg is equivalent to a pointer to a zero ValueConfig.
+// ZeroValue panics if t is a [types.Tuple] or [types.Union], which are
+// not the types of variables.
+func ZeroValue(t types.Type, cfg *ValueConfig) (expr string, ok bool) {
+	if cfg == nil {
+		cfg = new(ValueConfig)
+	}
+	return cfg.zero(t)
+}
+
+// DefaultValue is like [ZeroValue] but returns a more useful value for
+// some types: a context.Context is expressed as context.Background(),
+// and, if cfg.EmptyLiterals is set, a slice or map as an empty
+// composite literal. It is intended for generators of code, such as
+// tests, that supply arguments to functions.
+func DefaultValue(t types.Type, cfg *ValueConfig) (expr string, ok bool) {
+	if cfg == nil {
+		cfg = new(ValueConfig)
+	}
+	if isContext(t) {
+		return cfg.qualify(types.NewPackage("context", "context")) + "Background()", true
+	}
+	if cfg.EmptyLiterals {
+		switch types.Unalias(t).Underlying().(type) {
+		case *types.Slice, *types.Map:
+			if _, isParam := types.Unalias(t).(*types.TypeParam); !isParam && cfg.nameable(t) {
+				return cfg.literal(t)
+			}
+		}
+	}
+	return cfg.zero(t)
+}
+
+func (cfg *ValueConfig) zero(t types.Type) (string, bool) {
+	switch t := t.(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsBoolean != 0:
+			return "false", true
+		case t.Info()&types.IsNumeric != 0:
+			return "0", true
+		case t.Info()&types.IsString != 0:
+			return `""`, true
+		case t.Kind() == types.UnsafePointer, t.Kind() == types.UntypedNil:
+			return "nil", true
+		case t.Kind() == types.Invalid:
+			return "invalid", false
+		default:
+			panic(fmt.Sprintf("ZeroValue for unexpected type %v", t))
+		}
+
+	case *types.Pointer, *types.Slice, *types.Chan, *types.Map, *types.Signature:
+		return "nil", true
+
+	case *types.Interface:
+		if !t.IsMethodSet() {
+			return "invalid", false
+		}
+		return "nil", true
+
+	case *types.Alias:
+		if !cfg.accessible(t.Obj()) {
+			// The alias cannot be named; try its target.
+			return cfg.zero(t.Rhs())
+		}
+		switch t.Underlying().(type) {
+		case *types.Struct, *types.Array:
+			return cfg.literal(t)
+		default:
+			// Use Unalias, not Underlying, to preserve type parameters.
+			return cfg.zero(types.Unalias(t))
+		}
+
+	case *types.Named:
+		switch under := t.Underlying().(type) {
+		case *types.Struct, *types.Array:
+			return cfg.literal(t)
+		default:
+			// An untyped zero value is assignable to t
+			// even if t cannot be named.
+			return cfg.zero(under)
+		}
+
+	case *types.Array, *types.Struct:
+		return cfg.literal(t)
+
+	case *types.TypeParam:
+		return "*new(" + cfg.typeString(t) + ")", true
+
+	case *types.Tuple, *types.Union:
+		panic(fmt.Sprintf("invalid type for a variable: %v", t))
+
+	default:
+		panic(t) // unreachable
+	}
+}
+
+// literal returns an empty composite literal of type t, and
+// reports whether t can be named.
+func (cfg *ValueConfig) literal(t types.Type) (string, bool) {
+	if !cfg.nameable(t) {
+		// Don't call the qualifier for an invalid expression.
+		return types.TypeString(t, types.RelativeTo(cfg.Package)) + "{}", false
+	}
+	return cfg.typeString(t) + "{}", true
+}
+
+// accessible reports whether the type name obj may be referred to
+// from cfg.Package.
+func (cfg *ValueConfig) accessible(obj *types.TypeName) bool {
+	return cfg.Package == nil || obj.Pkg() == nil || obj.Pkg() == cfg.Package || obj.Exported()
+}
+
+// nameable reports whether all the named types in the syntax of t
+// may be referred to from cfg.Package.
+func (cfg *ValueConfig) nameable(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Alias:
+		return cfg.accessible(t.Obj()) && cfg.nameableList(t.TypeArgs())
+	case *types.Named:
+		return cfg.accessible(t.Obj()) && cfg.nameableList(t.TypeArgs())
+	case *types.Pointer:
+		return cfg.nameable(t.Elem())
+	case *types.Slice:
+		return cfg.nameable(t.Elem())
+	case *types.Array:
+		return cfg.nameable(t.Elem())
+	case *types.Chan:
+		return cfg.nameable(t.Elem())
+	case *types.Map:
+		return cfg.nameable(t.Key()) && cfg.nameable(t.Elem())
+	case *types.Signature:
+		return cfg.nameableTuple(t.Params()) && cfg.nameableTuple(t.Results())
+	case *types.Struct:
+		for i := range t.NumFields() {
+			// A struct type with an unexported field of
+			// another package is distinct from any that
+			// cfg.Package could write.
+			f := t.Field(i)
+			if cfg.Package != nil && !f.Exported() && f.Pkg() != cfg.Package || !cfg.nameable(f.Type()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (cfg *ValueConfig) nameableList(list *types.TypeList) bool {
+	for i := range list.Len() {
+		if !cfg.nameable(list.At(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cfg *ValueConfig) nameableTuple(tuple *types.Tuple) bool {
+	for i := range tuple.Len() {
+		if !cfg.nameable(tuple.At(i).Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeString returns the qualified syntax of t.
+func (cfg *ValueConfig) typeString(t types.Type) string {
+	return types.TypeString(t, cfg.qualifier())
+}
+
+// qualify returns the prefix, such as "pkg.", of references to
+// package-level names of pkg.
+func (cfg *ValueConfig) qualify(pkg *types.Package) string {
+	if cfg.Package != nil && pkg.Path() == cfg.Package.Path() {
+		return ""
+	}
+	// A nil qualifier qualifies by package path, as in TypeString.
+	name := pkg.Path()
+	if qual := cfg.qualifier(); qual != nil {
+		name = qual(pkg)
+	}
+	if name != "" {
+		return name + "."
+	}
+	return ""
+}
+
+func (cfg *ValueConfig) qualifier() types.Qualifier {
+	if cfg.Qualifier != nil {
+		return cfg.Qualifier
+	}
+	return types.RelativeTo(cfg.Package)
+}
+
+// isContext reports whether t is context.Context.
+func isContext(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typeutil_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestZeroValue(t *testing.T) {
+	testenv.NeedsGoBuild(t) // for importer.Default()
+
+	const (
+		psrc = `package p
+
+type (
+	S    struct{ x int }
+	t    struct{ x int }
+	A    = t
+	u    = S
+	I    int
+	i    int
+	L    []int
+	G[T any] struct{ x T }
+)
+
+func F() (t, i) { return t{}, 0 }
+
+var Anon struct{ x int }
+`
+		qsrc = `package q
+
+import (
+	"context"
+	"example.com/p"
+)
+
+type local struct{}
+
+var (
+	s   p.S
+	a   p.A
+	g   p.G[local]
+	n   p.I
+	ctx context.Context
+	l   p.L
+	m   map[string]int
+	arr [2]int
+	err error
+)
+
+var t, i = p.F()
+
+var anon = p.Anon
+
+func Generic[T any, M ~map[string]int](x T, y M) {}
+`
+	)
+	fset := token.NewFileSet()
+	imp := importer.Default()
+	pkgs := make(map[string]*types.Package)
+	check := func(path, src string) *types.Package {
+		f, err := parser.ParseFile(fset, path+".go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+			if pkg, ok := pkgs[path]; ok {
+				return pkg, nil
+			}
+			return imp.Import(path)
+		})}
+		pkg, err := conf.Check(path, fset, []*ast.File{f}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkgs[path] = pkg
+		return pkg
+	}
+	check("example.com/p", psrc)
+	q := check("example.com/q", qsrc)
+
+	// lookup returns the type of the named package-level object of q,
+	// or of the parameter of Generic.
+	lookup := func(name string) types.Type {
+		if obj := q.Scope().Lookup(name); obj != nil {
+			return obj.Type()
+		}
+		params := q.Scope().Lookup("Generic").Type().(*types.Signature).Params()
+		for i := range params.Len() {
+			if params.At(i).Name() == name {
+				return params.At(i).Type()
+			}
+		}
+		t.Fatalf("no object %s", name)
+		return nil
+	}
+
+	for _, test := range []struct {
+		name        string
+		zero        string
+		zeroOK      bool
+		def         string // with EmptyLiterals
+		defOK       bool
+		wantImports []string
+	}{
+		{"s", "p.S{}", true, "p.S{}", true, []string{"example.com/p"}},
+		{"a", "p.A{}", true, "p.A{}", true, []string{"example.com/p"}},
+		{"g", "p.G[local]{}", true, "p.G[local]{}", true, []string{"example.com/p"}},
+		{"n", "0", true, "0", true, nil},
+		{"ctx", "nil", true, "context.Background()", true, []string{"context"}},
+		{"l", "nil", true, "p.L{}", true, []string{"example.com/p"}},
+		{"m", "nil", true, "map[string]int{}", true, nil},
+		{"arr", "[2]int{}", true, "[2]int{}", true, nil},
+		{"err", "nil", true, "nil", true, nil},
+		{"t", "example.com/p.t{}", false, "example.com/p.t{}", false, nil}, // unexported struct type
+		{"i", "0", true, "0", true, nil},                                   // unexported non-struct type
+		{"anon", "struct{x int}{}", false, "struct{x int}{}", false, nil},  // unexported field of p
+		{"x", "*new(T)", true, "*new(T)", true, nil},
+		{"y", "*new(M)", true, "*new(M)", true, nil},
+	} {
+		var imports []string
+		cfg := &typeutil.ValueConfig{
+			Package: q,
+			Qualifier: func(pkg *types.Package) string {
+				if pkg == q {
+					return ""
+				}
+				imports = append(imports, pkg.Path())
+				return pkg.Name()
+			},
+		}
+		typ := lookup(test.name)
+		if got, ok := typeutil.ZeroValue(typ, cfg); got != test.zero || ok != test.zeroOK {
+			t.Errorf("ZeroValue(%s) = (%s, %t), want (%s, %t)", typ, got, ok, test.zero, test.zeroOK)
+		}
+		imports = nil
+		cfg.EmptyLiterals = true
+		if got, ok := typeutil.DefaultValue(typ, cfg); got != test.def || ok != test.defOK {
+			t.Errorf("DefaultValue(%s) = (%s, %t), want (%s, %t)", typ, got, ok, test.def, test.defOK)
+		}
+		if len(imports) != len(test.wantImports) || len(imports) > 0 && imports[0] != test.wantImports[0] {
+			t.Errorf("DefaultValue(%s) qualified packages %v, want %v", typ, imports, test.wantImports)
+		}
+	}
+
+	// An alias of an unexported type is replaced by its target.
+	u := pkgs["example.com/p"].Scope().Lookup("u").Type()
+	if got, ok := typeutil.ZeroValue(u, &typeutil.ValueConfig{Package: q}); got != "example.com/p.S{}" || !ok {
+		t.Errorf("ZeroValue(%s) = (%s, %t), want (example.com/p.S{}, true)", u, got, ok)
+	}
+
+	// A nil config assumes all types are accessible.
+	typ := lookup("t")
+	if got, ok := typeutil.ZeroValue(typ, nil); got != "example.com/p.t{}" || !ok {
+		t.Errorf("ZeroValue(%s, nil) = (%s, %t), want (example.com/p.t{}, true)", typ, got, ok)
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
@@ -0,0 +1,79 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package contextfield
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/astutil/edge"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "contextfield",
+	Doc:      analysisinternal.MustExtractDoc(doc, "contextfield"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/contextfield",
+}
+
+const FixCategory = "contextfield" // recognized by gopls ApplyFix
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	for curStruct := range cursor.Root(inspect).Preorder((*ast.StructType)(nil)) {
+		// Only the fields of a package-level named struct type have
+		// methods to which the fix can add a parameter.
+		named := false
+		if ek, _ := curStruct.Edge(); ek == edge.TypeSpec_Type {
+			curSpec := curStruct.Parent()
+			_, pkgLevel := curSpec.Parent().Parent().Node().(*ast.File)
+			named = pkgLevel && curSpec.Node().(*ast.TypeSpec).Assign == 0
+		}
+
+		for _, field := range curStruct.Node().(*ast.StructType).Fields.List {
+			if !analysisinternal.IsTypeNamed(pass.TypesInfo.TypeOf(field.Type), "context", "Context") {
+				continue
+			}
+			if len(field.Names) == 0 {
+				pass.Report(analysis.Diagnostic{
+					Pos:     field.Type.Pos(),
+					End:     field.Type.End(),
+					Message: "context.Context should not be embedded in a struct type",
+				})
+				continue
+			}
+			for _, id := range field.Names {
+				if id.Name == "_" {
+					continue
+				}
+				diag := analysis.Diagnostic{
+					Pos:     id.Pos(),
+					End:     id.End(),
+					Message: fmt.Sprintf("context.Context should not be stored in field %s; pass it as a parameter", id.Name),
+				}
+				if named {
+					diag.Category = FixCategory
+					diag.SuggestedFixes = []analysis.SuggestedFix{{
+						Message: fmt.Sprintf("Add ctx parameter to methods that use %s", id.Name),
+						// No TextEdits => computed by gopls ApplyFix.
+					}}
+				}
+				pass.Report(diag)
+			}
+		}
+	}
+	return nil, nil
+}
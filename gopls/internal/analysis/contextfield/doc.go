@@ -0,0 +1,36 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package contextfield defines an analyzer that reports struct fields
+// of type context.Context.
+//
+// # Analyzer contextfield
+//
+// contextfield: report struct fields of type context.Context
+//
+// The documentation of the context package advises:
+//
+//	Do not store Contexts inside a struct type; instead, pass a
+//	Context explicitly to each function that needs it.
+//
+// The contextfield analyzer reports each field of a struct type whose
+// type is context.Context, as in:
+//
+//	type Server struct {
+//		ctx context.Context
+//		...
+//	}
+//
+// A context stored in a struct outlives the operation it was created
+// for, so that its cancellation and deadline no longer apply to the
+// operations that use it.
+//
+// For a named field of a named struct type T, a suggested fix adds a
+// parameter ctx of type context.Context to each method of T that reads
+// the field through its receiver, replaces those reads by ctx, and
+// passes a context to each call of the methods, as the "Add
+// context.Context parameter" refactoring does. A method that already
+// has a context.Context parameter uses it instead. The field itself
+// is left in place, since it may be set elsewhere.
+package contextfield
@@ -0,0 +1,36 @@
+package a
+
+import (
+	"context"
+	ctxpkg "context"
+)
+
+type Server struct {
+	ctx  context.Context // want `context.Context should not be stored in field ctx; pass it as a parameter`
+	name string
+}
+
+type Client struct {
+	parent, child ctxpkg.Context // want `field parent` `field child`
+	_             context.Context
+}
+
+type Embedded struct {
+	context.Context // want `context.Context should not be embedded in a struct type`
+}
+
+type Alias = struct {
+	ctx context.Context // want `field ctx`
+}
+
+var request struct {
+	ctx context.Context // want `field ctx`
+}
+
+type Context interface{ Done() <-chan struct{} }
+
+type Other struct {
+	ctx Context // not context.Context
+}
+
+func serve(ctx context.Context, s *Server) {}
@@ -0,0 +1,294 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package deferclose
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/astutil/edge"
+	"golang.org/x/tools/internal/versions"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "deferclose",
+	Doc:      analysisinternal.MustExtractDoc(doc, "deferclose"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/deferclose",
+}
+
+var readonly = stringSetFlag{"net.Conn": true, "*net.TCPConn": true, "*net.UnixConn": true}
+
+func init() {
+	Analyzer.Flags.Var(&readonly, "readonly",
+		"comma-separated list of types, such as *os.File, whose Close errors may be ignored")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	info := pass.TypesInfo
+
+	for curDefer := range cursor.Root(inspect).Preorder((*ast.DeferStmt)(nil)) {
+		call := curDefer.Node().(*ast.DeferStmt).Call
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok || len(call.Args) > 0 {
+			continue
+		}
+		fn, ok := typeutil.Callee(info, call).(*types.Func)
+		if !ok || fn.Name() != "Close" && fn.Name() != "Flush" || !returnsError(fn.Signature()) {
+			continue
+		}
+		t := info.TypeOf(sel.X)
+		if t == nil || !isWritable(t) ||
+			readonly[types.TypeString(t, (*types.Package).Path)] ||
+			isOpenedReadOnly(info, curDefer, sel.X) {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:            call.Pos(),
+			End:            call.End(),
+			Message:        fmt.Sprintf("deferred call to %s ignores its error", fn.FullName()),
+			SuggestedFixes: joinErrorFix(pass, curDefer),
+		})
+	}
+	return nil, nil
+}
+
+// returnsError reports whether sig has no parameters and a single
+// result of type error.
+func returnsError(sig *types.Signature) bool {
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 &&
+		types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+// isWritable reports whether t has a method Write([]byte) (int, error).
+func isWritable(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "Write")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Signature()
+	if sig.Params().Len() != 1 || sig.Results().Len() != 2 {
+		return false
+	}
+	slice, ok := sig.Params().At(0).Type().(*types.Slice)
+	return ok && types.Identical(slice.Elem(), types.Typ[types.Byte]) &&
+		types.Identical(sig.Results().At(0).Type(), types.Typ[types.Int]) &&
+		types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type())
+}
+
+// isOpenedReadOnly reports whether x is a variable whose declaration,
+// within the function enclosing the defer statement at curDefer,
+// assigns it the result of a call to os.Open.
+func isOpenedReadOnly(info *types.Info, curDefer cursor.Cursor, x ast.Expr) bool {
+	id, ok := ast.Unparen(x).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	v, ok := info.Uses[id].(*types.Var)
+	if !ok {
+		return false
+	}
+	curFunc, ok := enclosingFunc(curDefer)
+	if !ok {
+		return false
+	}
+	for curAssign := range curFunc.Preorder((*ast.AssignStmt)(nil)) {
+		assign := curAssign.Node().(*ast.AssignStmt)
+		if len(assign.Rhs) != 1 {
+			continue
+		}
+		for _, lhs := range assign.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && info.Defs[id] == v {
+				call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+				return ok && analysisinternal.IsFunctionNamed(typeutil.Callee(info, call), "os", "Open")
+			}
+		}
+	}
+	return false
+}
+
+// enclosingFunc returns the cursor for the function declaration or
+// literal that encloses cur.
+func enclosingFunc(cur cursor.Cursor) (cursor.Cursor, bool) {
+	for curFunc := range cur.Ancestors((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+		return curFunc, true
+	}
+	return cursor.Cursor{}, false
+}
+
+// joinErrorFix returns the fix that replaces the defer statement at
+// curDefer, of the form "defer x.Close()", by
+//
+//	defer func() { err = errors.Join(err, x.Close()) }()
+//
+// where err is the error result of the enclosing function, named if
+// necessary. It returns no fix if the function has no error result,
+// if x is not a variable that keeps its value for the rest of the
+// function, or if errors.Join is not available.
+func joinErrorFix(pass *analysis.Pass, curDefer cursor.Cursor) []analysis.SuggestedFix {
+	info := pass.TypesInfo
+	deferStmt := curDefer.Node().(*ast.DeferStmt)
+	curFunc, ok := enclosingFunc(curDefer)
+	if !ok {
+		return nil
+	}
+	var ftype *ast.FuncType
+	switch fn := curFunc.Node().(type) {
+	case *ast.FuncDecl:
+		ftype = fn.Type
+	case *ast.FuncLit:
+		ftype = fn.Type
+	}
+	var file *ast.File
+	for curFile := range curDefer.Ancestors((*ast.File)(nil)) {
+		file = curFile.Node().(*ast.File)
+	}
+	if !versions.AtLeast(versions.FileVersion(info, file), versions.Go1_20) {
+		return nil // no errors.Join
+	}
+
+	// The function's last result must be an error.
+	results := ftype.Results
+	if results == nil {
+		return nil
+	}
+	last := results.List[len(results.List)-1]
+	if !types.Identical(info.TypeOf(last.Type), types.Universe.Lookup("error").Type()) {
+		return nil
+	}
+
+	// The receiver must be a variable that is not assigned after
+	// the defer statement, since the deferred function literal
+	// evaluates it when the function returns.
+	sel := ast.Unparen(deferStmt.Call.Fun).(*ast.SelectorExpr)
+	id, ok := ast.Unparen(sel.X).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := info.Uses[id].(*types.Var)
+	if !ok || v.Parent() == pass.Pkg.Scope() {
+		return nil
+	}
+	for curId := range curFunc.Preorder((*ast.Ident)(nil)) {
+		if n := curId.Node(); n.Pos() < deferStmt.End() || info.Uses[n.(*ast.Ident)] != v {
+			continue
+		}
+		switch ek, _ := curId.Edge(); ek {
+		case edge.AssignStmt_Lhs, edge.IncDecStmt_X, edge.RangeStmt_Key, edge.RangeStmt_Value:
+			return nil
+		case edge.UnaryExpr_X:
+			if curId.Parent().Node().(*ast.UnaryExpr).Op == token.AND {
+				return nil
+			}
+		}
+	}
+
+	// Name the error result, if necessary, with a name that is not
+	// used elsewhere in the function.
+	var (
+		edits []analysis.TextEdit
+		name  string
+	)
+	if len(last.Names) > 0 && last.Names[len(last.Names)-1].Name != "_" {
+		res := last.Names[len(last.Names)-1]
+		name = res.Name
+		scope := info.Scopes[ftype].Innermost(deferStmt.Pos())
+		if _, obj := scope.LookupParent(name, deferStmt.Pos()); obj != info.Defs[res] {
+			return nil // shadowed
+		}
+	} else {
+		for _, candidate := range []string{"err", "retErr"} {
+			if !usesName(curFunc.Node(), candidate) {
+				name = candidate
+				break
+			}
+		}
+		if name == "" {
+			return nil
+		}
+		switch {
+		case len(last.Names) > 0: // _
+			res := last.Names[len(last.Names)-1]
+			edits = append(edits, analysis.TextEdit{Pos: res.Pos(), End: res.End(), NewText: []byte(name)})
+		case !results.Opening.IsValid(): // error
+			edits = append(edits,
+				analysis.TextEdit{Pos: last.Type.Pos(), End: last.Type.Pos(), NewText: []byte("(" + name + " ")},
+				analysis.TextEdit{Pos: last.Type.End(), End: last.Type.End(), NewText: []byte(")")})
+		default: // (T, error)
+			for _, field := range results.List {
+				text := "_ "
+				if field == last {
+					text = name + " "
+				}
+				edits = append(edits, analysis.TextEdit{Pos: field.Type.Pos(), End: field.Type.Pos(), NewText: []byte(text)})
+			}
+		}
+	}
+
+	_, prefix, importEdits := analysisinternal.AddImport(info, file, "errors", "errors", "Join", deferStmt.Pos())
+	edits = append(edits, importEdits...)
+	edits = append(edits, analysis.TextEdit{
+		Pos: deferStmt.Pos(),
+		End: deferStmt.End(),
+		NewText: fmt.Appendf(nil, "defer func() { %s = %sJoin(%s, %s) }()",
+			name, prefix, name, analysisinternal.Format(pass.Fset, deferStmt.Call)),
+	})
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("Join error from deferred %s with result %s", sel.Sel.Name, name),
+		TextEdits: edits,
+	}}
+}
+
+// usesName reports whether name appears as an identifier within n.
+func usesName(n ast.Node, name string) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// stringSetFlag is a set of strings, set from a comma-separated list.
+type stringSetFlag map[string]bool
+
+func (ss *stringSetFlag) String() string {
+	var items []string
+	for item := range *ss {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func (ss *stringSetFlag) Set(s string) error {
+	m := make(map[string]bool) // clobber previous value
+	for _, name := range strings.Split(s, ",") {
+		if name != "" {
+			m[name] = true
+		}
+	}
+	*ss = m
+	return nil
+}
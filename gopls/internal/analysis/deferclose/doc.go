@@ -0,0 +1,39 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package deferclose defines an analyzer that reports deferred calls
+// to Close or Flush methods of writable resources whose errors are
+// ignored.
+//
+// # Analyzer deferclose
+//
+// deferclose: report ignored errors from deferred Close and Flush calls
+//
+// The deferclose analyzer reports defer statements that call the
+// Close or Flush method of a value that has a Write method, such as
+// an *os.File or a *bufio.Writer, as in:
+//
+//	f, err := os.Create(name)
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close()
+//
+// The error returned by such a call is discarded, yet for a writable
+// resource it may be the only report that data was not written, for
+// example when a file system flushes buffered data on Close.
+//
+// If the enclosing function returns an error as its last result, the
+// suggested fix names that result, if necessary, and joins the error
+// of the deferred call to it:
+//
+//	func save(name string) (err error) {
+//		...
+//		defer func() { err = errors.Join(err, f.Close()) }()
+//
+// Resources that are opened read-only by a call to os.Open are not
+// reported. Nor are values of the types listed by the -readonly flag,
+// a comma-separated list of types such as "*os.File" or "net.Conn"
+// whose Close errors may be ignored; by default, network connections.
+package deferclose
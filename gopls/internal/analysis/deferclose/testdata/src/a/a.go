@@ -0,0 +1,86 @@
+package a
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+)
+
+func create(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // want `deferred call to \(\*os.File\).Close ignores its error`
+	_, err = f.WriteString("hello")
+	return err
+}
+
+func named(name string) (n int, err error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close() // want `ignores its error`
+	return f.WriteString("hello")
+}
+
+func buffered(w io.Writer) (int, error) {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush() // want `deferred call to \(\*bufio.Writer\).Flush ignores its error`
+	return bw.WriteString("hello")
+}
+
+func blank(wc io.WriteCloser) (_ error) {
+	defer wc.Close() // want `deferred call to \(io.Closer\).Close ignores its error`
+	return nil
+}
+
+func conflict(wc io.WriteCloser) error {
+	err := error(nil)
+	defer wc.Close() // want `ignores its error`
+	return err
+}
+
+func noError(wc io.WriteCloser) {
+	defer wc.Close() // want `ignores its error`
+}
+
+func reassigned(name string) error {
+	f, _ := os.Create(name)
+	defer f.Close() // want `ignores its error`
+	f = nil
+	return nil
+}
+
+func lit() {
+	_ = func(wc io.WriteCloser) error {
+		defer wc.Close() // want `ignores its error`
+		return nil
+	}
+}
+
+func readOnly(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // opened read-only
+	return nil
+}
+
+func reader(rc io.ReadCloser) error {
+	defer rc.Close() // not writable
+	return nil
+}
+
+func conn(c net.Conn) error {
+	defer c.Close() // read-only by -readonly flag
+	return nil
+}
+
+func handled(wc io.WriteCloser) error {
+	defer func() { _ = wc.Close() }()
+	return nil
+}
@@ -6,10 +6,13 @@ package deprecated
 
 import (
 	"bytes"
+	"fmt"
 	"go/ast"
 	"go/format"
 	"go/token"
 	"go/types"
+	"path"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -18,6 +21,7 @@ import (
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
 	"golang.org/x/tools/internal/analysisinternal"
 	internalastutil "golang.org/x/tools/internal/astutil"
 )
@@ -55,7 +59,17 @@ func checkDeprecated(pass *analysis.Pass) (any, error) {
 			buf.Reset()
 			buf.WriteString("declaration")
 		}
-		pass.ReportRangef(node, "%s is deprecated: %s", buf, depr.Msg)
+		diag := analysis.Diagnostic{
+			Pos:     node.Pos(),
+			End:     node.End(),
+			Message: fmt.Sprintf("%s is deprecated: %s", buf, depr.Msg),
+		}
+		if sel, ok := node.(*ast.SelectorExpr); ok && depr.Name != "" {
+			if fix := replaceDeprecated(pass, sel, depr); fix != nil {
+				diag.SuggestedFixes = []analysis.SuggestedFix{*fix}
+			}
+		}
+		pass.Report(diag)
 	}
 
 	nodeFilter := []ast.Node{(*ast.SelectorExpr)(nil)}
@@ -142,7 +156,14 @@ func checkDeprecated(pass *analysis.Pass) (any, error) {
 	return nil, nil
 }
 
-type deprecationFact struct{ Msg string }
+// A deprecationFact records the deprecation message of an object or
+// package and, if the message names a replacement for the object, as
+// in "Use X instead", the package and name of that replacement.
+type deprecationFact struct {
+	Msg string
+
+	PkgPath, PkgName, Name string // replacement, if any
+}
 
 func (*deprecationFact) AFact()           {}
 func (d *deprecationFact) String() string { return "Deprecated: " + d.Msg }
@@ -164,7 +185,11 @@ func collectDeprecatedNames(pass *analysis.Pass, ins *inspector.Inspector) (depr
 
 		for _, name := range names {
 			obj := pass.TypesInfo.ObjectOf(name)
-			pass.ExportObjectFact(obj, &deprecationFact{alt})
+			fact := &deprecationFact{Msg: alt}
+			if repl := findReplacement(pass, obj, alt); repl != nil {
+				fact.PkgPath, fact.PkgName, fact.Name = repl.Pkg().Path(), repl.Pkg().Name(), repl.Name()
+			}
+			pass.ExportObjectFact(obj, fact)
 		}
 	}
 
@@ -177,7 +202,7 @@ func collectDeprecatedNames(pass *analysis.Pass, ins *inspector.Inspector) (depr
 	if pass.Pkg.Path() != "syscall" {
 		for _, f := range pass.Files {
 			if depr := internalastutil.Deprecation(f.Doc); depr != "" {
-				pass.ExportPackageFact(&deprecationFact{depr})
+				pass.ExportPackageFact(&deprecationFact{Msg: depr})
 				break
 			}
 		}
@@ -250,3 +275,195 @@ func collectDeprecatedNames(pass *analysis.Pass, ins *inspector.Inspector) (depr
 
 	return out, nil
 }
+
+// useRx matches a reference to a replacement in a deprecation
+// message, such as "Use X instead" or "use [pkg.X]".
+var useRx = regexp.MustCompile(`\b[Uu]se \[?([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)?)\]?(?:\s+instead\b|[.,;:]?(?:\s|$))`)
+
+// findReplacement returns the object named as the replacement of the
+// deprecated package-level object obj by its deprecation message msg,
+// either X or pkg.X as resolved in the file that declares obj, if it
+// can replace obj at every use: an exported type for a type; a
+// function of the same signature for a function; or a variable or
+// constant of the same type for a variable or constant.
+func findReplacement(pass *analysis.Pass, obj types.Object, msg string) types.Object {
+	if obj == nil || obj.Parent() != pass.Pkg.Scope() {
+		return nil // not package-level
+	}
+	m := useRx.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	var repl types.Object
+	if pkgName, name, ok := strings.Cut(m[1], "."); ok {
+		for _, file := range pass.Files {
+			if file.FileStart <= obj.Pos() && obj.Pos() < file.FileEnd {
+				if pkgName, ok := pass.TypesInfo.Scopes[file].Lookup(pkgName).(*types.PkgName); ok {
+					repl = pkgName.Imported().Scope().Lookup(name)
+				}
+				break
+			}
+		}
+	} else {
+		repl = pass.Pkg.Scope().Lookup(m[1])
+	}
+	if repl == nil || repl == obj || !repl.Exported() {
+		return nil
+	}
+	switch obj.(type) {
+	case *types.TypeName:
+		if _, ok := repl.(*types.TypeName); ok {
+			return repl
+		}
+	case *types.Func:
+		if _, ok := repl.(*types.Func); ok && types.Identical(obj.Type(), repl.Type()) {
+			return repl
+		}
+	case *types.Var, *types.Const:
+		switch repl.(type) {
+		case *types.Var, *types.Const:
+			if types.Identical(obj.Type(), repl.Type()) {
+				return repl
+			}
+		}
+	}
+	return nil
+}
+
+// replaceDeprecated returns a fix that replaces the qualified
+// identifier sel, a use of a deprecated object, by a reference to its
+// replacement as recorded by depr, updating the imports of the file
+// as needed. It returns nil if sel is not a qualified identifier or
+// the replacement is inaccessible.
+func replaceDeprecated(pass *analysis.Pass, sel *ast.SelectorExpr, depr *deprecationFact) *analysis.SuggestedFix {
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	oldPkg, ok := pass.TypesInfo.Uses[id].(*types.PkgName)
+	if !ok {
+		return nil
+	}
+	var file *ast.File
+	for _, f := range pass.Files {
+		if f.FileStart <= sel.Pos() && sel.Pos() < f.FileEnd {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return nil
+	}
+
+	var (
+		newText string
+		edits   []analysis.TextEdit
+	)
+	if depr.PkgPath == pass.Pkg.Path() {
+		// The replacement belongs to this package.
+		scope := pass.TypesInfo.Scopes[file].Innermost(sel.Pos())
+		if _, obj := scope.LookupParent(depr.Name, sel.Pos()); obj == nil || obj.Parent() != pass.Pkg.Scope() {
+			return nil // shadowed
+		}
+		newText = depr.Name
+	} else {
+		if !analysisinternal.CanImport(pass.Pkg.Path(), depr.PkgPath) {
+			return nil
+		}
+		var name, prefix string
+		name, prefix, edits = analysisinternal.AddImport(pass.TypesInfo, file, depr.PkgName, depr.PkgPath, depr.Name, sel.Pos())
+		newText = prefix + depr.Name
+
+		// If this is the only use of the deprecated object's
+		// package, replace its import by the new one.
+		if len(edits) > 0 && uses(pass.TypesInfo, oldPkg) == 1 {
+			if _, spec := findImport(pass.TypesInfo, file, oldPkg); spec != nil {
+				// The replaced import frees its name.
+				if _, obj := pass.TypesInfo.Scopes[file].Innermost(sel.Pos()).LookupParent(depr.PkgName, sel.Pos()); obj == oldPkg {
+					name = depr.PkgName
+					newText = name + "." + depr.Name
+				}
+				importText := strconv.Quote(depr.PkgPath)
+				if name != path.Base(depr.PkgPath) {
+					importText = name + " " + importText
+				}
+				edits = []analysis.TextEdit{{Pos: spec.Pos(), End: spec.End(), NewText: []byte(importText)}}
+			}
+		}
+	}
+
+	// Delete the import of the deprecated object's package if this
+	// is its only use in the file.
+	if len(edits) == 0 && oldPkg.Imported().Path() != depr.PkgPath && uses(pass.TypesInfo, oldPkg) == 1 {
+		edits = deleteImport(pass, file, oldPkg)
+	}
+
+	return &analysis.SuggestedFix{
+		Message: fmt.Sprintf("Replace %s.%s with %s", id.Name, sel.Sel.Name, newText),
+		TextEdits: append(edits, analysis.TextEdit{
+			Pos:     sel.Pos(),
+			End:     sel.End(),
+			NewText: []byte(newText),
+		}),
+	}
+}
+
+// uses returns the number of uses of pkgName.
+func uses(info *types.Info, pkgName *types.PkgName) int {
+	n := 0
+	for _, obj := range info.Uses {
+		if obj == pkgName {
+			n++
+		}
+	}
+	return n
+}
+
+// findImport returns the declaration and spec of the import of
+// pkgName in file.
+func findImport(info *types.Info, file *ast.File, pkgName *types.PkgName) (*ast.GenDecl, *ast.ImportSpec) {
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ImportSpec)
+			if info.PkgNameOf(spec) == pkgName {
+				return decl, spec
+			}
+		}
+	}
+	return nil, nil
+}
+
+// deleteImport returns the edits that delete the import of pkgName
+// from file, including its line, or none if the import does not
+// occupy a line of its own.
+func deleteImport(pass *analysis.Pass, file *ast.File, pkgName *types.PkgName) []analysis.TextEdit {
+	decl, spec := findImport(pass.TypesInfo, file, pkgName)
+	if spec == nil {
+		return nil
+	}
+	// Delete the declaration if it is a single import,
+	// or else the spec.
+	var node ast.Node = spec
+	if !decl.Lparen.IsValid() {
+		node = decl
+	}
+	tokFile := pass.Fset.File(file.FileStart)
+	start, end := node.Pos(), node.End()
+	if spec.Comment != nil && spec.Comment.End() > end {
+		end = spec.Comment.End()
+	}
+	line := safetoken.Line(tokFile, start)
+	if safetoken.Line(tokFile, end) != line ||
+		node == spec && (safetoken.Line(tokFile, decl.Lparen) == line || safetoken.Line(tokFile, decl.Rparen) == line) {
+		return nil
+	}
+	start = tokFile.LineStart(line)
+	if line < tokFile.LineCount() {
+		end = tokFile.LineStart(line + 1)
+	}
+	return []analysis.TextEdit{{Pos: start, End: end}}
+}
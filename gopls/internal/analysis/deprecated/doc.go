@@ -11,6 +11,12 @@
 // The deprecated analyzer looks for deprecated symbols and package
 // imports.
 //
+// When the deprecation message of a symbol names its replacement, as
+// in "Deprecated: Use X instead." or "Deprecated: use [pkg.X].", and
+// the replacement can be used wherever the symbol is, a suggested fix
+// replaces each qualified reference to the symbol by one to X,
+// updating the imports of the file as needed.
+//
 // See https://go.dev/wiki/Deprecated to learn about Go's convention
 // for documenting and signaling deprecated identifiers.
 package deprecated
@@ -0,0 +1,15 @@
+package b
+
+import (
+	"dep"
+)
+
+var (
+	_ = dep.Old("")       // want `dep.Old is deprecated: Use New instead.`
+	_ = dep.Count("", "") // want `dep.Count is deprecated: use \[strings.Count\].`
+	_ dep.OldType         // want `dep.OldType is deprecated: Use NewType.`
+	_ = dep.OldVar        // want `dep.OldVar is deprecated`
+	_ = dep.Mismatch      // want `dep.Mismatch is deprecated`
+	_ = dep.Missing       // want `dep.Missing is deprecated`
+	_ = dep.Elsewhere     // want `dep.Elsewhere is deprecated`
+)
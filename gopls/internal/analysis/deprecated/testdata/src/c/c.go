@@ -0,0 +1,5 @@
+package c
+
+import "dep"
+
+var _ = dep.Count("", "") // want `dep.Count is deprecated`
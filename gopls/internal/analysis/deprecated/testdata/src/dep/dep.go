@@ -0,0 +1,30 @@
+package dep
+
+import "strings"
+
+// Deprecated: Use New instead.
+func Old(s string) int { return 0 }
+
+func New(s string) int { return 0 }
+
+// Deprecated: use [strings.Count].
+func Count(s, substr string) int { return 0 }
+
+// Deprecated: Use NewType.
+type OldType int
+
+type NewType string
+
+// Deprecated: Use New instead, which has the same signature.
+var OldVar = 1
+
+// Deprecated: Use New instead.
+func Mismatch(s string) string { return "" }
+
+// Deprecated: Use NoSuchFunc instead.
+func Missing() {}
+
+// Deprecated: Use golang.org/x/text instead.
+func Elsewhere() {}
+
+var _ = strings.Count
@@ -0,0 +1,47 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package exhaustive defines an analyzer that reports switch
+// statements over enum-like types that do not handle every constant.
+//
+// # Analyzer exhaustive
+//
+// exhaustive: report switches that miss constants of an enum-like type
+//
+// The exhaustive analyzer reports a switch statement whose tag has a
+// named type for which its package declares constants, such as this
+// Suit type, if the switch has no default case and no case for some
+// of those constants:
+//
+//	type Suit int8
+//
+//	const (
+//		Spades Suit = iota
+//		Hearts
+//		Diamonds
+//		Clubs
+//	)
+//
+//	switch s {
+//	case Spades, Clubs: // missing cases in switch of type Suit: Hearts, Diamonds
+//		...
+//	}
+//
+// A case for a constant also handles other constants of the same
+// value. The analyzer ignores a switch with a case whose value is not
+// constant.
+//
+// The suggested fix inserts a case clause for each missing constant,
+// in the order of their declarations:
+//
+//	switch s {
+//	case Spades, Clubs:
+//		...
+//	case Hearts:
+//	case Diamonds:
+//	}
+//
+// The "Add cases for T" code action, by contrast, also adds a default
+// case that panics.
+package exhaustive
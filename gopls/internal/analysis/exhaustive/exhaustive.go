@@ -0,0 +1,154 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package exhaustive
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "exhaustive",
+	Doc:      analysisinternal.MustExtractDoc(doc, "exhaustive"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/exhaustive",
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	for curFile := range cursor.Root(inspect).Children() {
+		file := curFile.Node().(*ast.File)
+		for curSwitch := range curFile.Preorder((*ast.SwitchStmt)(nil)) {
+			checkSwitch(pass, file, curSwitch.Node().(*ast.SwitchStmt))
+		}
+	}
+	return nil, nil
+}
+
+// checkSwitch reports the constants of the type of the tag of stmt
+// that it does not handle.
+func checkSwitch(pass *analysis.Pass, file *ast.File, stmt *ast.SwitchStmt) {
+	info := pass.TypesInfo
+	if stmt.Tag == nil {
+		return
+	}
+	named, ok := types.Unalias(info.TypeOf(stmt.Tag)).(*types.Named)
+	if !ok {
+		return
+	}
+	consts := enumConsts(pass.Pkg, named)
+	if len(consts) < 2 {
+		return // not enum-like
+	}
+
+	// Gather the values handled by the cases.
+	handled := make(map[string]bool) // by exact value
+	for _, clause := range stmt.Body.List {
+		clause := clause.(*ast.CaseClause)
+		if clause.List == nil {
+			return // default case
+		}
+		for _, e := range clause.List {
+			value := info.Types[e].Value
+			if value == nil {
+				return // not constant
+			}
+			handled[value.ExactString()] = true
+		}
+	}
+
+	var missing []*types.Const
+	for _, c := range consts {
+		if key := c.Val().ExactString(); !handled[key] {
+			handled[key] = true // report each value once
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	// Qualify the names of constants declared in another package.
+	var (
+		qual  string
+		edits []analysis.TextEdit
+	)
+	if obj := named.Obj(); obj.Pkg() != pass.Pkg {
+		_, qual, edits = analysisinternal.AddImport(info, file, obj.Pkg().Name(), obj.Pkg().Path(), missing[0].Name(), stmt.Pos())
+	}
+
+	// Insert the cases before the closing brace, assuming that it is
+	// on a line of its own, indented by tabs.
+	var (
+		rbrace  = stmt.Body.Rbrace
+		indent  = strings.Repeat("\t", safetoken.StartPosition(pass.Fset, rbrace).Column-1)
+		names   []string
+		newText strings.Builder
+	)
+	for _, c := range missing {
+		names = append(names, c.Name())
+		fmt.Fprintf(&newText, "case %s%s:\n%s", qual, c.Name(), indent)
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos: stmt.Pos(),
+		End: stmt.Pos() + token.Pos(len("switch")),
+		Message: fmt.Sprintf("missing cases in switch of type %s: %s",
+			types.TypeString(named, typesinternal.NameRelativeTo(pass.Pkg)),
+			strings.Join(names, ", ")),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Add missing cases",
+			TextEdits: append(edits, analysis.TextEdit{
+				Pos:     rbrace,
+				End:     rbrace,
+				NewText: []byte(newText.String()),
+			}),
+		}},
+	})
+}
+
+// enumConsts returns the package-level constants of type named,
+// declared in its package and accessible from pkg, in the order of
+// their declarations.
+func enumConsts(pkg *types.Package, named *types.Named) []*types.Const {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return nil // error
+	}
+	if basic, ok := named.Underlying().(*types.Basic); !ok || basic.Info()&(types.IsInteger|types.IsString) == 0 {
+		return nil
+	}
+	var consts []*types.Const
+	scope := obj.Pkg().Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if ok &&
+			(c.Pkg() == pkg || c.Exported()) &&
+			c.Val().Kind() != constant.Unknown &&
+			types.Identical(c.Type(), named) {
+			consts = append(consts, c)
+		}
+	}
+	sort.Slice(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+	return consts
+}
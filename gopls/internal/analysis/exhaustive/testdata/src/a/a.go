@@ -0,0 +1,73 @@
+package a
+
+type Suit int8
+
+const (
+	Spades Suit = iota
+	Hearts
+	Diamonds
+	Clubs
+)
+
+const (
+	Pique = Spades // same value as Spades
+	Coeur = Hearts // same value as Hearts
+)
+
+const joker Suit = -1 // unexported
+
+func _(s Suit) {
+	switch s { // want "missing cases in switch of type Suit: Hearts, Diamonds, joker"
+	case Spades, Clubs:
+		println()
+	}
+
+	switch s { // want "missing cases in switch of type Suit: Diamonds, Clubs, joker"
+	case Pique:
+	case Coeur:
+	}
+
+	switch s {
+	case Spades, Hearts, Diamonds, Clubs, joker:
+	}
+
+	switch s { // nope: default case
+	case Spades:
+	default:
+	}
+
+	switch s { // nope: non-constant case
+	case Spades:
+	case f():
+	}
+
+	switch {
+	case s == Spades:
+	}
+}
+
+func f() Suit { return Clubs }
+
+type Flag bool
+
+const On Flag = true
+
+func _(f Flag) {
+	switch f { // nope: only one constant
+	}
+}
+
+type Name string
+
+const (
+	Alice Name = "alice"
+	Bob   Name = "bob"
+)
+
+func _(n Name) {
+	if true {
+		switch n { // want "missing cases in switch of type Name: Bob"
+		case Alice:
+		}
+	}
+}
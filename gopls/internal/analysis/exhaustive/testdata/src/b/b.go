@@ -0,0 +1,14 @@
+package b
+
+import "a"
+
+func _(s a.Suit) {
+	switch s { // want "missing cases in switch of type a.Suit: Hearts, Clubs"
+	case a.Spades, a.Diamonds:
+	}
+}
+
+func _(s a.Name) {
+	switch s { // want "missing cases in switch of type a.Name: Alice, Bob"
+	}
+}
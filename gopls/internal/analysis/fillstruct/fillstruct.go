@@ -14,6 +14,7 @@ package fillstruct
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -131,7 +132,7 @@ const FixCategory = "fillstruct" // recognized by gopls ApplyFix
 
 // SuggestedFix computes the suggested fix for the kinds of
 // diagnostics produced by the Analyzer above.
-func SuggestedFix(cpkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+func SuggestedFix(ctx context.Context, snapshot *cache.Snapshot, cpkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
 	var (
 		fset = cpkg.FileSet()
 		pkg  = cpkg.Types()
@@ -221,6 +222,8 @@ func SuggestedFix(cpkg *cache.Package, pgf *parsego.File, start, end token.Pos)
 		// NOTE: We currently match on the name of the field key rather than the field type.
 		if best := fuzzy.BestMatch(fieldName, names); best != "" {
 			kv.Value = ast.NewIdent(best)
+		} else if call := constructorCall(ctx, snapshot, cpkg, path, typ, fieldTyp, qual); call != nil {
+			kv.Value = call
 		} else if expr, isValid := populateValue(fieldTyp, qual); isValid {
 			kv.Value = expr
 		} else {
@@ -333,6 +336,44 @@ func indent(str, ind []byte) []byte {
 	return newText.Bytes()
 }
 
+// constructorCall returns a call of a constructor, accessible from
+// cpkg, that takes no arguments and returns a value of the specified
+// field type, T or *T for some named type T. It returns nil if there
+// is none, or if T is the type of the struct being filled, to avoid
+// runaway construction of recursive data types. The constructor
+// enclosing the literal, according to path, is never called.
+func constructorCall(ctx context.Context, snapshot *cache.Snapshot, cpkg *cache.Package, path []ast.Node, structType, fieldType types.Type, qual types.Qualifier) ast.Expr {
+	named, ok := types.Unalias(typesinternal.Unpointer(fieldType)).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || types.Identical(named, structType) {
+		return nil
+	}
+	var enclosing types.Object
+	if decl, ok := path[len(path)-2].(*ast.FuncDecl); ok {
+		enclosing = cpkg.TypesInfo().Defs[decl.Name]
+	}
+	pkg := named.Obj().Pkg()
+	ctors, err := snapshot.Constructors(ctx, cpkg.Metadata(), cache.PackagePath(pkg.Path()))
+	if err != nil {
+		return nil // e.g. an indirect dependency
+	}
+	for _, fn := range ctors.Constructors(pkg, named.Obj()) {
+		sig := fn.Signature()
+		if fn == enclosing ||
+			!fn.Exported() && pkg != cpkg.Types() ||
+			sig.Params().Len() > 0 ||
+			sig.Results().Len() != 1 ||
+			!types.Identical(sig.Results().At(0).Type(), fieldType) {
+			continue
+		}
+		var fun ast.Expr = ast.NewIdent(fn.Name())
+		if name := qual(pkg); name != "" {
+			fun = &ast.SelectorExpr{X: ast.NewIdent(name), Sel: ast.NewIdent(fn.Name())}
+		}
+		return &ast.CallExpr{Fun: fun}
+	}
+	return nil
+}
+
 // populateValue constructs an expression to fill the value of a struct field.
 //
 // When the type of a struct field is a basic literal or interface, we return
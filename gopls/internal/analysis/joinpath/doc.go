@@ -0,0 +1,42 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package joinpath defines an analyzer that reports file paths and
+// URLs built by string concatenation.
+//
+// # Analyzer joinpath
+//
+// joinpath: report file paths and URLs built by string concatenation
+//
+// The joinpath analyzer reports file paths and URLs that are built by
+// concatenating strings with "/", using the + operator or fmt.Sprintf
+// with %s verbs, and passed to a function that opens a file, such as
+// os.Open or os.ReadFile, or requests a URL, such as http.Get or
+// http.NewRequest, either directly or through a local variable.
+//
+// For a file path, the suggested fix uses filepath.Join, which uses the
+// separator of the operating system and cleans the path:
+//
+//	f, err := os.Open(dir + "/" + name)      // before
+//	f, err := os.Open(filepath.Join(dir, name)) // after
+//
+// In a URL, concatenation does not escape the elements of the path, so
+// that an element containing "?" or "#" changes the query or fragment
+// of the URL. When the scheme and host of the URL are constant, the
+// fix uses a url.URL, whose String method escapes its path:
+//
+//	resp, err := http.Get("https://example.com/users/" + id)
+//	resp, err := http.Get((&url.URL{Scheme: "https", Host: "example.com", Path: "/users/" + id}).String())
+//
+// When the URL is relative to a variable base URL and is assigned to a
+// variable in a function that returns an error, the fix uses
+// url.JoinPath, which also removes "." and ".." elements:
+//
+//	u := base + "/users/" + id
+//
+//	u, err := url.JoinPath(base, "users", id)
+//	if err != nil {
+//		return nil, err
+//	}
+package joinpath
@@ -0,0 +1,532 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package joinpath
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "joinpath",
+	Doc:      analysisinternal.MustExtractDoc(doc, "joinpath"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/joinpath",
+}
+
+// A sinkKind is the kind of string expected by a function.
+type sinkKind int
+
+const (
+	notSink  sinkKind = iota
+	pathSink          // a file path
+	urlSink           // a URL
+)
+
+// sink returns the kind of the argument of the call to fn that
+// holds a file path or URL, and its index.
+func sink(fn types.Object) (sinkKind, int) {
+	switch {
+	case analysisinternal.IsFunctionNamed(fn, "os",
+		"Open", "Create", "OpenFile", "ReadFile", "WriteFile", "ReadDir",
+		"Stat", "Lstat", "Remove", "RemoveAll", "Mkdir", "MkdirAll"):
+		return pathSink, 0
+	case analysisinternal.IsFunctionNamed(fn, "net/http", "Get", "Head", "Post", "PostForm"),
+		analysisinternal.IsMethodNamed(fn, "net/http", "Client", "Get", "Head", "Post", "PostForm"),
+		analysisinternal.IsFunctionNamed(fn, "net/url", "Parse"):
+		return urlSink, 0
+	case analysisinternal.IsFunctionNamed(fn, "net/http", "NewRequest"):
+		return urlSink, 1
+	case analysisinternal.IsFunctionNamed(fn, "net/http", "NewRequestWithContext"):
+		return urlSink, 2
+	}
+	return notSink, 0
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if !analysisinternal.Imports(pass.Pkg, "os") &&
+		!analysisinternal.Imports(pass.Pkg, "net/http") &&
+		!analysisinternal.Imports(pass.Pkg, "net/url") {
+		return nil, nil
+	}
+	info := pass.TypesInfo
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	reported := make(map[ast.Expr]bool)
+	for curFile := range cursor.Root(inspect).Children() {
+		file := curFile.Node().(*ast.File)
+		for curCall := range curFile.Preorder((*ast.CallExpr)(nil)) {
+			call := curCall.Node().(*ast.CallExpr)
+			fn := typeutil.Callee(info, call)
+			kind, index := sink(fn)
+			if kind == notSink || index >= len(call.Args) {
+				continue
+			}
+
+			// Find the expression of the argument, and the
+			// statement that assigns it to a variable, if any.
+			var (
+				e        = ast.Unparen(call.Args[index])
+				assign   *ast.AssignStmt
+				curStmt  cursor.Cursor
+				sinkCall *ast.CallExpr // non-nil if not adjacent
+			)
+			if id, ok := e.(*ast.Ident); ok {
+				v, ok := info.Uses[id].(*types.Var)
+				if !ok {
+					continue
+				}
+				// Search for the declaration of v within a common
+				// ancestor of v and the call.
+				e = nil
+				pos := v.Pos()
+				for curAncestor := range curCall.Ancestors() {
+					if curIdent, ok := curAncestor.FindPos(pos, pos); ok {
+						switch parent := curIdent.Parent().Node().(type) {
+						case *ast.AssignStmt:
+							if len(parent.Lhs) == 1 && len(parent.Rhs) == 1 {
+								// Have: v := ...
+								e, assign, curStmt = parent.Rhs[0], parent, curIdent.Parent()
+							}
+						case *ast.ValueSpec:
+							if len(parent.Names) == 1 && len(parent.Values) == 1 {
+								// Have: var v = ...
+								e = parent.Values[0]
+							}
+						}
+						break
+					}
+				}
+				if e == nil {
+					continue
+				}
+				sinkCall = call
+			}
+			if reported[e] {
+				continue
+			}
+
+			// Is e built by concatenation with "/"?
+			parts, ok := stringParts(info, e)
+			parts = compact(parts)
+			if !ok || !isConcat(parts) {
+				continue
+			}
+			reported[e] = true
+
+			suffix := ""
+			if sinkCall != nil {
+				suffix = fmt.Sprintf(" (passed to %s at L%d)",
+					sinkName(fn),
+					safetoken.StartPosition(pass.Fset, sinkCall.Pos()).Line)
+			}
+			var (
+				msg   string
+				fixes []analysis.SuggestedFix
+			)
+			switch kind {
+			case pathSink:
+				msg = fmt.Sprintf("file path built by string concatenation%s does not use filepath.Join", suffix)
+				fixes = pathFix(pass, file, e, parts)
+			case urlSink:
+				msg = fmt.Sprintf("URL built by string concatenation%s does not escape its path", suffix)
+				fixes = urlFix(pass, file, e, parts)
+				if fixes == nil && assign != nil {
+					fixes = joinPathFix(pass, file, curStmt, assign, parts)
+				}
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            e.Pos(),
+				End:            e.End(),
+				Message:        msg,
+				SuggestedFixes: fixes,
+			})
+		}
+	}
+	return nil, nil
+}
+
+// A part is a part of a string built by concatenation: either a
+// literal, or an expression of type string.
+type part struct {
+	lit  string   // the literal text, if expr is nil
+	expr ast.Expr // a non-constant expression
+}
+
+// stringParts returns the parts of e, a string that may be built
+// using the + operator or fmt.Sprintf with %s verbs. It reports false
+// if e is not of type string.
+func stringParts(info *types.Info, e ast.Expr) ([]part, bool) {
+	e = ast.Unparen(e)
+	tv := info.Types[e]
+	if tv.Value != nil && tv.Value.Kind() == constant.String {
+		return []part{{lit: constant.StringVal(tv.Value)}}, true
+	}
+	if !types.Identical(tv.Type, types.Typ[types.String]) {
+		return nil, false
+	}
+	switch e := e.(type) {
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			x, ok := stringParts(info, e.X)
+			if !ok {
+				return nil, false
+			}
+			y, ok := stringParts(info, e.Y)
+			if !ok {
+				return nil, false
+			}
+			return append(x, y...), true
+		}
+
+	case *ast.CallExpr:
+		if analysisinternal.IsFunctionNamed(typeutil.Callee(info, e), "fmt", "Sprintf") &&
+			len(e.Args) > 0 &&
+			!e.Ellipsis.IsValid() {
+			if tv := info.Types[e.Args[0]]; tv.Value != nil && tv.Value.Kind() == constant.String {
+				if parts, ok := formatParts(info, constant.StringVal(tv.Value), e.Args[1:]); ok {
+					return parts, true
+				}
+			}
+		}
+	}
+	return []part{{expr: e}}, true
+}
+
+// formatParts returns the parts of the string formatted by
+// fmt.Sprintf(format, args...), if format has only %s verbs whose
+// operands are strings.
+func formatParts(info *types.Info, format string, args []ast.Expr) ([]part, bool) {
+	var (
+		parts []part
+		lit   strings.Builder
+	)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			lit.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			return nil, false
+		}
+		i++
+		switch format[i] {
+		case '%':
+			lit.WriteByte('%')
+		case 's':
+			if len(args) == 0 {
+				return nil, false
+			}
+			argParts, ok := stringParts(info, args[0])
+			if !ok {
+				return nil, false
+			}
+			args = args[1:]
+			parts = append(parts, part{lit: lit.String()})
+			parts = append(parts, argParts...)
+			lit.Reset()
+		default:
+			return nil, false
+		}
+	}
+	if len(args) > 0 {
+		return nil, false
+	}
+	return append(parts, part{lit: lit.String()}), true
+}
+
+// compact returns parts without empty literals, and with adjacent
+// literals merged.
+func compact(parts []part) []part {
+	var res []part
+	for _, p := range parts {
+		switch {
+		case p.expr == nil && p.lit == "":
+			// skip
+		case p.expr == nil && len(res) > 0 && res[len(res)-1].expr == nil:
+			res[len(res)-1].lit += p.lit
+		default:
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// isConcat reports whether parts has a non-constant part and a "/"
+// separator.
+func isConcat(parts []part) bool {
+	var dynamic, slash bool
+	for _, p := range parts {
+		if p.expr != nil {
+			dynamic = true
+		} else if strings.Contains(p.lit, "/") {
+			slash = true
+		}
+	}
+	return dynamic && slash
+}
+
+// elements splits parts at each "/" into the elements of a path,
+// merging adjacent constant elements. A leading "/" becomes part of
+// the first element, or an element of its own. It reports false if the path
+// has empty elements other than at the start, such as in "a//b" or
+// "a/", or "." or ".." elements, which cleaning would change.
+func elements(parts []part) ([][]part, bool) {
+	// Split the parts into segments.
+	segments := [][]part{nil}
+	for _, p := range parts {
+		if p.expr != nil {
+			segments[len(segments)-1] = append(segments[len(segments)-1], p)
+			continue
+		}
+		for i, text := range strings.Split(p.lit, "/") {
+			if i > 0 {
+				segments = append(segments, nil)
+			}
+			if text != "" {
+				segments[len(segments)-1] = append(segments[len(segments)-1], part{lit: text})
+			}
+		}
+	}
+
+	var elems [][]part
+	for i, seg := range segments {
+		if len(seg) == 0 && i > 0 {
+			return nil, false // a//b or a/
+		}
+		if len(seg) == 1 && seg[0].expr == nil && (seg[0].lit == "." || seg[0].lit == "..") {
+			return nil, false
+		}
+		// Merge a constant segment with a preceding constant one.
+		if isConstant(seg) && len(elems) > 0 && isConstant(elems[len(elems)-1]) {
+			prev := elems[len(elems)-1]
+			text := "/" + seg[0].lit
+			if len(prev) > 0 {
+				text = prev[0].lit + text
+			}
+			elems[len(elems)-1] = []part{{lit: text}}
+			continue
+		}
+		elems = append(elems, seg)
+	}
+	// A leading "/" is the root.
+	if len(elems[0]) == 0 {
+		elems[0] = []part{{lit: "/"}}
+	}
+	return elems, true
+}
+
+// isConstant reports whether the segment has no non-constant part.
+func isConstant(seg []part) bool {
+	for _, p := range seg {
+		if p.expr != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// format returns the Go expression for the concatenation of parts.
+func format(fset *token.FileSet, parts []part) string {
+	var strs []string
+	for _, p := range parts {
+		if p.expr != nil {
+			strs = append(strs, analysisinternal.Format(fset, p.expr))
+		} else if p.lit != "" {
+			strs = append(strs, strconv.Quote(p.lit))
+		}
+	}
+	if len(strs) == 0 {
+		return `""`
+	}
+	return strings.Join(strs, " + ")
+}
+
+// formatElems returns the comma-separated Go expressions for elems.
+func formatElems(fset *token.FileSet, elems [][]part) string {
+	var strs []string
+	for _, elem := range elems {
+		strs = append(strs, format(fset, elem))
+	}
+	return strings.Join(strs, ", ")
+}
+
+// pathFix returns a fix that replaces e, the file path built from
+// parts, by a call to filepath.Join.
+func pathFix(pass *analysis.Pass, file *ast.File, e ast.Expr, parts []part) []analysis.SuggestedFix {
+	elems, ok := elements(parts)
+	if !ok || len(elems) < 2 {
+		return nil
+	}
+	_, prefix, edits := analysisinternal.AddImport(pass.TypesInfo, file, "filepath", "path/filepath", "Join", e.Pos())
+	return []analysis.SuggestedFix{{
+		Message: "Use filepath.Join",
+		TextEdits: append(edits, analysis.TextEdit{
+			Pos:     e.Pos(),
+			End:     e.End(),
+			NewText: fmt.Appendf(nil, "%sJoin(%s)", prefix, formatElems(pass.Fset, elems)),
+		}),
+	}}
+}
+
+// urlFix returns a fix that replaces e, a URL built from parts whose
+// scheme and host are constant, by the String of a url.URL.
+func urlFix(pass *analysis.Pass, file *ast.File, e ast.Expr, parts []part) []analysis.SuggestedFix {
+	if parts[0].expr != nil || !literalPath(parts[1:]) {
+		return nil
+	}
+	scheme, rest, ok := strings.Cut(parts[0].lit, "://")
+	if !ok || scheme != "http" && scheme != "https" {
+		return nil
+	}
+	host, path, ok := strings.Cut(rest, "/")
+	if !ok || host == "" || strings.ContainsAny(host, "@?#%") || strings.ContainsAny(path, "?#%") {
+		return nil
+	}
+	pathParts := append([]part{{lit: "/" + path}}, parts[1:]...)
+
+	_, prefix, edits := analysisinternal.AddImport(pass.TypesInfo, file, "url", "net/url", "URL", e.Pos())
+	return []analysis.SuggestedFix{{
+		Message: "Use url.URL",
+		TextEdits: append(edits, analysis.TextEdit{
+			Pos:     e.Pos(),
+			End:     e.End(),
+			NewText: fmt.Appendf(nil, "(&%sURL{Scheme: %q, Host: %q, Path: %s}).String()", prefix, scheme, host, format(pass.Fset, pathParts)),
+		}),
+	}}
+}
+
+// literalPath reports whether the constant parts of a URL path
+// contain no query, fragment, or escaped character.
+func literalPath(parts []part) bool {
+	for _, p := range parts {
+		if p.expr == nil && strings.ContainsAny(p.lit, "?#%") {
+			return false
+		}
+	}
+	return true
+}
+
+// joinPathFix returns a fix that replaces the statement "u := e", at
+// curStmt, where e is a URL built from parts whose first part is the
+// base URL, by a call to url.JoinPath and a check of its error.
+func joinPathFix(pass *analysis.Pass, file *ast.File, curStmt cursor.Cursor, assign *ast.AssignStmt, parts []part) []analysis.SuggestedFix {
+	info := pass.TypesInfo
+	if assign.Tok != token.DEFINE ||
+		len(parts) < 2 ||
+		parts[0].expr == nil ||
+		!strings.HasPrefix(parts[1].lit, "/") ||
+		!literalPath(parts[1:]) {
+		return nil
+	}
+	if _, ok := curStmt.Parent().Node().(*ast.BlockStmt); !ok {
+		return nil // e.g. if u := ...; cond {}
+	}
+	elems, ok := elements(append([]part{{lit: parts[1].lit[1:]}}, parts[2:]...))
+	if !ok || elems[0][0].lit == "/" {
+		return nil // base//path
+	}
+	u := assign.Lhs[0].(*ast.Ident)
+	if u.Name == "err" {
+		return nil
+	}
+
+	// The enclosing function must return an error, and err must be
+	// an error if already declared in the same scope.
+	var results *types.Tuple
+	for curFunc := range curStmt.Ancestors((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+		switch f := curFunc.Node().(type) {
+		case *ast.FuncDecl:
+			results = info.Defs[f.Name].Type().(*types.Signature).Results()
+		case *ast.FuncLit:
+			results = info.TypeOf(f).(*types.Signature).Results()
+		}
+		break
+	}
+	errorType := types.Universe.Lookup("error").Type()
+	if results == nil || results.Len() == 0 || !types.Identical(results.At(results.Len()-1).Type(), errorType) {
+		return nil
+	}
+	if obj := info.Defs[u].Parent().Lookup("err"); obj != nil && !types.Identical(obj.Type(), errorType) {
+		return nil
+	}
+	var zeros []string
+	qual := typesinternal.FileQualifier(file, pass.Pkg)
+	for i := 0; i < results.Len()-1; i++ {
+		zero, ok := typesinternal.ZeroString(results.At(i).Type(), qual)
+		if !ok {
+			return nil
+		}
+		zeros = append(zeros, zero)
+	}
+
+	// Check the error on the line after the statement, which must
+	// not be followed by another statement on the same line.
+	tokFile := pass.Fset.File(assign.Pos())
+	line := safetoken.Line(tokFile, assign.Pos())
+	if next, ok := curStmt.NextSibling(); ok && safetoken.Line(tokFile, next.Node().Pos()) == line {
+		return nil
+	}
+	if line == tokFile.LineCount() {
+		return nil
+	}
+	nextLine := tokFile.LineStart(line + 1)
+	indent := strings.Repeat("\t", safetoken.StartPosition(pass.Fset, assign.Pos()).Column-1) // assume tabs
+
+	_, prefix, edits := analysisinternal.AddImport(info, file, "url", "net/url", "JoinPath", assign.Pos())
+	return []analysis.SuggestedFix{{
+		Message: "Use url.JoinPath",
+		TextEdits: append(edits,
+			analysis.TextEdit{
+				Pos: assign.Pos(),
+				End: assign.End(),
+				NewText: fmt.Appendf(nil, "%s, err := %sJoinPath(%s, %s)",
+					u.Name,
+					prefix,
+					analysisinternal.Format(pass.Fset, parts[0].expr),
+					formatElems(pass.Fset, elems)),
+			},
+			analysis.TextEdit{
+				Pos: nextLine,
+				End: nextLine,
+				NewText: fmt.Appendf(nil, "%sif err != nil {\n%s\treturn %s\n%s}\n",
+					indent,
+					indent,
+					strings.Join(append(zeros, "err"), ", "),
+					indent),
+			}),
+	}}
+}
+
+// sinkName returns the name of the function fn for a diagnostic,
+// such as "os.Open" or "http.Client.Get".
+func sinkName(fn types.Object) string {
+	name := fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		if _, named := typesinternal.ReceiverNamed(recv); named != nil {
+			name = named.Obj().Name() + "." + name
+		}
+	}
+	return fn.Pkg().Name() + "." + name
+}
@@ -0,0 +1,58 @@
+package a
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+
+func open(dir, name string) {
+	os.Open(dir + "/" + name)                        // want "file path built by string concatenation does not use filepath.Join"
+	os.ReadFile(fmt.Sprintf("%s/%s.txt", dir, name)) // want "file path built by string concatenation does not use filepath.Join"
+	os.Remove("/tmp/app/" + name)                    // want "file path built by string concatenation does not use filepath.Join"
+
+	path := dir + "/data/" + name // want `file path built by string concatenation \(passed to os.Create at L15\) does not use filepath.Join`
+	os.Create(path)
+}
+
+func openNoFix(dir, name string) {
+	os.Open(dir + "/")           // want "file path built by string concatenation does not use filepath.Join"
+	os.Open(dir + "/../" + name) // want "file path built by string concatenation does not use filepath.Join"
+}
+
+func nopePath(dir, name string) {
+	os.Open(name + ".txt")        // nope: no separator
+	os.Open("/etc/" + "passwd")   // nope: constant
+	fmt.Println(dir + "/" + name) // nope: not a path
+}
+
+func get(id string) {
+	http.Get("https://example.com/users/" + id)                                        // want "URL built by string concatenation does not escape its path"
+	http.NewRequest("GET", fmt.Sprintf("http://localhost:8080/api/%s/items", id), nil) // want "URL built by string concatenation does not escape its path"
+	http.Get("https://example.com/search?q=" + id)                                     // want "URL built by string concatenation does not escape its path"
+}
+
+func fetch(base, id string) (*http.Response, error) {
+	u := base + "/users/" + id // want `URL built by string concatenation \(passed to http.Get at L37\) does not escape its path`
+	return http.Get(u)
+}
+
+func fetchSprintf(c *http.Client, base, id string) (int, error) {
+	u := fmt.Sprintf("%s/v1/items/%s", base, id) // want `URL built by string concatenation \(passed to http.Client.Get at L42\) does not escape its path`
+	resp, err := c.Get(u)
+	if err != nil {
+		return 0, err
+	}
+	return resp.StatusCode, nil
+}
+
+func fetchNoFix(base, id string) error {
+	u := base + "/users/" + id + "/" // want `URL built by string concatenation \(passed to http.Get at L51\) does not escape its path`
+	_, err := http.Get(u)
+	return err
+}
+
+func fetchNoError(base, id string) {
+	u := base + "/users/" + id // want `URL built by string concatenation \(passed to http.Get at L57\) does not escape its path`
+	http.Get(u)
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jsontag defines an analyzer that checks the consistency of
+// the json tags of struct fields.
+//
+// # Analyzer jsontag
+//
+// jsontag: check consistency of json struct tags
+//
+// The jsontag analyzer reports three kinds of inconsistency in the
+// json tags of the exported fields of a struct type.
+//
+// A field whose JSON name collides with that of another field, since
+// encoding/json matches names without regard to case when decoding.
+// The JSON name of a field without a tag is its own name. (Fields
+// whose tags have the same name are already reported by the
+// structtag analyzer.)
+//
+//	type User struct {
+//		ID     string
+//		UserID string `json:"id"` // JSON name "id" collides with that of field ID
+//	}
+//
+// A field without a json tag in a struct whose other fields mostly
+// have one:
+//
+//	type User struct {
+//		Name  string `json:"name"`
+//		Email string `json:"email"`
+//		Admin bool   // exported field Admin has no json tag
+//	}
+//
+// A tag whose name is derived from the field name by a different
+// naming convention (snake_case, camelCase, or kebab-case) than that
+// of most other tags of the struct:
+//
+//	type User struct {
+//		FirstName string `json:"first_name"`
+//		LastName  string `json:"last_name"`
+//		BirthDate string `json:"birthDate"` // does not follow snake_case
+//	}
+//
+// The suggested fixes derive the names of the tags they add or
+// change from the field names, using the naming convention of the
+// struct, in the same way as the "Add json struct tags" code action.
+// When the struct has no clear convention, they use snake_case.
+package jsontag
@@ -0,0 +1,260 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsontag
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/gopls/internal/util/structtag"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "jsontag",
+	Doc:      analysisinternal.MustExtractDoc(doc, "jsontag"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/jsontag",
+}
+
+// A jsonField is an exported field, as seen by encoding/json.
+type jsonField struct {
+	id       *ast.Ident
+	field    *ast.Field
+	tag      string // unquoted tag of the field, or ""
+	tagged   bool   // the tag has a json key
+	name     string // JSON name: that of the tag if explicit, otherwise the field name
+	explicit bool   // the tag specifies the JSON name
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	for curFile := range cursor.Root(inspect).Children() {
+		if ast.IsGenerated(curFile.Node().(*ast.File)) {
+			continue
+		}
+		for curStruct := range curFile.Preorder((*ast.StructType)(nil)) {
+			checkStruct(pass, curStruct.Node().(*ast.StructType))
+		}
+	}
+	return nil, nil
+}
+
+// checkStruct reports the inconsistencies in the json tags of st.
+func checkStruct(pass *analysis.Pass, st *ast.StructType) {
+	fields := jsonFields(st)
+	if len(fields) < 2 {
+		return
+	}
+	tagCase, consistent := namingCase(fields)
+
+	// Report JSON names that collide, ignoring case.
+	for i, f := range fields {
+		for _, prev := range fields[:i] {
+			if !strings.EqualFold(f.name, prev.name) {
+				continue
+			}
+			if f.explicit && prev.explicit && f.name == prev.name {
+				break // reported by the structtag analyzer
+			}
+			var fixes []analysis.SuggestedFix
+			if name := structtag.Name(f.id.Name, tagCase); !collides(fields, f, name) {
+				fixes = setNameFix(f, name, fmt.Sprintf("Rename JSON name of %s to %q", f.id.Name, name))
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            f.id.Pos(),
+				End:            f.id.End(),
+				Message:        fmt.Sprintf("JSON name %q of field %s collides with that of field %s", f.name, f.id.Name, prev.id.Name),
+				SuggestedFixes: fixes,
+			})
+			break
+		}
+	}
+
+	// Report the untagged fields of a mostly tagged struct.
+	var tagged, untagged []*jsonField
+	for _, f := range fields {
+		if f.tagged {
+			tagged = append(tagged, f)
+		} else if len(f.field.Names) > 0 {
+			untagged = append(untagged, f)
+		}
+	}
+	if len(tagged) > len(untagged) {
+		for _, f := range untagged {
+			var fixes []analysis.SuggestedFix
+			if name := structtag.Name(f.id.Name, tagCase); !collides(fields, f, name) {
+				fixes = setNameFix(f, name, fmt.Sprintf("Add json tag %q", name))
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            f.id.Pos(),
+				End:            f.id.End(),
+				Message:        fmt.Sprintf("exported field %s has no json tag, unlike the other fields of the struct", f.id.Name),
+				SuggestedFixes: fixes,
+			})
+		}
+	}
+
+	// Report the names derived from the field name with a naming
+	// convention other than that of the struct.
+	if consistent {
+		for _, f := range fields {
+			if !f.explicit || len(f.field.Names) == 0 {
+				continue
+			}
+			cases := casesOf(f)
+			if len(cases) == 0 || cases[tagCase] {
+				continue // not derived from the field name, or consistent
+			}
+			var fixes []analysis.SuggestedFix
+			if name := structtag.Name(f.id.Name, tagCase); !collides(fields, f, name) {
+				fixes = setNameFix(f, name, fmt.Sprintf("Rename JSON name of %s to %q", f.id.Name, name))
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            f.field.Tag.Pos(),
+				End:            f.field.Tag.End(),
+				Message:        fmt.Sprintf("json tag %q of field %s does not follow the %s case of the other tags", f.name, f.id.Name, tagCase),
+				SuggestedFixes: fixes,
+			})
+		}
+	}
+}
+
+// jsonFields returns the fields of st that encoding/json encodes
+// under a name of their own: the exported named fields, and the
+// embedded fields whose tag specifies a name. A field declaring
+// several names yields one jsonField for each.
+func jsonFields(st *ast.StructType) []*jsonField {
+	var fields []*jsonField
+	for _, field := range st.Fields.List {
+		var tag string
+		if field.Tag != nil {
+			var err error
+			tag, err = strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue // malformed tag
+			}
+		}
+		value, tagged := reflect.StructTag(tag).Lookup("json")
+		if value == "-" {
+			continue // ignored by encoding/json
+		}
+		name, _, _ := strings.Cut(value, ",")
+		if len(field.Names) == 0 {
+			// An embedded field is flattened unless its tag names it.
+			if name == "" {
+				continue
+			}
+			var id *ast.Ident
+			switch t := ast.Unparen(field.Type).(type) {
+			case *ast.StarExpr:
+				id, _ = ast.Unparen(t.X).(*ast.Ident)
+			case *ast.Ident:
+				id = t
+			case *ast.SelectorExpr:
+				id = t.Sel
+			}
+			if id != nil && id.IsExported() {
+				fields = append(fields, &jsonField{id, field, tag, tagged, name, true})
+			}
+			continue
+		}
+		for _, id := range field.Names {
+			if !id.IsExported() {
+				continue
+			}
+			f := &jsonField{id, field, tag, tagged, id.Name, false}
+			if name != "" {
+				f.name, f.explicit = name, true
+			}
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// casesOf returns the naming conventions by which the explicit JSON
+// name of field f may be derived from the field name.
+func casesOf(f *jsonField) map[structtag.Case]bool {
+	cases := make(map[structtag.Case]bool)
+	for _, c := range []structtag.Case{structtag.Snake, structtag.Camel, structtag.Kebab} {
+		if structtag.Name(f.id.Name, c) == f.name {
+			cases[c] = true
+		}
+	}
+	return cases
+}
+
+// namingCase returns the naming convention followed by most of the
+// explicit JSON names of fields, and whether it is followed by more
+// of them than any other convention. If there is no such convention,
+// it returns snake_case, the default of the "Add json struct tags"
+// code action.
+func namingCase(fields []*jsonField) (structtag.Case, bool) {
+	counts := make(map[structtag.Case]int)
+	for _, f := range fields {
+		if f.explicit && len(f.field.Names) > 0 {
+			for c := range casesOf(f) {
+				counts[c]++
+			}
+		}
+	}
+	best, bestCount, unique := structtag.Snake, 0, false
+	for _, c := range []structtag.Case{structtag.Snake, structtag.Camel, structtag.Kebab} {
+		switch n := counts[c]; {
+		case n > bestCount:
+			best, bestCount, unique = c, n, true
+		case n == bestCount:
+			unique = false
+		}
+	}
+	if !unique {
+		return structtag.Snake, false
+	}
+	return best, true
+}
+
+// collides reports whether name, as the JSON name of field f, would
+// collide with that of another field.
+func collides(fields []*jsonField, f *jsonField, name string) bool {
+	for _, other := range fields {
+		if other != f && strings.EqualFold(other.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// setNameFix returns a fix that sets the JSON name in the tag of
+// field f to name, adding the tag if necessary. It returns no fix if
+// the field declares several names, which share its tag.
+func setNameFix(f *jsonField, name, message string) []analysis.SuggestedFix {
+	if len(f.field.Names) > 1 {
+		return nil
+	}
+	tag := structtag.Update(f.tag, "json", name, false)
+	edit := analysis.TextEdit{Pos: f.field.Type.End(), End: f.field.Type.End(), NewText: []byte(" `" + tag + "`")}
+	if f.field.Tag != nil {
+		lit := strconv.Quote(tag)
+		if strings.HasPrefix(f.field.Tag.Value, "`") && !strings.Contains(tag, "`") {
+			lit = "`" + tag + "`"
+		}
+		edit = analysis.TextEdit{Pos: f.field.Tag.Pos(), End: f.field.Tag.End(), NewText: []byte(lit)}
+	}
+	return []analysis.SuggestedFix{{Message: message, TextEdits: []analysis.TextEdit{edit}}}
+}
@@ -0,0 +1,49 @@
+package a
+
+import "time"
+
+type Collision struct {
+	ID     string // want "exported field ID has no json tag, unlike the other fields of the struct"
+	UserID string `json:"id"` // want `JSON name "id" of field UserID collides with that of field ID`
+	Name   string `json:"name"`
+	NAME   string `json:"name"` // nope: reported by structtag
+}
+
+type Untagged struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	Admin   bool   // want "exported field Admin has no json tag, unlike the other fields of the struct"
+	private int
+	time.Time
+}
+
+type MostlyUntagged struct {
+	Name  string `json:"name"`
+	Email string
+	Admin bool
+}
+
+type Casing struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	BirthDate string `json:"birthDate"` // want `json tag "birthDate" of field BirthDate does not follow the snake case of the other tags`
+	Nickname  string `json:"alias"`
+}
+
+type CamelCasing struct {
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName,omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created"` // want `json tag "created_at" of field CreatedAt does not follow the camel case of the other tags`
+	UserID    string    // want "exported field UserID has no json tag, unlike the other fields of the struct"
+}
+
+type Ambiguous struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"lastName"`
+}
+
+type Ignored struct {
+	A string `json:"a"`
+	B string `json:"-"`
+	C string `json:"c"`
+}
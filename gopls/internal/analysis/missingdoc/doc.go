@@ -0,0 +1,27 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package missingdoc defines an analyzer that reports exported
+// declarations that have no doc comment.
+//
+// # Analyzer missingdoc
+//
+// missingdoc: report exported declarations without doc comments
+//
+// The missingdoc analyzer reports each exported function, method,
+// type, constant, and variable of a package that has no doc comment.
+// A constant or variable declared in a parenthesized group is
+// documented by the comment of the group, if any. Test files,
+// generated files, and main packages are not checked.
+//
+// The suggested fix inserts a doc comment stub that starts with the
+// name of the declaration, as the Go conventions require, followed by
+// an ellipsis for the rest of the sentence. For a function or method,
+// the stub also mentions its parameters and results:
+//
+//	// Parse ...
+//	//
+//	// It takes s and strict, and returns an int and an error.
+//	func Parse(s string, strict bool) (int, error)
+package missingdoc
@@ -0,0 +1,190 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package missingdoc
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name: "missingdoc",
+	Doc:  analysisinternal.MustExtractDoc(doc, "missingdoc"),
+	Run:  run,
+	URL:  "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/missingdoc",
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if pass.Pkg.Name() == "main" {
+		return nil, nil
+	}
+	for _, file := range pass.Files {
+		tokFile := pass.Fset.File(file.FileStart)
+		if strings.HasSuffix(tokFile.Name(), "_test.go") || ast.IsGenerated(file) {
+			continue
+		}
+		// report reports the undocumented declaration of id, whose
+		// doc comment stub is inserted before pos.
+		report := func(kind string, id *ast.Ident, pos token.Pos, extra string) {
+			// Indent the stub like the declaration, assuming tabs.
+			indent := strings.Repeat("\t", safetoken.Position(tokFile, pos).Column-1)
+			stub := "// " + id.Name + " ...\n"
+			if extra != "" {
+				stub += indent + "//\n" + indent + "// " + extra + "\n"
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     id.Pos(),
+				End:     id.End(),
+				Message: fmt.Sprintf("exported %s %s has no doc comment", kind, id.Name),
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Add doc comment for " + id.Name,
+					TextEdits: []analysis.TextEdit{{
+						Pos:     pos,
+						End:     pos,
+						NewText: []byte(stub + indent),
+					}},
+				}},
+			})
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Doc != nil || !decl.Name.IsExported() {
+					continue
+				}
+				kind := "function"
+				if decl.Recv != nil {
+					fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+					if !ok || !isExportedRecv(fn) {
+						continue
+					}
+					kind = "method"
+				}
+				report(kind, decl.Name, decl.Pos(), signatureHint(decl.Type))
+
+			case *ast.GenDecl:
+				if decl.Doc != nil && decl.Lparen.IsValid() && decl.Tok != token.TYPE {
+					continue // the group is documented
+				}
+				for _, spec := range decl.Specs {
+					// The doc comment of a single spec is that of the declaration.
+					pos, doc := decl.Pos(), decl.Doc
+					if decl.Lparen.IsValid() {
+						pos = spec.Pos()
+						switch spec := spec.(type) {
+						case *ast.TypeSpec:
+							doc = spec.Doc
+						case *ast.ValueSpec:
+							doc = spec.Doc
+						}
+					}
+					if doc != nil {
+						continue
+					}
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if spec.Name.IsExported() {
+							report("type", spec.Name, pos, "")
+						}
+					case *ast.ValueSpec:
+						kind := "variable"
+						if decl.Tok == token.CONST {
+							kind = "constant"
+						}
+						for _, id := range spec.Names {
+							if id.IsExported() {
+								report(kind, id, pos, "")
+								break
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// isExportedRecv reports whether the receiver type of method fn is a
+// named type that is exported.
+func isExportedRecv(fn *types.Func) bool {
+	t := fn.Signature().Recv().Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Exported()
+}
+
+// signatureHint returns a sentence that mentions the parameters and
+// results of a function of type ftype, as in "It takes s and strict,
+// and returns an int and an error.", or "" if it has neither.
+func signatureHint(ftype *ast.FuncType) string {
+	describe := func(fields *ast.FieldList) []string {
+		var items []string
+		if fields == nil {
+			return nil
+		}
+		for _, field := range fields.List {
+			if len(field.Names) == 0 {
+				items = append(items, indefinite(types.ExprString(field.Type)))
+				continue
+			}
+			for _, id := range field.Names {
+				if id.Name != "_" {
+					items = append(items, id.Name)
+				}
+			}
+		}
+		return items
+	}
+	var clauses []string
+	if params := describe(ftype.Params); len(params) > 0 {
+		clauses = append(clauses, "takes "+list(params))
+	}
+	if results := describe(ftype.Results); len(results) > 0 {
+		clauses = append(clauses, "returns "+list(results))
+	}
+	switch len(clauses) {
+	case 0:
+		return ""
+	case 1:
+		return "It " + clauses[0] + "."
+	default:
+		return "It " + clauses[0] + ", and " + clauses[1] + "."
+	}
+}
+
+// indefinite returns the type t preceded by the article "a" or "an".
+func indefinite(t string) string {
+	if strings.ContainsRune("aeiouAEIOU", rune(t[0])) {
+		return "an " + t
+	}
+	return "a " + t
+}
+
+// list joins items into an English list, as in "a, b, and c".
+func list(items []string) string {
+	switch len(items) {
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " and " + items[1]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + ", and " + items[len(items)-1]
+	}
+}
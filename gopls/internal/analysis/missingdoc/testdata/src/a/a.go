@@ -0,0 +1,39 @@
+package a
+
+func Parse(s string, strict bool) (int, error) { return 0, nil } // want `exported function Parse has no doc comment`
+
+func Reset() {} // want `exported function Reset has no doc comment`
+
+func Join(elems ...string) (s string) { return "" } // want `exported function Join has no doc comment`
+
+// Documented is documented.
+func Documented() {}
+
+func unexported() {}
+
+type T struct{} // want `exported type T has no doc comment`
+
+func (T) Method(int, []byte) {} // want `exported method Method has no doc comment`
+
+func (t *unexportedType) Method() {}
+
+type unexportedType int
+
+type (
+	// A is documented.
+	A int
+	B int // want `exported type B has no doc comment`
+)
+
+const Max = 10 // want `exported constant Max has no doc comment`
+
+// Limits of things.
+const (
+	Lo = 1
+	Hi = 2
+)
+
+var (
+	x, Y = 1, 2 // want `exported variable Y has no doc comment`
+	z    = 3
+)
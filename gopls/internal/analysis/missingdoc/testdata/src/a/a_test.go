@@ -0,0 +1,3 @@
+package a
+
+func Helper() {} // test files are not checked
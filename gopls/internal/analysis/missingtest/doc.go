@@ -0,0 +1,32 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package missingtest defines an analyzer that reports exported
+// functions and methods that have no test.
+//
+// # Analyzer missingtest
+//
+// missingtest: report exported functions and methods that have no test
+//
+// The missingtest analyzer reports each exported function or method
+// of a package that is not the subject of any Test, Fuzz, or Example
+// function in the package's test files. A suggested fix adds a test
+// for it.
+//
+// The subjects of a test function are the function or method after
+// which it is named, following the conventions of the testing package
+// and of the "Add test" code action, and the functions and methods
+// that it calls directly. TestF, FuzzF, and ExampleF are named after
+// F, and TestT_M and ExampleT_M after T.M, the method M of type T. A
+// suffix that starts with an underscore, or with an upper case letter
+// or digit, is ignored, so that TestF_empty and TestFEmpty are tests
+// of F too, unless the package declares a function FEmpty.
+//
+// The analyzer reports nothing for a package that has no tests. In
+// gopls, the tests of both the package and its external test package
+// (x_test) are considered, and the diagnostics are reported for the
+// package itself, not for its test variant. Other drivers consider
+// only the tests of the package itself, so they may report functions
+// that are tested only by the external test package.
+package missingtest
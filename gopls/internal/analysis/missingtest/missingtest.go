@@ -0,0 +1,117 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package missingtest
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
+	"golang.org/x/tools/gopls/internal/util/astutil"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name: "missingtest",
+	Doc:  analysisinternal.MustExtractDoc(doc, "missingtest"),
+	Run:  run,
+	URL:  "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/missingtest",
+}
+
+const FixCategory = "missingtest" // recognized by gopls ApplyFix
+
+// testRe matches the names of Test, Fuzz, and Example functions;
+// benchmarks do not test their subjects.
+var testRe = regexp.MustCompile(`^(Test|Fuzz|Example)([^a-z]|$)`)
+
+func run(pass *analysis.Pass) (any, error) {
+	// Note: the non-test files of a package are analyzed with its
+	// test files only in its test variant, so this analyzer reports
+	// nothing for the ordinary package. gopls does not run it, but
+	// computes the same diagnostics from the tests of the package and
+	// of its external test package (see golang.MissingTestDiagnostics).
+	var files, testFiles []*ast.File
+	for _, file := range pass.Files {
+		if strings.HasSuffix(pass.Fset.File(file.Pos()).Name(), "_test.go") {
+			testFiles = append(testFiles, file)
+		} else {
+			files = append(files, file)
+		}
+	}
+	if len(testFiles) == 0 || pass.Pkg.Name() == "main" {
+		return nil, nil
+	}
+	tested := make(map[string]bool)
+	for _, file := range testFiles {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil || !testRe.MatchString(decl.Name.Name) {
+				continue
+			}
+			if fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+				for _, subject := range testfuncs.Subjects(pass.Fset, pass.TypesInfo, fn, decl) {
+					tested[subject] = true
+				}
+			}
+		}
+	}
+	for _, diag := range Untested(files, func(name string) bool { return tested[name] }) {
+		pass.Report(diag)
+	}
+	return nil, nil
+}
+
+// Untested returns a diagnostic for each exported function or method
+// of an exported type declared in the given non-test files of a
+// package that is not tested, according to the tested function,
+// which is called with its name: "F" for a function F, or "T.M" for
+// a method M of type T. Generated files are ignored.
+//
+// The diagnostics depend only on syntax, so that gopls can compute
+// them without type-checking the package.
+func Untested(files []*ast.File, tested func(name string) bool) []analysis.Diagnostic {
+	var diags []analysis.Diagnostic
+	for _, file := range files {
+		if ast.IsGenerated(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Body == nil || !decl.Name.IsExported() {
+				continue
+			}
+			name, kind := decl.Name.Name, "function"
+			if decl.Recv != nil && len(decl.Recv.List) == 1 {
+				_, recv, _ := astutil.UnpackRecv(decl.Recv.List[0].Type)
+				if recv == nil || !recv.IsExported() {
+					continue
+				}
+				name, kind = recv.Name+"."+name, "method"
+			}
+			if tested(name) {
+				continue
+			}
+			diags = append(diags, analysis.Diagnostic{
+				Pos:      decl.Name.Pos(),
+				End:      decl.Name.End(),
+				Message:  fmt.Sprintf("%s %s has no test", kind, name),
+				Category: FixCategory,
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Add test for " + decl.Name.Name,
+					// No TextEdits => computed by gopls ApplyFix.
+				}},
+			})
+		}
+	}
+	return diags
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package missingtest_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/gopls/internal/analysis/missingtest"
+)
+
+// The analyzer reports only for the test variant of a package, whereas
+// analysistest checks the ordinary package too, so the testdata
+// packages have no untested functions; see TestUntested.
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, missingtest.Analyzer, "a", "b", "c")
+}
+
+func TestUntested(t *testing.T) {
+	const src = `package a
+
+func Parse()          {}
+func Format()         {}
+func helper()         {}
+func (T) Method()     {}
+func (*T) Other()     {}
+func (G[K]) Get()     {}
+func (unexported) M() {}
+func Decl()
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "a.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tested := func(name string) bool { return name == "Parse" || name == "T.Method" }
+	var got []string
+	for _, diag := range missingtest.Untested([]*ast.File{f}, tested) {
+		got = append(got, diag.Message)
+	}
+	want := []string{
+		"function Format has no test",
+		"method T.Other has no test",
+		"method G.Get has no test",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Untested reported %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,20 @@
+package a
+
+// All the exported functions and methods of package a are tested, by
+// name or by call, so nothing is reported.
+
+func Parse(s string) int { return length(s) }
+
+func Format(x int) string { return "" }
+
+type T struct{}
+
+func (T) Method() {}
+
+func (*T) Other() {}
+
+type unexported struct{}
+
+func (unexported) Method() {}
+
+func length(s string) int { return len(s) }
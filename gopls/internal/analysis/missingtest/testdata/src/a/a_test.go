@@ -0,0 +1,11 @@
+package a
+
+import "testing"
+
+func TestParseEmpty(t *testing.T) {}
+
+func TestRoundTrip(t *testing.T) { Parse(Format(1)) }
+
+func TestT_Method(t *testing.T) { new(T).Other() }
+
+func BenchmarkLength(b *testing.B) {}
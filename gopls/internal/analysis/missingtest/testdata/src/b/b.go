@@ -0,0 +1,5 @@
+package b
+
+// Package b has no tests, so nothing is reported.
+
+func F() {}
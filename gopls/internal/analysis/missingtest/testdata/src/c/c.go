@@ -0,0 +1,7 @@
+package main
+
+// The functions of a main package have no tests.
+
+func F() {}
+
+func main() {}
@@ -11,8 +11,8 @@
 // This analyzer reports opportunities for simplifying and clarifying
 // existing code by using more modern features of Go, such as:
 //
-//   - replacing an if/else conditional assignment by a call to the
-//     built-in min or max functions added in go1.21;
+//   - replacing an if/else conditional assignment or return by a call
+//     to the built-in min or max functions added in go1.21;
 //   - replacing sort.Slice(x, func(i, j int) bool) { return s[i] < s[j] }
 //     by a call to slices.Sort(s), added in go1.21;
 //   - replacing interface{} by the 'any' type added in go1.18;
@@ -21,10 +21,18 @@
 //   - replacing a loop around an m[k]=v map update by a call
 //     to one of the Collect, Copy, Clone, or Insert functions
 //     from the maps package, added in go1.21;
+//   - replacing a loop that appends the keys or values of a map to a
+//     slice by a call to slices.Collect or slices.AppendSeq of
+//     maps.Keys or maps.Values, added in go1.23;
 //   - replacing []byte(fmt.Sprintf...) by fmt.Appendf(nil, ...),
 //     added in go1.19;
 //   - replacing uses of context.WithCancel in tests with t.Context, added in
 //     go1.24;
+//   - replacing os.Setenv and a deferred restoration of the variable in
+//     tests by t.Setenv, added in go1.17; a temporary directory
+//     created by os.MkdirTemp and removed by a deferred os.RemoveAll
+//     by t.TempDir, added in go1.15; and the deferred calls of a test
+//     whose subtests are parallel by t.Cleanup, added in go1.14;
 //   - replacing omitempty by omitzero on structs, added in go1.24;
 //   - replacing append(s[:i], s[i+1]...) by slices.Delete(s, i, i+1),
 //     added in go1.21
@@ -32,6 +40,9 @@
 //     for i := range n {}, added in go1.22;
 //   - replacing Split in "for range strings.Split(...)" by go1.24's
 //     more efficient SplitSeq;
+//   - replacing a call to strings.Index or bytes.Index, whose result
+//     is used only to test for and slice around the separator, by
+//     Cut, added in go1.18;
 //
 // To apply all modernization fixes en masse, you can use the
 // following command:
@@ -241,3 +241,149 @@ func assignableToIterSeq2(t types.Type) (k, v types.Type, ok bool) {
 	}
 	return
 }
+
+// The mapskeys pass offers to simplify a loop that appends the keys,
+// or the values, of a map to a slice:
+//
+//	for k := range m {
+//		s = append(s, k)
+//	}
+//
+// by a call to go1.23's maps.Keys (or maps.Values) and either
+// slices.AppendSeq, or, if the preceding statement declares s,
+// slices.Collect:
+//
+//	s = slices.AppendSeq(s, maps.Keys(m))
+//	s := slices.Collect(maps.Keys(m))       (var s []K)
+//
+// Both the loop and the functions of the maps package visit the
+// elements of the map in an unspecified order.
+func mapskeys(pass *analysis.Pass) {
+	if pass.Pkg.Path() == "maps" || pass.Pkg.Path() == "slices" {
+		return
+	}
+
+	info := pass.TypesInfo
+
+	// check is called for each statement of this form:
+	//   for k := range m { s = append(s, k) }
+	// where elem is the type of k, and seqName is Keys or Values.
+	check := func(file *ast.File, curRange cursor.Cursor, assign *ast.AssignStmt, elem types.Type, seqName string) {
+		var (
+			rng = curRange.Node().(*ast.RangeStmt)
+			s   = assign.Lhs[0]
+		)
+
+		// Is the preceding statement "var s []K"?
+		start := rng.Pos()
+		if curPrev, ok := curRange.PrevSibling(); ok {
+			if decl, ok := curPrev.Node().(*ast.DeclStmt); ok {
+				gen := decl.Decl.(*ast.GenDecl)
+				if gen.Tok == token.VAR && len(gen.Specs) == 1 {
+					spec := gen.Specs[0].(*ast.ValueSpec)
+					if len(spec.Names) == 1 &&
+						spec.Values == nil &&
+						equalSyntax(spec.Names[0], s) &&
+						types.Identical(info.TypeOf(spec.Type), types.NewSlice(elem)) {
+						// Have: var s []K; for k := range m { s = append(s, k) }
+						start = decl.Pos()
+					}
+				}
+			}
+		}
+
+		funcName := cond(start == rng.Pos(), "AppendSeq", "Collect")
+		_, slicesPrefix, importEdits := analysisinternal.AddImport(info, file, "slices", "slices", funcName, rng.Pos())
+		_, mapsPrefix, importEdits2 := analysisinternal.AddImport(info, file, "maps", "maps", seqName, rng.Pos())
+		var newText []byte
+		if funcName == "Collect" {
+			newText = fmt.Appendf(nil, "%s := %sCollect(%s%s(%s))",
+				analysisinternal.Format(pass.Fset, s),
+				slicesPrefix,
+				mapsPrefix,
+				seqName,
+				analysisinternal.Format(pass.Fset, rng.X))
+		} else {
+			newText = fmt.Appendf(nil, "%s = %sAppendSeq(%s, %s%s(%s))",
+				analysisinternal.Format(pass.Fset, s),
+				slicesPrefix,
+				analysisinternal.Format(pass.Fset, s),
+				mapsPrefix,
+				seqName,
+				analysisinternal.Format(pass.Fset, rng.X))
+		}
+		msg := fmt.Sprintf("Replace append loop with slices.%s and maps.%s", funcName, seqName)
+		pass.Report(analysis.Diagnostic{
+			Pos:      assign.Lhs[0].Pos(),
+			End:      assign.Lhs[0].End(),
+			Category: "mapskeys",
+			Message:  msg,
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: msg,
+				TextEdits: append(append(importEdits, importEdits2...), analysis.TextEdit{
+					Pos:     start,
+					End:     rng.End(),
+					NewText: newText,
+				}),
+			}},
+		})
+	}
+
+	// Find all range loops over a map around s = append(s, k).
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	for curFile := range filesUsing(inspect, pass.TypesInfo, "go1.23") {
+		file := curFile.Node().(*ast.File)
+
+		for curRange := range curFile.Preorder((*ast.RangeStmt)(nil)) {
+			rng := curRange.Node().(*ast.RangeStmt)
+
+			tmap, ok := typeparams.CoreType(info.TypeOf(rng.X)).(*types.Map)
+			if !ok || rng.Tok != token.DEFINE || !isAssignBlock(rng.Body) {
+				continue
+			}
+			assign := rng.Body.List[0].(*ast.AssignStmt)
+			call, ok := assign.Rhs[0].(*ast.CallExpr)
+			if !ok ||
+				assign.Tok != token.ASSIGN ||
+				len(call.Args) != 2 ||
+				call.Ellipsis.IsValid() ||
+				!equalSyntax(assign.Lhs[0], call.Args[0]) {
+				continue
+			}
+			if id, ok := ast.Unparen(call.Fun).(*ast.Ident); !ok || info.Uses[id] != builtinAppend {
+				continue
+			}
+			tslice, ok := typeparams.CoreType(info.TypeOf(call.Args[0])).(*types.Slice)
+			if !ok {
+				continue
+			}
+
+			// Have: for ... := range m { s = append(s, x) }
+			// Is x the key or the value?
+			x, ok := call.Args[1].(*ast.Ident)
+			if !ok || x.Name == "_" {
+				continue
+			}
+			isBlank := func(e ast.Expr) bool {
+				id, ok := e.(*ast.Ident)
+				return e == nil || ok && id.Name == "_"
+			}
+			var (
+				elem    types.Type
+				seqName string
+			)
+			switch {
+			case equalSyntax(rng.Key, x) && isBlank(rng.Value):
+				elem, seqName = tmap.Key(), "Keys"
+			case isBlank(rng.Key) && rng.Value != nil && equalSyntax(rng.Value, x):
+				elem, seqName = tmap.Elem(), "Values"
+			default:
+				continue
+			}
+			// The element must not be converted.
+			if types.Identical(tslice.Elem(), elem) {
+				check(file, curRange, assign, elem, seqName)
+			}
+		}
+	}
+}
@@ -23,11 +23,13 @@ import (
 //
 //  1. if a < b { x = a } else { x = b }        =>      x = min(a, b)
 //  2. x = a; if a < b { x = b }                =>      x = max(a, b)
+//  3. if a < b { return a }; return b          =>      return min(a, b)
 //
 // Variants:
 // - all four ordered comparisons
 // - "x := a" or "x = a" or "var x = a" in pattern 2
 // - "x < b" or "a < b" in pattern 2
+// - "if a < b { return a } else { return b }" in pattern 3
 func minmax(pass *analysis.Pass) {
 
 	// check is called for all statements of this form:
@@ -158,7 +160,80 @@ func minmax(pass *analysis.Pass) {
 		}
 	}
 
-	// Find all "if a < b { lhs = rhs }" statements.
+	// checkReturn is called for all statements of this form:
+	//   if a < b { return rhs }
+	checkReturn := func(curIfStmt cursor.Cursor, compare *ast.BinaryExpr) {
+		var (
+			ifStmt = curIfStmt.Node().(*ast.IfStmt)
+			rhs    = ifStmt.Body.List[0].(*ast.ReturnStmt).Results[0]
+			a      = compare.X
+			b      = compare.Y
+			scope  = pass.TypesInfo.Scopes[ifStmt.Body]
+			sign   = isInequality(compare.Op)
+		)
+
+		// Find the other return statement, and the end of the
+		// statements to replace.
+		var (
+			rhs2 ast.Expr
+			end  token.Pos
+		)
+		if fblock, ok := ifStmt.Else.(*ast.BlockStmt); ok {
+			if !isReturnBlock(fblock) {
+				return
+			}
+			// Have: if a < b { return rhs } else { return rhs2 }
+			rhs2 = fblock.List[0].(*ast.ReturnStmt).Results[0]
+			end = ifStmt.End()
+		} else if next, ok := curIfStmt.NextSibling(); ok && ifStmt.Else == nil {
+			ret, ok := next.Node().(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				return
+			}
+			// Have: if a < b { return rhs }; return rhs2
+			rhs2 = ret.Results[0]
+			end = ret.End()
+		} else {
+			return
+		}
+
+		// Check that {rhs,rhs2} = {a,b}.
+		if equalSyntax(rhs, a) && equalSyntax(rhs2, b) {
+			sign = +sign
+		} else if equalSyntax(rhs2, a) && equalSyntax(rhs, b) {
+			sign = -sign
+		} else {
+			return
+		}
+		sym := cond(sign < 0, "min", "max")
+
+		if _, obj := scope.LookupParent(sym, ifStmt.Pos()); !is[*types.Builtin](obj) {
+			return // min/max function is shadowed
+		}
+
+		// pattern 3
+		pass.Report(analysis.Diagnostic{
+			// Highlight the condition a < b.
+			Pos:      compare.Pos(),
+			End:      compare.End(),
+			Category: "minmax",
+			Message:  fmt.Sprintf("if statement can be modernized using %s", sym),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: fmt.Sprintf("Replace if statement with %s", sym),
+				TextEdits: []analysis.TextEdit{{
+					// Replace IfStmt (and return) with return min(a, b).
+					Pos: ifStmt.Pos(),
+					End: end,
+					NewText: fmt.Appendf(nil, "return %s(%s, %s)",
+						sym,
+						analysisinternal.Format(pass.Fset, a),
+						analysisinternal.Format(pass.Fset, b)),
+				}},
+			}},
+		})
+	}
+
+	// Find all "if a < b { lhs = rhs }" and "if a < b { return rhs }" statements.
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	for curFile := range filesUsing(inspect, pass.TypesInfo, "go1.21") {
 		for curIfStmt := range curFile.Preorder((*ast.IfStmt)(nil)) {
@@ -166,11 +241,15 @@ func minmax(pass *analysis.Pass) {
 
 			if compare, ok := ifStmt.Cond.(*ast.BinaryExpr); ok &&
 				ifStmt.Init == nil &&
-				isInequality(compare.Op) != 0 &&
-				isAssignBlock(ifStmt.Body) {
+				isInequality(compare.Op) != 0 {
 
-				// Have: if a < b { lhs = rhs }
-				check(curIfStmt, compare)
+				if isAssignBlock(ifStmt.Body) {
+					// Have: if a < b { lhs = rhs }
+					check(curIfStmt, compare)
+				} else if isReturnBlock(ifStmt.Body) {
+					// Have: if a < b { return rhs }
+					checkReturn(curIfStmt, compare)
+				}
 			}
 		}
 	}
@@ -197,6 +276,15 @@ func isAssignBlock(b *ast.BlockStmt) bool {
 	return isSimpleAssign(b.List[0])
 }
 
+// isReturnBlock reports whether b is a block of the form { return rhs }.
+func isReturnBlock(b *ast.BlockStmt) bool {
+	if len(b.List) != 1 {
+		return false
+	}
+	ret, ok := b.List[0].(*ast.ReturnStmt)
+	return ok && len(ret.Results) == 1
+}
+
 // isSimpleAssign reports whether n has the form "lhs = rhs" or "lhs := rhs".
 func isSimpleAssign(n ast.Node) bool {
 	assign, ok := n.(*ast.AssignStmt)
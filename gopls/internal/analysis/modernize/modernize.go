@@ -64,6 +64,7 @@ func run(pass *analysis.Pass) (any, error) {
 	bloop(pass)
 	efaceany(pass)
 	fmtappendf(pass)
+	mapskeys(pass)
 	mapsloop(pass)
 	minmax(pass)
 	omitzero(pass)
@@ -71,8 +72,10 @@ func run(pass *analysis.Pass) (any, error) {
 	slicescontains(pass)
 	slicesdelete(pass)
 	splitseq(pass)
+	stringscut(pass)
 	sortslice(pass)
 	testingContext(pass)
+	testingHelpers(pass)
 
 	// TODO(adonovan):
 	// - more modernizers here; see #70815.
@@ -17,6 +17,7 @@ func Test(t *testing.T) {
 		"bloop",
 		"efaceany",
 		"fmtappendf",
+		"mapskeys",
 		"mapsloop",
 		"minmax",
 		"omitzero",
@@ -24,7 +25,9 @@ func Test(t *testing.T) {
 		"slicescontains",
 		"slicesdelete",
 		"splitseq",
+		"stringscut",
 		"sortslice",
 		"testingcontext",
+		"testinghelpers",
 	)
 }
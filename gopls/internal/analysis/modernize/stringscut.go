@@ -0,0 +1,289 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modernize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/astutil/edge"
+)
+
+// stringscut offers a fix to replace a call to strings.Index whose
+// result is used only to test whether sep occurs in s and to slice s
+// around it:
+//
+//	i := strings.Index(s, sep)
+//	if i < 0 {
+//		return
+//	}
+//	key, value := s[:i], s[i+len(sep):]
+//
+// by a call to go1.18's strings.Cut:
+//
+//	before, after, found := strings.Cut(s, sep)
+//	if !found {
+//		return
+//	}
+//	key, value := before, after
+//
+// Variants:
+// - bytes.Index
+// - "if i := strings.Index(s, sep); i >= 0 {...}"
+// - the tests i >= 0, i != -1, and i > -1, and their negations
+// - s[i+n:], where n is the length of the constant sep
+//
+// The variables s and sep must not be assigned within the function.
+func stringscut(pass *analysis.Pass) {
+	if !analysisinternal.Imports(pass.Pkg, "strings") &&
+		!analysisinternal.Imports(pass.Pkg, "bytes") {
+		return
+	}
+	info := pass.TypesInfo
+
+	// check is called for each statement of this form:
+	//   i := strings.Index(s, sep)
+	check := func(curAssign cursor.Cursor, call *ast.CallExpr, pkg string) {
+		var (
+			assign = curAssign.Node().(*ast.AssignStmt)
+			s      = call.Args[0]
+			sep    = call.Args[1]
+		)
+		v, ok := info.Defs[assign.Lhs[0].(*ast.Ident)].(*types.Var)
+		if !ok {
+			return // blank
+		}
+
+		// Find the enclosing function.
+		var (
+			curFunc cursor.Cursor
+			inFunc  bool
+		)
+		for cur := range curAssign.Ancestors((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+			curFunc, inFunc = cur, true
+			break
+		}
+		if !inFunc {
+			return
+		}
+
+		// s and sep must be local variables, or constants,
+		// that keep their values.
+		assigned := assignedVars(info, curFunc.Node())
+		stable := func(e ast.Expr) bool {
+			if tv, ok := info.Types[e]; ok && tv.Value != nil {
+				return true // constant
+			}
+			id, ok := ast.Unparen(e).(*ast.Ident)
+			if !ok {
+				return false
+			}
+			v, ok := info.Uses[id].(*types.Var)
+			return ok && v.Parent() != v.Pkg().Scope() && !assigned[v]
+		}
+		if !stable(s) || !stable(sep) {
+			return
+		}
+
+		// Classify the uses of i, and the edits that replace them.
+		var (
+			edits                 []analysis.TextEdit
+			usesBefore, usesAfter bool
+			usesFound             bool
+			funcNode              = curFunc.Node()
+			replace               = func(n ast.Node, text string) {
+				edits = append(edits, analysis.TextEdit{Pos: n.Pos(), End: n.End(), NewText: []byte(text)})
+			}
+		)
+		for curId := range curFunc.Preorder((*ast.Ident)(nil)) {
+			if info.Uses[curId.Node().(*ast.Ident)] != v {
+				continue
+			}
+			curParent := curId.Parent()
+			switch parent := curParent.Node().(type) {
+			case *ast.BinaryExpr:
+				if ek, _ := curId.Edge(); ek != edge.BinaryExpr_X {
+					return
+				}
+				if parent.Op == token.ADD {
+					// s[i+len(sep):]?
+					slice, ok := curParent.Parent().Node().(*ast.SliceExpr)
+					if ek, _ := curParent.Edge(); !ok || ek != edge.SliceExpr_Low ||
+						slice.High != nil || !equalSyntax(slice.X, s) || !isLenOf(info, parent.Y, sep) {
+						return
+					}
+					replace(slice, "after")
+					usesAfter = true
+					continue
+				}
+				// i >= 0, or a similar test?
+				found, ok := indexTest(info, parent)
+				if !ok {
+					return
+				}
+				replace(parent, cond(found, "found", "!found"))
+				usesFound = true
+
+			case *ast.SliceExpr:
+				// s[:i]?
+				if ek, _ := curId.Edge(); ek != edge.SliceExpr_High || parent.Low != nil || !equalSyntax(parent.X, s) {
+					return
+				}
+				replace(parent, "before")
+				usesBefore = true
+
+			default:
+				return
+			}
+		}
+		if !usesFound || !usesBefore && !usesAfter {
+			return // e.g. strings.Contains
+		}
+
+		// The names of the results must be free.
+		for _, name := range []string{"before", "after", "found"} {
+			if _, obj := v.Parent().LookupParent(name, assign.Pos()); obj != nil {
+				return
+			}
+			declared := false
+			ast.Inspect(funcNode, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && id.Name == name {
+					declared = true
+				}
+				return !declared
+			})
+			if declared {
+				return
+			}
+		}
+
+		sel := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		replace(assign.Lhs[0], fmt.Sprintf("%s, %s, found",
+			cond(usesBefore, "before", "_"),
+			cond(usesAfter, "after", "_")))
+		replace(sel.Sel, "Cut")
+		pass.Report(analysis.Diagnostic{
+			Pos:      call.Fun.Pos(),
+			End:      call.Fun.End(),
+			Category: "stringscut",
+			Message:  fmt.Sprintf("%s.Index can be modernized using %s.Cut", pkg, pkg),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Replace Index with Cut",
+				TextEdits: edits,
+			}},
+		})
+	}
+
+	// Find all "i := strings.Index(s, sep)" statements.
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	for curFile := range filesUsing(inspect, info, "go1.18") {
+		for curAssign := range curFile.Preorder((*ast.AssignStmt)(nil)) {
+			assign := curAssign.Node().(*ast.AssignStmt)
+			if assign.Tok != token.DEFINE || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+				continue
+			}
+			call, ok := assign.Rhs[0].(*ast.CallExpr)
+			if !ok || !is[*ast.SelectorExpr](ast.Unparen(call.Fun)) {
+				continue
+			}
+			obj := typeutil.Callee(info, call)
+			for _, pkg := range []string{"strings", "bytes"} {
+				if analysisinternal.IsFunctionNamed(obj, pkg, "Index") {
+					check(curAssign, call, pkg)
+				}
+			}
+		}
+	}
+}
+
+// indexTest reports whether the comparison x, whose left operand is
+// the result i of Index, has one of the forms i >= 0, i != -1, or
+// i > -1, which test whether the separator was found, or one of their
+// negations. It returns the truth value of the comparison when the
+// separator is found.
+func indexTest(info *types.Info, x *ast.BinaryExpr) (found, ok bool) {
+	tv := info.Types[x.Y]
+	if tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return false, false
+	}
+	n, exact := constant.Int64Val(tv.Value)
+	if !exact {
+		return false, false
+	}
+	switch {
+	case x.Op == token.GEQ && n == 0,
+		x.Op == token.NEQ && n == -1,
+		x.Op == token.GTR && n == -1:
+		return true, true
+	case x.Op == token.LSS && n == 0,
+		x.Op == token.EQL && n == -1,
+		x.Op == token.LEQ && n == -1:
+		return false, true
+	}
+	return false, false
+}
+
+// isLenOf reports whether e is len(sep), or the length of the
+// constant string sep.
+func isLenOf(info *types.Info, e, sep ast.Expr) bool {
+	if call, ok := ast.Unparen(e).(*ast.CallExpr); ok && len(call.Args) == 1 {
+		if id, ok := ast.Unparen(call.Fun).(*ast.Ident); ok && info.Uses[id] == builtinLen {
+			return equalSyntax(call.Args[0], sep)
+		}
+	}
+	n, sepValue := info.Types[e].Value, info.Types[sep].Value
+	if n == nil || sepValue == nil || n.Kind() != constant.Int || sepValue.Kind() != constant.String {
+		return false
+	}
+	length, exact := constant.Int64Val(n)
+	return exact && length == int64(len(constant.StringVal(sepValue)))
+}
+
+// assignedVars returns the set of variables that are assigned, other
+// than by their declaration, or whose address is taken, within n.
+func assignedVars(info *types.Info, n ast.Node) map[*types.Var]bool {
+	assigned := make(map[*types.Var]bool)
+	mark := func(e ast.Expr) {
+		if id, ok := ast.Unparen(e).(*ast.Ident); ok {
+			if v, ok := info.Uses[id].(*types.Var); ok {
+				assigned[v] = true
+			}
+		}
+	}
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				mark(lhs) // Uses, not Defs, for a redeclaration by :=
+			}
+		case *ast.IncDecStmt:
+			mark(n.X)
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN {
+				if n.Key != nil {
+					mark(n.Key)
+				}
+				if n.Value != nil {
+					mark(n.Value)
+				}
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND {
+				mark(n.X)
+			}
+		}
+		return true
+	})
+	return assigned
+}
@@ -0,0 +1,71 @@
+//go:build go1.23
+
+package mapskeys
+
+import (
+	"maps"
+	"slices"
+)
+
+var (
+	_ = maps.Keys[map[int]int] // force "maps" import so that each diagnostic doesn't add one
+	_ = slices.Collect[int]    // force "slices" import
+)
+
+func keys(m map[string]int) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k) // want "Replace append loop with slices.Collect and maps.Keys"
+	}
+	return keys
+}
+
+func values(m map[string]int, vs []int) []int {
+	for _, v := range m {
+		vs = append(vs, v) // want "Replace append loop with slices.AppendSeq and maps.Values"
+	}
+	return vs
+}
+
+func preallocated(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k) // want "Replace append loop with slices.AppendSeq and maps.Keys"
+	}
+	return keys
+}
+
+type Names []string
+
+func nopeConversion(m map[string]int) []any {
+	var keys []any
+	for k := range m {
+		keys = append(keys, k) // nope: implicit conversion to any
+	}
+	return keys
+}
+
+func nopeBoth(m map[string]string) []string {
+	var s []string
+	for k, v := range m {
+		s = append(s, v) // nope: key is not blank
+		_ = k
+	}
+	return s
+}
+
+func nopeSlice(s []string) []string {
+	var t []string
+	for _, x := range s {
+		t = append(t, x) // nope: not a map
+	}
+	return t
+}
+
+func namedDecl(m map[string]int) Names {
+	var names Names
+	for k := range m {
+		names = append(names, k) // want "Replace append loop with slices.AppendSeq and maps.Keys"
+	}
+	return names
+}
@@ -115,3 +115,33 @@ func nopeHasElseBlock(x int) int {
 	}
 	return y
 }
+
+func ifReturnMin(a, b int) int {
+	if a < b { // want "if statement can be modernized using min"
+		return a
+	}
+	return b
+}
+
+func ifElseReturnMax(a, b int) int {
+	if a <= b { // want "if statement can be modernized using max"
+		return b
+	} else {
+		return a
+	}
+}
+
+func nopeIfReturnOther(a, b int) int {
+	if a < b {
+		return a
+	}
+	return a + b
+}
+
+func nopeIfReturnBody(a, b int) int {
+	if a < b {
+		return a
+	}
+	print(b)
+	return b
+}
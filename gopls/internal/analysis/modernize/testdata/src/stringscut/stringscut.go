@@ -0,0 +1,70 @@
+package stringscut
+
+import (
+	"bytes"
+	"strings"
+)
+
+func keyValue(s string) (string, string) {
+	i := strings.Index(s, "=") // want "strings.Index can be modernized using strings.Cut"
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i+1:]
+}
+
+func prefix(s, sep string) string {
+	if i := strings.Index(s, sep); i != -1 { // want "strings.Index can be modernized using strings.Cut"
+		return s[:i]
+	}
+	return s
+}
+
+func suffix(b, sep []byte) []byte {
+	if i := bytes.Index(b, sep); i >= 0 { // want "bytes.Index can be modernized using bytes.Cut"
+		return b[i+len(sep):]
+	}
+	return nil
+}
+
+func nopeIndexUsed(s string) int {
+	i := strings.Index(s, "=")
+	if i < 0 {
+		return 0
+	}
+	print(s[:i])
+	return i
+}
+
+func nopeUnguarded(s string) string {
+	i := strings.Index(s, "=")
+	return s[:i]
+}
+
+func nopeContains(s string) bool {
+	i := strings.Index(s, "=")
+	return i >= 0
+}
+
+func nopeReassigned(s string) string {
+	i := strings.Index(s, "=")
+	s = strings.ToLower(s)
+	if i < 0 {
+		return ""
+	}
+	return s[:i]
+}
+
+func nopeOtherString(s, t string) string {
+	if i := strings.Index(s, "="); i >= 0 {
+		return t[:i]
+	}
+	return ""
+}
+
+func nopeNameInUse(s string) (before string) {
+	if i := strings.Index(s, "="); i >= 0 {
+		before = s[:i]
+	}
+	return
+}
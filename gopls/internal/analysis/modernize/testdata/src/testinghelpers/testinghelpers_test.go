@@ -0,0 +1,107 @@
+package testinghelpers
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSetenv(t *testing.T) {
+	old := os.Getenv("HOME")
+	os.Setenv("HOME", "/tmp") // want "os.Setenv can be modernized using t.Setenv"
+	defer os.Setenv("HOME", old)
+
+	prev := os.Getenv("USER")
+	defer os.Setenv("USER", prev)
+	os.Setenv("USER", "gopher") // want "os.Setenv can be modernized using t.Setenv"
+}
+
+func TestSetenvUsed(t *testing.T) {
+	old := os.Getenv("HOME")
+	os.Setenv("HOME", "/tmp") // nope: old has other uses
+	defer os.Setenv("HOME", old)
+	println(old)
+}
+
+func TestSetenvOtherKey(t *testing.T) {
+	old := os.Getenv("HOME")
+	os.Setenv("PATH", "/tmp") // nope: different keys
+	defer os.Setenv("HOME", old)
+}
+
+func TestSetenvParallel(t *testing.T) {
+	t.Parallel()
+	old := os.Getenv("HOME")
+	os.Setenv("HOME", "/tmp") // nope: t.Setenv panics in parallel tests
+	defer os.Setenv("HOME", old)
+}
+
+func TestTempDir(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test") // want "os.MkdirTemp can be modernized using t.TempDir"
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	_ = dir
+}
+
+func BenchmarkTempDir(b *testing.B) {
+	dir, _ := ioutil.TempDir("", "bench") // want "ioutil.TempDir can be modernized using b.TempDir"
+	defer os.RemoveAll(dir)
+	_ = dir
+}
+
+func TestTempDirErrUsed(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test") // nope: err is used after the check
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	_, _ = dir, err
+}
+
+func TestTempDirParent(t *testing.T) {
+	dir, _ := os.MkdirTemp("/var/tmp", "test") // nope: not the default directory
+	defer os.RemoveAll(dir)
+	_ = dir
+}
+
+func TestTempDirNotRemoved(t *testing.T) {
+	dir, _ := os.MkdirTemp("", "test") // nope: dir is not removed
+	_ = dir
+}
+
+func helper() {
+	old := os.Getenv("HOME")
+	os.Setenv("HOME", "/tmp") // nope: not a test
+	defer os.Setenv("HOME", old)
+}
+
+var _ = ioutil.ReadFile
+
+type server struct{}
+
+func (*server) Close()      {}
+func (*server) Stop() error { return nil }
+
+func TestCleanup(t *testing.T) {
+	s := new(server)
+	defer s.Close() // want "defer runs before the parallel subtests complete; use t.Cleanup"
+	defer func() {  // want "defer runs before the parallel subtests complete; use t.Cleanup"
+		s.Close()
+	}()
+	defer s.Stop() // nope: has a result
+
+	for _, name := range []string{"a", "b"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			defer s.Close() // nope: the subtest has no parallel subtests
+		})
+	}
+}
+
+func TestCleanupSequential(t *testing.T) {
+	s := new(server)
+	defer s.Close() // nope: the subtests are not parallel
+	t.Run("a", func(t *testing.T) {})
+}
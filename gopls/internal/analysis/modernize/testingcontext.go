@@ -108,23 +108,7 @@ func testingContext(pass *analysis.Pass) {
 		// Check that we are in a test func.
 		var testObj types.Object // relevant testing.{T,B,F}, or nil
 		if curFunc, ok := enclosingFunc(cur); ok {
-			switch n := curFunc.Node().(type) {
-			case *ast.FuncLit:
-				if e, idx := curFunc.Edge(); e == edge.CallExpr_Args && idx == 1 {
-					// Have: call(..., func(...) { ...context.WithCancel(...)... })
-					obj := typeutil.Callee(info, curFunc.Parent().Node().(*ast.CallExpr))
-					if (analysisinternal.IsMethodNamed(obj, "testing", "T", "Run") ||
-						analysisinternal.IsMethodNamed(obj, "testing", "B", "Run")) &&
-						len(n.Type.Params.List[0].Names) == 1 {
-
-						// Have tb.Run(..., func(..., tb *testing.[TB]) { ...context.WithCancel(...)... }
-						testObj = info.Defs[n.Type.Params.List[0].Names[0]]
-					}
-				}
-
-			case *ast.FuncDecl:
-				testObj = isTestFn(info, n)
-			}
+			testObj = testingParam(info, curFunc)
 		}
 
 		if testObj != nil {
@@ -157,6 +141,30 @@ func testingContext(pass *analysis.Pass) {
 	}
 }
 
+// testingParam returns the testing.{T,B,F} parameter of the function
+// at curFunc, if it is a test function or the function literal of a
+// subtest, or nil.
+func testingParam(info *types.Info, curFunc cursor.Cursor) types.Object {
+	switch n := curFunc.Node().(type) {
+	case *ast.FuncLit:
+		if e, idx := curFunc.Edge(); e == edge.CallExpr_Args && idx == 1 {
+			// Have: call(..., func(...) { ... })
+			obj := typeutil.Callee(info, curFunc.Parent().Node().(*ast.CallExpr))
+			if (analysisinternal.IsMethodNamed(obj, "testing", "T", "Run") ||
+				analysisinternal.IsMethodNamed(obj, "testing", "B", "Run")) &&
+				len(n.Type.Params.List[0].Names) == 1 {
+
+				// Have tb.Run(..., func(..., tb *testing.[TB]) { ... }
+				return info.Defs[n.Type.Params.List[0].Names[0]]
+			}
+		}
+
+	case *ast.FuncDecl:
+		return isTestFn(info, n)
+	}
+	return nil
+}
+
 // soleUse returns the ident that refers to obj, if there is exactly one.
 //
 // TODO(rfindley): consider factoring to share with gopls/internal/refactor/inline.
@@ -0,0 +1,370 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modernize
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/versions"
+)
+
+// The testingHelpers pass replaces manual setup and teardown in tests
+// by the helpers of testing.{T,B,F}. Specifically, it suggests to
+// replace:
+//
+//	old := os.Getenv(k)
+//	os.Setenv(k, v)
+//	defer os.Setenv(k, old)
+//
+// (in either order of the last two statements) with t.Setenv(k, v),
+// added in go1.17, provided old has no other uses and the test does
+// not call t.Parallel;
+//
+//	dir, err := os.MkdirTemp("", pattern) // or ioutil.TempDir
+//	if err != nil { ... }
+//	defer os.RemoveAll(dir)
+//
+// with dir := t.TempDir(), added in go1.15, provided err has no other
+// uses; and, in a test whose subtests call t.Parallel, and which
+// therefore returns before they complete:
+//
+//	defer f()
+//
+// with t.Cleanup(f), added in go1.14, provided f is a function
+// literal, or a function or method that has no results.
+//
+// In each case, the call must be within a test or subtest function,
+// and the relevant testing.{T,B,F} must be named and not shadowed.
+func testingHelpers(pass *analysis.Pass) {
+	if !analysisinternal.Imports(pass.Pkg, "testing") {
+		return
+	}
+	info := pass.TypesInfo
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// testFunc returns the testing.{T,B,F} of the test function that
+	// encloses cur, provided it is in scope at cur.
+	testFunc := func(cur cursor.Cursor) (cursor.Cursor, types.Object) {
+		curFunc, ok := enclosingFunc(cur)
+		if !ok {
+			return cursor.Cursor{}, nil
+		}
+		testObj := testingParam(info, curFunc)
+		if testObj == nil || !inScope(info, cur, testObj) {
+			return cursor.Cursor{}, nil
+		}
+		return curFunc, testObj
+	}
+
+	for curFile := range filesUsing(inspect, info, "go1.14") {
+		file := curFile.Node().(*ast.File)
+		if !strings.HasSuffix(pass.Fset.File(file.FileStart).Name(), "_test.go") {
+			continue
+		}
+		version := info.FileVersions[file]
+		for cur := range curFile.Preorder((*ast.CallExpr)(nil)) {
+			call := cur.Node().(*ast.CallExpr)
+			obj := typeutil.Callee(info, call)
+			switch {
+			case analysisinternal.IsFunctionNamed(obj, "os", "Setenv"):
+				if !versions.Before(version, "go1.17") {
+					testingSetenv(pass, cur, testFunc)
+				}
+			case analysisinternal.IsFunctionNamed(obj, "os", "MkdirTemp"),
+				analysisinternal.IsFunctionNamed(obj, "io/ioutil", "TempDir"):
+				if !versions.Before(version, "go1.15") {
+					testingTempDir(pass, cur, testFunc)
+				}
+			}
+		}
+		for cur := range curFile.Preorder((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+			testingCleanup(pass, cur)
+		}
+	}
+}
+
+// testingSetenv reports the call os.Setenv(k, v) at cur if it is
+// preceded by old := os.Getenv(k) and accompanied by a deferred
+// os.Setenv(k, old).
+func testingSetenv(pass *analysis.Pass, cur cursor.Cursor, testFunc func(cursor.Cursor) (cursor.Cursor, types.Object)) {
+	info := pass.TypesInfo
+	call := cur.Node().(*ast.CallExpr)
+	curStmt := cur.Parent()
+	if _, ok := curStmt.Node().(*ast.ExprStmt); !ok {
+		return
+	}
+	key := call.Args[0]
+
+	// isGetenv returns the variable old of a statement old := os.Getenv(key).
+	isGetenv := func(stmt ast.Node) *types.Var {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return nil
+		}
+		id, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		get, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok || !analysisinternal.IsFunctionNamed(typeutil.Callee(info, get), "os", "Getenv") ||
+			!equalSyntax(get.Args[0], key) {
+			return nil
+		}
+		v, _ := info.Defs[id].(*types.Var)
+		return v
+	}
+	// isRestore reports whether stmt is defer os.Setenv(key, old),
+	// the sole use of old.
+	isRestore := func(stmt ast.Node, old *types.Var) bool {
+		defr, ok := stmt.(*ast.DeferStmt)
+		if !ok || !analysisinternal.IsFunctionNamed(typeutil.Callee(info, defr.Call), "os", "Setenv") ||
+			!equalSyntax(defr.Call.Args[0], key) {
+			return false
+		}
+		id, ok := defr.Call.Args[1].(*ast.Ident)
+		return ok && old != nil && soleUse(info, old) == id
+	}
+
+	prev, ok := curStmt.PrevSibling()
+	if !ok {
+		return
+	}
+	var first, last ast.Node
+	if old := isGetenv(prev.Node()); old != nil {
+		// old := os.Getenv(k); os.Setenv(k, v); defer os.Setenv(k, old)
+		next, ok := curStmt.NextSibling()
+		if !ok || !isRestore(next.Node(), old) {
+			return
+		}
+		first, last = prev.Node(), next.Node()
+	} else {
+		// old := os.Getenv(k); defer os.Setenv(k, old); os.Setenv(k, v)
+		prev2, ok := prev.PrevSibling()
+		if !ok || !isRestore(prev.Node(), isGetenv(prev2.Node())) {
+			return
+		}
+		first, last = prev2.Node(), curStmt.Node()
+	}
+
+	curFunc, testObj := testFunc(cur)
+	if testObj == nil {
+		return
+	}
+	// t.Setenv panics in parallel tests.
+	for curCall := range curFunc.Preorder((*ast.CallExpr)(nil)) {
+		if isParallelCall(info, curCall.Node().(*ast.CallExpr), testObj) {
+			return
+		}
+	}
+
+	pass.Report(analysis.Diagnostic{
+		Pos:      call.Fun.Pos(),
+		End:      call.Fun.End(),
+		Category: "testingsetenv",
+		Message:  fmt.Sprintf("os.Setenv can be modernized using %s.Setenv", testObj.Name()),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Replace os.Setenv with %s.Setenv", testObj.Name()),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     first.Pos(),
+				End:     last.End(),
+				NewText: fmt.Appendf(nil, "%s.Setenv(%s)", testObj.Name(), formatExprs(pass.Fset, call.Args)),
+			}},
+		}},
+	})
+}
+
+// testingTempDir reports the call os.MkdirTemp("", pattern) at cur,
+// or ioutil.TempDir, if its error is only checked and the directory
+// is removed by a deferred call to os.RemoveAll.
+func testingTempDir(pass *analysis.Pass, cur cursor.Cursor, testFunc func(cursor.Cursor) (cursor.Cursor, types.Object)) {
+	info := pass.TypesInfo
+	call := cur.Node().(*ast.CallExpr)
+	if lit, ok := call.Args[0].(*ast.BasicLit); !ok || lit.Kind != token.STRING || len(lit.Value) != 2 {
+		return // not the default directory
+	}
+	curAssign := cur.Parent()
+	assign, ok := curAssign.Node().(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return
+	}
+	dirId, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok || dirId.Name == "_" {
+		return
+	}
+	errId, ok := assign.Lhs[1].(*ast.Ident)
+	if !ok {
+		return
+	}
+	dir := info.ObjectOf(dirId)
+
+	// The error, if any, must be new, and used only by the if
+	// statement that follows, which checks it.
+	next, ok := curAssign.NextSibling()
+	if !ok {
+		return
+	}
+	if errId.Name != "_" {
+		err := info.Defs[errId]
+		ifStmt, ok := next.Node().(*ast.IfStmt)
+		if err == nil || !ok || ifStmt.Init != nil || ifStmt.Else != nil {
+			return
+		}
+		cond, ok := ifStmt.Cond.(*ast.BinaryExpr)
+		if !ok || cond.Op != token.NEQ || !isIdentFor(info, cond.X, err) || !info.Types[cond.Y].IsNil() {
+			return
+		}
+		for id, obj := range info.Uses {
+			if obj == err && (id.Pos() < ifStmt.Pos() || id.End() > ifStmt.End()) {
+				return
+			}
+		}
+		if next, ok = next.NextSibling(); !ok {
+			return
+		}
+	}
+
+	defr, ok := next.Node().(*ast.DeferStmt)
+	if !ok || !analysisinternal.IsFunctionNamed(typeutil.Callee(info, defr.Call), "os", "RemoveAll") ||
+		!isIdentFor(info, defr.Call.Args[0], dir) {
+		return
+	}
+
+	_, testObj := testFunc(cur)
+	if testObj == nil {
+		return
+	}
+	tok := token.ASSIGN
+	if info.Defs[dirId] != nil {
+		tok = token.DEFINE
+	}
+	fn := typeutil.Callee(info, call)
+	pass.Report(analysis.Diagnostic{
+		Pos:      call.Fun.Pos(),
+		End:      call.Fun.End(),
+		Category: "testingtempdir",
+		Message:  fmt.Sprintf("%s.%s can be modernized using %s.TempDir", fn.Pkg().Name(), fn.Name(), testObj.Name()),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Replace %s.%s with %s.TempDir", fn.Pkg().Name(), fn.Name(), testObj.Name()),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     assign.Pos(),
+				End:     defr.End(),
+				NewText: fmt.Appendf(nil, "%s %s %s.TempDir()", dirId.Name, tok, testObj.Name()),
+			}},
+		}},
+	})
+}
+
+// testingCleanup reports the deferred calls of the function at
+// curFunc, if it is a test function whose subtests call t.Parallel.
+func testingCleanup(pass *analysis.Pass, curFunc cursor.Cursor) {
+	info := pass.TypesInfo
+	testObj := testingParam(info, curFunc)
+	if testObj == nil {
+		return
+	}
+
+	// Does a subtest, run by testObj.Run, call Parallel?
+	parallel := false
+	for curLit := range curFunc.Preorder((*ast.FuncLit)(nil)) {
+		if sub := testingParam(info, curLit); sub != nil {
+			run := curLit.Parent().Node().(*ast.CallExpr)
+			if sel, ok := run.Fun.(*ast.SelectorExpr); ok && isIdentFor(info, sel.X, testObj) {
+				for curCall := range curLit.Preorder((*ast.CallExpr)(nil)) {
+					if isParallelCall(info, curCall.Node().(*ast.CallExpr), sub) {
+						parallel = true
+					}
+				}
+			}
+		}
+	}
+	if !parallel {
+		return
+	}
+
+	for curDefer := range curFunc.Preorder((*ast.DeferStmt)(nil)) {
+		if c, _ := enclosingFunc(curDefer); c != curFunc {
+			continue // defer belongs to a nested function
+		}
+		defr := curDefer.Node().(*ast.DeferStmt)
+		call := defr.Call
+		if len(call.Args) > 0 {
+			continue
+		}
+		switch fun := ast.Unparen(call.Fun).(type) {
+		case *ast.FuncLit:
+			if fun.Type.Params.NumFields() > 0 || fun.Type.Results.NumFields() > 0 {
+				continue
+			}
+		case *ast.Ident, *ast.SelectorExpr:
+			sig, ok := info.TypeOf(fun).(*types.Signature)
+			if !ok || sig.Results().Len() > 0 || info.Types[fun].IsBuiltin() || info.Types[fun].IsType() {
+				continue
+			}
+		default:
+			continue
+		}
+		if !inScope(info, curDefer, testObj) {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      defr.Defer,
+			End:      defr.Defer + token.Pos(len("defer")),
+			Category: "testingcleanup",
+			Message: fmt.Sprintf("defer runs before the parallel subtests complete; use %s.Cleanup",
+				testObj.Name()),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: fmt.Sprintf("Replace defer with %s.Cleanup", testObj.Name()),
+				TextEdits: []analysis.TextEdit{
+					{
+						Pos:     defr.Defer,
+						End:     call.Fun.Pos(),
+						NewText: fmt.Appendf(nil, "%s.Cleanup(", testObj.Name()),
+					},
+					{
+						Pos:     call.Fun.End(),
+						End:     call.End(),
+						NewText: []byte(")"),
+					},
+				},
+			}},
+		})
+	}
+}
+
+// isParallelCall reports whether call is a call to the Parallel method
+// of testObj.
+func isParallelCall(info *types.Info, call *ast.CallExpr, testObj types.Object) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Parallel" && isIdentFor(info, sel.X, testObj) &&
+		analysisinternal.IsMethodNamed(typeutil.Callee(info, call), "testing", "T", "Parallel")
+}
+
+// inScope reports whether obj is in scope, and not shadowed, at the
+// node at cur.
+func inScope(info *types.Info, cur cursor.Cursor, obj types.Object) bool {
+	pos := cur.Node().Pos()
+	for curFile := range cur.Ancestors((*ast.File)(nil)) {
+		scope := info.Scopes[curFile.Node().(*ast.File)].Innermost(pos)
+		_, obj2 := scope.LookupParent(obj.Name(), pos)
+		return obj2 == obj
+	}
+	return false
+}
+
+// isIdentFor reports whether e is an identifier that refers to obj.
+func isIdentFor(info *types.Info, e ast.Expr, obj types.Object) bool {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	return ok && obj != nil && info.Uses[id] == obj
+}
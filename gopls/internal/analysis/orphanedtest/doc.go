@@ -0,0 +1,36 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package orphanedtest defines an analyzer that reports tests whose
+// subject no longer exists.
+//
+// # Analyzer orphanedtest
+//
+// orphanedtest: report tests whose subject no longer exists
+//
+// The orphanedtest analyzer reports each Test, Benchmark, or Fuzz
+// function that is named after a function or type that the package
+// under test does not declare, and that refers to no declaration of
+// that package. Such a test is usually left behind when its subject
+// is removed or renamed. For example, after the function Parse is
+// renamed to Load, the analyzer reports TestParse:
+//
+//	func TestParse(t *testing.T) {
+//		if Parse("1") != 1 { // error: undefined: Parse
+//			t.Error("Parse failed")
+//		}
+//	}
+//
+// The subjects of a test are determined as by the missingtest
+// analyzer. Functions named just Test, Benchmark, or Fuzz, and
+// TestMain, are never reported. Examples are not reported either,
+// since vet's tests analyzer reports examples named after undeclared
+// identifiers.
+//
+// One suggested fix deletes the test. If the package under test
+// declares an untested function or type whose name is similar to
+// that of the missing subject, another fix retargets the test to it,
+// renaming the test and replacing the references to the missing
+// subject in its body.
+package orphanedtest
@@ -0,0 +1,254 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package orphanedtest
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:             "orphanedtest",
+	Doc:              analysisinternal.MustExtractDoc(doc, "orphanedtest"),
+	Run:              run,
+	RunDespiteErrors: true, // orphaned tests usually refer to undeclared names
+	URL:              "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/orphanedtest",
+}
+
+// testRe matches the names of Test, Benchmark, and Fuzz functions.
+var testRe = regexp.MustCompile(`^(Test|Benchmark|Fuzz)([^a-z]|$)`)
+
+func run(pass *analysis.Pass) (any, error) {
+	underTest := pass.Pkg
+	if path, ok := strings.CutSuffix(underTest.Path(), "_test"); ok {
+		// An external test package: find the package under test among its imports.
+		underTest = nil
+		for _, imp := range pass.Pkg.Imports() {
+			if imp.Path() == path {
+				underTest = imp
+				break
+			}
+		}
+		if underTest == nil {
+			return nil, nil // tests are related to the package by name only
+		}
+	}
+	inTestFile := func(pos token.Pos) bool {
+		return strings.HasSuffix(safetoken.StartPosition(pass.Fset, pos).Filename, "_test.go")
+	}
+
+	// An orphan is a test with no subject.
+	type orphan struct {
+		file *ast.File
+		decl *ast.FuncDecl
+		word int // offset of the subject part of the test name
+	}
+	var orphans []orphan
+	tested := make(map[string]bool) // subjects of tests, and their types
+	for _, file := range pass.Files {
+		if !inTestFile(file.Pos()) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil || decl.Body == nil || !testRe.MatchString(decl.Name.Name) {
+				continue
+			}
+			fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			subjects := testfuncs.Subjects(pass.Fset, pass.TypesInfo, fn, decl)
+			for _, subject := range subjects {
+				tested[subject] = true
+				if typ, _, ok := strings.Cut(subject, "."); ok {
+					tested[typ] = true
+				}
+			}
+			if len(subjects) > 0 || decl.Name.Name == "TestMain" {
+				continue
+			}
+			// Find the subject part of the name: F in TestF or Test_F.
+			name := decl.Name.Name
+			word := len(name)
+			if i := strings.IndexFunc(name[1:], func(r rune) bool { return !unicode.IsLower(r) }); i >= 0 {
+				word = 1 + i
+			}
+			if word < len(name) && name[word] == '_' {
+				word++
+			}
+			if word == len(name) || name[word] == '_' {
+				continue // a test named just Test
+			}
+			// A test that uses any declaration of the package
+			// under test is not an orphan.
+			uses := false
+			ast.Inspect(decl.Body, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && !uses {
+					if obj := pass.TypesInfo.Uses[id]; obj != nil && obj.Pkg() == underTest && !inTestFile(obj.Pos()) {
+						uses = true
+					}
+				}
+				return !uses
+			})
+			if !uses {
+				orphans = append(orphans, orphan{file, decl, word})
+			}
+		}
+	}
+
+	for _, o := range orphans {
+		name := o.decl.Name.Name
+		subject, _, _ := strings.Cut(name[o.word:], "_")
+
+		// Find the undeclared names in the test that denote its
+		// subject, and choose the longest one as its former name.
+		var undeclared []*ast.Ident
+		old := ""
+		ast.Inspect(o.decl.Body, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok &&
+				pass.TypesInfo.Uses[id] == nil &&
+				pass.TypesInfo.Defs[id] == nil &&
+				testfuncs.IsSubjectPrefix(subject, id.Name) {
+				undeclared = append(undeclared, id)
+				if len(id.Name) > len(old) {
+					old = id.Name
+				}
+			}
+			return true
+		})
+		if old == "" {
+			old = subject
+		}
+
+		fixes := []analysis.SuggestedFix{{
+			Message:   "Delete " + name,
+			TextEdits: []analysis.TextEdit{deleteDecl(pass.Fset, o.file, o.decl)},
+		}}
+		if target := similar(underTest, old, tested, underTest != pass.Pkg, inTestFile); target != "" {
+			newName := name[:o.word] + target + name[o.word+len(old):]
+			if r, _ := utf8.DecodeRuneInString(target); unicode.IsLower(r) && name[o.word-1] != '_' {
+				newName = name[:o.word] + "_" + newName[o.word:] // Test_f tests an unexported f
+			}
+			if pass.Pkg.Scope().Lookup(newName) == nil {
+				edits := []analysis.TextEdit{{
+					Pos:     o.decl.Name.Pos(),
+					End:     o.decl.Name.End(),
+					NewText: []byte(newName),
+				}}
+				for _, id := range undeclared {
+					if id.Name == old {
+						edits = append(edits, analysis.TextEdit{
+							Pos:     id.Pos(),
+							End:     id.End(),
+							NewText: []byte(target),
+						})
+					}
+				}
+				fixes = append(fixes, analysis.SuggestedFix{
+					Message:   fmt.Sprintf("Retarget %s to %s", name, target),
+					TextEdits: edits,
+				})
+			}
+		}
+
+		pass.Report(analysis.Diagnostic{
+			Pos:            o.decl.Name.Pos(),
+			End:            o.decl.Name.End(),
+			Message:        fmt.Sprintf("%s tests %s, which package %s does not declare", name, old, underTest.Name()),
+			SuggestedFixes: fixes,
+		})
+	}
+	return nil, nil
+}
+
+// deleteDecl returns the edit that deletes decl, a declaration of
+// file, along with its doc comment, its line comment, and the blank
+// lines before it.
+func deleteDecl(fset *token.FileSet, file *ast.File, decl *ast.FuncDecl) analysis.TextEdit {
+	start, end := decl.Pos(), decl.End()
+	if decl.Doc != nil {
+		start = decl.Doc.Pos()
+	}
+	// Delete from the end of the preceding declaration,
+	// unless there are other comments in between.
+	prev := file.Name.End()
+	for _, d := range file.Decls {
+		if d == decl {
+			break
+		}
+		prev = d.End()
+	}
+	line := safetoken.EndPosition(fset, end).Line
+	for _, c := range file.Comments {
+		if prev < c.Pos() && c.End() < start {
+			prev = start
+		}
+		if c.Pos() >= end && safetoken.StartPosition(fset, c.Pos()).Line == line {
+			end = c.End()
+		}
+	}
+	return analysis.TextEdit{Pos: prev, End: end}
+}
+
+// similar returns the name of the function or type of pkg that is
+// most similar to old, among those that are not tested and not
+// declared in test files, or "" if none is similar enough. If exported
+// is set, only exported names are considered.
+func similar(pkg *types.Package, old string, tested map[string]bool, exported bool, inTestFile func(token.Pos) bool) string {
+	best, bestDist := "", 0
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		switch obj.(type) {
+		case *types.Func, *types.TypeName:
+		default:
+			continue
+		}
+		if tested[name] || exported && !obj.Exported() || inTestFile(obj.Pos()) || name == "main" {
+			continue
+		}
+		d := distance(old, name)
+		if 2*d <= max(len(old), len(name)) && (best == "" || d < bestDist) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// distance returns the Levenshtein distance between x and y.
+func distance(x, y string) int {
+	row := make([]int, len(y)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		diag := row[0]
+		row[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			diag, row[j] = row[j], min(row[j]+1, row[j-1]+1, diag+cost)
+		}
+	}
+	return row[len(y)]
+}
@@ -0,0 +1,11 @@
+package a
+
+func LoadConfig(s string) int { return len(s) }
+
+func Format(x int) string { return "" }
+
+type T struct{}
+
+func (T) Method() {}
+
+func helper() {}
@@ -0,0 +1,33 @@
+package a
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	if Format(1) != "" {
+		t.Error("Format failed")
+	}
+}
+
+// TestParseConfig tests ParseConfig, which was renamed to LoadConfig.
+func TestParseConfig(t *testing.T) { // want `TestParseConfig tests ParseConfig, which package a does not declare`
+	if ParseConfig("1") != 1 {
+		t.Error("ParseConfig failed")
+	}
+}
+
+func TestT_Method(t *testing.T) {}
+
+func TestRemoved(t *testing.T) {} // want `TestRemoved tests Removed, which package a does not declare`
+
+func BenchmarkUnrelated(b *testing.B) { // want `BenchmarkUnrelated tests Unrelated, which package a does not declare`
+	for b.Loop() {
+	}
+}
+
+func TestHelper(t *testing.T) {
+	helper()
+}
+
+func TestMain(m *testing.M) {}
+
+func Test(t *testing.T) {}
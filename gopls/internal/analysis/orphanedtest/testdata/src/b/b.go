@@ -0,0 +1,5 @@
+package b
+
+func Decode(s string) int { return len(s) }
+
+func EncodeString(x int) string { return "" }
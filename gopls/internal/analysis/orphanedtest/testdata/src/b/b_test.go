@@ -0,0 +1,14 @@
+package b_test
+
+import (
+	"b"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	b.Decode("")
+}
+
+func TestEncode(t *testing.T) { // want `TestEncode tests Encode, which package b does not declare`
+	b.Encode(1)
+}
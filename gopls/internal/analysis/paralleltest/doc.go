@@ -0,0 +1,47 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package paralleltest defines an analyzer that reports tests that
+// could call t.Parallel.
+//
+// # Analyzer paralleltest
+//
+// paralleltest: report tests and subtests that could run in parallel
+//
+// The paralleltest analyzer reports test functions, and subtests
+// started by t.Run with a function literal, that do not call
+// t.Parallel although they appear safe to run in parallel with other
+// tests. Its suggested fix inserts a call to t.Parallel at the start
+// of the function.
+//
+// A test or subtest is considered unsafe to run in parallel if it:
+//
+//   - assigns to, or takes the address of, a variable declared
+//     outside its function, such as a package-level variable or, for
+//     a subtest, a variable of the enclosing test;
+//   - changes the environment or working directory of the process,
+//     with os.Setenv, os.Chdir, t.Setenv, t.Chdir and the like;
+//   - changes other process-wide state, with flag.Set, log.SetOutput
+//     or rand.Seed, for instance;
+//   - in the case of a subtest, uses the *testing.T of its parent, or
+//     is started by a function containing a defer statement, which
+//     would run before the parallel subtest.
+//
+// The analysis considers only the body of the test: a test that
+// changes shared state by calling a helper function may be reported.
+//
+// Subtests of the table-driven form generated by the "Add test for
+// function" code action are recognized:
+//
+//	for _, tt := range tests {
+//		t.Run(tt.name, func(t *testing.T) {
+//			got := f(tt.input)
+//			...
+//		})
+//	}
+//
+// Before Go 1.22, all iterations of a loop share its variables, so
+// the fix for such a subtest also declares a copy (tt := tt) of each
+// loop variable used by the subtest before the call to t.Run.
+package paralleltest
@@ -0,0 +1,341 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package paralleltest
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/versions"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "paralleltest",
+	Doc:      analysisinternal.MustExtractDoc(doc, "paralleltest"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/paralleltest",
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if !analysisinternal.Imports(pass.Pkg, "testing") {
+		return nil, nil
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	info := pass.TypesInfo
+
+	for curFile := range cursor.Root(inspect).Children() {
+		file := curFile.Node().(*ast.File)
+		if !strings.HasSuffix(pass.Fset.File(file.Pos()).Name(), "_test.go") {
+			continue
+		}
+		before122 := versions.Before(versions.FileVersion(info, file), "go1.22")
+
+		for curDecl := range curFile.Preorder((*ast.FuncDecl)(nil)) {
+			decl := curDecl.Node().(*ast.FuncDecl)
+			t := testParam(info, decl)
+			if t == nil || decl.Body == nil || !canParallelize(info, decl, decl.Body, t) {
+				continue
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            decl.Name.Pos(),
+				End:            decl.Name.End(),
+				Message:        fmt.Sprintf("%s could run in parallel with other tests", decl.Name.Name),
+				SuggestedFixes: parallelFix(pass, decl.Body, t, nil),
+			})
+		}
+
+		for curCall := range curFile.Preorder((*ast.CallExpr)(nil)) {
+			checkSubtest(pass, curCall, before122)
+		}
+	}
+	return nil, nil
+}
+
+// checkSubtest reports the subtest started by the call at curCall, if
+// it is a call of the form t.Run(name, func(t *testing.T) { ... })
+// whose function could run in parallel with the other subtests.
+func checkSubtest(pass *analysis.Pass, curCall cursor.Cursor, before122 bool) {
+	info := pass.TypesInfo
+	call := curCall.Node().(*ast.CallExpr)
+	if len(call.Args) != 2 || !analysisinternal.IsMethodNamed(typeutil.Callee(info, call), "testing", "T", "Run") {
+		return
+	}
+	lit, ok := call.Args[1].(*ast.FuncLit)
+	if !ok || len(lit.Type.Params.List) != 1 || len(lit.Type.Params.List[0].Names) != 1 {
+		return
+	}
+	t, _ := info.Defs[lit.Type.Params.List[0].Names[0]].(*types.Var)
+	if t == nil || t.Name() == "_" {
+		return
+	}
+
+	// Find the function that starts the subtest, and the variables
+	// of the loops within it that enclose the call.
+	var (
+		parentBody *ast.BlockStmt
+		loopVars   = make(map[*types.Var]bool)
+	)
+	for cur := curCall.Parent(); parentBody == nil; cur = cur.Parent() {
+		switch n := cur.Node().(type) {
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				for _, e := range []ast.Expr{n.Key, n.Value} {
+					if id, ok := e.(*ast.Ident); ok {
+						if v, ok := info.Defs[id].(*types.Var); ok {
+							loopVars[v] = true
+						}
+					}
+				}
+			}
+		case *ast.ForStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+				for _, e := range init.Lhs {
+					if v, ok := info.Defs[e.(*ast.Ident)].(*types.Var); ok {
+						loopVars[v] = true
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			parentBody = n.Body
+		case *ast.FuncLit:
+			parentBody = n.Body
+		case *ast.File:
+			return
+		}
+	}
+	// The deferred calls of the parent run when it returns, before
+	// its parallel subtests resume.
+	if hasDefer(parentBody) {
+		return
+	}
+	if !canParallelize(info, lit, lit.Body, t) {
+		return
+	}
+
+	// Before go1.22, each iteration of a loop shares its variables,
+	// so the subtest must use copies of those it uses.
+	var copies []analysis.TextEdit
+	if before122 && len(loopVars) > 0 {
+		var used []*types.Var
+		seen := make(map[*types.Var]bool)
+		ast.Inspect(lit.Body, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if v, ok := info.Uses[id].(*types.Var); ok && loopVars[v] && !seen[v] {
+					seen[v] = true
+					used = append(used, v)
+				}
+			}
+			return true
+		})
+		if len(used) > 0 {
+			stmt, ok := curCall.Parent().Node().(*ast.ExprStmt)
+			if !ok {
+				return // no place to declare the copies
+			}
+			sort.Slice(used, func(i, j int) bool { return used[i].Pos() < used[j].Pos() })
+			indent := indentOf(pass.Fset, stmt.Pos())
+			var b strings.Builder
+			for _, v := range used {
+				fmt.Fprintf(&b, "%s := %s\n%s", v.Name(), v.Name(), indent)
+			}
+			copies = append(copies, analysis.TextEdit{Pos: stmt.Pos(), End: stmt.Pos(), NewText: []byte(b.String())})
+		}
+	}
+
+	pass.Report(analysis.Diagnostic{
+		Pos:            lit.Type.Pos(),
+		End:            lit.Type.End(),
+		Message:        fmt.Sprintf("subtest %s could run in parallel with other subtests", analysisinternal.Format(pass.Fset, call.Args[0])),
+		SuggestedFixes: parallelFix(pass, lit.Body, t, copies),
+	})
+}
+
+// testParam returns the *testing.T parameter of decl if it is a test
+// function, or nil if it is not, or if the parameter is unnamed.
+func testParam(info *types.Info, decl *ast.FuncDecl) *types.Var {
+	if decl.Recv != nil ||
+		decl.Type.TypeParams != nil ||
+		decl.Type.Results != nil && len(decl.Type.Results.List) > 0 ||
+		len(decl.Type.Params.List) != 1 ||
+		len(decl.Type.Params.List[0].Names) != 1 {
+		return nil
+	}
+	name := decl.Name.Name
+	if !strings.HasPrefix(name, "Test") {
+		return nil
+	}
+	if r, _ := utf8.DecodeRuneInString(name[len("Test"):]); unicode.IsLower(r) {
+		return nil // e.g. Testify
+	}
+	v, _ := info.Defs[decl.Type.Params.List[0].Names[0]].(*types.Var)
+	if v == nil || v.Name() == "_" || !analysisinternal.IsPointerToNamed(v.Type(), "testing", "T") {
+		return nil
+	}
+	return v
+}
+
+// canParallelize reports whether the function fn, with the given body
+// and *testing.T parameter t, neither calls t.Parallel nor appears to
+// be unsafe to run in parallel.
+func canParallelize(info *types.Info, fn ast.Node, body *ast.BlockStmt, t *types.Var) bool {
+	if len(body.List) == 0 {
+		return false
+	}
+	isOuter := func(v *types.Var) bool {
+		return v != nil && (v.Pos() < fn.Pos() || v.Pos() >= fn.End())
+	}
+	// outer reports whether e denotes a variable, or a part of one,
+	// declared outside fn.
+	outer := func(e ast.Expr) bool {
+		return isOuter(rootVar(info, e))
+	}
+	ok := true
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if outer(lhs) {
+						ok = false
+					}
+				}
+			}
+		case *ast.IncDecStmt:
+			if outer(n.X) {
+				ok = false
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN && (n.Key != nil && outer(n.Key) || n.Value != nil && outer(n.Value)) {
+				ok = false
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND && outer(n.X) {
+				ok = false // may be assigned through the pointer
+			}
+		case *ast.Ident:
+			if v, isVar := info.Uses[n].(*types.Var); isVar && isOuter(v) && analysisinternal.IsPointerToNamed(v.Type(), "testing", "T") {
+				ok = false // the test of a parent, which may have returned
+			}
+		case *ast.CallExpr:
+			callee := typeutil.Callee(info, n)
+			if analysisinternal.IsMethodNamed(callee, "testing", "T", "Parallel") {
+				if sel, isSel := ast.Unparen(n.Fun).(*ast.SelectorExpr); isSel && rootVar(info, sel.X) == t {
+					ok = false // already parallel
+				}
+			}
+			if mutatesProcess(callee) {
+				ok = false
+			}
+			// A call of a method with a pointer receiver on a
+			// variable takes its address.
+			if sel, isSel := ast.Unparen(n.Fun).(*ast.SelectorExpr); isSel {
+				if s := info.Selections[sel]; s != nil && s.Kind() == types.MethodVal {
+					_, ptrRecv := s.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer)
+					_, ptrX := info.TypeOf(sel.X).Underlying().(*types.Pointer)
+					if ptrRecv && !ptrX && outer(sel.X) {
+						ok = false
+					}
+				}
+			}
+		}
+		return ok
+	})
+	return ok
+}
+
+// rootVar returns the variable of which e denotes a part, such as v in
+// v, v.f, v[i], or *v, or nil if there is none.
+func rootVar(info *types.Info, e ast.Expr) *types.Var {
+	for {
+		switch x := ast.Unparen(e).(type) {
+		case *ast.Ident:
+			v, _ := info.Uses[x].(*types.Var)
+			return v
+		case *ast.SelectorExpr:
+			if info.Selections[x] == nil {
+				v, _ := info.Uses[x.Sel].(*types.Var) // qualified identifier
+				return v
+			}
+			e = x.X
+		case *ast.IndexExpr:
+			e = x.X
+		case *ast.StarExpr:
+			e = x.X
+		default:
+			return nil
+		}
+	}
+}
+
+// mutatesProcess reports whether fn changes state shared by all the
+// tests of the process, such as the environment.
+func mutatesProcess(fn types.Object) bool {
+	return analysisinternal.IsFunctionNamed(fn, "os", "Setenv", "Unsetenv", "Clearenv", "Chdir") ||
+		analysisinternal.IsFunctionNamed(fn, "syscall", "Setenv", "Unsetenv", "Clearenv", "Chdir") ||
+		analysisinternal.IsMethodNamed(fn, "testing", "T", "Setenv", "Chdir") ||
+		analysisinternal.IsFunctionNamed(fn, "flag", "Set") ||
+		analysisinternal.IsFunctionNamed(fn, "log", "SetOutput", "SetFlags", "SetPrefix") ||
+		analysisinternal.IsFunctionNamed(fn, "math/rand", "Seed")
+}
+
+// hasDefer reports whether body contains a defer statement, other
+// than in a function literal.
+func hasDefer(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.DeferStmt:
+			found = true
+		case *ast.FuncLit:
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// parallelFix returns a fix that inserts a call to t.Parallel at the
+// start of body, along with the edits of extra.
+func parallelFix(pass *analysis.Pass, body *ast.BlockStmt, t *types.Var, extra []analysis.TextEdit) []analysis.SuggestedFix {
+	first := body.List[0]
+	tokFile := pass.Fset.File(body.Pos())
+	var edit analysis.TextEdit
+	if line := safetoken.Line(tokFile, body.Lbrace); line == safetoken.Line(tokFile, first.Pos()) {
+		// A function on a single line.
+		edit = analysis.TextEdit{Pos: first.Pos(), End: first.Pos(), NewText: fmt.Appendf(nil, "%s.Parallel(); ", t.Name())}
+	} else {
+		// Insert a line after that of the brace, which may end
+		// with a comment.
+		pos := tokFile.LineStart(line + 1)
+		edit = analysis.TextEdit{Pos: pos, End: pos, NewText: fmt.Appendf(nil, "%s%s.Parallel()\n", indentOf(pass.Fset, first.Pos()), t.Name())}
+	}
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("Call %s.Parallel", t.Name()),
+		TextEdits: append(extra, edit),
+	}}
+}
+
+// indentOf returns the indentation of the line of pos, assuming tabs.
+func indentOf(fset *token.FileSet, pos token.Pos) string {
+	return strings.Repeat("\t", safetoken.StartPosition(fset, pos).Column-1)
+}
@@ -0,0 +1,102 @@
+package a
+
+import (
+	"os"
+	"testing"
+)
+
+var counter int
+
+func add(x, y int) int { return x + y }
+
+func TestAdd(t *testing.T) { // want "TestAdd could run in parallel with other tests"
+	if add(1, 2) != 3 {
+		t.Error("1 + 2 != 3")
+	}
+}
+
+func TestOneLine(t *testing.T) { _ = add(1, 2) } // want "TestOneLine could run in parallel with other tests"
+
+func TestParallel(t *testing.T) {
+	t.Parallel()
+	_ = add(1, 2)
+}
+
+func TestGlobal(t *testing.T) {
+	counter++ // nope: writes a package-level variable
+}
+
+func TestGlobalAddress(t *testing.T) {
+	p := &counter // nope: the variable may be written through p
+	_ = p
+}
+
+func TestSetenv(t *testing.T) {
+	t.Setenv("HOME", "/tmp") // nope: t.Setenv panics in a parallel test
+}
+
+func TestChdir(t *testing.T) {
+	os.Chdir("/") // nope: changes the working directory
+}
+
+func TestEmpty(t *testing.T) {}
+
+func TestUnnamed(_ *testing.T) {
+	_ = add(1, 2)
+}
+
+func Testify(t *testing.T) { // nope: not a test
+	_ = add(1, 2)
+}
+
+// The form of the tests generated by the "Add test for function" code action.
+func TestAddTable(t *testing.T) { // want "TestAddTable could run in parallel with other tests"
+	tests := []struct {
+		name string // description of this test case
+		// Named input parameters for target function.
+		x    int
+		y    int
+		want int
+	}{
+		{"zero", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) { // want "subtest tt.name could run in parallel with other subtests"
+			got := add(tt.x, tt.y)
+			// TODO: update the condition below to compare got with tt.want.
+			if true {
+				t.Errorf("add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtestWrites(t *testing.T) { // want "TestSubtestWrites could run in parallel with other tests"
+	var total int
+	for _, x := range []int{1, 2} {
+		t.Run("sum", func(t *testing.T) {
+			total += x // nope: writes a variable of the parent
+		})
+	}
+	_ = total
+}
+
+func TestSubtestDefer(t *testing.T) { // want "TestSubtestDefer could run in parallel with other tests"
+	f, _ := os.CreateTemp("", "")
+	defer f.Close()
+	t.Run("file", func(t *testing.T) {
+		_ = f.Name() // nope: the file is closed before the subtest runs
+	})
+}
+
+func TestSubtestParent(t *testing.T) { // want "TestSubtestParent could run in parallel with other tests"
+	t.Run("parent", func(st *testing.T) {
+		t.Log("parent") // nope: uses the *testing.T of the parent
+	})
+}
+
+func TestSubtestSetenv(t *testing.T) {
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("HOME", "/tmp") // nope
+	})
+}
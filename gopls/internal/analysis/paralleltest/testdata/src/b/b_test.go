@@ -0,0 +1,37 @@
+//go:build go1.21
+
+package b
+
+import "testing"
+
+func double(x int) int { return 2 * x }
+
+func TestDouble(t *testing.T) { // want "TestDouble could run in parallel with other tests"
+	tests := []struct {
+		name string
+		x    int
+		want int
+	}{
+		{"one", 1, 2},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) { // want "subtest tt.name could run in parallel with other subtests"
+			if got := double(tt.x); got != tt.want {
+				t.Errorf("case %d: double(%d) = %d, want %d", i, tt.x, got, tt.want)
+			}
+		})
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) { // want "subtest tt.name could run in parallel with other subtests"
+			_ = double(tt.x)
+		})
+	}
+	for _, tt := range tests {
+		if !t.Run(tt.name, func(t *testing.T) { // nope: no place to copy tt
+			_ = double(tt.x)
+		}) {
+			break
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package timeafter defines an analyzer that reports calls to
+// time.After in select statements within loops.
+//
+// # Analyzer timeafter
+//
+// timeafter: report calls to time.After in select statements within loops
+//
+// The timeafter analyzer reports a call to time.After in a case of a
+// select statement that is executed by each iteration of a loop:
+//
+//	for {
+//		select {
+//		case msg := <-ch:
+//			handle(msg)
+//		case <-time.After(time.Minute): // time.After in a loop creates a new timer on each iteration
+//			return
+//		}
+//	}
+//
+// Each iteration creates a timer that, before Go 1.23, is not
+// released until it fires, even when another case is selected, so a
+// busy loop accumulates timers. From Go 1.23, unreferenced timers are
+// released, but each iteration still allocates a new one.
+//
+// The suggested fix creates a single timer before the loop, stops it
+// when the function returns, and resets it before each execution of
+// the select statement, preserving the timeout of each iteration:
+//
+//	timer := time.NewTimer(time.Minute)
+//	defer timer.Stop()
+//	for {
+//		timer.Reset(time.Minute)
+//		select {
+//		case msg := <-ch:
+//			handle(msg)
+//		case <-timer.C:
+//			return
+//		}
+//	}
+//
+// Before Go 1.23, the fix also stops the timer and drains its channel
+// before resetting it. The fix is offered only if the duration can be
+// evaluated before the loop, and if the loop is not itself in a loop.
+package timeafter
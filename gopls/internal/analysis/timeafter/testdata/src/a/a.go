@@ -0,0 +1,65 @@
+package a
+
+import "time"
+
+func handle(int) {}
+
+func idle(ch chan int, d time.Duration) {
+	for {
+		select {
+		case msg := <-ch:
+			handle(msg)
+		case <-time.After(d): // want "time.After in a loop creates a new timer on each iteration"
+			return
+		}
+	}
+}
+
+func labeled(ch chan int) {
+loop:
+	for range 3 {
+		select {
+		case <-ch:
+		case <-time.After(time.Duration(2) * time.Second): // want "time.After in a loop creates a new timer on each iteration"
+			break loop
+		}
+	}
+}
+
+func nested(chs [][]chan int) {
+	for _, row := range chs {
+		for _, ch := range row {
+			select {
+			case <-ch:
+			// No fix: the loop is in a loop.
+			case <-time.After(time.Second): // want "time.After in a loop"
+			}
+		}
+	}
+}
+
+func loopVar(chs []chan int) {
+	for i, ch := range chs {
+		select {
+		case <-ch:
+		// No fix: i is declared by the loop.
+		case <-time.After(time.Duration(i) * time.Second): // want "time.After in a loop"
+		}
+	}
+}
+
+func notInLoop(ch chan int) {
+	select {
+	case <-ch:
+	case <-time.After(time.Second): // nope: not in a loop
+	}
+	for {
+		<-time.After(time.Second) // nope: not in a select
+		go func() {
+			select {
+			case <-ch:
+			case <-time.After(time.Second): // nope: not in a loop of this function
+			}
+		}()
+	}
+}
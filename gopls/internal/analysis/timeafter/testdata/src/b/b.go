@@ -0,0 +1,15 @@
+//go:build go1.22
+
+package b
+
+import "time"
+
+func idle(ch chan int, timer int) {
+	for {
+		select {
+		case <-ch:
+		case <-time.After(time.Minute): // want "time.After in a loop creates a new timer on each iteration, which is not released until it fires"
+			return
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package timeafter
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/versions"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "timeafter",
+	Doc:      analysisinternal.MustExtractDoc(doc, "timeafter"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/timeafter",
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if !analysisinternal.Imports(pass.Pkg, "time") {
+		return nil, nil
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	for curFile := range cursor.Root(inspect).Children() {
+		file := curFile.Node().(*ast.File)
+		before123 := versions.Before(versions.FileVersion(pass.TypesInfo, file), "go1.23")
+		for curCall := range curFile.Preorder((*ast.CallExpr)(nil)) {
+			curSelect, curLoop, ok := selectInLoop(pass.TypesInfo, curCall)
+			if !ok {
+				continue
+			}
+			call := curCall.Node().(*ast.CallExpr)
+			msg := "time.After in a loop creates a new timer on each iteration"
+			if before123 {
+				msg += ", which is not released until it fires"
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            call.Pos(),
+				End:            call.End(),
+				Message:        msg,
+				SuggestedFixes: timerFix(pass, curCall, curSelect, curLoop, before123),
+			})
+		}
+	}
+	return nil, nil
+}
+
+// selectInLoop reports whether curCall is a call to time.After that
+// is received from in a case of a select statement executed by each
+// iteration of a loop within the same function, and returns the
+// select statement and the innermost such loop.
+func selectInLoop(info *types.Info, curCall cursor.Cursor) (curSelect, curLoop cursor.Cursor, ok bool) {
+	call := curCall.Node().(*ast.CallExpr)
+	if len(call.Args) != 1 || !analysisinternal.IsFunctionNamed(typeutil.Callee(info, call), "time", "After") {
+		return
+	}
+	if recv, isRecv := curCall.Parent().Node().(*ast.UnaryExpr); !isRecv || recv.Op != token.ARROW {
+		return
+	}
+	inSelect := false
+	for cur := curCall.Parent(); ; cur = cur.Parent() {
+		switch n := cur.Node().(type) {
+		case *ast.CommClause:
+			if inSelect || n.Comm == nil || call.Pos() < n.Comm.Pos() || call.End() > n.Comm.End() {
+				return // not in the communication of the case
+			}
+			curSelect, inSelect = cur.Parent().Parent(), true // CommClause -> BlockStmt -> SelectStmt
+		case *ast.ForStmt:
+			if inSelect && inBlock(n.Body, call) {
+				return curSelect, cur, true
+			}
+		case *ast.RangeStmt:
+			if inSelect && inBlock(n.Body, call) {
+				return curSelect, cur, true
+			}
+		case *ast.FuncDecl, *ast.FuncLit, *ast.File:
+			return
+		}
+	}
+}
+
+// inBlock reports whether n is within block.
+func inBlock(block *ast.BlockStmt, n ast.Node) bool {
+	return block.Pos() <= n.Pos() && n.End() <= block.End()
+}
+
+// timerFix returns a fix that replaces the call to time.After at
+// curCall by the channel of a timer created before the loop at
+// curLoop, and reset before the select statement at curSelect.
+func timerFix(pass *analysis.Pass, curCall, curSelect, curLoop cursor.Cursor, before123 bool) []analysis.SuggestedFix {
+	info := pass.TypesInfo
+	call := curCall.Node().(*ast.CallExpr)
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil // dot import
+	}
+	pkgName := sel.X.(*ast.Ident).Name
+
+	// Insert the timer before the loop, or its label.
+	loopStmt := curLoop.Node()
+	if labeled, ok := curLoop.Parent().Node().(*ast.LabeledStmt); ok {
+		loopStmt = labeled
+		curLoop = curLoop.Parent()
+	}
+	var funcNode ast.Node
+	for cur := curLoop.Parent(); funcNode == nil; cur = cur.Parent() {
+		switch n := cur.Node().(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			return nil // the deferred Stop would accumulate
+		case *ast.FuncDecl, *ast.FuncLit:
+			funcNode = n
+		case *ast.File:
+			return nil
+		}
+	}
+
+	// The loop must contain no other call to time.After that we would
+	// replace, since both fixes would declare the same timer.
+	for cur := range curLoop.Preorder((*ast.CallExpr)(nil)) {
+		if cur != curCall {
+			if _, _, ok := selectInLoop(info, cur); ok {
+				return nil
+			}
+		}
+	}
+
+	// The duration must be evaluable before the loop.
+	d := call.Args[0]
+	evaluable := true
+	ast.Inspect(d, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			if v, ok := info.Uses[n].(*types.Var); ok && !v.IsField() && v.Pos() >= loopStmt.Pos() {
+				evaluable = false
+			}
+		case *ast.CallExpr:
+			if !info.Types[n.Fun].IsType() {
+				evaluable = false // may have effects
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				evaluable = false
+			}
+		case *ast.FuncLit:
+			evaluable = false
+		}
+		return evaluable
+	})
+	if !evaluable {
+		return nil
+	}
+
+	// Choose a name for the timer that is not used in the function.
+	var name string
+	for _, candidate := range []string{"timer", "timeout", "timer2"} {
+		if !usesName(funcNode, candidate) {
+			name = candidate
+			break
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	tokFile := pass.Fset.File(call.Pos())
+	indent := func(pos token.Pos) string {
+		return strings.Repeat("\t", safetoken.Position(tokFile, pos).Column-1) // assume tabs
+	}
+	loopIndent, selIndent := indent(loopStmt.Pos()), indent(curSelect.Node().Pos())
+	dur := analysisinternal.Format(pass.Fset, d)
+
+	reset := fmt.Sprintf("%s.Reset(%s)\n%s", name, dur, selIndent)
+	if before123 {
+		// Stop the timer and drain its channel before Reset.
+		reset = fmt.Sprintf("if !%[1]s.Stop() {\n%[2]s\tselect {\n%[2]s\tcase <-%[1]s.C:\n%[2]s\tdefault:\n%[2]s\t}\n%[2]s}\n%[2]s", name, selIndent) + reset
+	}
+	return []analysis.SuggestedFix{{
+		Message: fmt.Sprintf("Use a single timer created by %s.NewTimer", pkgName),
+		TextEdits: []analysis.TextEdit{
+			{
+				Pos:     loopStmt.Pos(),
+				End:     loopStmt.Pos(),
+				NewText: fmt.Appendf(nil, "%s := %s.NewTimer(%s)\n%sdefer %s.Stop()\n%s", name, pkgName, dur, loopIndent, name, loopIndent),
+			},
+			{
+				Pos:     curSelect.Node().Pos(),
+				End:     curSelect.Node().Pos(),
+				NewText: []byte(reset),
+			},
+			{
+				Pos:     call.Pos(),
+				End:     call.End(),
+				NewText: []byte(name + ".C"),
+			},
+		},
+	}}
+}
+
+// usesName reports whether name appears as an identifier within n.
+func usesName(n ast.Node, name string) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
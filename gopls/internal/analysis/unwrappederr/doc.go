@@ -0,0 +1,39 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package unwrappederr defines an analyzer that reports errors from
+// other packages that are returned without added context.
+//
+// # Analyzer unwrappederr
+//
+// unwrappederr: report errors from other packages returned without wrapping
+//
+// The unwrappederr analyzer reports statements that return an error,
+// obtained from a call to a function or method of another package,
+// unchanged, as in:
+//
+//	data, err := os.ReadFile(name)
+//	if err != nil {
+//		return nil, err
+//	}
+//
+// Such an error lacks the context of the returning function, making
+// the eventual message hard to trace to its cause. The suggested fix
+// wraps the error using fmt.Errorf and the %w verb, which preserves
+// it for errors.Is and errors.As:
+//
+//	return nil, fmt.Errorf("load: %w", err)
+//
+// Only errors that are returned within an "if err != nil" statement
+// that immediately follows the call that assigns them are reported.
+//
+// Some errors are sentinel values that callers compare using ==, such
+// as io.EOF, which a Read method must return unwrapped. A return
+// statement within an if statement that compares the error with one
+// of the sentinels listed by the -sentinels flag (by default io.EOF),
+// using == or errors.Is, is not reported.
+//
+// The -tests flag (true by default) controls whether test files are
+// checked.
+package unwrappederr
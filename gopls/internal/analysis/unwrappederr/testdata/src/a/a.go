@@ -0,0 +1,79 @@
+package a
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+func load(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err // want `error from os.ReadFile is returned without context`
+	}
+	return data, nil
+}
+
+func parse(s string) (int, error) {
+	if n, err := strconv.Atoi(s); err != nil {
+		return 0, err // want `error from strconv.Atoi is returned without context`
+	} else {
+		return n, nil
+	}
+}
+
+func close(f *os.File) error {
+	err := f.Close()
+	if err != nil && f != nil {
+		return err // want `error from \(\*os.File\).Close is returned without context`
+	}
+	return nil
+}
+
+type reader struct{ r io.Reader }
+
+func (r reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil {
+		if err == io.EOF {
+			return n, err // sentinel passthrough
+		}
+		if errors.Is(err, io.EOF) {
+			return n, err // sentinel passthrough
+		}
+		return n, err // want `error from \(io.Reader\).Read is returned without context`
+	}
+	return n, nil
+}
+
+func local() error {
+	err := helper()
+	if err != nil {
+		return err // same package
+	}
+	err = errors.New("x")
+	if err != nil {
+		return err // errors package
+	}
+	return nil
+}
+
+func helper() error { return nil }
+
+func nested() func() error {
+	return func() error {
+		_, err := os.Stat("x")
+		if err != nil {
+			return err // function literal
+		}
+		return nil
+	}
+}
+
+func unrelated(err error) error {
+	if err != nil {
+		return err // not assigned from a call
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unwrappederr
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/astutil/edge"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "unwrappederr",
+	Doc:      analysisinternal.MustExtractDoc(doc, "unwrappederr"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/unwrappederr",
+}
+
+var (
+	tests     = true
+	sentinels = stringSetFlag{"io.EOF": true}
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&tests, "tests", tests, "check test files")
+	Analyzer.Flags.Var(&sentinels, "sentinels",
+		"comma-separated list of sentinel errors, such as io.EOF, that may be returned unwrapped")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	info := pass.TypesInfo
+	errorType := types.Universe.Lookup("error").Type()
+
+	for curFile := range cursor.Root(inspect).Children() {
+		file := curFile.Node().(*ast.File)
+		if !tests && strings.HasSuffix(pass.Fset.File(file.Pos()).Name(), "_test.go") {
+			continue
+		}
+	nextReturn:
+		for curRet := range curFile.Preorder((*ast.ReturnStmt)(nil)) {
+			ret := curRet.Node().(*ast.ReturnStmt)
+			if len(ret.Results) == 0 {
+				continue
+			}
+			id, ok := ret.Results[len(ret.Results)-1].(*ast.Ident)
+			if !ok {
+				continue
+			}
+			v, ok := info.Uses[id].(*types.Var)
+			if !ok || !types.Identical(v.Type(), errorType) {
+				continue
+			}
+
+			// Find the innermost if statement that tests v != nil,
+			// and the enclosing function declaration.
+			var (
+				curIf    cursor.Cursor
+				guarded  bool
+				funcName string
+			)
+			for cur := curRet; funcName == ""; cur = cur.Parent() {
+				ek, _ := cur.Edge()
+				switch parent := cur.Parent().Node().(type) {
+				case *ast.IfStmt:
+					if ek == edge.IfStmt_Body {
+						if comparesSentinel(info, parent.Cond, v) {
+							continue nextReturn // sentinel passthrough
+						}
+						if !guarded && testsNonNil(info, parent.Cond, v) {
+							curIf, guarded = cur.Parent(), true
+						}
+					}
+				case *ast.FuncLit, *ast.File:
+					continue nextReturn
+				case *ast.FuncDecl:
+					funcName = parent.Name.Name
+				}
+			}
+			if !guarded {
+				continue
+			}
+
+			// Find the call that assigns v: in the init statement
+			// of the if statement, or in the preceding statement.
+			assign := curIf.Node().(*ast.IfStmt).Init
+			if assign == nil {
+				if prev, ok := curIf.PrevSibling(); ok {
+					assign, _ = prev.Node().(ast.Stmt)
+				}
+			}
+			call := assigningCall(info, assign, v)
+			if call == nil {
+				continue
+			}
+			fn, ok := typeutil.Callee(info, call).(*types.Func)
+			if !ok || fn.Pkg() == nil || fn.Pkg() == pass.Pkg {
+				continue
+			}
+			switch fn.Pkg().Path() {
+			case "errors", "fmt":
+				continue // the caller provides the context
+			}
+
+			_, prefix, edits := analysisinternal.AddImport(info, file, "fmt", "fmt", "Errorf", ret.Pos())
+			pass.Report(analysis.Diagnostic{
+				Pos:     id.Pos(),
+				End:     id.End(),
+				Message: fmt.Sprintf("error from %s is returned without context", fn.FullName()),
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Wrap error with fmt.Errorf",
+					TextEdits: append(edits, analysis.TextEdit{
+						Pos:     id.Pos(),
+						End:     id.End(),
+						NewText: fmt.Appendf(nil, "%sErrorf(%s, %s)", prefix, strconv.Quote(funcName+": %w"), id.Name),
+					}),
+				}},
+			})
+		}
+	}
+	return nil, nil
+}
+
+// assigningCall returns the call in the assignment statement stmt
+// whose results include v, if any.
+func assigningCall(info *types.Info, stmt ast.Stmt, v *types.Var) *ast.CallExpr {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || len(assign.Rhs) != 1 {
+		return nil
+	}
+	call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	for _, lhs := range assign.Lhs {
+		if id, ok := lhs.(*ast.Ident); ok && (info.Defs[id] == v || info.Uses[id] == v) {
+			return call
+		}
+	}
+	return nil
+}
+
+// testsNonNil reports whether cond is v != nil, or a conjunction
+// including it.
+func testsNonNil(info *types.Info, cond ast.Expr, v *types.Var) bool {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+	switch bin.Op {
+	case token.LAND:
+		return testsNonNil(info, bin.X, v) || testsNonNil(info, bin.Y, v)
+	case token.NEQ:
+		for _, pair := range [][2]ast.Expr{{bin.X, bin.Y}, {bin.Y, bin.X}} {
+			if isVar(info, pair[0], v) && info.Types[pair[1]].IsNil() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// comparesSentinel reports whether cond compares v with one of the
+// sentinel errors, using == or errors.Is, possibly within a
+// disjunction or conjunction.
+func comparesSentinel(info *types.Info, cond ast.Expr, v *types.Var) bool {
+	switch cond := ast.Unparen(cond).(type) {
+	case *ast.BinaryExpr:
+		switch cond.Op {
+		case token.LAND, token.LOR:
+			return comparesSentinel(info, cond.X, v) || comparesSentinel(info, cond.Y, v)
+		case token.EQL:
+			return isVar(info, cond.X, v) && isSentinel(info, cond.Y) ||
+				isVar(info, cond.Y, v) && isSentinel(info, cond.X)
+		}
+	case *ast.CallExpr:
+		if fn, ok := typeutil.Callee(info, cond).(*types.Func); ok && len(cond.Args) == 2 &&
+			analysisinternal.IsFunctionNamed(fn, "errors", "Is") {
+			return isVar(info, cond.Args[0], v) && isSentinel(info, cond.Args[1])
+		}
+	}
+	return false
+}
+
+// isVar reports whether e is a reference to v.
+func isVar(info *types.Info, e ast.Expr, v *types.Var) bool {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	return ok && info.Uses[id] == v
+}
+
+// isSentinel reports whether e refers to one of the sentinel errors.
+func isSentinel(info *types.Info, e ast.Expr) bool {
+	var id *ast.Ident
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return false
+	}
+	v, ok := info.Uses[id].(*types.Var)
+	return ok && v.Pkg() != nil && sentinels[v.Pkg().Path()+"."+v.Name()]
+}
+
+// stringSetFlag is a set of strings, set from a comma-separated list.
+type stringSetFlag map[string]bool
+
+func (ss *stringSetFlag) String() string {
+	var items []string
+	for item := range *ss {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func (ss *stringSetFlag) Set(s string) error {
+	m := make(map[string]bool) // clobber previous value
+	for _, name := range strings.Split(s, ",") {
+		if name != "" {
+			m[name] = true
+		}
+	}
+	*ss = m
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package wallclock defines an analyzer that reports functions that
+// read the wall clock directly.
+//
+// # Analyzer wallclock
+//
+// wallclock: report functions that read the wall clock directly
+//
+// The wallclock analyzer reports each call to time.Now, time.Since, or
+// time.Until within a function or method, as in:
+//
+//	func (c *Cache) expired(e *entry) bool {
+//		return time.Since(e.added) > c.ttl
+//	}
+//
+// The results of such a function depend on the time at which it runs,
+// so its tests cannot be deterministic unless they can substitute a
+// fake clock.
+//
+// The suggested fix injects a clock into the package: it declares
+// the package-level variable
+//
+//	// now returns the current time. Tests may replace it with a fake clock.
+//	var now = time.Now
+//
+// unless the package already declares it, and replaces the calls in
+// the function by calls of now, such as now().Sub(e.added). If the
+// package has no file clock_test.go, the fix also adds one that
+// declares a fake clock, and a helper, useFakeClock, that installs it
+// in place of now until a test completes.
+package wallclock
@@ -0,0 +1,36 @@
+package a
+
+import (
+	"time"
+	t "time"
+)
+
+type Cache struct {
+	ttl time.Duration
+}
+
+type entry struct {
+	added time.Time
+}
+
+func (c *Cache) expired(e *entry) bool {
+	return time.Since(e.added) > c.ttl // want `expired reads the wall clock by calling time.Since; inject a clock to make its tests deterministic`
+}
+
+func deadline(d time.Duration) (time.Time, time.Duration) {
+	return t.Now().Add(d), time.Until(time.Unix(0, 0)) // want `deadline reads the wall clock by calling time.Now` `deadline reads the wall clock by calling time.Until`
+}
+
+func stamp() func() time.Time {
+	return func() time.Time {
+		return time.Now() // want `stamp reads`
+	}
+}
+
+var now = time.Now
+
+var start = time.Now()
+
+func elapsed() time.Duration {
+	return now().Sub(start)
+}
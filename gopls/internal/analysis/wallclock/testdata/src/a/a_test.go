@@ -0,0 +1,5 @@
+package a
+
+import "time"
+
+func setup() time.Time { return time.Now() }
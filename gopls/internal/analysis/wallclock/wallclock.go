@@ -0,0 +1,78 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wallclock
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "wallclock",
+	Doc:      analysisinternal.MustExtractDoc(doc, "wallclock"),
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gopls/internal/analysis/wallclock",
+}
+
+const FixCategory = "wallclock" // recognized by gopls ApplyFix
+
+// ClockFunc returns the function time.Now, time.Since, or time.Until
+// that call calls, or nil if it calls none of them.
+func ClockFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	if fn, ok := typeutil.Callee(info, call).(*types.Func); ok &&
+		analysisinternal.IsFunctionNamed(fn, "time", "Now", "Since", "Until") {
+		return fn
+	}
+	return nil
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	for curFile := range cursor.Root(inspect).Children() {
+		file := curFile.Node().(*ast.File)
+		if strings.HasSuffix(pass.Fset.File(file.Pos()).Name(), "_test.go") {
+			continue
+		}
+		for curDecl := range curFile.Preorder((*ast.FuncDecl)(nil)) {
+			decl := curDecl.Node().(*ast.FuncDecl)
+			if decl.Body == nil {
+				continue
+			}
+			for curCall := range curDecl.Preorder((*ast.CallExpr)(nil)) {
+				call := curCall.Node().(*ast.CallExpr)
+				callee := ClockFunc(pass.TypesInfo, call)
+				if callee == nil {
+					continue
+				}
+				pass.Report(analysis.Diagnostic{
+					Pos:      call.Pos(),
+					End:      call.End(),
+					Category: FixCategory,
+					Message:  fmt.Sprintf("%s reads the wall clock by calling time.%s; inject a clock to make its tests deterministic", decl.Name.Name, callee.Name()),
+					SuggestedFixes: []analysis.SuggestedFix{{
+						Message: fmt.Sprintf("Inject a clock into %s", decl.Name.Name),
+						// No TextEdits => computed by gopls ApplyFix.
+					}},
+				})
+			}
+		}
+	}
+	return nil, nil
+}
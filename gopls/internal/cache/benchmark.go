@@ -0,0 +1,41 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+// A BenchmarkResult records the measurements reported by a run of
+// 'go test -bench' for the benchmarks of a Benchmark function: a
+// sample of each metric, such as ns/op, of the function and of each of
+// its sub-benchmarks, such as BenchmarkFoo/small.
+type BenchmarkResult struct {
+	Names   []string                        // names of the benchmarks, in order of report
+	Samples map[string]map[string][]float64 // name -> unit -> measurements
+}
+
+// A BenchmarkHistory records the two most recent results of a
+// Benchmark function, so that the later may be compared with the
+// earlier.
+//
+// Unlike coverage, benchmark results are not discarded when files
+// change, since their purpose is to measure the effect of changes.
+type BenchmarkHistory struct {
+	Previous *BenchmarkResult // nil until the function has run twice
+	Last     *BenchmarkResult
+}
+
+// BenchmarkKey returns the key of the history of the Benchmark
+// function name of the package pkgPath in a [StateChange].
+func BenchmarkKey(pkgPath PackagePath, name string) string {
+	return string(pkgPath) + " " + name
+}
+
+// Benchmark returns the history of the Benchmark function name of the
+// package pkgPath, or nil if it has not been run.
+func (s *Snapshot) Benchmark(pkgPath PackagePath, name string) *BenchmarkHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, _ := s.benchmarks.Get(BenchmarkKey(pkgPath, name))
+	return h
+}
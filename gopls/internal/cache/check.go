@@ -117,6 +117,26 @@ func (s *Snapshot) TypeCheck(ctx context.Context, ids ...PackageID) ([]*Package,
 	return pkgs, s.forEachPackage(ctx, ids, nil, post)
 }
 
+// ImportPackage returns the types of the specified package as they
+// appear to its importers: it reads them from export data in the
+// cache, if available, and otherwise type-checks the package without
+// function bodies. Unlike [Snapshot.TypeCheck], it records neither
+// syntax nor type information for expressions, and reports no errors,
+// so it is much cheaper for large packages.
+//
+// The resulting package may omit unexported declarations.
+func (s *Snapshot) ImportPackage(ctx context.Context, id PackageID) (*types.Package, error) {
+	b, release := s.acquireTypeChecking()
+	defer release()
+
+	handles, err := s.getPackageHandles(ctx, []PackageID{id})
+	if err != nil {
+		return nil, err
+	}
+	b.addHandles(handles)
+	return b.getImportPackage(ctx, id)
+}
+
 // Package visiting functions used by forEachPackage; see the documentation of
 // forEachPackage for details.
 type (
@@ -0,0 +1,294 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package constructors defines an index of the candidate constructor
+// functions of each named type declared by a package.
+//
+// A candidate constructor of type T is a non-generic package-level
+// function of the same package whose results are T or *T, optionally
+// followed by an error. A candidate factory of T is a method of another
+// non-generic named type of the package with the same results, such as
+// Config.NewClient or Pool.Get.
+//
+// The index is computed from syntax alone, so it may be queried
+// against any type-checked form of the package, whether from syntax
+// or from (possibly shallow) export data. It also records the
+// signatures of the exported package-level functions, and it is
+// serializable, so that it may be stored in the file cache.
+package constructors
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/util/frob"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// An Index maps the names of the named types declared by a package
+// to the names of their candidate constructors.
+type Index struct {
+	byType    map[string][]string // type name -> constructor names, preferred first
+	factories map[string][]string // type name -> factory methods, as "F.M", preferred first
+	funcs     []Func              // exported package-level functions, in declaration order
+}
+
+// A Func describes an exported package-level function of the package.
+type Func struct {
+	Name      string
+	Signature string // the syntax of the signature, such as "[T any](x T) (*U, error)"
+	Type      string // name of the type of which it is a candidate constructor, or ""
+}
+
+// Funcs returns the exported package-level functions of the package,
+// in declaration order.
+func (index *Index) Funcs() []Func {
+	return index.funcs
+}
+
+// NewIndex returns the constructor index of the package whose
+// files are specified.
+func NewIndex(files []*ast.File) *Index {
+	// Record the targets of alias declarations,
+	// so that constructors of T returning an alias of T are found.
+	aliases := make(map[string]string)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.GenDecl); ok {
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok && spec.Assign.IsValid() {
+						if name := typeName(spec.Type); name != "" {
+							aliases[spec.Name.Name] = name
+						}
+					}
+				}
+			}
+		}
+	}
+	resolve := func(name string) string {
+		for range len(aliases) { // bound the steps in case of a cycle
+			target, ok := aliases[name]
+			if !ok {
+				break
+			}
+			name = target
+		}
+		return name
+	}
+
+	index := &Index{
+		byType:    make(map[string][]string),
+		factories: make(map[string][]string),
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if decl.Recv != nil {
+				if len(decl.Recv.List) != 1 {
+					continue
+				}
+				factory := resolve(typeName(decl.Recv.List[0].Type))
+				if tname := resolve(resultTypeName(decl.Type)); factory != "" && tname != "" && tname != factory {
+					index.factories[tname] = append(index.factories[tname], factory+"."+decl.Name.Name)
+				}
+				continue
+			}
+			var fn *Func
+			if decl.Name.IsExported() {
+				index.funcs = append(index.funcs, Func{
+					Name:      decl.Name.Name,
+					Signature: signature(decl.Type),
+				})
+				fn = &index.funcs[len(index.funcs)-1]
+			}
+			tname := resolve(resultTypeName(decl.Type))
+			if tname == "" {
+				continue
+			}
+			index.byType[tname] = append(index.byType[tname], decl.Name.Name)
+			if fn != nil {
+				fn.Type = tname
+			}
+		}
+	}
+	for tname, ctors := range index.byType {
+		sortPreferred(ctors, tname)
+	}
+	for tname, factories := range index.factories {
+		sortPreferred(factories, tname)
+	}
+	return index
+}
+
+// sortPreferred sorts the names of the constructors or factories of
+// the type tname. Functions and methods named NewT are preferred over
+// others that match only the signature criteria.
+func sortPreferred(names []string, tname string) {
+	preferred := func(name string) bool {
+		name = name[strings.IndexByte(name, '.')+1:] // method name of a factory
+		return strings.EqualFold(name, "new"+tname)
+	}
+	slices.SortFunc(names, func(x, y string) int {
+		if px, py := preferred(x), preferred(y); px && !py {
+			return -1
+		} else if py && !px {
+			return +1
+		}
+		return strings.Compare(x, y)
+	})
+}
+
+// resultTypeName returns the name of the type T of the results of a
+// non-generic function type whose results are T or *T, optionally
+// followed by an error, or "" if it has another form.
+func resultTypeName(ftype *ast.FuncType) string {
+	if ftype.TypeParams != nil || ftype.Results == nil {
+		return ""
+	}
+	var results []ast.Expr
+	for _, field := range ftype.Results.List {
+		results = append(results, field.Type)
+		for range max(0, len(field.Names)-1) {
+			results = append(results, field.Type)
+		}
+	}
+	if len(results) == 0 || len(results) > 2 {
+		return ""
+	}
+	if len(results) == 2 && !isIdent(results[1], "error") {
+		return ""
+	}
+	return typeName(results[0])
+}
+
+// Constructors returns the candidate constructors of the named type
+// declared by tname, which must belong to pkg, the package from which
+// to resolve them. Preferred constructors appear first.
+//
+// Constructors absent from pkg, such as the unexported functions
+// omitted from shallow export data, are not reported.
+func (index *Index) Constructors(pkg *types.Package, tname *types.TypeName) []*types.Func {
+	if tname.Pkg() != pkg {
+		return nil
+	}
+	var fns []*types.Func
+	for _, name := range index.byType[tname.Name()] {
+		// The index is syntactic: check the candidate's types.
+		if fn, ok := pkg.Scope().Lookup(name).(*types.Func); ok && fn.Signature().Recv() == nil && constructs(fn, tname) {
+			fns = append(fns, fn)
+		}
+	}
+	return fns
+}
+
+// Factories returns the candidate factory methods of the named type
+// declared by tname, which must belong to pkg, the package from which
+// to resolve them: the methods of the other non-generic named types of
+// pkg, such as Config.NewClient, that construct it. Preferred factories
+// appear first.
+func (index *Index) Factories(pkg *types.Package, tname *types.TypeName) []*types.Func {
+	if tname.Pkg() != pkg {
+		return nil
+	}
+	var fns []*types.Func
+	for _, name := range index.factories[tname.Name()] {
+		factory, method, _ := strings.Cut(name, ".")
+		ftname, ok := pkg.Scope().Lookup(factory).(*types.TypeName)
+		if !ok || ftname == tname {
+			continue
+		}
+		named, ok := types.Unalias(ftname.Type()).(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		// The index is syntactic: check the candidate's types.
+		obj, _, _ := types.LookupFieldOrMethod(named, true, pkg, method)
+		if fn, ok := obj.(*types.Func); ok && fn.Signature().Recv() != nil && constructs(fn, tname) {
+			fns = append(fns, fn)
+		}
+	}
+	return fns
+}
+
+// constructs reports whether the results of fn, a function or method,
+// are those of a candidate constructor of the non-generic named type
+// declared by tname.
+func constructs(fn *types.Func, tname *types.TypeName) bool {
+	sig := fn.Signature()
+	if sig.TypeParams().Len() > 0 {
+		return false
+	}
+	results := sig.Results()
+	if results.Len() == 0 || results.Len() > 2 {
+		return false
+	}
+	if results.Len() == 2 && !types.Identical(results.At(1).Type(), types.Universe.Lookup("error").Type()) {
+		return false
+	}
+	_, named := typesinternal.ReceiverNamed(results.At(0))
+	return named != nil && named.Obj() == tname && named.TypeParams().Len() == 0
+}
+
+// signature returns the syntax of the signature of a function type,
+// including its type parameters, which [types.ExprString] omits.
+func signature(ftype *ast.FuncType) string {
+	sig := strings.TrimPrefix(types.ExprString(ftype), "func")
+	if ftype.TypeParams == nil {
+		return sig
+	}
+	var tparams []string
+	for _, field := range ftype.TypeParams.List {
+		var names []string
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		tparams = append(tparams, strings.Join(names, ", ")+" "+types.ExprString(field.Type))
+	}
+	return "[" + strings.Join(tparams, ", ") + "]" + sig
+}
+
+// typeName returns the name of the type denoted by the expression T
+// or *T, or "" if the expression has neither form.
+func typeName(e ast.Expr) string {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	if id, ok := e.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+func isIdent(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+// -- serial format of index --
+
+var indexCodec = frob.CodecFor[gobIndex]()
+
+// A gobIndex is the serial form of an Index.
+type gobIndex struct {
+	ByType    map[string][]string
+	Factories map[string][]string
+	Funcs     []Func
+}
+
+// Decode decodes data from [Index.Encode].
+func Decode(data []byte) *Index {
+	var gob gobIndex
+	indexCodec.Decode(data, &gob)
+	return &Index{byType: gob.ByType, factories: gob.Factories, funcs: gob.Funcs}
+}
+
+// Encode encodes the index.
+func (index *Index) Encode() []byte {
+	return indexCodec.Encode(gobIndex{ByType: index.byType, Factories: index.factories, Funcs: index.funcs})
+}
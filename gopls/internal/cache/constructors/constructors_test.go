@@ -0,0 +1,143 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package constructors_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/cache/constructors"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+func TestConstructors(t *testing.T) {
+	const src = `package p
+
+type T struct{}
+type U = T
+type G[X any] struct{}
+
+func makeT() T              { return T{} }
+func NewT() (*T, error)     { return nil, nil }
+func newU() *U              { return nil }
+func other() (T, int)       { return T{}, 0 }
+func NewG[X any]() G[X]     { return G[X]{} }
+func (T) Clone() T          { return T{} }
+func generic[X any]() T     { return T{} }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := constructors.NewIndex([]*ast.File{f})
+	checkConstructors(t, index, pkg)
+	checkConstructors(t, constructors.Decode(index.Encode()), pkg)
+}
+
+func checkConstructors(t *testing.T, index *constructors.Index, pkg *types.Package) {
+	t.Helper()
+	for _, test := range []struct {
+		typ  string
+		want []string
+	}{
+		{"T", []string{"NewT", "makeT", "newU"}},
+		{"G", nil},
+	} {
+		tname := pkg.Scope().Lookup(test.typ).(*types.TypeName)
+		var got []string
+		for _, fn := range index.Constructors(pkg, tname) {
+			got = append(got, fn.Name())
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Constructors(%s) = %v, want %v", test.typ, got, test.want)
+		}
+	}
+}
+
+func TestFactories(t *testing.T) {
+	const src = `package p
+
+type Client struct{}
+type Config struct{}
+type Pool struct{}
+type Conn = Client
+type G[X any] struct{}
+
+func (*Config) NewClient() (*Client, error) { return nil, nil }
+func (p *Pool) Get() Conn                   { return Client{} }
+func (Pool) Stats() (Client, int)           { return Client{}, 0 }
+func (Client) Clone() Client                { return Client{} }
+func (G[X]) Make() Client                   { return Client{} }
+func NewConfig() Config                     { return Config{} }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := constructors.NewIndex([]*ast.File{f})
+	for _, index := range []*constructors.Index{index, constructors.Decode(index.Encode())} {
+		for _, test := range []struct {
+			typ  string
+			want []string
+		}{
+			{"Client", []string{"Config.NewClient", "Pool.Get"}},
+			{"Config", nil},
+		} {
+			tname := pkg.Scope().Lookup(test.typ).(*types.TypeName)
+			var got []string
+			for _, fn := range index.Factories(pkg, tname) {
+				_, recv := typesinternal.ReceiverNamed(fn.Signature().Recv())
+				got = append(got, recv.Obj().Name()+"."+fn.Name())
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Factories(%s) = %v, want %v", test.typ, got, test.want)
+			}
+		}
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	const src = `package p
+
+type T struct{}
+
+func NewT() (*T, error)            { return nil, nil }
+func Parse(s string, n int) T      { return T{} }
+func Map[X, Y any](x X) (y Y)      { return }
+func helper() T                    { return T{} }
+func (T) Method()                  {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []constructors.Func{
+		{Name: "NewT", Signature: "() (*T, error)", Type: "T"},
+		{Name: "Parse", Signature: "(s string, n int) T", Type: "T"},
+		{Name: "Map", Signature: "[X, Y any](x X) (y Y)"},
+	}
+	index := constructors.NewIndex([]*ast.File{f})
+	if got := index.Funcs(); !slices.Equal(got, want) {
+		t.Errorf("Funcs() = %+v, want %+v", got, want)
+	}
+	if got := constructors.Decode(index.Encode()).Funcs(); !slices.Equal(got, want) {
+		t.Errorf("Funcs() of decoded index = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/tools/cover"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/persistent"
+	"golang.org/x/tools/internal/diff"
+)
+
+// FileCoverage records the statement coverage of a Go file, as
+// reported by a coverage profile (see "go help testflag").
+//
+// When the file changes, the blocks that the change touches are
+// discarded, since their coverage is no longer known, and the others
+// are moved to follow the text that they cover (see [adjustCoverage]).
+type FileCoverage struct {
+	Blocks []cover.ProfileBlock // sorted by start position
+}
+
+// Lines returns the number of lines in the interval [start, end] of
+// 1-based line numbers that contain a statement that was executed,
+// and the number of lines whose statements were all unexecuted.
+// Lines that contain no statements are not counted.
+func (c *FileCoverage) Lines(start, end int) (covered, uncovered int) {
+	counts := make(map[int]int) // line -> max execution count
+	for _, b := range c.Blocks {
+		if b.NumStmt == 0 || b.EndLine < start || b.StartLine > end {
+			continue
+		}
+		for line := max(b.StartLine, start); line <= min(b.EndLine, end); line++ {
+			counts[line] = max(counts[line], b.Count)
+		}
+	}
+	for _, count := range counts {
+		if count > 0 {
+			covered++
+		} else {
+			uncovered++
+		}
+	}
+	return covered, uncovered
+}
+
+// Coverage returns the coverage of the specified Go file,
+// or nil if none is known.
+func (s *Snapshot) Coverage(uri protocol.DocumentURI) *FileCoverage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cov, _ := s.coverage.Get(uri)
+	return cov
+}
+
+// CoverageOfProfiles maps each file of the coverage profiles to its
+// URI, and returns the coverage of each file of the snapshot's
+// packages.
+//
+// A profile names each file by the path of its package and its base
+// name, except for files outside any module, which it names by their
+// absolute path. The names of files in no package of the snapshot
+// are ignored.
+func (s *Snapshot) CoverageOfProfiles(profiles []*cover.Profile) map[protocol.DocumentURI]*FileCoverage {
+	// Index the Go files of each package by base name.
+	files := make(map[string]protocol.DocumentURI) // "pkgpath/base.go" -> URI
+	for _, mp := range s.MetadataGraph().Packages {
+		if mp.ForTest != "" {
+			continue
+		}
+		for _, uri := range mp.GoFiles {
+			files[path.Join(string(mp.PkgPath), filepath.Base(uri.Path()))] = uri
+		}
+	}
+
+	result := make(map[protocol.DocumentURI]*FileCoverage)
+	for _, profile := range profiles {
+		uri, ok := files[profile.FileName]
+		if !ok && filepath.IsAbs(profile.FileName) {
+			uri, ok = protocol.URIFromPath(profile.FileName), true
+		}
+		if ok {
+			if cov, ok := result[uri]; ok {
+				result[uri] = &FileCoverage{Blocks: mergeBlocks(cov.Blocks, profile.Blocks)}
+			} else {
+				result[uri] = &FileCoverage{Blocks: profile.Blocks}
+			}
+		}
+	}
+	return result
+}
+
+// mergeBlocks returns the union of two sorted lists of blocks of the
+// same file, such as those of the profiles of two test runs, in which
+// the count of a block in both is the larger of its counts.
+func mergeBlocks(x, y []cover.ProfileBlock) []cover.ProfileBlock {
+	type span struct{ startLine, startCol, endLine, endCol int }
+	index := make(map[span]int) // span -> index in merged
+	merged := slices.Clone(x)
+	for i, b := range merged {
+		index[span{b.StartLine, b.StartCol, b.EndLine, b.EndCol}] = i
+	}
+	for _, b := range y {
+		if i, ok := index[span{b.StartLine, b.StartCol, b.EndLine, b.EndCol}]; ok {
+			merged[i].Count = max(merged[i].Count, b.Count)
+		} else {
+			merged = append(merged, b)
+		}
+	}
+	slices.SortStableFunc(merged, func(a, b cover.ProfileBlock) int {
+		if a.StartLine != b.StartLine {
+			return a.StartLine - b.StartLine
+		}
+		return a.StartCol - b.StartCol
+	})
+	return merged
+}
+
+// adjustCoverage returns a clone of the coverage map m, whose files
+// are those of the map files, in which the coverage of each of the
+// changed files follows the change (see [FileCoverage.adjust]).
+func adjustCoverage(m *persistent.Map[protocol.DocumentURI, *FileCoverage], files *fileMap, changes map[protocol.DocumentURI]file.Handle) *persistent.Map[protocol.DocumentURI, *FileCoverage] {
+	m2 := m.Clone()
+	for uri, fh := range changes {
+		cov, ok := m2.Get(uri)
+		if !ok {
+			continue
+		}
+		m2.Delete(uri)
+		old, ok := files.get(uri)
+		if !ok {
+			continue
+		}
+		before, err := old.Content()
+		if err != nil {
+			continue
+		}
+		after, err := fh.Content()
+		if err != nil {
+			continue // e.g. deleted
+		}
+		if cov := cov.adjust(uri, before, after); cov != nil {
+			m2.Set(uri, cov, nil)
+		}
+	}
+	return m2
+}
+
+// adjust returns the coverage of the file uri after the change of its
+// content from before to after, without the blocks that the change
+// overlaps, and with the others moved to follow the text that they
+// cover, or nil if no block remains.
+func (c *FileCoverage) adjust(uri protocol.DocumentURI, before, after []byte) *FileCoverage {
+	edits := diff.Bytes(before, after)
+	if len(edits) == 0 {
+		return c
+	}
+	m0 := protocol.NewMapper(uri, before)
+	m1 := protocol.NewMapper(uri, after)
+	offset := func(line, col int) (int, bool) {
+		pos, err := m0.LineCol8Position(line, col)
+		if err != nil {
+			return 0, false
+		}
+		offset, err := m0.PositionOffset(pos)
+		return offset, err == nil
+	}
+	var blocks []cover.ProfileBlock
+blocks:
+	for _, b := range c.Blocks {
+		start, ok1 := offset(b.StartLine, b.StartCol)
+		end, ok2 := offset(b.EndLine, b.EndCol)
+		if !ok1 || !ok2 {
+			continue // the profile does not match the file
+		}
+		shift := 0
+		for _, e := range edits {
+			if e.Start >= end {
+				break // (edits are sorted)
+			}
+			if e.End > start {
+				continue blocks // the edit touches the block
+			}
+			shift += len(e.New) - (e.End - e.Start)
+		}
+		b.StartLine, b.StartCol = m1.OffsetLineCol8(start + shift)
+		b.EndLine, b.EndCol = m1.OffsetLineCol8(end + shift)
+		blocks = append(blocks, b)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	return &FileCoverage{Blocks: blocks}
+}
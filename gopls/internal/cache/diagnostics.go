@@ -113,6 +113,7 @@ const (
 	Govulncheck            DiagnosticSource = "govulncheck"
 	TemplateError          DiagnosticSource = "template"
 	WorkFileError          DiagnosticSource = "go.work file"
+	TestdataError          DiagnosticSource = "testdata"
 )
 
 // A SuggestedFix represents a suggested fix (for a diagnostic)
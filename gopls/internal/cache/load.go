@@ -284,6 +284,9 @@ func (s *Snapshot) load(ctx context.Context, allowNetwork AllowNetwork, scopes .
 			}
 			updates[mp.ID] = mp
 			s.shouldLoad.Delete(mp.ID)
+			if mp.ForTest != "" {
+				s.testRelations.Delete(mp.ForTest) // e.g. a new external test package
+			}
 		}
 	}
 
@@ -294,6 +297,7 @@ func (s *Snapshot) load(ctx context.Context, allowNetwork AllowNetwork, scopes .
 	meta := s.meta.Update(updates)
 	workspacePackages := computeWorkspacePackagesLocked(ctx, s, meta)
 	s.meta = meta
+	s.testPackages = nil
 	s.workspacePackages = workspacePackages
 
 	s.mu.Unlock()
@@ -166,6 +166,19 @@ func (mp *Package) IsIntermediateTestVariant() bool {
 	return mp.ForTest != "" && mp.ForTest != mp.PkgPath && mp.ForTest+"_test" != mp.PkgPath
 }
 
+// SameModule reports whether the packages a and b belong to the same
+// module, or both belong to none. In a workspace of several modules,
+// such as a go.work workspace, or a view whose modules require other
+// versions of each other, the graph may hold packages of the same path
+// from different modules, of which only those of the same module as a
+// package are its test variants.
+func SameModule(a, b *Package) bool {
+	if a.Module == nil || b.Module == nil {
+		return a.Module == b.Module
+	}
+	return a.Module.Path == b.Module.Path && a.Module.Dir == b.Module.Dir
+}
+
 // A Source maps package IDs to metadata for the packages.
 type Source interface {
 	// Metadata returns the [Package] for the given package ID, or nil if it does
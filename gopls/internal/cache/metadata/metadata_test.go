@@ -0,0 +1,37 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metadata
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestSameModule(t *testing.T) {
+	var (
+		a      = &packages.Module{Path: "example.com/a", Dir: "/work/a"}
+		a2     = &packages.Module{Path: "example.com/a", Dir: "/work/a"}         // a, loaded again
+		aCache = &packages.Module{Path: "example.com/a", Dir: "/cache/a@v1.0.0"} // another version of a
+		b      = &packages.Module{Path: "example.com/b", Dir: "/work/b"}
+	)
+	for _, test := range []struct {
+		x, y *packages.Module
+		want bool
+	}{
+		{a, a, true},
+		{a, a2, true},
+		{a, aCache, false},
+		{a, b, false},
+		{a, nil, false},
+		{nil, a, false},
+		{nil, nil, true}, // e.g. packages of GOPATH
+	} {
+		x, y := &Package{Module: test.x}, &Package{Module: test.y}
+		if got := SameModule(x, y); got != test.want {
+			t.Errorf("SameModule(%v, %v) = %t, want %t", test.x, test.y, got, test.want)
+		}
+	}
+}
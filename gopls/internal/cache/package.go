@@ -83,7 +83,7 @@ func (p *syntaxPackage) methodsets() *methodsets.Index {
 
 func (p *syntaxPackage) tests() *testfuncs.Index {
 	p.testsOnce.Do(func() {
-		p._tests = testfuncs.NewIndex(p.compiledGoFiles, p.typesInfo)
+		p._tests = testfuncs.NewIndex(p.fset, p.compiledGoFiles, p.typesInfo)
 	})
 	return p._tests
 }
@@ -26,6 +26,22 @@ func (s *Snapshot) ParseGo(ctx context.Context, fh file.Handle, mode parser.Mode
 	return pgfs[0], nil
 }
 
+// ParseHeader parses the package clause and imports of the file whose
+// contents are provided by fh, and the comments preceding them, as
+// ParseGo does in [parsego.Header] mode. If a complete parse of the
+// same contents is cached, such as one made to type-check the package
+// or to generate its tests, ParseHeader returns it rather than parsing
+// the file again, so the result may include the declarations after
+// the imports, and its ParseErr may report errors in them. Callers
+// that need only the header, such as those that determine the package
+// of a test file or its imports, should use ParseHeader.
+func (s *Snapshot) ParseHeader(ctx context.Context, fh file.Handle) (*parsego.File, error) {
+	if pgf := s.view.parseCache.parsedFull(ctx, fh); pgf != nil {
+		return pgf, nil
+	}
+	return s.ParseGo(ctx, fh, parsego.Header)
+}
+
 // parseGoImpl parses the Go source file whose content is provided by fh.
 func parseGoImpl(ctx context.Context, fset *token.FileSet, fh file.Handle, mode parser.Mode, purgeFuncBodies bool) (*parsego.File, error) {
 	ext := filepath.Ext(fh.URI().Path())
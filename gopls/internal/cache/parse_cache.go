@@ -253,6 +253,29 @@ func (c *parseCache) startParse(mode parser.Mode, purgeFuncBodies bool, fhs ...f
 	return promises, firstReadError
 }
 
+// parsedFull returns the complete parse of the file fh, if the cache
+// holds one of its current content, or nil. It does not parse the file.
+func (c *parseCache) parsedFull(ctx context.Context, fh file.Handle) *parsego.File {
+	c.mu.Lock()
+	var promise *memoize.Promise
+	if e, ok := c.m[parseKey{uri: fh.URI(), mode: parsego.Full}]; ok && e.hash == fh.Identity().Hash {
+		c.clock++
+		e.atime = c.clock
+		e.walltime = time.Now()
+		heap.Fix(&c.lru, e.lruIndex)
+		promise = e.promise
+	}
+	c.mu.Unlock()
+	if promise == nil {
+		return nil
+	}
+	result, err := promise.Get(ctx, nil)
+	if err != nil {
+		return nil
+	}
+	return result.(*parsego.File)
+}
+
 func (c *parseCache) gc() {
 	const period = 10 * time.Second // gc period
 	timer := time.NewTicker(period)
@@ -7,6 +7,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"go/parser"
 	"go/token"
 	"math/bits"
 	"testing"
@@ -193,6 +194,46 @@ func TestParseCache_Duplicates(t *testing.T) {
 	}
 }
 
+func TestParseCache_ParsedFull(t *testing.T) {
+	skipIfNoParseCache(t)
+
+	ctx := context.Background()
+	uri := protocol.DocumentURI("file:///myfile")
+	fh := makeFakeFileHandle(uri, []byte("package p\n\nconst _ = \"foo\""))
+
+	cache := newParseCache(0)
+	parse := func(mode parser.Mode) *parsego.File {
+		t.Helper()
+		promises, err := cache.startParse(mode, false, fh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := promises[0].Get(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.(*parsego.File)
+	}
+
+	if pgf := cache.parsedFull(ctx, fh); pgf != nil {
+		t.Errorf("parsedFull(fh) before parsing = %p, want nil", pgf)
+	}
+	parse(parsego.Header)
+	if pgf := cache.parsedFull(ctx, fh); pgf != nil {
+		t.Errorf("parsedFull(fh) after parsing the header = %p, want nil", pgf)
+	}
+	full := parse(parsego.Full)
+	if pgf := cache.parsedFull(ctx, fh); pgf != full {
+		t.Errorf("parsedFull(fh) = %p, want the complete parse %p", pgf, full)
+	}
+
+	// A parse of other content is not reused.
+	fh2 := makeFakeFileHandle(uri, []byte("package p\n\nconst _ = \"bar\""))
+	if pgf := cache.parsedFull(ctx, fh2); pgf != nil {
+		t.Errorf("parsedFull(fh2) = %p, want nil", pgf)
+	}
+}
+
 func dummyFileHandles(n int) []file.Handle {
 	var fhs []file.Handle
 	for i := 0; i < n; i++ {
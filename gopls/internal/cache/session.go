@@ -18,7 +18,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"golang.org/x/tools/gopls/internal/cache/constructors"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
 	"golang.org/x/tools/gopls/internal/cache/typerefs"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/label"
@@ -258,6 +260,8 @@ func (s *Session) createView(ctx context.Context, def *viewDefinition) (*View, *
 		packages:          new(persistent.Map[PackageID, *packageHandle]),
 		fullAnalysisKeys:  new(persistent.Map[PackageID, file.Hash]),
 		factyAnalysisKeys: new(persistent.Map[PackageID, file.Hash]),
+		constructors:      new(persistent.Map[PackageID, *constructors.Index]),
+		testRelations:     new(persistent.Map[PackagePath, *testfuncs.Relation]),
 		meta:              new(metadata.Graph),
 		files:             newFileMap(),
 		shouldLoad:        new(persistent.Map[PackageID, []PackagePath]),
@@ -269,6 +273,8 @@ func (s *Session) createView(ctx context.Context, def *viewDefinition) (*View, *
 		modWhyHandles:     new(persistent.Map[protocol.DocumentURI, *memoize.Promise]),
 		moduleUpgrades:    new(persistent.Map[protocol.DocumentURI, map[string]string]),
 		vulns:             new(persistent.Map[protocol.DocumentURI, *vulncheck.Result]),
+		coverage:          new(persistent.Map[protocol.DocumentURI, *FileCoverage]),
+		benchmarks:        new(persistent.Map[string, *BenchmarkHistory]),
 	}
 
 	// Snapshots must observe all open files, as there are some caching
@@ -7,6 +7,7 @@ package cache
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"go/ast"
@@ -24,6 +25,7 @@ import (
 	"sync"
 
 	"golang.org/x/tools/go/types/objectpath"
+	"golang.org/x/tools/gopls/internal/cache/constructors"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/cache/methodsets"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
@@ -144,6 +146,21 @@ type Snapshot struct {
 	fullAnalysisKeys  *persistent.Map[PackageID, file.Hash]
 	factyAnalysisKeys *persistent.Map[PackageID, file.Hash]
 
+	// constructors holds memoized constructor indexes of packages,
+	// shared by features that need to construct values of a type.
+	// It is invalidated along with the package.
+	constructors *persistent.Map[PackageID, *constructors.Index]
+
+	// testRelations holds the memoized relations between the tests of
+	// each package under test, identified by path, and their subjects.
+	// A relation is invalidated along with the package's test variants.
+	testRelations *persistent.Map[PackagePath, *testfuncs.Relation]
+
+	// testPackages maps the path of each package under test to the IDs
+	// of its test variant and external test package in meta, sorted.
+	// It is computed on first use and reset when meta changes.
+	testPackages map[PackagePath][]PackageID
+
 	// workspacePackages contains the workspace's packages, which are loaded
 	// when the view is created. It does not contain intermediate test variants.
 	workspacePackages immutable.Map[PackageID, PackagePath]
@@ -183,6 +200,14 @@ type Snapshot struct {
 	// vulns maps each go.mod file's URI to its known vulnerabilities.
 	vulns *persistent.Map[protocol.DocumentURI, *vulncheck.Result]
 
+	// coverage maps each Go file's URI to its coverage, as reported by
+	// the most recently loaded coverage profile that includes it.
+	coverage *persistent.Map[protocol.DocumentURI, *FileCoverage]
+
+	// benchmarks maps the key of each Benchmark function that has been
+	// run (see [BenchmarkKey]) to its recent results.
+	benchmarks *persistent.Map[string, *BenchmarkHistory]
+
 	// compilerOptDetails is the set of directories whose packages
 	// and tests need compiler optimization details in the diagnostics.
 	compilerOptDetails map[protocol.DocumentURI]unit
@@ -231,6 +256,8 @@ func (s *Snapshot) decref() {
 	s.refcount--
 	if s.refcount == 0 {
 		s.packages.Destroy()
+		s.constructors.Destroy()
+		s.testRelations.Destroy()
 		s.files.destroy()
 		s.parseModHandles.Destroy()
 		s.parseWorkHandles.Destroy()
@@ -240,6 +267,8 @@ func (s *Snapshot) decref() {
 		s.unloadableFiles.Destroy()
 		s.moduleUpgrades.Destroy()
 		s.vulns.Destroy()
+		s.coverage.Destroy()
+		s.benchmarks.Destroy()
 		s.done()
 	}
 }
@@ -516,13 +545,14 @@ func (s *Snapshot) Overlays() []*overlay {
 // Package data kinds, identifying various package data that may be stored in
 // the file cache.
 const (
-	xrefsKind       = "xrefs"
-	methodSetsKind  = "methodsets"
-	testsKind       = "tests"
-	exportDataKind  = "export"
-	diagnosticsKind = "diagnostics"
-	typerefsKind    = "typerefs"
-	symbolsKind     = "symbols"
+	xrefsKind        = "xrefs"
+	methodSetsKind   = "methodsets"
+	testsKind        = "tests"
+	exportDataKind   = "export"
+	diagnosticsKind  = "diagnostics"
+	typerefsKind     = "typerefs"
+	symbolsKind      = "symbols"
+	constructorsKind = "constructors"
 )
 
 // PackageDiagnostics returns diagnostics for files contained in specified
@@ -647,6 +677,177 @@ func (s *Snapshot) Tests(ctx context.Context, ids ...PackageID) ([]*testfuncs.In
 	return indexes, s.forEachPackage(ctx, ids, pre, post)
 }
 
+// TestRelation returns the relation between the tests of the package
+// under test with the specified path, from its test files and those of
+// its external test package, and their subjects. The relation is
+// computed from the test indexes of those packages on first use and
+// memoized until they are invalidated.
+//
+// If the test indexes cannot be loaded from cache, the test packages
+// may be type-checked.
+func (s *Snapshot) TestRelation(ctx context.Context, path PackagePath) (*testfuncs.Relation, error) {
+	s.mu.Lock()
+	rel, ok := s.testRelations.Get(path)
+	s.mu.Unlock()
+	if ok {
+		return rel, nil
+	}
+
+	indexes, err := s.Tests(ctx, s.testPackageIDs(path)...)
+	if err != nil {
+		return nil, err
+	}
+	rel = testfuncs.NewRelation(indexes...)
+
+	s.mu.Lock()
+	s.testRelations.Set(path, rel, nil)
+	s.mu.Unlock()
+	return rel, nil
+}
+
+// testPackageIDs returns the IDs of the test variant and external test
+// package of the package under test with the specified path, indexing
+// the test packages of the metadata graph on first use.
+func (s *Snapshot) testPackageIDs(path PackagePath) []PackageID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.testPackages == nil {
+		s.testPackages = make(map[PackagePath][]PackageID)
+		for _, mp := range s.meta.Packages {
+			if mp.ForTest != "" && (mp.PkgPath == mp.ForTest || mp.PkgPath == mp.ForTest+"_test") {
+				s.testPackages[mp.ForTest] = append(s.testPackages[mp.ForTest], mp.ID)
+			}
+		}
+		for _, ids := range s.testPackages {
+			slices.Sort(ids) // for determinism
+		}
+	}
+	return s.testPackages[path]
+}
+
+// IndexTests computes the relations between the tests of each
+// workspace package under test and their subjects (see
+// [Snapshot.TestRelation]) that are not yet memoized. It is called in
+// the background after each change, once the workspace packages have
+// been diagnosed, so that the test indexes are usually in the file
+// cache and requests for the code lenses and test navigation of a
+// large workspace need not wait for the relations to be computed.
+func (s *Snapshot) IndexTests(ctx context.Context) error {
+	ctx, done := event.Start(ctx, "cache.snapshot.IndexTests")
+	defer done()
+
+	workspace, err := s.WorkspaceMetadata(ctx)
+	if err != nil {
+		return err
+	}
+	paths := make(map[PackagePath]bool)
+	s.mu.Lock()
+	for _, mp := range workspace {
+		if mp.ForTest != "" {
+			if _, ok := s.testRelations.Get(mp.ForTest); !ok {
+				paths[mp.ForTest] = true
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for path := range paths {
+		if _, err := s.TestRelation(ctx, path); err != nil {
+			return err
+		}
+	}
+
+	// This log message is sought for by TestIndexTests.
+	if len(paths) > 0 {
+		event.Log(ctx, fmt.Sprintf("indexed tests of %d packages", len(paths)), s.Labels()...)
+	}
+	return nil
+}
+
+// Constructors returns the constructor index of the package with the
+// specified path, which is either the package mp or a dependency of it.
+// The index is computed from syntax, or read from the file cache, on
+// first use and memoized until the package is invalidated.
+func (s *Snapshot) Constructors(ctx context.Context, mp *metadata.Package, path PackagePath) (*constructors.Index, error) {
+	id := mp.ID
+	if path != mp.PkgPath {
+		var ok bool
+		id, ok = mp.DepsByPkgPath[path]
+		if !ok {
+			return nil, fmt.Errorf("no package %q among dependencies of %s", path, mp.ID)
+		}
+	}
+
+	s.mu.Lock()
+	index, ok := s.constructors.Get(id)
+	s.mu.Unlock()
+	if ok {
+		return index, nil
+	}
+
+	mp = s.Metadata(id)
+	if mp == nil {
+		return nil, fmt.Errorf("no metadata for %s", id)
+	}
+	index, err := s.constructorIndex(ctx, mp)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.constructors.Set(id, index, nil)
+	s.mu.Unlock()
+	return index, nil
+}
+
+// constructorIndex returns the constructor index of the package mp,
+// reading it from the file cache if possible, so that cold starts in
+// large workspaces need not parse every package from which
+// constructors are requested. The index is keyed by the identities of
+// the compiled Go files of the package, as it depends only on their
+// syntax.
+func (s *Snapshot) constructorIndex(ctx context.Context, mp *metadata.Package) (*constructors.Index, error) {
+	var fhs []file.Handle
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "constructors: %s\n", mp.PkgPath)
+	fmt.Fprintf(hasher, "files: %d\n", len(mp.CompiledGoFiles))
+	for _, uri := range mp.CompiledGoFiles {
+		fh, err := s.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err // context cancelled
+		}
+		fhs = append(fhs, fh)
+		fmt.Fprintln(hasher, fh.Identity())
+	}
+	var key file.Hash
+	hasher.Sum(key[:0])
+
+	if data, err := filecache.Get(constructorsKind, key); err == nil {
+		return constructors.Decode(data), nil
+	} else if err != filecache.ErrNotFound {
+		bug.Reportf("internal error reading constructor data: %v", err)
+	}
+
+	pgfs, err := s.view.parseCache.parseFiles(ctx, token.NewFileSet(), parsego.Full&^parser.ParseComments, false, fhs...)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*ast.File, len(pgfs))
+	for i, pgf := range pgfs {
+		files[i] = pgf.File
+	}
+	index := constructors.NewIndex(files)
+
+	// Store the resulting data in the cache.
+	go func() {
+		if err := filecache.Set(constructorsKind, key, index.Encode()); err != nil {
+			event.Error(ctx, fmt.Sprintf("storing constructor data for %s", mp.ID), err)
+		}
+	}()
+	return index, nil
+}
+
 // NarrowestMetadataForFile returns metadata for the narrowest package
 // (the one with the fewest files) that encloses the specified file.
 // The result may be a test variant, but never an intermediate test variant.
@@ -1532,6 +1733,8 @@ func (s *Snapshot) clone(ctx, bgCtx context.Context, changed StateChange, done f
 		packages:          s.packages.Clone(),
 		fullAnalysisKeys:  s.fullAnalysisKeys.Clone(),
 		factyAnalysisKeys: s.factyAnalysisKeys.Clone(),
+		constructors:      s.constructors.Clone(),
+		testRelations:     s.testRelations.Clone(),
 		files:             s.files.clone(changedFiles),
 		workspacePackages: s.workspacePackages,
 		shouldLoad:        s.shouldLoad.Clone(),      // not cloneWithout: shouldLoad is cleared on loads
@@ -1543,6 +1746,8 @@ func (s *Snapshot) clone(ctx, bgCtx context.Context, changed StateChange, done f
 		modVulnHandles:    cloneWithout(s.modVulnHandles, changedFiles, &needsDiagnosis),
 		moduleUpgrades:    cloneWith(s.moduleUpgrades, changed.ModuleUpgrades),
 		vulns:             cloneWith(s.vulns, changed.Vulns),
+		coverage:          cloneWith(adjustCoverage(s.coverage, s.files, changedFiles), changed.Coverage),
+		benchmarks:        cloneWith(s.benchmarks, changed.Benchmarks),
 	}
 
 	// Compute the new set of packages for which we want compiler
@@ -1811,6 +2016,11 @@ func (s *Snapshot) clone(ctx, bgCtx context.Context, changed StateChange, done f
 
 	// Invalidated package information.
 	for id, invalidateMetadata := range idsToInvalidate {
+		result.constructors.Delete(id)
+		if mp := s.meta.Packages[id]; mp != nil && mp.ForTest != "" {
+			result.testRelations.Delete(mp.ForTest)
+		}
+
 		// See the [packageHandle] documentation for more details about this
 		// invalidation.
 		if ph, ok := result.packages.Get(id); ok {
@@ -1870,6 +2080,9 @@ func (s *Snapshot) clone(ctx, bgCtx context.Context, changed StateChange, done f
 
 	// Update metadata, if necessary.
 	result.meta = s.meta.Update(metadataUpdates)
+	if result.meta == s.meta {
+		result.testPackages = s.testPackages // never mutated once computed
+	}
 
 	// Update workspace and active packages, if necessary.
 	if result.meta != s.meta || anyFileOpenedOrClosed {
@@ -78,9 +78,9 @@ func parseSubtestNumber(s string) (prefix string, nn int) {
 	return prefix, int(n)
 }
 
-// rewrite rewrites a subname to having only printable characters and no white
+// Rewrite rewrites a subname to having only printable characters and no white
 // space.
-func rewrite(s string) string {
+func Rewrite(s string) string {
 	b := []byte{}
 	for _, r := range s {
 		switch {
@@ -0,0 +1,197 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testfuncs
+
+// This file defines the relation between tests and their subjects.
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// Subjects returns the subjects of fn, a Test, Benchmark, Fuzz, or
+// Example function declared by decl, whose package is either the
+// package under test or its external test package.
+//
+// The first subject is the function, method, or type of the package
+// under test after which fn is named, if any: F for TestF, or for
+// TestFSuffix if the package declares no function FSuffix; the method
+// T.M for TestT_M; or the type T, if T has no such method. The others
+// are the functions and methods of the package under test that fn
+// calls directly, including from function literals, in order of their
+// first call. Declarations in _test.go files are never subjects.
+//
+// If fn belongs to an external test package that does not import the
+// package under test, its only subject is the one named by fn, taken
+// verbatim: F for TestF, or T.M for TestT_M.
+//
+// Each subject is denoted by its name: "F" for a function or type F,
+// and "T.M" for a method M of type T.
+func Subjects(fset *token.FileSet, info *types.Info, fn *types.Func, decl *ast.FuncDecl) []string {
+	underTest := fn.Pkg()
+	if path, ok := strings.CutSuffix(underTest.Path(), "_test"); ok {
+		// An external test package: find the package under test among its imports.
+		underTest = nil
+		for _, imp := range fn.Pkg().Imports() {
+			if imp.Path() == path {
+				underTest = imp
+				break
+			}
+		}
+	}
+	inTestFile := func(obj types.Object) bool {
+		return strings.HasSuffix(safetoken.StartPosition(fset, obj.Pos()).Filename, "_test.go")
+	}
+
+	var subjects []string
+	add := func(subject string) {
+		if !slices.Contains(subjects, subject) {
+			subjects = append(subjects, subject)
+		}
+	}
+
+	// Find the subject after which the test is named.
+	var rest string
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		if r, ok := strings.CutPrefix(fn.Name(), prefix); ok {
+			rest = strings.TrimPrefix(r, "_") // Test_f tests an unexported f
+			break
+		}
+	}
+	words := strings.Split(rest, "_")
+	if underTest == nil {
+		// The external test package does not import the package
+		// under test, so the test can only be related to it by name.
+		if words[0] != "" {
+			name := words[0]
+			if len(words) > 1 && words[1] != "" {
+				name += "." + words[1]
+			}
+			add(name)
+		}
+		return subjects
+	}
+
+	// Choose the longest name that is a prefix of the first word.
+	var subject types.Object
+	scope := underTest.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		switch obj.(type) {
+		case *types.Func, *types.TypeName:
+			if IsSubjectPrefix(words[0], name) &&
+				(subject == nil || len(name) > len(subject.Name())) &&
+				!inTestFile(obj) {
+				subject = obj
+			}
+		}
+	}
+	if subject != nil {
+		name := subject.Name()
+		// Choose the method of type T named by the second word, if any.
+		if tname, ok := subject.(*types.TypeName); ok && len(words) > 1 {
+			if named, ok := tname.Type().(*types.Named); ok {
+				var method *types.Func
+				for m := range named.Methods() {
+					if IsSubjectPrefix(words[1], m.Name()) && (method == nil || len(m.Name()) > len(method.Name())) {
+						method = m
+					}
+				}
+				if method != nil {
+					name += "." + method.Name()
+				}
+			}
+		}
+		add(name)
+	}
+
+	// Add the functions that the test calls.
+	if decl.Body == nil {
+		return subjects
+	}
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if callee, ok := typeutil.Callee(info, call).(*types.Func); ok {
+				callee = callee.Origin()
+				if callee.Pkg() != nil && callee.Pkg().Path() == underTest.Path() && !inTestFile(callee) {
+					name := callee.Name()
+					if recv := callee.Signature().Recv(); recv != nil {
+						_, named := typesinternal.ReceiverNamed(recv)
+						if named == nil {
+							return true
+						}
+						name = named.Obj().Name() + "." + name
+					}
+					add(name)
+				}
+			}
+		}
+		return true
+	})
+	return subjects
+}
+
+// IsSubjectPrefix reports whether word consists of the name followed
+// by nothing, or by an upper case letter or digit, as in
+// TestParseError, a test of Parse.
+func IsSubjectPrefix(word, name string) bool {
+	rest, ok := strings.CutPrefix(word, name)
+	if !ok {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || unicode.IsUpper(r) || unicode.IsDigit(r)
+}
+
+// A Relation relates the functions and methods of a package under
+// test to the tests that exercise them, according to the indexes of
+// the package's test variant and of its external test package.
+type Relation struct {
+	tests     []Result         // top-level tests, in index order
+	bySubject map[string][]int // subject -> indexes of tests
+}
+
+// NewRelation returns the relation between the tests recorded by the
+// specified indexes and their subjects.
+func NewRelation(indexes ...*Index) *Relation {
+	r := &Relation{bySubject: make(map[string][]int)}
+	for _, index := range indexes {
+		for _, test := range index.All() {
+			if strings.Contains(test.Name, "/") {
+				continue // a subtest
+			}
+			for _, subject := range test.Subjects {
+				r.bySubject[subject] = append(r.bySubject[subject], len(r.tests))
+			}
+			r.tests = append(r.tests, test)
+		}
+	}
+	return r
+}
+
+// Tests returns the top-level tests of the package under test, in
+// index order.
+func (r *Relation) Tests() []Result {
+	return r.tests
+}
+
+// TestsOf returns the tests whose subjects include the specified
+// function or method, "F" or "T.M", in index order.
+func (r *Relation) TestsOf(subject string) []Result {
+	var tests []Result
+	for _, i := range r.bySubject[subject] {
+		tests = append(tests, r.tests[i])
+	}
+	return tests
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testfuncs_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
+)
+
+func TestSubjects(t *testing.T) {
+	const src = `package p
+
+type T struct{}
+
+func (T) Method()      {}
+func (*T) MethodLong() {}
+func Parse()           {}
+func ParseAll()        { helper() }
+func helper()          {}
+`
+	const testSrc = `package p
+
+func TestParse()          {}
+func TestParseError()     { ParseAll() }
+func TestParseAllFast()   {}
+func TestT_MethodLongX()  {}
+func TestT_Missing()      { var x T; x.Method(); fixture() }
+func TestUnknown()        { func() { helper() }() }
+func fixture()            {}
+`
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, f := range []struct{ name, src string }{{"p.go", src}, {"p_test.go", testSrc}} {
+		file, err := parser.ParseFile(fset, f.name, f.src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	if _, err := new(types.Config).Check("p", fset, files, info); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name string
+		want []string
+	}{
+		{"TestParse", []string{"Parse"}},
+		{"TestParseError", []string{"Parse", "ParseAll"}},
+		{"TestParseAllFast", []string{"ParseAll"}},
+		{"TestT_MethodLongX", []string{"T.MethodLong"}},
+		{"TestT_Missing", []string{"T", "T.Method"}},
+		{"TestUnknown", []string{"helper"}},
+	} {
+		var decl *ast.FuncDecl
+		for _, d := range files[1].Decls {
+			if d, ok := d.(*ast.FuncDecl); ok && d.Name.Name == test.name {
+				decl = d
+			}
+		}
+		fn := info.Defs[decl.Name].(*types.Func)
+		if got := testfuncs.Subjects(fset, info, fn, decl); !slices.Equal(got, test.want) {
+			t.Errorf("Subjects(%s) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestIsSubjectPrefix(t *testing.T) {
+	for _, test := range []struct {
+		word, name string
+		want       bool
+	}{
+		{"Parse", "Parse", true},
+		{"ParseError", "Parse", true},
+		{"Parse2", "Parse", true},
+		{"Parser", "Parse", false},
+		{"Pars", "Parse", false},
+	} {
+		if got := testfuncs.IsSubjectPrefix(test.word, test.name); got != test.want {
+			t.Errorf("IsSubjectPrefix(%q, %q) = %t, want %t", test.word, test.name, got, test.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ package testfuncs
 import (
 	"go/ast"
 	"go/constant"
+	"go/token"
 	"go/types"
 	"strings"
 	"unicode"
@@ -49,20 +50,21 @@ func (index *Index) All() []Result {
 type Result struct {
 	Location protocol.Location // location of the test
 	Name     string            // name of the test
+	Subjects []string          // subjects of a top-level test; see [Subjects]
 }
 
 // NewIndex returns a new index of method-set information for all
 // package-level types in the specified package.
-func NewIndex(files []*parsego.File, info *types.Info) *Index {
+func NewIndex(fset *token.FileSet, files []*parsego.File, info *types.Info) *Index {
 	b := &indexBuilder{
 		fileIndex: make(map[protocol.DocumentURI]int),
 		subNames:  make(map[string]int),
 	}
-	return b.build(files, info)
+	return b.build(fset, files, info)
 }
 
 // build adds to the index all tests of the specified package.
-func (b *indexBuilder) build(files []*parsego.File, info *types.Info) *Index {
+func (b *indexBuilder) build(fset *token.FileSet, files []*parsego.File, info *types.Info) *Index {
 	for _, file := range files {
 		if !strings.HasSuffix(file.Tok.Name(), "_test.go") {
 			continue
@@ -92,6 +94,7 @@ func (b *indexBuilder) build(files []*parsego.File, info *types.Info) *Index {
 			t.Name = decl.Name.Name
 			t.Location.URI = file.URI
 			t.Location.Range, _ = file.NodeRange(decl)
+			t.Subjects = Subjects(fset, info, obj, decl)
 
 			i, ok := b.fileIndex[t.Location.URI]
 			if !ok {
@@ -163,7 +166,7 @@ func (b *indexBuilder) findSubtests(parent gobTest, typ *ast.FuncType, body *ast
 		}
 
 		var t gobTest
-		t.Name = b.uniqueName(parent.Name, rewrite(constant.StringVal(val)))
+		t.Name = b.uniqueName(parent.Name, Rewrite(constant.StringVal(val)))
 		t.Location.URI = file.URI
 		t.Location.Range, _ = file.NodeRange(call)
 		tests = append(tests, t)
@@ -328,6 +331,7 @@ type gobFile struct {
 type gobTest struct {
 	Location protocol.Location // location of the test
 	Name     string            // name of the test
+	Subjects []string          // subjects of a top-level test; see [Subjects]
 }
 
 func (t *gobTest) result() Result {
@@ -750,6 +750,8 @@ type StateChange struct {
 	ModuleUpgrades     map[protocol.DocumentURI]map[string]string
 	Vulns              map[protocol.DocumentURI]*vulncheck.Result
 	CompilerOptDetails map[protocol.DocumentURI]bool // package directory -> whether or not we want details
+	Coverage           map[protocol.DocumentURI]*FileCoverage
+	Benchmarks         map[string]*BenchmarkHistory // see BenchmarkKey
 }
 
 // InvalidateView processes the provided state change, invalidating any derived
@@ -0,0 +1,163 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/settings"
+	"golang.org/x/tools/internal/tool"
+)
+
+// addtest implements the addtest verb for gopls.
+type addtest struct {
+	EditFlags
+	app *Application
+}
+
+func (a *addtest) Name() string      { return "addtest" }
+func (a *addtest) Parent() string    { return a.app.Name() }
+func (a *addtest) Usage() string     { return "[addtest-flags] <position-or-symbol>" }
+func (a *addtest) ShortHelp() string { return "add a test for a function or method" }
+func (a *addtest) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+The addtest command adds a table-driven test for the function or method
+at the specified position, or of the specified name, to the
+corresponding _test.go file, like the "Add test for F" code action.
+
+A name may be that of a function F or a method T.M, optionally
+qualified by its package name or path, such as strings.Cut or
+example.com/p.T.M.
+
+Example:
+
+	$ # 1-based location (:line:column or :#offset) within the function
+	$ gopls addtest helper/helper.go:8:6
+	$ gopls addtest -w helper.Parse
+
+addtest-flags:
+`)
+	printFlagDefaults(f)
+}
+
+// Run adds a test for the specified function and either:
+// - if -w is specified, updates the file(s) in place;
+// - if -l is specified, prints the names of the edited files; or
+// - otherwise, prints out unified diffs of the changes.
+func (a *addtest) Run(ctx context.Context, args ...string) error {
+	if len(args) != 1 {
+		return tool.CommandLineErrorf("addtest expects 1 argument (position or symbol)")
+	}
+	if !a.Write && !a.List {
+		a.Diff = true
+	}
+	a.app.editFlags = &a.EditFlags
+
+	opts := a.app.options
+	a.app.options = func(o *settings.Options) {
+		if opts != nil {
+			opts(o)
+		}
+		o.SymbolMatcher = settings.SymbolCaseSensitive
+		o.SymbolStyle = settings.FullyQualifiedSymbols
+	}
+
+	conn, err := a.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	var loc protocol.Location
+	if from := parseSpan(args[0]); strings.HasSuffix(from.URI().Path(), ".go") {
+		file, err := conn.openFile(ctx, from.URI())
+		if err != nil {
+			return err
+		}
+		if loc, err = file.spanLocation(from); err != nil {
+			return err
+		}
+	} else {
+		if loc, err = conn.funcLocation(ctx, args[0]); err != nil {
+			return err
+		}
+		if _, err := conn.openFile(ctx, loc.URI); err != nil {
+			return err
+		}
+	}
+
+	cmd := command.NewAddTestCommand("", command.AddTestArgs{
+		Location:     loc,
+		ResolveEdits: true,
+	})
+	res, err := conn.executeCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	// The result is a *command.AddTestResult, unless decoded from a
+	// remote server's response.
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	var result command.AddTestResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("decoding edits: %v", err)
+	}
+	if result.Edit == nil {
+		return fmt.Errorf("no edits")
+	}
+	return conn.client.applyWorkspaceEdit(result.Edit)
+}
+
+// funcLocation returns the location of the declaration of the function
+// or method of the specified name: F or T.M, optionally qualified by
+// the name or path of its package.
+func (conn *connection) funcLocation(ctx context.Context, name string) (protocol.Location, error) {
+	query := name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		query = name[i+1:]
+	}
+	symbols, err := conn.Symbol(ctx, &protocol.WorkspaceSymbolParams{Query: query})
+	if err != nil {
+		return protocol.Location{}, err
+	}
+	var matches []protocol.SymbolInformation
+	for _, s := range symbols {
+		if s.Kind != protocol.Function && s.Kind != protocol.Method {
+			continue
+		}
+		// A fully qualified name is the package path followed by
+		// ".F" or ".T.M"; the path may itself contain dots.
+		slash := strings.LastIndex(s.Name, "/") + 1
+		dot := strings.Index(s.Name[slash:], ".")
+		if dot < 0 {
+			continue
+		}
+		pkgPath, local := s.Name[:slash+dot], s.Name[slash+dot+1:]
+		if name == local || name == path.Base(pkgPath)+"."+local || name == s.Name {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return protocol.Location{}, fmt.Errorf("no function or method named %s", name)
+	case 1:
+		return matches[0].Location, nil
+	default:
+		var names []string
+		for _, s := range matches {
+			names = append(names, s.Name)
+		}
+		return protocol.Location{}, fmt.Errorf("%s is ambiguous: %s", name, strings.Join(names, ", "))
+	}
+}
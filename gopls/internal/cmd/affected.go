@@ -0,0 +1,108 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/internal/tool"
+)
+
+// affected implements the affected verb for gopls.
+type affected struct {
+	app *Application
+}
+
+func (a *affected) Name() string      { return "affected" }
+func (a *affected) Parent() string    { return a.app.Name() }
+func (a *affected) Usage() string     { return "<file>..." }
+func (a *affected) ShortHelp() string { return "list the tests affected by changes to files" }
+func (a *affected) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+The affected command prints the tests of the workspace that changes
+to the specified files, such as those of a commit, may affect: for
+each package with affected tests, a line with its path followed by
+the names of its affected tests, or by "(all)" if all its tests are.
+
+A change to a test affects that test; a change to a function or method
+affects the tests that exercise it, or that exercise a function of its
+package that calls it, directly or not; and any other change, such as
+to a type or a variable, affects all the tests of the package and of
+the packages that import it.
+
+Example: test only what the last commit may have broken:
+
+	$ gopls -json affected $(git diff --name-only HEAD~1)
+`)
+	printFlagDefaults(f)
+}
+
+func (a *affected) Run(ctx context.Context, args ...string) error {
+	if len(args) == 0 {
+		return tool.CommandLineErrorf("affected expects at least 1 argument (file)")
+	}
+	var files []protocol.DocumentURI
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		files = append(files, protocol.URIFromPath(abs))
+	}
+
+	conn, err := a.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	cmd := command.NewAffectedTestsCommand("", command.AffectedTestsArgs{
+		URI:   files[0],
+		Files: files,
+	})
+	res, err := conn.executeCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	// The result is a command.AffectedTestsResult, unless decoded
+	// from a remote server's response.
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	var result command.AffectedTestsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("decoding result: %v", err)
+	}
+
+	pkgs := []affectedPackage{} // non-nil, for JSON
+	for _, pkg := range result.Packages {
+		pkgs = append(pkgs, affectedPackage{
+			Package: pkg.PkgPath,
+			Dir:     pkg.Dir.Path(),
+			Tests:   pkg.Tests,
+			All:     pkg.All,
+		})
+	}
+	if a.app.JSON {
+		return printJSON(pkgs)
+	}
+	for _, pkg := range pkgs {
+		tests := strings.Join(pkg.Tests, " ")
+		if pkg.All {
+			tests = "(all)"
+		}
+		fmt.Fprintf(os.Stdout, "%s %s\n", pkg.Package, tests)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"maps"
 	"slices"
 
 	"golang.org/x/tools/gopls/internal/protocol"
@@ -18,6 +19,7 @@ import (
 type check struct {
 	app      *Application
 	Severity string `flag:"severity" help:"minimum diagnostic severity (hint, info, warning, or error)"`
+	SARIF    bool   `flag:"sarif" help:"emit diagnostics and their fixes in SARIF format"`
 }
 
 func (c *check) Name() string      { return "check" }
@@ -29,6 +31,15 @@ func (c *check) DetailedHelp(f *flag.FlagSet) {
 Example: show the diagnostic results of this file:
 
 	$ gopls check internal/cmd/check.go
+
+The -sarif flag causes the diagnostics to be printed as a SARIF 2.1.0
+log, suitable for upload to code-scanning services, in which each
+result records the edits of the quick fixes offered for it, if any.
+Files within the working directory are reported relative to it.
+
+Example: report the diagnostics of all Go files in SARIF format:
+
+	$ gopls check -sarif $(find . -name '*.go') > gopls.sarif
 `)
 	printFlagDefaults(f)
 }
@@ -90,21 +101,40 @@ func (c *check) Run(ctx context.Context, args ...string) error {
 		return err
 	}
 
-	// print prints a single element of a diagnostic.
-	print := func(uri protocol.DocumentURI, rng protocol.Range, message string) error {
+	// toSpan converts a range of a diagnostic to a span.
+	toSpan := func(uri protocol.DocumentURI, rng protocol.Range, message string) (span, error) {
 		file, err := conn.openFile(ctx, uri)
 		if err != nil {
-			return err
+			return span{}, err
 		}
 		spn, err := file.rangeSpan(rng)
 		if err != nil {
-			return fmt.Errorf("could not convert position %v for %q", rng, message)
+			return span{}, fmt.Errorf("could not convert position %v for %q", rng, message)
+		}
+		return spn, nil
+	}
+
+	// print prints a single element of a diagnostic.
+	print := func(uri protocol.DocumentURI, rng protocol.Range, message string) error {
+		spn, err := toSpan(uri, rng, message)
+		if err != nil {
+			return err
 		}
 		fmt.Printf("%v: %v\n", spn, message)
 		return nil
 	}
 
-	for _, file := range checking {
+	var sarif *sarifBuilder
+	if c.SARIF {
+		sarif, err = newSARIFBuilder(conn)
+		if err != nil {
+			return err
+		}
+	}
+
+	jsonDiags := []diagnostic{} // non-nil, for JSON
+	for _, uri := range slices.Sorted(maps.Keys(checking)) {
+		file := checking[uri]
 		file.diagnosticsMu.Lock()
 		diags := slices.Clone(file.diagnostics)
 		file.diagnosticsMu.Unlock()
@@ -113,6 +143,34 @@ func (c *check) Run(ctx context.Context, args ...string) error {
 			if diag.Severity > severityCutoff { // lower severity value => greater severity, counterintuitively
 				continue
 			}
+			if sarif != nil {
+				if err := sarif.add(ctx, file.uri, diag); err != nil {
+					return err
+				}
+				continue
+			}
+			if c.app.JSON {
+				spn, err := toSpan(file.uri, diag.Range, diag.Message)
+				if err != nil {
+					return err
+				}
+				d := diagnostic{
+					Span:     spn,
+					Severity: severityName(diag.Severity),
+					Source:   diag.Source,
+					Code:     diag.Code,
+					Message:  diag.Message,
+				}
+				for _, rel := range diag.RelatedInformation {
+					spn, err := toSpan(rel.Location.URI, rel.Location.Range, rel.Message)
+					if err != nil {
+						return err
+					}
+					d.Related = append(d.Related, relatedInformation{Span: spn, Message: rel.Message})
+				}
+				jsonDiags = append(jsonDiags, d)
+				continue
+			}
 			if err := print(file.uri, diag.Range, diag.Message); err != nil {
 				return err
 			}
@@ -124,5 +182,11 @@ func (c *check) Run(ctx context.Context, args ...string) error {
 
 		}
 	}
+	if sarif != nil {
+		return printJSON(sarif.log())
+	}
+	if c.app.JSON {
+		return printJSON(jsonDiags)
+	}
 	return nil
 }
@@ -8,13 +8,16 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -63,6 +66,9 @@ type Application struct {
 	// VeryVerbose enables a higher level of verbosity in logging output.
 	VeryVerbose bool `flag:"vv,veryverbose" help:"very verbose output"`
 
+	// JSON causes commands to emit machine-readable JSON output.
+	JSON bool `flag:"json" help:"emit locations, edits, and diagnostics in JSON format"`
+
 	// Control ocagent export of telemetry
 	OCAgent string `flag:"ocagent" help:"the address of the ocagent (e.g. http://localhost:55678), or off"`
 
@@ -76,7 +82,7 @@ type Application struct {
 	editFlags *EditFlags
 }
 
-// EditFlags defines flags common to {code{action,lens},format,imports,rename}
+// EditFlags defines flags common to {addtest,code{action,lens},format,generate-tests,imports,rename}
 // that control how edits are applied to the client's files.
 //
 // The type is exported for flag reflection.
@@ -89,6 +95,8 @@ type EditFlags struct {
 	Preserve bool `flag:"preserve" help:"with -write, make copies of original files"`
 	Diff     bool `flag:"d,diff" help:"display diffs instead of edited file content"`
 	List     bool `flag:"l,list" help:"display names of edited files"`
+
+	json bool // emit edits in JSON form, instead of -diff, -list, or content (see Application.JSON)
 }
 
 func (app *Application) verbose() bool {
@@ -283,15 +291,19 @@ func (app *Application) internalCommands() []tool.Application {
 
 func (app *Application) featureCommands() []tool.Application {
 	return []tool.Application{
+		&addtest{app: app},
+		&affected{app: app},
 		&callHierarchy{app: app},
 		&check{app: app, Severity: "warning"},
 		&codeaction{app: app},
 		&codelens{app: app},
+		&coverage{app: app, Sort: "position"},
 		&definition{app: app},
 		&execute{app: app},
-		&fix{app: app}, // (non-functional)
+		&fix{app: app},
 		&foldingRanges{app: app},
 		&format{app: app},
+		&generateTests{app: app},
 		&highlight{app: app},
 		&implementation{app: app},
 		&imports{app: app},
@@ -312,6 +324,9 @@ func (app *Application) featureCommands() []tool.Application {
 
 // connect creates and initializes a new in-process gopls session.
 func (app *Application) connect(ctx context.Context) (*connection, error) {
+	if app.editFlags != nil {
+		app.editFlags.json = app.JSON
+	}
 	client := newClient(app)
 	var svr protocol.Server
 	if app.Remote == "" {
@@ -515,68 +530,215 @@ func (c *cmdClient) ApplyEdit(ctx context.Context, p *protocol.ApplyWorkspaceEdi
 // files, honoring the preferred edit mode specified by cli.app.editMode.
 // (Used by rename and by ApplyEdit downcalls.)
 //
+// The changes are applied in order to the contents of the files in
+// memory, so that edits may follow the creation or renaming of a file,
+// or an earlier edit of the same file. The net change to each file is
+// then written, listed, diffed, or printed, and the client's view of
+// the file is updated.
+//
 // See also:
 //   - changedFiles in ../test/marker/marker_test.go for the golden-file capturing variant
 //   - applyWorkspaceEdit in ../test/integration/fake/editor.go for the Editor variant
 func (cli *cmdClient) applyWorkspaceEdit(wsedit *protocol.WorkspaceEdit) error {
-
-	create := func(uri protocol.DocumentURI, content []byte) error {
-		edits := []diff.Edit{{Start: 0, End: 0, New: string(content)}}
-		return updateFile(uri.Path(), nil, content, edits, cli.app.editFlags)
+	// A fileState records the content of a file before the
+	// changes and after those so far; nil denotes absence.
+	type fileState struct {
+		before, after []byte
+	}
+	var (
+		states = make(map[protocol.DocumentURI]*fileState)
+		order  []protocol.DocumentURI // URIs in order of first change
+	)
+	state := func(uri protocol.DocumentURI) (*fileState, error) {
+		st, ok := states[uri]
+		if !ok {
+			st = new(fileState)
+			cli.filesMu.Lock()
+			f, ok := cli.files[uri]
+			cli.filesMu.Unlock()
+			if ok && f.err == nil && f.mapper != nil {
+				st.before = f.mapper.Content // perhaps not yet written
+			} else if _, err := os.Stat(uri.Path()); err == nil {
+				f := cli.openFile(uri)
+				if f.err != nil {
+					return nil, f.err
+				}
+				st.before = f.mapper.Content
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+			st.after = st.before
+			states[uri] = st
+			order = append(order, uri)
+		}
+		return st, nil
 	}
 
-	delete := func(uri protocol.DocumentURI, content []byte) error {
-		edits := []diff.Edit{{Start: 0, End: len(content), New: ""}}
-		return updateFile(uri.Path(), content, nil, edits, cli.app.editFlags)
+	// rename moves the content of one file to another.
+	// (NB: loses file mode.)
+	rename := func(oldURI, newURI protocol.DocumentURI, opts *protocol.RenameFileOptions) error {
+		from, err := state(oldURI)
+		if err != nil {
+			return err
+		}
+		to, err := state(newURI)
+		if err != nil {
+			return err
+		}
+		if from.after == nil {
+			return fmt.Errorf("%s: rename of nonexistent file", oldURI.Path())
+		}
+		if to.after != nil {
+			if opts != nil && opts.IgnoreIfExists && !opts.Overwrite {
+				return nil
+			}
+			if opts == nil || !opts.Overwrite {
+				return fmt.Errorf("%s: file already exists", newURI.Path())
+			}
+		}
+		to.after, from.after = from.after, nil
+		return nil
 	}
+	var renamedDirs []string // directories emptied by renaming
 
 	for _, c := range wsedit.DocumentChanges {
 		switch {
 		case c.TextDocumentEdit != nil:
-			f := cli.openFile(c.TextDocumentEdit.TextDocument.URI)
-			if f.err != nil {
-				return f.err
+			uri := c.TextDocumentEdit.TextDocument.URI
+			st, err := state(uri)
+			if err != nil {
+				return err
+			}
+			if st.after == nil {
+				return fmt.Errorf("%s: edit of nonexistent file", uri.Path())
 			}
 			// TODO(adonovan): sanity-check c.TextDocumentEdit.TextDocument.Version
 			edits := protocol.AsTextEdits(c.TextDocumentEdit.Edits)
-			if err := applyTextEdits(f.mapper, edits, cli.app.editFlags); err != nil {
+			st.after, _, err = protocol.ApplyEdits(protocol.NewMapper(uri, st.after), edits)
+			if err != nil {
 				return err
 			}
 
 		case c.CreateFile != nil:
-			if err := create(c.CreateFile.URI, []byte{}); err != nil {
+			st, err := state(c.CreateFile.URI)
+			if err != nil {
 				return err
 			}
+			if st.after != nil {
+				opts := c.CreateFile.Options
+				if opts != nil && opts.IgnoreIfExists && !opts.Overwrite {
+					continue
+				}
+				if opts == nil || !opts.Overwrite {
+					return fmt.Errorf("%s: file already exists", c.CreateFile.URI.Path())
+				}
+			}
+			st.after = []byte{}
 
 		case c.RenameFile != nil:
-			// Analyze as creation + deletion. (NB: loses file mode.)
-			f := cli.openFile(c.RenameFile.OldURI)
-			if f.err != nil {
-				return f.err
-			}
-			if err := create(c.RenameFile.NewURI, f.mapper.Content); err != nil {
-				return err
-			}
-			if err := delete(f.mapper.URI, f.mapper.Content); err != nil {
+			oldURI, newURI := c.RenameFile.OldURI, c.RenameFile.NewURI
+			if info, err := os.Stat(oldURI.Path()); err == nil && info.IsDir() {
+				// Rename each file of the directory tree,
+				// including any created by earlier changes.
+				var files []protocol.DocumentURI
+				err := filepath.WalkDir(oldURI.Path(), func(path string, d fs.DirEntry, err error) error {
+					if err == nil && !d.IsDir() {
+						files = append(files, protocol.URIFromPath(path))
+					}
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				for _, uri := range order {
+					if oldURI.Encloses(uri) && !slices.Contains(files, uri) {
+						files = append(files, uri)
+					}
+				}
+				for _, uri := range files {
+					if st, ok := states[uri]; ok && st.after == nil {
+						continue // deleted by an earlier change
+					}
+					rel, err := filepath.Rel(oldURI.Path(), uri.Path())
+					if err != nil {
+						return err
+					}
+					to := protocol.URIFromPath(filepath.Join(newURI.Path(), rel))
+					if err := rename(uri, to, c.RenameFile.Options); err != nil {
+						return err
+					}
+				}
+				renamedDirs = append(renamedDirs, oldURI.Path())
+			} else if err := rename(oldURI, newURI, c.RenameFile.Options); err != nil {
 				return err
 			}
 
 		case c.DeleteFile != nil:
-			f := cli.openFile(c.DeleteFile.URI)
-			if f.err != nil {
-				return f.err
-			}
-			if err := delete(f.mapper.URI, f.mapper.Content); err != nil {
+			st, err := state(c.DeleteFile.URI)
+			if err != nil {
 				return err
 			}
+			if st.after == nil {
+				if opts := c.DeleteFile.Options; opts != nil && opts.IgnoreIfNotExists {
+					continue
+				}
+				return fmt.Errorf("%s: delete of nonexistent file", c.DeleteFile.URI.Path())
+			}
+			st.after = nil
 
 		default:
 			return fmt.Errorf("unknown DocumentChange: %#v", c)
 		}
 	}
+
+	for _, uri := range order {
+		st := states[uri]
+		if st.before == nil && st.after == nil {
+			continue // created and deleted
+		}
+		if st.before != nil && st.after != nil && bytes.Equal(st.before, st.after) {
+			continue // no net change
+		}
+		edits := diff.Bytes(st.before, st.after)
+		if err := updateFile(uri.Path(), st.before, st.after, edits, cli.app.editFlags); err != nil {
+			return err
+		}
+
+		// Subsequent edits apply to the new content,
+		// which exists on disk only if written.
+		cli.filesMu.Lock()
+		if st.after != nil {
+			cli.files[uri] = &cmdFile{uri: uri, mapper: protocol.NewMapper(uri, st.after)}
+		} else {
+			delete(cli.files, uri)
+		}
+		cli.filesMu.Unlock()
+	}
+
+	// Remove the renamed directories, if written and now empty.
+	if cli.app.editFlags.Write {
+		for _, dir := range renamedDirs {
+			removeEmptyDirs(dir)
+		}
+	}
 	return nil
 }
 
+// removeEmptyDirs removes the directory tree rooted at dir if it
+// contains only directories.
+func removeEmptyDirs(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			removeEmptyDirs(filepath.Join(dir, e.Name()))
+		}
+	}
+	os.Remove(dir) // fails if not empty
+}
+
 // applyTextEdits applies a list of edits to the mapper file content,
 // using the preferred edit mode. It is a no-op if there are no edits.
 func applyTextEdits(mapper *protocol.Mapper, edits []protocol.TextEdit, flags *EditFlags) error {
@@ -594,7 +756,18 @@ func applyTextEdits(mapper *protocol.Mapper, edits []protocol.TextEdit, flags *E
 // If the old content is nil, the operation creates the file.
 // If the new content is nil, the operation deletes the file.
 // The flags control whether the operation is written, or merely listed, diffed, or printed.
+// With flags.json, the operation is printed as a [fileEdit] in JSON form instead.
 func updateFile(filename string, old, new []byte, edits []diff.Edit, flags *EditFlags) error {
+	if flags.json {
+		if err := printFileEdit(filename, old, new, edits); err != nil {
+			return err
+		}
+		flags = &EditFlags{Write: flags.Write, Preserve: flags.Preserve}
+		if !flags.Write {
+			return nil
+		}
+	}
+
 	if flags.List {
 		fmt.Println(filename)
 	}
@@ -608,6 +781,11 @@ func updateFile(filename string, old, new []byte, edits []diff.Edit, flags *Edit
 
 		if new != nil {
 			// create or edit
+			if old == nil {
+				if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+					return err
+				}
+			}
 			if err := os.WriteFile(filename, new, 0666); err != nil {
 				return err
 			}
@@ -924,17 +1102,3 @@ func pointPosition(m *protocol.Mapper, p point) (protocol.Position, error) {
 	}
 	return protocol.Position{}, fmt.Errorf("point has neither offset nor line/column")
 }
-
-// TODO(adonovan): delete in 2025.
-type fix struct{ app *Application }
-
-func (*fix) Name() string       { return "fix" }
-func (cmd *fix) Parent() string { return cmd.app.Name() }
-func (*fix) Usage() string      { return "" }
-func (*fix) ShortHelp() string  { return "apply suggested fixes (obsolete)" }
-func (*fix) DetailedHelp(flags *flag.FlagSet) {
-	fmt.Fprintf(flags.Output(), `No longer supported; use "gopls codeaction" instead.`)
-}
-func (*fix) Run(ctx context.Context, args ...string) error {
-	return tool.CommandLineErrorf(`no longer supported; use "gopls codeaction" instead`)
-}
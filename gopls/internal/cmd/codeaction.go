@@ -160,8 +160,8 @@ func (cmd *codeaction) Run(ctx context.Context, args ...string) error {
 		return fmt.Errorf("%v: %v", from, err)
 	}
 
-	// Gather edits from matching code actions.
-	var edits []protocol.TextEdit
+	// Execute or list matching code actions.
+	jsonActions := []codeAction{} // non-nil, for JSON
 	for _, act := range actions {
 		if act.Disabled != nil {
 			continue
@@ -193,22 +193,20 @@ func (cmd *codeaction) Run(ctx context.Context, args ...string) error {
 				// instead of them, but we don't want to
 				// duplicate edits.
 			} else {
-				// Partially apply CodeAction.Edit, a WorkspaceEdit.
-				// (See also conn.Client.applyWorkspaceEdit(a.Edit)).
-				for _, c := range act.Edit.DocumentChanges {
-					tde := c.TextDocumentEdit
-					if tde != nil && tde.TextDocument.URI == uri {
-						// TODO(adonovan): this logic will butcher an edit that spans files.
-						// It will also ignore create/delete/rename operations.
-						// Fix or document. Need a three-way merge.
-						edits = append(edits, protocol.AsTextEdits(tde.Edits)...)
-					}
-				}
-				return applyTextEdits(file.mapper, edits, cmd.app.editFlags)
+				// Apply CodeAction.Edit, a WorkspaceEdit.
+				return conn.client.applyWorkspaceEdit(act.Edit)
 			}
 			return nil
 		} else {
 			// No -exec: list matching code actions.
+			if cmd.app.JSON {
+				action := codeAction{Title: act.Title, Kind: act.Kind}
+				if act.Command != nil {
+					action.Command = act.Command.Command
+				}
+				jsonActions = append(jsonActions, action)
+				continue
+			}
 			action := "edit"
 			if act.Command != nil {
 				action = "command"
@@ -223,5 +221,8 @@ func (cmd *codeaction) Run(ctx context.Context, args ...string) error {
 	if cmd.Exec {
 		return fmt.Errorf("no matching code action at %s", from)
 	}
+	if cmd.app.JSON {
+		return printJSON(jsonActions)
+	}
 	return nil
 }
@@ -0,0 +1,165 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/internal/tool"
+)
+
+// coverage implements the coverage verb for gopls.
+type coverage struct {
+	Profile string `flag:"profile" help:"coverage profile, as produced by 'go test -coverprofile'"`
+	Sort    string `flag:"sort" help:"order of functions (position, name, coverage, or uncovered)"`
+	app     *Application
+}
+
+func (c *coverage) Name() string      { return "coverage" }
+func (c *coverage) Parent() string    { return c.app.Name() }
+func (c *coverage) Usage() string     { return "-profile=<file> [coverage-flags] <package-or-file>..." }
+func (c *coverage) ShortHelp() string { return "report the test coverage of each function" }
+func (c *coverage) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+The coverage command reads a coverage profile, such as one produced by
+'go test -coverprofile', and prints the test coverage of each function
+and method of the specified packages or files: the percentage of the
+lines of its body that were covered, and the numbers of covered and
+total lines. Each package is denoted by its directory; a directory
+followed by /... denotes the packages of the workspace in it and its
+subdirectories. The last line reports the total coverage.
+
+Files that the profile does not mention, such as those of packages
+without tests, are not reported. The -sort flag orders the functions
+by position (the default), by package and name, by increasing
+coverage, or by decreasing number of uncovered lines.
+
+Example: list the least covered functions first:
+
+	$ go test -coverprofile=cover.out ./...
+	$ gopls coverage -profile=cover.out -sort=coverage ./...
+
+coverage-flags:
+`)
+	printFlagDefaults(f)
+}
+
+func (c *coverage) Run(ctx context.Context, args ...string) error {
+	if c.Profile == "" {
+		return tool.CommandLineErrorf("coverage requires a -profile flag")
+	}
+	if len(args) == 0 {
+		return tool.CommandLineErrorf("coverage expects at least 1 argument (package or file)")
+	}
+	var compare func(x, y *funcCoverage) int
+	switch c.Sort {
+	case "position":
+	case "name":
+		compare = func(x, y *funcCoverage) int {
+			return cmp.Or(
+				strings.Compare(x.Package, y.Package),
+				strings.Compare(x.Name, y.Name))
+		}
+	case "coverage":
+		compare = func(x, y *funcCoverage) int {
+			return cmp.Compare(x.Percent, y.Percent)
+		}
+	case "uncovered":
+		compare = func(x, y *funcCoverage) int {
+			return -cmp.Compare(x.Lines-x.Covered, y.Lines-y.Covered)
+		}
+	default:
+		return tool.CommandLineErrorf("invalid -sort value %q", c.Sort)
+	}
+	profile, err := filepath.Abs(c.Profile)
+	if err != nil {
+		return err
+	}
+
+	conn, err := c.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	funcs := []*funcCoverage{} // non-nil, for JSON
+	for _, pattern := range args {
+		dir, recursive := strings.CutSuffix(filepath.ToSlash(pattern), "/...")
+		if dir == "..." {
+			dir, recursive = ".", true
+		}
+		dir, err := filepath.Abs(filepath.FromSlash(dir))
+		if err != nil {
+			return err
+		}
+		cmd := command.NewFunctionCoverageCommand("", command.FunctionCoverageArgs{
+			Profile:   protocol.URIFromPath(profile),
+			URI:       protocol.URIFromPath(dir),
+			Recursive: recursive,
+		})
+		res, err := conn.executeCommand(ctx, cmd)
+		if err != nil {
+			return fmt.Errorf("%s: %v", pattern, err)
+		}
+		// The result is a command.FunctionCoverageResult, unless
+		// decoded from a remote server's response.
+		data, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		var result command.FunctionCoverageResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return fmt.Errorf("decoding result: %v", err)
+		}
+		for _, fn := range result.Functions {
+			file, err := conn.openFile(ctx, fn.Location.URI)
+			if err != nil {
+				return err
+			}
+			spn, err := file.locationSpan(fn.Location)
+			if err != nil {
+				return err
+			}
+			lines := fn.Covered + fn.Uncovered
+			funcs = append(funcs, &funcCoverage{
+				Span:    spn,
+				Package: fn.PkgPath,
+				Name:    fn.Name,
+				Covered: fn.Covered,
+				Lines:   lines,
+				Percent: 100 * float64(fn.Covered) / float64(lines),
+			})
+		}
+	}
+	if compare != nil {
+		slices.SortStableFunc(funcs, compare)
+	}
+
+	if c.app.JSON {
+		return printJSON(funcs)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var covered, lines int
+	for _, fn := range funcs {
+		fmt.Fprintf(w, "%v\t%s\t%.1f%%\t(%d/%d lines)\n", fn.Span, fn.Name, fn.Percent, fn.Covered, fn.Lines)
+		covered += fn.Covered
+		lines += fn.Lines
+	}
+	if lines > 0 {
+		fmt.Fprintf(w, "total\t\t%.1f%%\t(%d/%d lines)\n", 100*float64(covered)/float64(lines), covered, lines)
+	}
+	return w.Flush()
+}
@@ -6,10 +6,8 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"os"
 	"strings"
 
 	"golang.org/x/tools/gopls/internal/protocol"
@@ -123,10 +121,8 @@ func (d *definition) Run(ctx context.Context, args ...string) error {
 		Span:        definition,
 		Description: description,
 	}
-	if d.JSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "\t")
-		return enc.Encode(result)
+	if d.JSON || d.app.JSON {
+		return printJSON(result)
 	}
 	fmt.Printf("%v", result.Span)
 	if len(result.Description) > 0 {
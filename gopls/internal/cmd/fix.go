@@ -0,0 +1,146 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/settings"
+	"golang.org/x/tools/internal/tool"
+)
+
+// fix implements the fix verb for gopls.
+type fix struct {
+	EditFlags
+	Analyzers string `flag:"analyzers" help:"comma-separated list of analyzers whose fixes to apply"`
+	Category  string `flag:"category" help:"comma-separated list of diagnostic categories whose fixes to apply"`
+	app       *Application
+}
+
+func (f *fix) Name() string      { return "fix" }
+func (f *fix) Parent() string    { return f.app.Name() }
+func (f *fix) Usage() string     { return "[fix-flags] <package-or-file>..." }
+func (f *fix) ShortHelp() string { return "apply the suggested fixes of analyzers" }
+func (f *fix) DetailedHelp(fs *flag.FlagSet) {
+	fmt.Fprint(fs.Output(), `
+The fix command runs the analyzers over the specified packages or files
+and applies the first suggested fix of each diagnostic they report, such
+as those of the modernize analyzer. Each package is denoted by its
+directory; a directory followed by /... denotes the packages of the
+workspace in it and its subdirectories.
+
+The -analyzers flag restricts the fixes to those of the named analyzers,
+which are enabled even if they are disabled by default. The -category
+flag restricts the fixes to those of diagnostics of the specified
+categories. Fixes that conflict with an earlier fix are skipped, so
+running the command again may apply more fixes.
+
+Unless -w or -l is specified, the changes are printed as unified diffs.
+When done, the command reports the number of fixes applied, the number
+of conflicting fixes, and the number of fixes that could not be applied
+in bulk.
+
+Example:
+
+	$ gopls fix ./...
+	$ gopls fix -analyzers=modernize -category=minmax -w ./internal/...
+
+fix-flags:
+`)
+	printFlagDefaults(fs)
+}
+
+// Run applies the fixes for the specified packages and either:
+// - if -w is specified, updates the file(s) in place;
+// - if -l is specified, prints the names of the edited files; or
+// - otherwise, prints out unified diffs of the changes.
+func (f *fix) Run(ctx context.Context, args ...string) error {
+	if len(args) == 0 {
+		return tool.CommandLineErrorf("fix expects at least 1 argument (package or file)")
+	}
+	if !f.Write && !f.List {
+		f.Diff = true
+	}
+	f.app.editFlags = &f.EditFlags
+
+	var analyzers, categories []string
+	if f.Analyzers != "" {
+		analyzers = strings.Split(f.Analyzers, ",")
+	}
+	if f.Category != "" {
+		categories = strings.Split(f.Category, ",")
+	}
+	opts := f.app.options
+	f.app.options = func(o *settings.Options) {
+		if opts != nil {
+			opts(o)
+		}
+		if len(analyzers) > 0 && o.Analyses == nil {
+			o.Analyses = make(map[string]bool)
+		}
+		for _, name := range analyzers {
+			o.Analyses[name] = true
+		}
+	}
+
+	conn, err := f.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	var total command.ApplyAnalyzerFixesResult
+	for _, pattern := range args {
+		dir, recursive := strings.CutSuffix(filepath.ToSlash(pattern), "/...")
+		if dir == "..." {
+			dir, recursive = ".", true
+		}
+		dir, err := filepath.Abs(filepath.FromSlash(dir))
+		if err != nil {
+			return err
+		}
+		cmd := command.NewApplyAnalyzerFixesCommand("", command.ApplyAnalyzerFixesArgs{
+			URI:          protocol.URIFromPath(dir),
+			Recursive:    recursive,
+			Analyzers:    analyzers,
+			Categories:   categories,
+			ResolveEdits: true,
+		})
+		res, err := conn.executeCommand(ctx, cmd)
+		if err != nil {
+			return fmt.Errorf("%s: %v", pattern, err)
+		}
+		// The result is a command.ApplyAnalyzerFixesResult, unless
+		// decoded from a remote server's response.
+		data, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		var result command.ApplyAnalyzerFixesResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return fmt.Errorf("decoding result: %v", err)
+		}
+		if result.Edit != nil {
+			if err := conn.client.applyWorkspaceEdit(result.Edit); err != nil {
+				return err
+			}
+		}
+		total.Applied += result.Applied
+		total.Conflicts += result.Conflicts
+		total.Skipped += result.Skipped
+	}
+
+	fmt.Fprintf(os.Stderr, "%d fixes applied, %d conflicting, %d skipped\n",
+		total.Applied, total.Conflicts, total.Skipped)
+	return nil
+}
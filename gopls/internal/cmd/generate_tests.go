@@ -0,0 +1,118 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/internal/tool"
+)
+
+// generateTests implements the generate-tests verb for gopls.
+type generateTests struct {
+	EditFlags
+	ExportedOnly bool `flag:"exported-only" help:"add tests only for exported functions and methods"`
+	app          *Application
+}
+
+func (g *generateTests) Name() string      { return "generate-tests" }
+func (g *generateTests) Parent() string    { return g.app.Name() }
+func (g *generateTests) Usage() string     { return "[generate-tests-flags] <package>..." }
+func (g *generateTests) ShortHelp() string { return "add tests for the untested functions of packages" }
+func (g *generateTests) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+The generate-tests command adds a table-driven test, like the one added
+by the "Add test for F" code action, for each function and method of
+the specified packages that has no test. Each package is denoted by
+its directory; a directory followed by /... denotes the packages of
+the workspace in it and its subdirectories.
+
+When done, it reports the number of functions processed, the number of
+them for which no test could be added, and the number of files created.
+
+Example:
+
+	$ gopls generate-tests ./...
+	$ gopls generate-tests -exported-only -w ./internal/parser
+
+generate-tests-flags:
+`)
+	printFlagDefaults(f)
+}
+
+// Run adds tests for the specified packages and either:
+// - if -w is specified, updates the file(s) in place;
+// - if -l is specified, prints the names of the edited files; or
+// - otherwise, prints out unified diffs of the changes.
+func (g *generateTests) Run(ctx context.Context, args ...string) error {
+	if len(args) == 0 {
+		return tool.CommandLineErrorf("generate-tests expects at least 1 argument (package)")
+	}
+	if !g.Write && !g.List {
+		g.Diff = true
+	}
+	g.app.editFlags = &g.EditFlags
+
+	conn, err := g.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	var total command.AddTestsResult
+	for _, pattern := range args {
+		dir, recursive := strings.CutSuffix(filepath.ToSlash(pattern), "/...")
+		if dir == "..." {
+			dir, recursive = ".", true
+		}
+		dir, err := filepath.Abs(filepath.FromSlash(dir))
+		if err != nil {
+			return err
+		}
+		cmd, err := command.NewAddTestsCommand("", command.AddTestsArgs{
+			URI:          protocol.URIFromPath(dir),
+			Recursive:    recursive,
+			ExportedOnly: g.ExportedOnly,
+			ResolveEdits: true,
+		})
+		if err != nil {
+			return err
+		}
+		res, err := conn.executeCommand(ctx, cmd)
+		if err != nil {
+			return fmt.Errorf("%s: %v", pattern, err)
+		}
+		// The result is a command.AddTestsResult, unless decoded
+		// from a remote server's response.
+		data, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		var result command.AddTestsResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return fmt.Errorf("decoding result: %v", err)
+		}
+		if result.Edit != nil {
+			if err := conn.client.applyWorkspaceEdit(result.Edit); err != nil {
+				return err
+			}
+		}
+		total.Processed += result.Processed
+		total.Skipped += result.Skipped
+		total.Created = append(total.Created, result.Created...)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d functions processed, %d skipped, %d files created\n",
+		total.Processed, total.Skipped, len(total.Created))
+	return nil
+}
@@ -8,7 +8,6 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"sort"
 
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/internal/tool"
@@ -64,7 +63,7 @@ func (i *implementation) Run(ctx context.Context, args ...string) error {
 		return err
 	}
 
-	var spans []string
+	var spans []span
 	for _, impl := range implementations {
 		f, err := conn.openFile(ctx, impl.URI)
 		if err != nil {
@@ -74,10 +73,13 @@ func (i *implementation) Run(ctx context.Context, args ...string) error {
 		if err != nil {
 			return err
 		}
-		spans = append(spans, fmt.Sprint(span))
+		spans = append(spans, span)
 	}
-	sort.Strings(spans)
+	sortSpans(spans)
 
+	if i.app.JSON {
+		return printJSON(spans)
+	}
 	for _, s := range spans {
 		fmt.Println(s)
 	}
@@ -35,12 +35,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
 
 	"golang.org/x/tools/gopls/internal/cmd"
 	"golang.org/x/tools/gopls/internal/debug"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/gopls/internal/version"
 	"golang.org/x/tools/internal/testenv"
@@ -114,6 +116,10 @@ package d
 import "io/ioutil"
 
 var _ = ioutil.ReadFile
+-- e/e.go --
+package e
+
+func f(x interface{}) {}
 `)
 
 	// no files
@@ -163,6 +169,271 @@ var _ = ioutil.ReadFile
 		res.checkExit(true)
 		res.checkStdout(`ioutil.ReadFile is deprecated`)
 	}
+
+	// -json
+	{
+		res := gopls(t, tree, "-json", "check", "./a.go", "./c/c2.go")
+		res.checkExit(true)
+		var diags []struct {
+			Span struct {
+				URI   protocol.DocumentURI
+				Start struct{ Line, Column int }
+			}
+			Severity, Source, Message string
+			Related                   []struct{ Message string }
+		}
+		if res.toJSON(&diags) {
+			if len(diags) != 2 {
+				t.Fatalf("check -json: got %d diagnostics, want 2 (%v)", len(diags), res)
+			}
+			if d := diags[0]; filepath.Base(d.Span.URI.Path()) != "a.go" || d.Span.Start.Line != 3 || d.Severity != "warning" || d.Source != "printf" || !strings.Contains(d.Message, "fmt.Sprintf format %s") {
+				t.Errorf("check -json: unexpected diagnostic %+v", d)
+			}
+			if d := diags[1]; filepath.Base(d.Span.URI.Path()) != "c2.go" || len(d.Related) != 1 || d.Related[0].Message != "other declaration of C" {
+				t.Errorf("check -json: unexpected diagnostic %+v", d)
+			}
+		}
+	}
+
+	// -sarif
+	{
+		res := gopls(t, tree, "check", "-sarif", "-severity=hint", "./a.go", "./e/e.go")
+		res.checkExit(true)
+		type region struct{ StartLine, StartColumn, EndLine, EndColumn int }
+		type location struct {
+			PhysicalLocation struct {
+				ArtifactLocation struct{ URI, URIBaseID string }
+				Region           region
+			}
+		}
+		var log struct {
+			Version string
+			Runs    []struct {
+				Tool struct {
+					Driver struct {
+						Name  string
+						Rules []struct{ ID string }
+					}
+				}
+				Results []struct {
+					RuleID, Level string
+					Message       struct{ Text string }
+					Locations     []location
+					Fixes         []struct {
+						ArtifactChanges []struct {
+							Replacements []struct {
+								DeletedRegion   region
+								InsertedContent struct{ Text string }
+							}
+						}
+					}
+				}
+			}
+		}
+		if res.toJSON(&log) {
+			if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "gopls" {
+				t.Fatalf("check -sarif: unexpected log %v", res)
+			}
+			var printf, modernize bool
+			for _, r := range log.Runs[0].Results {
+				loc := r.Locations[0].PhysicalLocation
+				switch r.RuleID {
+				case "printf":
+					printf = true
+					if r.Level != "warning" || loc.ArtifactLocation.URI != "a.go" || loc.ArtifactLocation.URIBaseID != "SRCROOT" || loc.Region.StartLine != 3 {
+						t.Errorf("check -sarif: unexpected printf result %+v", r)
+					}
+				case "modernize":
+					modernize = true
+					if loc.ArtifactLocation.URI != "e/e.go" || loc.Region != (region{3, 10, 3, 21}) {
+						t.Errorf("check -sarif: unexpected modernize result %+v", r)
+					}
+					if len(r.Fixes) != 1 || len(r.Fixes[0].ArtifactChanges) != 1 {
+						t.Fatalf("check -sarif: modernize result has no fix: %+v", r)
+					}
+					if repl := r.Fixes[0].ArtifactChanges[0].Replacements; len(repl) != 1 || repl[0].InsertedContent.Text != "any" {
+						t.Errorf("check -sarif: unexpected modernize fix %+v", repl)
+					}
+				}
+			}
+			if !printf || !modernize {
+				t.Errorf("check -sarif: missing printf or modernize result: %v", res)
+			}
+		}
+	}
+}
+
+// TestAddTest tests the 'addtest' subcommand (addtest.go).
+func TestAddTest(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- a/a.go --
+package a
+
+func Parse(s string) int { return len(s) }
+
+type T int
+
+func (T) Format() string { return "" }
+-- b/b.go --
+package b
+
+func Parse(s string) int { return len(s) }
+`)
+	// no arguments
+	{
+		res := gopls(t, tree, "addtest")
+		res.checkExit(false)
+		res.checkStderr("expects 1 argument")
+	}
+	// position (default diff)
+	{
+		res := gopls(t, tree, "addtest", "a/a.go:3:6")
+		res.checkExit(true)
+		res.checkStdout(regexp.QuoteMeta("+func TestParse(t *testing.T) {"))
+		if _, err := os.Stat(filepath.Join(tree, "a/a_test.go")); !os.IsNotExist(err) {
+			t.Errorf("addtest without -w created a/a_test.go (err=%v)", err)
+		}
+	}
+	// ambiguous symbol
+	{
+		res := gopls(t, tree, "addtest", "Parse")
+		res.checkExit(false)
+		res.checkStderr("Parse is ambiguous: example.com/a.Parse, example.com/b.Parse")
+	}
+	// unknown symbol
+	{
+		res := gopls(t, tree, "addtest", "a.Print")
+		res.checkExit(false)
+		res.checkStderr("no function or method named a.Print")
+	}
+	// qualified method (and -write)
+	{
+		res := gopls(t, tree, "addtest", "-w", "a.T.Format")
+		res.checkExit(true)
+		data, err := os.ReadFile(filepath.Join(tree, "a/a_test.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "func TestT_Format(t *testing.T) {"
+		if got := string(data); !strings.Contains(got, want) {
+			t.Errorf("addtest -w: a/a_test.go does not contain %q:\n%s", want, got)
+		}
+	}
+	// -json: one value per file operation (creation, then edit)
+	{
+		res := gopls(t, tree, "-json", "addtest", "b.Parse")
+		res.checkExit(true)
+		var (
+			created bool
+			text    strings.Builder
+		)
+		for dec := json.NewDecoder(strings.NewReader(res.stdout)); dec.More(); {
+			var edit struct {
+				File    string
+				Created bool
+				Edits   []struct{ NewText string }
+			}
+			if err := dec.Decode(&edit); err != nil {
+				t.Fatalf("addtest -json: %v (%v)", err, res)
+			}
+			if filepath.Base(edit.File) != "b_test.go" {
+				t.Errorf("addtest -json: unexpected edit of %s", edit.File)
+			}
+			created = created || edit.Created
+			for _, e := range edit.Edits {
+				text.WriteString(e.NewText)
+			}
+		}
+		if !created || !strings.Contains(text.String(), "func TestParse(t *testing.T) {") {
+			t.Errorf("addtest -json: unexpected output (%v)", res)
+		}
+		if _, err := os.Stat(filepath.Join(tree, "b/b_test.go")); !os.IsNotExist(err) {
+			t.Errorf("addtest -json created b/b_test.go (err=%v)", err)
+		}
+	}
+}
+
+// TestAffected tests the 'affected' subcommand (affected.go).
+func TestAffected(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- a/a.go --
+package a
+
+func F() {}
+
+func G() {}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestF(t *testing.T) { F() }
+-- a/g_test.go --
+package a
+
+import "testing"
+
+func TestG(t *testing.T) { G() }
+-- b/b.go --
+package b
+
+import "example.com/a"
+
+var V = a.F
+-- b/b_test.go --
+package b
+
+import "testing"
+
+func TestV(t *testing.T) { V() }
+`)
+	// missing argument
+	{
+		res := gopls(t, tree, "affected")
+		res.checkExit(false)
+		res.checkStderr("expects at least 1 argument")
+	}
+	// a test file
+	{
+		res := gopls(t, tree, "affected", "./a/a_test.go")
+		res.checkExit(true)
+		if got, want := res.stdout, "example.com/a TestF\n"; got != want {
+			t.Errorf("affected: got %q, want %q", got, want)
+		}
+	}
+	// an exported function, -json
+	{
+		res := gopls(t, tree, "-json", "affected", "./a/a.go")
+		res.checkExit(true)
+		var pkgs []struct {
+			Package string
+			Tests   []string
+			All     bool
+		}
+		if res.toJSON(&pkgs) {
+			if len(pkgs) != 2 {
+				t.Fatalf("affected -json: got %d packages, want 2 (%v)", len(pkgs), res)
+			}
+			if pkg := pkgs[0]; pkg.Package != "example.com/a" || !slices.Equal(pkg.Tests, []string{"TestF", "TestG"}) || !pkg.All {
+				t.Errorf("affected -json: unexpected first package %+v", pkg)
+			}
+			if pkg := pkgs[1]; pkg.Package != "example.com/b" || !slices.Equal(pkg.Tests, []string{"TestV"}) || !pkg.All {
+				t.Errorf("affected -json: unexpected second package %+v", pkg)
+			}
+		}
+	}
 }
 
 // TestCallHierarchy tests the 'call_hierarchy' subcommand (call_hierarchy.go).
@@ -260,6 +531,86 @@ func TestFail(t *testing.T) { t.Fatal("fail") }
 	}
 }
 
+// TestCoverage tests the 'coverage' subcommand (coverage.go).
+func TestCoverage(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- a/a.go --
+package a
+
+func F(x int) int {
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+func G() {
+	println()
+}
+
+type T int
+
+func (T) M() {}
+-- b/b.go --
+package b
+
+func H() {}
+-- cover.out --
+mode: set
+example.com/a/a.go:3.19,4.11 1 1
+example.com/a/a.go:4.11,6.3 1 0
+example.com/a/a.go:7.2,7.10 1 1
+example.com/a/a.go:10.10,12.2 1 0
+`)
+	// missing profile
+	{
+		res := gopls(t, tree, "coverage", "./...")
+		res.checkExit(false)
+		res.checkStderr("requires a -profile flag")
+	}
+	// functions in order of position, omitting M and b.H
+	{
+		res := gopls(t, tree, "coverage", "-profile=cover.out", "./...")
+		res.checkExit(true)
+		got := strings.Fields(res.stdout)
+		want := strings.Fields(`
+./a/a.go:3:6-7  F      60.0%  (3/5 lines)
+./a/a.go:10:6-7 G      0.0%   (0/3 lines)
+total                  37.5%  (3/8 lines)
+`)
+		if !slices.Equal(got, want) {
+			t.Errorf("coverage: got %q, want %q", res.stdout, want)
+		}
+	}
+	// -sort=uncovered -json
+	{
+		res := gopls(t, tree, "-json", "coverage", "-profile=cover.out", "-sort=uncovered", "./a")
+		res.checkExit(true)
+		var funcs []struct {
+			Package, Name  string
+			Covered, Lines int
+			Percent        float64
+		}
+		if res.toJSON(&funcs) {
+			if len(funcs) != 2 {
+				t.Fatalf("coverage -json: got %d functions, want 2 (%v)", len(funcs), res)
+			}
+			if fn := funcs[0]; fn.Package != "example.com/a" || fn.Name != "G" || fn.Covered != 0 || fn.Lines != 3 {
+				t.Errorf("coverage -json: unexpected first function %+v", fn)
+			}
+			if fn := funcs[1]; fn.Name != "F" || fn.Covered != 3 || fn.Lines != 5 || fn.Percent != 60 {
+				t.Errorf("coverage -json: unexpected second function %+v", fn)
+			}
+		}
+	}
+}
+
 // TestDefinition tests the 'definition' subcommand (definition.go).
 func TestDefinition(t *testing.T) {
 	t.Parallel()
@@ -475,6 +826,124 @@ func f() {}
 	}
 }
 
+// TestGenerateTests tests the 'generate-tests' subcommand (generate_tests.go).
+func TestGenerateTests(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- a/a.go --
+package a
+
+func F(x int) int { return x }
+
+func g(x int) int { return x }
+-- a/b/b.go --
+package b
+
+func H(s string) string { return s }
+-- c/c.go --
+package c
+
+func C() {}
+
+func d() {}
+-- c/c_test.go --
+package c_test
+`)
+	// no arguments
+	{
+		res := gopls(t, tree, "generate-tests")
+		res.checkExit(false)
+		res.checkStderr("expects at least 1 argument")
+	}
+	// all packages (default diff); d cannot be tested from c_test
+	{
+		res := gopls(t, tree, "generate-tests", "./...")
+		res.checkExit(true)
+		res.checkStdout(regexp.QuoteMeta("+func TestF(t *testing.T) {"))
+		res.checkStdout(regexp.QuoteMeta("+func Test_g(t *testing.T) {"))
+		res.checkStdout(regexp.QuoteMeta("+func TestH(t *testing.T) {"))
+		res.checkStdout(regexp.QuoteMeta("+func TestC(t *testing.T) {"))
+		res.checkStderr("5 functions processed, 1 skipped, 2 files created")
+	}
+	// exported functions only (and -write)
+	{
+		res := gopls(t, tree, "generate-tests", "-exported-only", "-w", "./a/...")
+		res.checkExit(true)
+		res.checkStderr("2 functions processed, 0 skipped, 2 files created")
+		data, err := os.ReadFile(filepath.Join(tree, "a/a_test.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); !strings.Contains(got, "func TestF(") || strings.Contains(got, "func Test_g(") {
+			t.Errorf("generate-tests -exported-only -w: unexpected a/a_test.go:\n%s", got)
+		}
+	}
+}
+
+// TestFix tests the 'fix' subcommand (fix.go).
+func TestFix(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.22
+
+-- a/a.go --
+package a
+
+func F(x interface{}) {}
+
+func G(a, b int) int {
+	x := a
+	if b > x {
+		x = b
+	}
+	return x
+}
+-- a/b/b.go --
+package b
+
+func H() {
+	for i := 0; i < 10; i++ {
+	}
+}
+`)
+	// no arguments
+	{
+		res := gopls(t, tree, "fix")
+		res.checkExit(false)
+		res.checkStderr("expects at least 1 argument")
+	}
+	// all packages (default diff)
+	{
+		res := gopls(t, tree, "fix", "./...")
+		res.checkExit(true)
+		res.checkStdout(regexp.QuoteMeta("+func F(x any) {}"))
+		res.checkStdout(regexp.QuoteMeta("+	x := max(b, a)"))
+		res.checkStdout(regexp.QuoteMeta("+	for range 10 {"))
+		res.checkStderr("3 fixes applied, 0 conflicting, 0 skipped")
+	}
+	// one category of one package (and -write)
+	{
+		res := gopls(t, tree, "fix", "-analyzers=modernize", "-category=efaceany", "-w", "./a")
+		res.checkExit(true)
+		res.checkStderr("1 fixes applied, 0 conflicting, 0 skipped")
+		data, err := os.ReadFile(filepath.Join(tree, "a/a.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); !strings.Contains(got, "func F(x any)") || strings.Contains(got, "max(") {
+			t.Errorf("fix -category=efaceany -w: unexpected a/a.go:\n%s", got)
+		}
+	}
+}
+
 // TestHighlight tests the 'highlight' subcommand (highlight.go).
 func TestHighlight(t *testing.T) {
 	t.Parallel()
@@ -663,6 +1132,21 @@ func g() {
 		res.checkStdout("a.go:4:6-13")
 		res.checkStdout("b.go:4:6-13")
 	}
+	// -json
+	{
+		res := gopls(t, tree, "-json", "references", "a.go:4:10")
+		res.checkExit(true)
+		var spans []struct {
+			URI        protocol.DocumentURI
+			Start, End struct{ Line, Column int }
+		}
+		if res.toJSON(&spans) {
+			if len(spans) != 2 || filepath.Base(spans[0].URI.Path()) != "a.go" || filepath.Base(spans[1].URI.Path()) != "b.go" ||
+				spans[0].Start.Line != 4 || spans[0].Start.Column != 6 || spans[0].End.Column != 13 {
+				t.Errorf("references -json: unexpected spans %+v", spans)
+			}
+		}
+	}
 }
 
 // TestSignature tests the 'signature' subcommand (signature.go).
@@ -781,6 +1265,53 @@ func oldname() {}
 	}
 }
 
+// TestRenamePackage tests that the 'rename' subcommand applies the
+// renaming of a package's directory.
+func TestRenamePackage(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- a/a.go --
+package a
+
+func A() {}
+-- a/sub/sub.go --
+package sub
+-- main.go --
+package main
+
+import "example.com/a"
+
+func main() { a.A() }
+`)
+	// -diff shows the creation and deletion of files
+	{
+		res := gopls(t, tree, "rename", "-diff", "a/a.go:1:9", "b")
+		res.checkExit(true)
+		res.checkStdout(`--- .*a.go.orig\n\+\+\+ .*a.go\n@@ -1,3 \+0,0 @@`)
+		res.checkStdout(regexp.QuoteMeta("+package b"))
+		res.checkStdout(regexp.QuoteMeta(`+import "example.com/b"`))
+		if _, err := os.Stat(filepath.Join(tree, "b")); !os.IsNotExist(err) {
+			t.Errorf("rename -diff created b (err=%v)", err)
+		}
+	}
+	// -write moves the files
+	{
+		res := gopls(t, tree, "rename", "-w", "a/a.go:1:9", "b")
+		res.checkExit(true)
+		checkContent(t, filepath.Join(tree, "b/a.go"), "package b\n\nfunc A() {}\n")
+		checkContent(t, filepath.Join(tree, "b/sub/sub.go"), "package sub\n")
+		checkContent(t, filepath.Join(tree, "main.go"), "package main\n\nimport \"example.com/b\"\n\nfunc main() { b.A() }\n")
+		if _, err := os.Stat(filepath.Join(tree, "a")); !os.IsNotExist(err) {
+			t.Errorf("rename -w did not remove a (err=%v)", err)
+		}
+	}
+}
+
 // TestSymbols tests the 'symbols' subcommand (symbols.go).
 func TestSymbols(t *testing.T) {
 	t.Parallel()
@@ -925,6 +1456,21 @@ package foo
 		}
 	}
 
+	// Check that the completed memstats command was recorded.
+	{
+		var got *command.CommandStats
+		for i, cs := range stats.WorkspaceStats.Commands {
+			if cs.Command == command.MemStats.String() {
+				got = &stats.WorkspaceStats.Commands[i]
+			}
+		}
+		if got == nil {
+			t.Errorf("WorkspaceStats.Commands does not contain %s. Got:<<%+v>>", command.MemStats, stats.WorkspaceStats.Commands)
+		} else if got.Started != 1 || got.Completed != 1 || got.Failed != 0 {
+			t.Errorf("WorkspaceStats.Commands[%s] = %+v, want 1 started and completed execution", command.MemStats, *got)
+		}
+	}
+
 	// Check that -anon suppresses fields containing user information.
 	{
 		res2 := gopls(t, tree, "stats", "-anon")
@@ -982,6 +1528,13 @@ package a
 import "io"
 var _ io.Reader = C{}
 type C struct{}
+
+-- a/c.go --
+package a
+
+func G() {}
+
+func H() {}
 `)
 
 	// no arguments
@@ -1023,6 +1576,18 @@ type C struct{}
 			t.Errorf("codeaction: got <<%s>>, want <<%s>>\nstderr:\n%s", got, want, res.stderr)
 		}
 	}
+	// list code actions in file, in JSON
+	{
+		res := gopls(t, tree, "-json", "codeaction", "-kind=source.doc", "a/a.go")
+		res.checkExit(true)
+		var actions []struct{ Title, Kind, Command string }
+		if res.toJSON(&actions) {
+			if len(actions) != 1 || actions[0].Title != "Browse documentation for package a" ||
+				actions[0].Kind != "source.doc" || actions[0].Command != "gopls.doc" {
+				t.Errorf("codeaction -json: unexpected actions %+v", actions)
+			}
+		}
+	}
 	// list code actions at position (of io.Reader)
 	{
 		res := gopls(t, tree, "codeaction", "a/b.go:#31")
@@ -1062,6 +1627,14 @@ func (c C) Read(p []byte) (n int, err error) {
 			t.Errorf("codeaction: got <<%s>>, want <<%s>>\nstderr:\n%s", got, want, res.stderr)
 		}
 	}
+	// success, with an edit that creates a file (and -diff)
+	{
+		res := gopls(t, tree, "codeaction", "-kind=refactor.extract.toNewFile", "-exec", "-diff", "a/c.go:#11-#22")
+		res.checkExit(true)
+		res.checkStdout(regexp.QuoteMeta("-func G() {}"))
+		res.checkStdout(`--- .*g.go.orig\n\+\+\+ .*g.go\n`)
+		res.checkStdout(regexp.QuoteMeta("+func G() {}"))
+	}
 }
 
 // TestWorkspaceSymbol tests the 'workspace_symbol' subcommand (workspace_symbol.go).
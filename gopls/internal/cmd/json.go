@@ -0,0 +1,134 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+// This file defines the JSON output of the -json flag.
+
+import (
+	"encoding/json"
+	"os"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/diff"
+)
+
+// printJSON prints v to stdout in indented JSON form.
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	return enc.Encode(v)
+}
+
+// A fileEdit is the JSON form of an edit to a single file.
+// Each edited file is printed as a separate JSON value.
+type fileEdit struct {
+	File    string     `json:"file"`
+	Created bool       `json:"created,omitempty"`
+	Deleted bool       `json:"deleted,omitempty"`
+	Edits   []textEdit `json:"edits"`
+}
+
+// A textEdit is the JSON form of a text edit. Its span denotes the
+// replaced text of the original file.
+type textEdit struct {
+	Span    span   `json:"span"`
+	NewText string `json:"newText"`
+}
+
+// printFileEdit prints the edit of the specified file as a [fileEdit].
+// As in [updateFile], a nil old or new content denotes the creation
+// or deletion of the file.
+func printFileEdit(filename string, old, new []byte, edits []diff.Edit) error {
+	mapper := protocol.NewMapper(protocol.URIFromPath(filename), old)
+	fe := fileEdit{
+		File:    filename,
+		Created: old == nil,
+		Deleted: new == nil,
+		Edits:   []textEdit{}, // non-nil, for JSON
+	}
+	for _, edit := range edits {
+		if edit.Start == edit.End && edit.New == "" {
+			continue // no-op, such as the creation of an empty file
+		}
+		start, err := offsetPoint(mapper, edit.Start)
+		if err != nil {
+			return err
+		}
+		end, err := offsetPoint(mapper, edit.End)
+		if err != nil {
+			return err
+		}
+		fe.Edits = append(fe.Edits, textEdit{
+			Span:    newSpan(mapper.URI, start, end),
+			NewText: edit.New,
+		})
+	}
+	return printJSON(&fe) // (addressable, for span.MarshalJSON)
+}
+
+// A diagnostic is the JSON form of a diagnostic.
+type diagnostic struct {
+	Span     span                 `json:"span"`
+	Severity string               `json:"severity"`
+	Source   string               `json:"source,omitempty"`
+	Code     any                  `json:"code,omitempty"`
+	Message  string               `json:"message"`
+	Related  []relatedInformation `json:"related,omitempty"`
+}
+
+// A relatedInformation is the JSON form of a diagnostic's related
+// information.
+type relatedInformation struct {
+	Span    span   `json:"span"`
+	Message string `json:"message"`
+}
+
+// severityName returns the name of the severity, as accepted by the
+// -severity flag of the check command.
+func severityName(severity protocol.DiagnosticSeverity) string {
+	switch severity {
+	case protocol.SeverityError:
+		return "error"
+	case protocol.SeverityWarning:
+		return "warning"
+	case protocol.SeverityInformation:
+		return "info"
+	default:
+		return "hint"
+	}
+}
+
+// A codeAction is the JSON form of a code action listed by the
+// codeaction command. Command is the name of the action's command,
+// if any.
+type codeAction struct {
+	Title   string                  `json:"title"`
+	Kind    protocol.CodeActionKind `json:"kind"`
+	Command string                  `json:"command,omitempty"`
+}
+
+// An affectedPackage is the JSON form of the affected tests of a
+// package, as reported by the affected command. All reports whether
+// all of its tests are affected, so that it may be tested without
+// selecting Tests.
+type affectedPackage struct {
+	Package string   `json:"package"`
+	Dir     string   `json:"dir"`
+	Tests   []string `json:"tests"`
+	All     bool     `json:"all"`
+}
+
+// A funcCoverage is the JSON form of the test coverage of a function,
+// as reported by the coverage command. Its span is that of the
+// function's name; Lines is the number of lines of its body that
+// contain statements, of which Covered were executed.
+type funcCoverage struct {
+	Span    span    `json:"span"`
+	Package string  `json:"package"`
+	Name    string  `json:"name"`
+	Covered int     `json:"covered"`
+	Lines   int     `json:"lines"`
+	Percent float64 `json:"percent"`
+}
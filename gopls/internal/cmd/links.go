@@ -6,10 +6,8 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"os"
 
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/internal/tool"
@@ -64,10 +62,8 @@ func (l *links) Run(ctx context.Context, args ...string) error {
 	if err != nil {
 		return fmt.Errorf("%v: %v", from, err)
 	}
-	if l.JSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "\t")
-		return enc.Encode(results)
+	if l.JSON || l.app.JSON {
+		return printJSON(results)
 	}
 	for _, v := range results {
 		fmt.Println(*v.Target)
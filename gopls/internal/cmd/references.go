@@ -8,7 +8,6 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"sort"
 
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/internal/tool"
@@ -68,7 +67,7 @@ func (r *references) Run(ctx context.Context, args ...string) error {
 	if err != nil {
 		return err
 	}
-	var spans []string
+	var spans []span
 	for _, l := range locations {
 		f, err := conn.openFile(ctx, l.URI)
 		if err != nil {
@@ -80,10 +79,13 @@ func (r *references) Run(ctx context.Context, args ...string) error {
 		if err != nil {
 			return err
 		}
-		spans = append(spans, fmt.Sprint(span))
+		spans = append(spans, span)
 	}
 
-	sort.Strings(spans)
+	sortSpans(spans)
+	if r.app.JSON {
+		return printJSON(spans)
+	}
 	for _, s := range spans {
 		fmt.Println(s)
 	}
@@ -0,0 +1,267 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+// This file defines the SARIF output of the check command.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	versionpkg "golang.org/x/tools/gopls/internal/version"
+)
+
+// A sarifLog is the root object of a SARIF file.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	ColumnKind         string                           `json:"columnKind"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// A sarifRegion is a range of a text file. Lines and columns are
+// 1-based; columns are measured in UTF-16 code units, as in LSP.
+type sarifRegion struct {
+	StartLine   uint32 `json:"startLine"`
+	StartColumn uint32 `json:"startColumn"`
+	EndLine     uint32 `json:"endLine"`
+	EndColumn   uint32 `json:"endColumn"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion  `json:"deletedRegion"`
+	InsertedContent sarifMessage `json:"insertedContent"`
+}
+
+// sarifBaseID is the symbolic name of the directory relative to
+// which the files of the workspace are reported.
+const sarifBaseID = "SRCROOT"
+
+// A sarifBuilder builds a SARIF log of diagnostics.
+type sarifBuilder struct {
+	conn  *connection
+	root  string // working directory
+	rules map[string]sarifRule
+	run   sarifRun
+}
+
+func newSARIFBuilder(conn *connection) (*sarifBuilder, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return &sarifBuilder{
+		conn:  conn,
+		root:  wd,
+		rules: make(map[string]sarifRule),
+		run: sarifRun{
+			OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+				sarifBaseID: {URI: string(protocol.URIFromPath(wd)) + "/"},
+			},
+			ColumnKind: "utf16CodeUnits",
+			Results:    []sarifResult{}, // non-nil, for JSON
+		},
+	}, nil
+}
+
+// add adds a result for the diagnostic of the specified file, along
+// with the edits of the quick fixes the server offers for it.
+func (b *sarifBuilder) add(ctx context.Context, uri protocol.DocumentURI, diag protocol.Diagnostic) error {
+	ruleID := diag.Source
+	if ruleID == "" {
+		ruleID = "gopls"
+	}
+	if _, ok := b.rules[ruleID]; !ok {
+		rule := sarifRule{ID: ruleID}
+		if diag.CodeDescription != nil {
+			rule.HelpURI = string(diag.CodeDescription.Href)
+		}
+		b.rules[ruleID] = rule
+	}
+
+	result := sarifResult{
+		RuleID:    ruleID,
+		Level:     sarifLevel(diag.Severity),
+		Message:   sarifMessage{Text: diag.Message},
+		Locations: []sarifLocation{b.location(uri, diag.Range)},
+	}
+	for i, rel := range diag.RelatedInformation {
+		loc := b.location(rel.Location.URI, rel.Location.Range)
+		loc.ID = i + 1
+		loc.Message = &sarifMessage{Text: rel.Message}
+		result.RelatedLocations = append(result.RelatedLocations, loc)
+	}
+
+	// Request the quick fixes for the diagnostic. Only fixes
+	// whose edits are computed eagerly can be reported.
+	actions, err := b.conn.CodeAction(ctx, &protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        diag.Range,
+		Context: protocol.CodeActionContext{
+			Only:        []protocol.CodeActionKind{protocol.QuickFix},
+			Diagnostics: []protocol.Diagnostic{diag},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%v: %v", uri, err)
+	}
+	for _, act := range actions {
+		if act.Edit == nil || act.Disabled != nil ||
+			!slices.ContainsFunc(act.Diagnostics, func(d protocol.Diagnostic) bool {
+				return d.Range == diag.Range && d.Message == diag.Message
+			}) {
+			continue
+		}
+		fix := sarifFix{Description: sarifMessage{Text: act.Title}}
+		for _, c := range act.Edit.DocumentChanges {
+			if c.TextDocumentEdit == nil {
+				fix.ArtifactChanges = nil // file operations are not representable
+				break
+			}
+			change := sarifArtifactChange{
+				ArtifactLocation: b.artifact(c.TextDocumentEdit.TextDocument.URI),
+			}
+			for _, edit := range protocol.AsTextEdits(c.TextDocumentEdit.Edits) {
+				change.Replacements = append(change.Replacements, sarifReplacement{
+					DeletedRegion:   sarifRegionOf(edit.Range),
+					InsertedContent: sarifMessage{Text: edit.NewText},
+				})
+			}
+			fix.ArtifactChanges = append(fix.ArtifactChanges, change)
+		}
+		if len(fix.ArtifactChanges) > 0 {
+			result.Fixes = append(result.Fixes, fix)
+		}
+	}
+
+	b.run.Results = append(b.run.Results, result)
+	return nil
+}
+
+// log returns the completed SARIF log.
+func (b *sarifBuilder) log() *sarifLog {
+	run := b.run
+	run.Tool.Driver = sarifDriver{
+		Name:           "gopls",
+		Version:        versionpkg.Version(),
+		InformationURI: "https://go.dev/gopls",
+		Rules:          []sarifRule{}, // non-nil, for JSON
+	}
+	for _, id := range slices.Sorted(maps.Keys(b.rules)) {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, b.rules[id])
+	}
+	return &sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	}
+}
+
+func (b *sarifBuilder) location(uri protocol.DocumentURI, rng protocol.Range) sarifLocation {
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: b.artifact(uri),
+			Region:           sarifRegionOf(rng),
+		},
+	}
+}
+
+// artifact returns the location of the file, relative to the working
+// directory if it is within it.
+func (b *sarifBuilder) artifact(uri protocol.DocumentURI) sarifArtifactLocation {
+	if rel, err := filepath.Rel(b.root, uri.Path()); err == nil && !strings.HasPrefix(rel, "..") {
+		return sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: sarifBaseID}
+	}
+	return sarifArtifactLocation{URI: string(uri)}
+}
+
+func sarifRegionOf(rng protocol.Range) sarifRegion {
+	return sarifRegion{
+		StartLine:   rng.Start.Line + 1,
+		StartColumn: rng.Start.Character + 1,
+		EndLine:     rng.End.Line + 1,
+		EndColumn:   rng.End.Character + 1,
+	}
+}
+
+// sarifLevel returns the SARIF level of a diagnostic severity.
+func sarifLevel(severity protocol.DiagnosticSeverity) string {
+	switch severity {
+	case protocol.SeverityError:
+		return "error"
+	case protocol.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"strings"
 	"time"
 
+	"golang.org/x/tools/gopls/internal/debug"
 	"golang.org/x/tools/gopls/internal/filecache"
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/gopls/internal/protocol/command"
@@ -64,7 +65,9 @@ func (s *stats) Run(ctx context.Context, args ...string) error {
 	}
 
 	if !s.app.Verbose {
-		event.SetExporter(nil) // don't log errors to stderr
+		// Don't log errors to stderr, but record the statistics
+		// of the commands reported by the WorkspaceStats command.
+		event.SetExporter(debug.MakeQuietExporter())
 	}
 
 	stats := GoplsStats{
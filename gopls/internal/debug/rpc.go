@@ -13,6 +13,8 @@ import (
 	"sync"
 	"time"
 
+	label1 "golang.org/x/tools/gopls/internal/label"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/event/core"
 	"golang.org/x/tools/internal/event/export"
@@ -27,6 +29,8 @@ var RPCTmpl = template.Must(template.Must(BaseTemplate.Clone()).Parse(`
 	{{template "rpcSection" .Inbound}}
 	<H2>Outbound</H2>
 	{{template "rpcSection" .Outbound}}
+	<H2>Commands</H2>
+	{{template "commandSection" .Commands}}
 {{end}}
 {{define "rpcSection"}}
 	{{range .}}<P>
@@ -42,12 +46,24 @@ var RPCTmpl = template.Must(template.Must(BaseTemplate.Clone()).Parse(`
 		</P>
 	{{end}}
 {{end}}
+{{define "commandSection"}}
+	{{range .}}<P>
+		<b>{{.Method}}</b> {{.Started}} ({{.InProgress}} in progress)
+		<br>
+		<i>Latency</i> {{with .Latency}}{{.Mean}} ({{.Min}}<{{.Max}}){{end}}
+		<i>By bucket</i> 0s {{range .Latency.Values}}{{if gt .Count 0}}<b>{{.Count}}</b> {{.Limit}} {{end}}{{end}}
+		<br>
+		<i>Outcomes</i> {{range .Codes}}{{.Key}}={{.Count}} {{end}}
+		</P>
+	{{end}}
+{{end}}
 `))
 
 type Rpcs struct { // exported for testing
 	mu       sync.Mutex
 	Inbound  []*rpcStats // stats for incoming lsp rpcs sorted by method name
 	Outbound []*rpcStats // stats for outgoing lsp rpcs sorted by method name
+	Commands []*rpcStats // stats for executed commands sorted by command name
 }
 
 type rpcStats struct {
@@ -164,14 +180,22 @@ func (r *Rpcs) getRPCSpan(ctx context.Context) (*export.Span, *rpcStats) {
 }
 
 func (r *Rpcs) getRPCStats(lm label.Map) *rpcStats {
+	var set *[]*rpcStats
 	method := jsonrpc2.Method.Get(lm)
-	if method == "" {
+	switch {
+	case method != "":
+		set = &r.Inbound
+		if jsonrpc2.RPCDirection.Get(lm) != jsonrpc2.Inbound {
+			set = &r.Outbound
+		}
+	case label1.Command.Get(lm) != "":
+		// The span of an executeCommand request, whose stats are
+		// recorded per command (see [server.ExecuteCommand]).
+		method = label1.Command.Get(lm)
+		set = &r.Commands
+	default:
 		return nil
 	}
-	set := &r.Inbound
-	if jsonrpc2.RPCDirection.Get(lm) != jsonrpc2.Inbound {
-		set = &r.Outbound
-	}
 	// get the record for this method
 	index := sort.Search(len(*set), func(i int) bool {
 		return (*set)[i].Method >= method
@@ -205,6 +229,9 @@ func getStatusCode(span *export.Span) string {
 		if status := jsonrpc2.StatusCode.Get(ev); status != "" {
 			return status
 		}
+		if status := label1.CommandStatus.Get(ev); status != "" {
+			return status
+		}
 	}
 	return ""
 }
@@ -213,6 +240,35 @@ func (r *Rpcs) getData(req *http.Request) any {
 	return r
 }
 
+// commandStats returns the statistics of the executed commands, sorted
+// by command name.
+func (r *Rpcs) commandStats() []command.CommandStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]command.CommandStats, 0, len(r.Commands))
+	for _, stats := range r.Commands {
+		cs := command.CommandStats{
+			Command:   stats.Method,
+			Started:   stats.Started,
+			Completed: stats.Completed,
+		}
+		for _, b := range stats.Codes {
+			switch b.Key { // see server.CommandFailed, server.CommandCanceled
+			case "failed":
+				cs.Failed = b.Count
+			case "canceled":
+				cs.Canceled = b.Count
+			}
+		}
+		if stats.Latency.Count > 0 {
+			cs.MeanMillis = float64(stats.Latency.Mean())
+			cs.MaxMillis = float64(stats.Latency.Max)
+		}
+		res = append(res, cs)
+	}
+	return res
+}
+
 func units(v float64, suffixes []string) string {
 	s := ""
 	for _, s = range suffixes {
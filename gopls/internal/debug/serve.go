@@ -28,6 +28,7 @@ import (
 	"golang.org/x/tools/gopls/internal/debug/log"
 	label1 "golang.org/x/tools/gopls/internal/label"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/event/core"
@@ -497,6 +498,29 @@ func (i *Instance) ListenedDebugAddress() string {
 	return i.listenedDebugAddress
 }
 
+// CommandStats returns the durations and outcomes of the commands
+// executed by the servers of this instance, sorted by command name.
+func (i *Instance) CommandStats() []command.CommandStats {
+	if i.rpcs == nil {
+		return nil
+	}
+	return i.rpcs.commandStats()
+}
+
+// MakeQuietExporter returns an event exporter that, unlike the default
+// exporter, drops all log events, but still records the spans and
+// metrics of the debug instance of the context, such as the statistics
+// of executed commands.
+func MakeQuietExporter() event.Exporter {
+	return func(ctx context.Context, ev core.Event, lm label.Map) context.Context {
+		i := GetInstance(ctx)
+		if i == nil || event.IsLog(ev) {
+			return ctx
+		}
+		return i.exporter(ctx, ev, lm)
+	}
+}
+
 func makeGlobalExporter(stderr io.Writer) event.Exporter {
 	p := export.Printer{}
 	var pMu sync.Mutex
@@ -0,0 +1,325 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the "Add context.Context parameter to F" code
+// actions.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/astutil/edge"
+)
+
+// addContextParamAt returns the declaration of the selected function
+// or method, if a context.Context parameter can be added to it.
+func addContextParamAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.FuncDecl, *types.Func) {
+	decl, fn := funcDeclAt(pkg, pgf, start, end)
+	if decl == nil || !canAddContextParam(pkg.TypesInfo(), pgf, decl, fn) {
+		return nil, nil
+	}
+	return decl, fn
+}
+
+// canAddContextParam reports whether a parameter ctx of type
+// context.Context can be added to the function fn declared by decl:
+// it must not already have a context.Context parameter, nor refer to
+// any other ctx, and its signature must not be fixed by convention.
+func canAddContextParam(info *types.Info, pgf *parsego.File, decl *ast.FuncDecl, fn *types.Func) bool {
+	if decl.Body == nil {
+		return false
+	}
+	if decl.Recv == nil {
+		switch {
+		case fn.Name() == "init",
+			fn.Name() == "main" && fn.Pkg().Name() == "main":
+			return false
+		}
+		if strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+			for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+				if strings.HasPrefix(fn.Name(), prefix) {
+					return false
+				}
+			}
+		}
+	}
+	for v := range fn.Signature().Params().Variables() {
+		if analysisinternal.IsTypeNamed(v.Type(), "context", "Context") {
+			return false
+		}
+	}
+	// The new parameter must not conflict with, or be shadowed by,
+	// any other ctx. (A field or method x.ctx does neither.)
+	found := false
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.Ident:
+			if n.Name == "ctx" {
+				found = true
+			}
+		}
+		return !found
+	}
+	ast.Inspect(decl, visit)
+	return !found
+}
+
+// contextInScope returns the name of the innermost variable of type
+// context.Context that is in scope at pos in pgf, if any.
+func contextInScope(pkg *cache.Package, pgf *parsego.File, pos token.Pos) string {
+	info := pkg.TypesInfo()
+	innermost := info.Scopes[pgf.File].Innermost(pos)
+	for scope := innermost; scope != nil && scope != pkg.Types().Scope(); scope = scope.Parent() {
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			if v, ok := obj.(*types.Var); ok && name != "_" && v.Pos() < pos &&
+				analysisinternal.IsTypeNamed(v.Type(), "context", "Context") {
+				if _, obj2 := innermost.LookupParent(name, pos); obj2 == obj {
+					return name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// A contextFunc is a function that gains a context.Context parameter.
+type contextFunc struct {
+	pkg  *cache.Package
+	pgf  *parsego.File
+	decl *ast.FuncDecl
+	fn   *types.Func
+}
+
+// A contextAdder computes the changes that add a context.Context
+// parameter to a set of functions.
+type contextAdder struct {
+	*workspaceChange
+	pkgs     []*cache.Package // packages that may refer to the functions
+	callers  bool             // whether to add the parameter to callers without a context
+	funcs    []*contextFunc   // the functions that gain the parameter
+	excluded []*types.Func    // callers that cannot gain the parameter
+}
+
+// find returns the element of funcs that denotes fn, if any.
+func find(funcs []*contextFunc, fn types.Object) *contextFunc {
+	for _, f := range funcs {
+		if isSameFunc(fn, f.fn) {
+			return f
+		}
+	}
+	return nil
+}
+
+// AddContextParam adds a parameter ctx of type context.Context to the
+// selected function, and passes a context to it at each call: a
+// context.Context variable in scope at the call, if any, or else
+// context.Background(). If callers is set, the parameter is instead
+// added, recursively, to each calling function that has no context in
+// scope, where possible.
+func AddContextParam(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location, callers bool) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	decl, fn := addContextParamAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, fmt.Errorf("cannot add a context.Context parameter to the selected function")
+	}
+	pkgs, err := typeCheckReverseDependencies(ctx, snapshot, pgf.URI, callers)
+	if err != nil {
+		return nil, err
+	}
+	root := &contextFunc{pkg, pgf, decl, fn}
+
+	// Find the set of functions that gain the parameter. A caller that
+	// cannot gain it is excluded, and the set recomputed without it.
+	var a *contextAdder
+	var excluded []*types.Func
+	for {
+		a = &contextAdder{
+			workspaceChange: newWorkspaceChange(),
+			pkgs:            pkgs,
+			callers:         callers,
+			funcs:           []*contextFunc{root},
+			excluded:        excluded,
+		}
+		n := len(excluded)
+		for i := 0; i < len(a.funcs); i++ {
+			f := a.funcs[i]
+			conflicts := len(a.conflicts)
+			if err := a.addParam(f); err != nil {
+				return nil, err
+			}
+			if len(a.conflicts) > conflicts && f != root {
+				excluded = append(excluded, f.fn)
+			}
+		}
+		if len(excluded) == n {
+			break
+		}
+	}
+	if err := a.conflictError("cannot add a context.Context parameter to %s", fn.Name()); err != nil {
+		return nil, err
+	}
+	return a.documentChanges(ctx, snapshot)
+}
+
+// addParam adds the parameter to the declaration of f, and an argument
+// to each call of f, adding callers to a.funcs as needed.
+func (a *contextAdder) addParam(f *contextFunc) error {
+	prefix, err := a.importContext(f.pkg, f.pgf, f.decl.Pos())
+	if err != nil {
+		return err
+	}
+	params := f.decl.Type.Params
+	text := "ctx " + prefix + "Context"
+	if len(params.List) > 0 {
+		if len(params.List[0].Names) == 0 {
+			text = prefix + "Context" // parameters are unnamed
+		}
+		text += ", "
+	}
+	if err := a.addEdit(f.pgf, params.Opening+1, params.Opening+1, text); err != nil {
+		return err
+	}
+
+	for _, pkg := range a.pkgs {
+		info := pkg.TypesInfo()
+		for _, pgf := range pkg.CompiledGoFiles() {
+			for cur := range pgf.Cursor.Preorder((*ast.Ident)(nil)) {
+				id := cur.Node().(*ast.Ident)
+				if obj := info.Uses[id]; obj == nil || !isSameFunc(obj, f.fn) {
+					continue
+				}
+				call := calleeCall(cur)
+				if call == nil {
+					a.addConflict(pkg, id.Pos(), "reference to %s is not a call", f.fn.Name())
+					continue
+				}
+				if len(call.Args) == 1 {
+					if _, ok := info.TypeOf(call.Args[0]).(*types.Tuple); ok {
+						a.addConflict(pkg, call.Pos(), "call to %s has a multi-valued argument", f.fn.Name())
+						continue
+					}
+				}
+				arg, err := a.contextArg(pkg, pgf, cur)
+				if err != nil {
+					return err
+				}
+				pos := call.Lparen + 1
+				if sel, ok := cur.Parent().Node().(*ast.SelectorExpr); ok &&
+					info.Selections[sel] != nil && info.Selections[sel].Kind() == types.MethodExpr {
+					// In a call T.F(x, ...), the context follows the receiver.
+					pos = call.Args[0].End()
+					arg = ", " + arg
+				} else if len(call.Args) > 0 {
+					arg += ", "
+				}
+				if err := a.addEdit(pgf, pos, pos, arg); err != nil {
+					return err
+				}
+			}
+
+			// A method with a new parameter no longer implements
+			// interfaces that it used to.
+			if f.decl.Recv != nil {
+				forEachInterfaceConversion(info, pgf.File, func(e ast.Expr, iface types.Type) {
+					t := info.TypeOf(e)
+					if t == nil || types.IsInterface(t) {
+						return
+					}
+					if obj, _, _ := types.LookupFieldOrMethod(iface, false, f.fn.Pkg(), f.fn.Name()); obj == nil {
+						return
+					}
+					if obj, _, _ := types.LookupFieldOrMethod(t, false, f.fn.Pkg(), f.fn.Name()); obj != nil && isSameFunc(obj, f.fn) {
+						a.addConflict(pkg, e.Pos(), "value of type %s would no longer implement %s",
+							types.TypeString(t, types.RelativeTo(pkg.Types())), types.TypeString(iface, types.RelativeTo(pkg.Types())))
+					}
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// calleeCall returns the call whose callee is the function or method
+// denoted by the identifier at cur, possibly qualified or
+// instantiated, or nil if the identifier is not called.
+func calleeCall(cur cursor.Cursor) *ast.CallExpr {
+	for {
+		ek, _ := cur.Edge()
+		switch ek {
+		case edge.SelectorExpr_Sel, edge.IndexExpr_X, edge.IndexListExpr_X, edge.ParenExpr_X:
+			cur = cur.Parent()
+			continue
+		case edge.CallExpr_Fun:
+			return cur.Parent().Node().(*ast.CallExpr)
+		}
+		return nil
+	}
+}
+
+// contextArg returns the context argument for a call at cur in pgf: a
+// context.Context variable in scope, if any; ctx, if the enclosing
+// function gains the parameter too; or else context.Background().
+func (a *contextAdder) contextArg(pkg *cache.Package, pgf *parsego.File, cur cursor.Cursor) (string, error) {
+	pos := cur.Node().Pos()
+	if name := contextInScope(pkg, pgf, pos); name != "" {
+		return name, nil
+	}
+	for curDecl := range cur.Ancestors((*ast.FuncDecl)(nil)) {
+		decl := curDecl.Node().(*ast.FuncDecl)
+		if fn, ok := pkg.TypesInfo().Defs[decl.Name].(*types.Func); ok {
+			if find(a.funcs, fn) != nil {
+				return "ctx", nil
+			}
+			if a.callers &&
+				!slices.ContainsFunc(a.excluded, func(ex *types.Func) bool { return isSameFunc(fn, ex) }) &&
+				canAddContextParam(pkg.TypesInfo(), pgf, decl, fn) {
+				a.funcs = append(a.funcs, &contextFunc{pkg, pgf, decl, fn})
+				return "ctx", nil
+			}
+		}
+		break
+	}
+	prefix, err := a.importContext(pkg, pgf, pos)
+	if err != nil {
+		return "", err
+	}
+	return prefix + "Background()", nil
+}
+
+// importContext returns the prefix that qualifies a reference at pos
+// in pgf to a member of the context package, adding an import if
+// needed.
+func (a *contextAdder) importContext(pkg *cache.Package, pgf *parsego.File, pos token.Pos) (string, error) {
+	_, prefix, edits := analysisinternal.AddImport(pkg.TypesInfo(), pgf.File, "context", "context", "Context", pos)
+	for _, edit := range edits {
+		if err := a.addEdit(pgf, edit.Pos, edit.End, string(edit.NewText)); err != nil {
+			return "", err
+		}
+	}
+	return prefix, nil
+}
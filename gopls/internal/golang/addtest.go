@@ -12,24 +12,37 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
-	"go/format"
+	"go/build/constraint"
 	"go/token"
 	"go/types"
+	"iter"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
-	"text/template"
-	"unicode"
+	"sync"
 
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
 	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/constructors"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
+	"golang.org/x/tools/gopls/internal/golang/completion/snippet"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/settings"
 	goplsastutil "golang.org/x/tools/gopls/internal/util/astutil"
 	"golang.org/x/tools/gopls/internal/util/moremaps"
-	"golang.org/x/tools/internal/imports"
+	"golang.org/x/tools/gopls/internal/util/typesutil"
+	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/typesinternal"
 )
 
@@ -63,34 +76,63 @@ func {{.TestFuncName}}(t *{{.TestingPackageName}}.T) {
 		{{- end}}
 		{{- end}}
 
-		{{- range $index, $res := .Func.Results}}
-		{{- if eq $res.Name "gotErr"}}
-		wantErr bool
-		{{- else if eq $index 0}}
-		want {{$res.Type}}
-		{{- else}}
-		want{{add $index 1}} {{$res.Type}}
-		{{- end}}
+		{{- range .Wants}}
+		{{.Name}} {{.Type}}{{with .Comment}} // {{.}}{{end}}
 		{{- end}}
 	}{
+		{{- range .Cases}}
+		{{.}}
+		{{- end}}
 		// TODO: Add test cases.
 	}
 
 	{{- /* Loop over all the test cases. */}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *{{.TestingPackageName}}.T) {
+			{{- /* Preparation of input parameters. */}}
+			{{- range .Setup}}
+			{{.}}
+			{{- end}}
+
 			{{- /* Constructor or empty initialization. */}}
 			{{- if .Receiver}}
-			{{- if .Receiver.Constructor}}
+			{{- if .Receiver.Helper}}
+			{{- /* Receiver variable by calling the test helper. */}}
+			{{.Receiver.Var.Name}} := {{.Receiver.Helper}}(t)
+			{{- else if .Receiver.Constructor}}
+			{{- with .Receiver.Factory}}
+			{{- /* Factory variable by calling its constructor, or zero value. */}}
+			{{- if .Constructor}}
+			{{fieldNames .Constructor.Results ""}} := {{if $.PackageName}}{{$.PackageName}}.{{end}}
+			{{- .Constructor.Name -}}
+			(
+				{{- range $index, $arg := .Constructor.Args}}
+				{{- if ne $index 0}}, {{end}}
+				{{- .Value}}
+				{{- end -}}
+			)
+			{{- $last := last .Constructor.Results}}
+			{{- if eq $last.Type "error"}}
+			if err != nil {
+				t.Fatalf("could not construct factory: %v", err)
+			}
+			{{- end}}
+			{{- if .Cleanup}}
+			{{.Cleanup}}
+			{{- end}}
+			{{- else}}
+			var {{.Var.Name}} {{.Var.Type}}
+			{{- end}}
+			{{- end}}
 			{{- /* Receiver variable by calling constructor. */}}
-			{{fieldNames .Receiver.Constructor.Results ""}} := {{if .PackageName}}{{.PackageName}}.{{end}}
+			{{fieldNames .Receiver.Constructor.Results ""}} := {{if .Receiver.Factory}}{{.Receiver.Factory.Var.Name}}.{{else if .PackageName}}{{.PackageName}}.{{end}}
 			{{- .Receiver.Constructor.Name}}
 
 			{{- /* Constructor input parameters. */ -}}
 			(
 				{{- range $index, $arg := .Receiver.Constructor.Args}}
 				{{- if ne $index 0}}, {{end}}
-				{{- if .Name}}tt.{{.Name}}{{else}}{{.Value}}{{end}}
+				{{- if .Value}}{{.Value}}{{else}}tt.{{.Name}}{{if .Variadic}}...{{end}}{{end}}
 				{{- end -}}
 			)
 
@@ -101,6 +143,9 @@ func {{.TestFuncName}}(t *{{.TestingPackageName}}.T) {
 				t.Fatalf("could not construct receiver type: %v", err)
 			}
 			{{- end}}
+			{{- if .Receiver.Cleanup}}
+			{{.Receiver.Cleanup}}
+			{{- end}}
 			{{- else}}
 			{{- /* Receiver variable declaration. */}}
 			// TODO: construct the receiver type.
@@ -108,48 +153,58 @@ func {{.TestFuncName}}(t *{{.TestingPackageName}}.T) {
 			{{- end}}
 			{{- end}}
 
+			{{- /* Subtest for each form of the receiver. */}}
+			{{- if and .Receiver .Receiver.Forms}}
+			for _, form := range []struct {
+				name   string
+				method {{.Receiver.FormType}}
+			}{
+				{{- range .Receiver.Forms}}
+				{ {{- printf "%q" .Name}}, {{.Method}}},
+				{{- end}}
+			} {
+				t.Run(form.name, func(t *{{$.TestingPackageName}}.T) {
+			{{- end}}
+
 			{{- /* Got variables. */}}
 			{{if .Func.Results}}{{fieldNames .Func.Results ""}} := {{end}}
 
 			{{- /* Call expression. */}}
+			{{- if and .Receiver .Receiver.Forms}}{{/* Call method by form.method. */}}
+			{{- "form.method"}}
+			{{- else}}
 			{{- if .Receiver}}{{/* Call method by VAR.METHOD. */}}
 			{{- .Receiver.Var.Name}}.
 			{{- else if .PackageName}}{{/* Call function by PACKAGE.FUNC. */}}
 			{{- .PackageName}}.
 			{{- end}}{{.Func.Name}}
+			{{- end}}
 
 			{{- /* Input parameters. */ -}}
 			(
 				{{- range $index, $arg := .Func.Args}}
 				{{- if ne $index 0}}, {{end}}
-				{{- if .Name}}tt.{{.Name}}{{else}}{{.Value}}{{end}}
+				{{- if .Value}}{{.Value}}{{else}}tt.{{.Name}}{{if .Variadic}}...{{end}}{{end}}
 				{{- end -}}
 			)
 
 			{{- /* Handles the returned error before the rest of return value. */}}
-			{{- $last := last .Func.Results}}
-			{{- if eq $last.Type "error"}}
-			if gotErr != nil {
-				if !tt.wantErr {
-					t.Errorf("{{$.Func.Name}}() failed: %v", gotErr)
-				}
-				return
-			}
-			if tt.wantErr {
-				t.Fatal("{{$.Func.Name}}() succeeded unexpectedly")
-			}
+			{{- if .CheckErr}}
+			{{.CheckErr}}
 			{{- end}}
 
-			{{- /* Compare the returned values except for the last returned error. */}}
-			{{- if or (and .Func.Results (ne $last.Type "error")) (and (gt (len .Func.Results) 1) (eq $last.Type "error"))}}
-			// TODO: update the condition below to compare got with tt.want.
-			{{- range $index, $res := .Func.Results}}
-			{{- if ne $res.Name "gotErr"}}
-			if true {
-				t.Errorf("{{$.Func.Name}}() = %v, want %v", {{.Name}}, tt.{{if eq $index 0}}want{{else}}want{{add $index 1}}{{end}})
-			}
+			{{- /* Collects the values of the returned iterators. */}}
+			{{- range .Collect}}
+			{{.}}
 			{{- end}}
+
+			{{- /* Compare the returned values except for the last returned error. */}}
+			{{- if .CheckResults}}
+			{{.CheckResults}}
 			{{- end}}
+			{{- if and .Receiver .Receiver.Forms}}
+				})
+			}
 			{{- end}}
 		})
 	}
@@ -159,9 +214,26 @@ func {{.TestFuncName}}(t *{{.TestingPackageName}}.T) {
 // Name is the name of the field this input parameter should reference.
 // Value is the expression this input parameter should accept.
 //
-// Exactly one of Name or Value must be set.
+// Exactly one of Name or Value must be set, unless the test derives
+// the argument from the field, in which case Value is the variable
+// that holds it (see [mapFSStmt] and [sqlMockStmt]).
+//
+// Default, if set, is the expression the field Name takes when a test
+// case leaves it equal to Unset, its zero value (see [tempDefault]).
+//
+// Variadic reports whether the field holds the slice of the arguments
+// of a variadic parameter, which the call passes with "...".
+//
+// Comment, if set, follows the field in the test case struct.
 type field struct {
 	Name, Type, Value string
+	Default, Unset    string
+	Variadic          bool
+	Comment           string
+
+	mapFS   bool // field Name holds the files of the fstest.MapFS Value
+	sqlMock bool // field Name holds the expectations of the mock *sql.DB Value
+	server  bool // the argument refers to the test HTTP server (see [httpDefault])
 }
 
 type function struct {
@@ -176,6 +248,82 @@ type receiver struct {
 	// Constructor holds information about the constructor for the receiver type.
 	// If no qualified constructor is found, this field will be nil.
 	Constructor *function
+	// Factory is the value whose method Constructor is, if it is a
+	// factory method (see [constructors.Index.Factories]).
+	Factory *factory
+	// Helper is the name of the function of the test file that
+	// constructs the receiver, if any, in which case Constructor is nil.
+	Helper string
+	// Cleanup is the statement that releases the resources of the
+	// receiver returned by Constructor, if it has a Close method or
+	// similar (see [cleanupStmt]).
+	Cleanup string
+	// Forms holds the forms of the receiver through which the test
+	// calls the method, each in a subtest, if the test exercises
+	// several (see [TestOptions]); FormType is the type of their
+	// method values.
+	Forms    []receiverForm
+	FormType string
+}
+
+// A factory is the value whose method constructs the receiver of a
+// test: the variable Var that holds it, which Constructor, its own
+// constructor, if any, creates with default arguments, or which is
+// otherwise the zero value of its type, and the statement that
+// releases its resources (see [cleanupStmt]).
+type factory struct {
+	Var         field
+	Constructor *function
+	Cleanup     string
+}
+
+// A receiverForm is a form of the receiver through which a test calls
+// a method: Name describes the form, such as *T, and Method is the
+// method value bound to the receiver in that form, such as (&v).M.
+type receiverForm struct {
+	Name, Method string
+}
+
+// TestOptions selects optional forms of the tests generated by
+// [TestFuncSource].
+type TestOptions struct {
+	// ReceiverForms causes the test of a method with a value receiver
+	// of type T to call it in a subtest for each of T, *T, and the
+	// interfaces of its package that T implements and that have the
+	// method, so as to catch bugs in which the method depends on the
+	// form of its receiver, such as the mutation of a copy. The test of
+	// a method with a pointer receiver calls it through *T and the
+	// interfaces that *T implements.
+	ReceiverForms bool
+
+	// FieldAssertions causes the test to compare each field of a
+	// result whose type is a struct of the package under test with
+	// a field of the test case, such as wantName, rather than the
+	// whole result with a single want field.
+	FieldAssertions bool
+
+	// CallSites holds the arguments of the calls of the function in
+	// the workspace (see [callSiteArgs]), with which the table of
+	// test cases is pre-populated, so that the user starts from real
+	// inputs rather than an empty table. [AddTestForFunc] finds them
+	// if it is nil.
+	CallSites [][]string
+
+	// ConstantResult is the expression that the function returns, if
+	// it may compute a constant from its arguments (see
+	// [constantResult]), in which case the test cases derived from
+	// CallSites want its value for their arguments, if it has one for
+	// all of them. [AddTestForFunc] sets it if it is nil.
+	ConstantResult ast.Expr
+
+	// Comments selects the comments of the test (see
+	// [TestOptions.adjustComments]); the zero value is
+	// [settings.TestCommentsFull].
+	Comments settings.TestComments
+
+	// TodoMarker replaces "TODO:" in the comments that mark the sites
+	// the user must complete, if set.
+	TodoMarker string
 }
 
 type testInfo struct {
@@ -183,7 +331,9 @@ type testInfo struct {
 	// package "testing"
 	TestingPackageName string
 	// PackageName is the package name the target function/method is delcared from.
-	PackageName  string
+	PackageName string
+	// TestFuncName is the name of the generated function, as chosen by
+	// its generator (see [generator]).
 	TestFuncName string
 	// Func holds information about the function or method being tested.
 	Func function
@@ -191,103 +341,197 @@ type testInfo struct {
 	// being tested.
 	// This field is nil for functions and non-nil for methods.
 	Receiver *receiver
+	// Setup holds the statements that prepare the input parameters of
+	// the function and of the receiver constructor from the test case,
+	// such as those that provide default values.
+	Setup []string
+	// Cases holds the test cases that pre-populate the table, each a
+	// composite literal derived from a call of the function (see
+	// [callSiteCases]).
+	Cases []string
+	// Wants holds the fields of the test case that hold the wanted
+	// results of the function: wantErr, want, want2, and so on, or,
+	// for the fields of a struct result, wantName, want2Name, and so
+	// on (see [TestOptions]).
+	Wants []field
+	// Collect holds the statements that collect the values of the
+	// iterators returned by the function, such as
+	// got := slices.Collect(gotSeq), so that the test compares them
+	// rather than the functions (see [collectIter]).
+	Collect []string
+	// CheckErr and CheckResults are the statements that check the
+	// error result, if any, and the other results of the function,
+	// rendered in the assertion style of the test file.
+	CheckErr, CheckResults string
 }
 
-var testTmpl = template.Must(template.New("test").Funcs(template.FuncMap{
-	"add": func(a, b int) int { return a + b },
-	"last": func(slice []field) field {
-		if len(slice) == 0 {
-			return field{}
-		}
-		return slice[len(slice)-1]
-	},
-	"fieldNames": func(fields []field, qualifier string) (res string) {
-		var names []string
-		for _, f := range fields {
-			names = append(names, qualifier+f.Name)
-		}
-		return strings.Join(names, ", ")
-	},
-}).Parse(testTmplString))
-
-// AddTestForFunc adds a test for the function enclosing the given input range.
+// AddTestForFunc adds a test for the function enclosing the given input range,
+// or, if the range is within a type declaration, a test for each method
+// of the type (see [addMethodTests]).
 // It creates a _test.go file if one does not already exist.
-func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) (changes []protocol.DocumentChange, _ error) {
-	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+// If integration is set, it adds an integration test of the function
+// instead (see [addTests]). The opts select optional forms of the
+// test (see [TestOptions]).
+//
+// A test depends only on the signature of the function and on the
+// package-level declarations of its package, so AddTestForFunc uses
+// the types of the package as seen by its importers (see
+// [cache.Snapshot.ImportPackage]), which are much cheaper to obtain
+// than those of a complete type-check in a large package. It falls
+// back to type-checking the package if they lack the function.
+func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location, integration bool, opts TestOptions) ([]protocol.DocumentChange, error) {
+	mp, err := NarrowestMetadataForFile(ctx, snapshot, loc.URI)
 	if err != nil {
 		return nil, err
 	}
-
-	if metadata.IsCommandLineArguments(pkg.Metadata().ID) {
-		return nil, fmt.Errorf("current file in command-line-arguments package")
+	fh, err := snapshot.ReadFile(ctx, loc.URI)
+	if err != nil {
+		return nil, err
 	}
-
-	if errors := pkg.ParseErrors(); len(errors) > 0 {
-		return nil, fmt.Errorf("package has parse errors: %v", errors[0])
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
 	}
-	if errors := pkg.TypeErrors(); len(errors) > 0 {
-		return nil, fmt.Errorf("package has type errors: %v", errors[0])
+	if pgf.ParseErr != nil {
+		return nil, fmt.Errorf("file has parse errors: %v", pgf.ParseErr)
+	}
+	decl, err := enclosingFuncDecl(pgf, loc.Range)
+	if err != nil {
+		if spec := enclosingTypeSpec(pgf, loc.Range); spec != nil && !integration {
+			return addMethodTests(ctx, snapshot, loc.URI, spec.Name.Name)
+		}
+		return nil, err
 	}
 
-	// All three maps map the path of an imported package to
-	// the local name if explicit or "" otherwise.
-	var (
-		fileImports  map[string]string         // imports in foo.go file
-		testImports  map[string]string         // imports in foo_test.go file
-		extraImports = make(map[string]string) // imports to add to test file
-	)
+	var tp testedPackage
+	if !metadata.IsCommandLineArguments(mp.ID) {
+		tpkg, err := snapshot.ImportPackage(ctx, mp.ID)
+		if err != nil {
+			return nil, err
+		}
+		tp = testedPackage{mp: mp, types: tpkg}
+	}
+	if tp.types == nil || tp.funcOf(decl) == nil {
+		// The function may be unexported, and thus absent from export
+		// data. An ad hoc package is always type-checked.
+		pkg, fullPGF, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+		if err != nil {
+			return nil, err
+		}
+		if tp, err = checkedPackageOf(ctx, snapshot, pkg, loc.URI); err != nil {
+			return nil, err
+		}
+		if decl, err = enclosingFuncDecl(fullPGF, loc.Range); err != nil {
+			return nil, err
+		}
+		pgf = fullPGF
+	}
 
-	var collectImports = func(file *ast.File) (map[string]string, error) {
-		imps := make(map[string]string)
-		for _, spec := range file.Imports {
-			// TODO(hxjiang): support dot imports.
-			if spec.Name != nil && spec.Name.Name == "." {
-				return nil, fmt.Errorf("\"add test for func\" does not support files containing dot imports")
-			}
-			path, err := strconv.Unquote(spec.Path.Value)
-			if err != nil {
-				return nil, err
-			}
-			if spec.Name != nil {
-				if spec.Name.Name == "_" {
-					continue
-				}
-				imps[path] = spec.Name.Name
-			} else {
-				imps[path] = ""
-			}
+	// Pre-populate the test cases with the literal arguments of the
+	// calls of the function, if any.
+	if opts.CallSites == nil {
+		calls, err := callSiteArgs(ctx, snapshot, fh, pgf, decl)
+		if err != nil {
+			event.Error(ctx, "finding the calls of "+decl.Name.Name, err)
 		}
-		return imps, nil
+		opts.CallSites = calls
+	}
+	if opts.ConstantResult == nil {
+		opts.ConstantResult = constantResult(decl)
 	}
 
-	// Collect all the imports from the x.go, keep track of the local package name.
-	if fileImports, err = collectImports(pgf.File); err != nil {
+	changes, _, err := addTests(ctx, snapshot, tp, []*parsego.File{pgf}, []*ast.FuncDecl{decl}, false, false, integration, opts)
+	if err != nil {
 		return nil, err
 	}
+	if snapshot.Options().SnippetEditSupported && len(changes) > 0 {
+		// The test is inserted by the last edit of the last change.
+		edits := changes[len(changes)-1].TextDocumentEdit.Edits
+		if test, ok := edits[len(edits)-1].Value.(protocol.TextEdit); ok {
+			edits[len(edits)-1].Value = testSnippetEdit(test)
+		}
+	}
+	return changes, nil
+}
 
-	testBase := strings.TrimSuffix(filepath.Base(loc.URI.Path()), ".go") + "_test.go"
-	goTestFileURI := protocol.URIFromPath(filepath.Join(loc.URI.DirPath(), testBase))
-
-	testFH, err := snapshot.ReadFile(ctx, goTestFileURI)
+// AddedTestDebugConfig returns the configuration of a debug session of
+// the test that [AddTestForFunc] adds for the function declared at loc,
+// for the AddTest command with Debug.
+func AddedTestDebugConfig(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location, integration bool) (*command.DebugTestResult, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
 	if err != nil {
 		return nil, err
 	}
+	decl, err := enclosingFuncDecl(pgf, loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	tp, err := checkedPackageOf(ctx, snapshot, pkg, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	name, err := testName(tp.funcOf(decl))
+	if err != nil {
+		return nil, err
+	}
+	// The test may belong to a package of another directory of the
+	// module (see [testFileURI]).
+	pkgPath, dir := tp.mp.PkgPath, loc.URI.DirPath()
+	testURI, err := testFileURI(snapshot, tp, pgf, integration)
+	if err != nil {
+		return nil, err
+	}
+	if testDir := testURI.DirPath(); testDir != dir && tp.mp.Module != nil {
+		rel, err := filepath.Rel(tp.mp.Module.Dir, testDir)
+		if err != nil {
+			return nil, err
+		}
+		pkgPath, dir = PackagePath(path.Join(tp.mp.Module.Path, filepath.ToSlash(rel))), testDir
+	}
+	config := debugTestConfig(snapshot, pkgPath, dir, name, integration)
+	return &config, nil
+}
 
-	// TODO(hxjiang): use a fresh name if the same test function name already
-	// exist.
-
+// todoSiteRe matches the sites of a generated test that the user must
+// complete: the test cases, the construction of the receiver, and the
+// condition of each comparison of a result with the wanted one. The
+// comments that mark the first two may begin with any marker (see
+// [TestOptions]).
+var todoSiteRe = regexp.MustCompile(`// [^\n]*(?:Add test cases|construct the receiver type)\.|if (true) \{`)
+
+// testSnippetEdit returns a snippet edit equivalent to the edit that
+// inserts a generated test, with a placeholder at each site that the
+// user must complete, so that the user can tab through them.
+func testSnippetEdit(edit protocol.TextEdit) protocol.SnippetTextEdit {
 	var (
-		eofRange protocol.Range // empty selection at end of new file
-		// edits contains all the text edits to be applied to the test file.
-		edits []protocol.TextEdit
-		// xtest indicates whether the test file use package x or x_test.
-		// TODO(hxjiang): We can discuss the option to interpret the user's
-		// intention by which function they are selecting. Have one file for
-		// x_test package testing, one file for x package testing.
-		xtest = true
+		b    snippet.Builder
+		text = edit.NewText
+		pos  = 0 // end of the text written so far
 	)
+	for _, m := range todoSiteRe.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[0], m[1]
+		if m[2] >= 0 { // the condition of a comparison
+			start, end = m[2], m[3]
+		}
+		b.WriteText(text[pos:start])
+		b.WritePlaceholder(func(b *snippet.Builder) {
+			b.WriteText(text[start:end])
+		})
+		pos = end
+	}
+	b.WriteText(text[pos:])
+	return protocol.SnippetTextEdit{
+		Range:   edit.Range,
+		Snippet: protocol.StringValue{Kind: "snippet", Value: b.String()},
+	}
+}
 
-	start, end, err := pgf.RangePos(loc.Range)
+// enclosingFuncDecl returns the function declaration of the file
+// enclosing the specified range, or the declaration of a package-level
+// variable initialized by a function literal, as a function
+// declaration (see [funcVarDecl]).
+func enclosingFuncDecl(pgf *parsego.File, rng protocol.Range) (*ast.FuncDecl, error) {
+	start, end, err := pgf.RangePos(rng)
 	if err != nil {
 		return nil, err
 	}
@@ -299,54 +543,697 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 
 	decl, ok := path[len(path)-2].(*ast.FuncDecl)
 	if !ok {
+		if decl := funcVarDecl(path, start); decl != nil {
+			return decl, nil
+		}
 		return nil, fmt.Errorf("no enclosing function")
 	}
+	return decl, nil
+}
 
-	fn := pkg.TypesInfo().Defs[decl.Name].(*types.Func)
-	sig := fn.Signature()
+// funcVarDecl returns the declaration of the package-level variable,
+// initialized by a function literal, that encloses pos, given the path
+// to pos, as a function declaration that has the name of the variable
+// and the type and body of the literal, such as
+//
+//	func Handler(w http.ResponseWriter, r *http.Request) {...}
+//
+// for var Handler = func(w http.ResponseWriter, r *http.Request) {...}.
+// The variable is the subject of the tests added for the declaration,
+// which call it as they would a function (see [funcVarObj]). It
+// returns nil if there is no such variable.
+func funcVarDecl(path []ast.Node, pos token.Pos) *ast.FuncDecl {
+	if len(path) < 3 {
+		return nil
+	}
+	gen, ok := path[len(path)-2].(*ast.GenDecl)
+	if !ok || gen.Tok != token.VAR {
+		return nil
+	}
+	spec, ok := path[len(path)-3].(*ast.ValueSpec)
+	if !ok || len(spec.Names) != len(spec.Values) {
+		return nil
+	}
+	within := func(n ast.Node) bool { return n.Pos() <= pos && pos <= n.End() }
+	for i, name := range spec.Names {
+		lit, ok := spec.Values[i].(*ast.FuncLit)
+		if ok && (len(spec.Names) == 1 || within(name) || within(lit)) {
+			return &ast.FuncDecl{Name: name, Type: lit.Type, Body: lit.Body}
+		}
+	}
+	return nil
+}
 
-	testPGF, err := snapshot.ParseGo(ctx, testFH, parsego.Header)
+// enclosingTypeSpec returns the declaration of the non-generic,
+// non-interface type of the file enclosing the specified range that
+// has methods declared in the file, or nil if there is none.
+func enclosingTypeSpec(pgf *parsego.File, rng protocol.Range) *ast.TypeSpec {
+	start, end, err := pgf.RangePos(rng)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	if len(path) < 3 {
+		return nil
+	}
+	spec, ok := path[len(path)-3].(*ast.TypeSpec)
+	if !ok || spec.TypeParams != nil || spec.Assign.IsValid() || is[*ast.InterfaceType](spec.Type) {
+		return nil
+	}
+	for _, decl := range pgf.File.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv != nil {
+			if _, recv, _ := goplsastutil.UnpackRecv(decl.Recv.List[0].Type); recv != nil && recv.Name == spec.Name.Name {
+				return spec
+			}
+		}
+	}
+	return nil
+}
+
+// addMethodTests adds a test for each method of the named type that is
+// declared in the specified file and has no test, to the corresponding
+// _test.go file. The tests construct the receiver by calling a helper
+// of the test file, such as newTestT(t *testing.T) *T, which is added
+// to the file if it does not declare one already, so that they do not
+// each repeat the construction.
+func addMethodTests(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI, typeName string) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, uri)
+	if err != nil {
+		return nil, err
+	}
+	tp, err := checkedPackageOf(ctx, snapshot, pkg, uri)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := snapshot.TestRelation(ctx, tp.mp.PkgPath)
+	if err != nil {
+		return nil, err
+	}
+	var decls []*ast.FuncDecl
+	for _, decl := range pgf.File.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv != nil {
+			name, ok := testableName(pgf.File, decl)
+			if ok && strings.HasPrefix(name, typeName+".") && len(testsOf(rel, name)) == 0 {
+				decls = append(decls, decl)
+			}
+		}
+	}
+	if len(decls) == 0 {
+		return nil, fmt.Errorf("all methods of %s already have tests", typeName)
+	}
+	changes, added, err := addTests(ctx, snapshot, tp, []*parsego.File{pgf}, decls, true, true, false, TestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if added == 0 {
+		return nil, fmt.Errorf("cannot add tests for the methods of %s", typeName)
+	}
+	return changes, nil
+}
+
+// A testedPackage holds the type information of a package under test
+// needed to add tests of its functions.
+type testedPackage struct {
+	mp    *metadata.Package
+	types *types.Package
+	info  *types.Info // nil if types was imported rather than type-checked
+
+	// shared, if set, memoizes the information about the test package
+	// that does not depend on the test file, for commands that add
+	// tests to many files of the package (see [packageBatch]).
+	shared *sharedTestInfo
+}
+
+// sharedTestInfo memoizes the helpers of the test packages, and the
+// optional packages of the module, of a package under test, and
+// records the imports of the tests added to it.
+type sharedTestInfo struct {
+	mu      sync.Mutex
+	helpers map[bool]TestHelpers // by xtest
+	inputs  TestInputs           // nil until computed
+	imports map[string]bool      // packages that added tests import anew
+}
+
+// addImports records the paths of the packages that the tests added to
+// a file import anew.
+func (shared *sharedTestInfo) addImports(paths iter.Seq[string]) {
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+	if shared.imports == nil {
+		shared.imports = make(map[string]bool)
+	}
+	for path := range paths {
+		shared.imports[path] = true
+	}
+}
+
+// testHelpers returns the helpers of the test package of tp (see
+// [testPackageHelpers]), which the caller may modify.
+func (tp testedPackage) testHelpers(ctx context.Context, snapshot *cache.Snapshot, xtest bool) (TestHelpers, error) {
+	if tp.shared == nil {
+		return testPackageHelpers(ctx, snapshot, tp.mp, xtest)
+	}
+	tp.shared.mu.Lock()
+	defer tp.shared.mu.Unlock()
+	helpers, ok := tp.shared.helpers[xtest]
+	if !ok {
+		var err error
+		helpers, err = testPackageHelpers(ctx, snapshot, tp.mp, xtest)
+		if err != nil {
 			return nil, err
 		}
-		changes = append(changes, protocol.DocumentChangeCreate(goTestFileURI))
+		if tp.shared.helpers == nil {
+			tp.shared.helpers = make(map[bool]TestHelpers)
+		}
+		tp.shared.helpers[xtest] = helpers
+	}
+	return maps.Clone(helpers), nil
+}
 
-		// header is the buffer containing the text to add to the beginning of the file.
-		var header bytes.Buffer
+// testInputs returns the optional packages that the module of tp
+// requires (see [moduleTestInputs]).
+func (tp testedPackage) testInputs(ctx context.Context, snapshot *cache.Snapshot) (TestInputs, error) {
+	if tp.shared == nil {
+		return moduleTestInputs(ctx, snapshot, tp.mp)
+	}
+	tp.shared.mu.Lock()
+	defer tp.shared.mu.Unlock()
+	if tp.shared.inputs == nil {
+		inputs, err := moduleTestInputs(ctx, snapshot, tp.mp)
+		if err != nil {
+			return nil, err
+		}
+		tp.shared.inputs = inputs
+	}
+	return tp.shared.inputs, nil
+}
 
-		// If this test file was created by the gopls, add a copyright header and
-		// package decl based on the originating file.
-		// Search for something that looks like a copyright header, to replicate
-		// in the new file.
-		if c := copyrightComment(pgf.File); c != nil {
-			start, end, err := pgf.NodeOffsets(c)
-			if err != nil {
-				return nil, err
-			}
-			header.Write(pgf.Src[start:end])
-			// One empty line between copyright header and following.
-			header.WriteString("\n\n")
+// A packageBatch holds the narrowest packages of a set of files, and
+// the information about each package needed to add or update its
+// tests, so that the commands that process many files, such as
+// AddTests and UpdateTests, type-check each package, and compute its
+// test relation and the information about its test packages, once
+// rather than for each file.
+type packageBatch struct {
+	files map[protocol.DocumentURI]batchFile
+}
+
+// A batchFile is a file of a packageBatch.
+type batchFile struct {
+	pkg *batchPackage
+	pgf *parsego.File
+}
+
+// A batchPackage is a package of a packageBatch.
+type batchPackage struct {
+	pkg *cache.Package
+	rel *testfuncs.Relation
+
+	once sync.Once // guards tp and err
+	tp   testedPackage
+	err  error
+}
+
+// newPackageBatch type-checks the narrowest packages of the files
+// together, and computes the test relation of each package.
+func newPackageBatch(ctx context.Context, snapshot *cache.Snapshot, uris []protocol.DocumentURI) (*packageBatch, error) {
+	var (
+		ids     []metadata.PackageID
+		idOf    = make(map[protocol.DocumentURI]metadata.PackageID)
+		pkgByID = make(map[metadata.PackageID]*batchPackage)
+	)
+	for _, uri := range uris {
+		mp, err := NarrowestMetadataForFile(ctx, snapshot, uri)
+		if err != nil {
+			return nil, err
+		}
+		idOf[uri] = mp.ID
+		if _, ok := pkgByID[mp.ID]; !ok {
+			pkgByID[mp.ID] = nil
+			ids = append(ids, mp.ID)
+		}
+	}
+	pkgs, err := snapshot.TypeCheck(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	for i, pkg := range pkgs {
+		rel, err := snapshot.TestRelation(ctx, pkg.Metadata().PkgPath)
+		if err != nil {
+			return nil, err
+		}
+		pkgByID[ids[i]] = &batchPackage{pkg: pkg, rel: rel}
+	}
+	batch := &packageBatch{files: make(map[protocol.DocumentURI]batchFile)}
+	for _, uri := range uris {
+		bp := pkgByID[idOf[uri]]
+		pgf, err := bp.pkg.File(uri)
+		if err != nil {
+			return nil, err
 		}
+		batch.files[uri] = batchFile{bp, pgf}
+	}
+	return batch, nil
+}
 
-		// If this test file was created by gopls, add build constraints
-		// matching the non-test file.
-		if c := buildConstraintComment(pgf.File); c != nil {
-			start, end, err := pgf.NodeOffsets(c)
-			if err != nil {
-				return nil, err
-			}
-			header.Write(pgf.Src[start:end])
-			// One empty line between build constraint and following.
-			header.WriteString("\n\n")
+// testedPackage returns the testedPackage of the package, which is
+// shared by all its files; see [checkedPackage].
+func (bp *batchPackage) testedPackage() (testedPackage, error) {
+	bp.once.Do(func() {
+		bp.tp, bp.err = checkedPackage(bp.pkg)
+		if bp.err == nil {
+			bp.tp.shared = new(sharedTestInfo)
 		}
+	})
+	return bp.tp, bp.err
+}
 
-		// Determine if a new test file should use in-package test (package x)
-		// or external test (package x_test). If any of the function parameters
-		// reference an unexported object, we cannot write out test cases from
-		// an x_test package.
-		externalTestOK := func() bool {
-			if !fn.Exported() {
+// checkedPackage returns the testedPackage of a type-checked package,
+// which must be free of errors.
+func checkedPackage(pkg *cache.Package) (testedPackage, error) {
+	if errors := pkg.ParseErrors(); len(errors) > 0 {
+		return testedPackage{}, fmt.Errorf("package has parse errors: %v", errors[0])
+	}
+	if errors := pkg.TypeErrors(); len(errors) > 0 {
+		return testedPackage{}, fmt.Errorf("package has type errors: %v", errors[0])
+	}
+	return testedPackage{mp: pkg.Metadata(), types: pkg.Types(), info: pkg.TypesInfo()}, nil
+}
+
+// checkedPackageOf returns the testedPackage of a type-checked package
+// of the file uri, as does [checkedPackage], but for a file of an ad
+// hoc command-line-arguments package, such as a standalone script,
+// whose tests belong to the package of its directory (see
+// [dirPackage]), it returns the metadata of that package, so that the
+// tests import it, and are related to its other tests, by its path.
+func checkedPackageOf(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, uri protocol.DocumentURI) (testedPackage, error) {
+	tp, err := checkedPackage(pkg)
+	if err != nil {
+		return testedPackage{}, err
+	}
+	if metadata.IsCommandLineArguments(tp.mp.ID) {
+		if tp.mp, err = dirPackage(ctx, snapshot, tp.mp, uri); err != nil {
+			return testedPackage{}, err
+		}
+	}
+	return tp, nil
+}
+
+// dirPackage returns the metadata of the workspace package, other
+// than a test package, that has the same name as the ad hoc package
+// mp of the file uri and a file in its directory.
+func dirPackage(ctx context.Context, snapshot *cache.Snapshot, mp *metadata.Package, uri protocol.DocumentURI) (*metadata.Package, error) {
+	mps, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mps {
+		if m.ForTest != "" || metadata.IsCommandLineArguments(m.ID) || m.Name != mp.Name {
+			continue
+		}
+		if slices.ContainsFunc(m.CompiledGoFiles, func(f protocol.DocumentURI) bool { return f.Dir() == uri.Dir() }) {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("file in command-line-arguments package, and no package %s in its directory", mp.Name)
+}
+
+// funcOf returns the function or method declared by decl, or nil if
+// it is not known.
+//
+// The declarations of a file that uses cgo may be absent from the type
+// information, if the package was type-checked from the files that
+// cgo generated from it (see [testedPackage.checked]); the function is
+// then found by name.
+func (tp testedPackage) funcOf(decl *ast.FuncDecl) *types.Func {
+	if tp.checked(decl) {
+		obj := tp.info.Defs[decl.Name]
+		if v, ok := obj.(*types.Var); ok {
+			return funcVarObj(v, tp.info.TypeOf(decl.Type))
+		}
+		fn, _ := obj.(*types.Func)
+		return fn
+	}
+	if decl.Recv == nil {
+		// The signature of the literal of a variable is known only
+		// to a type-checked package.
+		fn, _ := tp.types.Scope().Lookup(decl.Name.Name).(*types.Func)
+		return fn
+	}
+	_, recv, _ := goplsastutil.UnpackRecv(decl.Recv.List[0].Type)
+	if recv == nil {
+		return nil
+	}
+	tname, _ := tp.types.Scope().Lookup(recv.Name).(*types.TypeName)
+	if tname == nil {
+		return nil
+	}
+	if named, ok := types.Unalias(tname.Type()).(*types.Named); ok {
+		for m := range named.Methods() {
+			if m.Name() == decl.Name.Name {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// refsUnexported reports whether the signature of the function
+// declared by decl refers to an unexported object of the package,
+// in which case its test cannot belong to the external test package.
+func (tp testedPackage) refsUnexported(decl *ast.FuncDecl, fn *types.Func) bool {
+	if !tp.checked(decl) {
+		// Without syntax information, inspect the types of the signature.
+		return typeRefsUnexported(fn.Signature(), tp.types, make(map[types.Type]bool))
+	}
+	refsUnexported := false
+	ast.Inspect(decl, func(n ast.Node) bool {
+		// The original function refs to an unexported object from the
+		// same package, so further inspection is unnecessary.
+		if refsUnexported {
+			return false
+		}
+		switch t := n.(type) {
+		case *ast.BlockStmt:
+			// Avoid inspect the function body.
+			return false
+		case *ast.Ident:
+			// Use test variant (package foo) if the function signature
+			// references any unexported objects (like types or
+			// constants) from the same package.
+			// Note: types.PkgName is excluded from this check as it's
+			// always defined in the same package.
+			if obj, ok := tp.info.Uses[t]; ok && !obj.Exported() && obj.Pkg() == tp.types && !is[*types.PkgName](obj) {
+				refsUnexported = true
+			}
+			return false
+		default:
+			return true
+		}
+	})
+	return refsUnexported
+}
+
+// funcVarObj returns a function of the name of the package-level
+// variable v, initialized by a function literal of type t (see
+// [funcVarDecl]), and of the signature of the literal, which a test
+// calls as it would the variable. It returns nil if v is not such a
+// variable.
+func funcVarObj(v *types.Var, t types.Type) *types.Func {
+	sig, ok := t.(*types.Signature)
+	if !ok || v.Pkg() == nil || v.Pkg().Scope().Lookup(v.Name()) != v {
+		return nil
+	}
+	return types.NewFunc(v.Pos(), v.Pkg(), v.Name(), sig)
+}
+
+// checked reports whether decl is among the declarations that the
+// type information of the package describes. It is not if the package
+// was type-checked from the files that cgo generated from the file of
+// decl, as when go/types does not support cgo, rather than from the
+// file itself.
+func (tp testedPackage) checked(decl *ast.FuncDecl) bool {
+	return tp.info != nil && tp.info.Defs[decl.Name] != nil
+}
+
+// typeRefsUnexported reports whether type t refers to an unexported
+// named type or alias of package pkg.
+func typeRefsUnexported(t types.Type, pkg *types.Package, seen map[types.Type]bool) bool {
+	return typeRefs(t, func(obj *types.TypeName) bool { return obj.Pkg() == pkg && !obj.Exported() }, seen)
+}
+
+// typeRefsCgo reports whether type t refers to a type of the "C"
+// pseudo-package of cgo, such as C.int, which cgo declares in the
+// package as _Ctype_int. A test file cannot refer to such types, as it
+// cannot import "C".
+func typeRefsCgo(t types.Type) bool {
+	return typeRefs(t, func(obj *types.TypeName) bool { return strings.HasPrefix(obj.Name(), "_Ctype_") }, make(map[types.Type]bool))
+}
+
+// typeRefs reports whether type t refers to a named type or alias
+// whose type name satisfies pred.
+func typeRefs(t types.Type, pred func(*types.TypeName) bool, seen map[types.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	refs := func(t types.Type) bool { return typeRefs(t, pred, seen) }
+	switch t := t.(type) {
+	case typesinternal.NamedOrAlias:
+		if pred(t.Obj()) {
+			return true
+		}
+		if targs := typesinternal.TypeArgs(t); targs != nil {
+			for targ := range targs.Types() {
+				if refs(targ) {
+					return true
+				}
+			}
+		}
+		if alias, ok := t.(*types.Alias); ok {
+			return refs(alias.Rhs())
+		}
+	case *types.Pointer:
+		return refs(t.Elem())
+	case *types.Slice:
+		return refs(t.Elem())
+	case *types.Array:
+		return refs(t.Elem())
+	case *types.Chan:
+		return refs(t.Elem())
+	case *types.Map:
+		return refs(t.Key()) || refs(t.Elem())
+	case *types.Signature:
+		return refs(t.Params()) || refs(t.Results())
+	case *types.Tuple:
+		for v := range t.Variables() {
+			if refs(v.Type()) {
+				return true
+			}
+		}
+	case *types.Struct:
+		for field := range t.Fields() {
+			if refs(field.Type()) {
+				return true
+			}
+		}
+	case *types.Interface:
+		for m := range t.Methods() {
+			if refs(m.Type()) {
+				return true
+			}
+		}
+		for embedded := range t.EmbeddedTypes() {
+			if refs(embedded) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// integrationTag is the build tag that guards the files of integration
+// tests (see [addTests]).
+const integrationTag = "integration"
+
+// testFileURI returns the URI of the file to which [addTests] adds the
+// tests of the file pgf of package tp, or of its integration tests, as
+// determined by the TestFileTemplate option.
+func testFileURI(snapshot *cache.Snapshot, tp testedPackage, pgf *parsego.File, integration bool) (protocol.DocumentURI, error) {
+	template := snapshot.Options().TestFileTemplate
+	if template == "" {
+		template = "{file}_test.go"
+	}
+	dir := pgf.URI.DirPath()
+	if rest, ok := strings.CutPrefix(template, "/"); ok {
+		modURI := testModFile(snapshot, tp.mp)
+		if modURI == "" {
+			return "", fmt.Errorf("test file template %q requires a module", template)
+		}
+		rel, err := filepath.Rel(modURI.DirPath(), dir)
+		if err != nil {
+			return "", err
+		}
+		template = strings.ReplaceAll(rest, "{dir}", filepath.ToSlash(rel))
+		dir = modURI.DirPath()
+	}
+	name := strings.NewReplacer(
+		"{file}", strings.TrimSuffix(filepath.Base(pgf.URI.Path()), ".go"),
+		"{package}", tp.types.Name(),
+	).Replace(template)
+	if integration {
+		name = strings.TrimSuffix(name, "_test.go") + "_integration_test.go"
+	}
+	return protocol.URIFromPath(filepath.Join(dir, filepath.FromSlash(name))), nil
+}
+
+// addTests adds a test for each of the specified function declarations
+// of the files pgfs of package tp, which share a test file, to the
+// corresponding _test.go file (see [testFileURI]), creating it if it
+// does not already exist, with the header of the first file. It returns
+// the changes and the number of tests added.
+//
+// If skip is set, a declaration for which no test can be added, such
+// as an unexported function when the test file belongs to the external
+// test package, is skipped; otherwise it causes an error.
+//
+// The tests of methods construct their receivers by calling a helper
+// of the test package (see [TestHelpers]), if there is one, in
+// preference to a constructor. If shareRecv is set, such a helper is
+// added to the test file if the test package declares none.
+//
+// If integration is set, the tests are integration tests: they are
+// added to the file foo_integration_test.go, which a new file guards
+// by the build tag "integration", and use real values of the
+// parameters rather than fakes and mocks. The opts select optional
+// forms of the tests (see [TestFuncSource]).
+func addTests(ctx context.Context, snapshot *cache.Snapshot, tp testedPackage, pgfs []*parsego.File, decls []*ast.FuncDecl, skip, shareRecv, integration bool, opts TestOptions) (changes []protocol.DocumentChange, added int, _ error) {
+	pgf := pgfs[0]
+	if metadata.IsCommandLineArguments(tp.mp.ID) {
+		return nil, 0, fmt.Errorf("current file in command-line-arguments package")
+	}
+	opts.Comments = snapshot.Options().TestComments
+	opts.TodoMarker = snapshot.Options().TestTodoMarker
+
+	// All three maps map the path of an imported package to
+	// the local name if explicit or "" otherwise.
+	var (
+		fileImports  map[string]string         // imports in foo.go file
+		testImports  map[string]string         // imports in foo_test.go file
+		extraImports = make(map[string]string) // imports to add to test file
+	)
+
+	var collectImports = func(file *ast.File) (map[string]string, error) {
+		imps := make(map[string]string)
+		for _, spec := range file.Imports {
+			// TODO(hxjiang): support dot imports.
+			if spec.Name != nil && spec.Name.Name == "." {
+				return nil, fmt.Errorf("\"add test for func\" does not support files containing dot imports")
+			}
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			if spec.Name != nil {
+				if spec.Name.Name == "_" {
+					continue
+				}
+				imps[path] = spec.Name.Name
+			} else {
+				imps[path] = ""
+			}
+		}
+		return imps, nil
+	}
+
+	// Collect all the imports from the x.go files, keep track of the local package names.
+	fileImports = make(map[string]string)
+	for _, pgf := range pgfs {
+		imps, err := collectImports(pgf.File)
+		if err != nil {
+			return nil, 0, err
+		}
+		for path, name := range imps {
+			if _, ok := fileImports[path]; !ok {
+				fileImports[path] = name
+			}
+		}
+	}
+
+	goTestFileURI, err := testFileURI(snapshot, tp, pgf, integration)
+	if err != nil {
+		return nil, 0, err
+	}
+	// elsewhere indicates whether the test file lies outside the
+	// directory of the package, in which case it belongs to a distinct
+	// package, which can test only the exported API, like an external
+	// test package.
+	elsewhere := goTestFileURI.Dir() != pgf.URI.Dir()
+
+	testFH, err := snapshot.ReadFile(ctx, goTestFileURI)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// TODO(hxjiang): use a fresh name if the same test function name already
+	// exist.
+
+	var (
+		eofRange protocol.Range // empty selection at end of new file
+		// newFileHeader holds the copyright header and package decl of a
+		// new test file.
+		newFileHeader []byte
+		// edits contains all the text edits to be applied to the test file.
+		edits []protocol.TextEdit
+		// xtest indicates whether the test file use package x or x_test.
+		// TODO(hxjiang): We can discuss the option to interpret the user's
+		// intention by which function they are selecting. Have one file for
+		// x_test package testing, one file for x package testing.
+		xtest = true
+		// style is the assertion style of the test file.
+		style AssertionStyle = stdStyle{}
+		// doubles are the fakes declared in the test file.
+		doubles TestDoubles
+		// declared holds the names of the functions of the test file.
+		declared = make(map[string]bool)
+		// eol is the line terminator of the test file, or of the
+		// tested file if the test file has none.
+		eol = lineEnding(pgf.Src)
+	)
+
+	testPGF, err := snapshot.ParseGo(ctx, testFH, parsego.Full)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, 0, err
+		}
+		changes = append(changes, protocol.DocumentChangeCreate(goTestFileURI))
+
+		// header is the buffer containing the text to add to the beginning of the file.
+		var header bytes.Buffer
+
+		// If this test file was created by the gopls, add a copyright header and
+		// package decl based on the originating file.
+		// Search for something that looks like a copyright header, to replicate
+		// in the new file.
+		if c := copyrightComment(pgf.File); c != nil {
+			start, end, err := pgf.NodeOffsets(c)
+			if err != nil {
+				return nil, 0, err
+			}
+			header.Write(pgf.Src[start:end])
+			// One empty line between copyright header and following.
+			header.WriteString("\n\n")
+		}
+
+		// If this test file was created by gopls, add build constraints
+		// matching the non-test file, and the integration tag if needed.
+		if c := buildConstraintComment(pgf.File); c != nil {
+			start, end, err := pgf.NodeOffsets(c)
+			if err != nil {
+				return nil, 0, err
+			}
+			line := string(pgf.Src[start:end])
+			if integration {
+				expr, err := constraint.Parse(line)
+				if err != nil {
+					return nil, 0, err
+				}
+				line = "//go:build " + (&constraint.AndExpr{X: &constraint.TagExpr{Tag: integrationTag}, Y: expr}).String()
+			}
+			header.WriteString(line)
+			// One empty line between build constraint and following.
+			header.WriteString("\n\n")
+		} else if integration {
+			fmt.Fprintf(&header, "//go:build %s\n\n", integrationTag)
+		}
+
+		// Determine if a new test file should use in-package test (package x)
+		// or external test (package x_test). If any of the function parameters
+		// reference an unexported object, we cannot write out test cases from
+		// an x_test package.
+		externalTestOK := func(decl *ast.FuncDecl) bool {
+			fn := tp.funcOf(decl)
+			if fn == nil || !fn.Exported() {
 				return false
 			}
 			if fn.Signature().Recv() != nil {
@@ -354,127 +1241,697 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 					return false
 				}
 			}
-			refsUnexported := false
-			ast.Inspect(decl, func(n ast.Node) bool {
-				// The original function refs to an unexported object from the
-				// same package, so further inspection is unnecessary.
-				if refsUnexported {
-					return false
+			return !tp.refsUnexported(decl, fn)
+		}
+
+		// Use an external test only if all the functions permit it.
+		for _, decl := range decls {
+			if !elsewhere && !externalTestOK(decl) {
+				xtest = false
+				break
+			}
+		}
+		if xtest {
+			fmt.Fprintf(&header, "package %s_test\n", tp.types.Name())
+		} else {
+			fmt.Fprintf(&header, "package %s\n", tp.types.Name())
+		}
+
+		// The copyright and package decl, followed by the imports, are
+		// written to the beginning of the file.
+		newFileHeader = header.Bytes()
+	} else { // existing _test.go file.
+		if testPGF.File.Name == nil || testPGF.File.Name.NamePos == token.NoPos {
+			return nil, 0, fmt.Errorf("missing package declaration")
+		}
+		switch testPGF.File.Name.Name {
+		case pgf.File.Name.Name:
+			xtest = elsewhere
+		case pgf.File.Name.Name + "_test":
+			xtest = true
+		default:
+			if elsewhere {
+				break // any package name will do
+			}
+			return nil, 0, fmt.Errorf("invalid package declaration %q in test file %q", testPGF.File.Name, testPGF)
+		}
+
+		eofRange, err = testPGF.PosRange(testPGF.File.FileEnd, testPGF.File.FileEnd)
+		if err != nil {
+			return nil, 0, err
+		}
+		if bytes.IndexByte(testPGF.Src, '\n') >= 0 {
+			eol = lineEnding(testPGF.Src)
+		}
+
+		// Collect all the imports from the foo_test.go.
+		if testImports, err = collectImports(testPGF.File); err != nil {
+			return nil, 0, err
+		}
+		style = AssertionStyleOf(testPGF.File)
+		if !integration {
+			doubles = TestDoublesOf(testPGF.File)
+		}
+		for _, decl := range testPGF.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil {
+				declared[decl.Name.Name] = true
+			}
+		}
+	}
+
+	// Names that the new imports of the test file must not take: the
+	// package-level names of the test package, and the names of the
+	// imports of the test file.
+	var taken map[string]bool
+	if elsewhere {
+		taken = make(map[string]bool)
+		if testPGF != nil {
+			addPackageNames(taken, testPGF.File)
+		}
+	} else if taken, err = testScopeNames(ctx, snapshot, tp, xtest); err != nil {
+		return nil, 0, err
+	}
+	for _, name := range importNames(snapshot.MetadataGraph(), testImports) {
+		taken[name] = true
+	}
+	// newImports maps the path of each package that the tests import
+	// anew to its name in the test file.
+	newImports := make(map[string]string)
+
+	// qual qualifier determines the correct package name to use for a type in
+	// foo_test.go. It does this by:
+	// - Consult imports map from test file foo_test.go.
+	// - If not found, consult imports map from original file foo.go.
+	// If the package is not imported in test file foo_test.go, it is added to
+	// extraImports map, renamed if its name is taken, such as context2.
+	qual := func(p *types.Package) string {
+		// References from an in-package test should not be qualified.
+		if !xtest && p == tp.types {
+			return ""
+		}
+		// The path of an ad hoc package is that of the package of its
+		// directory (see [checkedPackageOf]).
+		path := p.Path()
+		if p == tp.types {
+			path = string(tp.mp.PkgPath)
+		}
+		// Prefer using the package name if already defined in foo_test.go
+		if local, ok := testImports[path]; ok {
+			if local != "" {
+				return local
+			} else {
+				return p.Name()
+			}
+		}
+		if name, ok := newImports[path]; ok {
+			return name
+		}
+		// Prefer the local import name (if any) used in the package under
+		// test, and fall back to the package name since there is no renaming.
+		name := p.Name()
+		if local, ok := fileImports[path]; ok && local != "" {
+			name = local
+		}
+		name = freshImportName(name, taken)
+		taken[name] = true
+		newImports[path] = name
+		if name == p.Name() {
+			extraImports[path] = ""
+		} else {
+			extraImports[path] = name
+		}
+		return name
+	}
+
+	// Construct receivers by calling the helpers of the test package.
+	var helpers TestHelpers
+	if elsewhere {
+		helpers = make(TestHelpers)
+		if testPGF != nil {
+			addTestHelpers(helpers, testPGF.File)
+		}
+	} else if helpers, err = tp.testHelpers(ctx, snapshot, xtest); err != nil {
+		return nil, 0, err
+	}
+	// Construct inputs using the optional packages the module requires,
+	// which provide mocks, and so are of no use to integration tests.
+	var inputs TestInputs
+	if !integration {
+		inputs, err = tp.testInputs(ctx, snapshot)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// testSource returns the source of the test of the declared function.
+	testSource := func(decl *ast.FuncDecl) ([]byte, error) {
+		fn := tp.funcOf(decl)
+		if fn == nil {
+			return nil, fmt.Errorf("no type information for %s", decl.Name)
+		}
+		if typeRefsCgo(fn.Signature()) {
+			return nil, fmt.Errorf("cannot add test of %s, whose signature refers to C types, as a test file cannot import \"C\"", decl.Name)
+		}
+		if xtest {
+			// Reject if function/method is unexported.
+			if !fn.Exported() {
+				return nil, fmt.Errorf("cannot add test of unexported function %s to external test package %s_test", decl.Name, pgf.File.Name)
+			}
+
+			// Reject if receiver is unexported.
+			if fn.Signature().Recv() != nil {
+				if _, ident, _ := goplsastutil.UnpackRecv(decl.Recv.List[0].Type); ident == nil || !ident.IsExported() {
+					return nil, fmt.Errorf("cannot add external test for method %s.%s as receiver type is not exported", ident.Name, decl.Name)
+				}
+			}
+			// TODO(hxjiang): reject if the any input parameter type is unexported.
+			// TODO(hxjiang): reject if any return value type is unexported. Explore
+			// the option to drop the return value if the type is unexported.
+		}
+		ctors, err := snapshot.Constructors(ctx, tp.mp, tp.mp.PkgPath)
+		if err != nil {
+			return nil, err
+		}
+		params := TestFuncParams{
+			Constructors: ctors,
+			XTest:        xtest,
+			Qual:         qual,
+			Style:        style,
+			Doubles:      doubles,
+			Helpers:      helpers,
+			Inputs:       inputs,
+			Options:      opts,
+		}
+		if !shareRecv {
+			return TestFuncSource(fn, params)
+		}
+
+		// Add a helper to construct the receiver, unless there is one.
+		var (
+			helper  []byte
+			recvKey string // key of the added helper
+		)
+		if recv := fn.Signature().Recv(); recv != nil {
+			recvType := recv.Type()
+			if ptr, ok := recvType.(*types.Pointer); ok {
+				recvType = ptr.Elem()
+			}
+			if t, ok := recvType.(typesinternal.NamedOrAlias); ok && helpers[types.TypeString(t, qual)] == "" {
+				name := "newTest" + exportedName(t.Obj().Name())
+				if !declared[name] {
+					helper, err = receiverHelperSource(name, t, fn.Pkg(), ctors, xtest, qual)
+					if err != nil {
+						return nil, err
+					}
+					if helper, err = opts.adjustComments(helper); err != nil {
+						return nil, err
+					}
+					recvKey = types.TypeString(t, qual)
+					helpers[recvKey] = name
+				}
+			}
+		}
+		test, err := TestFuncSource(fn, params)
+		if err != nil {
+			if helper != nil {
+				delete(helpers, recvKey)
+			}
+			return nil, err
+		}
+		return append(helper, test...), nil
+	}
+
+	// Add each test after the tests of its subject or of the nearest
+	// preceding declaration that has some, if any, or else at the end
+	// of the file, so that the tests of a file follow the order of their
+	// subjects. The tests to add at each point are accumulated in order.
+	var points map[*ast.FuncDecl]protocol.Position
+	if testPGF != nil {
+		rel, err := snapshot.TestRelation(ctx, tp.mp.PkgPath)
+		if err != nil {
+			return nil, 0, err
+		}
+		points = make(map[*ast.FuncDecl]protocol.Position)
+		for _, pgf := range pgfs {
+			maps.Copy(points, testInsertionPoints(rel, pgf, testPGF.URI))
+		}
+	}
+	var (
+		order []protocol.Position // insertion points, in order of first use
+		tests = make(map[protocol.Position]*bytes.Buffer)
+	)
+	for _, decl := range decls {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		oldImports, oldHelpers := maps.Clone(newImports), maps.Clone(helpers)
+		// rollback forgets the imports and helpers added by the test.
+		rollback := func() {
+			for path, name := range newImports {
+				if _, ok := oldImports[path]; !ok {
+					delete(newImports, path)
+					delete(extraImports, path)
+					delete(taken, name)
+				}
+			}
+			maps.DeleteFunc(helpers, func(typ, _ string) bool {
+				_, ok := oldHelpers[typ]
+				return !ok
+			})
+		}
+		test, err := testSource(decl)
+		if err == nil {
+			// If the locals of the test shadow the packages it imports
+			// anew, import them by other names, and generate it again.
+			locals := localNames(test)
+			for path, name := range newImports {
+				if _, ok := oldImports[path]; !ok && locals[name] {
+					rollback()
+					maps.Copy(taken, locals)
+					test, err = testSource(decl)
+					break
+				}
+			}
+		}
+		if err != nil {
+			rollback()
+			if skip {
+				continue
+			}
+			return nil, 0, err
+		}
+		point, ok := points[decl]
+		if !ok {
+			point = eofRange.Start
+		}
+		if tests[point] == nil {
+			tests[point] = new(bytes.Buffer)
+			order = append(order, point)
+		}
+		tests[point].Write(test)
+		added++
+	}
+	if added == 0 {
+		return nil, 0, nil
+	}
+
+	// Compute edits to update imports.
+	//
+	// If we're adding to an existing test file, we need to adjust existing
+	// imports. Otherwise, we can simply write out the imports to the new file.
+	if testPGF != nil {
+		batch := NewImportFixBatch(snapshot.Options().Local, testPGF.Src)
+		for path, name := range extraImports {
+			batch.AddImport(path, name)
+		}
+		importEdits, err := batch.Edits()
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not compute the import fix edits: %w", err)
+		}
+		edits = append(edits, importEdits...)
+	} else {
+		importsBuffer := bytes.NewBuffer(newFileHeader)
+		if len(extraImports) == 1 {
+			importsBuffer.WriteString("\nimport ")
+			for path, name := range extraImports {
+				if name != "" {
+					importsBuffer.WriteString(name + " ")
+				}
+				importsBuffer.WriteString(fmt.Sprintf("\"%s\"\n", path))
+			}
+		} else {
+			importsBuffer.WriteString("\nimport(")
+			// Sort for determinism.
+			for path, name := range moremaps.Sorted(extraImports) {
+				importsBuffer.WriteString("\n\t")
+				if name != "" {
+					importsBuffer.WriteString(name + " ")
+				}
+				importsBuffer.WriteString(fmt.Sprintf("\"%s\"", path))
+			}
+			importsBuffer.WriteString("\n)\n")
+		}
+		header, err := formatGenerated(snapshot.Options(), tp.mp, importsBuffer.Bytes(), false)
+		if err != nil {
+			return nil, 0, err
+		}
+		edits = append(edits, protocol.TextEdit{
+			Range:   protocol.Range{},
+			NewText: withLineEnding(string(header), eol),
+		})
+	}
+
+	// Format the tests as the file would be, which gofumpt may change.
+	slices.SortFunc(order, protocol.ComparePosition)
+	for _, point := range order {
+		formatted, err := formatGenerated(snapshot.Options(), tp.mp, tests[point].Bytes(), true)
+		if err != nil {
+			return nil, 0, err
+		}
+		text := string(formatted)
+		if point != eofRange.Start {
+			// Insert the tests after the closing brace of a test.
+			text = "\n\n" + strings.Trim(text, "\n")
+		} else {
+			// Terminate the last line of the file, both before the
+			// tests and after them.
+			if testPGF != nil && len(testPGF.Src) > 0 && !bytes.HasSuffix(testPGF.Src, []byte("\n")) {
+				text = "\n" + text
+			}
+			if !strings.HasSuffix(text, "\n") {
+				text += "\n"
+			}
+		}
+		edits = append(edits, protocol.TextEdit{
+			Range:   protocol.Range{Start: point, End: point},
+			NewText: withLineEnding(text, eol),
+		})
+	}
+
+	changes = append(changes, protocol.DocumentChangeEdit(testFH, edits))
+
+	// Require the modules of the packages that the tests import anew,
+	// or let the batch require them once for the module.
+	if tp.shared != nil {
+		tp.shared.addImports(maps.Keys(extraImports))
+		return changes, added, nil
+	}
+	modChanges, err := testModuleChanges(ctx, snapshot, testModFile(snapshot, tp.mp), maps.Keys(extraImports))
+	if err != nil {
+		return nil, 0, err
+	}
+	return append(changes, modChanges...), added, nil
+}
+
+// testInsertionPoints returns the points of the test file uri at which
+// to add the tests of the function declarations of the file pgf of the
+// package under test, according to the relation rel between its tests
+// and their subjects: the end of the last test, in that file, of the
+// declaration and the declarations that precede it, so that the tests
+// follow the order of their subjects. Declarations that have no such
+// point, as none of them has tests there, are absent.
+func testInsertionPoints(rel *testfuncs.Relation, pgf *parsego.File, uri protocol.DocumentURI) map[*ast.FuncDecl]protocol.Position {
+	points := make(map[*ast.FuncDecl]protocol.Position)
+	var (
+		last  protocol.Position // end of the last test of a preceding declaration
+		found bool
+	)
+	for _, decl := range pgf.File.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if name, ok := testableName(pgf.File, decl); ok {
+			for _, test := range rel.TestsOf(name) {
+				if test.Location.URI == uri && (!found || protocol.ComparePosition(last, test.Location.Range.End) < 0) {
+					last, found = test.Location.Range.End, true
+				}
+			}
+		}
+		if found {
+			points[decl] = last
+		}
+	}
+	return points
+}
+
+// AddTests adds a test for each function and method declared in the
+// specified Go file, or in the files of the package in the specified
+// directory, that has no test (see [testsOf]). If args.Recursive is
+// set, it also processes the workspace packages in the subdirectories
+// of the directory, and if args.ExportedOnly is set, it processes only
+// exported functions and exported methods of exported types. Each
+// file's tests are added to the corresponding _test.go file. Functions
+// for which no test can be added are skipped.
+//
+// The packages of the files are type-checked together, each once (see
+// [packageBatch]), and the tests of each test file are generated
+// concurrently with the others; report is called as each test file is
+// done, never concurrently, with the number of files done, the total,
+// and the changes for that test file, so that they may be delivered
+// before the others are done. An error from report stops the operation.
+// AddTests returns the changes and a summary of them; the Edit field
+// of the summary is unset.
+func AddTests(ctx context.Context, snapshot *cache.Snapshot, args command.AddTestsArgs, report func(done, total int, changes []protocol.DocumentChange) error) ([]protocol.DocumentChange, command.AddTestsResult, error) {
+	var result command.AddTestsResult
+	uris, err := subjectFiles(ctx, snapshot, args.URI, args.Recursive)
+	if err != nil {
+		return nil, result, err
+	}
+	batch, err := newPackageBatch(ctx, snapshot, uris)
+	if err != nil {
+		return nil, result, err
+	}
+
+	// Group the files by test file: with a template such as
+	// "{package}_test.go", the tests of several files go to one file,
+	// whose edits must be computed at once.
+	type testFile struct {
+		tp    testedPackage
+		pgfs  []*parsego.File
+		decls []*ast.FuncDecl
+	}
+	var (
+		files []*testFile
+		byURI = make(map[protocol.DocumentURI]*testFile)
+		idle  int // files with no functions to test
+	)
+	for _, uri := range uris {
+		file := batch.files[uri]
+		pgf, rel := file.pgf, file.pkg.rel
+		var decls []*ast.FuncDecl
+		for _, decl := range pgf.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok {
+				name, ok := testableName(pgf.File, decl)
+				if !ok || args.ExportedOnly && !isExportedName(name) {
+					continue
 				}
-				switch t := n.(type) {
-				case *ast.BlockStmt:
-					// Avoid inspect the function body.
-					return false
-				case *ast.Ident:
-					// Use test variant (package foo) if the function signature
-					// references any unexported objects (like types or
-					// constants) from the same package.
-					// Note: types.PkgName is excluded from this check as it's
-					// always defined in the same package.
-					if obj, ok := pkg.TypesInfo().Uses[t]; ok && !obj.Exported() && obj.Pkg() == pkg.Types() && !is[*types.PkgName](obj) {
-						refsUnexported = true
-					}
-					return false
-				default:
-					return true
+				if len(testsOf(rel, name)) == 0 {
+					decls = append(decls, decl)
 				}
-			})
-			return !refsUnexported
+			}
 		}
-
-		xtest = externalTestOK()
-		if xtest {
-			fmt.Fprintf(&header, "package %s_test\n", pkg.Types().Name())
-		} else {
-			fmt.Fprintf(&header, "package %s\n", pkg.Types().Name())
+		if len(decls) == 0 {
+			idle++
+			continue
 		}
-
-		// Write the copyright and package decl to the beginning of the file.
-		edits = append(edits, protocol.TextEdit{
-			Range:   protocol.Range{},
-			NewText: header.String(),
-		})
-	} else { // existing _test.go file.
-		if testPGF.File.Name == nil || testPGF.File.Name.NamePos == token.NoPos {
-			return nil, fmt.Errorf("missing package declaration")
+		result.Processed += len(decls)
+		tp, err := file.pkg.testedPackage()
+		if err != nil {
+			return nil, result, fmt.Errorf("adding tests for %s: %w", filepath.Base(uri.Path()), err)
 		}
-		switch testPGF.File.Name.Name {
-		case pgf.File.Name.Name:
-			xtest = false
-		case pgf.File.Name.Name + "_test":
-			xtest = true
-		default:
-			return nil, fmt.Errorf("invalid package declaration %q in test file %q", testPGF.File.Name, testPGF)
+		testURI, err := testFileURI(snapshot, tp, pgf, false)
+		if err != nil {
+			return nil, result, fmt.Errorf("adding tests for %s: %w", filepath.Base(uri.Path()), err)
 		}
+		tf := byURI[testURI]
+		if tf == nil {
+			tf = &testFile{tp: tp}
+			byURI[testURI] = tf
+			files = append(files, tf)
+		}
+		tf.pgfs = append(tf.pgfs, pgf)
+		tf.decls = append(tf.decls, decls...)
+	}
 
-		eofRange, err = testPGF.PosRange(testPGF.File.FileEnd, testPGF.File.FileEnd)
+	var (
+		changes = make([][]protocol.DocumentChange, len(files))
+		added   = make([]int, len(files))
+		mu      sync.Mutex // guards done
+		done    = idle
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(runtime.GOMAXPROCS(-1)) // type-checking and formatting are CPU-bound
+	for i, tf := range files {
+		g.Go(func() error {
+			var err error
+			changes[i], added[i], err = addTests(gctx, snapshot, tf.tp, tf.pgfs, tf.decls, true, false, false, TestOptions{})
+			if err != nil {
+				return fmt.Errorf("adding tests for %s: %w", filepath.Base(tf.pgfs[0].URI.Path()), err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			done += len(tf.pgfs)
+			return report(done, len(uris), changes[i])
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, result, err
+	}
+
+	var allChanges []protocol.DocumentChange
+	result.Skipped = result.Processed
+	for i := range files {
+		allChanges = append(allChanges, changes[i]...)
+		result.Skipped -= added[i]
+	}
+
+	// Require, once for each module, the modules of the packages that
+	// the added tests import anew.
+	imports := make(map[protocol.DocumentURI]map[string]bool) // by go.mod file
+	for _, file := range batch.files {
+		if tp := file.pkg.tp; tp.shared != nil && len(tp.shared.imports) > 0 {
+			modURI := testModFile(snapshot, tp.mp)
+			if imports[modURI] == nil {
+				imports[modURI] = make(map[string]bool)
+			}
+			maps.Copy(imports[modURI], tp.shared.imports)
+		}
+	}
+	var modChanges []protocol.DocumentChange
+	for modURI, paths := range moremaps.Sorted(imports) {
+		changes, err := testModuleChanges(ctx, snapshot, modURI, maps.Keys(paths))
 		if err != nil {
-			return nil, err
+			return nil, result, err
 		}
-
-		// Collect all the imports from the foo_test.go.
-		if testImports, err = collectImports(testPGF.File); err != nil {
-			return nil, err
+		modChanges = append(modChanges, changes...)
+	}
+	if len(modChanges) > 0 {
+		if err := report(len(uris), len(uris), modChanges); err != nil {
+			return nil, result, err
 		}
+		allChanges = append(allChanges, modChanges...)
 	}
-
-	// qual qualifier determines the correct package name to use for a type in
-	// foo_test.go. It does this by:
-	// - Consult imports map from test file foo_test.go.
-	// - If not found, consult imports map from original file foo.go.
-	// If the package is not imported in test file foo_test.go, it is added to
-	// extraImports map.
-	qual := func(p *types.Package) string {
-		// References from an in-package test should not be qualified.
-		if !xtest && p == pkg.Types() {
-			return ""
+	for _, change := range allChanges {
+		if change.CreateFile != nil {
+			result.Created = append(result.Created, change.CreateFile.URI)
 		}
-		// Prefer using the package name if already defined in foo_test.go
-		if local, ok := testImports[p.Path()]; ok {
-			if local != "" {
-				return local
-			} else {
-				return p.Name()
+	}
+	return allChanges, result, nil
+}
+
+// subjectFiles returns the non-test Go files of the workspace
+// denoted by uri, which is either a Go file or the directory of a
+// package; if recursive, the workspace packages in its subdirectories
+// are included too.
+func subjectFiles(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI, recursive bool) ([]protocol.DocumentURI, error) {
+	uris := []protocol.DocumentURI{uri}
+	if filepath.Ext(uri.Path()) != ".go" {
+		var mps []*metadata.Package
+		if recursive {
+			var err error
+			mps, err = snapshot.WorkspaceMetadata(ctx)
+			if err != nil {
+				return nil, err
 			}
+		} else {
+			mps = slices.Collect(maps.Values(snapshot.MetadataGraph().Packages))
 		}
-		// TODO(hxjiang): we should consult the scope of the test package to
-		// ensure these new imports do not shadow any package-level names.
-		// Prefer the local import name (if any) used in the package under test.
-		if local, ok := fileImports[p.Path()]; ok && local != "" {
-			extraImports[p.Path()] = local
-			return local
+		uris = nil
+		for _, mp := range mps {
+			if mp.ForTest != "" || metadata.IsCommandLineArguments(mp.ID) {
+				continue
+			}
+			for _, f := range mp.CompiledGoFiles {
+				if f.Dir() == uri || recursive && uri.Encloses(f) {
+					uris = append(uris, f)
+				}
+			}
 		}
-		// Fall back to the package name since there is no renaming.
-		extraImports[p.Path()] = ""
-		return p.Name()
+		slices.Sort(uris)
+		uris = slices.Compact(uris)
+	}
+	uris = slices.DeleteFunc(uris, func(uri protocol.DocumentURI) bool {
+		return strings.HasSuffix(uri.Path(), "_test.go")
+	})
+	if len(uris) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", uri)
 	}
+	return uris, nil
+}
 
-	if xtest {
-		// Reject if function/method is unexported.
-		if !fn.Exported() {
-			return nil, fmt.Errorf("cannot add test of unexported function %s to external test package %s_test", decl.Name, pgf.File.Name)
+// isExportedName reports whether each component of the name F or T.M
+// is exported.
+func isExportedName(name string) bool {
+	for part := range strings.SplitSeq(name, ".") {
+		if !token.IsExported(part) {
+			return false
 		}
+	}
+	return true
+}
 
-		// Reject if receiver is unexported.
-		if sig.Recv() != nil {
-			if _, ident, _ := goplsastutil.UnpackRecv(decl.Recv.List[0].Type); ident == nil || !ident.IsExported() {
-				return nil, fmt.Errorf("cannot add external test for method %s.%s as receiver type is not exported", ident.Name, decl.Name)
-			}
+// testableName returns the name, F or T.M, by which the tests of the
+// function or method declared by decl in the specified file are
+// identified (see [testfuncs.Subjects]), and reports whether it is a
+// function that may have tests: it is not an init function, a main
+// function of package main, or a declaration without a body.
+func testableName(file *ast.File, decl *ast.FuncDecl) (string, bool) {
+	if decl.Body == nil || decl.Name.Name == "_" || decl.Name.Name == "init" {
+		return "", false
+	}
+	name := decl.Name.Name
+	if decl.Recv != nil {
+		_, recv, _ := goplsastutil.UnpackRecv(decl.Recv.List[0].Type)
+		if recv == nil {
+			return "", false
 		}
-		// TODO(hxjiang): reject if the any input parameter type is unexported.
-		// TODO(hxjiang): reject if any return value type is unexported. Explore
-		// the option to drop the return value if the type is unexported.
+		name = recv.Name + "." + name
+	} else if name == "main" && file.Name.Name == "main" {
+		return "", false
 	}
+	return name, true
+}
 
-	testName, err := testName(fn)
-	if err != nil {
-		return nil, err
-	}
+// TestFuncParams holds the parameters of [TestFuncSource], other than
+// the function or method under test.
+type TestFuncParams struct {
+	// Constructors is the constructor index of the package of the
+	// function, whose first suitable constructor creates the receiver
+	// of a method.
+	Constructors *constructors.Index
 
-	data := testInfo{
+	// XTest reports whether the test belongs to the external test
+	// package, rather than to the package of the function.
+	XTest bool
+
+	// Qual qualifies references to packages.
+	Qual types.Qualifier
+
+	// Style is the style in which the test checks the results (see
+	// [AssertionStyleOf]); if nil, it is the standard library style.
+	Style AssertionStyle
+
+	// Doubles holds the fakes that the test cases use for the
+	// parameters of interface type, if any.
+	Doubles TestDoubles
+
+	// Helpers holds the helpers that the test calls to create the
+	// receiver of a method, instead of a constructor, if any.
+	Helpers TestHelpers
+
+	// Inputs holds the optional packages with which the test
+	// constructs the inputs, such as go-sqlmock for parameters of
+	// type *sql.DB, if any.
+	Inputs TestInputs
+
+	// Options selects optional forms of the test.
+	Options TestOptions
+}
+
+// TestFuncSource returns the formatted source of a table-driven test
+// of the function or method fn, as it appears in a test file of the
+// package of fn or of its external test package, as described by
+// params.
+func TestFuncSource(fn *types.Func, params TestFuncParams) ([]byte, error) {
+	return generate(testGenerator, fn, params)
+}
+
+// newTestInfo returns the data with which the template of a generator
+// (see [generator]) renders the function named name that exercises the
+// function or method fn: the qualified names of the packages, the
+// fields of the test cases for the inputs and wanted results of fn,
+// the checks of its results, and the construction of the receiver of
+// a method, as described by params.
+func newTestInfo(name string, fn *types.Func, params TestFuncParams) (*testInfo, error) {
+	sig := fn.Signature()
+	qual := params.Qual
+
+	data := &testInfo{
 		TestingPackageName: qual(types.NewPackage("testing", "testing")),
-		PackageName:        qual(pkg.Types()),
-		TestFuncName:       testName,
+		PackageName:        qual(fn.Pkg()),
+		TestFuncName:       name,
 		Func: function{
 			Name: fn.Name(),
 		},
@@ -490,18 +1947,145 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 		return named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
 	}
 
-	for i := range sig.Params().Len() {
-		param := sig.Params().At(i)
+	// values forms the arguments for parameters that have no name,
+	// or that are contexts.
+	values := &typeutil.ValueConfig{Package: fn.Pkg(), Qualifier: qual}
+	if params.XTest {
+		values.Package = types.NewPackage(fn.Pkg().Path()+"_test", fn.Pkg().Name()+"_test")
+	}
+
+	// paramField returns the field for the ith of the parameters of a
+	// function, which refers to the fake of its type, if any, or
+	// defaults to a temporary directory or file, or to the URL or a
+	// client of a test HTTP server, if the parameter denotes one. The
+	// field of a parameter of type fs.FS holds the files of an
+	// fstest.MapFS, and that of a parameter of type *sql.DB, if
+	// go-sqlmock is available, the expectations of a mock database.
+	paramField := func(vars *types.Tuple, i int) field {
+		param := vars.At(i)
 		name, typ := param.Name(), param.Type()
-		f := field{Type: types.TypeString(typ, qual)}
-		if i == 0 && isContextType(typ) {
-			f.Value = qual(types.NewPackage("context", "context")) + ".Background()"
-		} else if name == "" || name == "_" {
-			f.Value, _ = typesinternal.ZeroString(typ, qual)
+		// The type of the field is that of the parameter unless the
+		// test constructs the argument from other inputs, in which case
+		// qualifying it would import its package in vain.
+		var f field
+		if isFSType(typ) {
+			if name == "" || name == "_" {
+				f.Value = qual(types.NewPackage("testing/fstest", "fstest")) + ".MapFS{}"
+			} else {
+				f.Name, f.Type, f.Value, f.mapFS = derivedField(vars, i, "files", isFSType), "map[string]string", name, true
+			}
+			return f
+		}
+		if params.Inputs[sqlmockPath] && isSQLDBType(typ) && name != "" && name != "_" {
+			sqlmock := qual(types.NewPackage(sqlmockPath, "sqlmock"))
+			f.Name, f.Type, f.Value, f.sqlMock = derivedField(vars, i, "expect", isSQLDBType), "func(mock "+sqlmock+".Sqlmock)", name, true
+			return f
+		}
+		fake := ""
+		if !isContextType(typ) {
+			fake = params.Doubles.fakeFor(typ)
+		}
+		if fake != "" {
+			f.Type = "*" + fake
+		} else {
+			f.Type = types.TypeString(typ, qual)
+		}
+		if i == 0 && isContextType(typ) || name == "" || name == "_" {
+			if fake != "" {
+				f.Value = "&" + fake + "{}"
+				return f
+			}
+			f.Value, _ = typeutil.DefaultValue(typ, values)
+			if temp, _ := tempDefault(name, typ, qual); temp != "" {
+				f.Value = temp
+			} else if expr, _ := httpDefault(name, typ, values.Package, qual); expr != "" {
+				f.Value, f.server = expr, true
+			}
 		} else {
 			f.Name = name
+			if fake == "" {
+				f.Default, f.Unset = tempDefault(name, typ, qual)
+				if f.Default == "" {
+					f.Default, f.Unset = httpDefault(name, typ, values.Package, qual)
+					f.server = f.Default != ""
+				}
+			}
+		}
+		return f
+	}
+
+	// argField returns the field for the ith parameter of a call of a
+	// function of signature sig, or false if the call passes nothing
+	// for it: the variadic parameter of a constructor (if ctor is set),
+	// or an unnamed variadic parameter, takes no arguments, rather than
+	// a nil option, say. A named variadic parameter of the function
+	// under test takes the arguments of a test case.
+	argField := func(sig *types.Signature, i int, ctor bool) (field, bool) {
+		if !sig.Variadic() || i < sig.Params().Len()-1 {
+			return paramField(sig.Params(), i), true
+		}
+		if name := sig.Params().At(i).Name(); ctor || name == "" || name == "_" {
+			return field{}, false
+		}
+		f := paramField(sig.Params(), i)
+		f.Variadic = f.Value == ""
+		return f, true
+	}
+
+	// defaultArgs returns the fields of the arguments of a call of a
+	// constructor of signature sig that the test cases do not vary:
+	// each takes the default value of its parameter, as if it were
+	// unnamed, and the variadic parameter, if any, takes none.
+	defaultArgs := func(sig *types.Signature) []field {
+		n := sig.Params().Len()
+		if sig.Variadic() {
+			n--
+		}
+		vars := make([]*types.Var, n)
+		for i := range n {
+			param := sig.Params().At(i)
+			vars[i] = types.NewParam(param.Pos(), param.Pkg(), "", param.Type())
+		}
+		params := types.NewTuple(vars...)
+		var args []field
+		for i := range n {
+			args = append(args, paramField(params, i))
+		}
+		return args
+	}
+
+	for i := range sig.Params().Len() {
+		if f, ok := argField(sig, i, false); ok {
+			data.Func.Args = append(data.Func.Args, f)
+		}
+	}
+
+	// Pre-populate the table with the test cases of the call sites
+	// and, if the function computes a constant from the arguments of
+	// each, its wanted result.
+	cases := callSiteCases(fn.Name(), data.Func.Args, params.Options.CallSites)
+	var wanted []string // result of each case, if all are known
+	for _, c := range cases {
+		value, ok := evalResult(fn, params.Options.ConstantResult, c.call)
+		if !ok {
+			wanted = nil
+			break
+		}
+		wanted = append(wanted, value)
+	}
+
+	// resultSuffix returns the suffix of the names of the got variable
+	// and want field of the ith result of the function other than a
+	// final error: its name, if it has one, as in gotHead and wantHead,
+	// or else its position, as in got2 and want2.
+	resultSuffix := func(i int) string {
+		if name := sig.Results().At(i).Name(); name != "" && name != "_" && exportedName(name) != "Err" {
+			return exportedName(name)
+		}
+		if i == 0 {
+			return ""
 		}
-		data.Func.Args = append(data.Func.Args, f)
+		return fmt.Sprint(i + 1)
 	}
 
 	for i := range sig.Results().Len() {
@@ -509,15 +2093,76 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 		var name string
 		if i == sig.Results().Len()-1 && types.Identical(typ, errorType) {
 			name = "gotErr"
-		} else if i == 0 {
-			name = "got"
 		} else {
-			name = fmt.Sprintf("got%d", i+1)
+			name = "got" + resultSuffix(i)
+		}
+		res := field{Name: name}
+		if _, ok := collectIter(typ, "", qual); ok {
+			// The test collects the values into got, so it does not
+			// refer to the iter package, which it need not import.
+			res.Name += "Seq"
+		} else {
+			res.Type = types.TypeString(typ, qual)
+		}
+		data.Func.Results = append(data.Func.Results, res)
+	}
+
+	// Render the checks of the results.
+	style := params.Style
+	if style == nil {
+		style = stdStyle{}
+	}
+	var (
+		got, want []string
+		checks    []string // checks of the fields of struct results
+	)
+	for i, res := range data.Func.Results {
+		if res.Name == "gotErr" {
+			data.Wants = append(data.Wants, field{Name: "wantErr", Type: "bool"})
+			target := errorTarget(fn.Pkg(), qual)
+			if target != "" {
+				data.Wants = append(data.Wants, field{Name: "wantErrType", Type: "any", Comment: "target of errors.As, such as " + target})
+			}
+			data.CheckErr = style.checkErr(qual, fn.Name(), target != "")
+			continue
+		}
+		name := "want" + resultSuffix(i)
+		typ := sig.Results().At(i).Type()
+		if collect, ok := collectIter(typ, res.Name, qual); ok {
+			// Compare the values of an iterator, not the function.
+			g := strings.TrimSuffix(res.Name, "Seq")
+			data.Collect = append(data.Collect, g+" := "+collect.Value)
+			data.Wants = append(data.Wants, field{Name: name, Type: collect.Type})
+			got, want = append(got, g), append(want, "tt."+name)
+			continue
+		}
+		var fields []*types.Var
+		if params.Options.FieldAssertions {
+			fields = resultFields(typ, fn.Pkg(), params.XTest)
+		}
+		if fields == nil {
+			data.Wants = append(data.Wants, res)
+			data.Wants[len(data.Wants)-1].Name = name
+			got, want = append(got, res.Name), append(want, "tt."+name)
+			continue
+		}
+		for _, f := range fields {
+			w := name + exportedName(f.Name())
+			data.Wants = append(data.Wants, field{Name: w, Type: types.TypeString(f.Type(), qual)})
+			checks = append(checks, style.checkField(qual, fn.Name(), f.Name(), res.Name+"."+f.Name(), "tt."+w, types.Comparable(f.Type())))
+		}
+	}
+	if len(got) > 0 {
+		checks = append([]string{style.checkResults(qual, fn.Name(), got, want, wanted != nil)}, checks...)
+	}
+	data.CheckResults = strings.Join(checks, "\n")
+	for i, c := range cases {
+		if wanted != nil {
+			// The function has a single result, whose want field is the last.
+			data.Cases = append(data.Cases, c.literal(data.Wants[len(data.Wants)-1].Name, wanted[i]))
+		} else {
+			data.Cases = append(data.Cases, c.literal("", ""))
 		}
-		data.Func.Results = append(data.Func.Results, field{
-			Name: name,
-			Type: types.TypeString(typ, qual),
-		})
 	}
 
 	if sig.Recv() != nil {
@@ -533,43 +2178,15 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 			return nil, fmt.Errorf("the receiver type is neither named type nor alias type")
 		}
 
+		// Prefer the receiver's own name, then a name derived from
+		// its type, avoiding the names of the *testing.T, of the
+		// test case variables, and of the test HTTP server.
+		avoid := map[string]bool{"t": true, "tt": true, serverVar: true}
 		var varName string
-		{
-			var possibleNames []string // list of candidates, preferring earlier entries.
-			if len(sig.Recv().Name()) > 0 {
-				possibleNames = append(possibleNames,
-					sig.Recv().Name(),            // receiver name.
-					string(sig.Recv().Name()[0]), // first character of receiver name.
-				)
-			}
-			possibleNames = append(possibleNames,
-				string(t.Obj().Name()[0]), // first character of receiver type name.
-			)
-			if len(t.Obj().Name()) >= 2 {
-				possibleNames = append(possibleNames,
-					string(t.Obj().Name()[:2]), // first two character of receiver type name.
-				)
-			}
-			var camelCase []rune
-			for i, s := range t.Obj().Name() {
-				if i == 0 || unicode.IsUpper(s) {
-					camelCase = append(camelCase, s)
-				}
-			}
-			possibleNames = append(possibleNames,
-				string(camelCase), // captalized initials.
-			)
-			for _, name := range possibleNames {
-				name = strings.ToLower(name)
-				if name == "" || name == "t" || name == "tt" {
-					continue
-				}
-				varName = name
-				break
-			}
-			if varName == "" {
-				varName = "r" // default as "r" for "receiver".
-			}
+		if name := strings.ToLower(sig.Recv().Name()); name != "" && name != "_" && !avoid[name] {
+			varName = name
+		} else {
+			varName = typesutil.VarName(t, avoid)
 		}
 
 		data.Receiver = &receiver{
@@ -579,63 +2196,53 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 			},
 		}
 
-		// constructor is the selected constructor for type T.
+		// constructor is the selected constructor for type T. The
+		// forms of the receiver depend on whether the constructor
+		// returns a T or a *T, which the helpers do not record.
 		var constructor *types.Func
-
-		// When finding the qualified constructor, the function should return the
-		// any type whose named type is the same type as T's named type.
-		_, wantType := typesinternal.ReceiverNamed(sig.Recv())
-		for _, name := range pkg.Types().Scope().Names() {
-			f, ok := pkg.Types().Scope().Lookup(name).(*types.Func)
-			if !ok {
-				continue
-			}
-			if f.Signature().Recv() != nil {
-				continue
-			}
-			// Unexported constructor is not visible in x_test package.
-			if xtest && !f.Exported() {
-				continue
-			}
-			// Only allow constructors returning T, T, (T, error), or (T, error).
-			if f.Signature().Results().Len() > 2 || f.Signature().Results().Len() == 0 {
-				continue
-			}
-
-			_, gotType := typesinternal.ReceiverNamed(f.Signature().Results().At(0))
-			if gotType == nil || !types.Identical(gotType, wantType) {
-				continue
-			}
-
-			if f.Signature().Results().Len() == 2 && !types.Identical(f.Signature().Results().At(1).Type(), errorType) {
-				continue
-			}
-
+		if helper := params.Helpers[data.Receiver.Var.Type]; helper != "" && !params.Options.ReceiverForms {
+			data.Receiver.Helper = helper
+		} else {
+			_, named := typesinternal.ReceiverNamed(sig.Recv())
+			constructor = receiverConstructor(params.Constructors, fn.Pkg(), named, params.XTest)
 			if constructor == nil {
-				constructor = f
-			}
-
-			// Functions named NewType are prioritized as constructors over other
-			// functions that match only the signature criteria.
-			if strings.EqualFold(strings.ToLower(f.Name()), strings.ToLower("new"+t.Obj().Name())) {
-				constructor = f
+				constructor = receiverFactory(params.Constructors, fn.Pkg(), named, params.XTest)
 			}
 		}
 
 		if constructor != nil {
 			data.Receiver.Constructor = &function{Name: constructor.Name()}
+			if recv := constructor.Signature().Recv(); recv != nil {
+				// Construct the factory, one level deep: by its own
+				// constructor, if any, or else as a zero value.
+				_, named := typesinternal.ReceiverNamed(recv)
+				avoid[varName] = true
+				avoid["err"] = true
+				f := &factory{Var: field{
+					Name: typesutil.VarName(named, avoid),
+					Type: types.TypeString(named, qual),
+				}}
+				if ctor := receiverConstructor(params.Constructors, fn.Pkg(), named, params.XTest); ctor != nil {
+					f.Constructor = &function{Name: ctor.Name(), Args: defaultArgs(ctor.Signature())}
+					f.Cleanup = cleanupStmt(f.Var.Name, ctor.Signature().Results().At(0).Type(), qual)
+					for i := range ctor.Signature().Results().Len() {
+						typ := ctor.Signature().Results().At(i).Type()
+						name := "_"
+						if i == 0 {
+							name = f.Var.Name
+						} else if i == ctor.Signature().Results().Len()-1 && types.Identical(typ, errorType) {
+							name = "err"
+						}
+						f.Constructor.Results = append(f.Constructor.Results, field{Name: name, Type: types.TypeString(typ, qual)})
+					}
+				}
+				data.Receiver.Factory = f
+			}
+			data.Receiver.Cleanup = cleanupStmt(varName, constructor.Signature().Results().At(0).Type(), qual)
 			for i := range constructor.Signature().Params().Len() {
-				param := constructor.Signature().Params().At(i)
-				name, typ := param.Name(), param.Type()
-				f := field{Type: types.TypeString(typ, qual)}
-				if i == 0 && isContextType(typ) {
-					f.Value = qual(types.NewPackage("context", "context")) + ".Background()"
-				} else if name == "" || name == "_" {
-					f.Value, _ = typesinternal.ZeroString(typ, qual)
-				} else {
-					f.Name = name
+				if f, ok := argField(constructor.Signature(), i, true); ok {
+					data.Receiver.Constructor.Args = append(data.Receiver.Constructor.Args, f)
 				}
-				data.Receiver.Constructor.Args = append(data.Receiver.Constructor.Args, f)
 			}
 			for i := range constructor.Signature().Results().Len() {
 				typ := constructor.Signature().Results().At(i).Type()
@@ -657,6 +2264,15 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 				})
 			}
 		}
+
+		if params.Options.ReceiverForms {
+			ptr := false // the receiver variable holds a *T
+			if constructor != nil {
+				_, ptr = constructor.Signature().Results().At(0).Type().(*types.Pointer)
+			}
+			data.Receiver.Forms = receiverForms(fn, varName, ptr, params.XTest, qual)
+			data.Receiver.FormType = types.TypeString(types.NewSignatureType(nil, nil, nil, sig.Params(), sig.Results(), sig.Variadic()), qual)
+		}
 	}
 
 	// Resolves duplicate parameter names between the function and its
@@ -695,71 +2311,188 @@ func AddTestForFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.
 		}
 	}
 
-	// Compute edits to update imports.
-	//
-	// If we're adding to an existing test file, we need to adjust existing
-	// imports. Otherwise, we can simply write out the imports to the new file.
-	if testPGF != nil {
-		var importFixes []*imports.ImportFix
-		for path, name := range extraImports {
-			importFixes = append(importFixes, &imports.ImportFix{
-				StmtInfo: imports.ImportInfo{
-					ImportPath: path,
-					Name:       name,
-				},
-				FixType: imports.AddImport,
-			})
+	// Prepare the input parameters once the names of the fields are final.
+	var args []field
+	if data.Receiver != nil && data.Receiver.Factory != nil && data.Receiver.Factory.Constructor != nil {
+		args = append(args, data.Receiver.Factory.Constructor.Args...)
+	}
+	if data.Receiver != nil && data.Receiver.Constructor != nil {
+		args = append(args, data.Receiver.Constructor.Args...)
+	}
+	args = append(args, data.Func.Args...)
+	if slices.ContainsFunc(args, func(f field) bool { return f.server }) {
+		data.Setup = append(data.Setup, serverStmt(qual))
+	}
+	for _, f := range args {
+		switch {
+		case f.mapFS:
+			data.Setup = append(data.Setup, mapFSStmt(f.Value, "tt."+f.Name, qual))
+		case f.sqlMock:
+			data.Setup = append(data.Setup, sqlMockStmt(f.Value, "tt."+f.Name, qual))
+		case f.Name != "" && f.Default != "":
+			data.Setup = append(data.Setup, fmt.Sprintf("if tt.%s == %s {\ntt.%[1]s = %[3]s\n}", f.Name, f.Unset, f.Default))
 		}
-		importEdits, err := ComputeImportFixEdits(snapshot.Options().Local, testPGF.Src, importFixes...)
-		if err != nil {
-			return nil, fmt.Errorf("could not compute the import fix edits: %w", err)
+	}
+
+	return data, nil
+}
+
+// resultFields returns the fields of a result of type t, as seen from
+// the test package, whose values a test compares one by one if t is a
+// struct type of package pkg, or nil otherwise.
+func resultFields(t types.Type, pkg *types.Package, xtest bool) []*types.Var {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() != pkg || named.TypeParams() != nil {
+		return nil
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var fields []*types.Var
+	for f := range st.Fields() {
+		if f.Name() != "_" && (!xtest || f.Exported()) {
+			fields = append(fields, f)
 		}
-		edits = append(edits, importEdits...)
-	} else {
-		var importsBuffer bytes.Buffer
-		if len(extraImports) == 1 {
-			importsBuffer.WriteString("\nimport ")
-			for path, name := range extraImports {
-				if name != "" {
-					importsBuffer.WriteString(name + " ")
-				}
-				importsBuffer.WriteString(fmt.Sprintf("\"%s\"\n", path))
-			}
-		} else {
-			importsBuffer.WriteString("\nimport(")
-			// Sort for determinism.
-			for path, name := range moremaps.Sorted(extraImports) {
-				importsBuffer.WriteString("\n\t")
-				if name != "" {
-					importsBuffer.WriteString(name + " ")
-				}
-				importsBuffer.WriteString(fmt.Sprintf("\"%s\"", path))
+	}
+	return fields
+}
+
+// errorTarget returns an example of the targets of errors.As for the
+// error types of package pkg, such as new(*NotFoundError), or "" if it
+// declares no exported error types: non-generic named types other than
+// interfaces whose values, or pointers to them, implement error.
+func errorTarget(pkg *types.Package, qual types.Qualifier) string {
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		tname, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tname.IsAlias() || !tname.Exported() {
+			continue
+		}
+		named, ok := tname.Type().(*types.Named)
+		if !ok || named.TypeParams() != nil || types.IsInterface(named) {
+			continue
+		}
+		var t types.Type = named
+		if !types.Implements(t, errorType) {
+			t = types.NewPointer(named)
+			if !types.Implements(t, errorType) {
+				continue
 			}
-			importsBuffer.WriteString("\n)\n")
 		}
-		edits = append(edits, protocol.TextEdit{
-			Range:   protocol.Range{},
-			NewText: importsBuffer.String(),
-		})
+		return "new(" + types.TypeString(t, qual) + ")"
 	}
+	return ""
+}
 
-	var test bytes.Buffer
-	if err := testTmpl.Execute(&test, data); err != nil {
-		return nil, err
+// collectIter returns the expression that collects the values of the
+// iterator seq of type t into a slice, if t is an iter.Seq[V], or into
+// a map, if t is an iter.Seq2[K, V] whose key type K is comparable,
+// and the field type of the collection, []V or map[K]V. It reports
+// false if t is neither.
+func collectIter(t types.Type, seq string, qual types.Qualifier) (field, bool) {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != "iter" {
+		return field{}, false
+	}
+	targs := named.TypeArgs()
+	switch named.Obj().Name() {
+	case "Seq":
+		if targs.Len() == 1 {
+			return field{
+				Type:  "[]" + types.TypeString(targs.At(0), qual),
+				Value: qual(types.NewPackage("slices", "slices")) + ".Collect(" + seq + ")",
+			}, true
+		}
+	case "Seq2":
+		if targs.Len() == 2 && types.Comparable(targs.At(0)) {
+			return field{
+				Type:  "map[" + types.TypeString(targs.At(0), qual) + "]" + types.TypeString(targs.At(1), qual),
+				Value: qual(types.NewPackage("maps", "maps")) + ".Collect(" + seq + ")",
+			}, true
+		}
 	}
+	return field{}, false
+}
 
-	formatted, err := format.Source(test.Bytes())
-	if err != nil {
-		return nil, err
+// receiverForms returns the forms through which a test calls the
+// method fn, given the variable v of the receiver, which holds a *T if
+// ptr is set: T, *T, and each interface of the package of fn,
+// accessible from the test package, that T implements and that has
+// the method, if the receiver of fn has a value type T; or else *T and
+// each such interface that *T implements, as only a *T has the method.
+func receiverForms(fn *types.Func, v string, ptr, xtest bool, qual types.Qualifier) []receiverForm {
+	recvType := fn.Signature().Recv().Type()
+	value, addr := v, "&"+v // the receiver as a T and as a *T
+	if ptr {
+		value, addr = "*"+v, v
 	}
+	method := func(x string) string {
+		if x != v {
+			x = "(" + x + ")"
+		}
+		return x + "." + fn.Name()
+	}
+	var forms []receiverForm
+	conv := value // the operand of a conversion to an interface
+	if _, ok := recvType.(*types.Pointer); ok {
+		forms = append(forms, receiverForm{Name: types.TypeString(recvType, qual), Method: method(addr)})
+		conv = addr
+	} else {
+		name := types.TypeString(recvType, qual)
+		forms = append(forms,
+			receiverForm{Name: name, Method: method(value)},
+			receiverForm{Name: "*" + name, Method: method(addr)})
+	}
+	scope := fn.Pkg().Scope()
+	for _, n := range scope.Names() {
+		tname, ok := scope.Lookup(n).(*types.TypeName)
+		if !ok || tname.IsAlias() || xtest && !tname.Exported() {
+			continue
+		}
+		named, ok := tname.Type().(*types.Named)
+		if !ok || named.TypeParams() != nil {
+			continue
+		}
+		iface, ok := named.Underlying().(*types.Interface)
+		if !ok || !types.Implements(recvType, iface) {
+			continue
+		}
+		if obj, _, _ := types.LookupFieldOrMethod(named, false, fn.Pkg(), fn.Name()); obj == nil {
+			continue
+		}
+		iname := types.TypeString(named, qual)
+		forms = append(forms, receiverForm{Name: iname, Method: iname + "(" + conv + ")." + fn.Name()})
+	}
+	return forms
+}
 
-	edits = append(edits,
-		protocol.TextEdit{
-			Range:   eofRange,
-			NewText: string(formatted),
-		})
+// receiverConstructor returns the first constructor among ctors, the
+// constructor index of package pkg, of the named receiver type that
+// is accessible from the test package, or nil if there is none.
+func receiverConstructor(ctors *constructors.Index, pkg *types.Package, named *types.Named, xtest bool) *types.Func {
+	for _, f := range ctors.Constructors(pkg, named.Obj()) {
+		// Unexported constructor is not visible in x_test package.
+		if !xtest || f.Exported() {
+			return f
+		}
+	}
+	return nil
+}
 
-	return append(changes, protocol.DocumentChangeEdit(testFH, edits)), nil
+// receiverFactory returns the first factory method among ctors, the
+// constructor index of package pkg, of the named receiver type whose
+// type and method are accessible from the test package, or nil if there
+// is none.
+func receiverFactory(ctors *constructors.Index, pkg *types.Package, named *types.Named, xtest bool) *types.Func {
+	for _, m := range ctors.Factories(pkg, named.Obj()) {
+		_, factory := typesinternal.ReceiverNamed(m.Signature().Recv())
+		if !xtest || m.Exported() && factory.Obj().Exported() {
+			return m
+		}
+	}
+	return nil
 }
 
 // testName returns the name of the function to use for the new function that
@@ -0,0 +1,65 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+)
+
+func TestTestSnippetEdit(t *testing.T) {
+	const test = `
+func TestT_M(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		want int
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// TODO: construct the receiver type.
+			var x T
+			got := x.M()
+			// TODO: update the condition below to compare got with tt.want.
+			if true {
+				t.Errorf("M() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+`
+	// Closing braces outside placeholders are escaped.
+	const want = `
+func TestT_M(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		want int
+	\}{
+		${1:// TODO: Add test cases.}
+	\}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			${2:// TODO: construct the receiver type.}
+			var x T
+			got := x.M()
+			// TODO: update the condition below to compare got with tt.want.
+			if ${3:true} {
+				t.Errorf("M() = %v, want %v", got, tt.want)
+			\}
+		\})
+	\}
+\}
+`
+	rng := protocol.Range{Start: protocol.Position{Line: 3}, End: protocol.Position{Line: 3}}
+	got := testSnippetEdit(protocol.TextEdit{Range: rng, NewText: test})
+	if got.Range != rng || got.Snippet.Kind != "snippet" {
+		t.Errorf("testSnippetEdit returned range %v and kind %q, want %v and \"snippet\"", got.Range, got.Snippet.Kind, rng)
+	}
+	if got.Snippet.Value != want {
+		t.Errorf("testSnippetEdit returned snippet:\n%s\nwant:\n%s", got.Snippet.Value, want)
+	}
+}
@@ -0,0 +1,482 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the analysis of the tests affected by changes.
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/util/moremaps"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// AffectedTests returns the tests of the workspace packages that
+// changes to the specified files may affect, by package, in order of
+// package path; packages with no affected tests are omitted. If uris
+// is empty, the changes are the unsaved changes of the open files,
+// and only the declarations they change are considered changed;
+// otherwise all the declarations of each file are.
+//
+// The analysis is approximate but conservative for changes that can
+// be traced to declarations:
+//   - a change to a Test, Fuzz, or Example function affects it;
+//   - a change to another declaration of a test file affects all the
+//     tests of its package;
+//   - a change to a function or method affects the tests that exercise
+//     it or a function of its package that calls it, directly or
+//     transitively (see [testfuncs.Subjects]), and, if any of them is
+//     exported, all the tests of the packages that import the package;
+//   - any other change to a Go file, such as to a type, a variable, or
+//     an init function, affects all the tests of its package and of
+//     the packages that import it; and
+//   - a change to another file affects all the tests of the packages
+//     in its directory tree, if it is a go.mod, go.sum, or go.work
+//     file, or otherwise of the packages whose directory contains it,
+//     such as their testdata.
+//
+// Calls through interfaces are assumed to reach every method of the
+// same name, but calls made by other packages, such as fmt calling a
+// String method, are not considered.
+func AffectedTests(ctx context.Context, snapshot *cache.Snapshot, uris []protocol.DocumentURI) ([]command.AffectedPackage, error) {
+	mps, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a := &affectedTests{
+		snapshot: snapshot,
+		dirs:     make(map[PackagePath]string),
+		pkgs:     make(map[PackagePath]*affectedPackage),
+	}
+	for _, mp := range mps {
+		if len(mp.GoFiles) > 0 {
+			a.dirs[underTest(mp)] = mp.GoFiles[0].DirPath()
+		}
+	}
+
+	// The old contents of the changed files, if known.
+	old := make(map[protocol.DocumentURI][]byte)
+	if len(uris) == 0 {
+		for _, o := range snapshot.Overlays() {
+			if o.SameContentsOnDisk() {
+				continue
+			}
+			uris = append(uris, o.URI())
+			if data, err := os.ReadFile(o.URI().Path()); err == nil {
+				old[o.URI()] = data
+			}
+		}
+	}
+
+	for _, uri := range uris {
+		if err := a.change(ctx, uri, old[uri]); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []command.AffectedPackage
+	for path, ap := range moremaps.Sorted(a.pkgs) {
+		rel, err := snapshot.TestRelation(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		pkg := command.AffectedPackage{
+			PkgPath: string(path),
+			Dir:     protocol.URIFromPath(a.dirs[path]),
+			All:     true,
+		}
+		for _, test := range rel.Tests() {
+			if benchmarkRe.MatchString(test.Name) || slices.Contains(pkg.Tests, test.Name) {
+				continue
+			}
+			if ap.all || ap.tests[test.Name] {
+				pkg.Tests = append(pkg.Tests, test.Name)
+			} else {
+				pkg.All = false
+			}
+		}
+		if len(pkg.Tests) > 0 {
+			result = append(result, pkg)
+		}
+	}
+	return result, nil
+}
+
+// underTest returns the path of the package under test of the package
+// mp, which is its own path unless it is a test variant or an external
+// test package.
+func underTest(mp *metadata.Package) PackagePath {
+	if mp.ForTest != "" {
+		return mp.ForTest
+	}
+	return mp.PkgPath
+}
+
+// affectedTests accumulates the tests affected by changes, by the
+// path of their package under test; see [AffectedTests].
+type affectedTests struct {
+	snapshot *cache.Snapshot
+	dirs     map[PackagePath]string // directory of each workspace package
+	pkgs     map[PackagePath]*affectedPackage
+}
+
+// An affectedPackage records the affected tests of a package.
+type affectedPackage struct {
+	all   bool            // all the tests are affected
+	tests map[string]bool // names of the affected tests, unless all
+}
+
+// add records that the tests of the specified names of the package
+// path are affected. It ignores packages outside the workspace.
+func (a *affectedTests) add(path PackagePath, tests ...string) {
+	if _, ok := a.dirs[path]; !ok {
+		return
+	}
+	ap := a.pkgs[path]
+	if ap == nil {
+		ap = &affectedPackage{tests: make(map[string]bool)}
+		a.pkgs[path] = ap
+	}
+	for _, test := range tests {
+		ap.tests[test] = true
+	}
+}
+
+// addAll records that all the tests of the package path are affected.
+func (a *affectedTests) addAll(path PackagePath) {
+	a.add(path)
+	if ap := a.pkgs[path]; ap != nil {
+		ap.all = true
+	}
+}
+
+// addPackage records that all the tests of the package mp, of path
+// path, and of the workspace packages that import it are affected.
+func (a *affectedTests) addPackage(ctx context.Context, path PackagePath, mp *metadata.Package) error {
+	a.addAll(path)
+	if mp == nil {
+		return nil
+	}
+	return a.addImporters(ctx, path, mp)
+}
+
+// addImporters records that all the tests of the workspace packages
+// that import the package mp, of path path, directly or transitively,
+// are affected.
+func (a *affectedTests) addImporters(ctx context.Context, path PackagePath, mp *metadata.Package) error {
+	rdeps, err := a.snapshot.ReverseDependencies(ctx, mp.ID, true)
+	if err != nil {
+		return err
+	}
+	for _, rdep := range rdeps {
+		if rdepPath := underTest(rdep); rdepPath != path {
+			a.addAll(rdepPath)
+		}
+	}
+	return nil
+}
+
+// change records the tests affected by the change to the file uri,
+// whose old contents are old, if known.
+func (a *affectedTests) change(ctx context.Context, uri protocol.DocumentURI, old []byte) error {
+	dir := uri.DirPath()
+	if !strings.HasSuffix(uri.Path(), ".go") {
+		// Module files affect the packages of their tree; other files,
+		// such as testdata or embedded files, those that contain them.
+		switch filepath.Base(uri.Path()) {
+		case "go.mod", "go.sum", "go.work", "go.work.sum":
+			for path, pkgDir := range a.dirs {
+				if pkgDir == dir || strings.HasPrefix(pkgDir, dir+string(filepath.Separator)) {
+					a.addAll(path)
+				}
+			}
+			return nil
+		}
+		for path, pkgDir := range a.dirs {
+			if dir == pkgDir || strings.HasPrefix(dir, pkgDir+string(filepath.Separator)) {
+				if err := a.addPackage(ctx, path, a.metadata(path)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	mps, err := a.snapshot.MetadataForFile(ctx, uri)
+	if err != nil {
+		return err
+	}
+	if len(mps) == 0 {
+		// A deleted file, or one that no package includes.
+		for path, pkgDir := range a.dirs {
+			if dir == pkgDir {
+				if err := a.addPackage(ctx, path, a.metadata(path)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	path := underTest(mps[0])
+
+	fh, err := a.snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return err
+	}
+	pgf, err := a.snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return err
+	}
+	funcs, others, err := changedDecls(pgf, old)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(uri.Path(), "_test.go") {
+		// Only the changed tests are affected, unless the change is
+		// to a declaration that tests may share, such as a helper.
+		rel, err := a.snapshot.TestRelation(ctx, path)
+		if err != nil {
+			return err
+		}
+		for _, name := range funcs {
+			if benchmarkRe.MatchString(name) {
+				continue
+			}
+			if !slices.ContainsFunc(rel.Tests(), func(test testfuncs.Result) bool { return test.Name == name }) {
+				others = true
+				break
+			}
+			a.add(path, name)
+		}
+		if others {
+			a.addAll(path)
+		}
+		return nil
+	}
+
+	// The file belongs to the package under test, whose narrowest
+	// variant is not a test variant.
+	mp := mps[0]
+	if mp.ForTest != "" {
+		a.addAll(path)
+		return nil
+	}
+	if others {
+		return a.addPackage(ctx, path, mp)
+	}
+	if len(funcs) == 0 {
+		return nil
+	}
+	pkgs, err := a.snapshot.TypeCheck(ctx, mp.ID)
+	if err != nil {
+		return err
+	}
+	callers := callGraph(pkgs[0])
+
+	// Visit the changed functions and their callers, transitively.
+	var (
+		seen    = make(map[string]bool)
+		visit   func(name string)
+		unknown bool // a function is referenced outside of functions
+	)
+	visit = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		next := callers[name]
+		if _, method, ok := strings.Cut(name, "."); ok {
+			next = append(slices.Clip(next), callers["."+method]...) // calls through interfaces
+		}
+		for _, caller := range next {
+			if caller == "" {
+				unknown = true
+			} else {
+				visit(caller)
+			}
+		}
+	}
+	for _, name := range funcs {
+		visit(name)
+	}
+	if unknown {
+		return a.addPackage(ctx, path, mp)
+	}
+
+	rel, err := a.snapshot.TestRelation(ctx, path)
+	if err != nil {
+		return err
+	}
+	exported := false
+	for name := range seen {
+		a.add(path, testsOf(rel, name)...)
+		_, last, _ := strings.Cut(name, ".")
+		if last == "" {
+			last = name
+		}
+		exported = exported || token.IsExported(last)
+	}
+	if exported {
+		return a.addImporters(ctx, path, mp)
+	}
+	return nil
+}
+
+// metadata returns the metadata of the non-test variant of the
+// workspace package path, or nil if it has none.
+func (a *affectedTests) metadata(path PackagePath) *metadata.Package {
+	for _, mp := range a.snapshot.MetadataGraph().Packages {
+		if mp.PkgPath == path && mp.ForTest == "" {
+			return mp
+		}
+	}
+	return nil
+}
+
+// changedDecls returns the names, F or T.M, of the functions and
+// methods changed, added, or removed by the change to the file pgf
+// whose old contents are old, if known, or otherwise of all of them,
+// and reports whether the change affects other declarations, such as
+// types, variables, init functions, or blank imports. Changes to doc
+// comments are ignored.
+func changedDecls(pgf *parsego.File, old []byte) (funcs []string, others bool, _ error) {
+	cur, curOthers, err := declSources(pgf.File, pgf.Tok, pgf.Src)
+	if err != nil {
+		return nil, false, err
+	}
+	var prev map[string]string
+	prevOthers := make(map[string]bool)
+	if old != nil {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, pgf.URI.Path(), old, parser.SkipObjectResolution)
+		if err == nil {
+			prev, prevOthers, err = declSources(f, fset.File(f.FileStart), old)
+		}
+		if err != nil {
+			old = nil // unparsable: assume all changed
+		}
+	}
+
+	for name, src := range cur {
+		if old == nil || prev[name] != src {
+			funcs = append(funcs, name)
+		}
+	}
+	for name := range prev {
+		if _, ok := cur[name]; !ok {
+			funcs = append(funcs, name)
+		}
+	}
+	sort.Strings(funcs)
+	if old == nil {
+		others = len(curOthers) > 0
+	} else {
+		others = len(curOthers) != len(prevOthers)
+		for src := range curOthers {
+			others = others || !prevOthers[src]
+		}
+	}
+	return funcs, others, nil
+}
+
+// declSources returns the source of each testable function and method
+// declared by the file f (see [testableName]), by name, and the set of
+// the sources of its other declarations, other than non-blank imports,
+// whose effects are those of the declarations that use them.
+func declSources(f *ast.File, tok *token.File, src []byte) (funcs map[string]string, others map[string]bool, err error) {
+	funcs = make(map[string]string)
+	others = make(map[string]bool)
+	text := func(n ast.Node) string {
+		start, end, err1 := safetoken.Offsets(tok, n.Pos(), n.End())
+		if err1 != nil {
+			err = err1
+			return ""
+		}
+		return string(src[start:end])
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if name, ok := testableName(f, decl); ok {
+				funcs[name] = text(decl) // (excludes the doc comment)
+			} else {
+				others[text(decl)] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if imp, ok := spec.(*ast.ImportSpec); ok && (imp.Name == nil || imp.Name.Name != "_") {
+					continue
+				}
+				others[decl.Tok.String()+" "+text(spec)] = true
+			}
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return funcs, others, nil
+}
+
+// callGraph returns the callers within the package pkg of each of its
+// functions and methods, by name (see [testableName]), where a method
+// is also listed by its name alone, as .M, for calls through
+// interfaces. A caller is "" if the function is referenced outside of
+// a testable function, such as in the initializer of a variable.
+func callGraph(pkg *cache.Package) map[string][]string {
+	callers := make(map[string][]string)
+	info := pkg.TypesInfo()
+	for _, f := range pkg.Syntax() {
+		for _, decl := range f.Decls {
+			caller := ""
+			if decl, ok := decl.(*ast.FuncDecl); ok {
+				if name, ok := testableName(f, decl); ok {
+					caller = name
+				}
+			}
+			ast.Inspect(decl, func(n ast.Node) bool {
+				id, ok := n.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				fn, ok := info.Uses[id].(*types.Func)
+				if !ok || fn.Pkg() != pkg.Types() {
+					return true
+				}
+				fn = fn.Origin()
+				callee := fn.Name()
+				if recv := fn.Signature().Recv(); recv != nil {
+					_, named := typesinternal.ReceiverNamed(recv)
+					if named == nil {
+						return true
+					}
+					callee = named.Obj().Name() + "." + fn.Name()
+					callers["."+fn.Name()] = append(callers["."+fn.Name()], caller)
+				}
+				if callee != caller {
+					callers[callee] = append(callers[callee], caller)
+				}
+				return true
+			})
+		}
+	}
+	return callers
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+)
+
+func TestChangedDecls(t *testing.T) {
+	const old = `package p
+
+import "fmt"
+
+type T int
+
+// F does nothing.
+func F() {}
+
+func G() { fmt.Println() }
+
+func (T) M() {}
+
+func Removed() {}
+`
+	for _, test := range []struct {
+		name       string
+		src        string
+		old        string
+		wantFuncs  []string
+		wantOthers bool
+	}{
+		{"unchanged", old, old, nil, false},
+		{"doc comment", replace(old, "does nothing", "is a no-op"), old, nil, false},
+		{"bodies", replace(replace(old, "func F() {}", "func F() { G() }"), "func (T) M() {}", "func (T) M() { F() }"), old, []string{"F", "T.M"}, false},
+		{"added and removed", replace(old, "func Removed() {}", "func Added() {}"), old, []string{"Added", "Removed"}, false},
+		{"import", replace(old, `import "fmt"`, `import "fmt"; import "os"`), old, nil, false},
+		{"blank import", replace(old, `import "fmt"`, `import "fmt"; import _ "os"`), old, nil, true},
+		{"type", replace(old, "type T int", "type T string"), old, nil, true},
+		{"unknown", old, "", []string{"F", "G", "Removed", "T.M"}, true},
+	} {
+		uri := protocol.URIFromPath("/p/p.go")
+		pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), uri, []byte(test.src), parsego.Full, false)
+		var prev []byte
+		if test.old != "" {
+			prev = []byte(test.old)
+		}
+		funcs, others, err := changedDecls(pgf, prev)
+		if err != nil {
+			t.Fatalf("%s: changedDecls failed: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(funcs, test.wantFuncs) || others != test.wantOthers {
+			t.Errorf("%s: changedDecls = %q, %t, want %q, %t", test.name, funcs, others, test.wantFuncs, test.wantOthers)
+		}
+	}
+}
+
+// replace returns s with its first occurrence of old replaced by new.
+func replace(s, old, new string) string {
+	return strings.Replace(s, old, new, 1)
+}
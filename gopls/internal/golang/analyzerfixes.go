@@ -0,0 +1,212 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the bulk application of analyzer fixes
+// used by the 'gopls fix' subcommand.
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/internal/diff"
+)
+
+// ApplyAnalyzerFixes runs the enabled analyzers over the packages
+// specified by args and returns the changes that apply the first
+// suggested fix of each selected diagnostic.
+//
+// Fixes are applied in order of position; a fix whose edits conflict
+// with those of an earlier fix is skipped entirely, as in 'go vet -fix'.
+// Identical edits, such as the same import added by two fixes, are
+// coalesced.
+func ApplyAnalyzerFixes(ctx context.Context, snapshot *cache.Snapshot, args command.ApplyAnalyzerFixesArgs) ([]protocol.DocumentChange, command.ApplyAnalyzerFixesResult, error) {
+	var result command.ApplyAnalyzerFixesResult
+
+	// Select the packages.
+	uri := args.URI
+	var mps []*metadata.Package
+	if args.Recursive {
+		var err error
+		mps, err = snapshot.WorkspaceMetadata(ctx)
+		if err != nil {
+			return nil, result, err
+		}
+	} else {
+		mps = slices.Collect(maps.Values(snapshot.MetadataGraph().Packages))
+	}
+	pkgs := make(map[metadata.PackageID]*metadata.Package)
+	for _, mp := range mps {
+		if metadata.IsCommandLineArguments(mp.ID) {
+			continue
+		}
+		for _, f := range mp.CompiledGoFiles {
+			if f == uri || f.Dir() == uri || args.Recursive && uri.Encloses(f) {
+				pkgs[mp.ID] = mp
+				break
+			}
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil, result, fmt.Errorf("no packages in %s", uri)
+	}
+
+	diags, err := snapshot.Analyze(ctx, pkgs, nil)
+	if err != nil {
+		return nil, result, err
+	}
+
+	// Select the diagnostics, discarding duplicates reported for
+	// the test variants of a package, and order them by position.
+	type key struct {
+		uri     protocol.DocumentURI
+		rng     protocol.Range
+		source  cache.DiagnosticSource
+		message string
+	}
+	seen := make(map[key]bool)
+	var selected []*cache.Diagnostic
+	for _, diag := range diags {
+		if len(diag.SuggestedFixes) == 0 ||
+			len(args.Analyzers) > 0 && !slices.Contains(args.Analyzers, string(diag.Source)) ||
+			len(args.Categories) > 0 && !slices.Contains(args.Categories, diag.Code) {
+			continue
+		}
+		if filepath.Ext(uri.Path()) == ".go" && diag.URI != uri {
+			continue
+		}
+		k := key{diag.URI, diag.Range, diag.Source, diag.Message}
+		if !seen[k] {
+			seen[k] = true
+			selected = append(selected, diag)
+		}
+	}
+	slices.SortFunc(selected, func(x, y *cache.Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(x.URI, y.URI),
+			protocol.CompareRange(x.Range, y.Range),
+			cmp.Compare(x.Source, y.Source),
+			cmp.Compare(x.Message, y.Message))
+	})
+
+	// Merge the edits of the fixes, file by file.
+	type fileEdits struct {
+		fh     file.Handle
+		mapper *protocol.Mapper
+		edits  []diff.Edit
+	}
+	files := make(map[protocol.DocumentURI]*fileEdits)
+	getFile := func(uri protocol.DocumentURI) (*fileEdits, error) {
+		f, ok := files[uri]
+		if !ok {
+			fh, err := snapshot.ReadFile(ctx, uri)
+			if err != nil {
+				return nil, err
+			}
+			content, err := fh.Content()
+			if err != nil {
+				return nil, err
+			}
+			f = &fileEdits{fh: fh, mapper: protocol.NewMapper(uri, content)}
+			files[uri] = f
+		}
+		return f, nil
+	}
+nextFix:
+	for _, diag := range selected {
+		edits, ok, err := fixEdits(ctx, snapshot, diag.SuggestedFixes[0])
+		if err != nil {
+			return nil, result, err
+		}
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		merged := make(map[protocol.DocumentURI][]diff.Edit)
+		for uri, textEdits := range edits {
+			f, err := getFile(uri)
+			if err != nil {
+				return nil, result, err
+			}
+			diffEdits, err := protocol.EditsToDiffEdits(f.mapper, textEdits)
+			if err != nil {
+				return nil, result, err
+			}
+			diff.SortEdits(diffEdits)
+			m, ok := diff.Merge(f.edits, diffEdits)
+			if !ok {
+				result.Conflicts++
+				continue nextFix
+			}
+			merged[uri] = m
+		}
+		for uri, m := range merged {
+			files[uri].edits = m
+		}
+		result.Applied++
+	}
+
+	var changes []protocol.DocumentChange
+	for _, uri := range slices.Sorted(maps.Keys(files)) {
+		f := files[uri]
+		if len(f.edits) == 0 {
+			continue
+		}
+		textEdits, err := protocol.EditsFromDiffEdits(f.mapper, f.edits)
+		if err != nil {
+			return nil, result, err
+		}
+		changes = append(changes, protocol.DocumentChangeEdit(f.fh, textEdits))
+	}
+	return changes, result, nil
+}
+
+// fixEdits returns the edits of the suggested fix, computing them if
+// the fix is lazy. It reports false if the fix cannot be applied in
+// bulk, because it is neither an edit nor an ApplyFix command, or
+// because it creates, renames, or deletes files.
+func fixEdits(ctx context.Context, snapshot *cache.Snapshot, fix cache.SuggestedFix) (map[protocol.DocumentURI][]protocol.TextEdit, bool, error) {
+	if fix.Command == nil {
+		return fix.Edits, true, nil
+	}
+	if fix.Command.Command != command.ApplyFix.String() {
+		return nil, false, nil
+	}
+	var args command.ApplyFixArgs
+	if err := command.UnmarshalArgs(fix.Command.Arguments, &args); err != nil {
+		return nil, false, err
+	}
+	fh, err := snapshot.ReadFile(ctx, args.Location.URI)
+	if err != nil {
+		return nil, false, err
+	}
+	changes, err := ApplyFix(ctx, args.Fix, snapshot, fh, args.Location.Range)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false, ctx.Err()
+		}
+		// The fix is not applicable in this state,
+		// such as a test for a function that already has one.
+		return nil, false, nil
+	}
+	edits := make(map[protocol.DocumentURI][]protocol.TextEdit)
+	for _, change := range changes {
+		if change.TextDocumentEdit == nil {
+			return nil, false, nil
+		}
+		uri := change.TextDocumentEdit.TextDocument.URI
+		edits[uri] = append(edits[uri], protocol.AsTextEdits(change.TextDocumentEdit.Edits)...)
+	}
+	return edits, true, nil
+}
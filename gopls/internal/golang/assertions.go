@@ -0,0 +1,275 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the styles of the assertions of generated tests.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// An AssertionStyle renders the statements of a generated test that
+// check the results of the function under test, in the style of an
+// existing test file: the standard library style, which compares
+// values with ordinary conditions, or that of an assertion package
+// or helper function that the file already uses.
+//
+// Each method renders statements of a subtest whose *testing.T is t,
+// and whose test case is tt; qual qualifies references to packages.
+type AssertionStyle interface {
+	// checkErr returns the statements that check the error gotErr
+	// returned by the function fn against tt.wantErr, returning
+	// from the subtest if an error occurred. If errType is set, they
+	// also check, with errors.As, that the error has the type of
+	// the target tt.wantErrType, if any.
+	checkErr(qual types.Qualifier, fn string, errType bool) string
+
+	// checkResults returns the statements that compare each result
+	// got[i] returned by the function fn with the wanted value want[i].
+	// If known is set, the wanted values are known to be comparable
+	// constants, such as those computed from the arguments of the
+	// test cases, so there is nothing for the user to complete.
+	checkResults(qual types.Qualifier, fn string, got, want []string, known bool) string
+
+	// checkField returns the statements that compare the field got,
+	// named name, of a struct result of the function fn with the
+	// wanted value want; comparable reports whether its type is
+	// comparable.
+	checkField(qual types.Qualifier, fn, name, got, want string, comparable bool) string
+}
+
+// AssertionStyleOf returns the style of the assertions of the
+// specified test file: that of testify if it imports one of the
+// testify assert and require packages, that of go-cmp if it imports
+// the cmp package, that of an assertion helper if it declares a
+// function such as assertEqual(t *testing.T, got, want any), or
+// otherwise the standard library style.
+func AssertionStyleOf(file *ast.File) AssertionStyle {
+	var assert, require, cmp *types.Package
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		switch path {
+		case "github.com/stretchr/testify/assert":
+			assert = types.NewPackage(path, "assert")
+		case "github.com/stretchr/testify/require":
+			require = types.NewPackage(path, "require")
+		case "github.com/google/go-cmp/cmp":
+			cmp = types.NewPackage(path, "cmp")
+		}
+	}
+	switch {
+	case assert != nil || require != nil:
+		// Prefer require for errors, which end the subtest,
+		// and assert for results, which need not.
+		style := testifyStyle{errPkg: require, resultPkg: assert}
+		if style.errPkg == nil {
+			style.errPkg = assert
+		}
+		if style.resultPkg == nil {
+			style.resultPkg = require
+		}
+		return style
+	case cmp != nil:
+		return cmpStyle{cmp}
+	}
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok {
+			if style, ok := helperStyleOf(decl); ok {
+				return style
+			}
+		}
+	}
+	return stdStyle{}
+}
+
+// stdStyle is the standard library style of assertions, which uses
+// conditions that the user must complete.
+type stdStyle struct{}
+
+func (stdStyle) checkErr(qual types.Qualifier, fn string, errType bool) string {
+	var as string
+	if errType {
+		as = fmt.Sprintf(`
+	if tt.wantErrType != nil && !%s.As(gotErr, tt.wantErrType) {
+		t.Errorf("%s() error = %%v, want a match for %%T", gotErr, tt.wantErrType)
+	}`, qual(types.NewPackage("errors", "errors")), fn)
+	}
+	return fmt.Sprintf(`if gotErr != nil {
+	if !tt.wantErr {
+		t.Errorf("%[1]s() failed: %%v", gotErr)
+	}%[2]s
+	return
+}
+if tt.wantErr {
+	t.Fatal("%[1]s() succeeded unexpectedly")
+}`, fn, as)
+}
+
+func (stdStyle) checkResults(qual types.Qualifier, fn string, got, want []string, known bool) string {
+	var b strings.Builder
+	if !known {
+		b.WriteString("// TODO: update the condition below to compare got with tt.want.\n")
+	}
+	for i := range got {
+		cond := "true"
+		if known {
+			cond = got[i] + " != " + want[i]
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, `if %s {
+	t.Errorf("%s() = %%v, want %%v", %s, %s)
+}`, cond, fn, got[i], want[i])
+	}
+	return b.String()
+}
+
+func (stdStyle) checkField(qual types.Qualifier, fn, name, got, want string, comparable bool) string {
+	cond := fmt.Sprintf("%s != %s", got, want)
+	if !comparable {
+		cond = fmt.Sprintf("!%s.DeepEqual(%s, %s)", qual(types.NewPackage("reflect", "reflect")), got, want)
+	}
+	return fmt.Sprintf(`if %s {
+	t.Errorf("%s().%s = %%v, want %%v", %s, %s)
+}`, cond, fn, name, got, want)
+}
+
+// testifyStyle is the style of the testify assert and require
+// packages.
+type testifyStyle struct {
+	errPkg, resultPkg *types.Package // used for errors and results
+}
+
+func (s testifyStyle) checkErr(qual types.Qualifier, fn string, errType bool) string {
+	pkg := qual(s.errPkg)
+	var as string
+	if errType {
+		as = fmt.Sprintf(`
+	if tt.wantErrType != nil {
+		%s.ErrorAs(t, gotErr, tt.wantErrType)
+	}`, pkg)
+	}
+	return fmt.Sprintf(`if tt.wantErr {
+	%[1]s.Error(t, gotErr)%[2]s
+	return
+}
+%[1]s.NoError(t, gotErr)`, pkg, as)
+}
+
+func (s testifyStyle) checkResults(qual types.Qualifier, fn string, got, want []string, known bool) string {
+	pkg := qual(s.resultPkg)
+	var lines []string
+	for i := range got {
+		lines = append(lines, fmt.Sprintf("%s.Equal(t, %s, %s)", pkg, want[i], got[i]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s testifyStyle) checkField(qual types.Qualifier, fn, name, got, want string, comparable bool) string {
+	return s.checkResults(qual, fn, []string{got}, []string{want}, false)
+}
+
+// cmpStyle is the style of the go-cmp package, which reports the
+// differences between results and the wanted values.
+type cmpStyle struct {
+	cmp *types.Package
+}
+
+func (s cmpStyle) checkErr(qual types.Qualifier, fn string, errType bool) string {
+	return stdStyle{}.checkErr(qual, fn, errType)
+}
+
+func (s cmpStyle) checkResults(qual types.Qualifier, fn string, got, want []string, known bool) string {
+	pkg := qual(s.cmp)
+	var lines []string
+	for i := range got {
+		lines = append(lines, fmt.Sprintf(`if diff := %s.Diff(%s, %s); diff != "" {
+	t.Errorf("%s() mismatch (-want +got):\n%%s", diff)
+}`, pkg, want[i], got[i], fn))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s cmpStyle) checkField(qual types.Qualifier, fn, name, got, want string, comparable bool) string {
+	return fmt.Sprintf(`if diff := %s.Diff(%s, %s); diff != "" {
+	t.Errorf("%s().%s mismatch (-want +got):\n%%s", diff)
+}`, qual(s.cmp), want, got, fn, name)
+}
+
+// helperStyle is the style of an assertion helper declared in the
+// test file, such as assertEqual(t, got, want).
+type helperStyle struct {
+	name      string
+	wantFirst bool // the helper takes the wanted value before the result
+}
+
+// helperStyleOf returns the style of the function declared by decl,
+// and reports whether it is an assertion helper: a function whose
+// name contains "Equal" or "equal", whose first parameter is a
+// *testing.T or testing.TB, and which takes two more values.
+func helperStyleOf(decl *ast.FuncDecl) (helperStyle, bool) {
+	if decl.Recv != nil || !strings.Contains(strings.ToLower(decl.Name.Name), "equal") {
+		return helperStyle{}, false
+	}
+	var names []string
+	var exprs []ast.Expr
+	for _, field := range decl.Type.Params.List {
+		if len(field.Names) == 0 {
+			names = append(names, "")
+			exprs = append(exprs, field.Type)
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+			exprs = append(exprs, field.Type)
+		}
+	}
+	if len(names) != 3 || !isTestingT(exprs[0]) {
+		return helperStyle{}, false
+	}
+	switch names[1] {
+	case "want", "wanted", "expected", "exp":
+		return helperStyle{name: decl.Name.Name, wantFirst: true}, true
+	}
+	return helperStyle{name: decl.Name.Name}, true
+}
+
+// isTestingT reports whether the type expression e denotes
+// *testing.T or testing.TB.
+func isTestingT(e ast.Expr) bool {
+	if star, ok := e.(*ast.StarExpr); ok {
+		sel, ok := star.X.(*ast.SelectorExpr)
+		return ok && isIdent(sel.X, "testing") && sel.Sel.Name == "T"
+	}
+	sel, ok := e.(*ast.SelectorExpr)
+	return ok && isIdent(sel.X, "testing") && sel.Sel.Name == "TB"
+}
+
+func (s helperStyle) checkErr(qual types.Qualifier, fn string, errType bool) string {
+	return stdStyle{}.checkErr(qual, fn, errType)
+}
+
+func (s helperStyle) checkResults(qual types.Qualifier, fn string, got, want []string, known bool) string {
+	var lines []string
+	for i := range got {
+		x, y := got[i], want[i]
+		if s.wantFirst {
+			x, y = y, x
+		}
+		lines = append(lines, fmt.Sprintf("%s(t, %s, %s)", s.name, x, y))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s helperStyle) checkField(qual types.Qualifier, fn, name, got, want string, comparable bool) string {
+	return s.checkResults(qual, fn, []string{got}, []string{want}, false)
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the recording and comparison of benchmark results.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+)
+
+// benchmarkCount is the number of times that the RunBenchmark command
+// runs a benchmark, so that the variation of its measurements may be
+// estimated.
+const benchmarkCount = 5
+
+// BenchmarkArgs returns the arguments of 'go test' that run the
+// Benchmark function name of the package pkgPath, and its
+// sub-benchmarks, for the RunBenchmark command.
+func BenchmarkArgs(pkgPath PackagePath, name string) []string {
+	return []string{
+		string(pkgPath),
+		"-run=^$",
+		"-bench=" + TestRunPattern(name),
+		"-benchmem",
+		fmt.Sprintf("-count=%d", benchmarkCount),
+	}
+}
+
+// benchmarkProcsRe matches the -GOMAXPROCS suffix of the name of a
+// benchmark in the output of 'go test -bench'.
+var benchmarkProcsRe = regexp.MustCompile(`-[0-9]+$`)
+
+// ParseBenchmarks returns the measurements of the Benchmark function
+// name and its sub-benchmarks reported by the output of 'go test
+// -bench', such as
+//
+//	BenchmarkFoo/small-8   1000   1234 ns/op   56 B/op   2 allocs/op
+//
+// or nil if there are none.
+func ParseBenchmarks(output []byte, name string) *cache.BenchmarkResult {
+	result := &cache.BenchmarkResult{Samples: make(map[string]map[string][]float64)}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || len(fields)%2 != 0 {
+			continue // not a result line
+		}
+		bench := benchmarkProcsRe.ReplaceAllString(fields[0], "")
+		if bench != name && !strings.HasPrefix(bench, name+"/") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue // not an iteration count
+		}
+		samples := result.Samples[bench]
+		for i := 2; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				break
+			}
+			if samples == nil {
+				samples = make(map[string][]float64)
+				result.Samples[bench] = samples
+				result.Names = append(result.Names, bench)
+			}
+			unit := fields[i+1]
+			samples[unit] = append(samples[unit], v)
+		}
+	}
+	if len(result.Names) == 0 {
+		return nil
+	}
+	return result
+}
+
+// CompareBenchmarks compares the measurements of each metric of each
+// benchmark of the result last with those of the result prev, which
+// may be nil, in the manner of benchstat.
+func CompareBenchmarks(prev, last *cache.BenchmarkResult) []command.BenchmarkMetric {
+	var metrics []command.BenchmarkMetric
+	for _, name := range last.Names {
+		samples := last.Samples[name]
+		for _, unit := range benchmarkUnits(samples) {
+			metric := command.BenchmarkMetric{
+				Name: name,
+				Unit: unit,
+				New:  summarizeBenchmark(samples[unit]),
+			}
+			if prev != nil && len(prev.Samples[name][unit]) > 0 {
+				old := summarizeBenchmark(prev.Samples[name][unit])
+				metric.Old = &old
+				if old.Median != 0 {
+					metric.Delta = (metric.New.Median - old.Median) / old.Median
+				}
+				metric.Significant = metric.Delta != 0 && (old.Max < metric.New.Min || metric.New.Max < old.Min)
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+// benchmarkUnits returns the units of the measurements of a benchmark,
+// with ns/op, B/op, and allocs/op first, as in the output of 'go test'.
+func benchmarkUnits(samples map[string][]float64) []string {
+	var units []string
+	for _, unit := range []string{"ns/op", "B/op", "allocs/op"} {
+		if _, ok := samples[unit]; ok {
+			units = append(units, unit)
+		}
+	}
+	var others []string
+	for unit := range samples {
+		if !slices.Contains(units, unit) {
+			others = append(others, unit)
+		}
+	}
+	slices.Sort(others)
+	return append(units, others...)
+}
+
+// summarizeBenchmark returns the summary of the specified measurements.
+func summarizeBenchmark(values []float64) command.BenchmarkSummary {
+	values = slices.Sorted(slices.Values(values))
+	n := len(values)
+	median := values[n/2]
+	if n%2 == 0 {
+		median = (values[n/2-1] + values[n/2]) / 2
+	}
+	summary := command.BenchmarkSummary{
+		Median: median,
+		Min:    values[0],
+		Max:    values[n-1],
+		N:      n,
+	}
+	if median != 0 {
+		summary.Variation = max(summary.Max-median, median-summary.Min) / math.Abs(median)
+	}
+	return summary
+}
+
+// FormatBenchmarkMetrics returns a table of the comparisons of the
+// metrics, in the manner of benchstat:
+//
+//	BenchmarkFoo  ns/op      1.234µs ±2%  1.100µs ±1%  -10.86%
+//	BenchmarkFoo  allocs/op  2 ±0%        2 ±0%        ~
+func FormatBenchmarkMetrics(metrics []command.BenchmarkMetric) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "benchmark\tunit\tprevious\tlast\tdelta\n")
+	for _, m := range metrics {
+		old, delta := "-", ""
+		if m.Old != nil {
+			old = formatBenchmarkSummary(m.Unit, *m.Old)
+			delta = formatBenchmarkDelta(m)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.Name, m.Unit, old, formatBenchmarkSummary(m.Unit, m.New), delta)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// formatBenchmarkSummary formats the median and variation of a
+// summary, such as 1.234µs ±2%.
+func formatBenchmarkSummary(unit string, s command.BenchmarkSummary) string {
+	return fmt.Sprintf("%s ±%.0f%%", formatBenchmarkValue(unit, s.Median), 100*s.Variation)
+}
+
+// formatBenchmarkDelta formats the change of a metric, such as -10.86%,
+// or ~ if it is not significant.
+func formatBenchmarkDelta(m command.BenchmarkMetric) string {
+	if !m.Significant {
+		return "~"
+	}
+	return fmt.Sprintf("%+.2f%%", 100*m.Delta)
+}
+
+// formatBenchmarkValue formats a measurement of the specified unit,
+// scaling times in ns/op to the most legible unit, such as 1.234µs.
+func formatBenchmarkValue(unit string, v float64) string {
+	if unit == "ns/op" {
+		switch {
+		case v >= 1e9:
+			return fmt.Sprintf("%.4gs", v/1e9)
+		case v >= 1e6:
+			return fmt.Sprintf("%.4gms", v/1e6)
+		case v >= 1e3:
+			return fmt.Sprintf("%.4gµs", v/1e3)
+		default:
+			return fmt.Sprintf("%.4gns", v)
+		}
+	}
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return fmt.Sprintf("%.4g", v)
+}
+
+// benchmarkSummary returns the summary of the recent results of a
+// Benchmark function shown by its code lens: the median of each metric
+// and its change since the previous run, if any, or, if the function
+// has sub-benchmarks, their number and the geometric mean of the
+// relative changes of their times.
+func benchmarkSummary(h *cache.BenchmarkHistory) string {
+	metrics := CompareBenchmarks(h.Previous, h.Last)
+	if len(h.Last.Names) > 1 {
+		var (
+			logs float64
+			n    int
+		)
+		for _, m := range metrics {
+			if m.Unit == "ns/op" && m.Old != nil && m.Old.Median > 0 && m.New.Median > 0 {
+				logs += math.Log(m.New.Median / m.Old.Median)
+				n++
+			}
+		}
+		summary := fmt.Sprintf("%d benchmarks", len(h.Last.Names))
+		if n > 0 {
+			summary += fmt.Sprintf(", time geomean %+.2f%%", 100*(math.Exp(logs/float64(n))-1))
+		}
+		return summary
+	}
+	var parts []string
+	for _, m := range metrics {
+		part := formatBenchmarkValue(m.Unit, m.New.Median)
+		if m.Unit == "ns/op" {
+			part += "/op"
+		} else {
+			part += " " + m.Unit
+		}
+		if m.Old != nil {
+			part += " (" + formatBenchmarkDelta(m) + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// benchmarkCodeLens annotates each Benchmark function of a _test.go
+// file with a command to run it and compare its measurements with
+// those of its previous run, whose title summarizes its recent
+// results, if any.
+func benchmarkCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	_, benchFuncs, err := testsAndBenchmarks(pkg.TypesInfo(), pgf)
+	if err != nil {
+		return nil, err
+	}
+	var codeLens []protocol.CodeLens
+	for _, fn := range benchFuncs {
+		title := "run and record benchmark"
+		if h := snapshot.Benchmark(underTest(pkg.Metadata()), fn.name); h != nil {
+			title = "rerun benchmark: " + benchmarkSummary(h)
+		}
+		cmd := command.NewRunBenchmarkCommand(title, command.RunBenchmarkArgs{
+			URI:       fh.URI(),
+			Benchmark: fn.name,
+		})
+		rng := protocol.Range{Start: fn.rng.Start, End: fn.rng.Start}
+		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: cmd})
+	}
+	return codeLens, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/cache"
+)
+
+func TestParseBenchmarks(t *testing.T) {
+	const output = `goos: linux
+goarch: amd64
+pkg: example.com/a
+BenchmarkFoo/small-8         	    1000	      1200 ns/op	      56 B/op	       2 allocs/op
+BenchmarkFoo/small-8         	    1000	      1300 ns/op	      56 B/op	       2 allocs/op
+BenchmarkFoo/large-8         	      10	    150000 ns/op	      12.5 MB/s
+BenchmarkFooBar-8            	    1000	      1000 ns/op
+BenchmarkFoo                 	--- FAIL: BenchmarkFoo/broken
+PASS
+ok  	example.com/a	3.142s
+`
+	got := ParseBenchmarks([]byte(output), "BenchmarkFoo")
+	want := &cache.BenchmarkResult{
+		Names: []string{"BenchmarkFoo/small", "BenchmarkFoo/large"},
+		Samples: map[string]map[string][]float64{
+			"BenchmarkFoo/small": {"ns/op": {1200, 1300}, "B/op": {56, 56}, "allocs/op": {2, 2}},
+			"BenchmarkFoo/large": {"ns/op": {150000}, "MB/s": {12.5}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseBenchmarks = %+v, want %+v", got, want)
+	}
+	if got := ParseBenchmarks([]byte("PASS\n"), "BenchmarkFoo"); got != nil {
+		t.Errorf("ParseBenchmarks of no results = %+v, want nil", got)
+	}
+}
+
+func TestCompareBenchmarks(t *testing.T) {
+	result := func(ns, allocs []float64) *cache.BenchmarkResult {
+		return &cache.BenchmarkResult{
+			Names:   []string{"BenchmarkFoo"},
+			Samples: map[string]map[string][]float64{"BenchmarkFoo": {"ns/op": ns, "allocs/op": allocs}},
+		}
+	}
+	prev := result([]float64{1240, 1200, 1250, 1260, 1230}, []float64{2, 2, 2})
+	last := result([]float64{1100, 1110, 1090, 1100, 1120}, []float64{2, 2, 2})
+
+	metrics := CompareBenchmarks(nil, prev)
+	if len(metrics) != 2 || metrics[0].Unit != "ns/op" || metrics[1].Unit != "allocs/op" || metrics[0].Old != nil {
+		t.Fatalf("CompareBenchmarks(nil, prev) = %+v, want ns/op and allocs/op without previous measurements", metrics)
+	}
+	if s := metrics[0].New; s.Median != 1240 || s.Min != 1200 || s.Max != 1260 || s.N != 5 {
+		t.Errorf("summary of prev = %+v", s)
+	}
+
+	metrics = CompareBenchmarks(prev, last)
+	const want = `benchmark     unit       previous    last       delta
+BenchmarkFoo  ns/op      1.24µs ±3%  1.1µs ±2%  -11.29%
+BenchmarkFoo  allocs/op  2 ±0%       2 ±0%      ~
+`
+	if got := FormatBenchmarkMetrics(metrics); got != want {
+		t.Errorf("FormatBenchmarkMetrics =\n%s\nwant:\n%s", got, want)
+	}
+	h := &cache.BenchmarkHistory{Previous: prev, Last: last}
+	if got, want := benchmarkSummary(h), "1.1µs/op (-11.29%), 2 allocs/op (~)"; got != want {
+		t.Errorf("benchmarkSummary = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the search for the arguments of the calls of a
+// function, with which generated tests are pre-populated (see
+// [TestOptions.CallSites]).
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/event"
+)
+
+// maxCallSiteCases is the maximum number of test cases that a
+// generated test derives from the call sites of its function.
+const maxCallSiteCases = 10
+
+// callSiteArgs returns the arguments of the calls of the function
+// declared by decl in the file pgf that the workspace makes outside of
+// its tests, in order of their location: each is the list of the
+// arguments of a call, in which an argument that is not a literal,
+// such as 1, -2.5, "a", or true, is "". Calls that pass no literal,
+// or that spread a slice over a variadic parameter, are omitted.
+//
+// The search is a convenience to the user, so a reference that cannot
+// be inspected is skipped rather than reported.
+func callSiteArgs(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pgf *parsego.File, decl *ast.FuncDecl) ([][]string, error) {
+	pos, err := pgf.PosPosition(decl.Name.Pos())
+	if err != nil {
+		return nil, err
+	}
+	refs, err := references(ctx, snapshot, fh, pos, false)
+	if err != nil {
+		return nil, err
+	}
+	var calls [][]string
+	for _, ref := range refs {
+		if strings.HasSuffix(ref.location.URI.Path(), "_test.go") {
+			continue
+		}
+		call, err := enclosingCall(ctx, snapshot, ref.location)
+		if err != nil {
+			event.Error(ctx, fmt.Sprintf("inspecting the call of %s", decl.Name.Name), err)
+			continue
+		}
+		if call == nil || call.Ellipsis.IsValid() {
+			continue
+		}
+		args := make([]string, len(call.Args))
+		literals := 0
+		for i, arg := range call.Args {
+			// A literal, or the constant true or false, keeps its
+			// meaning in a test.
+			arg = ast.Unparen(arg)
+			if id, ok := arg.(*ast.Ident); isLiteral(arg) || ok && (id.Name == "true" || id.Name == "false") {
+				args[i] = types.ExprString(arg)
+				literals++
+			}
+		}
+		if literals > 0 {
+			calls = append(calls, args)
+		}
+	}
+	return calls, nil
+}
+
+// enclosingCall returns the call expression whose function is the
+// identifier at the specified location, as in f(x) or pkg.f(x), or nil
+// if the identifier is not called.
+func enclosingCall(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) (*ast.CallExpr, error) {
+	fh, err := snapshot.ReadFile(ctx, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	if len(path) < 2 {
+		return nil, nil
+	}
+	id, ok := path[0].(*ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+	var fun ast.Expr = id
+	if sel, ok := path[1].(*ast.SelectorExpr); ok && sel.Sel == path[0] && len(path) > 2 {
+		fun, path = sel, path[1:]
+	}
+	if call, ok := path[1].(*ast.CallExpr); ok && call.Fun == fun {
+		return call, nil
+	}
+	return nil, nil
+}
+
+// A callSiteCase is a test case derived from a call of the tested
+// function (see [callSiteCases]).
+type callSiteCase struct {
+	name   string   // the call, with _ for the arguments that are not literals
+	call   []string // the arguments of the call, as for [callSiteArgs]
+	fields []string // key: value pairs of the fields set by the call
+}
+
+// literal returns the composite literal of the test case struct for
+// the test case, which sets the field want to the wanted value, if
+// any.
+func (c callSiteCase) literal(want, value string) string {
+	fields := c.fields
+	if value != "" {
+		fields = append(slices.Clip(fields), want+": "+value)
+	}
+	return fmt.Sprintf("{name: %q, %s},", c.name, strings.Join(fields, ", "))
+}
+
+// callSiteCases returns the test cases of a test of the function named
+// name, whose parameters have the specified fields, for the arguments
+// of its calls (see [callSiteArgs]): a case for each call whose
+// arguments for the fields are all literals, and differ from those of
+// the previous calls, up to [maxCallSiteCases].
+//
+// Fields derived from another value, and those of parameters that have
+// none, such as contexts, are not set by the cases.
+func callSiteCases(name string, args []field, calls [][]string) []callSiteCase {
+	var (
+		cases []callSiteCase
+		seen  = make(map[string]bool)
+	)
+	for _, call := range calls {
+		var (
+			values []string // key: value pairs of the test case
+			ok     = true
+		)
+		for i, f := range args {
+			if f.Name == "" || f.Value != "" {
+				continue // not a field of the test case, or derived
+			}
+			if f.Variadic {
+				if i > len(call) {
+					ok = false
+					break
+				}
+				rest := call[i:]
+				for _, arg := range rest {
+					if arg == "" {
+						ok = false
+					}
+				}
+				if len(rest) > 0 {
+					values = append(values, fmt.Sprintf("%s: %s{%s}", f.Name, f.Type, strings.Join(rest, ", ")))
+				}
+				continue
+			}
+			if i >= len(call) || call[i] == "" {
+				ok = false
+				break
+			}
+			values = append(values, fmt.Sprintf("%s: %s", f.Name, call[i]))
+		}
+		if !ok || len(values) == 0 {
+			continue
+		}
+		shown := make([]string, len(call))
+		for i, arg := range call {
+			shown[i] = arg
+			if arg == "" {
+				shown[i] = "_"
+			}
+		}
+		key := strings.Join(values, ", ")
+		if !seen[key] {
+			seen[key] = true
+			cases = append(cases, callSiteCase{
+				name:   name + "(" + strings.Join(shown, ", ") + ")",
+				call:   call,
+				fields: values,
+			})
+			if len(cases) == maxCallSiteCases {
+				break
+			}
+		}
+	}
+	return cases
+}
+
+// constantResult returns the expression that the function declared by
+// decl returns, if its body is a single return statement of a single
+// expression, such as x*x or min(max(x, lo), hi), whose result may be
+// a constant function of the arguments (see [evalResult]).
+func constantResult(decl *ast.FuncDecl) ast.Expr {
+	if decl.Body == nil || len(decl.Body.List) != 1 {
+		return nil
+	}
+	ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil
+	}
+	return ret.Results[0]
+}
+
+// evalResult returns the value, as a literal, of the expression result
+// that the function fn returns (see [constantResult]) for the arguments
+// of a call (see [callSiteArgs]), or false if it is not a constant of a
+// basic type, as when it depends on an argument that is not a literal,
+// or calls a function other than a builtin such as min or len.
+//
+// The expression is evaluated in the scope of the package of fn, with
+// each parameter replaced by its argument converted to the type of the
+// parameter, so that, for example, x/2 is 2 for x := 5, and the value
+// is converted to the type of the result.
+func evalResult(fn *types.Func, result ast.Expr, call []string) (string, bool) {
+	sig := fn.Signature()
+	if result == nil || sig.Results().Len() != 1 || sig.Variadic() {
+		return "", false
+	}
+	basic, ok := sig.Results().At(0).Type().Underlying().(*types.Basic)
+	if !ok || basic.Info()&(types.IsBoolean|types.IsNumeric|types.IsString) == 0 || basic.Info()&types.IsComplex != 0 {
+		return "", false
+	}
+	qual := types.RelativeTo(fn.Pkg())
+
+	// Substitute the arguments for the parameters in a copy of the
+	// expression, since the original belongs to a shared syntax tree.
+	expr, err := parser.ParseExpr(types.ExprString(result))
+	if err != nil {
+		return "", false
+	}
+	params := make(map[string]int) // index of each parameter, by name
+	for i := range sig.Params().Len() {
+		params[sig.Params().At(i).Name()] = i
+	}
+	// The receiver and the named results have no value, but their
+	// names could otherwise resolve to constants of the package.
+	if recv := sig.Recv(); recv != nil {
+		params[recv.Name()] = -1
+	}
+	for v := range sig.Results().Variables() {
+		params[v.Name()] = -1
+	}
+	ok = true
+	expr = astutil.Apply(expr, func(c *astutil.Cursor) bool {
+		switch c.Node().(type) {
+		case *ast.FuncLit:
+			ok = false // its parameters may shadow those of fn
+			return false
+		case *ast.Ident:
+		default:
+			return true
+		}
+		switch c.Parent().(type) {
+		case *ast.SelectorExpr:
+			if c.Name() == "Sel" {
+				return false
+			}
+		case *ast.KeyValueExpr:
+			if c.Name() == "Key" {
+				return false
+			}
+		}
+		i, isParam := params[c.Node().(*ast.Ident).Name]
+		if !isParam {
+			return false
+		}
+		if i < 0 || i >= len(call) || call[i] == "" {
+			ok = false
+			return false
+		}
+		arg, err := parser.ParseExpr(fmt.Sprintf("%s(%s)", types.TypeString(sig.Params().At(i).Type(), qual), call[i]))
+		if err != nil {
+			ok = false
+			return false
+		}
+		c.Replace(arg)
+		return false
+	}, nil).(ast.Expr)
+	if !ok {
+		return "", false
+	}
+
+	src := fmt.Sprintf("%s(%s)", types.TypeString(sig.Results().At(0).Type(), qual), types.ExprString(expr))
+	tv, err := types.Eval(token.NewFileSet(), fn.Pkg(), token.NoPos, src)
+	if err != nil || tv.Value == nil {
+		return "", false
+	}
+	switch tv.Value.Kind() {
+	case constant.Bool, constant.String, constant.Int:
+		return tv.Value.ExactString(), true
+	case constant.Float:
+		bits := 64
+		if basic.Kind() == types.Float32 {
+			bits = 32
+		}
+		f, _ := constant.Float64Val(tv.Value)
+		return strconv.FormatFloat(f, 'g', -1, bits), true
+	}
+	return "", false
+}
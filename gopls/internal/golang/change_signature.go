@@ -16,10 +16,12 @@ import (
 	"regexp"
 
 	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	goplsastutil "golang.org/x/tools/gopls/internal/util/astutil"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/gopls/internal/util/safetoken"
@@ -120,13 +122,13 @@ func removeParam(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle,
 		return nil, fmt.Errorf("no param found")
 	}
 	// Write a transformation to remove the param.
-	var newParams []int
+	var newParams []command.ChangeSignatureParam
 	for i := 0; i < info.decl.Type.Params.NumFields(); i++ {
 		if i != info.paramIndex {
-			newParams = append(newParams, i)
+			newParams = append(newParams, command.ChangeSignatureParam{OldIndex: i})
 		}
 	}
-	return ChangeSignature(ctx, snapshot, pkg, pgf, rng, newParams)
+	return ChangeSignature(ctx, snapshot, pkg, pgf, rng, newParams, nil)
 }
 
 // ChangeSignature computes a refactoring to update the signature according to
@@ -134,18 +136,29 @@ func removeParam(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle,
 // surrounding rng.
 //
 // newParams expresses the new parameters for the signature in terms of the old
-// parameters. Each entry in newParams is the index of the new parameter in the
-// original parameter list. For example, given func Foo(a, b, c int) and newParams
-// [2, 0, 1], the resulting changed signature is Foo(c, a, b int). If newParams
-// omits an index of the original signature, that parameter is removed.
+// parameters. Each entry in newParams either references a parameter of the
+// original signature by index (OldIndex), or declares a new parameter field
+// (NewField, e.g. "x int"). For example, given func Foo(a, b, c int) and
+// newParams [2, 0, 1], the resulting changed signature is Foo(c, a, b int). If
+// newParams omits an index of the original signature, that parameter is
+// removed.
+//
+// newResults expresses the new results in the same way, or is nil if they
+// are unchanged. Changes to the results are not yet supported, so any
+// transformation other than the identity is rejected with an error.
+//
+// Each call site passes the zero value of its type for a new parameter. A
+// new field whose name matches that of an omitted parameter is treated as a
+// change to the type of that parameter: call sites convert their existing
+// argument to the new type, so for example retyping the parameter 'b' of
+// Foo(a, b int) as "b int64" rewrites the call Foo(x, y) to Foo(x, int64(y)).
 //
 // This operation is a work in progress. Remaining TODO:
-//   - Handle adding parameters.
 //   - Handle adding/removing/reordering results.
 //   - Improve the extra newlines in output.
 //   - Stream type checking via ForEachPackage.
 //   - Avoid unnecessary additional type checking.
-func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, rng protocol.Range, newParams []int) ([]protocol.DocumentChange, error) {
+func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, rng protocol.Range, newParams, newResults []command.ChangeSignatureParam) ([]protocol.DocumentChange, error) {
 	// Changes to our heuristics for whether we can remove a parameter must also
 	// be reflected in the canRemoveParameter helper.
 	if perrors, terrors := pkg.ParseErrors(), pkg.TypeErrors(); len(perrors) > 0 || len(terrors) > 0 {
@@ -162,6 +175,15 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 	if info == nil || info.decl == nil {
 		return nil, fmt.Errorf("failed to find declaration")
 	}
+	if newResults != nil {
+		unchanged := len(newResults) == info.decl.Type.Results.NumFields()
+		for i, r := range newResults {
+			unchanged = unchanged && r.NewField == "" && r.OldIndex == i
+		}
+		if !unchanged {
+			return nil, fmt.Errorf("changing results not yet supported")
+		}
+	}
 
 	// Step 1: create the new declaration, which is a copy of the original decl
 	// with the rewritten signature.
@@ -173,28 +195,52 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 		name     string // empty if the field is unnamed
 		typeExpr ast.Expr
 		typ      types.Type
+		oldIndex int // index of the field in the original signature, or -1 if new
 	}
 
-	var newParamFields []flatField
+	var oldParamFields []flatField
 	for id, field := range goplsastutil.FlatFields(info.decl.Type.Params) {
 		typ := pkg.TypesInfo().TypeOf(field.Type)
 		if typ == nil {
-			return nil, fmt.Errorf("missing field type for field #%d", len(newParamFields))
+			return nil, fmt.Errorf("missing field type for field #%d", len(oldParamFields))
 		}
 		field := flatField{
 			typeExpr: field.Type,
 			typ:      typ,
+			oldIndex: len(oldParamFields),
 		}
 		if id != nil {
 			field.name = id.Name
 		}
-		newParamFields = append(newParamFields, field)
+		oldParamFields = append(oldParamFields, field)
 	}
 
-	// Select the new parameter fields.
-	newParamFields, ok := selectElements(newParamFields, newParams)
-	if !ok {
-		return nil, fmt.Errorf("failed to apply parameter transformation %v", newParams)
+	// Select the new parameter fields, parsing and type checking any new
+	// fields in the scope of the declaration.
+	var newParamFields []flatField
+	for _, p := range newParams {
+		if p.NewField == "" {
+			if p.OldIndex < 0 || p.OldIndex >= len(oldParamFields) {
+				return nil, fmt.Errorf("failed to apply parameter transformation: index %d out of range", p.OldIndex)
+			}
+			newParamFields = append(newParamFields, oldParamFields[p.OldIndex])
+			continue
+		}
+		name, typeExpr, typ, err := parseNewField(pkg, info.decl, p.NewField)
+		if err != nil {
+			return nil, err
+		}
+		newParamFields = append(newParamFields, flatField{
+			name:     name,
+			typeExpr: typeExpr,
+			typ:      typ,
+			oldIndex: -1,
+		})
+	}
+	for i, f := range newParamFields {
+		if _, ok := f.typeExpr.(*ast.Ellipsis); ok && i < len(newParamFields)-1 {
+			return nil, fmt.Errorf("variadic parameter %q must be last", f.name)
+		}
 	}
 
 	// writeFields performs the regrouping of named fields.
@@ -226,7 +272,7 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 
 	var (
 		params   = internalastutil.CloneNode(info.decl.Type.Params) // parameters of wrapper func: "_" names must be modified
-		args     = make([]ast.Expr, len(newParams))                 // arguments to the delegated call
+		args     []ast.Expr                                         // arguments to the delegated call
 		variadic = false                                            // whether the signature is variadic
 	)
 	{
@@ -247,23 +293,18 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 				fld.Names = append(fld.Names, ast.NewIdent("_")) // will be named below
 			}
 		}
-		// oldParams maps parameters to their argument in the delegated call.
-		// In other words, it is the inverse of newParams, but it is represented as
-		// a map rather than a slice, as not every old param need exist in
-		// newParams.
-		oldParams := make(map[int]int)
-		for new, old := range newParams {
-			oldParams[old] = new
+		retained := make(map[int]bool) // indices of parameters passed through
+		for _, f := range newParamFields {
+			if f.oldIndex >= 0 {
+				retained[f.oldIndex] = true
+			}
 		}
+		// wrapperNames holds the name of each parameter of the wrapper, by
+		// index in the original signature.
+		var wrapperNames []string
 		blanks := 0
-		paramIndex := 0 // global param index.
-		for id, field := range goplsastutil.FlatFields(params) {
-			argIndex, ok := oldParams[paramIndex]
-			paramIndex++
-			if !ok {
-				continue // parameter is removed
-			}
-			if id.Name == "_" { // from above: every field has names
+		for id := range goplsastutil.FlatFields(params) {
+			if id.Name == "_" && retained[len(wrapperNames)] { // from above: every field has names
 				// Create names for blank (_) parameters so the delegating wrapper
 				// can refer to them.
 				for {
@@ -277,10 +318,66 @@ func ChangeSignature(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.P
 					}
 				}
 			}
-			args[argIndex] = ast.NewIdent(id.Name)
-			// Record whether the call has an ellipsis.
-			// (Only the last loop iteration matters.)
-			_, variadic = field.Type.(*ast.Ellipsis)
+			wrapperNames = append(wrapperNames, id.Name)
+		}
+
+		// Parameters of the original signature that are not retained by
+		// index, by name. A new field of the same name retypes them.
+		omitted := make(map[string]int)
+		for _, f := range oldParamFields {
+			if f.name != "" && f.name != "_" {
+				omitted[f.name] = f.oldIndex
+			}
+		}
+		for _, f := range oldParamFields {
+			if retained[f.oldIndex] {
+				delete(omitted, f.name)
+			}
+		}
+
+		qual := typesinternal.FileQualifier(pgf.File, pkg.Types())
+		for i, f := range newParamFields {
+			last := i == len(newParamFields)-1
+			switch {
+			case f.oldIndex >= 0:
+				// An existing parameter is passed through.
+				args = append(args, ast.NewIdent(wrapperNames[f.oldIndex]))
+				_, variadic = f.typeExpr.(*ast.Ellipsis)
+				variadic = variadic && last
+
+			case is[*ast.Ellipsis](f.typeExpr):
+				// A new variadic parameter receives no arguments.
+
+			default:
+				if old, ok := omitted[f.name]; ok {
+					// A retyped parameter: convert the existing argument.
+					if _, ok := oldParamFields[old].typeExpr.(*ast.Ellipsis); ok {
+						return nil, fmt.Errorf("cannot change the type of variadic parameter %q", f.name)
+					}
+					if from := oldParamFields[old].typ; !types.ConvertibleTo(from, f.typ) {
+						return nil, fmt.Errorf("cannot change the type of parameter %q: %s is not convertible to %s",
+							f.name, types.TypeString(from, qual), types.TypeString(f.typ, qual))
+					}
+					delete(omitted, f.name)
+					var fun ast.Expr = internalastutil.CloneNode(f.typeExpr)
+					switch fun.(type) {
+					case *ast.StarExpr, *ast.FuncType, *ast.ChanType:
+						fun = &ast.ParenExpr{X: fun} // e.g. (*T)(x)
+					}
+					args = append(args, &ast.CallExpr{
+						Fun:  fun,
+						Args: []ast.Expr{ast.NewIdent(wrapperNames[old])},
+					})
+					break
+				}
+				// A new parameter: pass its zero value.
+				zero, ok := typeutil.ZeroValue(f.typ, &typeutil.ValueConfig{Package: pkg.Types(), Qualifier: qual})
+				if !ok {
+					return nil, fmt.Errorf("cannot compute zero value of new parameter type %s", types.TypeString(f.typ, qual))
+				}
+				// The wrapper is formatted and reparsed below.
+				args = append(args, ast.NewIdent(zero))
+			}
 		}
 	}
 
@@ -755,20 +852,45 @@ func reTypeCheck(logf func(string, ...any), orig *cache.Package, fileMask map[pr
 // TODO(golang/go#63472): this looks wrong with the new Go version syntax.
 var goVersionRx = regexp.MustCompile(`^go([1-9][0-9]*)\.(0|[1-9][0-9]*)$`)
 
-// selectElements returns a new array of elements of s indicated by the
-// provided list of indices. It returns false if any index was out of bounds.
-//
-// For example, given the slice []string{"a", "b", "c", "d"}, the
-// indices []int{3, 0, 1} results in the slice []string{"d", "a", "b"}.
-func selectElements[T any](s []T, indices []int) ([]T, bool) {
-	res := make([]T, len(indices))
-	for i, index := range indices {
-		if index < 0 || index >= len(s) {
-			return nil, false
-		}
-		res[i] = s[index]
+// parseNewField parses the description of a new parameter field, such as
+// "x int", and type checks its type in the scope of decl. It returns the
+// (possibly empty) name of the field along with its type expression and type.
+func parseNewField(pkg *cache.Package, decl *ast.FuncDecl, desc string) (string, ast.Expr, types.Type, error) {
+	expr, err := parser.ParseExpr("func(" + desc + ")")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("invalid parameter %q: %v", desc, err)
+	}
+	ftype, ok := expr.(*ast.FuncType)
+	if !ok || ftype.Params.NumFields() != 1 {
+		return "", nil, nil, fmt.Errorf("invalid parameter %q: want a single field", desc)
+	}
+	field := ftype.Params.List[0]
+
+	// Type check the type expression in the scope of the declaration, so that
+	// it may refer to package-level types and to the file's imports.
+	typeExpr := field.Type
+	checkExpr := typeExpr
+	if ellipsis, ok := typeExpr.(*ast.Ellipsis); ok {
+		checkExpr = &ast.ArrayType{Elt: ellipsis.Elt}
+	}
+	tinfo := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	if err := types.CheckExpr(pkg.FileSet(), pkg.Types(), decl.Pos(), checkExpr, tinfo); err != nil {
+		return "", nil, nil, fmt.Errorf("invalid type in parameter %q: %v", desc, err)
+	}
+	tv := tinfo.Types[checkExpr]
+	if !tv.IsType() {
+		return "", nil, nil, fmt.Errorf("invalid parameter %q: %s is not a type", desc, types.ExprString(typeExpr))
+	}
+
+	// The parsed syntax belongs to no file: clear its positions so that
+	// they don't confuse the printer when formatted with the declaration.
+	internalastutil.ClearPositions(typeExpr)
+
+	var name string
+	if len(field.Names) > 0 {
+		name = field.Names[0].Name
 	}
-	return res, true
+	return name, typeExpr, tv.Type, nil
 }
 
 // replaceFileDecl replaces old with new in the file described by pgf.
@@ -0,0 +1,198 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the fix for the wallclock analyzer, which injects
+// a clock into a function that reads the wall clock directly.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"path/filepath"
+
+	"golang.org/x/tools/gopls/internal/analysis/wallclock"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/astutil/cursor"
+)
+
+// clockVar is the name of the package-level variable of type
+// func() time.Time through which injected clocks are read.
+const clockVar = "now"
+
+// injectClock replaces the calls of time.Now, time.Since, and
+// time.Until within the function enclosing rng by calls of the
+// package-level variable now, which it declares, if necessary, as
+//
+//	var now = time.Now
+//
+// When it declares now, it also adds the file clock_test.go, unless
+// it exists, declaring a fake clock, fakeClock, and a helper,
+// useFakeClock, that installs it in place of now until a test
+// completes.
+func injectClock(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng protocol.Range) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	decl, err := enclosingFuncDecl(pgf, rng)
+	if err != nil {
+		return nil, err
+	}
+	info := pkg.TypesInfo()
+
+	// Use the existing clock, if any.
+	clock := pkg.Types().Scope().Lookup(clockVar)
+	if clock != nil {
+		v, ok := clock.(*types.Var)
+		if !ok || !isClockType(v.Type()) {
+			return nil, fmt.Errorf("cannot inject a clock: %s is already declared", clockVar)
+		}
+	}
+
+	text := func(n ast.Node) string {
+		start, end, _ := pgf.NodeOffsets(n)
+		return string(pgf.Src[start:end])
+	}
+	var (
+		edits    []protocol.TextEdit
+		timeName = "time" // local name of the time package
+	)
+	curBody, ok := pgf.Cursor.FindNode(decl.Body)
+	if !ok {
+		return nil, fmt.Errorf("cannot find body of %s", decl.Name.Name)
+	}
+	curBody.Inspect([]ast.Node{(*ast.CallExpr)(nil)}, func(cur cursor.Cursor, push bool) bool {
+		if err != nil || !push {
+			return false
+		}
+		call := cur.Node().(*ast.CallExpr)
+		fn := wallclock.ClockFunc(info, call)
+		if fn == nil {
+			return true
+		}
+		if _, obj := info.Scopes[pgf.File].Innermost(call.Pos()).LookupParent(clockVar, call.Pos()); obj != clock {
+			err = fmt.Errorf("cannot inject a clock: %s is shadowed", clockVar)
+			return false
+		}
+		if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				timeName = id.Name
+			}
+		}
+		var replacement string
+		switch fn.Name() {
+		case "Now":
+			replacement = clockVar + "()"
+		case "Since":
+			replacement = fmt.Sprintf("%s().Sub(%s)", clockVar, text(call.Args[0]))
+		case "Until":
+			x := text(call.Args[0])
+			switch call.Args[0].(type) {
+			case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr, *ast.ParenExpr, *ast.CompositeLit:
+			default:
+				x = "(" + x + ")"
+			}
+			replacement = fmt.Sprintf("%s.Sub(%s())", x, clockVar)
+		}
+		var rng protocol.Range
+		if rng, err = pgf.NodeRange(call); err != nil {
+			return false
+		}
+		edits = append(edits, protocol.TextEdit{Range: rng, NewText: replacement})
+		return false // the arguments are copied as is
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(edits) == 0 {
+		return nil, fmt.Errorf("%s does not read the wall clock", decl.Name.Name)
+	}
+	if clock != nil {
+		return []protocol.DocumentChange{protocol.DocumentChangeEdit(fh, edits)}, nil
+	}
+
+	// Declare the clock before the function.
+	pos := decl.Pos()
+	if decl.Doc != nil {
+		pos = decl.Doc.Pos()
+	}
+	declRng, err := pgf.PosRange(pos, pos)
+	if err != nil {
+		return nil, err
+	}
+	edits = append(edits, protocol.TextEdit{
+		Range: declRng,
+		NewText: fmt.Sprintf("// %s returns the current time. Tests may replace it with a fake clock.\nvar %[1]s = %s.Now\n\n",
+			clockVar, timeName),
+	})
+	changes := []protocol.DocumentChange{protocol.DocumentChangeEdit(fh, edits)}
+
+	// Add the fake clock to the tests, unless they have a file for it.
+	uri := protocol.URIFromPath(filepath.Join(pgf.URI.DirPath(), "clock_test.go"))
+	testFH, err := snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := testFH.Content(); err != nil {
+		var buf bytes.Buffer
+		if c := copyrightComment(pgf.File); c != nil {
+			buf.WriteString(text(c))
+			buf.WriteString("\n\n")
+		}
+		fmt.Fprintf(&buf, "package %s\n", pgf.File.Name.Name)
+		fmt.Fprintf(&buf, fakeClockSource, clockVar)
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes,
+			protocol.DocumentChangeCreate(uri),
+			protocol.DocumentChangeEdit(testFH, []protocol.TextEdit{{NewText: string(src)}}))
+	}
+	return changes, nil
+}
+
+// fakeClockSource is the source of the declarations of clock_test.go,
+// which refer to the clock variable as %[1]s.
+const fakeClockSource = `
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock whose time changes only when a test advances it.
+type fakeClock struct {
+	now time.Time
+}
+
+// Now returns the current time of the fake clock.
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// Advance moves the fake clock forward by d.
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// useFakeClock replaces the clock of the package, %[1]s, by a fake
+// clock whose time is initially start, until the test completes.
+func useFakeClock(t testing.TB, start time.Time) *fakeClock {
+	c := &fakeClock{now: start}
+	saved := %[1]s
+	%[1]s = c.Now
+	t.Cleanup(func() { %[1]s = saved })
+	return c
+}
+`
+
+// isClockType reports whether t is the type func() time.Time.
+func isClockType(t types.Type) bool {
+	sig, ok := t.Underlying().(*types.Signature)
+	return ok && sig.Params().Len() == 0 && sig.Results().Len() == 1 &&
+		isTimeType(sig.Results().At(0).Type())
+}
@@ -0,0 +1,324 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Generate Clone method for T".
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/versions"
+)
+
+// cloneStructAt returns the declaration of the struct type enclosing
+// [start, end), as for [structDeclAt], provided it has no Clone
+// method and the file may use the slices and maps packages.
+func cloneStructAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.GenDecl, *types.Named) {
+	decl, _, named := structDeclAt(pkg, pgf, start, end)
+	if named == nil ||
+		hasMethod(pkg.Types(), named, "Clone") ||
+		!versions.AtLeast(versions.FileVersion(pkg.TypesInfo(), pgf.File), "go1.21") {
+		return nil, nil
+	}
+	return decl, named
+}
+
+// addCloneMethod is a singleFileFixer that adds a Clone method to
+// the selected struct type T. The method returns a deep copy of its
+// receiver: it copies the slices, maps, and pointers that T refers
+// to, recursively, and calls the Clone methods of the types that have
+// one. Values it cannot copy are marked with TODO comments.
+func addCloneMethod(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	decl, named := cloneStructAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, nil, fmt.Errorf("no struct type selected")
+	}
+	info := pkg.TypesInfo()
+	name := named.Obj().Name()
+
+	c := &cloner{
+		pkg:      pkg.Types(),
+		named:    named,
+		needs:    make(map[types.Type]bool),
+		prefixes: make(map[string]string),
+	}
+
+	// Find the packages that the method needs, and import them in order.
+	c.buf = new(bytes.Buffer)
+	c.copyFields()
+	var edits []analysis.TextEdit
+	var imported []string
+	for _, pkgname := range slices.Sorted(maps.Keys(c.prefixes)) {
+		name, prefix, importEdits := analysisinternal.AddImport(info, pgf.File, pkgname, pkgname, "Clone", decl.Pos())
+		edits = append(edits, importEdits...)
+		imported = append(imported, name)
+		c.prefixes[pkgname] = prefix
+	}
+
+	recv := receiverName(named)
+	if recv == "" || recv == "_" || isCloneName(recv) {
+		recv = strings.ToLower(name[:1])
+		if isCloneName(recv) {
+			recv = "x"
+		}
+	}
+
+	for _, n := range imported {
+		if n == recv || isCloneName(n) {
+			return nil, nil, fmt.Errorf("name %s conflicts with an imported package", n)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Clone returns a deep copy of %s.\n", recv)
+	fmt.Fprintf(&buf, "func (%s *%s) Clone() *%s {\n", recv, name, name)
+	fmt.Fprintf(&buf, "if %s == nil {\nreturn nil\n}\n", recv)
+	fmt.Fprintf(&buf, "clone := *%s\n", recv)
+	c.buf = &buf
+	c.copyFields()
+	buf.WriteString("return &clone\n}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting generated method: %v", err)
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     decl.End(),
+		End:     decl.End(),
+		NewText: append([]byte("\n\n"), bytes.TrimSuffix(src, []byte("\n"))...),
+	})
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// cloneNames are the names of the local variables declared by
+// generated Clone methods, not counting numeric suffixes.
+var cloneNames = []string{"clone", "i", "k", "v"}
+
+// A cloner generates the statements of a Clone method.
+type cloner struct {
+	pkg      *types.Package
+	named    *types.Named // the type whose Clone method is generated
+	buf      *bytes.Buffer
+	needs    map[types.Type]bool // memo of needsCopy
+	stack    []*types.Named      // struct types being copied field by field
+	prefixes map[string]string   // import prefixes of needed packages, by name
+}
+
+// isCloneName reports whether a generated Clone method may declare a
+// local variable of the given name.
+func isCloneName(name string) bool {
+	return slices.Contains(cloneNames, strings.TrimRight(name, "0123456789"))
+}
+
+// qualifier returns the prefix that refers to the named package,
+// recording that the method needs it.
+func (c *cloner) qualifier(pkgname string) string {
+	prefix, ok := c.prefixes[pkgname]
+	if !ok {
+		c.prefixes[pkgname] = "" // not yet imported
+	}
+	return prefix
+}
+
+// copyFields emits statements that copy each field of the variable
+// clone, a shallow copy of the receiver.
+func (c *cloner) copyFields() {
+	for f := range c.named.Underlying().(*types.Struct).Fields() {
+		if f.Name() != "_" {
+			c.copy("clone."+f.Name(), f.Type(), 0)
+		}
+	}
+}
+
+// cloneMethod reports whether the named type T has a method Clone
+// that returns a T or a *T, and whether it returns a pointer.
+func (c *cloner) cloneMethod(t types.Type) (ok, ptr bool) {
+	named, isNamed := types.Unalias(t).(*types.Named)
+	if !isNamed {
+		return false, false
+	}
+	if named == c.named {
+		return true, true
+	}
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), false, c.pkg, "Clone")
+	fn, isFunc := obj.(*types.Func)
+	if !isFunc {
+		return false, false
+	}
+	sig := fn.Signature()
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false, false
+	}
+	switch res := sig.Results().At(0).Type(); {
+	case types.Identical(res, named):
+		return true, false
+	case types.Identical(res, types.NewPointer(named)):
+		return true, true
+	}
+	return false, false
+}
+
+// needsCopy reports whether a value of type t refers to variables
+// that its copy must not share, or to values that cannot be copied.
+func (c *cloner) needsCopy(t types.Type) bool {
+	if needs, ok := c.needs[t]; ok {
+		return needs
+	}
+	c.needs[t] = false // break cycles
+	var needs bool
+	switch u := t.Underlying().(type) {
+	case *types.Slice, *types.Map, *types.Pointer, *types.Signature, *types.Chan:
+		needs = true
+	case *types.Interface:
+		needs = !types.Identical(t, types.Universe.Lookup("error").Type())
+	case *types.Array:
+		needs = c.needsCopy(u.Elem())
+	case *types.Struct:
+		if isTimeType(t) {
+			break // time.Time has value semantics
+		}
+		for f := range u.Fields() {
+			if c.needsCopy(f.Type()) {
+				needs = true
+				break
+			}
+		}
+	}
+	c.needs[t] = needs
+	return needs
+}
+
+// isTimeType reports whether t is time.Time.
+func isTimeType(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time"
+}
+
+// todo emits a comment stating that the variable x is shared with the
+// original for the given reason.
+func (c *cloner) todo(x, reason string) {
+	fmt.Fprintf(c.buf, "// TODO: %s is shared with the original (%s).\n", x, reason)
+}
+
+// copy emits statements that replace the parts of the variable x of
+// type t that it shares with the original by copies of them. Nested
+// loops and blocks declare variables whose names are suffixed by depth.
+func (c *cloner) copy(x string, t types.Type, depth int) {
+	if !c.needsCopy(t) {
+		return
+	}
+	if ok, ptr := c.cloneMethod(t); ok {
+		if ptr {
+			fmt.Fprintf(c.buf, "%s = *%s.Clone()\n", x, x)
+		} else {
+			fmt.Fprintf(c.buf, "%s = %s.Clone()\n", x, x)
+		}
+		return
+	}
+	suffix := ""
+	if depth > 0 {
+		suffix = fmt.Sprint(depth)
+	}
+	qual := types.RelativeTo(c.pkg)
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		elem := u.Elem()
+		if ok, ptr := c.cloneMethod(elem); ok {
+			switch {
+			case types.Unalias(elem) == c.named:
+				// The generated method accepts a nil receiver.
+				fmt.Fprintf(c.buf, "%s = %s.Clone()\n", x, x)
+			case ptr:
+				fmt.Fprintf(c.buf, "if %s != nil {\n%s = %s.Clone()\n}\n", x, x, x)
+			default:
+				fmt.Fprintf(c.buf, "if %s != nil {\nv%s := %s.Clone()\n%s = &v%s\n}\n", x, suffix, x, x, suffix)
+			}
+			return
+		}
+		if named, ok := types.Unalias(elem).(*types.Named); ok && slices.Contains(c.stack, named) {
+			c.todo(x, "recursive type")
+			return
+		}
+		v := "v" + suffix
+		fmt.Fprintf(c.buf, "if %s != nil {\n%s := *%s\n", x, v, x)
+		c.copy(v, elem, depth+1)
+		fmt.Fprintf(c.buf, "%s = &%s\n}\n", x, v)
+
+	case *types.Slice:
+		clone := c.qualifier("slices") + "Clone"
+		if !c.needsCopy(u.Elem()) {
+			fmt.Fprintf(c.buf, "%s = %s(%s)\n", x, clone, x)
+			return
+		}
+		i := "i" + suffix
+		fmt.Fprintf(c.buf, "if %s != nil {\n%s = %s(%s)\n", x, x, clone, x)
+		fmt.Fprintf(c.buf, "for %s := range %s {\n", i, x)
+		c.copy(fmt.Sprintf("%s[%s]", x, i), u.Elem(), depth+1)
+		c.buf.WriteString("}\n}\n")
+
+	case *types.Map:
+		clone := c.qualifier("maps") + "Clone"
+		if !c.needsCopy(u.Elem()) {
+			fmt.Fprintf(c.buf, "%s = %s(%s)\n", x, clone, x)
+			return
+		}
+		k, v := "k"+suffix, "v"+suffix
+		fmt.Fprintf(c.buf, "if %s != nil {\n%s = %s(%s)\n", x, x, clone, x)
+		fmt.Fprintf(c.buf, "for %s, %s := range %s {\n", k, v, x)
+		c.copy(v, u.Elem(), depth+1)
+		fmt.Fprintf(c.buf, "%s[%s] = %s\n}\n}\n", x, k, v)
+
+	case *types.Array:
+		i := "i" + suffix
+		fmt.Fprintf(c.buf, "for %s := range %s {\n", i, x)
+		c.copy(fmt.Sprintf("%s[%s]", x, i), u.Elem(), depth+1)
+		c.buf.WriteString("}\n")
+
+	case *types.Struct:
+		named, _ := types.Unalias(t).(*types.Named)
+		if named != nil {
+			if slices.Contains(c.stack, named) {
+				c.todo(x, "recursive type")
+				return
+			}
+			c.stack = append(c.stack, named)
+			defer func() { c.stack = c.stack[:len(c.stack)-1] }()
+		}
+		for f := range u.Fields() {
+			if f.Name() == "_" || !c.needsCopy(f.Type()) {
+				continue
+			}
+			fx := x + "." + f.Name()
+			if !f.Exported() && f.Pkg() != c.pkg {
+				c.todo(fx, "unexported field of "+types.TypeString(t, qual))
+				continue
+			}
+			c.copy(fx, f.Type(), depth)
+		}
+
+	case *types.Signature:
+		c.todo(x, "func")
+	case *types.Chan:
+		c.todo(x, "channel")
+	case *types.Interface:
+		c.todo(x, "interface "+types.TypeString(t, qual))
+	}
+}
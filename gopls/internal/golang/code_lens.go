@@ -6,11 +6,15 @@ package golang
 
 import (
 	"context"
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
@@ -23,9 +27,13 @@ import (
 // CodeLensSources returns the supported sources of code lenses for Go files.
 func CodeLensSources() map[settings.CodeLensSource]cache.CodeLensSourceFunc {
 	return map[settings.CodeLensSource]cache.CodeLensSourceFunc{
-		settings.CodeLensGenerate:      goGenerateCodeLens, // commands: Generate
-		settings.CodeLensTest:          runTestCodeLens,    // commands: Test
-		settings.CodeLensRegenerateCgo: regenerateCgoLens,  // commands: RegenerateCgo
+		settings.CodeLensGenerate:       goGenerateCodeLens,     // commands: Generate
+		settings.CodeLensTest:           runTestCodeLens,        // commands: Test
+		settings.CodeLensDebugTest:      debugTestCodeLens,      // commands: DebugTest
+		settings.CodeLensBenchmark:      benchmarkCodeLens,      // commands: RunBenchmark
+		settings.CodeLensFunctionTests:  functionTestsCodeLens,  // commands: AddTest, Test
+		settings.CodeLensTestNavigation: testNavigationCodeLens, // commands: GoToTestOrSubject
+		settings.CodeLensRegenerateCgo:  regenerateCgoLens,      // commands: RegenerateCgo
 	}
 }
 
@@ -55,6 +63,21 @@ func runTestCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: cmd})
 	}
 
+	// Annotate each subtest whose name is statically known, such as
+	// a case of a table-driven test, with a command to run just it.
+	for _, decl := range pgf.File.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || !slices.ContainsFunc(testFuncs, func(fn testFunc) bool { return fn.name == decl.Name.Name }) {
+			continue
+		}
+		codeLens = append(codeLens, subtestCodeLens(decl.Name.Name, subtestSymbols(pgf.Mapper, pgf.Tok, decl), func(name string) *protocol.Command {
+			return command.NewRunTestsCommand("run subtest", command.RunTestsArgs{
+				URI:   puri,
+				Tests: []string{name},
+			})
+		})...)
+	}
+
 	for _, fn := range benchFuncs {
 		cmd := command.NewRunTestsCommand("run benchmark", command.RunTestsArgs{
 			URI:        puri,
@@ -87,6 +110,174 @@ func runTestCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 	return codeLens, nil
 }
 
+// subtestCodeLens returns a code lens with the command returned by
+// newCommand for each subtest of the test or subtest named parent, and
+// each of their subtests, given their symbols as computed by
+// [subtestSymbols].
+func subtestCodeLens(parent string, subtests []protocol.DocumentSymbol, newCommand func(name string) *protocol.Command) []protocol.CodeLens {
+	var codeLens []protocol.CodeLens
+	for _, s := range subtests {
+		name := parent + "/" + subtestName(s.Name)
+		rng := protocol.Range{Start: s.SelectionRange.Start, End: s.SelectionRange.Start}
+		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: newCommand(name)})
+		codeLens = append(codeLens, subtestCodeLens(name, s.Children, newCommand)...)
+	}
+	return codeLens
+}
+
+// debugTestCodeLens annotates each Test function of a _test.go file,
+// and each of its subtests whose name is known statically, with a
+// command that returns the configuration of a debug session of it.
+func debugTestCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	testFuncs, _, err := testsAndBenchmarks(pkg.TypesInfo(), pgf)
+	if err != nil {
+		return nil, err
+	}
+	newCommand := func(title string) func(name string) *protocol.Command {
+		return func(name string) *protocol.Command {
+			return command.NewDebugTestCommand(title, command.DebugTestArgs{
+				URI:  fh.URI(),
+				Test: name,
+			})
+		}
+	}
+	var codeLens []protocol.CodeLens
+	for _, fn := range testFuncs {
+		rng := protocol.Range{Start: fn.rng.Start, End: fn.rng.Start}
+		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: newCommand("debug test")(fn.name)})
+	}
+	for _, decl := range pgf.File.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || !slices.ContainsFunc(testFuncs, func(fn testFunc) bool { return fn.name == decl.Name.Name }) {
+			continue
+		}
+		codeLens = append(codeLens, subtestCodeLens(decl.Name.Name, subtestSymbols(pgf.Mapper, pgf.Tok, decl), newCommand("debug subtest"))...)
+	}
+	return codeLens, nil
+}
+
+// subtestName returns the name that the testing package gives to a
+// subtest started by t.Run(name, ...): it replaces spaces with
+// underscores and escapes non-printable characters.
+func subtestName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteByte('_')
+		case !strconv.IsPrint(r):
+			s := strconv.QuoteRune(r)
+			b.WriteString(s[1 : len(s)-1])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// functionTestsCodeLens annotates each function and method declared
+// in a non-test file with a command to run its tests (see [testsOf]),
+// or, if it has none, to add a test for it.
+func functionTestsCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	if strings.HasSuffix(fh.URI().Path(), "_test.go") {
+		return nil, nil
+	}
+	mp, err := snapshot.NarrowestMetadataForFile(ctx, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := snapshot.TestRelation(ctx, mp.PkgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
+	}
+	var codeLens []protocol.CodeLens
+	for _, decl := range pgf.File.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		name, ok := testableName(pgf.File, decl)
+		if !ok {
+			continue
+		}
+
+		var cmd *protocol.Command
+		if tests := testsOf(rel, name); len(tests) > 0 {
+			title := "run test"
+			if len(tests) > 1 {
+				title = fmt.Sprintf("run %d tests", len(tests))
+			}
+			cmd = command.NewRunTestsCommand(title, command.RunTestsArgs{
+				URI:   fh.URI(),
+				Tests: tests,
+			})
+		} else {
+			loc, err := pgf.NodeLocation(decl.Name)
+			if err != nil {
+				return nil, err
+			}
+			cmd = command.NewAddTestCommand("add test", command.AddTestArgs{Location: loc})
+		}
+		rng, err := pgf.PosRange(decl.Pos(), decl.Pos())
+		if err != nil {
+			return nil, err
+		}
+		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: cmd})
+	}
+	return codeLens, nil
+}
+
+// testNavigationCodeLens annotates each function and method that has
+// tests, and each test, with a command to go to its counterparts.
+func testNavigationCodeLens(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.CodeLens, error) {
+	nav, pgf, err := newTestNavigator(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	var codeLens []protocol.CodeLens
+	for _, decl := range pgf.File.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || decl.Body == nil {
+			continue
+		}
+		locs, isTest, err := nav.counterparts(ctx, pgf, decl)
+		if err != nil {
+			return nil, err
+		}
+		if len(locs) == 0 {
+			continue
+		}
+		noun := cond(isTest, "subject", "test")
+		title := "go to " + noun
+		if len(locs) > 1 {
+			title = fmt.Sprintf("go to %d %ss", len(locs), noun)
+		}
+		loc, err := pgf.NodeLocation(decl.Name)
+		if err != nil {
+			return nil, err
+		}
+		rng, err := pgf.PosRange(decl.Pos(), decl.Pos())
+		if err != nil {
+			return nil, err
+		}
+		codeLens = append(codeLens, protocol.CodeLens{
+			Range:   rng,
+			Command: command.NewGoToTestOrSubjectCommand(title, loc),
+		})
+	}
+	return codeLens, nil
+}
+
 type testFunc struct {
 	name string
 	rng  protocol.Range // of *ast.FuncDecl
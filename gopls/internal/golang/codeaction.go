@@ -13,6 +13,7 @@ import (
 	"go/types"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"slices"
 	"sort"
 	"strings"
@@ -194,6 +195,7 @@ func (req *codeActionsRequest) addEditAction(title string, fixedDiagnostics []pr
 
 // addAction adds a code action to the response.
 func (req *codeActionsRequest) addAction(act protocol.CodeAction) {
+	act.Kind = req.snapshot.Options().ClientCodeActionKind(act.Kind)
 	*req.actions = append(*req.actions, act)
 }
 
@@ -236,29 +238,71 @@ type codeActionProducer struct {
 var codeActionProducers = [...]codeActionProducer{
 	{kind: protocol.QuickFix, fn: quickFix, needPkg: true},
 	{kind: protocol.SourceOrganizeImports, fn: sourceOrganizeImports},
-	{kind: settings.AddTest, fn: addTest, needPkg: true},
+	{kind: settings.AddTest, fn: addTest},
+	{kind: settings.AddIntegrationTest, fn: addIntegrationTest},
+	{kind: settings.AddReceiverFormsTest, fn: addReceiverFormsTest, needPkg: true},
+	{kind: settings.AddFieldAssertionsTest, fn: addFieldAssertionsTest, needPkg: true},
+	{kind: settings.ConvertAssertionsStd, fn: convertAssertionsStd, needPkg: true},
+	{kind: settings.ConvertAssertionsTestify, fn: convertAssertionsTestify, needPkg: true},
+	{kind: settings.AddStringMethod, fn: addStringMethod, needPkg: true},
+	{kind: settings.AddJSONMethods, fn: addJSONMethodsAction, needPkg: true},
+	{kind: settings.AddCloneMethod, fn: addCloneMethodAction, needPkg: true},
+	{kind: settings.AddEqualMethod, fn: addEqualMethodAction, needPkg: true},
+	{kind: settings.AddFuncOptions, fn: addFuncOptionsAction, needPkg: true},
+	{kind: settings.AddFlagsMethod, fn: addFlagsMethodAction, needPkg: true},
+	{kind: settings.OrganizeTests, fn: organizeTests},
+	{kind: settings.AddMock, fn: addMockAction, needPkg: true},
+	{kind: settings.AddFake, fn: addFakeAction, needPkg: true},
 	{kind: settings.GoAssembly, fn: goAssembly, needPkg: true},
 	{kind: settings.GoDoc, fn: goDoc, needPkg: true},
 	{kind: settings.GoFreeSymbols, fn: goFreeSymbols},
 	{kind: settings.GoTest, fn: goTest},
+	{kind: settings.GoCopyTestCommand, fn: goCopyTestCommand, needPkg: true},
 	{kind: settings.GoToggleCompilerOptDetails, fn: toggleCompilerOptDetails},
 	{kind: settings.GoplsDocFeatures, fn: goplsDocFeatures},
 	{kind: settings.RefactorExtractFunction, fn: refactorExtractFunction},
 	{kind: settings.RefactorExtractMethod, fn: refactorExtractMethod},
+	{kind: settings.RefactorExtractTestHelper, fn: refactorExtractTestHelper},
 	{kind: settings.RefactorExtractToNewFile, fn: refactorExtractToNewFile},
+	{kind: settings.RefactorExtractSplitFile, fn: refactorExtractSplitFile, needPkg: true},
+	{kind: settings.RefactorExtractParamStruct, fn: refactorExtractParamStruct, needPkg: true},
 	{kind: settings.RefactorExtractConstant, fn: refactorExtractVariable, needPkg: true},
 	{kind: settings.RefactorExtractVariable, fn: refactorExtractVariable, needPkg: true},
 	{kind: settings.RefactorExtractConstantAll, fn: refactorExtractVariableAll, needPkg: true},
 	{kind: settings.RefactorExtractVariableAll, fn: refactorExtractVariableAll, needPkg: true},
 	{kind: settings.RefactorInlineCall, fn: refactorInlineCall, needPkg: true},
+	{kind: settings.RefactorInlineVariable, fn: refactorInlineVariable, needPkg: true},
 	{kind: settings.RefactorRewriteChangeQuote, fn: refactorRewriteChangeQuote},
 	{kind: settings.RefactorRewriteFillStruct, fn: refactorRewriteFillStruct, needPkg: true},
 	{kind: settings.RefactorRewriteFillSwitch, fn: refactorRewriteFillSwitch, needPkg: true},
+	{kind: settings.RefactorRewriteFillTypeSwitch, fn: refactorRewriteFillTypeSwitch, needPkg: true},
 	{kind: settings.RefactorRewriteInvertIf, fn: refactorRewriteInvertIf},
+	{kind: settings.RefactorRewriteIfToSwitch, fn: refactorRewriteIfToSwitch, needPkg: true},
+	{kind: settings.RefactorRewriteAddIterator, fn: refactorRewriteAddIterator, needPkg: true},
+	{kind: settings.RefactorRewriteRangeOverFunc, fn: refactorRewriteRangeOverFunc, needPkg: true},
+	{kind: settings.RefactorRewriteWrapError, fn: refactorRewriteWrapError, needPkg: true},
+	{kind: settings.RefactorRewriteWrapErrorAll, fn: refactorRewriteWrapErrorAll, needPkg: true},
+	{kind: settings.RefactorRewritePointerReceivers, fn: refactorRewriteChangeReceivers(true), needPkg: true},
+	{kind: settings.RefactorRewriteValueReceivers, fn: refactorRewriteChangeReceivers(false), needPkg: true},
+	{kind: settings.RefactorRewriteUnexport, fn: refactorRewriteUnexport, needPkg: true},
+	{kind: settings.RefactorRewriteFuncToMethod, fn: refactorRewriteFuncToMethod, needPkg: true},
+	{kind: settings.RefactorRewriteMethodToFunc, fn: refactorRewriteMethodToFunc, needPkg: true},
+	{kind: settings.RefactorRewriteAddContext, fn: refactorRewriteAddContextParam(false), needPkg: true},
+	{kind: settings.RefactorRewriteAddContextCallers, fn: refactorRewriteAddContextParam(true), needPkg: true},
 	{kind: settings.RefactorRewriteJoinLines, fn: refactorRewriteJoinLines, needPkg: true},
 	{kind: settings.RefactorRewriteRemoveUnusedParam, fn: refactorRewriteRemoveUnusedParam, needPkg: true},
 	{kind: settings.RefactorRewriteMoveParamLeft, fn: refactorRewriteMoveParamLeft, needPkg: true},
 	{kind: settings.RefactorRewriteMoveParamRight, fn: refactorRewriteMoveParamRight, needPkg: true},
+	{kind: settings.RefactorRewriteAddFieldNames, fn: refactorRewriteAddFieldNames, needPkg: true},
+	{kind: settings.RefactorRewriteAddFieldNamesAll, fn: refactorRewriteAddFieldNamesAll, needPkg: true},
+	{kind: settings.RefactorRewriteRemoveFieldNames, fn: refactorRewriteRemoveFieldNames, needPkg: true},
+	{kind: settings.RefactorRewriteRemoveFieldNamesAll, fn: refactorRewriteRemoveFieldNamesAll, needPkg: true},
+	{kind: settings.RefactorRewriteAddStructTagsJSON, fn: refactorRewriteAddStructTags("json", fixAddStructTagsJSON)},
+	{kind: settings.RefactorRewriteAddStructTagsYAML, fn: refactorRewriteAddStructTags("yaml", fixAddStructTagsYAML)},
+	{kind: settings.RefactorRewriteAddStructTagsDB, fn: refactorRewriteAddStructTags("db", fixAddStructTagsDB)},
+	{kind: settings.RefactorRewriteAddTestCase, fn: refactorRewriteAddTestCase, needPkg: true},
+	{kind: settings.RefactorRewriteTableTest, fn: refactorRewriteTableTest, needPkg: true},
+	{kind: settings.RefactorRewriteMergeTests, fn: refactorRewriteMergeTests, needPkg: true},
 	{kind: settings.RefactorRewriteSplitLines, fn: refactorRewriteSplitLines, needPkg: true},
 	{kind: settings.RefactorRewriteEliminateDotImport, fn: refactorRewriteEliminateDotImport, needPkg: true},
 
@@ -289,6 +333,9 @@ func quickFix(ctx context.Context, req *codeActionsRequest) error {
 		return nil
 	}
 
+	// Offer to create missing testdata files.
+	createTestdataFiles(req)
+
 	// Process any missing imports and pair them with the diagnostics they fix.
 	res := lazyInit[*allImportsFixesResult](ctx, req)
 	if res.err != nil {
@@ -476,6 +523,19 @@ func refactorExtractMethod(ctx context.Context, req *codeActionsRequest) error {
 	return nil
 }
 
+// refactorExtractTestHelper produces "Extract test helper" code actions
+// for statements of tests and subtests.
+// See [extractTestHelper] for command implementation.
+func refactorExtractTestHelper(ctx context.Context, req *codeActionsRequest) error {
+	if !strings.HasSuffix(req.pgf.URI.Path(), "_test.go") {
+		return nil
+	}
+	if p, ok, _, _ := canExtractFunction(req.pgf.Tok, req.start, req.end, req.pgf.Src, req.pgf.Cursor); ok && enclosingTestingParam(p.path) != nil {
+		req.addApplyFixAction("Extract test helper", fixExtractTestHelper, req.loc)
+	}
+	return nil
+}
+
 // refactorExtractVariable produces "Extract variable|constant" code actions.
 // See [extractVariable] for command implementation.
 func refactorExtractVariable(ctx context.Context, req *codeActionsRequest) error {
@@ -539,23 +599,155 @@ func refactorExtractToNewFile(ctx context.Context, req *codeActionsRequest) erro
 	return nil
 }
 
+// refactorExtractSplitFile produces "Split file into N files" code
+// actions, when the selection is within the package clause.
+// See [server.commandHandler.SplitFile] for command implementation.
+func refactorExtractSplitFile(ctx context.Context, req *codeActionsRequest) error {
+	file := req.pgf.File
+	if !posRangeContains(file.Package, file.Name.End(), req.start, req.end) {
+		return nil
+	}
+	if groups, _ := splitFileGroups(req.pkg, req.pgf); groups != nil {
+		cmd := command.NewSplitFileCommand(fmt.Sprintf("Split file into %d files", len(groups)+1), req.loc)
+		req.addCommandAction(cmd, false)
+	}
+	return nil
+}
+
+// refactorExtractParamStruct produces "Extract parameters of F into
+// struct" code actions.
+// See [server.commandHandler.ExtractParamStruct] for command implementation.
+func refactorExtractParamStruct(ctx context.Context, req *codeActionsRequest) error {
+	if _, fn, _ := paramStructAt(req.pkg, req.pgf, req.start, req.end); fn != nil {
+		title := fmt.Sprintf("Extract parameters of %s into struct %s", fn.Name(), paramStructName(fn))
+		cmd := command.NewExtractParamStructCommand(title, req.loc)
+		req.addCommandAction(cmd, false)
+	}
+	return nil
+}
+
 // addTest produces "Add test for FUNC" code actions.
 // See [server.commandHandler.AddTest] for command implementation.
 func addTest(ctx context.Context, req *codeActionsRequest) error {
-	// Reject test package.
+	return addTestAction(ctx, req, false)
+}
+
+// addIntegrationTest produces "Add integration test for FUNC" code
+// actions, which add the test to a file guarded by a build tag.
+func addIntegrationTest(ctx context.Context, req *codeActionsRequest) error {
+	return addTestAction(ctx, req, true)
+}
+
+// maxFormsRecvSize is the size in bytes of the largest receiver type
+// for whose methods addReceiverFormsTest offers tests: methods of
+// larger types have value receivers for reasons other than economy.
+const maxFormsRecvSize = 64
+
+// addReceiverFormsTest produces "Add test for FUNC through T and *T"
+// code actions for the methods with value receivers of small types,
+// which call the method through each form of its receiver, and "Add
+// test for FUNC through *T and its interfaces" code actions for the
+// methods with pointer receivers that implement an interface.
+func addReceiverFormsTest(ctx context.Context, req *codeActionsRequest) error {
 	if req.pkg.Metadata().ForTest != "" {
 		return nil
 	}
-
-	path, _ := astutil.PathEnclosingInterval(req.pgf.File, req.start, req.end)
-	if len(path) < 2 {
+	decl, err := enclosingFuncDecl(req.pgf, req.loc.Range)
+	if err != nil || decl.Recv == nil || decl.Name.Name == "_" {
+		return nil
+	}
+	fn, ok := req.pkg.TypesInfo().Defs[decl.Name].(*types.Func)
+	if !ok {
 		return nil
 	}
+	recv := fn.Signature().Recv().Type()
+	ptr, isPtr := recv.(*types.Pointer)
+	named, ok := types.Unalias(recv).(*types.Named)
+	if isPtr {
+		named, ok = types.Unalias(ptr.Elem()).(*types.Named)
+	}
+	if !ok || named.TypeParams() != nil {
+		return nil // generic type
+	}
+	qual := types.RelativeTo(req.pkg.Types())
+	name := types.TypeString(recv, qual)
+	var title string
+	if isPtr {
+		// Without an interface, *T is the only form of the receiver.
+		if len(receiverForms(fn, "x", false, false, qual)) < 2 {
+			return nil
+		}
+		title = fmt.Sprintf("Add test for %s through %s and its interfaces", decl.Name, name)
+	} else {
+		if req.pkg.TypesSizes().Sizeof(recv) > maxFormsRecvSize {
+			return nil
+		}
+		title = fmt.Sprintf("Add test for %s through %s and *%[2]s", decl.Name, name)
+	}
+	cmd := command.NewAddTestCommand(title, command.AddTestArgs{
+		Location:      req.loc,
+		ReceiverForms: true,
+		ResolveEdits:  req.resolveEdits(),
+	})
+	req.addCommandAction(cmd, true)
+	return nil
+}
 
-	decl, ok := path[len(path)-2].(*ast.FuncDecl)
+// addFieldAssertionsTest produces "Add test for FUNC with per-field
+// assertions" code actions for the functions with a result whose type
+// is a struct of their package, which compare each field of the
+// result with its own want field.
+func addFieldAssertionsTest(ctx context.Context, req *codeActionsRequest) error {
+	if req.pkg.Metadata().ForTest != "" {
+		return nil
+	}
+	decl, err := enclosingFuncDecl(req.pgf, req.loc.Range)
+	if err != nil || decl.Name.Name == "_" || decl.Name.Name == "init" || decl.Type.TypeParams != nil {
+		return nil
+	}
+	fn, ok := req.pkg.TypesInfo().Defs[decl.Name].(*types.Func)
 	if !ok {
 		return nil
 	}
+	for res := range fn.Signature().Results().Variables() {
+		if resultFields(res.Type(), fn.Pkg(), false) != nil {
+			cmd := command.NewAddTestCommand("Add test for "+decl.Name.Name+" with per-field assertions", command.AddTestArgs{
+				Location:        req.loc,
+				FieldAssertions: true,
+				ResolveEdits:    req.resolveEdits(),
+			})
+			req.addCommandAction(cmd, true)
+			break
+		}
+	}
+	return nil
+}
+
+// addTestAction produces the code actions of addTest, or of
+// addIntegrationTest if integration is set.
+func addTestAction(ctx context.Context, req *codeActionsRequest, integration bool) error {
+	// Reject test package.
+	// (The action requires only syntax and metadata, so that it
+	// activates without type-checking the package.)
+	mp, err := NarrowestMetadataForFile(ctx, req.snapshot, req.fh.URI())
+	if err != nil || mp.ForTest != "" {
+		return nil
+	}
+
+	// The function may also be a package-level variable initialized
+	// by a function literal (see [funcVarDecl]).
+	decl, err := enclosingFuncDecl(req.pgf, req.loc.Range)
+	if err != nil {
+		// Offer to create tests of all the methods of a type.
+		if spec := enclosingTypeSpec(req.pgf, req.loc.Range); spec != nil && !integration {
+			cmd := command.NewAddTestCommand("Add tests for methods of "+spec.Name.Name, command.AddTestArgs{
+				Location:     req.loc,
+				ResolveEdits: req.resolveEdits(),
+			})
+			req.addCommandAction(cmd, true)
+		}
+		return nil
+	}
 
 	// Don't offer to create tests of "init" or "_".
 	if decl.Name.Name == "_" || decl.Name.Name == "init" {
@@ -567,8 +759,16 @@ func addTest(ctx context.Context, req *codeActionsRequest) error {
 		return nil
 	}
 
-	cmd := command.NewAddTestCommand("Add test for "+decl.Name.String(), req.loc)
-	req.addCommandAction(cmd, false)
+	title := "Add test for " + decl.Name.String()
+	if integration {
+		title = "Add integration test for " + decl.Name.String()
+	}
+	cmd := command.NewAddTestCommand(title, command.AddTestArgs{
+		Location:     req.loc,
+		Integration:  integration,
+		ResolveEdits: req.resolveEdits(),
+	})
+	req.addCommandAction(cmd, true)
 
 	// TODO(hxjiang): add code action for generate test for package/file.
 	return nil
@@ -654,6 +854,29 @@ func refactorRewriteMoveParamRight(ctx context.Context, req *codeActionsRequest)
 	return nil
 }
 
+// convertAssertionsStd produces "Convert assertions to standard
+// library style" code actions for test files that use testify
+// assertions. See [convertToStdAssertions] for command implementation.
+func convertAssertionsStd(ctx context.Context, req *codeActionsRequest) error {
+	if len(testifyRewrites(req.pkg.TypesInfo(), req.pgf)) > 0 {
+		req.addApplyFixAction("Convert assertions to standard library style", fixAssertionsToStd, req.loc)
+	}
+	return nil
+}
+
+// convertAssertionsTestify produces "Convert assertions to testify"
+// code actions for test files of modules that do not yet use testify.
+// See [assertionsToTestify] for command implementation.
+func convertAssertionsTestify(ctx context.Context, req *codeActionsRequest) error {
+	if usesTestify(req.pgf.File) || len(stdRewrites(req.pkg.TypesInfo(), req.pgf)) == 0 {
+		return nil
+	}
+	if testModFile(req.snapshot, req.pkg.Metadata()) != "" {
+		req.addApplyFixAction("Convert assertions to testify", fixAssertionsToTestify, req.loc)
+	}
+	return nil
+}
+
 // refactorRewriteChangeQuote produces "Convert to {raw,interpreted} string literal" code actions.
 func refactorRewriteChangeQuote(ctx context.Context, req *codeActionsRequest) error {
 	convertStringLiteral(req)
@@ -669,6 +892,170 @@ func refactorRewriteInvertIf(ctx context.Context, req *codeActionsRequest) error
 	return nil
 }
 
+// refactorRewriteIfToSwitch produces "Convert if/else-if chain to switch"
+// code actions. See [convertIfToSwitch] for command implementation.
+func refactorRewriteIfToSwitch(ctx context.Context, req *codeActionsRequest) error {
+	if _, err := canConvertIfToSwitch(req.pkg.TypesInfo(), req.pgf, req.start, req.end); err == nil {
+		req.addApplyFixAction("Convert if/else-if chain to switch", fixIfToSwitch, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteAddTestCase produces "Add test case" code actions.
+// See [addTestCase] for command implementation.
+func refactorRewriteAddTestCase(ctx context.Context, req *codeActionsRequest) error {
+	if _, _, err := testCaseTable(req.pkg.TypesInfo(), req.pgf, req.start, req.end); err == nil {
+		req.addApplyFixAction("Add test case", fixAddTestCase, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteTableTest produces "Convert to table-driven test"
+// code actions. See [convertToTableTest] for command implementation.
+func refactorRewriteTableTest(ctx context.Context, req *codeActionsRequest) error {
+	if _, err := tableTestOf(req.pkg.TypesInfo(), req.pgf, req.start, req.end); err == nil {
+		req.addApplyFixAction("Convert to table-driven test", fixTableTest, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteMergeTests produces "Merge TestFoo_* into table-driven
+// TestFoo" code actions. See [mergeTests] for command implementation.
+func refactorRewriteMergeTests(ctx context.Context, req *codeActionsRequest) error {
+	if m, err := testMergeOf(req.pkg.TypesInfo(), req.pgf, req.start, req.end); err == nil {
+		req.addApplyFixAction(fmt.Sprintf("Merge %s_* into table-driven %s", m.name, m.name), fixMergeTests, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteAddIterator produces "Add iterator function FSeq" code
+// actions. See [addIteratorFunc] for command implementation.
+func refactorRewriteAddIterator(ctx context.Context, req *codeActionsRequest) error {
+	if _, fn := iteratorFuncAt(req.pkg, req.pgf, req.start, req.end); fn != nil {
+		req.addApplyFixAction("Add iterator function "+fn.Name()+seqSuffix, fixAddIterator, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteRangeOverFunc produces "Convert to range-over-func loop"
+// code actions. See [convertToRangeOverFunc] for command implementation.
+func refactorRewriteRangeOverFunc(ctx context.Context, req *codeActionsRequest) error {
+	if _, err := rangeOverFuncCallAt(req.pkg, req.pgf, req.start, req.end); err == nil {
+		req.addApplyFixAction("Convert to range-over-func loop", fixRangeOverFunc, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteWrapError produces "Wrap error with fmt.Errorf" code
+// actions. See [wrapError] for command implementation.
+func refactorRewriteWrapError(ctx context.Context, req *codeActionsRequest) error {
+	curSel, ok := req.pgf.Cursor.FindPos(req.start, req.end)
+	if !ok {
+		return nil
+	}
+	if _, ok := bareErrorReturnAt(req.pkg.TypesInfo(), curSel); ok {
+		req.addApplyFixAction("Wrap error with fmt.Errorf", fixWrapError, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteWrapErrorAll produces "Wrap all N returned errors
+// with fmt.Errorf" code actions. See [wrapErrorAll] for command
+// implementation.
+func refactorRewriteWrapErrorAll(ctx context.Context, req *codeActionsRequest) error {
+	curSel, ok := req.pgf.Cursor.FindPos(req.start, req.end)
+	if !ok {
+		return nil
+	}
+	// Offer the file-wide action only when the selection is
+	// within a bare error return and there are others.
+	if _, ok := bareErrorReturnAt(req.pkg.TypesInfo(), curSel); ok {
+		if n := len(bareErrorReturns(req.pkg.TypesInfo(), req.pgf)); n > 1 {
+			req.addApplyFixAction(fmt.Sprintf("Wrap all %d returned errors in file with fmt.Errorf", n), fixWrapErrorAll, req.loc)
+		}
+	}
+	return nil
+}
+
+// refactorRewriteUnexport produces "Unexport X" code actions.
+// See [server.commandHandler.Unexport] for command implementation.
+func refactorRewriteUnexport(ctx context.Context, req *codeActionsRequest) error {
+	if id, _ := unexportableAt(req.pkg, req.pgf, req.start, req.end); id != nil {
+		cmd := command.NewUnexportCommand("Unexport "+id.Name, req.loc)
+		req.addCommandAction(cmd, false)
+	}
+	return nil
+}
+
+// refactorRewriteFuncToMethod produces "Convert function F to method
+// of T" code actions.
+// See [server.commandHandler.ConvertFuncToMethod] for command implementation.
+func refactorRewriteFuncToMethod(ctx context.Context, req *codeActionsRequest) error {
+	if _, fn, named := funcToMethodAt(req.pkg, req.pgf, req.start, req.end); fn != nil {
+		title := fmt.Sprintf("Convert function %s to method of %s", fn.Name(), named.Obj().Name())
+		req.addCommandAction(command.NewConvertFuncToMethodCommand(title, req.loc), false)
+	}
+	return nil
+}
+
+// refactorRewriteMethodToFunc produces "Convert method T.F to
+// function" code actions.
+// See [server.commandHandler.ConvertMethodToFunc] for command implementation.
+func refactorRewriteMethodToFunc(ctx context.Context, req *codeActionsRequest) error {
+	if _, fn, named := methodToFuncAt(req.pkg, req.pgf, req.start, req.end); fn != nil {
+		title := fmt.Sprintf("Convert method %s.%s to function", named.Obj().Name(), fn.Name())
+		req.addCommandAction(command.NewConvertMethodToFuncCommand(title, req.loc), false)
+	}
+	return nil
+}
+
+// refactorRewriteChangeReceivers returns a code action producer for
+// "Convert all methods of T to pointer receivers" code actions (or
+// value receivers, if !pointer).
+// See [server.commandHandler.ChangeReceivers] for command implementation.
+func refactorRewriteChangeReceivers(pointer bool) func(context.Context, *codeActionsRequest) error {
+	return func(ctx context.Context, req *codeActionsRequest) error {
+		named, nvalue, nptr := receiverTypeAt(req.pkg, req.pgf, req.start, req.end)
+		if named == nil {
+			return nil
+		}
+		kind, n := "value", nptr
+		if pointer {
+			kind, n = "pointer", nvalue
+		}
+		if n > 0 {
+			title := fmt.Sprintf("Convert all methods of %s to %s receivers", named.Obj().Name(), kind)
+			cmd := command.NewChangeReceiversCommand(title, command.ChangeReceiversArgs{
+				Location: req.loc,
+				Pointer:  pointer,
+			})
+			req.addCommandAction(cmd, false)
+		}
+		return nil
+	}
+}
+
+// refactorRewriteAddContextParam returns a code action producer for
+// "Add context.Context parameter to F" code actions (and to its
+// callers, if callers).
+// See [server.commandHandler.AddContextParam] for command implementation.
+func refactorRewriteAddContextParam(callers bool) func(context.Context, *codeActionsRequest) error {
+	return func(ctx context.Context, req *codeActionsRequest) error {
+		if decl, _ := addContextParamAt(req.pkg, req.pgf, req.start, req.end); decl != nil {
+			title := fmt.Sprintf("Add context.Context parameter to %s", decl.Name.Name)
+			if callers {
+				title += " and its callers"
+			}
+			cmd := command.NewAddContextParamCommand(title, command.AddContextParamArgs{
+				Location: req.loc,
+				Callers:  callers,
+			})
+			req.addCommandAction(cmd, false)
+		}
+		return nil
+	}
+}
+
 // refactorRewriteSplitLines produces "Split ITEMS into separate lines" code actions.
 // See [splitLines] for command implementation.
 func refactorRewriteSplitLines(ctx context.Context, req *codeActionsRequest) error {
@@ -819,6 +1206,17 @@ func refactorRewriteFillSwitch(ctx context.Context, req *codeActionsRequest) err
 	return nil
 }
 
+// refactorRewriteFillTypeSwitch produces "Add cases for all
+// implementations of T" code actions.
+// See [fillTypeSwitch] for command implementation.
+func refactorRewriteFillTypeSwitch(ctx context.Context, req *codeActionsRequest) error {
+	if stmt, iface := typeSwitchAt(req.pkg, req.pgf, req.start, req.end); stmt != nil {
+		title := "Add cases for all implementations of " + types.TypeString(iface, typesinternal.NameRelativeTo(req.pkg.Types()))
+		req.addApplyFixAction(title, fixFillTypeSwitch, req.loc)
+	}
+	return nil
+}
+
 // removableParameter returns paramInfo about a removable parameter indicated
 // by the given [start, end) range, or nil if no such removal is available.
 //
@@ -887,6 +1285,162 @@ func refactorInlineCall(ctx context.Context, req *codeActionsRequest) error {
 	return nil
 }
 
+// refactorInlineVariable produces "Inline variable V" code actions.
+// See [inlineVariable] for command implementation.
+func refactorInlineVariable(ctx context.Context, req *codeActionsRequest) error {
+	// As with inline call, offer "inline" only after a selection
+	// or explicit menu operation.
+	if req.trigger == protocol.CodeActionAutomatic && req.loc.Empty() {
+		return nil
+	}
+
+	if info, err := canInlineVariable(req.pkg.TypesInfo(), req.pgf.Cursor, req.start, req.end); err == nil {
+		req.addApplyFixAction("Inline variable "+info.v.Name(), fixInlineVariable, req.loc)
+	}
+	return nil
+}
+
+// addStringMethod produces "Generate String method for T" code actions.
+// See [server.commandHandler.AddStringMethod] for command implementation.
+func addStringMethod(ctx context.Context, req *codeActionsRequest) error {
+	if enum := enumTypeAt(req.pkg, req.pgf, req.start, req.end); enum != nil {
+		cmd := command.NewAddStringMethodCommand("Generate String method for "+enum.Obj().Name(), command.AddStringMethodArgs{
+			Location:     req.loc,
+			ResolveEdits: req.resolveEdits(),
+		})
+		req.addCommandAction(cmd, true)
+	}
+	return nil
+}
+
+// addJSONMethodsAction produces "Generate MarshalJSON and UnmarshalJSON
+// methods for T" code actions. See [addJSONMethods] for command
+// implementation.
+func addJSONMethodsAction(ctx context.Context, req *codeActionsRequest) error {
+	if _, _, named := jsonStructAt(req.pkg, req.pgf, req.start, req.end); named != nil {
+		title := fmt.Sprintf("Generate MarshalJSON and UnmarshalJSON methods for %s", named.Obj().Name())
+		req.addApplyFixAction(title, fixAddJSONMethods, req.loc)
+	}
+	return nil
+}
+
+// addCloneMethodAction produces "Generate Clone method for T" code
+// actions. See [addCloneMethod] for command implementation.
+func addCloneMethodAction(ctx context.Context, req *codeActionsRequest) error {
+	if _, named := cloneStructAt(req.pkg, req.pgf, req.start, req.end); named != nil {
+		title := fmt.Sprintf("Generate Clone method for %s", named.Obj().Name())
+		req.addApplyFixAction(title, fixAddCloneMethod, req.loc)
+	}
+	return nil
+}
+
+// addEqualMethodAction produces "Generate Equal method for T" code
+// actions. See [addEqualMethod] for command implementation.
+func addEqualMethodAction(ctx context.Context, req *codeActionsRequest) error {
+	if _, named := equalStructAt(req.pkg, req.pgf, req.start, req.end); named != nil {
+		title := fmt.Sprintf("Generate Equal method for %s", named.Obj().Name())
+		req.addApplyFixAction(title, fixAddEqualMethod, req.loc)
+	}
+	return nil
+}
+
+// addMockAction produces "Generate mock for I" code actions. If a
+// go:generate directive of the package runs a mock generator for I
+// (see [mockDirective]), the action runs it; otherwise, see [addMock]
+// for command implementation.
+func addMockAction(ctx context.Context, req *codeActionsRequest) error {
+	if _, named := mockInterfaceAt(req.pkg, req.pgf, req.start, req.end); named != nil {
+		name := named.Obj().Name()
+		if directive, tool := mockDirective(req.pkg, req.pgf, name); directive != "" {
+			cmd := command.NewGenerateCommand(fmt.Sprintf("Generate mock for %s (%s)", name, tool), command.GenerateArgs{
+				Dir: req.fh.URI().Dir(),
+				Run: "^" + regexp.QuoteMeta(directive) + "$",
+			})
+			req.addCommandAction(cmd, false)
+		} else {
+			req.addApplyFixAction(fmt.Sprintf("Generate mock for %s", name), fixAddMock, req.loc)
+		}
+	}
+	return nil
+}
+
+// addFakeAction produces "Generate fake for I" code actions.
+// See [addFake] for command implementation.
+func addFakeAction(ctx context.Context, req *codeActionsRequest) error {
+	if named, _ := fakeInterfaceAt(req.pkg, req.pgf, req.start, req.end); named != nil {
+		req.addApplyFixAction(fmt.Sprintf("Generate fake for %s", named.Obj().Name()), fixAddFake, req.loc)
+	}
+	return nil
+}
+
+// addFuncOptionsAction produces "Generate functional options for T"
+// code actions. See [addFuncOptions] for command implementation.
+func addFuncOptionsAction(ctx context.Context, req *codeActionsRequest) error {
+	if _, _, named, _ := funcOptionsAt(req.pkg, req.pgf, req.start, req.end); named != nil {
+		title := fmt.Sprintf("Generate functional options for %s", named.Obj().Name())
+		req.addApplyFixAction(title, fixAddFuncOptions, req.loc)
+	}
+	return nil
+}
+
+// addFlagsMethodAction produces "Generate RegisterFlags method for T"
+// code actions. See [addFlagsMethod] for command implementation.
+func addFlagsMethodAction(ctx context.Context, req *codeActionsRequest) error {
+	if _, _, named := flagsStructAt(req.pkg, req.pgf, req.start, req.end); named != nil {
+		title := fmt.Sprintf("Generate RegisterFlags method for %s", named.Obj().Name())
+		req.addApplyFixAction(title, fixAddFlagsMethod, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteAddFieldNames produces "Add field names to T literal"
+// code actions. See [addFieldNames] for command implementation.
+func refactorRewriteAddFieldNames(ctx context.Context, req *codeActionsRequest) error {
+	if title, _ := canConvertStructLit(req.pkg, req.pgf, req.start, req.end, true); title != "" {
+		req.addApplyFixAction(title, fixAddFieldNames, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteAddFieldNamesAll produces "Add field names to all N T
+// literals in file" code actions. See [addFieldNamesAll] for command implementation.
+func refactorRewriteAddFieldNamesAll(ctx context.Context, req *codeActionsRequest) error {
+	if _, title := canConvertStructLit(req.pkg, req.pgf, req.start, req.end, true); title != "" {
+		req.addApplyFixAction(title, fixAddFieldNamesAll, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteRemoveFieldNames produces "Remove field names from T
+// literal" code actions. See [removeFieldNames] for command implementation.
+func refactorRewriteRemoveFieldNames(ctx context.Context, req *codeActionsRequest) error {
+	if title, _ := canConvertStructLit(req.pkg, req.pgf, req.start, req.end, false); title != "" {
+		req.addApplyFixAction(title, fixRemoveFieldNames, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteRemoveFieldNamesAll produces "Remove field names from
+// all N T literals in file" code actions. See [removeFieldNamesAll] for
+// command implementation.
+func refactorRewriteRemoveFieldNamesAll(ctx context.Context, req *codeActionsRequest) error {
+	if _, title := canConvertStructLit(req.pkg, req.pgf, req.start, req.end, false); title != "" {
+		req.addApplyFixAction(title, fixRemoveFieldNamesAll, req.loc)
+	}
+	return nil
+}
+
+// refactorRewriteAddStructTags returns a code action producer for "Add
+// KEY struct tags" code actions. See [addStructTags] for command implementation.
+func refactorRewriteAddStructTags(key, fix string) func(context.Context, *codeActionsRequest) error {
+	return func(ctx context.Context, req *codeActionsRequest) error {
+		if structTypeAt(req.pgf, req.start, req.end) != nil {
+			req.addApplyFixAction(fmt.Sprintf("Add %s struct tags", key), fix, req.loc)
+		}
+		return nil
+	}
+}
+
 // goTest produces "Run tests and benchmarks" code actions.
 // See [server.commandHandler.runTests] for command implementation.
 func goTest(ctx context.Context, req *codeActionsRequest) error {
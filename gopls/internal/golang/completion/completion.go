@@ -769,6 +769,7 @@ func (c *completer) collectCompletions(ctx context.Context) error {
 	// At the file scope, only keywords are allowed.
 	case *ast.BadDecl, *ast.File:
 		c.addKeywordCompletions()
+		c.addTestPostfixCandidate(ctx)
 	default:
 		// fallback to lexical completions
 		return c.lexical(ctx)
@@ -1764,6 +1765,9 @@ func (c *completer) lexical(ctx context.Context) error {
 		c.injectType(ctx, c.inference.objType)
 	}
 
+	// Offer a fake implementation of an interface parameter.
+	c.addFakeCandidate(ctx)
+
 	// Add keyword completion items appropriate in the current context.
 	c.addKeywordCompletions()
 
@@ -0,0 +1,115 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/event"
+)
+
+// addFakeCandidate adds a candidate when completing an argument of a
+// call whose parameter type is a named interface type I. The candidate
+// expands to "&fakeI{}", and declares after the enclosing declaration
+// the struct type fakeI with a stub of each method of I:
+//
+//	f(&fakeReader{})
+//
+//	// fakeReader is a fake implementation of io.Reader.
+//	type fakeReader struct{}
+//
+//	// Read implements io.Reader.
+//	func (*fakeReader) Read(p []byte) (n int, err error) {
+//		panic("unimplemented")
+//	}
+func (c *completer) addFakeCandidate(ctx context.Context) {
+	if !c.opts.snippets || c.inference.objType == nil || len(c.path) < 2 {
+		return
+	}
+
+	// Are we completing an argument of a call?
+	call, ok := c.path[0].(*ast.CallExpr)
+	if !ok {
+		if _, isIdent := c.path[0].(*ast.Ident); isIdent {
+			call, ok = c.path[1].(*ast.CallExpr)
+		}
+	}
+	if !ok || c.pos <= call.Lparen || c.pos > call.Rparen {
+		return
+	}
+
+	// Is the parameter type a named interface type with methods,
+	// all of which the current package may implement?
+	named, ok := types.Unalias(c.inference.objType).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() == 0 {
+		return
+	}
+	for m := range iface.Methods() {
+		if !m.Exported() && m.Pkg() != c.pkg.Types() {
+			return
+		}
+	}
+
+	// Choose a name for the fake type that is not yet declared.
+	r, size := utf8.DecodeRuneInString(named.Obj().Name())
+	base := "fake" + string(unicode.ToUpper(r)) + named.Obj().Name()[size:]
+	name := base
+	for i := 2; c.pkg.Types().Scope().Lookup(name) != nil; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	score := c.matcher.Score(name)
+	if score <= 0 {
+		return
+	}
+
+	fileScope := c.pkg.TypesInfo().Scopes[c.pgf.File]
+	if fileScope == nil {
+		return
+	}
+	qual, importEdits := c.importingQualifier(fileScope)
+	ifaceName := types.TypeString(named, qual)
+
+	var decl strings.Builder
+	fmt.Fprintf(&decl, "\n\n// %s is a fake implementation of %s.\n", name, ifaceName)
+	fmt.Fprintf(&decl, "type %s struct{}\n", name)
+	for m := range iface.Methods() {
+		fmt.Fprintf(&decl, "\n// %s implements %s.\n", m.Name(), ifaceName)
+		fmt.Fprintf(&decl, "func (*%s) %s%s {\n\tpanic(\"unimplemented\")\n}\n",
+			name, m.Name(), strings.TrimPrefix(types.TypeString(m.Type(), qual), "func"))
+	}
+	edits, err := importEdits()
+	if err != nil {
+		return // e.g. the package name is shadowed
+	}
+
+	// Declare the type after the enclosing top-level declaration.
+	topDecl := c.path[len(c.path)-2]
+	declEdits, err := c.editText(topDecl.End(), topDecl.End(), strings.TrimSuffix(decl.String(), "\n"))
+	if err != nil {
+		event.Error(ctx, "error making edit for fake candidate", err)
+		return
+	}
+
+	lit := "&" + name + "{}"
+	c.items = append(c.items, CompletionItem{
+		Label:               lit,
+		InsertText:          lit,
+		Detail:              "fake " + ifaceName,
+		Score:               float64(score) * stdScore,
+		Kind:                protocol.VariableCompletion,
+		AdditionalTextEdits: append(edits, declEdits...),
+	})
+}
@@ -155,7 +155,9 @@ Suffixes:
 					}
 				}
 
-				c.functionCallSnippet("", tparams, s.Params(), &snip)
+				if fn, ok := obj.(*types.Func); !ok || !c.subtestSnippet(fn, &snip) {
+					c.functionCallSnippet("", tparams, s.Params(), &snip)
+				}
 				if sig.Results().Len() == 1 {
 					funcType = sig.Results().At(0).Type()
 				}
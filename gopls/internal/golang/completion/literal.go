@@ -14,6 +14,7 @@ import (
 	"golang.org/x/tools/gopls/internal/golang"
 	"golang.org/x/tools/gopls/internal/golang/completion/snippet"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/typesutil"
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/typesinternal"
 )
@@ -392,7 +393,7 @@ func (c *completer) functionLiteral(ctx context.Context, sig *types.Signature, m
 // conventionalAcronyms contains conventional acronyms for type names
 // in lower case. For example, "ctx" for "context" and "err" for "error".
 //
-// Keep this up to date with golang.conventionalVarNames.
+// Keep this up to date with typesutil.conventionalVarNames.
 var conventionalAcronyms = map[string]string{
 	"context":        "ctx",
 	"error":          "err",
@@ -425,7 +426,7 @@ func abbreviateTypeName(s string) string {
 		return acr
 	}
 
-	return golang.AbbreviateVarName(s)
+	return typesutil.AbbreviateVarName(s)
 }
 
 // compositeLiteral returns a composite literal completion item for the given typeName.
@@ -5,9 +5,11 @@
 package completion
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"go/ast"
+	"go/scanner"
 	"go/token"
 	"go/types"
 	"log"
@@ -623,6 +625,138 @@ func (c *completer) addPostfixSnippetCandidates(ctx context.Context, sel *ast.Se
 	}
 }
 
+// addTestPostfixCandidate adds a "test!" candidate when completing a
+// dotted name such as F.test, T.M.test, or pkg.F.test at the top level
+// of a _test.go file, where the name before the final dot denotes a
+// function or method. The candidate expands into a table-driven test
+// of the function, the same as the one added by the "Add test for F"
+// code action.
+func (c *completer) addTestPostfixCandidate(ctx context.Context) {
+	if !c.opts.postfix || !strings.HasSuffix(c.pgf.URI.Path(), "_test.go") {
+		return
+	}
+
+	// Scan the line up to the cursor, which must consist of the
+	// dotted name alone.
+	tokFile := c.pgf.Tok
+	lineStart, err := safetoken.Offset(tokFile, tokFile.LineStart(safetoken.Line(tokFile, c.pos)))
+	if err != nil {
+		return
+	}
+	end, err := safetoken.Offset(tokFile, c.pos)
+	if err != nil {
+		return
+	}
+	var (
+		sc    scanner.Scanner
+		names []string // the names before the last dot
+		ident string   // the pending identifier
+	)
+	sc.Init(token.NewFileSet().AddFile("", -1, end-lineStart), c.pgf.Src[lineStart:end], nil, 0)
+scan:
+	for {
+		_, tok, lit := sc.Scan()
+		switch {
+		case tok == token.IDENT && ident == "":
+			ident = lit
+		case tok == token.PERIOD && ident != "":
+			names = append(names, ident)
+			ident = ""
+		case tok == token.SEMICOLON && lit == "\n":
+			// automatically inserted at end of input
+		case tok == token.EOF:
+			break scan
+		default:
+			return
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	afterDot := tokFile.Pos(lineStart + bytes.LastIndexByte(c.pgf.Src[lineStart:end], '.') + 1)
+
+	// Resolve the names to a function or method.
+	info := c.pkg.TypesInfo()
+	fileScope := info.Scopes[c.pgf.File]
+	if fileScope == nil {
+		return
+	}
+	_, obj := fileScope.LookupParent(names[0], token.NoPos)
+	if pkgName, ok := obj.(*types.PkgName); ok && len(names) > 1 {
+		obj = pkgName.Imported().Scope().Lookup(names[1])
+		if obj == nil || !obj.Exported() {
+			return
+		}
+		names = names[1:]
+	}
+	if tname, ok := obj.(*types.TypeName); ok && len(names) == 2 {
+		obj, _, _ = types.LookupFieldOrMethod(types.NewPointer(tname.Type()), false, c.pkg.Types(), names[1])
+		names = names[1:]
+	}
+	fn, ok := obj.(*types.Func)
+	if !ok || len(names) != 1 || fn.Pkg() == nil {
+		return
+	}
+	xtest := fn.Pkg() != c.pkg.Types()
+	if xtest && !fn.Exported() {
+		return
+	}
+
+	// Qualify the references to other packages, importing them as needed.
+	qual, importEdits := c.importingQualifier(fileScope)
+	ctors, err := c.snapshot.Constructors(ctx, c.pkg.Metadata(), metadata.PackagePath(fn.Pkg().Path()))
+	if err != nil {
+		return
+	}
+	// Construct the receiver by calling a helper of the test package.
+	var testFiles []*ast.File
+	for _, pgf := range c.pkg.CompiledGoFiles() {
+		if strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+			testFiles = append(testFiles, pgf.File)
+		}
+	}
+	helpers := golang.TestHelpersOf(testFiles...)
+	src, err := golang.TestFuncSource(fn, golang.TestFuncParams{
+		Constructors: ctors,
+		XTest:        xtest,
+		Qual:         qual,
+		Style:        golang.AssertionStyleOf(c.pgf.File),
+		Doubles:      golang.TestDoublesOf(c.pgf.File),
+		Helpers:      helpers,
+		Inputs:       golang.TestInputsOf(c.pgf.File),
+	})
+	if err != nil {
+		return
+	}
+	edits, err := importEdits()
+	if err != nil {
+		return
+	}
+
+	// Remove the dotted name, leaving the identifier after the last
+	// dot to be replaced by the snippet.
+	lineEdits, err := c.editText(tokFile.Pos(lineStart), afterDot, "")
+	if err != nil {
+		event.Error(ctx, "error calculating postfix edits", err)
+		return
+	}
+
+	score := c.matcher.Score("test")
+	if score <= 0 {
+		return
+	}
+	var snip snippet.Builder
+	snip.WriteText(strings.TrimSpace(string(src)))
+	c.items = append(c.items, CompletionItem{
+		Label:               "test!",
+		Detail:              "table-driven test",
+		Score:               float64(score) * 0.01,
+		Kind:                protocol.SnippetCompletion,
+		snippet:             &snip,
+		AdditionalTextEdits: append(lineEdits, edits...),
+	})
+}
+
 var postfixRulesOnce sync.Once
 
 func initPostfixRules() {
@@ -647,6 +781,44 @@ func inc(i int) int {
 	return i + 1
 }
 
+// importingQualifier returns a qualifier that names each package
+// other than the current one as the current file does, importing it if
+// needed, and a function that returns the edits that add the imports
+// used by the qualifier so far, or the first error it encountered.
+func (c *completer) importingQualifier(scope *types.Scope) (types.Qualifier, func() ([]protocol.TextEdit, error)) {
+	var (
+		edits    []protocol.TextEdit
+		imported = make(map[string]string)
+		qualErr  error
+	)
+	qual := func(p *types.Package) string {
+		if p == c.pkg.Types() {
+			return ""
+		}
+		name, ok := imported[p.Path()]
+		if !ok {
+			for _, spec := range c.pgf.File.Imports {
+				if pkgName := c.pkg.TypesInfo().PkgNameOf(spec); pkgName != nil && pkgName.Imported().Path() == p.Path() && pkgName.Name() != "_" {
+					imported[p.Path()] = pkgName.Name()
+					return pkgName.Name()
+				}
+			}
+			var (
+				importEdits []protocol.TextEdit
+				err         error
+			)
+			name, importEdits, err = c.importIfNeeded(p.Path(), scope)
+			if err != nil && qualErr == nil {
+				qualErr = err
+			}
+			imported[p.Path()] = name
+			edits = append(edits, importEdits...)
+		}
+		return name
+	}
+	return qual, func() ([]protocol.TextEdit, error) { return edits, qualErr }
+}
+
 // importIfNeeded returns the package identifier and any necessary
 // edits to import package pkgPath.
 func (c *completer) importIfNeeded(pkgPath string, scope *types.Scope) (string, []protocol.TextEdit, error) {
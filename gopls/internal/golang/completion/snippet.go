@@ -6,9 +6,11 @@ package completion
 
 import (
 	"go/ast"
+	"go/types"
 
 	"golang.org/x/tools/gopls/internal/golang/completion/snippet"
 	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
 )
 
 // structFieldSnippet calculates the snippet for struct literal field names.
@@ -61,27 +63,8 @@ func (c *completer) functionCallSnippet(name string, tparams, params []string, s
 		return
 	}
 
-	// If there is no suffix then we need to reuse existing call parens
-	// "()" if present. If there is an identifier suffix then we always
-	// need to include "()" since we don't overwrite the suffix.
-	if c.surrounding != nil && c.surrounding.Suffix() == "" && len(c.path) > 1 {
-		// If we are the left side (i.e. "Fun") part of a call expression,
-		// we don't want a snippet since there are already parens present.
-		switch n := c.path[1].(type) {
-		case *ast.CallExpr:
-			// The Lparen != Rparen check detects fudged CallExprs we
-			// inserted when fixing the AST. In this case, we do still need
-			// to insert the calling "()" parens.
-			if n.Fun == c.path[0] && n.Lparen != n.Rparen {
-				return
-			}
-		case *ast.SelectorExpr:
-			if len(c.path) > 2 {
-				if call, ok := c.path[2].(*ast.CallExpr); ok && call.Fun == c.path[1] && call.Lparen != call.Rparen {
-					return
-				}
-			}
-		}
+	if c.hasCallParens() {
+		return
 	}
 
 	snip.WriteText(name)
@@ -124,3 +107,100 @@ func (c *completer) functionCallSnippet(name string, tparams, params []string, s
 
 	snip.WriteText(")")
 }
+
+// hasCallParens reports whether the completion is the left side (i.e.
+// "Fun") part of a call expression that already has parens, in which
+// case a function call snippet would be redundant.
+func (c *completer) hasCallParens() bool {
+	// If there is no suffix then we need to reuse existing call parens
+	// "()" if present. If there is an identifier suffix then we always
+	// need to include "()" since we don't overwrite the suffix.
+	if c.surrounding != nil && c.surrounding.Suffix() == "" && len(c.path) > 1 {
+		switch n := c.path[1].(type) {
+		case *ast.CallExpr:
+			// The Lparen != Rparen check detects fudged CallExprs we
+			// inserted when fixing the AST. In this case, we do still need
+			// to insert the calling "()" parens.
+			if n.Fun == c.path[0] && n.Lparen != n.Rparen {
+				return true
+			}
+		case *ast.SelectorExpr:
+			if len(c.path) > 2 {
+				if call, ok := c.path[2].(*ast.CallExpr); ok && call.Fun == c.path[1] && call.Lparen != call.Rparen {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// subtestSnippet calculates the snippet for calls of the Run method of
+// testing.T or testing.B, which starts a subtest:
+//
+//	Run("name", func(t *testing.T) {
+//		<>
+//	})
+//
+// It names the subtest after the current test case, as tt.name, if the
+// call is within a loop over a table of test cases with a name field.
+// It reports whether fn is such a method.
+func (c *completer) subtestSnippet(fn *types.Func, snip *snippet.Builder) bool {
+	sig := fn.Signature()
+	if fn.Name() != "Run" || sig.Recv() == nil || sig.Params().Len() != 2 {
+		return false
+	}
+	ptr, ok := sig.Recv().Type().(*types.Pointer)
+	if !ok || !analysisinternal.IsTypeNamed(ptr.Elem(), "testing", "T", "B") {
+		return false
+	}
+	if !c.opts.completeFunctionCalls || c.hasCallParens() {
+		return true
+	}
+
+	snip.WriteText("(")
+	if name := c.testCaseName(); name != "" {
+		snip.WriteText(name)
+	} else {
+		snip.WriteText(`"`)
+		snip.WritePlaceholder(func(b *snippet.Builder) {
+			b.WriteText("name")
+		})
+		snip.WriteText(`"`)
+	}
+	snip.WriteText(", " + types.TypeString(sig.Params().At(1).Type(), c.qual) + " {\n\t")
+	snip.WriteFinalTabstop()
+	snip.WriteText("\n})")
+	return true
+}
+
+// testCaseName returns the expression for the name of the test case,
+// such as tt.name, if the completion is within the body of a loop over
+// a table of test cases, whose elements are structs with a string
+// field name or Name, or "" otherwise.
+func (c *completer) testCaseName() string {
+	for _, n := range c.path {
+		rng, ok := n.(*ast.RangeStmt)
+		if !ok || rng.Body == nil || c.pos <= rng.Body.Lbrace {
+			continue
+		}
+		id, ok := rng.Value.(*ast.Ident)
+		if !ok || id.Name == "_" {
+			continue
+		}
+		v, ok := c.pkg.TypesInfo().Defs[id].(*types.Var)
+		if !ok {
+			continue
+		}
+		st, ok := v.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		for f := range st.Fields() {
+			if (f.Name() == "name" || f.Name() == "Name") && types.Identical(f.Type(), types.Typ[types.String]) {
+				return id.Name + "." + f.Name()
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,378 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the refactor.rewrite.{add,remove}FieldNames code
+// actions, which convert struct literals between the positional
+// form T{1, "x"} and the keyed form T{A: 1, B: "x"}.
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// structLitAt returns the innermost struct composite literal
+// enclosing the selection, and its struct type.
+func structLitAt(info *types.Info, curFile cursor.Cursor, start, end token.Pos) (*ast.CompositeLit, *types.Struct) {
+	curSel, ok := curFile.FindPos(start, end)
+	if !ok {
+		return nil, nil
+	}
+	lit, ok := curSel.Node().(*ast.CompositeLit)
+	if !ok {
+		for cur := range curSel.Ancestors((*ast.CompositeLit)(nil)) {
+			lit = cur.Node().(*ast.CompositeLit)
+			break
+		}
+	}
+	if lit != nil {
+		if tStruct, ok := structUnder(info.TypeOf(lit)); ok {
+			return lit, tStruct
+		}
+	}
+	return nil, nil
+}
+
+// structUnder returns the struct type underlying t, if any,
+// looking through a pointer as in the elided type of &T{} elements.
+func structUnder(t types.Type) (*types.Struct, bool) {
+	if t == nil {
+		return nil, false
+	}
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	tStruct, ok := t.Underlying().(*types.Struct)
+	return tStruct, ok
+}
+
+// isPositionalLit reports whether lit is a non-empty struct literal
+// with positional (unkeyed) fields.
+func isPositionalLit(lit *ast.CompositeLit) bool {
+	return len(lit.Elts) > 0 && !is[*ast.KeyValueExpr](lit.Elts[0])
+}
+
+// isKeyedLit reports whether lit is a non-empty struct literal with
+// keyed fields that may be expressed in positional form in the
+// package pkg: all fields of the struct must be accessible, and, if
+// the fields are not in order, the values must be free of side
+// effects (see [isDuplicable]), since reordering them would change
+// the order of their evaluation.
+func isKeyedLit(info *types.Info, pkg *types.Package, tStruct *types.Struct, lit *ast.CompositeLit) bool {
+	if len(lit.Elts) == 0 || !is[*ast.KeyValueExpr](lit.Elts[0]) {
+		return false
+	}
+	for i := range tStruct.NumFields() {
+		if f := tStruct.Field(i); !f.Exported() && f.Pkg() != pkg {
+			return false // positional literal would be invalid
+		}
+	}
+	prev := -1
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return false
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		index := structFieldIndex(tStruct, key.Name)
+		if index < prev {
+			for _, elt := range lit.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); !ok || !isDuplicable(info, kv.Value) {
+					return false
+				}
+			}
+			break
+		}
+		prev = index
+	}
+	return true
+}
+
+// structFieldIndex returns the index of the named field of tStruct, or -1.
+func structFieldIndex(tStruct *types.Struct, name string) int {
+	for i := range tStruct.NumFields() {
+		if tStruct.Field(i).Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameTypeStructLits returns all struct literals in the file of the
+// same type as lit that satisfy the predicate, in source order,
+// including those nested within another result.
+func sameTypeStructLits(info *types.Info, curFile cursor.Cursor, lit *ast.CompositeLit, pred func(*ast.CompositeLit) bool) []*ast.CompositeLit {
+	want := info.TypeOf(lit)
+	var lits []*ast.CompositeLit
+	for cur := range curFile.Preorder((*ast.CompositeLit)(nil)) {
+		lit2 := cur.Node().(*ast.CompositeLit)
+		if t := info.TypeOf(lit2); t != nil && types.Identical(t, want) && pred(lit2) {
+			lits = append(lits, lit2)
+		}
+	}
+	return lits
+}
+
+// canConvertStructLit reports the titles of the "add field names"
+// (if keyed is true) or "remove field names" actions available for
+// the struct literal enclosing the selection: one for the selected
+// literal, and another for all n literals of the same type in the file
+// if there is more than one.
+func canConvertStructLit(pkg *cache.Package, pgf *parsego.File, start, end token.Pos, keyed bool) (title, allTitle string) {
+	info := pkg.TypesInfo()
+	lit, tStruct := structLitAt(info, pgf.Cursor, start, end)
+	if lit == nil {
+		return "", ""
+	}
+	pred := isPositionalLit
+	verb := "Add field names to"
+	if !keyed {
+		pred = func(lit *ast.CompositeLit) bool { return isKeyedLit(info, pkg.Types(), tStruct, lit) }
+		verb = "Remove field names from"
+	}
+	if !pred(lit) {
+		return "", ""
+	}
+	name := types.TypeString(info.TypeOf(lit), typesinternal.FileQualifier(pgf.File, pkg.Types()))
+	if strings.HasPrefix(name, "struct{") {
+		name = "struct"
+	}
+	title = fmt.Sprintf("%s %s literal", verb, name)
+	if n := len(sameTypeStructLits(info, pgf.Cursor, lit, pred)); n > 1 {
+		allTitle = fmt.Sprintf("%s all %d %s literals in file", verb, n, name)
+	}
+	return title, allTitle
+}
+
+// addFieldNames is a singleFileFixer that converts the selected
+// positional struct literal to keyed form.
+func addFieldNames(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	return convertStructLits(pkg, pgf, start, end, true, false)
+}
+
+// addFieldNamesAll is a singleFileFixer that converts all positional
+// literals of the selected struct type in the file to keyed form.
+func addFieldNamesAll(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	return convertStructLits(pkg, pgf, start, end, true, true)
+}
+
+// removeFieldNames is a singleFileFixer that converts the selected
+// keyed struct literal to positional form.
+func removeFieldNames(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	return convertStructLits(pkg, pgf, start, end, false, false)
+}
+
+// removeFieldNamesAll is a singleFileFixer that converts all keyed
+// literals of the selected struct type in the file to positional form.
+func removeFieldNamesAll(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	return convertStructLits(pkg, pgf, start, end, false, true)
+}
+
+// convertStructLits converts the struct literal enclosing the
+// selection (or, if all is set, all literals of its type in the file)
+// to keyed form (if keyed is set) or positional form.
+func convertStructLits(pkg *cache.Package, pgf *parsego.File, start, end token.Pos, keyed, all bool) (*token.FileSet, *analysis.SuggestedFix, error) {
+	info := pkg.TypesInfo()
+	lit, tStruct := structLitAt(info, pgf.Cursor, start, end)
+	if lit == nil {
+		return nil, nil, fmt.Errorf("no struct literal selected")
+	}
+	pred := isPositionalLit
+	if !keyed {
+		pred = func(lit *ast.CompositeLit) bool { return isKeyedLit(info, pkg.Types(), tStruct, lit) }
+	}
+	if !pred(lit) {
+		return nil, nil, fmt.Errorf("struct literal is already in the requested form")
+	}
+	lits := []*ast.CompositeLit{lit}
+	if all {
+		lits = sameTypeStructLits(info, pgf.Cursor, lit, pred)
+	}
+
+	// Convert the literals innermost first, so that the conversion of
+	// each literal may incorporate those of the literals nested
+	// within it.
+	var edits []analysis.TextEdit
+	for i := len(lits) - 1; i >= 0; i-- {
+		lit := lits[i]
+		var nested, rest []analysis.TextEdit
+		for _, edit := range edits {
+			if lit.Lbrace < edit.Pos && edit.End <= lit.Rbrace {
+				nested = append(nested, edit)
+			} else {
+				rest = append(rest, edit)
+			}
+		}
+		var (
+			litEdits []analysis.TextEdit
+			err      error
+		)
+		if keyed {
+			litEdits, err = keyStructLit(tStruct, lit, nested)
+		} else {
+			litEdits, err = unkeyStructLit(pkg.Types(), pgf, tStruct, lit, nested)
+		}
+		if err != nil {
+			if all {
+				continue // skip literals that can't be converted
+			}
+			return nil, nil, err
+		}
+		edits = append(rest, litEdits...)
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// keyStructLit returns edits that insert field names before each
+// element of the positional struct literal lit, followed by the edits
+// of the literals nested within it.
+func keyStructLit(tStruct *types.Struct, lit *ast.CompositeLit, nested []analysis.TextEdit) ([]analysis.TextEdit, error) {
+	if len(lit.Elts) != tStruct.NumFields() {
+		return nil, fmt.Errorf("positional literal has %d elements, want %d", len(lit.Elts), tStruct.NumFields())
+	}
+	var edits []analysis.TextEdit
+	for i, elt := range lit.Elts {
+		edits = append(edits, analysis.TextEdit{
+			Pos:     elt.Pos(),
+			End:     elt.Pos(),
+			NewText: []byte(tStruct.Field(i).Name() + ": "),
+		})
+	}
+	return append(edits, nested...), nil
+}
+
+// unkeyStructLit returns edits that convert the keyed struct literal
+// lit to positional form, supplying zero values for omitted fields.
+//
+// If the elements already appear in field order with none omitted,
+// only the keys are deleted, preserving the layout and any comments
+// of the literal. Otherwise the list of elements is reconstructed, in
+// which case the literal must not contain comments. The nested edits,
+// of the literals nested within lit, are included in the result,
+// applied to the values of a reconstructed list.
+func unkeyStructLit(pkg *types.Package, pgf *parsego.File, tStruct *types.Struct, lit *ast.CompositeLit, nested []analysis.TextEdit) ([]analysis.TextEdit, error) {
+	values := make([]ast.Expr, tStruct.NumFields())
+	inOrder := len(lit.Elts) == tStruct.NumFields()
+	for i, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, fmt.Errorf("mixture of keyed and positional elements")
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("invalid key")
+		}
+		index := structFieldIndex(tStruct, key.Name)
+		if index < 0 {
+			return nil, fmt.Errorf("unknown field %s", key.Name)
+		}
+		values[index] = kv.Value
+		if index != i {
+			inOrder = false
+		}
+	}
+
+	if inOrder {
+		var edits []analysis.TextEdit
+		for _, elt := range lit.Elts {
+			kv := elt.(*ast.KeyValueExpr)
+			edits = append(edits, analysis.TextEdit{Pos: kv.Pos(), End: kv.Value.Pos()})
+		}
+		return append(edits, nested...), nil
+	}
+
+	for _, cg := range pgf.File.Comments {
+		if cg.Pos() > lit.Lbrace && cg.End() < lit.Rbrace {
+			return nil, fmt.Errorf("cannot reorder elements of a literal containing comments")
+		}
+	}
+
+	// Supply zero values for omitted fields. They must not refer to
+	// packages that are not imported by the file.
+	imported := make(map[string]bool)
+	for _, imp := range pgf.File.Imports {
+		if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+			imported[path] = true
+		}
+	}
+	fileQual := typesinternal.FileQualifier(pgf.File, pkg)
+	missingImport := false
+	qual := func(p *types.Package) string {
+		if p != pkg && !imported[p.Path()] {
+			missingImport = true
+		}
+		return fileQual(p)
+	}
+	texts := make([]string, len(values))
+	for i, v := range values {
+		if v != nil {
+			start, end, err := safetoken.Offsets(pgf.Tok, v.Pos(), v.End())
+			if err != nil {
+				return nil, err
+			}
+			var valueEdits []diff.Edit
+			for _, edit := range nested {
+				if v.Pos() <= edit.Pos && edit.End <= v.End() {
+					editStart, editEnd, err := safetoken.Offsets(pgf.Tok, edit.Pos, edit.End)
+					if err != nil {
+						return nil, err
+					}
+					valueEdits = append(valueEdits, diff.Edit{Start: editStart - start, End: editEnd - start, New: string(edit.NewText)})
+				}
+			}
+			texts[i], err = diff.Apply(string(pgf.Src[start:end]), valueEdits)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			zero, ok := typeutil.ZeroValue(tStruct.Field(i).Type(), &typeutil.ValueConfig{Package: pkg, Qualifier: qual})
+			if !ok || missingImport {
+				return nil, fmt.Errorf("cannot express zero value of field %s", tStruct.Field(i).Name())
+			}
+			texts[i] = zero
+		}
+	}
+
+	// Preserve a multi-line layout, one element per line.
+	var buf bytes.Buffer
+	if safetoken.Line(pgf.Tok, lit.Lbrace) != safetoken.Line(pgf.Tok, lit.Rbrace) && len(lit.Elts) > 0 {
+		indent, err := calculateIndentation(pgf.Src, pgf.Tok, lit.Elts[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, text := range texts {
+			buf.WriteString("\n" + indent + text + ",")
+		}
+		rbraceIndent, err := calculateIndentation(pgf.Src, pgf.Tok, &ast.Ident{NamePos: lit.Rbrace})
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n" + rbraceIndent)
+	} else {
+		buf.WriteString(strings.Join(texts, ", "))
+	}
+	return []analysis.TextEdit{{
+		Pos:     lit.Lbrace + 1,
+		End:     lit.Rbrace,
+		NewText: buf.Bytes(),
+	}}, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the fix for the contextfield analyzer, which
+// passes the context stored in a struct field as a parameter instead.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/edge"
+)
+
+// contextFieldAt returns the field of type context.Context whose name
+// encloses [start, end), and the package-level struct type T that
+// declares it.
+func contextFieldAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*types.Var, *types.Named) {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	if len(path) < 2 {
+		return nil, nil
+	}
+	id, ok := path[0].(*ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+	field, ok := pkg.TypesInfo().Defs[id].(*types.Var)
+	if !ok || !field.IsField() || !analysisinternal.IsTypeNamed(field.Type(), "context", "Context") {
+		return nil, nil
+	}
+	for _, n := range path {
+		if spec, ok := n.(*ast.TypeSpec); ok {
+			named, ok := pkg.TypesInfo().Defs[spec.Name].Type().(*types.Named)
+			if !ok || named.Obj().Parent() != pkg.Types().Scope() {
+				return nil, nil
+			}
+			strct, ok := named.Underlying().(*types.Struct)
+			if !ok {
+				return nil, nil
+			}
+			for f := range strct.Fields() {
+				if f == field {
+					return field, named
+				}
+			}
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// contextFieldToParam passes the context stored in the selected field
+// of type context.Context of struct type T as a parameter instead: it
+// adds a parameter ctx of type context.Context to each method of T
+// that reads the field through its receiver, as for
+// [AddContextParam], and replaces those reads by ctx. A method that
+// already has a context.Context parameter uses it instead. The field
+// itself is left in place, since it may be set elsewhere.
+func contextFieldToParam(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng protocol.Range) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(rng)
+	if err != nil {
+		return nil, err
+	}
+	field, named := contextFieldAt(pkg, pgf, start, end)
+	if field == nil {
+		return nil, fmt.Errorf("no context.Context field selected")
+	}
+	pkgs, err := typeCheckReverseDependencies(ctx, snapshot, pgf.URI, false)
+	if err != nil {
+		return nil, err
+	}
+	a := &contextAdder{
+		workspaceChange: newWorkspaceChange(),
+		pkgs:            pkgs,
+	}
+
+	// Replace the reads of the field in each method of T, and find
+	// the methods that gain the parameter.
+	info := pkg.TypesInfo()
+	found := false
+	for _, pgf := range pkg.CompiledGoFiles() {
+		for _, decl := range pgf.File.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv == nil || decl.Body == nil {
+				continue
+			}
+			fn, ok := info.Defs[decl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			recv := fn.Signature().Recv()
+			t := recv.Type()
+			if ptr, ok := t.(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			if t, ok := types.Unalias(t).(*types.Named); !ok || t.Origin() != named {
+				continue
+			}
+			curBody, ok := pgf.Cursor.FindNode(decl.Body)
+			if !ok {
+				continue
+			}
+			var reads []*ast.SelectorExpr
+			for cur := range curBody.Preorder((*ast.SelectorExpr)(nil)) {
+				sel := cur.Node().(*ast.SelectorExpr)
+				seln, ok := info.Selections[sel]
+				if !ok {
+					continue // qualified identifier
+				}
+				if v, ok := seln.Obj().(*types.Var); !ok || v.Origin() != field {
+					continue
+				}
+				if x, ok := ast.Unparen(sel.X).(*ast.Ident); !ok || info.Uses[x] != recv {
+					continue
+				}
+				switch ek, _ := cur.Edge(); ek {
+				case edge.AssignStmt_Lhs:
+					continue // a write
+				case edge.UnaryExpr_X:
+					a.addConflict(pkg, sel.Pos(), "address of %s is taken", field.Name())
+					continue
+				}
+				reads = append(reads, sel)
+			}
+			if len(reads) == 0 {
+				continue
+			}
+			found = true
+
+			// Use the existing context.Context parameter, if any;
+			// otherwise add one.
+			var param *types.Var
+			for v := range fn.Signature().Params().Variables() {
+				if analysisinternal.IsTypeNamed(v.Type(), "context", "Context") {
+					param = v
+					break
+				}
+			}
+			name := "ctx"
+			if param != nil {
+				name = param.Name()
+				if name == "" || name == "_" {
+					a.addConflict(pkg, decl.Name.Pos(), "context.Context parameter of %s is unnamed", fn.Name())
+					continue
+				}
+			} else if canAddContextParam(info, pgf, decl, fn) {
+				a.funcs = append(a.funcs, &contextFunc{pkg, pgf, decl, fn})
+			} else {
+				a.addConflict(pkg, decl.Name.Pos(), "cannot add a context.Context parameter to %s", fn.Name())
+				continue
+			}
+			for _, sel := range reads {
+				if param != nil {
+					if _, obj := info.Scopes[pgf.File].Innermost(sel.Pos()).LookupParent(name, sel.Pos()); obj != param {
+						a.addConflict(pkg, sel.Pos(), "parameter %s is shadowed", name)
+						continue
+					}
+				}
+				if err := a.addEdit(pgf, sel.Pos(), sel.End(), name); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no method of %s reads field %s", named.Obj().Name(), field.Name())
+	}
+	for _, f := range a.funcs {
+		if err := a.addParam(f); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.conflictError("cannot pass %s as a parameter", field.Name()); err != nil {
+		return nil, err
+	}
+	return a.documentChanges(ctx, snapshot)
+}
@@ -0,0 +1,541 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code actions "Convert assertions to standard
+// library style" and "Convert assertions to testify", which rewrite
+// the assertions of a test file from one style to the other.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+const (
+	testifyAssertPath  = "github.com/stretchr/testify/assert"
+	testifyRequirePath = "github.com/stretchr/testify/require"
+	testifyModule      = "github.com/stretchr/testify"
+)
+
+// An assertionRewrite is the replacement of an assertion statement in
+// one style by an equivalent statement in the other.
+type assertionRewrite struct {
+	stmt ast.Stmt
+	text string   // the replacement, indented as stmt
+	pkg  string   // path of the package that text refers to, if any
+	uses []string // paths of the packages that stmt refers to
+}
+
+// testifyRewrites returns the rewrites of the testify assertions of
+// the file, such as assert.Equal(t, want, got), into if statements in
+// the standard library style, such as
+//
+//	if got != want {
+//		t.Errorf("got %v, want %v", got, want)
+//	}
+//
+// Assertions that pass messages, or whose functions have no simple
+// equivalent, are left unchanged.
+func testifyRewrites(info *types.Info, pgf *parsego.File) []assertionRewrite {
+	var rewrites []assertionRewrite
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		stmt, ok := n.(*ast.ExprStmt)
+		if !ok {
+			return true
+		}
+		call, ok := stmt.X.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 || call.Ellipsis.IsValid() {
+			return true
+		}
+		fn := typeutil.StaticCallee(info, call)
+		if fn == nil || fn.Pkg() == nil || fn.Signature().Recv() != nil {
+			return true
+		}
+		fail := ""
+		switch fn.Pkg().Path() {
+		case testifyAssertPath:
+			fail = "Error"
+		case testifyRequirePath:
+			fail = "Fatal"
+		default:
+			return true
+		}
+		text := func(e ast.Expr) string { s, _ := nodeText(pgf, e); return s }
+		t, args := text(call.Args[0]), call.Args[1:]
+		var (
+			cond string
+			msg  string // arguments of t.Error or t.Errorf
+			init string // declaration of got or err, if the value is not simple
+			pkg  string // path of the package to which cond refers, if any
+		)
+		// bind returns the expression for the value e, binding it to
+		// a variable name in the init statement if it may have side
+		// effects, as it is used twice; other is the expression with
+		// which it is compared, which must not refer to name.
+		bind := func(e ast.Expr, name, other string) (string, bool) {
+			if !hasCall(e) {
+				return text(e), true
+			}
+			if identRefersTo(other, name) {
+				return "", false
+			}
+			init = name + " := " + text(e) + "; "
+			return name, true
+		}
+		switch {
+		case (fn.Name() == "Equal" || fn.Name() == "NotEqual") && len(args) == 2:
+			want := text(args[0])
+			got, ok := bind(args[1], "got", want)
+			if !ok {
+				return true
+			}
+			op, neg := "!=", "!"
+			if fn.Name() == "NotEqual" {
+				op, neg = "==", ""
+			}
+			if comparableOperands(info, args[0], args[1]) {
+				cond = fmt.Sprintf("%s %s %s", got, op, want)
+			} else {
+				reflect, _, _ := analysisinternal.AddImport(info, pgf.File, "reflect", "reflect", "DeepEqual", stmt.Pos())
+				cond = fmt.Sprintf("%s%s.DeepEqual(%s, %s)", neg, reflect, got, want)
+				pkg = "reflect"
+			}
+			if fn.Name() == "Equal" {
+				msg = fmt.Sprintf(`"got %%v, want %%v", %s, %s`, got, want)
+			} else {
+				msg = fmt.Sprintf(`"got %%v, want another value", %s`, got)
+			}
+		case fn.Name() == "NoError" && len(args) == 1:
+			err, _ := bind(args[0], "err", "")
+			cond, msg = err+" != nil", fmt.Sprintf(`"unexpected error: %%v", %s`, err)
+		case fn.Name() == "Error" && len(args) == 1:
+			err, _ := bind(args[0], "err", "")
+			cond, msg = err+" == nil", `"got no error, want one"`
+		case fn.Name() == "True" && len(args) == 1:
+			cond, msg = negate(args[0], text), `"got false, want true"`
+		case fn.Name() == "False" && len(args) == 1:
+			cond, msg = text(args[0]), `"got true, want false"`
+		case fn.Name() == "Nil" && len(args) == 1 && isNillable(info.TypeOf(args[0])):
+			got, _ := bind(args[0], "got", "")
+			cond, msg = got+" != nil", fmt.Sprintf(`"got %%v, want nil", %s`, got)
+		case fn.Name() == "NotNil" && len(args) == 1 && isNillable(info.TypeOf(args[0])):
+			got, _ := bind(args[0], "got", "")
+			cond, msg = got+" == nil", `"got nil, want non-nil"`
+		case fn.Name() == "Len" && len(args) == 2:
+			want := text(args[1])
+			got, ok := bind(args[0], "got", want)
+			if !ok {
+				return true
+			}
+			cond, msg = fmt.Sprintf("len(%s) != %s", got, want), fmt.Sprintf(`"len = %%d, want %%d", len(%s), %s`, got, want)
+		default:
+			return true
+		}
+		method := fail
+		if strings.HasPrefix(msg, `"`) && strings.Contains(msg, "%") {
+			method += "f"
+		}
+		indent := lineIndent(pgf, stmt.Pos())
+		rewrites = append(rewrites, assertionRewrite{
+			stmt: stmt,
+			text: fmt.Sprintf("if %s%s {\n%s\t%s.%s(%s)\n%s}", init, cond, indent, t, method, msg, indent),
+			pkg:  pkg,
+			uses: []string{fn.Pkg().Path()},
+		})
+		return true
+	})
+	return rewrites
+}
+
+// stdRewrites returns the rewrites of the if statements of the file
+// that check a condition and report a failure, such as
+//
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//
+// into testify assertions, such as require.NoError(t, err): those of
+// the require package for checks that end the test, and those of the
+// assert package otherwise.
+func stdRewrites(info *types.Info, pgf *parsego.File) []assertionRewrite {
+	var rewrites []assertionRewrite
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		stmt, ok := n.(*ast.IfStmt)
+		if !ok || stmt.Init != nil || stmt.Else != nil || len(stmt.Body.List) != 1 {
+			return true
+		}
+		body, ok := stmt.Body.List[0].(*ast.ExprStmt)
+		if !ok {
+			return true
+		}
+		call, ok := body.X.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !isTestingTB(info.TypeOf(sel.X)) {
+			return true
+		}
+		pkg := testifyAssertPath
+		switch sel.Sel.Name {
+		case "Error", "Errorf":
+		case "Fatal", "Fatalf":
+			pkg = testifyRequirePath
+		default:
+			return true
+		}
+		text := func(e ast.Expr) string { s, _ := nodeText(pgf, e); return s }
+		t := text(sel.X)
+		var (
+			assertion string
+			args      []string
+			uses      []string // packages to which the condition refers
+		)
+		isError := func(e ast.Expr) bool {
+			return types.Identical(info.TypeOf(e), types.Universe.Lookup("error").Type())
+		}
+		switch cond := ast.Unparen(stmt.Cond).(type) {
+		case *ast.BinaryExpr:
+			x, y := ast.Unparen(cond.X), ast.Unparen(cond.Y)
+			if isNilIdent(info, x) {
+				x, y = y, x
+			}
+			switch {
+			case isNilIdent(info, y) && cond.Op == token.NEQ:
+				assertion, args = "Nil", []string{text(x)}
+				if isError(x) {
+					assertion = "NoError"
+				}
+			case isNilIdent(info, y) && cond.Op == token.EQL:
+				assertion, args = "NotNil", []string{text(x)}
+				if isError(x) {
+					assertion = "Error"
+				}
+			case cond.Op == token.NEQ || cond.Op == token.EQL:
+				got, want := x, y
+				if looksWanted(got) && !looksWanted(want) {
+					got, want = want, got
+				}
+				assertion = "Equal"
+				if cond.Op == token.EQL {
+					assertion = "NotEqual"
+				}
+				args = []string{text(want), text(got)}
+				if call, ok := got.(*ast.CallExpr); ok && cond.Op == token.NEQ && len(call.Args) == 1 {
+					if id, ok := call.Fun.(*ast.Ident); ok && id.Name == "len" && info.Uses[id] == types.Universe.Lookup("len") {
+						assertion, args = "Len", []string{text(call.Args[0]), text(want)}
+					}
+				}
+			}
+		case *ast.UnaryExpr:
+			if cond.Op != token.NOT {
+				break
+			}
+			if call, ok := ast.Unparen(cond.X).(*ast.CallExpr); ok && len(call.Args) == 2 {
+				if fn := typeutil.StaticCallee(info, call); fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "reflect" && fn.Name() == "DeepEqual" {
+					got, want := call.Args[0], call.Args[1]
+					if looksWanted(got) && !looksWanted(want) {
+						got, want = want, got
+					}
+					assertion, args, uses = "Equal", []string{text(want), text(got)}, []string{"reflect"}
+					break
+				}
+			}
+			assertion, args = "True", []string{text(cond.X)}
+		}
+		// The type of a comparison is untyped bool.
+		if basic, ok := info.TypeOf(stmt.Cond).Underlying().(*types.Basic); ok && assertion == "" && basic.Info()&types.IsBoolean != 0 {
+			assertion, args = "False", []string{text(stmt.Cond)}
+		}
+		if assertion == "" {
+			return true
+		}
+		name, _, _ := analysisinternal.AddImport(info, pgf.File, pkg[strings.LastIndex(pkg, "/")+1:], pkg, assertion, stmt.Pos())
+		rewrites = append(rewrites, assertionRewrite{
+			stmt: stmt,
+			text: fmt.Sprintf("%s.%s(%s)", name, assertion, strings.Join(append([]string{t}, args...), ", ")),
+			pkg:  pkg,
+			uses: uses,
+		})
+		return false
+	})
+	return rewrites
+}
+
+// convertToStdAssertions rewrites the testify assertions of the file
+// in the standard library style (see [testifyRewrites]).
+func convertToStdAssertions(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	rewrites := testifyRewrites(pkg.TypesInfo(), pgf)
+	if len(rewrites) == 0 {
+		return nil, nil, fmt.Errorf("no testify assertions to convert")
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: assertionEdits(pkg.TypesInfo(), pgf, rewrites)}, nil
+}
+
+// assertionsToTestify returns the changes of the "Convert assertions
+// to testify" fix (see [convertToTestifyAssertions]), and those of the
+// go.mod and go.sum files that require testify, if the module does not
+// require it yet (see [testModuleChanges]).
+func assertionsToTestify(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng protocol.Range) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(rng)
+	if err != nil {
+		return nil, err
+	}
+	fset, fix, err := convertToTestifyAssertions(pkg, pgf, start, end)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := suggestedFixToDocumentChange(ctx, snapshot, fset, fix)
+	if err != nil {
+		return nil, err
+	}
+	modChanges, err := testModuleChanges(ctx, snapshot, testModFile(snapshot, pkg.Metadata()), slices.Values([]string{testifyAssertPath, testifyRequirePath}))
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, modChanges...), nil
+}
+
+// convertToTestifyAssertions rewrites the assertions of the file in
+// the standard library style as testify assertions (see
+// [stdRewrites]).
+func convertToTestifyAssertions(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	rewrites := stdRewrites(pkg.TypesInfo(), pgf)
+	if len(rewrites) == 0 {
+		return nil, nil, fmt.Errorf("no assertions to convert")
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: assertionEdits(pkg.TypesInfo(), pgf, rewrites)}, nil
+}
+
+// assertionEdits returns the edits that apply the rewrites to the
+// file, adding the imports of the packages to which they refer and
+// deleting those of the packages to which the file no longer refers.
+func assertionEdits(info *types.Info, pgf *parsego.File, rewrites []assertionRewrite) []analysis.TextEdit {
+	var (
+		edits   []analysis.TextEdit
+		added   = make(map[string]bool)
+		removed = make(map[string]int) // number of references removed, by path
+	)
+	for _, r := range rewrites {
+		edits = append(edits, analysis.TextEdit{Pos: r.stmt.Pos(), End: r.stmt.End(), NewText: []byte(r.text)})
+		if r.pkg != "" && !added[r.pkg] {
+			added[r.pkg] = true
+			name := r.pkg[strings.LastIndex(r.pkg, "/")+1:]
+			_, _, importEdits := analysisinternal.AddImport(info, pgf.File, name, r.pkg, "", r.stmt.Pos())
+			edits = append(edits, importEdits...)
+		}
+		for _, path := range r.uses {
+			removed[path]++
+		}
+	}
+
+	// Delete the imports of the packages that are no longer used:
+	// those whose references were all within rewritten statements.
+	for _, spec := range pgf.File.Imports {
+		pkgName := info.PkgNameOf(spec)
+		if pkgName == nil || removed[pkgName.Imported().Path()] == 0 || added[pkgName.Imported().Path()] {
+			continue
+		}
+		used := false
+		for id, obj := range info.Uses {
+			if obj == pkgName && !within(id, rewrites) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			edits = append(edits, deleteImportEdit(pgf, spec)...)
+		}
+	}
+	return edits
+}
+
+// usesTestify reports whether the file imports a package of testify.
+func usesTestify(file *ast.File) bool {
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil && strings.HasPrefix(path, testifyModule+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether n is within the statement of one of the
+// rewrites.
+func within(n ast.Node, rewrites []assertionRewrite) bool {
+	for _, r := range rewrites {
+		if r.stmt.Pos() <= n.Pos() && n.End() <= r.stmt.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteImportEdit returns the edit that deletes the line of the
+// import spec from the file, or none if the spec does not occupy a
+// line of its own within a parenthesized import declaration.
+func deleteImportEdit(pgf *parsego.File, spec *ast.ImportSpec) []analysis.TextEdit {
+	for _, decl := range pgf.File.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		for _, s := range decl.Specs {
+			if s != spec {
+				continue
+			}
+			var node ast.Node = spec
+			if !decl.Lparen.IsValid() {
+				node = decl
+			}
+			line := safetoken.Line(pgf.Tok, node.Pos())
+			if safetoken.Line(pgf.Tok, node.End()) != line ||
+				node == spec && (safetoken.Line(pgf.Tok, decl.Lparen) == line || safetoken.Line(pgf.Tok, decl.Rparen) == line) {
+				return nil
+			}
+			start, end := pgf.Tok.LineStart(line), pgf.File.FileEnd
+			if line < pgf.Tok.LineCount() {
+				end = pgf.Tok.LineStart(line + 1)
+			}
+			return []analysis.TextEdit{{Pos: start, End: end}}
+		}
+	}
+	return nil
+}
+
+// hasCall reports whether the expression e contains a call, and so
+// may have side effects.
+func hasCall(e ast.Expr) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		if _, ok := n.(*ast.CallExpr); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// identRefersTo reports whether the source text of an expression
+// contains the identifier name.
+func identRefersTo(expr, name string) bool {
+	for _, word := range strings.FieldsFunc(expr, func(r rune) bool {
+		return !(r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		if word == name {
+			return true
+		}
+	}
+	return false
+}
+
+// comparableOperands reports whether the values of x and y may be
+// compared with ==, giving the same result as testify's equality,
+// which requires equal types.
+func comparableOperands(info *types.Info, x, y ast.Expr) bool {
+	tx, ty := info.TypeOf(x), info.TypeOf(y)
+	if tx == nil || ty == nil {
+		return false
+	}
+	if isUntyped(tx) {
+		tx = ty
+	} else if isUntyped(ty) {
+		ty = tx
+	}
+	return types.Identical(tx, ty) && types.Comparable(tx) && !types.IsInterface(tx)
+}
+
+// isNillable reports whether a value of type t may be nil.
+func isNillable(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Map, *types.Slice, *types.Chan, *types.Signature:
+		return true
+	}
+	return false
+}
+
+// isNilIdent reports whether e denotes the predeclared nil.
+func isNilIdent(info *types.Info, e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && info.Uses[id] == types.Universe.Lookup("nil")
+}
+
+// isTestingTB reports whether t is *testing.T, *testing.B, or
+// testing.TB.
+func isTestingTB(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if analysisinternal.IsTypeNamed(t, "testing", "TB") {
+		return true
+	}
+	ptr, ok := t.(*types.Pointer)
+	return ok && analysisinternal.IsTypeNamed(ptr.Elem(), "testing", "T", "B")
+}
+
+// looksWanted reports whether the expression e denotes an expected
+// value: a literal, or a variable or field named want or expected,
+// such as tt.wantName.
+func looksWanted(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.BasicLit, *ast.CompositeLit:
+		return true
+	case *ast.Ident:
+		name := strings.ToLower(e.Name)
+		return strings.HasPrefix(name, "want") || strings.HasPrefix(name, "expected")
+	case *ast.SelectorExpr:
+		return looksWanted(e.Sel)
+	}
+	return false
+}
+
+// negate returns the negation of the boolean expression e.
+func negate(e ast.Expr, text func(ast.Expr) string) string {
+	switch e := e.(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			return text(e.X)
+		}
+	case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.ParenExpr, *ast.IndexExpr:
+		return "!" + text(e)
+	}
+	return "!(" + text(e) + ")"
+}
+
+// lineIndent returns the indentation of the line of the file that
+// contains pos.
+func lineIndent(pgf *parsego.File, pos token.Pos) string {
+	start, err := safetoken.Offset(pgf.Tok, pgf.Tok.LineStart(safetoken.Line(pgf.Tok, pos)))
+	if err != nil {
+		return ""
+	}
+	end := start
+	for end < len(pgf.Src) && (pgf.Src[end] == ' ' || pgf.Src[end] == '\t') {
+		end++
+	}
+	return string(pgf.Src[start:end])
+}
@@ -0,0 +1,289 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the FunctionCoverage, DocumentCoverage, and
+// BrowseCoverage commands.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"html"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/cover"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	goplsastutil "golang.org/x/tools/gopls/internal/util/astutil"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+)
+
+// FunctionCoverage reports the coverage, according to the coverage
+// profile args.Profile, of each function and method declared in the
+// specified Go file, or in the files of the package in the specified
+// directory, and, if args.Recursive is set, of the workspace packages
+// in the subdirectories of the directory.
+//
+// Files that the profile does not mention, such as those of untested
+// packages, are not reported, nor are functions whose bodies contain
+// no statements.
+func FunctionCoverage(ctx context.Context, snapshot *cache.Snapshot, args command.FunctionCoverageArgs) (command.FunctionCoverageResult, error) {
+	var result command.FunctionCoverageResult
+
+	fh, err := snapshot.ReadFile(ctx, args.Profile)
+	if err != nil {
+		return result, err
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return result, err
+	}
+	profiles, err := cover.ParseProfilesFromReader(bytes.NewReader(content))
+	if err != nil {
+		return result, fmt.Errorf("parsing coverage profile: %v", err)
+	}
+	coverage := snapshot.CoverageOfProfiles(profiles)
+	if len(coverage) == 0 {
+		return result, fmt.Errorf("coverage profile %s names no files in the workspace", args.Profile.Path())
+	}
+
+	// Select the files, and the paths of their packages.
+	uri := args.URI
+	var mps []*metadata.Package
+	if args.Recursive {
+		mps, err = snapshot.WorkspaceMetadata(ctx)
+		if err != nil {
+			return result, err
+		}
+	} else {
+		mps = slices.Collect(maps.Values(snapshot.MetadataGraph().Packages))
+	}
+	pkgPaths := make(map[protocol.DocumentURI]metadata.PackagePath)
+	for _, mp := range mps {
+		if mp.ForTest != "" || metadata.IsCommandLineArguments(mp.ID) {
+			continue
+		}
+		for _, f := range mp.CompiledGoFiles {
+			if f == uri || f.Dir() == uri || args.Recursive && uri.Encloses(f) {
+				pkgPaths[f] = mp.PkgPath
+			}
+		}
+	}
+	if len(pkgPaths) == 0 {
+		return result, fmt.Errorf("no Go files in %s", uri)
+	}
+
+	result.Functions = []command.FuncCoverage{} // non-nil, for JSON
+	for _, uri := range slices.Sorted(maps.Keys(pkgPaths)) {
+		cov := coverage[uri]
+		if cov == nil {
+			continue
+		}
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return result, err
+		}
+		pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+		if err != nil {
+			return result, err
+		}
+		for _, decl := range pgf.File.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Body == nil {
+				continue
+			}
+			name := decl.Name.Name
+			if decl.Recv != nil {
+				_, recv, _ := goplsastutil.UnpackRecv(decl.Recv.List[0].Type)
+				if recv == nil {
+					continue
+				}
+				name = recv.Name + "." + name
+			}
+			covered, uncovered := cov.Lines(
+				safetoken.Line(pgf.Tok, decl.Body.Lbrace),
+				safetoken.Line(pgf.Tok, decl.Body.Rbrace))
+			if covered+uncovered == 0 {
+				continue
+			}
+			loc, err := pgf.NodeLocation(decl.Name)
+			if err != nil {
+				return result, err
+			}
+			result.Functions = append(result.Functions, command.FuncCoverage{
+				Name:      name,
+				PkgPath:   string(pkgPaths[uri]),
+				Location:  loc,
+				Covered:   covered,
+				Uncovered: uncovered,
+			})
+		}
+	}
+	return result, nil
+}
+
+// DocumentCoverage reports the ranges of the statements of the file
+// fh that the tests covered and did not cover, according to the
+// coverage that the snapshot records for it, if any.
+func DocumentCoverage(snapshot *cache.Snapshot, fh file.Handle) (command.DocumentCoverageResult, error) {
+	result := command.DocumentCoverageResult{Version: fh.Version()}
+	cov := snapshot.Coverage(fh.URI())
+	if cov == nil {
+		return result, nil
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return result, err
+	}
+	m := protocol.NewMapper(fh.URI(), content)
+	for _, b := range cov.Blocks {
+		if b.NumStmt == 0 {
+			continue
+		}
+		start, err := m.LineCol8Position(b.StartLine, b.StartCol)
+		if err != nil {
+			return result, err
+		}
+		end, err := m.LineCol8Position(b.EndLine, b.EndCol)
+		if err != nil {
+			return result, err
+		}
+		rng := protocol.Range{Start: start, End: end}
+		if b.Count > 0 {
+			result.Covered = append(result.Covered, rng)
+		} else {
+			result.Uncovered = append(result.Uncovered, rng)
+		}
+	}
+	return result, nil
+}
+
+// CoverageHTML returns a view, in HTML, of the source of the file fh
+// annotated with the coverage that the snapshot records for it, in the
+// manner of "go tool cover -html", for the BrowseCoverage command.
+func CoverageHTML(snapshot *cache.Snapshot, fh file.Handle, web Web) ([]byte, error) {
+	cov := snapshot.Coverage(fh.URI())
+	if cov == nil {
+		return nil, fmt.Errorf("no coverage of %s", fh.URI().Path())
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+
+	// -- model --
+
+	// A span is the extent of the statements of a block,
+	// as byte offsets.
+	type span struct {
+		start, end int
+		count      int
+	}
+	var (
+		spans              []span // sorted, as are the blocks
+		covered, statement int
+	)
+	m := protocol.NewMapper(fh.URI(), content)
+	offset := func(line, col int) (int, error) {
+		pos, err := m.LineCol8Position(line, col)
+		if err != nil {
+			return 0, err
+		}
+		return m.PositionOffset(pos)
+	}
+	for _, b := range cov.Blocks {
+		if b.NumStmt == 0 {
+			continue
+		}
+		start, err := offset(b.StartLine, b.StartCol)
+		if err != nil {
+			return nil, err
+		}
+		end, err := offset(b.EndLine, b.EndCol)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span{start, end, b.Count})
+		statement += b.NumStmt
+		if b.Count > 0 {
+			covered += b.NumStmt
+		}
+	}
+
+	// -- presentation --
+
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+pre { line-height: 1.3; }
+.num { color: #808080; text-decoration: none; }
+.cov { background-color: #c8f0d8; }
+.uncov { background-color: #f8c8c8; }
+</style>
+  <script src="/assets/common.js"></script>
+  <link rel="stylesheet" href="/assets/common.css">
+</head>
+<body>
+`)
+	filename := fh.URI().Path()
+	fmt.Fprintf(&buf, "<h1>Test coverage of %s</h1>\n", html.EscapeString(filename))
+	if statement > 0 {
+		fmt.Fprintf(&buf, "<p>\n  %d%% of the statements are covered (%d of %d).\n",
+			covered*100/statement, covered, statement)
+	} else {
+		buf.WriteString("<p>\n  The file has no statements.\n")
+	}
+	buf.WriteString("  Statements that the tests <span class='cov'>covered</span> and <span class='uncov'>did not cover</span> are highlighted.\n")
+	buf.WriteString("  Click a line number to open the line in the editor.\n</p>\n")
+
+	buf.WriteString("<pre>")
+	var (
+		start int // offset of the line
+		next  int // index of the first span that may intersect the line
+	)
+	for i, line := range strings.SplitAfter(string(content), "\n") {
+		if line == "" {
+			break // final newline
+		}
+		end := start + len(strings.TrimSuffix(line, "\n"))
+		buf.WriteString(sourceLink(fmt.Sprintf("<span class='num'>%5d</span>", i+1), web.SrcURL(filename, i+1, 1)))
+		buf.WriteString("  ")
+		for next < len(spans) && spans[next].end <= start {
+			next++
+		}
+		pos := start
+		for _, sp := range spans[next:] {
+			if sp.start >= end {
+				break
+			}
+			from, to := max(sp.start, pos), min(sp.end, end)
+			if from >= to {
+				continue // overlaps a previous span
+			}
+			buf.WriteString(html.EscapeString(string(content[pos:from])))
+			class := "cov"
+			if sp.count == 0 {
+				class = "uncov"
+			}
+			fmt.Fprintf(&buf, "<span class='%s' title='count: %d'>%s</span>", class, sp.count, html.EscapeString(string(content[from:to])))
+			pos = to
+		}
+		buf.WriteString(html.EscapeString(string(content[pos:end])))
+		buf.WriteString("\n")
+		start += len(line)
+	}
+	buf.WriteString("</pre>\n</body>\n</html>\n")
+	return buf.Bytes(), nil
+}
@@ -85,6 +85,12 @@ func Definition(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p
 		return locations, err // may be success or failure
 	}
 
+	// Handle the case where the cursor is in a string literal naming a
+	// file of the package directory, such as a testdata fixture.
+	if loc, ok := packageFileDefinition(snapshot, pgf, pos); ok {
+		return []protocol.Location{loc}, nil
+	}
+
 	// Handle definition requests for various special kinds of syntax node.
 	path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
 	switch node := path[0].(type) {
@@ -415,7 +421,7 @@ func importDefinition(ctx context.Context, s *cache.Snapshot, pkg *cache.Package
 			}
 			continue
 		}
-		pgf, err := s.ParseGo(ctx, fh, parsego.Header)
+		pgf, err := s.ParseHeader(ctx, fh)
 		if err != nil {
 			if ctx.Err() != nil {
 				return nil, ctx.Err()
@@ -37,6 +37,11 @@ func DiagnoseFile(ctx context.Context, snapshot *cache.Snapshot, uri protocol.Do
 		return nil, err
 	}
 	analysisDiags := moremaps.Group(pkgAnalysisDiags, byURI)[uri]
+	missingTestDiags, err := MissingTestDiagnostics(ctx, snapshot, map[PackageID]*metadata.Package{mp.ID: mp})
+	if err != nil {
+		return nil, err
+	}
+	analysisDiags = append(analysisDiags, missingTestDiags[uri]...)
 
 	// Return the merged set of file diagnostics, combining type error analyses
 	// with type error diagnostics.
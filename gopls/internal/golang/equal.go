@@ -0,0 +1,213 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Generate Equal method for T".
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/typesinternal"
+	"golang.org/x/tools/internal/versions"
+)
+
+// equalStructAt returns the declaration of the struct type enclosing
+// [start, end), as for [structDeclAt], provided it has no Equal
+// method and the file may use the slices and maps packages.
+func equalStructAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.GenDecl, *types.Named) {
+	decl, _, named := structDeclAt(pkg, pgf, start, end)
+	if named == nil ||
+		hasMethod(pkg.Types(), named, "Equal") ||
+		!versions.AtLeast(versions.FileVersion(pkg.TypesInfo(), pgf.File), "go1.21") {
+		return nil, nil
+	}
+	return decl, named
+}
+
+// addEqualMethod is a singleFileFixer that adds an Equal method to
+// the selected struct type T, which compares the fields of two values
+// of type T. Fields are compared using their Equal methods, if any;
+// slices and maps are compared element-wise; and other fields of
+// comparable types are compared using ==. Fields it cannot compare
+// are marked with TODO comments.
+func addEqualMethod(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	decl, named := equalStructAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, nil, fmt.Errorf("no struct type selected")
+	}
+	info := pkg.TypesInfo()
+	name := named.Obj().Name()
+
+	recv := receiverName(named)
+	if recv == "" || recv == "_" || recv == "other" {
+		recv = strings.ToLower(name[:1])
+		if recv == "o" {
+			recv = "x"
+		}
+	}
+
+	e := &equaler{
+		pkg:      pkg.Types(),
+		qual:     typesinternal.FileQualifier(pgf.File, pkg.Types()),
+		prefixes: make(map[string]string),
+	}
+
+	// Find the packages that the method needs, and import them in order.
+	e.compareFields(named, recv)
+	var edits []analysis.TextEdit
+	for _, pkgname := range slices.Sorted(maps.Keys(e.prefixes)) {
+		name, prefix, importEdits := analysisinternal.AddImport(info, pgf.File, pkgname, pkgname, "Equal", decl.Pos())
+		if name == recv || name == "other" {
+			return nil, nil, fmt.Errorf("name %s conflicts with an imported package", name)
+		}
+		edits = append(edits, importEdits...)
+		e.prefixes[pkgname] = prefix
+	}
+	e.conds, e.todos = nil, nil
+	e.compareFields(named, recv)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Equal reports whether %s and other are equal.\n", recv)
+	fmt.Fprintf(&buf, "func (%s %s) Equal(other %s) bool {\n", recv, name, name)
+	for _, todo := range e.todos {
+		fmt.Fprintf(&buf, "// TODO: compare %s.\n", todo)
+	}
+	if len(e.conds) == 0 {
+		buf.WriteString("return true\n}\n")
+	} else {
+		fmt.Fprintf(&buf, "return %s\n}\n", strings.Join(e.conds, " &&\n"))
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting generated method: %v", err)
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     decl.End(),
+		End:     decl.End(),
+		NewText: append([]byte("\n\n"), bytes.TrimSuffix(src, []byte("\n"))...),
+	})
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// An equaler generates the operands of the conjunction returned by an
+// Equal method.
+type equaler struct {
+	pkg      *types.Package
+	qual     types.Qualifier   // qualifier for types in the file
+	prefixes map[string]string // import prefixes of needed packages, by name
+	conds    []string          // operands of the conjunction
+	todos    []string          // fields that cannot be compared
+}
+
+// prefix returns the prefix that refers to the named package,
+// recording that the method needs it.
+func (e *equaler) prefix(pkgname string) string {
+	prefix, ok := e.prefixes[pkgname]
+	if !ok {
+		e.prefixes[pkgname] = "" // not yet imported
+	}
+	return prefix
+}
+
+// compareFields compares each field of the receiver recv of type
+// named with the corresponding field of other.
+func (e *equaler) compareFields(named *types.Named, recv string) {
+	for f := range named.Underlying().(*types.Struct).Fields() {
+		if f.Name() != "_" {
+			e.compare(recv+"."+f.Name(), "other."+f.Name(), f.Type())
+		}
+	}
+}
+
+// hasEqualMethod reports whether type t has a method Equal(t) bool,
+// either in its method set or, if addressable, in that of *t.
+// Pointers, which may be nil, are compared using == instead.
+func (e *equaler) hasEqualMethod(t types.Type, addressable bool) bool {
+	if is[*types.Pointer](t.Underlying()) {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, addressable, e.pkg, "Equal")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Signature()
+	return sig.Params().Len() == 1 && types.Identical(sig.Params().At(0).Type(), t) &&
+		sig.Results().Len() == 1 && types.Identical(sig.Results().At(0).Type(), types.Typ[types.Bool])
+}
+
+// compare adds an operand that compares x and y, of type t.
+func (e *equaler) compare(x, y string, t types.Type) {
+	if e.hasEqualMethod(t, true) {
+		e.conds = append(e.conds, fmt.Sprintf("%s.Equal(%s)", x, y))
+		return
+	}
+	if types.Comparable(t) {
+		e.conds = append(e.conds, fmt.Sprintf("%s == %s", x, y))
+		return
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		if types.Identical(u, types.NewSlice(types.Typ[types.Byte])) {
+			e.conds = append(e.conds, fmt.Sprintf("%sEqual(%s, %s)", e.prefix("bytes"), x, y))
+		} else if cond, ok := e.compareElems("slices", x, y, u.Elem()); ok {
+			e.conds = append(e.conds, cond)
+		} else {
+			e.todos = append(e.todos, fmt.Sprintf("%s (slice of %s)", x, types.TypeString(u.Elem(), e.qual)))
+		}
+
+	case *types.Map:
+		if cond, ok := e.compareElems("maps", x, y, u.Elem()); ok {
+			e.conds = append(e.conds, cond)
+		} else {
+			e.todos = append(e.todos, fmt.Sprintf("%s (map of %s)", x, types.TypeString(u.Elem(), e.qual)))
+		}
+
+	case *types.Struct:
+		for f := range u.Fields() {
+			if !f.Exported() && f.Pkg() != e.pkg {
+				e.todos = append(e.todos, fmt.Sprintf("%s (%s has unexported fields)", x, types.TypeString(t, e.qual)))
+				return
+			}
+		}
+		for f := range u.Fields() {
+			if f.Name() != "_" {
+				e.compare(x+"."+f.Name(), y+"."+f.Name(), f.Type())
+			}
+		}
+
+	case *types.Signature:
+		e.todos = append(e.todos, fmt.Sprintf("%s (func)", x))
+	default:
+		e.todos = append(e.todos, fmt.Sprintf("%s (%s)", x, types.TypeString(t, e.qual)))
+	}
+}
+
+// compareElems returns an operand that compares the slices or maps x
+// and y, whose elements have type elem, using the Equal or EqualFunc
+// function of the named package. It reports false if the elements
+// cannot be compared.
+func (e *equaler) compareElems(pkgname, x, y string, elem types.Type) (string, bool) {
+	switch {
+	case e.hasEqualMethod(elem, false):
+		return fmt.Sprintf("%sEqualFunc(%s, %s, %s.Equal)", e.prefix(pkgname), x, y, types.TypeString(elem, e.qual)), true
+	case types.Comparable(elem):
+		return fmt.Sprintf("%sEqual(%s, %s)", e.prefix(pkgname), x, y), true
+	}
+	return "", false
+}
@@ -25,6 +25,7 @@ import (
 	goplsastutil "golang.org/x/tools/gopls/internal/util/astutil"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/gopls/internal/util/typesutil"
 	"golang.org/x/tools/internal/analysisinternal"
 	"golang.org/x/tools/internal/astutil/cursor"
 	"golang.org/x/tools/internal/typesinternal"
@@ -58,6 +59,9 @@ func extractExprs(pkg *cache.Package, pgf *parsego.File, start, end token.Pos, a
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot extract: %v", err)
 	}
+	if all && isLiteral(exprs[0]) {
+		return extractLiteralAll(pkg, pgf, exprs)
+	}
 
 	// innermost scope enclosing ith expression
 	exprScopes := make([]*types.Scope, len(exprs))
@@ -420,6 +424,34 @@ func canExtractVariable(info *types.Info, curFile cursor.Cursor, start, end toke
 	var exprs []ast.Expr
 	if !all {
 		exprs = append(exprs, expr)
+	} else if isLiteral(expr) {
+		// Find all identical literals in the file;
+		// they will be replaced by a package-level constant.
+		skip := make(map[ast.Expr]bool)
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ImportSpec:
+				return false
+			case *ast.Field:
+				// Struct tags must remain literals.
+				if n.Tag != nil {
+					skip[n.Tag] = true
+				}
+			case *ast.ValueSpec:
+				// The value of a constant is already named.
+				if is[*types.Const](info.Defs[n.Names[0]]) {
+					for _, v := range n.Values {
+						skip[v] = true
+					}
+				}
+			case ast.Expr:
+				if !skip[n] && goplsastutil.Equal(n, expr, nil) {
+					exprs = append(exprs, n)
+					return false
+				}
+			}
+			return true
+		})
 	} else if funcDecl, ok := path[len(path)-2].(*ast.FuncDecl); ok {
 		// Find all expressions in the same function body that
 		// are equal to the selected expression.
@@ -507,6 +539,136 @@ func canExtractVariable(info *types.Info, curFile cursor.Cursor, start, end toke
 	return exprs, nil
 }
 
+// isLiteral reports whether e is a basic literal, possibly signed.
+func isLiteral(e ast.Expr) bool {
+	if u, ok := e.(*ast.UnaryExpr); ok && (u.Op == token.ADD || u.Op == token.SUB) {
+		e = u.X
+	}
+	return is[*ast.BasicLit](e)
+}
+
+// extractLiteralAll replaces all occurrences exprs of a literal within
+// the file by a reference to a new package-level constant. The
+// constant is declared alongside related constants (untyped constants
+// of the same kind) if the file has any, preferring the last such
+// declaration before the first occurrence; otherwise it is declared
+// before the declaration enclosing the first occurrence.
+func extractLiteralAll(pkg *cache.Package, pgf *parsego.File, exprs []ast.Expr) (*token.FileSet, *analysis.SuggestedFix, error) {
+	var (
+		info    = pkg.TypesInfo()
+		tokFile = pgf.Tok
+	)
+	name, _ := generateName(0, "newConst", func(name string) bool {
+		for _, e := range exprs {
+			if obj, _ := info.Scopes[pgf.File].Innermost(e.Pos()).LookupParent(name, e.Pos()); obj != nil {
+				return true
+			}
+		}
+		// The name must not conflict with an import in any file.
+		for _, file := range pkg.Syntax() {
+			if info.Scopes[file].Lookup(name) != nil {
+				return true
+			}
+		}
+		return false
+	})
+	start, end, err := safetoken.Offsets(tokFile, exprs[0].Pos(), exprs[0].End())
+	if err != nil {
+		return nil, nil, err
+	}
+	value := string(pgf.Src[start:end])
+
+	// Find the related constant declarations.
+	lit, _ := exprs[0].(*ast.BasicLit)
+	if lit == nil {
+		lit = exprs[0].(*ast.UnaryExpr).X.(*ast.BasicLit)
+	}
+	untyped := map[token.Token]types.BasicKind{
+		token.INT:    types.UntypedInt,
+		token.FLOAT:  types.UntypedFloat,
+		token.IMAG:   types.UntypedComplex,
+		token.CHAR:   types.UntypedRune,
+		token.STRING: types.UntypedString,
+	}[lit.Kind]
+	var (
+		related   *ast.GenDecl // declaration of related constants
+		enclosing ast.Decl     // declaration enclosing first occurrence
+		firstPos  = exprs[0].Pos()
+	)
+	for _, decl := range pgf.File.Decls {
+		if decl.Pos() <= firstPos && firstPos < decl.End() {
+			enclosing = decl
+		}
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST &&
+			(related == nil || gen.Pos() <= firstPos) &&
+			slices.ContainsFunc(gen.Specs, func(spec ast.Spec) bool {
+				return slices.ContainsFunc(spec.(*ast.ValueSpec).Names, func(id *ast.Ident) bool {
+					obj := info.Defs[id]
+					return obj != nil && obj.Type() == types.Typ[untyped]
+				})
+			}) {
+			related = gen
+		}
+	}
+	if enclosing == nil {
+		return nil, nil, bug.Errorf("no declaration encloses literal")
+	}
+
+	var edit analysis.TextEdit
+	switch {
+	case related != nil && related.Rparen.IsValid() && len(related.Specs) > 0 &&
+		safetoken.Position(tokFile, related.Rparen).Column == 1:
+		// Add a spec at the end of the group.
+		indent, err := calculateIndentation(pgf.Src, tokFile, related.Specs[len(related.Specs)-1])
+		if err != nil {
+			return nil, nil, err
+		}
+		edit = analysis.TextEdit{
+			Pos:     related.Rparen,
+			End:     related.Rparen,
+			NewText: fmt.Appendf(nil, "%s%s = %s\n", indent, name, value),
+		}
+
+	case related != nil && safetoken.Line(tokFile, related.End()) < tokFile.LineCount():
+		// Add a declaration on the line after it.
+		pos := tokFile.LineStart(safetoken.Line(tokFile, related.End()) + 1)
+		edit = analysis.TextEdit{
+			Pos:     pos,
+			End:     pos,
+			NewText: fmt.Appendf(nil, "const %s = %s\n", name, value),
+		}
+
+	default:
+		// Add a declaration before the enclosing one, and its doc comment.
+		pos := enclosing.Pos()
+		switch decl := enclosing.(type) {
+		case *ast.GenDecl:
+			if decl.Doc != nil {
+				pos = decl.Doc.Pos()
+			}
+		case *ast.FuncDecl:
+			if decl.Doc != nil {
+				pos = decl.Doc.Pos()
+			}
+		}
+		edit = analysis.TextEdit{
+			Pos:     pos,
+			End:     pos,
+			NewText: fmt.Appendf(nil, "const %s = %s\n\n", name, value),
+		}
+	}
+
+	edits := []analysis.TextEdit{edit}
+	for _, e := range exprs {
+		edits = append(edits, analysis.TextEdit{
+			Pos:     e.Pos(),
+			End:     e.End(),
+			NewText: []byte(name),
+		})
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
 // Calculate indentation for insertion.
 // When inserting lines of code, we must ensure that the lines have consistent
 // formatting (i.e. the proper indentation). To do so, we observe the indentation on the
@@ -583,12 +745,20 @@ type returnVariable struct {
 
 // extractMethod refactors the selected block of code into a new method.
 func extractMethod(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
-	return extractFunctionMethod(pkg, pgf, start, end, true)
+	return extractFunctionMethod(pkg, pgf, start, end, true, false)
 }
 
 // extractFunction refactors the selected block of code into a new function.
 func extractFunction(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
-	return extractFunctionMethod(pkg, pgf, start, end, false)
+	return extractFunctionMethod(pkg, pgf, start, end, false, false)
+}
+
+// extractTestHelper refactors the selected block of code of a test into
+// a new test helper: a function whose first parameter is the
+// *testing.T, *testing.B, or testing.TB of the enclosing test or
+// subtest, and that begins by calling its Helper method.
+func extractTestHelper(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	return extractFunctionMethod(pkg, pgf, start, end, false, true)
 }
 
 // extractFunctionMethod refactors the selected block of code into a new function/method.
@@ -598,8 +768,9 @@ func extractFunction(pkg *cache.Package, pgf *parsego.File, start, end token.Pos
 // by AST nodes. Next, we determine the variables that will be the parameters
 // and return values of the extracted function/method. Lastly, we construct the call
 // of the function/method and insert this call as well as the extracted function/method into
-// their proper locations.
-func extractFunctionMethod(cpkg *cache.Package, pgf *parsego.File, start, end token.Pos, isMethod bool) (*token.FileSet, *analysis.SuggestedFix, error) {
+// their proper locations. If isHelper is set, the function is a test
+// helper (see [extractTestHelper]).
+func extractFunctionMethod(cpkg *cache.Package, pgf *parsego.File, start, end token.Pos, isMethod, isHelper bool) (*token.FileSet, *analysis.SuggestedFix, error) {
 	var (
 		fset = cpkg.FileSet()
 		pkg  = cpkg.Types()
@@ -610,6 +781,8 @@ func extractFunctionMethod(cpkg *cache.Package, pgf *parsego.File, start, end to
 	errorPrefix := "extractFunction"
 	if isMethod {
 		errorPrefix = "extractMethod"
+	} else if isHelper {
+		errorPrefix = "extractTestHelper"
 	}
 
 	file := pgf.Cursor.Node().(*ast.File)
@@ -779,6 +952,30 @@ func extractFunctionMethod(cpkg *cache.Package, pgf *parsego.File, start, end to
 
 	reorderParams(params, paramTypes)
 
+	// A test helper takes the testing.TB of the test first, even if
+	// the selection does not refer to it.
+	var helperTB string
+	if isHelper {
+		i := slices.IndexFunc(paramTypes, func(f *ast.Field) bool { return isTestingTBExpr(f.Type) })
+		if i < 0 {
+			id := enclosingTestingParam(path)
+			if id == nil || info.Defs[id] == nil {
+				return nil, nil, fmt.Errorf("%s: no enclosing test", errorPrefix)
+			}
+			params = append([]ast.Expr{ast.NewIdent(id.Name)}, params...)
+			paramTypes = append([]*ast.Field{{
+				Names: []*ast.Ident{ast.NewIdent(id.Name)},
+				Type:  typesinternal.TypeExpr(info.Defs[id].Type(), qual),
+			}}, paramTypes...)
+		} else {
+			p, t := params[i], paramTypes[i]
+			copy(params[1:], params[:i])
+			copy(paramTypes[1:], paramTypes[:i])
+			params[0], paramTypes[0] = p, t
+		}
+		helperTB = paramTypes[0].Names[0].Name
+	}
+
 	// Find the function literal that encloses the selection. The enclosing function literal
 	// may not be the enclosing function declaration (i.e. 'outer'). For example, in the
 	// following block:
@@ -940,6 +1137,8 @@ func extractFunctionMethod(cpkg *cache.Package, pgf *parsego.File, start, end to
 	if isMethod {
 		// TODO(suzmue): generate a name that does not conflict for "newMethod".
 		funName = "newMethod"
+	} else if isHelper {
+		funName, _ = freshName(info, file, start, "newHelper", 0)
 	} else {
 		funName, _ = freshName(info, file, start, "newFunction", 0)
 	}
@@ -1007,9 +1206,16 @@ func extractFunctionMethod(cpkg *cache.Package, pgf *parsego.File, start, end to
 		Node:     extractedBlock,
 		Comments: extractedComments,
 	}
-	if err := format.Node(&newFuncBuf, fset, commentedNode); err != nil {
+	var blockBuf bytes.Buffer
+	if err := format.Node(&blockBuf, fset, commentedNode); err != nil {
 		return nil, nil, err
 	}
+	if isHelper {
+		// Call Helper first, after the opening '{'.
+		newFuncBuf.WriteString("{\n\t" + helperTB + ".Helper()")
+		blockBuf.Next(1)
+	}
+	newFuncBuf.Write(blockBuf.Bytes())
 
 	// We're going to replace the whole enclosing function,
 	// so preserve the text before and after the selected block.
@@ -1079,6 +1285,43 @@ func reorderParams(params []ast.Expr, paramTypes []*ast.Field) {
 	moveParamToFrontIfFound(params, paramTypes, "context", "Context")
 }
 
+// isTestingTBExpr reports whether the type expression e denotes
+// *testing.T, *testing.B, or testing.TB.
+func isTestingTBExpr(e ast.Expr) bool {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	return isSelector(e, "testing", "T") || isSelector(e, "testing", "B") || isSelector(e, "testing", "TB")
+}
+
+// enclosingTestingParam returns the name of the *testing.T, *testing.B, or
+// testing.TB parameter of the innermost function on the path that
+// has one, such as the function literal of a subtest, or nil if there
+// is none.
+func enclosingTestingParam(path []ast.Node) *ast.Ident {
+	for _, n := range path {
+		var ftype *ast.FuncType
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			ftype = n.Type
+		case *ast.FuncDecl:
+			ftype = n.Type
+		default:
+			continue
+		}
+		for _, field := range ftype.Params.List {
+			if isTestingTBExpr(field.Type) {
+				for _, name := range field.Names {
+					if name.Name != "_" {
+						return name
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func moveParamToFrontIfFound(params []ast.Expr, paramTypes []*ast.Field, x, sel string) {
 	// Move Context parameter (if any) to front.
 	for i, t := range paramTypes {
@@ -1664,46 +1907,14 @@ func generateReturnInfo(enclosing *ast.FuncType, pkg *types.Package, path []ast.
 	return retVars, ifReturn, nil
 }
 
-type objKey struct{ pkg, name string }
-
-// conventionalVarNames specifies conventional names for variables with various
-// standard library types.
-//
-// Keep this up to date with completion.conventionalAcronyms.
-//
-// TODO(rfindley): consider factoring out a "conventions" library.
-var conventionalVarNames = map[objKey]string{
-	{"", "error"}:              "err",
-	{"context", "Context"}:     "ctx",
-	{"sql", "Tx"}:              "tx",
-	{"http", "ResponseWriter"}: "rw", // Note: same as [AbbreviateVarName].
-}
-
 // varNameForType chooses a "good" name for a variable with the given type,
-// if possible. Otherwise, it returns "", false.
-//
-// For special types, it uses known conventional names.
+// if it has a name. Otherwise, it returns "", false.
 func varNameForType(t types.Type) (string, bool) {
-	var typeName string
-	if tn, ok := t.(interface{ Obj() *types.TypeName }); ok {
-		obj := tn.Obj()
-		k := objKey{name: obj.Name()}
-		if obj.Pkg() != nil {
-			k.pkg = obj.Pkg().Name()
-		}
-		if name, ok := conventionalVarNames[k]; ok {
-			return name, true
-		}
-		typeName = obj.Name()
-	} else if b, ok := t.(*types.Basic); ok {
-		typeName = b.Name()
+	switch t.(type) {
+	case interface{ Obj() *types.TypeName }, *types.Basic:
+		return typesutil.VarName(t, nil), true
 	}
-
-	if typeName == "" {
-		return "", false
-	}
-
-	return AbbreviateVarName(typeName), true
+	return "", false
 }
 
 // adjustReturnStatements adds "zero values" of the given types to each return statement
@@ -0,0 +1,220 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Generate fake for I".
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	goplsastutil "golang.org/x/tools/gopls/internal/util/astutil"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+// fakeInterfaceAt returns the non-generic interface type with methods
+// denoted by the identifier at [start, end) of the test file pgf, and
+// the name of its fake, provided the test package can implement the
+// interface and does not yet declare the fake.
+func fakeInterfaceAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*types.Named, string) {
+	if !strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+		return nil, ""
+	}
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, ""
+	}
+	var id *ast.Ident
+	switch n := curSel.Node().(type) {
+	case *ast.Ident:
+		id = n
+	case *ast.SelectorExpr:
+		id = n.Sel
+	default:
+		return nil, ""
+	}
+	obj, ok := pkg.TypesInfo().ObjectOf(id).(*types.TypeName)
+	if !ok {
+		return nil, ""
+	}
+	named, ok := types.Unalias(obj.Type()).(*types.Named)
+	if !ok || named.TypeParams() != nil || named.TypeArgs() != nil {
+		return nil, ""
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() == 0 || !iface.IsMethodSet() {
+		return nil, ""
+	}
+	if p := named.Obj().Pkg(); p != nil && p != pkg.Types() {
+		// The interface belongs to another package, perhaps the
+		// package under test of an external test package.
+		if !named.Obj().Exported() || typeRefsUnexported(iface, p, make(map[types.Type]bool)) {
+			return nil, ""
+		}
+		for m := range iface.Methods() {
+			if !m.Exported() {
+				return nil, ""
+			}
+		}
+	}
+	fake := "fake" + exportedName(named.Obj().Name())
+	if pkg.Types().Scope().Lookup(fake) != nil {
+		return nil, ""
+	}
+	return named, fake
+}
+
+// addFake is a singleFileFixer that appends to the test file a fake
+// implementation of the selected interface type I: a struct type
+// fakeI with a field MFunc of function type for each method M of I,
+// which the method M of *fakeI calls. Unlike a mock (see [addMock]),
+// a fake records nothing, and needs no file or package of its own.
+func addFake(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	named, fake := fakeInterfaceAt(pkg, pgf, start, end)
+	if named == nil {
+		return nil, nil, fmt.Errorf("no interface type selected")
+	}
+	iface := named.Underlying().(*types.Interface)
+
+	// The names of the methods and fields of the fake must not conflict.
+	for m := range iface.Methods() {
+		if obj, _, _ := types.LookupFieldOrMethod(iface, false, m.Pkg(), m.Name()+"Func"); obj != nil {
+			return nil, nil, fmt.Errorf("cannot generate fake: method %s conflicts with the field for %s", obj.Name(), m.Name())
+		}
+	}
+
+	// Qualify the references to other packages, importing them as needed.
+	var (
+		prefixes = make(map[string]string) // by package path
+		edits    []analysis.TextEdit
+	)
+	qual := func(p *types.Package) string {
+		if p == pkg.Types() {
+			return ""
+		}
+		prefix, ok := prefixes[p.Path()]
+		if !ok {
+			var importEdits []analysis.TextEdit
+			_, prefix, importEdits = analysisinternal.AddImport(pkg.TypesInfo(), pgf.File, p.Name(), p.Path(), "", pgf.File.Name.End())
+			edits = append(edits, importEdits...)
+			prefixes[p.Path()] = prefix
+		}
+		return strings.TrimSuffix(prefix, ".")
+	}
+
+	const recv = "fake"
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\n// %s is a fake implementation of [%s].\n", fake, types.TypeString(named, qual))
+	fmt.Fprintf(&buf, "// Each method calls the function in the corresponding field.\n")
+	fmt.Fprintf(&buf, "type %s struct {\n", fake)
+	for m := range iface.Methods() {
+		params, _, _, results := mockSignature(m.Signature(), qual, recv)
+		fmt.Fprintf(&buf, "%sFunc func(%s) %s\n", m.Name(), strings.Join(params, ", "), results)
+	}
+	fmt.Fprintf(&buf, "}\n\n")
+	fmt.Fprintf(&buf, "var _ %s = (*%s)(nil)\n", types.TypeString(named, qual), fake)
+	for m := range iface.Methods() {
+		params, args, _, results := mockSignature(m.Signature(), qual, recv)
+		fmt.Fprintf(&buf, "\nfunc (%s *%s) %s(%s) %s {\n", recv, fake, m.Name(), strings.Join(params, ", "), results)
+		if results != "" {
+			buf.WriteString("return ")
+		}
+		fmt.Fprintf(&buf, "%s.%sFunc(%s)\n}\n", recv, m.Name(), strings.Join(args, ", "))
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting generated fake: %v", err)
+	}
+
+	edits = append(edits, analysis.TextEdit{
+		Pos:     pgf.File.FileEnd,
+		End:     pgf.File.FileEnd,
+		NewText: src,
+	})
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// TestDoubles records the fakes declared in a test file, such as
+// those written by the "Generate fake for I" code action (see
+// [addFake]): struct types named fakeI, which implement the interface
+// type I by means of methods with pointer receivers. TestDoubles maps
+// the name of each fake to the names of its methods.
+//
+// Generated tests use a fake of I as the type of the test case field
+// for each parameter of type I, so that each test case may set its
+// functions.
+type TestDoubles map[string][]string
+
+// TestDoublesOf returns the fakes declared in the specified test file.
+func TestDoublesOf(file *ast.File) TestDoubles {
+	doubles := make(TestDoubles)
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				if is[*ast.StructType](spec.Type) && spec.TypeParams == nil && strings.HasPrefix(spec.Name.Name, "fake") {
+					doubles[spec.Name.Name] = nil
+				}
+			}
+		}
+	}
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv != nil {
+			if _, recv, _ := goplsastutil.UnpackRecv(decl.Recv.List[0].Type); recv != nil {
+				if methods, ok := doubles[recv.Name]; ok {
+					doubles[recv.Name] = append(methods, decl.Name.Name)
+				}
+			}
+		}
+	}
+	return doubles
+}
+
+// fakeFor returns the name of the fake of the interface type t, or ""
+// if there is none.
+func (doubles TestDoubles) fakeFor(t types.Type) string {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return ""
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() == 0 {
+		return ""
+	}
+	fake := "fake" + exportedName(named.Obj().Name())
+	methods, ok := doubles[fake]
+	if !ok {
+		return ""
+	}
+	for m := range iface.Methods() {
+		if !slices.Contains(methods, m.Name()) {
+			return ""
+		}
+	}
+	return fake
+}
+
+// usedIn returns the subset of the fakes to which node n refers.
+func (doubles TestDoubles) usedIn(n ast.Node) TestDoubles {
+	used := make(TestDoubles)
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if methods, ok := doubles[id.Name]; ok {
+				used[id.Name] = methods
+			}
+		}
+		return true
+	})
+	return used
+}
@@ -0,0 +1,242 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Add cases for all implementations of T".
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/methodsets"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// typeSwitchAt returns the innermost type switch statement whose
+// header (the part before the opening brace) encloses [start, end),
+// and the type of its operand, provided that it is an interface type
+// with at least one method.
+func typeSwitchAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.TypeSwitchStmt, types.Type) {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, nil
+	}
+	var stmt *ast.TypeSwitchStmt
+	if s, ok := curSel.Node().(*ast.TypeSwitchStmt); ok {
+		stmt = s
+	} else {
+		for cur := range curSel.Ancestors((*ast.TypeSwitchStmt)(nil)) {
+			stmt = cur.Node().(*ast.TypeSwitchStmt)
+			break
+		}
+	}
+	if stmt == nil || end > stmt.Body.Lbrace {
+		return nil, nil
+	}
+	var assert *ast.TypeAssertExpr
+	switch assign := stmt.Assign.(type) {
+	case *ast.ExprStmt:
+		assert, _ = assign.X.(*ast.TypeAssertExpr)
+	case *ast.AssignStmt:
+		assert, _ = assign.Rhs[0].(*ast.TypeAssertExpr)
+	}
+	if assert == nil {
+		return nil, nil
+	}
+	t := pkg.TypesInfo().TypeOf(assert.X)
+	if t == nil || !types.IsInterface(t) {
+		return nil, nil
+	}
+	if _, hasMethods := methodsets.KeyOf(t); !hasMethods {
+		return nil, nil // e.g. any
+	}
+	return stmt, t
+}
+
+// fillTypeSwitch is a [fixer] that adds to the selected type switch a
+// case for each type in the workspace that implements the interface
+// type of its operand, other than those it already handles.
+//
+// It finds the implementations using the method-set index, and
+// considers only the packages that the current package may import.
+// A case names T if the type T implements the interface, or *T if only
+// the pointer does. The cases are inserted before the default case,
+// if any, with the types of the current package first, followed by
+// those of other packages in order of package path.
+func fillTypeSwitch(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	stmt, iface := typeSwitchAt(pkg, pgf, start, end)
+	if stmt == nil {
+		return nil, nil, fmt.Errorf("no type switch over an interface selected")
+	}
+	info := pkg.TypesInfo()
+	key, _ := methodsets.KeyOf(iface)
+
+	// Find the workspace packages that the current package may import,
+	// which excludes those that depend on it.
+	self := pkg.Metadata()
+	rdeps, err := snapshot.ReverseDependencies(ctx, self.ID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	metas, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata.RemoveIntermediateTestVariants(&metas)
+	goList := snapshot.View().Type() != cache.GoPackagesDriverView
+	var ids []PackageID
+	for _, mp := range metas {
+		if mp.ID == self.ID ||
+			mp.PkgPath != self.PkgPath &&
+				mp.ForTest == "" &&
+				mp.Name != "main" &&
+				rdeps[mp.ID] == nil &&
+				metadata.IsValidImport(self.PkgPath, mp.PkgPath, goList) {
+			ids = append(ids, mp.ID)
+		}
+	}
+
+	// Search the index of each package, and type-check only the
+	// packages that declare some implementation.
+	indexes, err := snapshot.MethodSets(ctx, ids...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("querying method sets: %v", err)
+	}
+	var (
+		declIDs []PackageID
+		results [][]methodsets.Result
+	)
+	for i, index := range indexes {
+		if res := index.Search(key, nil); len(res) > 0 {
+			declIDs = append(declIDs, ids[i])
+			results = append(results, res)
+		}
+	}
+	declPkgs, err := snapshot.TypeCheck(ctx, declIDs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The types of the existing cases, as "path.Name".
+	handled := make(map[string]bool)
+	for _, clause := range stmt.Body.List {
+		for _, e := range clause.(*ast.CaseClause).List {
+			if tv, ok := info.Types[e]; ok && tv.IsType() {
+				t := types.Unalias(tv.Type)
+				if ptr, ok := t.(*types.Pointer); ok {
+					t = types.Unalias(ptr.Elem())
+				}
+				if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil {
+					handled[named.Obj().Pkg().Path()+"."+named.Obj().Name()] = true
+				}
+			}
+		}
+	}
+
+	// The interface method IDs, which are comparable across
+	// type-checking realms, unlike the types of the methods.
+	var methodIDs []string
+	for m := range iface.Underlying().(*types.Interface).Methods() {
+		methodIDs = append(methodIDs, m.Id())
+	}
+
+	type impl struct {
+		obj  *types.TypeName
+		ptr  bool // only *T implements the interface
+		posn token.Position
+	}
+	var impls []impl
+	for i, declPkg := range declPkgs {
+		// Map the locations of the results to the package-level type names.
+		locs := make(map[methodsets.Location]bool)
+		for _, res := range results[i] {
+			locs[methodsets.Location{Filename: res.Location.Filename, Start: res.Location.Start}] = true
+		}
+		scope := declPkg.Types().Scope()
+		for _, name := range scope.Names() {
+			tname, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tname.IsAlias() {
+				continue
+			}
+			posn := safetoken.StartPosition(declPkg.FileSet(), tname.Pos())
+			if !locs[methodsets.Location{Filename: posn.Filename, Start: posn.Offset}] {
+				continue
+			}
+			named, ok := tname.Type().(*types.Named)
+			if !ok ||
+				named.TypeParams().Len() > 0 ||
+				types.IsInterface(named) ||
+				declPkg.Metadata() != self && !tname.Exported() ||
+				handled[tname.Pkg().Path()+"."+tname.Name()] {
+				continue
+			}
+			// The pointer method set is a superset of the value
+			// method set with identical signatures, so T implements
+			// the interface if its method set has every method ID.
+			valueIDs := make(map[string]bool)
+			for sel := range types.NewMethodSet(named).Methods() {
+				valueIDs[sel.Obj().Id()] = true
+			}
+			ptr := slices.ContainsFunc(methodIDs, func(id string) bool { return !valueIDs[id] })
+			impls = append(impls, impl{tname, ptr, posn})
+		}
+	}
+	if len(impls) == 0 {
+		return nil, nil, fmt.Errorf("no other implementations of %s in the workspace", types.TypeString(iface, typesinternal.NameRelativeTo(pkg.Types())))
+	}
+	selfPath := string(self.PkgPath)
+	slices.SortFunc(impls, func(x, y impl) int {
+		xpath, ypath := x.obj.Pkg().Path(), y.obj.Pkg().Path()
+		return cmp.Or(
+			cmp.Compare(cond(xpath == selfPath, 0, 1), cond(ypath == selfPath, 0, 1)),
+			strings.Compare(xpath, ypath),
+			strings.Compare(x.posn.Filename, y.posn.Filename),
+			cmp.Compare(x.posn.Offset, y.posn.Offset))
+	})
+
+	// Insert the cases before the default case, or the closing brace,
+	// assuming that it is on a line of its own, indented by tabs.
+	pos := stmt.Body.Rbrace
+	for _, clause := range stmt.Body.List {
+		if clause := clause.(*ast.CaseClause); clause.List == nil {
+			pos = clause.Pos()
+		}
+	}
+	var (
+		indent   = strings.Repeat("\t", safetoken.StartPosition(pkg.FileSet(), pos).Column-1)
+		prefixes = make(map[string]string) // by package path
+		edits    []analysis.TextEdit
+		buf      strings.Builder
+	)
+	for _, impl := range impls {
+		path := impl.obj.Pkg().Path()
+		prefix, ok := prefixes[path]
+		if !ok && path != selfPath {
+			var importEdits []analysis.TextEdit
+			_, prefix, importEdits = analysisinternal.AddImport(info, pgf.File, impl.obj.Pkg().Name(), path, impl.obj.Name(), stmt.Pos())
+			edits = append(edits, importEdits...)
+			prefixes[path] = prefix
+		}
+		fmt.Fprintf(&buf, "case %s%s%s:\n%s", cond(impl.ptr, "*", ""), prefix, impl.obj.Name(), indent)
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     pos,
+		End:     pos,
+		NewText: []byte(buf.String()),
+	})
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
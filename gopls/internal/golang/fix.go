@@ -10,9 +10,12 @@ import (
 	"go/token"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/analysis/contextfield"
 	"golang.org/x/tools/gopls/internal/analysis/embeddirective"
 	"golang.org/x/tools/gopls/internal/analysis/fillstruct"
+	"golang.org/x/tools/gopls/internal/analysis/missingtest"
 	"golang.org/x/tools/gopls/internal/analysis/unusedparams"
+	"golang.org/x/tools/gopls/internal/analysis/wallclock"
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/file"
@@ -56,13 +59,40 @@ const (
 	fixExtractVariableAll      = "extract_variable_all"
 	fixExtractFunction         = "extract_function"
 	fixExtractMethod           = "extract_method"
+	fixExtractTestHelper       = "extract_test_helper"
 	fixInlineCall              = "inline_call"
+	fixInlineVariable          = "inline_variable"
 	fixInvertIfCondition       = "invert_if_condition"
+	fixIfToSwitch              = "if_to_switch"
+	fixAddIterator             = "add_iterator"
+	fixRangeOverFunc           = "range_over_func"
+	fixAddJSONMethods          = "add_json_methods"
+	fixAddCloneMethod          = "add_clone_method"
+	fixAddEqualMethod          = "add_equal_method"
+	fixAddFuncOptions          = "add_func_options"
+	fixAddFlagsMethod          = "add_flags_method"
+	fixAddMock                 = "add_mock"
+	fixAddFake                 = "add_fake"
+	fixFillTypeSwitch          = "fill_type_switch"
+	fixWrapError               = "wrap_error"
+	fixWrapErrorAll            = "wrap_error_all"
 	fixSplitLines              = "split_lines"
 	fixJoinLines               = "join_lines"
 	fixCreateUndeclared        = "create_undeclared"
+	fixAddTestCase             = "add_test_case"
+	fixAssertionsToStd         = "assertions_to_std"
+	fixAssertionsToTestify     = "assertions_to_testify"
+	fixTableTest               = "table_test"
+	fixMergeTests              = "merge_tests"
 	fixMissingInterfaceMethods = "stub_missing_interface_method"
 	fixMissingCalledFunction   = "stub_missing_called_function"
+	fixAddFieldNames           = "add_field_names"
+	fixAddFieldNamesAll        = "add_field_names_all"
+	fixRemoveFieldNames        = "remove_field_names"
+	fixRemoveFieldNamesAll     = "remove_field_names_all"
+	fixAddStructTagsJSON       = "add_struct_tags_json"
+	fixAddStructTagsYAML       = "add_struct_tags_yaml"
+	fixAddStructTagsDB         = "add_struct_tags_db"
 )
 
 // ApplyFix applies the specified kind of suggested fix to the given
@@ -89,26 +119,66 @@ func ApplyFix(ctx context.Context, fix string, snapshot *cache.Snapshot, fh file
 	if fix == unusedparams.FixCategory {
 		return removeParam(ctx, snapshot, fh, rng)
 	}
+	if fix == missingtest.FixCategory {
+		return AddTestForFunc(ctx, snapshot, protocol.Location{URI: fh.URI(), Range: rng}, false, TestOptions{})
+	}
+	if fix == contextfield.FixCategory {
+		return contextFieldToParam(ctx, snapshot, fh, rng)
+	}
+	if fix == wallclock.FixCategory {
+		return injectClock(ctx, snapshot, fh, rng)
+	}
+	if fix == fixAddMock {
+		return addMock(ctx, snapshot, fh, rng)
+	}
+	if fix == fixAssertionsToTestify {
+		return assertionsToTestify(ctx, snapshot, fh, rng)
+	}
 
 	fixers := map[string]fixer{
 		// Fixes for analyzer-provided diagnostics.
 		// These match the Diagnostic.Category.
 		embeddirective.FixCategory: addEmbedImport,
-		fillstruct.FixCategory:     singleFile(fillstruct.SuggestedFix),
+		fillstruct.FixCategory:     fillstruct.SuggestedFix,
 
 		// Ad-hoc fixers: these are used when the command is
 		// constructed directly by logic in server/code_action.
 		fixExtractFunction:         singleFile(extractFunction),
 		fixExtractMethod:           singleFile(extractMethod),
+		fixExtractTestHelper:       singleFile(extractTestHelper),
 		fixExtractVariable:         singleFile(extractVariable),
 		fixExtractVariableAll:      singleFile(extractVariableAll),
 		fixInlineCall:              inlineCall,
+		fixInlineVariable:          singleFile(inlineVariable),
 		fixInvertIfCondition:       singleFile(invertIfCondition),
+		fixIfToSwitch:              singleFile(convertIfToSwitch),
+		fixAddIterator:             singleFile(addIteratorFunc),
+		fixRangeOverFunc:           singleFile(convertToRangeOverFunc),
+		fixAddJSONMethods:          singleFile(addJSONMethods),
+		fixAddCloneMethod:          singleFile(addCloneMethod),
+		fixAddEqualMethod:          singleFile(addEqualMethod),
+		fixAddFuncOptions:          singleFile(addFuncOptions),
+		fixAddFlagsMethod:          singleFile(addFlagsMethod),
+		fixAddFake:                 singleFile(addFake),
+		fixFillTypeSwitch:          fillTypeSwitch,
+		fixWrapError:               singleFile(wrapError),
+		fixWrapErrorAll:            singleFile(wrapErrorAll),
 		fixSplitLines:              singleFile(splitLines),
 		fixJoinLines:               singleFile(joinLines),
 		fixCreateUndeclared:        singleFile(createUndeclared),
+		fixAddTestCase:             singleFile(addTestCase),
+		fixAssertionsToStd:         singleFile(convertToStdAssertions),
+		fixTableTest:               singleFile(convertToTableTest),
+		fixMergeTests:              singleFile(mergeTests),
 		fixMissingInterfaceMethods: stubMissingInterfaceMethodsFixer,
 		fixMissingCalledFunction:   stubMissingCalledFunctionFixer,
+		fixAddFieldNames:           singleFile(addFieldNames),
+		fixAddFieldNamesAll:        singleFile(addFieldNamesAll),
+		fixRemoveFieldNames:        singleFile(removeFieldNames),
+		fixRemoveFieldNamesAll:     singleFile(removeFieldNamesAll),
+		fixAddStructTagsJSON:       addStructTags("json"),
+		fixAddStructTagsYAML:       addStructTags("yaml"),
+		fixAddStructTagsDB:         addStructTags("db"),
 	}
 	fixer, ok := fixers[fix]
 	if !ok {
@@ -0,0 +1,198 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Generate RegisterFlags method for T".
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// flagsStructAt returns the declaration of the struct type enclosing
+// [start, end), as for [structDeclAt], provided it has no
+// RegisterFlags method.
+func flagsStructAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.GenDecl, *ast.TypeSpec, *types.Named) {
+	decl, spec, named := structDeclAt(pkg, pgf, start, end)
+	if named == nil || hasMethod(pkg.Types(), named, "RegisterFlags") {
+		return nil, nil, nil
+	}
+	return decl, spec, named
+}
+
+// flagFuncs maps each basic type to the method of flag.FlagSet that
+// defines a flag of that type.
+var flagFuncs = map[types.BasicKind]string{
+	types.Bool:    "BoolVar",
+	types.Int:     "IntVar",
+	types.Int64:   "Int64Var",
+	types.Uint:    "UintVar",
+	types.Uint64:  "Uint64Var",
+	types.Float64: "Float64Var",
+	types.String:  "StringVar",
+}
+
+// flagValue is the method set of flag.Value.
+var flagValue = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "Set", types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewParam(token.NoPos, nil, "", types.Typ[types.String])),
+		types.NewTuple(types.NewParam(token.NoPos, nil, "", types.Universe.Lookup("error").Type())), false)),
+	types.NewFunc(token.NoPos, nil, "String", types.NewSignatureType(nil, nil, nil, nil,
+		types.NewTuple(types.NewParam(token.NoPos, nil, "", types.Typ[types.String])), false)),
+}, nil).Complete()
+
+// addFlagsMethod is a singleFileFixer that adds a method
+// RegisterFlags(fs *flag.FlagSet) to the selected struct type T,
+// which defines a flag for each field of T that is stored in that
+// field. The name of each flag is given by the field's "flag" or
+// "json" tag, or else derived from the field name, and its usage by
+// the field's comment. Fields of types that have a RegisterFlags
+// method register their own flags; fields of types that flag.FlagSet
+// cannot define are marked with TODO comments.
+func addFlagsMethod(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	decl, spec, named := flagsStructAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, nil, fmt.Errorf("no struct type selected")
+	}
+	info := pkg.TypesInfo()
+	name := named.Obj().Name()
+	qual := types.RelativeTo(pkg.Types())
+
+	recv := receiverName(named)
+	if recv == "" || recv == "_" || recv == "fs" {
+		recv = strings.ToLower(name[:1])
+		if recv == "f" {
+			recv = "x"
+		}
+	}
+	flagName, flagPrefix, edits := analysisinternal.AddImport(info, pgf.File, "flag", "flag", "FlagSet", decl.Pos())
+	if flagName == recv || flagName == "fs" {
+		return nil, nil, fmt.Errorf("name %s conflicts with an imported package", flagName)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// RegisterFlags defines a flag for each field of %s in fs.\n", recv)
+	fmt.Fprintf(&buf, "func (%s *%s) RegisterFlags(fs *%sFlagSet) {\n", recv, name, flagPrefix)
+	for _, field := range spec.Type.(*ast.StructType).Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			if s, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(s)
+			}
+		}
+		if tag.Get("flag") == "-" {
+			continue
+		}
+		usage := strings.TrimSpace(field.Doc.Text())
+		if usage == "" {
+			usage = strings.TrimSpace(field.Comment.Text())
+		}
+		usage = strconv.Quote(strings.Join(strings.Fields(usage), " "))
+
+		names := field.Names
+		if len(names) == 0 {
+			if id := embeddedIdent(field.Type); id != nil {
+				names = []*ast.Ident{id}
+			}
+		}
+		t := info.TypeOf(field.Type)
+		for _, id := range names {
+			if id.Name == "_" {
+				continue
+			}
+			x := recv + "." + id.Name
+			flag := tag.Get("flag")
+			if flag == "" && len(names) == 1 {
+				flag, _, _ = strings.Cut(tag.Get("json"), ",")
+			}
+			if flag == "" || flag == "-" {
+				flag = flagCase(id.Name)
+			}
+			flag = strconv.Quote(flag)
+
+			if isFlagsMethod(pkg.Types(), t) {
+				fmt.Fprintf(&buf, "%s.RegisterFlags(fs)\n", x)
+				continue
+			}
+			if types.Implements(types.NewPointer(t), flagValue) {
+				fmt.Fprintf(&buf, "fs.Var(&%s, %s, %s)\n", x, flag, usage)
+				continue
+			}
+			if analysisinternal.IsTypeNamed(t, "time", "Duration") {
+				fmt.Fprintf(&buf, "fs.DurationVar(&%s, %s, 0, %s)\n", x, flag, usage)
+				continue
+			}
+			basic, ok := t.Underlying().(*types.Basic)
+			if !ok || flagFuncs[basic.Kind()] == "" {
+				fmt.Fprintf(&buf, "// TODO: no flag for %s of type %s.\n", x, types.TypeString(t, qual))
+				continue
+			}
+			ptr := "&" + x
+			if types.Unalias(t) != types.Typ[basic.Kind()] {
+				ptr = fmt.Sprintf("(*%s)(%s)", basic.Name(), ptr) // named type
+			}
+			zero, _ := typesinternal.ZeroString(t, qual)
+			fmt.Fprintf(&buf, "fs.%s(%s, %s, %s, %s)\n", flagFuncs[basic.Kind()], ptr, flag, zero, usage)
+		}
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting generated method: %v", err)
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     decl.End(),
+		End:     decl.End(),
+		NewText: append([]byte("\n\n"), bytes.TrimSuffix(src, []byte("\n"))...),
+	})
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// isFlagsMethod reports whether *t has a method
+// RegisterFlags(*flag.FlagSet), as generated by addFlagsMethod.
+func isFlagsMethod(pkg *types.Package, t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), false, pkg, "RegisterFlags")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	params := fn.Signature().Params()
+	if params.Len() != 1 {
+		return false
+	}
+	ptr, ok := params.At(0).Type().(*types.Pointer)
+	return ok && analysisinternal.IsTypeNamed(ptr.Elem(), "flag", "FlagSet")
+}
+
+// flagCase returns the name of the flag for a field: its name in
+// lower case, with hyphens between words, as in "read-timeout" for
+// ReadTimeout or "http-addr" for HTTPAddr.
+func flagCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			b.WriteByte('-')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
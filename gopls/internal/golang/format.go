@@ -12,17 +12,16 @@ import (
 	"fmt"
 	"go/ast"
 	"go/format"
-	"go/parser"
-	"go/token"
+	"slices"
+	"sort"
 	"strings"
-	"text/scanner"
 
 	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/gopls/internal/settings"
-	"golang.org/x/tools/gopls/internal/util/safetoken"
 	"golang.org/x/tools/internal/diff"
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/imports"
@@ -81,12 +80,7 @@ func Format(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]pr
 		var opts gofumptFormat.Options
 		meta, err := NarrowestMetadataForFile(ctx, snapshot, fh.URI())
 		if err == nil {
-			if mi := meta.Module; mi != nil {
-				if v := mi.GoVersion; v != "" {
-					opts.LangVersion = "go" + v
-				}
-				opts.ModulePath = mi.Path
-			}
+			opts = gofumptOptions(meta)
 		}
 		b, err := gofumptFormat.Source(buf.Bytes(), opts)
 		if err != nil {
@@ -97,6 +91,110 @@ func Format(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]pr
 	return computeTextEdits(ctx, pgf, formatted)
 }
 
+// gofumptOptions returns the options of gofumpt for the files of the
+// package mp: the language version and path of its module, if any.
+func gofumptOptions(mp *metadata.Package) gofumptFormat.Options {
+	var opts gofumptFormat.Options
+	if mi := mp.Module; mi != nil {
+		if v := mi.GoVersion; v != "" {
+			opts.LangVersion = "go" + v
+		}
+		opts.ModulePath = mi.Path
+	}
+	return opts
+}
+
+// formatGenerated formats src, generated code of a file of the package
+// mp, as [Format] would: with gofmt, and then with gofumpt, if the
+// options enable it. If fragment is set, src is a
+// sequence of declarations rather than a file, whose leading and
+// trailing newlines are preserved, so that it may be inserted between
+// those of a file.
+func formatGenerated(options *settings.Options, mp *metadata.Package, src []byte, fragment bool) ([]byte, error) {
+	const header = "package p\n"
+	var lead, trail []byte
+	if fragment {
+		body := bytes.TrimLeft(src, "\n")
+		lead = src[:len(src)-len(body)]
+		trimmed := bytes.TrimRight(body, "\n")
+		trail = body[len(trimmed):]
+		src = append([]byte(header), trimmed...)
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, err
+	}
+	if options.Gofumpt {
+		if formatted, err = gofumptFormat.Source(formatted, gofumptOptions(mp)); err != nil {
+			return nil, err
+		}
+	}
+	if fragment {
+		body := bytes.Trim(bytes.TrimPrefix(formatted, []byte(header)), "\n")
+		formatted = slices.Concat(lead, body, trail)
+	}
+	return formatted, nil
+}
+
+// lineEnding returns the line terminator used by src: "\r\n" if its
+// first line ends with one, and "\n" otherwise.
+func lineEnding(src []byte) string {
+	if i := bytes.IndexByte(src, '\n'); i > 0 && src[i-1] == '\r' {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// withLineEnding returns text with each of its line terminators
+// replaced by eol.
+func withLineEnding(text, eol string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if eol != "\n" {
+		text = strings.ReplaceAll(text, "\n", eol)
+	}
+	return text
+}
+
+// editsWithLineEnding returns edits of src equivalent to the given
+// ones, but whose new text uses the line terminator eol. Edits that
+// were computed against text with different line terminators may
+// insert or delete lone carriage returns, so unless eol is "\n", the
+// edits are replaced by a single edit of the whole lines they span.
+func editsWithLineEnding(src []byte, edits []protocol.TextEdit, eol string) ([]protocol.TextEdit, error) {
+	if eol == "\n" || len(edits) == 0 {
+		return edits, nil
+	}
+	m := protocol.NewMapper("", src)
+	diffEdits, err := protocol.EditsToDiffEdits(m, edits)
+	if err != nil {
+		return nil, err
+	}
+	diff.SortEdits(diffEdits)
+	start, end := diffEdits[0].Start, 0
+	for _, edit := range diffEdits {
+		end = max(end, edit.End)
+	}
+	start = bytes.LastIndexByte(src[:start], '\n') + 1
+	if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+		end += i + 1
+	} else {
+		end = len(src)
+	}
+	for i := range diffEdits {
+		diffEdits[i].Start -= start
+		diffEdits[i].End -= start
+	}
+	text, err := diff.Apply(string(src[start:end]), diffEdits)
+	if err != nil {
+		return nil, err
+	}
+	rng, err := m.OffsetRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.TextEdit{{Range: rng, NewText: withLineEnding(text, eol)}}, nil
+}
+
 func formatSource(ctx context.Context, fh file.Handle) ([]byte, error) {
 	_, done := event.Start(ctx, "golang.formatSource")
 	defer done()
@@ -196,137 +294,80 @@ func ComputeImportFixEdits(localPrefix string, src []byte, fixes ...*imports.Imp
 	return computeFixEdits(src, options, fixes)
 }
 
-func computeFixEdits(src []byte, options *imports.Options, fixes []*imports.ImportFix) ([]protocol.TextEdit, error) {
-	// trim the original data to match fixedData
-	left, err := importPrefix(src)
-	if err != nil {
-		return nil, err
-	}
-	extra := !strings.Contains(left, "\n") // one line may have more than imports
-	if extra {
-		left = string(src)
-	}
-	if len(left) > 0 && left[len(left)-1] != '\n' {
-		left += "\n"
-	}
-	// Apply the fixes and re-parse the file so that we can locate the
-	// new imports.
-	flags := parser.ImportsOnly
-	if extra {
-		// used all of origData above, use all of it here too
-		flags = 0
-	}
-	fixedData, err := imports.ApplyFixes(fixes, "", src, options, flags)
-	if err != nil {
-		return nil, err
-	}
-	if fixedData == nil || fixedData[len(fixedData)-1] != '\n' {
-		fixedData = append(fixedData, '\n') // ApplyFixes may miss the newline, go figure.
+// An ImportFixBatch accumulates the import fixes of a series of edits
+// of the same file, such as the addition of several generated tests,
+// so that the edit of its imports is computed once, over the original
+// source, when the series is done, rather than after each edit.
+type ImportFixBatch struct {
+	localPrefix string
+	src         []byte
+	fixes       map[imports.ImportInfo]imports.ImportFixType
+}
+
+// NewImportFixBatch returns an empty batch of the import fixes of the
+// file whose original source is src. The localPrefix is as for
+// [ComputeImportFixEdits].
+func NewImportFixBatch(localPrefix string, src []byte) *ImportFixBatch {
+	return &ImportFixBatch{
+		localPrefix: localPrefix,
+		src:         src,
+		fixes:       make(map[imports.ImportInfo]imports.ImportFixType),
 	}
-	edits := diff.Strings(left, string(fixedData))
-	return protocolEditsFromSource([]byte(left), edits)
 }
 
-// importPrefix returns the prefix of the given file content through the final
-// import statement. If there are no imports, the prefix is the package
-// statement and any comment groups below it.
-func importPrefix(src []byte) (string, error) {
-	fset := token.NewFileSet()
-	// do as little parsing as possible
-	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
-	if err != nil { // This can happen if 'package' is misspelled
-		return "", fmt.Errorf("importPrefix: failed to parse: %s", err)
-	}
-	tok := fset.File(f.FileStart)
-	var importEnd int
-	for _, d := range f.Decls {
-		if x, ok := d.(*ast.GenDecl); ok && x.Tok == token.IMPORT {
-			if e, err := safetoken.Offset(tok, d.End()); err != nil {
-				return "", fmt.Errorf("importPrefix: %s", err)
-			} else if e > importEnd {
-				importEnd = e
-			}
-		}
+// Add adds fixes to the batch. A fix of an import supersedes an earlier
+// fix of the same import, so that, for example, an import that is added
+// then deleted is left alone.
+func (b *ImportFixBatch) Add(fixes ...*imports.ImportFix) {
+	for _, fix := range fixes {
+		b.fixes[fix.StmtInfo] = fix.FixType
 	}
+}
 
-	maybeAdjustToLineEnd := func(pos token.Pos, isCommentNode bool) int {
-		offset, err := safetoken.Offset(tok, pos)
-		if err != nil {
-			return -1
-		}
+// AddImport adds to the batch a fix that imports the package of the
+// specified path, with the specified name, if not empty.
+func (b *ImportFixBatch) AddImport(path, name string) {
+	b.Add(&imports.ImportFix{
+		StmtInfo: imports.ImportInfo{ImportPath: path, Name: name},
+		FixType:  imports.AddImport,
+	})
+}
 
-		// Don't go past the end of the file.
-		if offset > len(src) {
-			offset = len(src)
-		}
-		// The go/ast package does not account for different line endings, and
-		// specifically, in the text of a comment, it will strip out \r\n line
-		// endings in favor of \n. To account for these differences, we try to
-		// return a position on the next line whenever possible.
-		switch line := safetoken.Line(tok, tok.Pos(offset)); {
-		case line < tok.LineCount():
-			nextLineOffset, err := safetoken.Offset(tok, tok.LineStart(line+1))
-			if err != nil {
-				return -1
-			}
-			// If we found a position that is at the end of a line, move the
-			// offset to the start of the next line.
-			if offset+1 == nextLineOffset {
-				offset = nextLineOffset
-			}
-		case isCommentNode, offset+1 == tok.Size():
-			// If the last line of the file is a comment, or we are at the end
-			// of the file, the prefix is the entire file.
-			offset = len(src)
-		}
-		return offset
-	}
-	if importEnd == 0 {
-		pkgEnd := f.Name.End()
-		importEnd = maybeAdjustToLineEnd(pkgEnd, false)
-	}
-	for _, cgroup := range f.Comments {
-		for _, c := range cgroup.List {
-			if end, err := safetoken.Offset(tok, c.End()); err != nil {
-				return "", err
-			} else if end > importEnd {
-				startLine := safetoken.Position(tok, c.Pos()).Line
-				endLine := safetoken.Position(tok, c.End()).Line
-
-				// Work around golang/go#41197 by checking if the comment might
-				// contain "\r", and if so, find the actual end position of the
-				// comment by scanning the content of the file.
-				startOffset, err := safetoken.Offset(tok, c.Pos())
-				if err != nil {
-					return "", err
-				}
-				if startLine != endLine && bytes.Contains(src[startOffset:], []byte("\r")) {
-					if commentEnd := scanForCommentEnd(src[startOffset:]); commentEnd > 0 {
-						end = startOffset + commentEnd
-					}
-				}
-				importEnd = maybeAdjustToLineEnd(tok.Pos(end), true)
-			}
-		}
+// Len returns the number of fixes of the batch.
+func (b *ImportFixBatch) Len() int { return len(b.fixes) }
+
+// Edits returns the text edits of the source that apply all the fixes
+// of the batch, merged into a single edit of its imports. The new text
+// uses the line terminators of the source.
+func (b *ImportFixBatch) Edits() ([]protocol.TextEdit, error) {
+	if len(b.fixes) == 0 {
+		return nil, nil
 	}
-	if importEnd > len(src) {
-		importEnd = len(src)
+	var fixes []*imports.ImportFix
+	for info, typ := range b.fixes {
+		fixes = append(fixes, &imports.ImportFix{StmtInfo: info, FixType: typ})
 	}
-	return string(src[:importEnd]), nil
+	// Sort for determinism.
+	sort.Slice(fixes, func(i, j int) bool {
+		x, y := fixes[i].StmtInfo, fixes[j].StmtInfo
+		if x.ImportPath != y.ImportPath {
+			return x.ImportPath < y.ImportPath
+		}
+		return x.Name < y.Name
+	})
+	edits, err := ComputeImportFixEdits(b.localPrefix, b.src, fixes...)
+	if err != nil {
+		return nil, err
+	}
+	return editsWithLineEnding(b.src, edits, lineEnding(b.src))
 }
 
-// scanForCommentEnd returns the offset of the end of the multi-line comment
-// at the start of the given byte slice.
-func scanForCommentEnd(src []byte) int {
-	var s scanner.Scanner
-	s.Init(bytes.NewReader(src))
-	s.Mode ^= scanner.SkipComments
-
-	t := s.Scan()
-	if t == scanner.Comment {
-		return s.Pos().Offset
+func computeFixEdits(src []byte, options *imports.Options, fixes []*imports.ImportFix) ([]protocol.TextEdit, error) {
+	edits, err := imports.ComputeFixEdits(src, options, fixes)
+	if err != nil {
+		return nil, err
 	}
-	return 0
+	return protocolEditsFromSource(src, edits)
 }
 
 func computeTextEdits(ctx context.Context, pgf *parsego.File, formatted string) ([]protocol.TextEdit, error) {
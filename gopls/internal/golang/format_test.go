@@ -1,75 +1,117 @@
-// Copyright 2020 The Go Authors. All rights reserved.
+// Copyright 2025 The Go Authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
 package golang
 
 import (
-	"strings"
 	"testing"
 
-	"golang.org/x/tools/gopls/internal/test/compare"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/settings"
+	"golang.org/x/tools/internal/imports"
+)
+
+func TestImportFixBatch(t *testing.T) {
+	const src = `package p
+
+import "fmt"
+
+var _ = fmt.Sprint
+`
+	const want = `package p
+
+import (
+	"fmt"
+	str "strconv"
+	"strings"
 )
 
-func TestImportPrefix(t *testing.T) {
-	for i, tt := range []struct {
-		input, want string
+var _ = fmt.Sprint
+`
+	batch := NewImportFixBatch("", []byte(src))
+	batch.AddImport("strings", "")
+	batch.AddImport("os", "")
+	batch.AddImport("strings", "") // duplicate
+	batch.AddImport("strconv", "str")
+	batch.Add(&imports.ImportFix{
+		StmtInfo: imports.ImportInfo{ImportPath: "os"},
+		FixType:  imports.DeleteImport,
+	})
+	if got := batch.Len(); got != 3 {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+	edits, err := batch.Edits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := protocol.ApplyEdits(protocol.NewMapper("", []byte(src)), edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("edited source:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImportFixBatchLineEnding(t *testing.T) {
+	const src = "package p\r\n\r\nimport \"fmt\"\r\n\r\nvar _ = fmt.Sprint\r\n"
+	const want = "package p\r\n\r\nimport (\r\n\t\"fmt\"\r\n\t\"strings\"\r\n)\r\n\r\nvar _ = fmt.Sprint\r\n"
+	batch := NewImportFixBatch("", []byte(src))
+	batch.AddImport("strings", "")
+	edits, err := batch.Edits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := protocol.ApplyEdits(protocol.NewMapper("", []byte(src)), edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("edited source = %q, want %q", got, want)
+	}
+}
+
+func TestWithLineEnding(t *testing.T) {
+	for _, test := range []struct {
+		src, eol string
 	}{
-		{"package foo", "package foo"},
-		{"package foo\n", "package foo\n"},
-		{"package foo\n\nfunc f(){}\n", "package foo\n"},
-		{"package foo\n\nimport \"fmt\"\n", "package foo\n\nimport \"fmt\""},
-		{"package foo\nimport (\n\"fmt\"\n)\n", "package foo\nimport (\n\"fmt\"\n)"},
-		{"\n\n\npackage foo\n", "\n\n\npackage foo\n"},
-		{"// hi \n\npackage foo //xx\nfunc _(){}\n", "// hi \n\npackage foo //xx\n"},
-		{"package foo //hi\n", "package foo //hi\n"},
-		{"//hi\npackage foo\n//a\n\n//b\n", "//hi\npackage foo\n//a\n\n//b\n"},
-		{
-			"package a\n\nimport (\n  \"fmt\"\n)\n//hi\n",
-			"package a\n\nimport (\n  \"fmt\"\n)\n//hi\n",
-		},
-		{`package a /*hi*/`, `package a /*hi*/`},
-		{"package main\r\n\r\nimport \"go/types\"\r\n\r\n/*\r\n\r\n */\r\n", "package main\r\n\r\nimport \"go/types\"\r\n\r\n/*\r\n\r\n */\r\n"},
-		{"package x; import \"os\"; func f() {}\n\n", "package x; import \"os\""},
-		{"package x; func f() {fmt.Println()}\n\n", "package x"},
+		{"package p\n", "\n"},
+		{"package p\r\n\nvar x int\n", "\r\n"},
+		{"package p", "\n"},
 	} {
-		got, err := importPrefix([]byte(tt.input))
-		if err != nil {
-			t.Fatal(err)
-		}
-		if d := compare.Text(tt.want, got); d != "" {
-			t.Errorf("%d: failed for %q:\n%s", i, tt.input, d)
+		if got := lineEnding([]byte(test.src)); got != test.eol {
+			t.Errorf("lineEnding(%q) = %q, want %q", test.src, got, test.eol)
 		}
 	}
+	const text = "a\r\nb\nc"
+	if got, want := withLineEnding(text, "\n"), "a\nb\nc"; got != want {
+		t.Errorf("withLineEnding(%q, LF) = %q, want %q", text, got, want)
+	}
+	if got, want := withLineEnding(text, "\r\n"), "a\r\nb\r\nc"; got != want {
+		t.Errorf("withLineEnding(%q, CRLF) = %q, want %q", text, got, want)
+	}
 }
 
-func TestCRLFFile(t *testing.T) {
-	for i, tt := range []struct {
-		input, want string
+func TestFormatGenerated(t *testing.T) {
+	const src = "\n\nfunc F() {\n\n\tvar x = 1\n\t_ = x\n}\n"
+	mp := &metadata.Package{}
+	for _, test := range []struct {
+		gofumpt bool
+		want    string
 	}{
-		{
-			input: `package main
-
-/*
-Hi description
-*/
-func Hi() {
-}
-`,
-			want: `package main
-
-/*
-Hi description
-*/`,
-		},
+		{false, "\n\nfunc F() {\n\n\tvar x = 1\n\t_ = x\n}\n"},
+		{true, "\n\nfunc F() {\n\tx := 1\n\t_ = x\n}\n"},
 	} {
-		got, err := importPrefix([]byte(strings.ReplaceAll(tt.input, "\n", "\r\n")))
+		opts := new(settings.Options)
+		opts.Gofumpt = test.gofumpt
+		got, err := formatGenerated(opts, mp, []byte(src), true)
 		if err != nil {
 			t.Fatal(err)
 		}
-		want := strings.ReplaceAll(tt.want, "\n", "\r\n")
-		if d := compare.Text(want, got); d != "" {
-			t.Errorf("%d: failed for %q:\n%s", i, tt.input, d)
+		if string(got) != test.want {
+			t.Errorf("formatGenerated(gofumpt=%t) = %q, want %q", test.gofumpt, got, test.want)
 		}
 	}
 }
@@ -0,0 +1,453 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code actions "Convert function F to method of
+// T" and "Convert method T.F to function".
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/edge"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// funcDeclAt returns the declaration of the function or method whose
+// signature (but not body) encloses [start, end), and its object.
+func funcDeclAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.FuncDecl, *types.Func) {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, nil
+	}
+	curDecl := curSel
+	if !is[*ast.FuncDecl](curDecl.Node()) {
+		for cur := range curSel.Ancestors((*ast.FuncDecl)(nil)) {
+			curDecl = cur
+			break
+		}
+	}
+	decl, ok := curDecl.Node().(*ast.FuncDecl)
+	if !ok || decl.Body == nil || !posRangeContains(decl.Pos(), decl.Type.End(), start, end) {
+		return nil, nil
+	}
+	fn, ok := pkg.TypesInfo().Defs[decl.Name].(*types.Func)
+	if !ok {
+		return nil, nil
+	}
+	return decl, fn
+}
+
+// funcToMethodAt returns the declaration of the selected function F,
+// if it can become a method of the named type T (declared in pkg) of
+// its first parameter, whose type is T or *T; and T.
+func funcToMethodAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.FuncDecl, *types.Func, *types.Named) {
+	decl, fn := funcDeclAt(pkg, pgf, start, end)
+	if decl == nil || decl.Recv != nil || decl.Type.TypeParams != nil || decl.Type.Params.NumFields() == 0 {
+		return nil, nil, nil
+	}
+	param := decl.Type.Params.List[0]
+	typ := param.Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	id, ok := typ.(*ast.Ident)
+	if !ok {
+		return nil, nil, nil
+	}
+	tname, ok := pkg.TypesInfo().Uses[id].(*types.TypeName)
+	if !ok || tname.IsAlias() || tname.Pkg() != pkg.Types() || tname.Parent() != pkg.Types().Scope() {
+		return nil, nil, nil
+	}
+	named, ok := tname.Type().(*types.Named)
+	if !ok || named.TypeParams() != nil {
+		return nil, nil, nil
+	}
+	switch named.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return nil, nil, nil // invalid receiver type
+	}
+	if obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), false, pkg.Types(), fn.Name()); obj != nil {
+		return nil, nil, nil // name is taken
+	}
+	return decl, fn, named
+}
+
+// methodToFuncAt returns the declaration of the selected method of
+// the non-generic named type T declared in pkg, if the method can
+// become a package-level function.
+func methodToFuncAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.FuncDecl, *types.Func, *types.Named) {
+	decl, fn := funcDeclAt(pkg, pgf, start, end)
+	if decl == nil || decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return nil, nil, nil
+	}
+	_, named := typesinternal.ReceiverNamed(fn.Signature().Recv())
+	if named == nil || named.Obj().Pkg() != pkg.Types() || named.TypeParams() != nil {
+		return nil, nil, nil
+	}
+	if pkg.Types().Scope().Lookup(fn.Name()) != nil {
+		return nil, nil, nil // name is taken
+	}
+	return decl, fn, named
+}
+
+// isSameFunc reports whether obj, which may belong to a different
+// type-checking of the same package, denotes the function or method fn.
+func isSameFunc(obj types.Object, fn *types.Func) bool {
+	obj2, ok := obj.(*types.Func)
+	if !ok || obj2.Name() != fn.Name() || obj2.Pkg() == nil || obj2.Pkg().Path() != fn.Pkg().Path() {
+		return false
+	}
+	recv, recv2 := fn.Signature().Recv(), obj2.Signature().Recv()
+	if recv == nil || recv2 == nil {
+		return recv == nil && recv2 == nil && obj2.Parent() == obj2.Pkg().Scope()
+	}
+	_, named := typesinternal.ReceiverNamed(recv)
+	_, named2 := typesinternal.ReceiverNamed(recv2)
+	return named2 != nil && named2.Obj().Name() == named.Obj().Name()
+}
+
+// ConvertFuncToMethod converts the selected function F, whose first
+// parameter has type T or *T, to a method of T, and replaces each call
+// F(x, ...) by x.F(...), and each other reference to F by a method
+// expression T.F or (*T).F.
+func ConvertFuncToMethod(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	decl, fn, named := funcToMethodAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, fmt.Errorf("no function selected")
+	}
+	c := newWorkspaceChange()
+
+	// Move the first parameter to the receiver.
+	param := decl.Type.Params.List[0]
+	typeText, err := nodeText(pgf, param.Type)
+	if err != nil {
+		return nil, err
+	}
+	recv := typeText
+	if len(param.Names) > 0 {
+		recv = param.Names[0].Name + " " + typeText
+	}
+	if err := c.addEdit(pgf, decl.Name.Pos(), decl.Name.Pos(), "("+recv+") "); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(param.Names) > 1: // F(x, y T)
+		err = c.addEdit(pgf, param.Names[0].Pos(), param.Names[1].Pos(), "")
+	case len(decl.Type.Params.List) > 1: // F(x T, y U)
+		err = c.addEdit(pgf, param.Pos(), decl.Type.Params.List[1].Pos(), "")
+	default: // F(x T)
+		err = c.addEdit(pgf, param.Pos(), decl.Type.Params.Closing, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the references to the function.
+	_, ptr := param.Type.(*ast.StarExpr)
+	pkgs, err := typeCheckReverseDependencies(ctx, snapshot, pgf.URI, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		info := pkg.TypesInfo()
+		for _, pgf := range pkg.CompiledGoFiles() {
+			for cur := range pgf.Cursor.Preorder((*ast.Ident)(nil)) {
+				id := cur.Node().(*ast.Ident)
+				if !isSameFunc(info.Uses[id], fn) {
+					continue
+				}
+				// The type of the first parameter, in this package.
+				paramType := info.Uses[id].(*types.Func).Signature().Params().At(0).Type()
+
+				// e is F or pkg.F.
+				curE := cur
+				if ek, _ := cur.Edge(); ek == edge.SelectorExpr_Sel {
+					curE = cur.Parent()
+				}
+				e := curE.Node().(ast.Expr)
+
+				ek, _ := curE.Edge()
+				if ek != edge.CallExpr_Fun {
+					// Replace F by a method expression.
+					prefix, ok, err := c.qualifier(pkg, pgf, named.Obj(), e.Pos())
+					if err != nil {
+						return nil, err
+					}
+					if !ok {
+						c.addConflict(pkg, e.Pos(), "method expression %s.%s would refer to unexported type", named.Obj().Name(), fn.Name())
+						continue
+					}
+					expr := prefix + named.Obj().Name() + "." + fn.Name()
+					if ptr {
+						expr = "(*" + prefix + named.Obj().Name() + ")." + fn.Name()
+					}
+					if err := c.addEdit(pgf, e.Pos(), e.End(), expr); err != nil {
+						return nil, err
+					}
+					continue
+				}
+
+				// Replace the call F(x, ...) by x.F(...).
+				call := curE.Parent().Node().(*ast.CallExpr)
+				if is[*types.Tuple](info.TypeOf(call.Args[0])) {
+					c.addConflict(pkg, call.Pos(), "call %s has a multi-valued argument", types.ExprString(call))
+					continue
+				}
+				arg := call.Args[0]
+				if tv := info.Types[arg]; tv.Value != nil || !types.Identical(tv.Type, paramType) {
+					c.addConflict(pkg, arg.Pos(), "argument %s does not have type %s", types.ExprString(arg),
+						types.TypeString(paramType, types.RelativeTo(pkg.Types())))
+					continue
+				}
+				x, err := receiverText(info, pgf, arg, ptr)
+				if err != nil {
+					return nil, err
+				}
+				end := call.Rparen
+				if len(call.Args) > 1 {
+					end = call.Args[1].Pos()
+				}
+				if err := c.addEdit(pgf, call.Pos(), end, x+"."+fn.Name()+"("); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := c.conflictError("cannot convert %s to a method of %s", fn.Name(), named.Obj().Name()); err != nil {
+		return nil, err
+	}
+	return c.documentChanges(ctx, snapshot)
+}
+
+// receiverText returns the text of the operand x of a method call x.f
+// whose receiver is the argument arg: arg itself, parenthesized if
+// necessary, or its operand in the case of &v and *p, since v.f and
+// p.f implicitly take the address of v and dereference p.
+func receiverText(info *types.Info, pgf *parsego.File, arg ast.Expr, ptr bool) (string, error) {
+	arg = ast.Unparen(arg)
+	switch e := arg.(type) {
+	case *ast.UnaryExpr:
+		if ptr && e.Op == token.AND && isAddressable(info, e.X) {
+			arg = ast.Unparen(e.X)
+		}
+	case *ast.StarExpr:
+		if !ptr {
+			arg = ast.Unparen(e.X)
+		}
+	}
+	text, err := nodeText(pgf, arg)
+	if err != nil {
+		return "", err
+	}
+	switch arg.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr, *ast.IndexListExpr,
+		*ast.SliceExpr, *ast.TypeAssertExpr:
+		return text, nil
+	}
+	return "(" + text + ")", nil
+}
+
+// nodeText returns the source text of node n in pgf.
+func nodeText(pgf *parsego.File, n ast.Node) (string, error) {
+	start, end, err := pgf.NodeOffsets(n)
+	if err != nil {
+		return "", err
+	}
+	return string(pgf.Src[start:end]), nil
+}
+
+// ConvertMethodToFunc converts the selected method T.F to a
+// package-level function F whose first parameter is the receiver, and
+// replaces each call x.F(...) by F(x, ...), and each method expression
+// T.F by F.
+func ConvertMethodToFunc(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	decl, fn, named := methodToFuncAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, fmt.Errorf("no method selected")
+	}
+	c := newWorkspaceChange()
+
+	// Move the receiver to the first parameter.
+	field := decl.Recv.List[0]
+	recv, err := nodeText(pgf, field)
+	if err != nil {
+		return nil, err
+	}
+	params := decl.Type.Params
+	if len(field.Names) == 0 && params.NumFields() > 0 && len(params.List[0].Names) > 0 {
+		recv = "_ " + recv // parameters are named
+	}
+	if err := c.addEdit(pgf, decl.Recv.Pos(), decl.Name.Pos(), ""); err != nil {
+		return nil, err
+	}
+	switch {
+	case len(params.List) == 0:
+		err = c.addEdit(pgf, params.Opening+1, params.Opening+1, recv)
+	case len(field.Names) > 0 && len(params.List[0].Names) == 0:
+		// Name the parameters, as the receiver is named.
+		for i, param := range params.List {
+			text := "_ "
+			if i == 0 {
+				text = recv + ", _ "
+			}
+			if err = c.addEdit(pgf, param.Type.Pos(), param.Type.Pos(), text); err != nil {
+				break
+			}
+		}
+	default:
+		err = c.addEdit(pgf, params.List[0].Pos(), params.List[0].Pos(), recv+", ")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the references to the method.
+	isPtr, _ := typesinternal.ReceiverNamed(fn.Signature().Recv())
+	pkgs, err := typeCheckReverseDependencies(ctx, snapshot, pgf.URI, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		info := pkg.TypesInfo()
+		for _, pgf := range pkg.CompiledGoFiles() {
+			for cur := range pgf.Cursor.Preorder((*ast.SelectorExpr)(nil)) {
+				n := cur.Node().(*ast.SelectorExpr)
+				sel, ok := info.Selections[n]
+				if !ok || !isSameFunc(sel.Obj(), fn) {
+					continue
+				}
+				if len(sel.Index()) > 1 {
+					c.addConflict(pkg, n.Pos(), "%s uses method %s promoted from an embedded field", types.ExprString(n), fn.Name())
+					continue
+				}
+				qual, ok, err := c.qualifier(pkg, pgf, fn, n.Pos())
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					c.addConflict(pkg, n.Pos(), "unexported function %s would not be accessible", fn.Name())
+					continue
+				}
+				switch sel.Kind() {
+				case types.MethodExpr:
+					// T.F or (*T).F
+					_, recvIsPtr := types.Unalias(sel.Recv()).(*types.Pointer)
+					if recvIsPtr != isPtr {
+						c.addConflict(pkg, n.Pos(), "method expression %s has a different type than function %s", types.ExprString(n), fn.Name())
+						continue
+					}
+					if err := c.addEdit(pgf, n.Pos(), n.End(), qual+fn.Name()); err != nil {
+						return nil, err
+					}
+
+				case types.MethodVal:
+					ek, _ := cur.Edge()
+					if ek != edge.CallExpr_Fun {
+						c.addConflict(pkg, n.Pos(), "method value %s cannot be converted", types.ExprString(n))
+						continue
+					}
+					call := cur.Parent().Node().(*ast.CallExpr)
+					arg, err := argText(info, pgf, n.X, isPtr)
+					if err != nil {
+						return nil, err
+					}
+					if len(call.Args) > 0 {
+						arg += ", "
+					}
+					if err := c.addEdit(pgf, n.Pos(), call.Lparen+1, qual+fn.Name()+"("+arg); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			// A function cannot implement an interface.
+			forEachInterfaceConversion(info, pgf.File, func(e ast.Expr, iface types.Type) {
+				t := info.TypeOf(e)
+				if t == nil || types.IsInterface(t) {
+					return
+				}
+				if obj, _, _ := types.LookupFieldOrMethod(iface, false, fn.Pkg(), fn.Name()); obj == nil {
+					return
+				}
+				if obj, _, _ := types.LookupFieldOrMethod(t, false, fn.Pkg(), fn.Name()); obj != nil && isSameFunc(obj, fn) {
+					c.addConflict(pkg, e.Pos(), "value of type %s would no longer implement %s",
+						types.TypeString(t, types.RelativeTo(pkg.Types())), types.TypeString(iface, types.RelativeTo(pkg.Types())))
+				}
+			})
+		}
+	}
+
+	if err := c.conflictError("cannot convert method %s.%s to a function", named.Obj().Name(), fn.Name()); err != nil {
+		return nil, err
+	}
+	return c.documentChanges(ctx, snapshot)
+}
+
+// qualifier returns the prefix that qualifies a reference at pos in
+// pgf to the package-level object obj, adding an import if needed.
+// It reports false if obj is unexported and inaccessible from pkg.
+func (c *workspaceChange) qualifier(pkg *cache.Package, pgf *parsego.File, obj types.Object, pos token.Pos) (string, bool, error) {
+	if pkg.Types().Path() == obj.Pkg().Path() {
+		return "", true, nil
+	}
+	if !obj.Exported() {
+		return "", false, nil
+	}
+	_, prefix, edits := analysisinternal.AddImport(pkg.TypesInfo(), pgf.File, obj.Pkg().Name(), obj.Pkg().Path(), obj.Name(), pos)
+	for _, edit := range edits {
+		if err := c.addEdit(pgf, edit.Pos, edit.End, string(edit.NewText)); err != nil {
+			return "", false, err
+		}
+	}
+	return prefix, true, nil
+}
+
+// argText returns the text of the argument for the receiver of a
+// method call x.f whose operand is x: x itself, or &x or *x if the
+// call implicitly takes the address of x or dereferences it.
+func argText(info *types.Info, pgf *parsego.File, x ast.Expr, ptr bool) (string, error) {
+	x = ast.Unparen(x)
+	_, isPtr := info.TypeOf(x).Underlying().(*types.Pointer)
+	switch {
+	case ptr && !isPtr:
+		if star, ok := x.(*ast.StarExpr); ok {
+			return nodeText(pgf, star.X) // &*p = p
+		}
+		text, err := nodeText(pgf, x)
+		return "&" + text, err
+	case !ptr && isPtr:
+		if addr, ok := x.(*ast.UnaryExpr); ok && addr.Op == token.AND {
+			return nodeText(pgf, addr.X) // *&v = v
+		}
+		text, err := nodeText(pgf, x)
+		return "*" + text, err
+	}
+	return nodeText(pgf, x)
+}
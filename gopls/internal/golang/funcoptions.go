@@ -0,0 +1,160 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Generate functional options for T".
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+)
+
+// funcOptions holds the names of the declarations generated for the
+// functional options of a struct type T.
+type funcOptions struct {
+	option string            // the Option type
+	with   map[string]string // the WithF function of each field F
+	ctor   string            // the NewT constructor
+}
+
+// funcOptionsAt returns the declaration of the struct type enclosing
+// [start, end), as for [structDeclAt], and the names of its functional
+// options, provided that it has at least one named field and that the
+// names are free in its package.
+func funcOptionsAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.GenDecl, *ast.TypeSpec, *types.Named, *funcOptions) {
+	decl, spec, named := structDeclAt(pkg, pgf, start, end)
+	if named == nil {
+		return nil, nil, nil, nil
+	}
+	// The names of the generated declarations are exported if T is.
+	name := named.Obj().Name()
+	export := func(s string) string {
+		if named.Obj().Exported() {
+			return s
+		}
+		return lowerFirst(s)
+	}
+	scope := pkg.Types().Scope()
+	// Prefer Option and WithF; but if any of those is taken, as when
+	// another type of the package has options, use TOption and WithTF.
+	for _, prefix := range []string{"", name} {
+		opts := &funcOptions{
+			option: export(prefix + "Option"),
+			with:   make(map[string]string),
+			ctor:   export("New" + strings.ToUpper(name[:1]) + name[1:]),
+		}
+		if prefix != "" {
+			prefix = strings.ToUpper(prefix[:1]) + prefix[1:]
+		}
+		taken := scope.Lookup(opts.option) != nil || scope.Lookup(opts.ctor) != nil
+		for f := range named.Underlying().(*types.Struct).Fields() {
+			if f.Name() == "_" {
+				continue
+			}
+			with := export("With" + prefix + strings.ToUpper(f.Name()[:1]) + f.Name()[1:])
+			if scope.Lookup(with) != nil {
+				taken = true
+			}
+			opts.with[f.Name()] = with
+		}
+		if len(opts.with) == 0 {
+			return nil, nil, nil, nil
+		}
+		if !taken {
+			return decl, spec, named, opts
+		}
+	}
+	return nil, nil, nil, nil
+}
+
+// addFuncOptions is a singleFileFixer that generates functional
+// options for the selected struct type T: a type Option func(*T), a
+// function WithF for each field F that returns an Option that sets
+// it, and a constructor NewT that applies a list of options to a new
+// T.
+func addFuncOptions(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	decl, spec, named, opts := funcOptionsAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, nil, fmt.Errorf("no struct type selected")
+	}
+	name := named.Obj().Name()
+
+	// The variable that holds the *T in each function.
+	recv := receiverName(named)
+	if recv == "" || recv == "_" {
+		recv = strings.ToLower(name[:1])
+	}
+	if recv == "opt" || recv == "opts" {
+		recv = "x"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// %s configures %s.\n", indefinite(opts.option, true), indefinite(name, false))
+	fmt.Fprintf(&buf, "type %s func(*%s)\n", opts.option, name)
+	for _, field := range spec.Type.(*ast.StructType).Fields.List {
+		typ, err := nodeText(pgf, field.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		names := field.Names
+		if len(names) == 0 {
+			if id := embeddedIdent(field.Type); id != nil {
+				names = []*ast.Ident{id}
+			}
+		}
+		for _, id := range names {
+			with, ok := opts.with[id.Name]
+			if !ok {
+				continue // blank
+			}
+			param := lowerFirst(id.Name)
+			if token.IsKeyword(param) || param == recv {
+				param = "v"
+			}
+			fmt.Fprintf(&buf, "\n// %s returns %s that sets the %s field of %s.\n", with, indefinite(opts.option, false), id.Name, indefinite(name, false))
+			fmt.Fprintf(&buf, "func %s(%s %s) %s {\n", with, param, typ, opts.option)
+			fmt.Fprintf(&buf, "return func(%s *%s) { %s.%s = %s }\n}\n", recv, name, recv, id.Name, param)
+		}
+	}
+	fmt.Fprintf(&buf, "\n// %s returns a new %s configured by the given options.\n", opts.ctor, name)
+	fmt.Fprintf(&buf, "func %s(opts ...%s) *%s {\n", opts.ctor, opts.option, name)
+	fmt.Fprintf(&buf, "%s := new(%s)\n", recv, name)
+	fmt.Fprintf(&buf, "for _, opt := range opts {\nopt(%s)\n}\n", recv)
+	fmt.Fprintf(&buf, "return %s\n}\n", recv)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting generated declarations: %v", err)
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{
+		TextEdits: []analysis.TextEdit{{
+			Pos:     decl.End(),
+			End:     decl.End(),
+			NewText: append([]byte("\n\n"), bytes.TrimSuffix(src, []byte("\n"))...),
+		}},
+	}, nil
+}
+
+// indefinite returns name preceded by the article "a" or "an",
+// capitalized if it begins a sentence.
+func indefinite(name string, sentence bool) string {
+	article := "a"
+	if strings.ContainsRune("AEIOUaeiou", rune(name[0])) {
+		article = "an"
+	}
+	if sentence {
+		article = strings.ToUpper(article[:1]) + article[1:]
+	}
+	return article + " " + name
+}
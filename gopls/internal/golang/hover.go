@@ -105,6 +105,14 @@ type hoverResult struct {
 	// embedded field.
 	promotedFields string
 
+	// coverage summarizes the test coverage of a function, from the
+	// most recently loaded coverage profile, or is "" if none.
+	coverage string
+
+	// coverageURI is the file of the function whose coverage is
+	// summarized.
+	coverageURI protocol.DocumentURI
+
 	// footer is additional content to insert at the bottom of the hover
 	// documentation, before the pkgdoc link.
 	footer string
@@ -114,7 +122,9 @@ type hoverResult struct {
 // It may return nil even on success.
 //
 // If pkgURL is non-nil, it should be used to generate doc links.
-func Hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, position protocol.Position, pkgURL func(path PackagePath, fragment string) protocol.URI) (*protocol.Hover, error) {
+// If coverageURL is non-nil, it should be used to link the coverage
+// of a function to an annotated view of the source of its file.
+func Hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, position protocol.Position, pkgURL func(path PackagePath, fragment string) protocol.URI, coverageURL func(protocol.DocumentURI) protocol.URI) (*protocol.Hover, error) {
 	ctx, done := event.Start(ctx, "golang.Hover")
 	defer done()
 
@@ -125,6 +135,11 @@ func Hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, positi
 	if h == nil {
 		return nil, nil
 	}
+	if h.coverage != "" && coverageURL != nil && snapshot.Options().PreferredContentFormat == protocol.Markdown {
+		if url := coverageURL(h.coverageURI); url != "" {
+			h.coverage += fmt.Sprintf(" ([browse](%s))", url)
+		}
+	}
 	hover, err := formatHover(h, snapshot.Options(), pkgURL)
 	if err != nil {
 		return nil, err
@@ -223,6 +238,19 @@ func hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp pro
 		return hoverEmbed(fh, embedRng, pattern)
 	}
 
+	// Handle hovering over a string literal naming a file of the package
+	// directory, such as a testdata fixture.
+	if lit, uri := packageFileAt(snapshot, pgf, pos); lit != nil {
+		return hoverPackageFile(ctx, snapshot, pgf, lit, uri)
+	}
+
+	// Handle hovering over the name of a subtest.
+	if lit, name, err := subtestAt(ctx, snapshot, pkg, pgf, pos); err != nil {
+		return protocol.Range{}, nil, err
+	} else if lit != nil {
+		return hoverSubtest(pgf, lit, name)
+	}
+
 	// hoverRange is the range reported to the client (e.g. for highlighting).
 	// It may be an expansion around the selected identifier,
 	// for instance when hovering over a linkname directive or doc link.
@@ -622,6 +650,20 @@ func hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp pro
 		footer = fmt.Sprintf("Added in %v", sym.Version)
 	}
 
+	// Report the coverage of a function's body, if known.
+	var coverage string
+	if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+		if cov := snapshot.Coverage(declPGF.URI); cov != nil {
+			covered, uncovered := cov.Lines(
+				safetoken.Line(declPGF.Tok, decl.Body.Lbrace),
+				safetoken.Line(declPGF.Tok, decl.Body.Rbrace))
+			if total := covered + uncovered; total > 0 {
+				coverage = fmt.Sprintf("Test coverage: %d%% (%d lines covered, %d uncovered)",
+					covered*100/total, covered, uncovered)
+			}
+		}
+	}
+
 	return *hoverRange, &hoverResult{
 		Synopsis:          doc.Synopsis(docText),
 		FullDocumentation: docText,
@@ -633,6 +675,8 @@ func hover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp pro
 		typeDecl:          typeDecl,
 		methods:           methods,
 		promotedFields:    fields,
+		coverage:          coverage,
+		coverageURI:       declPGF.URI,
 		footer:            footer,
 	}, nil
 }
@@ -766,7 +810,7 @@ func hoverImport(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Packa
 			}
 			continue
 		}
-		pgf, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+		pgf, err := snapshot.ParseHeader(ctx, fh)
 		if err != nil {
 			if ctx.Err() != nil {
 				return protocol.Range{}, nil, ctx.Err()
@@ -1330,6 +1374,7 @@ func formatHover(h *hoverResult, options *settings.Options, pkgURL func(path Pac
 
 		// Footer section.
 		sections = append(sections, []string{
+			h.coverage,
 			h.footer,
 			formatLink(h, options, pkgURL),
 		})
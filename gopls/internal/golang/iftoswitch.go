@@ -0,0 +1,318 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Convert if/else-if chain to
+// switch".
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/astutil/edge"
+	"golang.org/x/tools/internal/diff"
+)
+
+// An ifChain describes a chain of if/else-if statements that compare
+// the same tag expression with a list of values in each condition:
+//
+//	if x == a || x == b { ... } else if x == c { ... } else { ... }
+type ifChain struct {
+	ifs    []*ast.IfStmt
+	values [][]ast.Expr   // values compared with tag, per if statement
+	tag    ast.Expr       // the expression compared in every condition
+	els    *ast.BlockStmt // final else block, or nil
+}
+
+// canConvertIfToSwitch returns the chain of if/else-if statements
+// one of whose headers (such as "if x == a {" or "} else if x == b {")
+// encloses [start, end), if it can be converted to a switch statement.
+func canConvertIfToSwitch(info *types.Info, pgf *parsego.File, start, end token.Pos) (*ifChain, error) {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, fmt.Errorf("no enclosing if statement")
+	}
+	curIf := curSel
+	if !is[*ast.IfStmt](curIf.Node()) {
+		for cur := range curSel.Ancestors((*ast.IfStmt)(nil)) {
+			curIf = cur
+			break
+		}
+	}
+	if !is[*ast.IfStmt](curIf.Node()) {
+		return nil, fmt.Errorf("no enclosing if statement")
+	}
+
+	// Find the head of the chain.
+	for {
+		ek, _ := curIf.Edge()
+		if ek != edge.IfStmt_Else {
+			break
+		}
+		curIf = curIf.Parent()
+	}
+	head := curIf.Node().(*ast.IfStmt)
+
+	chain := &ifChain{}
+	for s := head; ; {
+		if s != head && s.Init != nil {
+			return nil, fmt.Errorf("else-if statement has an init statement")
+		}
+		chain.ifs = append(chain.ifs, s)
+		if s.Else == nil {
+			break
+		}
+		if els, ok := s.Else.(*ast.BlockStmt); ok {
+			chain.els = els
+			break
+		}
+		s = s.Else.(*ast.IfStmt)
+	}
+	if len(chain.ifs) < 2 {
+		return nil, fmt.Errorf("not an if/else-if chain")
+	}
+	if !slices.ContainsFunc(chain.headers(), func(rng [2]token.Pos) bool {
+		return posRangeContains(rng[0], rng[1], start, end)
+	}) {
+		return nil, fmt.Errorf("selection is not within the header of an if statement")
+	}
+
+	// Choose the tag: an operand of the first comparison
+	// that appears in every condition.
+	first, ok := ast.Unparen(disjuncts(head.Cond)[0]).(*ast.BinaryExpr)
+	if !ok || first.Op != token.EQL {
+		return nil, fmt.Errorf("condition is not a comparison")
+	}
+	for _, tag := range []ast.Expr{first.X, first.Y} {
+		if !isTagExpr(info, tag) {
+			continue
+		}
+		values, ok := chainValues(chain.ifs, tag)
+		if ok {
+			chain.tag = tag
+			chain.values = values
+			break
+		}
+	}
+	if chain.tag == nil {
+		return nil, fmt.Errorf("conditions do not compare the same expression")
+	}
+
+	// Duplicate constant cases are not permitted in a switch.
+	seen := make(map[string]bool)
+	for _, values := range chain.values {
+		for _, v := range values {
+			if tv, ok := info.Types[v]; ok && tv.Value != nil {
+				key := tv.Value.ExactString()
+				if seen[key] {
+					return nil, fmt.Errorf("duplicate case %s", key)
+				}
+				seen[key] = true
+			}
+		}
+	}
+
+	// An unlabeled break statement within a branch would
+	// break out of the switch instead of the enclosing statement.
+	blocks := make([]*ast.BlockStmt, 0, len(chain.ifs)+1)
+	for _, s := range chain.ifs {
+		blocks = append(blocks, s.Body)
+	}
+	if chain.els != nil {
+		blocks = append(blocks, chain.els)
+	}
+	for _, block := range blocks {
+		if hasUnlabeledBreak(block) {
+			return nil, fmt.Errorf("branch contains an unlabeled break statement")
+		}
+	}
+
+	// The parts of the chain that are replaced must not contain comments.
+	for _, rng := range chain.headers() {
+		for _, cg := range pgf.File.Comments {
+			if rng[0] <= cg.Pos() && cg.End() <= rng[1] {
+				return nil, fmt.Errorf("if/else-if chain contains comments between branches")
+			}
+		}
+	}
+
+	return chain, nil
+}
+
+// headers returns the [start, end) ranges of the parts of the chain
+// that are replaced by switch and case clauses: the first if
+// statement's header, and each "} else if cond {" or "} else {".
+func (chain *ifChain) headers() [][2]token.Pos {
+	var ranges [][2]token.Pos
+	prev := chain.ifs[0]
+	ranges = append(ranges, [2]token.Pos{prev.If, prev.Body.Lbrace + 1})
+	for _, s := range chain.ifs[1:] {
+		ranges = append(ranges, [2]token.Pos{prev.Body.Rbrace, s.Body.Lbrace + 1})
+		prev = s
+	}
+	if chain.els != nil {
+		ranges = append(ranges, [2]token.Pos{prev.Body.Rbrace, chain.els.Lbrace + 1})
+	}
+	return ranges
+}
+
+// disjuncts returns the operands of a chain of || operators.
+func disjuncts(cond ast.Expr) []ast.Expr {
+	if bin, ok := ast.Unparen(cond).(*ast.BinaryExpr); ok && bin.Op == token.LOR {
+		return append(disjuncts(bin.X), disjuncts(bin.Y)...)
+	}
+	return []ast.Expr{cond}
+}
+
+// isTagExpr reports whether e is a non-constant identifier or
+// selector chain, whose evaluation has no effects and may be
+// performed once instead of in each condition.
+func isTagExpr(info *types.Info, e ast.Expr) bool {
+	if tv, ok := info.Types[e]; !ok || tv.Value != nil || !tv.IsValue() {
+		return false
+	}
+	for {
+		switch x := ast.Unparen(e).(type) {
+		case *ast.Ident:
+			return true
+		case *ast.SelectorExpr:
+			e = x.X
+		default:
+			return false
+		}
+	}
+}
+
+// chainValues returns, for each if statement, the values compared
+// with tag in its condition, which must be a disjunction of
+// comparisons of tag.
+func chainValues(ifs []*ast.IfStmt, tag ast.Expr) ([][]ast.Expr, bool) {
+	tagStr := types.ExprString(tag)
+	var values [][]ast.Expr
+	for _, s := range ifs {
+		var vals []ast.Expr
+		for _, d := range disjuncts(s.Cond) {
+			bin, ok := ast.Unparen(d).(*ast.BinaryExpr)
+			if !ok || bin.Op != token.EQL {
+				return nil, false
+			}
+			switch tagStr {
+			case types.ExprString(bin.X):
+				vals = append(vals, bin.Y)
+			case types.ExprString(bin.Y):
+				vals = append(vals, bin.X)
+			default:
+				return nil, false
+			}
+		}
+		values = append(values, vals)
+	}
+	return values, true
+}
+
+// hasUnlabeledBreak reports whether block contains an unlabeled break
+// statement that refers to a statement enclosing the block.
+func hasUnlabeledBreak(block *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(block, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit, *ast.ForStmt, *ast.RangeStmt,
+			*ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			return false
+		case *ast.BranchStmt:
+			if n.Tok == token.BREAK && n.Label == nil {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// convertIfToSwitch is a singleFileFixer that converts an if/else-if
+// chain into a switch statement.
+func convertIfToSwitch(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	chain, err := canConvertIfToSwitch(pkg.TypesInfo(), pgf, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text := func(n ast.Node) (string, error) {
+		start, end, err := safetoken.Offsets(pgf.Tok, n.Pos(), n.End())
+		if err != nil {
+			return "", err
+		}
+		return string(pgf.Src[start:end]), nil
+	}
+	caseClause := func(values []ast.Expr) (string, error) {
+		var texts []string
+		for _, v := range values {
+			t, err := text(v)
+			if err != nil {
+				return "", err
+			}
+			texts = append(texts, t)
+		}
+		return "case " + strings.Join(texts, ", ") + ":", nil
+	}
+
+	// Replace each header by a case clause, retaining the
+	// statements (and comments) of each branch.
+	var edits []diff.Edit
+	for i, rng := range chain.headers() {
+		var newText string
+		switch {
+		case i == 0:
+			// switch [init;] tag {
+			//   case values:
+			head := chain.ifs[0]
+			var sb strings.Builder
+			sb.WriteString("switch ")
+			if head.Init != nil {
+				init, err := text(head.Init)
+				if err != nil {
+					return nil, nil, err
+				}
+				sb.WriteString(init + "; ")
+			}
+			tag, err := text(chain.tag)
+			if err != nil {
+				return nil, nil, err
+			}
+			clause, err := caseClause(chain.values[0])
+			if err != nil {
+				return nil, nil, err
+			}
+			sb.WriteString(tag + " {\n" + clause)
+			newText = sb.String()
+		case i < len(chain.ifs):
+			newText, err = caseClause(chain.values[i])
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			newText = "default:"
+		}
+		startOffset, endOffset, err := safetoken.Offsets(pgf.Tok, rng[0], rng[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		edits = append(edits, diff.Edit{Start: startOffset, End: endOffset, New: newText})
+	}
+
+	textEdits, err := formatEditsWithin(pgf, edits, chain.ifs[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: textEdits}, nil
+}
@@ -0,0 +1,576 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the refactor.inline.variable code action,
+// the inverse of refactor.extract.variable.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/astutil/edge"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// inlineVariableInfo describes a local variable that may be inlined.
+type inlineVariableInfo struct {
+	v        *types.Var
+	decl     cursor.Cursor   // the declaring statement (AssignStmt or DeclStmt)
+	typeExpr ast.Expr        // explicit type of a var declaration, or nil
+	rhs      ast.Expr        // the initializer expression
+	uses     []cursor.Cursor // references to v, in source order
+}
+
+// canInlineVariable reports whether the selection indicates a local
+// variable (either at its declaration or at a reference) that may be
+// safely replaced by its initializer at each of its uses.
+//
+// The variable must be declared by a statement "v := expr" or
+// "var v [T] = expr" within a block, must never be updated or have
+// its address taken, and the free variables of expr must have the same
+// values and meaning at each use of v as at its declaration.
+// If expr may have side effects, or evaluating it repeatedly may
+// yield distinct values (e.g. a pointer to a new composite literal),
+// v must have exactly one use, in the statement immediately
+// following the declaration, before any other call in that statement.
+func canInlineVariable(info *types.Info, curFile cursor.Cursor, start, end token.Pos) (*inlineVariableInfo, error) {
+	curId, ok := curFile.FindPos(start, end)
+	if !ok {
+		return nil, fmt.Errorf("no identifier selected")
+	}
+	id, ok := curId.Node().(*ast.Ident)
+	if !ok {
+		return nil, fmt.Errorf("no identifier selected")
+	}
+	v, ok := info.ObjectOf(id).(*types.Var)
+	if !ok || v.IsField() || typesinternal.IsPackageLevel(v) {
+		return nil, fmt.Errorf("%s is not a local variable", id.Name)
+	}
+
+	// Find the declaring statement.
+	curDef, ok := curFile.FindPos(v.Pos(), v.Pos()+token.Pos(len(v.Name())))
+	if !ok || curDef.Node() == nil || info.Defs[curDef.Node().(*ast.Ident)] != v {
+		return nil, fmt.Errorf("can't find declaration of %s", v.Name())
+	}
+	res := &inlineVariableInfo{v: v}
+	switch ek, _ := curDef.Edge(); ek {
+	case edge.AssignStmt_Lhs:
+		assign := curDef.Parent().Node().(*ast.AssignStmt)
+		if assign.Tok != token.DEFINE || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return nil, fmt.Errorf("%s is not declared by a single assignment", v.Name())
+		}
+		res.decl = curDef.Parent()
+		res.rhs = assign.Rhs[0]
+
+	case edge.ValueSpec_Names:
+		spec := curDef.Parent().Node().(*ast.ValueSpec)
+		curGenDecl := curDef.Parent().Parent()
+		if len(spec.Names) != 1 || len(spec.Values) != 1 || len(curGenDecl.Node().(*ast.GenDecl).Specs) != 1 {
+			return nil, fmt.Errorf("%s is not declared by a single assignment", v.Name())
+		}
+		res.decl = curGenDecl.Parent() // DeclStmt
+		res.typeExpr = spec.Type
+		res.rhs = spec.Values[0]
+
+	default:
+		return nil, fmt.Errorf("%s is not declared by an assignment", v.Name())
+	}
+	if ek, _ := res.decl.Edge(); ek != edge.BlockStmt_List && ek != edge.CaseClause_Body && ek != edge.CommClause_Body {
+		return nil, fmt.Errorf("%s is not declared by a statement in a block", v.Name())
+	}
+	if tv, ok := info.Types[res.rhs]; ok && tv.IsNil() {
+		return nil, fmt.Errorf("cannot inline untyped nil")
+	}
+
+	// The enclosing function body is the extent of v's scope.
+	curBody := res.decl
+	for c := range res.decl.Ancestors((*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)) {
+		curBody = c
+		break
+	}
+
+	// Find the uses of v, which must not update it.
+	for curUse := range curBody.Preorder((*ast.Ident)(nil)) {
+		if info.Uses[curUse.Node().(*ast.Ident)] == v {
+			if mayUpdate(info, curUse) {
+				return nil, fmt.Errorf("%s is updated or its address is taken", v.Name())
+			}
+			res.uses = append(res.uses, curUse)
+		}
+	}
+	if len(res.uses) == 0 {
+		return nil, fmt.Errorf("%s is never used", v.Name())
+	}
+
+	// Compute the extent of the program from the declaration to the
+	// last use of v, including any loop or function literal enclosing
+	// a use but not the declaration, as these may be executed repeatedly.
+	extentEnd := res.uses[len(res.uses)-1].Node().End()
+	for _, curUse := range res.uses {
+		for c := range curUse.Ancestors((*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.FuncLit)(nil)) {
+			if c.Node().Pos() <= res.decl.Node().Pos() {
+				break // encloses the declaration too
+			}
+			extentEnd = max(extentEnd, c.Node().End())
+		}
+	}
+
+	// Check that each free identifier of the initializer refers to
+	// the same object at each use, and that the values of the free
+	// local variables don't change between the declaration and each use.
+	curRhs, _ := curFile.FindNode(res.rhs)
+	for curRef := range curRhs.Preorder((*ast.Ident)(nil)) {
+		ref := curRef.Node().(*ast.Ident)
+		obj := info.Uses[ref]
+		if obj == nil {
+			continue // e.g. field name of composite literal
+		}
+		if ek, _ := curRef.Edge(); ek == edge.SelectorExpr_Sel {
+			continue // qualified name or field/method selection
+		}
+		for _, curUse := range res.uses {
+			use := curUse.Node()
+			scope := info.Scopes[curFile.Node().(*ast.File)].Innermost(use.Pos())
+			if _, found := scope.LookupParent(ref.Name, use.Pos()); found != obj {
+				return nil, fmt.Errorf("%s is shadowed at a use of %s", ref.Name, v.Name())
+			}
+		}
+		if addressTaken(info, curRef) {
+			continue // the value of &w does not depend on the value of w
+		}
+		if w, ok := obj.(*types.Var); ok && !typesinternal.IsPackageLevel(w) {
+			for curRef2 := range curBody.Preorder((*ast.Ident)(nil)) {
+				ref2 := curRef2.Node().(*ast.Ident)
+				if info.Uses[ref2] != w && info.Defs[ref2] != w {
+					continue
+				}
+				if addressTaken(info, curRef2) {
+					return nil, fmt.Errorf("the address of %s is taken", w.Name())
+				}
+				if !mayUpdate(info, curRef2) {
+					continue
+				}
+				if ref2.Pos() > res.decl.Node().End() && ref2.Pos() < extentEnd {
+					return nil, fmt.Errorf("%s may change before a use of %s", w.Name(), v.Name())
+				}
+				// A function literal that updates w may be called
+				// between the declaration and a use, wherever it is.
+				for c := range curRef2.Ancestors((*ast.FuncLit)(nil)) {
+					if c == curBody {
+						break
+					}
+					if lit := c.Node(); w.Pos() < lit.Pos() || w.Pos() >= lit.End() {
+						return nil, fmt.Errorf("%s may be changed by a function literal", w.Name())
+					}
+				}
+			}
+		}
+	}
+
+	// If the initializer is not free of effects, or evaluation of
+	// the duplicated expression would not yield an equivalent value,
+	// there must be a single use of v, immediately after the declaration.
+	if !isDuplicable(info, res.rhs) {
+		if len(res.uses) > 1 {
+			return nil, fmt.Errorf("initializer of %s may have side effects", v.Name())
+		}
+		next, ok := res.decl.NextSibling()
+		use := res.uses[0]
+		if !ok || use.Node().Pos() < next.Node().Pos() || use.Node().End() > next.Node().End() {
+			return nil, fmt.Errorf("initializer of %s may have side effects, and its use does not immediately follow", v.Name())
+		}
+		// The use must be evaluated exactly once, unconditionally.
+		for c := use; c != next; c = c.Parent() {
+			switch n := c.Parent().Node().(type) {
+			case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause, *ast.FuncLit,
+				*ast.ForStmt, *ast.RangeStmt, *ast.SelectStmt:
+				return nil, fmt.Errorf("initializer of %s may have side effects, and its use is not evaluated exactly once", v.Name())
+			case *ast.BinaryExpr:
+				if (n.Op == token.LAND || n.Op == token.LOR) && n.Y == c.Node() {
+					return nil, fmt.Errorf("initializer of %s may have side effects, and its use is conditional", v.Name())
+				}
+			}
+		}
+		// No other effects may precede the use.
+		for c := range next.Preorder((*ast.CallExpr)(nil), (*ast.UnaryExpr)(nil)) {
+			n := c.Node()
+			if n.Pos() >= use.Node().Pos() {
+				break
+			}
+			if n.End() >= use.Node().End() {
+				continue // encloses the use, so is evaluated after it
+			}
+			if !isDuplicable(info, n.(ast.Expr)) {
+				return nil, fmt.Errorf("inlining %s would change the order of evaluation", v.Name())
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// mayUpdate reports whether the reference to a variable at cur may
+// update it: by assignment, increment, or by taking its address.
+// Updates to a field or element of a struct or array variable
+// count as updates of the variable.
+func mayUpdate(info *types.Info, cur cursor.Cursor) bool {
+	if addressTaken(info, cur) {
+		return true
+	}
+	cur = enclosingLvalue(info, cur)
+	switch ek, _ := cur.Edge(); ek {
+	case edge.AssignStmt_Lhs, edge.IncDecStmt_X:
+		return true
+	case edge.RangeStmt_Key, edge.RangeStmt_Value:
+		return cur.Parent().Node().(*ast.RangeStmt).Tok == token.ASSIGN
+	}
+	return false
+}
+
+// addressTaken reports whether the reference to a variable at cur
+// (or to one of its fields or elements) has its address taken,
+// explicitly by &v or implicitly by a call to a pointer method.
+func addressTaken(info *types.Info, cur cursor.Cursor) bool {
+	cur = enclosingLvalue(info, cur)
+	switch ek, _ := cur.Edge(); ek {
+	case edge.UnaryExpr_X:
+		return cur.Parent().Node().(*ast.UnaryExpr).Op == token.AND
+	case edge.SelectorExpr_X:
+		if sel, ok := info.Selections[cur.Parent().Node().(*ast.SelectorExpr)]; ok && sel.Kind() == types.MethodVal {
+			_, isPtrRecv := sel.Obj().Type().(*types.Signature).Recv().Type().(*types.Pointer)
+			_, isPtr := sel.Recv().Underlying().(*types.Pointer)
+			return isPtrRecv && !isPtr
+		}
+	}
+	return false
+}
+
+// enclosingLvalue returns the outermost expression enclosing cur
+// whose value is part of the variable referenced at cur: that is,
+// selections of fields of struct values and elements of arrays.
+func enclosingLvalue(info *types.Info, cur cursor.Cursor) cursor.Cursor {
+	for {
+		switch ek, _ := cur.Edge(); ek {
+		case edge.ParenExpr_X:
+		case edge.SelectorExpr_X:
+			sel, ok := info.Selections[cur.Parent().Node().(*ast.SelectorExpr)]
+			if !ok || sel.Kind() != types.FieldVal || sel.Indirect() || is[*types.Pointer](sel.Recv().Underlying()) {
+				return cur
+			}
+		case edge.IndexExpr_X:
+			if !is[*types.Array](info.TypeOf(cur.Node().(ast.Expr)).Underlying()) {
+				return cur
+			}
+		default:
+			return cur
+		}
+		cur = cur.Parent()
+	}
+}
+
+// isDuplicable reports whether the expression e is free of side
+// effects, reads no memory that may be updated through aliases, and
+// yields equivalent values if evaluated more than once: it may safely
+// be evaluated at a later point, or more than once.
+func isDuplicable(info *types.Info, e ast.Expr) bool {
+	if tv, ok := info.Types[e]; ok && tv.Value != nil {
+		return true // constant
+	}
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return true
+
+	case *ast.Ident:
+		// Package-level variables may be updated by any call.
+		v, ok := info.Uses[e].(*types.Var)
+		return !ok || !typesinternal.IsPackageLevel(v)
+
+	case *ast.ParenExpr:
+		return isDuplicable(info, e.X)
+
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			// Field selections through pointers read shared memory.
+			if sel.Kind() != types.FieldVal || sel.Indirect() || is[*types.Pointer](sel.Recv().Underlying()) {
+				return false
+			}
+			return isDuplicable(info, e.X)
+		}
+		return isDuplicable(info, e.Sel) // qualified identifier
+
+	case *ast.IndexExpr:
+		if tv, ok := info.Types[e.X]; ok && !tv.IsValue() {
+			return true // generic instantiation
+		}
+		switch info.TypeOf(e.X).Underlying().(type) {
+		case *types.Array:
+			return isDuplicable(info, e.X) && isDuplicable(info, e.Index)
+		case *types.Basic: // string
+			return isDuplicable(info, e.X) && isDuplicable(info, e.Index)
+		}
+		return false // slices and maps are shared
+
+	case *ast.UnaryExpr:
+		switch e.Op {
+		case token.ARROW:
+			return false
+		case token.AND:
+			// Each evaluation of &T{} yields a distinct variable.
+			return !is[*ast.CompositeLit](ast.Unparen(e.X)) && isDuplicable(info, e.X)
+		}
+		return isDuplicable(info, e.X)
+
+	case *ast.BinaryExpr:
+		return isDuplicable(info, e.X) && isDuplicable(info, e.Y)
+
+	case *ast.StarExpr:
+		return false // reads shared memory
+
+	case *ast.CallExpr:
+		if tv, ok := info.Types[e.Fun]; ok && tv.IsType() {
+			// Conversions are pure, but converting to a reference
+			// type (e.g. []byte(s)) allocates a distinct value.
+			switch tv.Type.Underlying().(type) {
+			case *types.Slice, *types.Map, *types.Chan, *types.Pointer, *types.Signature:
+				return false
+			}
+			return len(e.Args) == 1 && isDuplicable(info, e.Args[0])
+		}
+		if id, ok := ast.Unparen(e.Fun).(*ast.Ident); ok {
+			if b, ok := info.Uses[id].(*types.Builtin); ok {
+				switch b.Name() {
+				case "len", "cap", "min", "max", "real", "imag", "complex":
+					for _, arg := range e.Args {
+						if !isDuplicable(info, arg) {
+							return false
+						}
+					}
+					return true
+				}
+			}
+		}
+		return false
+
+	case *ast.CompositeLit:
+		// Composite literals of reference types yield distinct values.
+		switch info.TypeOf(e).Underlying().(type) {
+		case *types.Struct, *types.Array:
+			for _, elt := range e.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if _, isStruct := info.TypeOf(e).Underlying().(*types.Struct); !isStruct && !isDuplicable(info, kv.Key) {
+						return false
+					}
+					elt = kv.Value
+				}
+				if !isDuplicable(info, elt) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+
+	case *ast.TypeAssertExpr:
+		return isDuplicable(info, e.X)
+	}
+	return false
+}
+
+// inlineVariable is a singleFileFixer that replaces each use of the
+// indicated local variable by its initializer, and deletes its declaration.
+func inlineVariable(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	info := pkg.TypesInfo()
+	res, err := canInlineVariable(info, pgf.Cursor, start, end)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot inline variable: %v", err)
+	}
+
+	rhsStart, rhsEnd, err := safetoken.Offsets(pgf.Tok, res.rhs.Pos(), res.rhs.End())
+	if err != nil {
+		return nil, nil, err
+	}
+	rhsText := string(pgf.Src[rhsStart:rhsEnd])
+
+	// Compute the type of the initializer in isolation, as the
+	// declaration may have converted it to the variable's type.
+	var rhsType types.Type
+	{
+		tinfo := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+		if err := types.CheckExpr(pkg.FileSet(), pkg.Types(), res.rhs.Pos(), res.rhs, tinfo); err != nil {
+			return nil, nil, fmt.Errorf("cannot inline variable: %v", err)
+		}
+		rhsType = tinfo.Types[res.rhs].Type
+	}
+
+	// An explicit conversion is needed if the variable's type differs
+	// from that of its initializer (e.g. "var r io.Reader = f"), or
+	// that of an untyped constant initializer (e.g. "var x int64 = 1").
+	// Untyped constants of the default type need a conversion only when
+	// used as operands, where the constant expression could otherwise
+	// be interpreted differently (e.g. x / 2.0).
+	var convert, convertOperand string
+	{
+		typeStr := types.TypeString(res.v.Type(), typesinternal.FileQualifier(pgf.File, pkg.Types()))
+		if res.typeExpr != nil {
+			start, end, err := safetoken.Offsets(pgf.Tok, res.typeExpr.Pos(), res.typeExpr.End())
+			if err != nil {
+				return nil, nil, err
+			}
+			typeStr = string(pgf.Src[start:end])
+		}
+		switch types.Unalias(res.v.Type()).(type) {
+		case *types.Pointer, *types.Signature, *types.Chan:
+			typeStr = "(" + typeStr + ")"
+		}
+		switch {
+		case isUntyped(rhsType):
+			if !types.Identical(types.Default(rhsType), res.v.Type()) {
+				convert = typeStr
+			} else {
+				convertOperand = typeStr
+			}
+		case !types.Identical(rhsType, res.v.Type()):
+			convert = typeStr
+		}
+	}
+
+	var edits []analysis.TextEdit
+	for _, curUse := range res.uses {
+		use := curUse.Node()
+		text := rhsText
+		parent := curUse.Parent().Node()
+		switch {
+		case convert != "":
+			text = convert + "(" + text + ")"
+		case convertOperand != "" && isConstantOperand(info, parent, use):
+			text = convertOperand + "(" + text + ")"
+		case needsParens(parent, use, res.rhs),
+			is[*ast.CompositeLit](res.rhs) && inControlClause(curUse):
+			text = "(" + text + ")"
+		}
+		edits = append(edits, analysis.TextEdit{
+			Pos:     use.Pos(),
+			End:     use.End(),
+			NewText: []byte(text),
+		})
+	}
+
+	// Delete the declaration, along with its line if it is alone on it.
+	decl := res.decl.Node()
+	delStart, delEnd := decl.Pos(), decl.End()
+	{
+		startOffset, endOffset, err := safetoken.Offsets(pgf.Tok, delStart, delEnd)
+		if err != nil {
+			return nil, nil, err
+		}
+		lineStart := startOffset
+		for lineStart > 0 && (pgf.Src[lineStart-1] == ' ' || pgf.Src[lineStart-1] == '\t') {
+			lineStart--
+		}
+		lineEnd := endOffset
+		for lineEnd < len(pgf.Src) && (pgf.Src[lineEnd] == ' ' || pgf.Src[lineEnd] == '\t') {
+			lineEnd++
+		}
+		alone := (lineStart == 0 || pgf.Src[lineStart-1] == '\n') &&
+			(lineEnd == len(pgf.Src) || pgf.Src[lineEnd] == '\n')
+		for _, cg := range pgf.File.Comments {
+			if cg.Pos() < pgf.Tok.Pos(lineEnd) && cg.End() > pgf.Tok.Pos(lineStart) {
+				alone = false // preserve comments
+			}
+		}
+		if alone {
+			delStart = pgf.Tok.Pos(lineStart)
+			delEnd = pgf.Tok.Pos(min(lineEnd+1, len(pgf.Src)))
+		} else {
+			delEnd = pgf.Tok.Pos(lineEnd) // trailing space
+		}
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos: delStart,
+		End: delEnd,
+	})
+
+	return pkg.FileSet(), &analysis.SuggestedFix{
+		TextEdits: edits,
+	}, nil
+}
+
+// inControlClause reports whether the expression at cur appears in the
+// header of an if, for, or switch statement, where a composite literal
+// must be parenthesized to avoid ambiguity with the statement's body.
+func inControlClause(cur cursor.Cursor) bool {
+	for ; cur.Node() != nil; cur = cur.Parent() {
+		switch ek, _ := cur.Edge(); ek {
+		case edge.IfStmt_Init, edge.IfStmt_Cond,
+			edge.ForStmt_Init, edge.ForStmt_Cond, edge.ForStmt_Post,
+			edge.RangeStmt_X,
+			edge.SwitchStmt_Init, edge.SwitchStmt_Tag,
+			edge.TypeSwitchStmt_Init, edge.TypeSwitchStmt_Assign:
+			return true
+		case edge.BlockStmt_List, edge.FuncLit_Body, edge.CallExpr_Args, edge.CompositeLit_Elts:
+			return false // parenthesized or braced context
+		}
+	}
+	return false
+}
+
+// isConstantOperand reports whether the expression old is an operand
+// of the parent expression whose other operands are all constant,
+// so that substituting an untyped constant for old would make the
+// parent a constant expression.
+func isConstantOperand(info *types.Info, parent, old ast.Node) bool {
+	switch parent := parent.(type) {
+	case *ast.UnaryExpr:
+		return true
+	case *ast.BinaryExpr:
+		other := cond(parent.X == old, parent.Y, parent.X)
+		return info.Types[other].Value != nil
+	}
+	return false
+}
+
+// isUntyped reports whether t is an untyped basic type.
+func isUntyped(t types.Type) bool {
+	b, ok := t.(*types.Basic)
+	return ok && b.Info()&types.IsUntyped != 0
+}
+
+// needsParens reports whether the expression new, when it replaces
+// the operand old of the parent node, must be parenthesized to
+// preserve the structure of the parent expression.
+func needsParens(parent, old ast.Node, new ast.Expr) bool {
+	precedence := func(e ast.Expr) int {
+		switch e := e.(type) {
+		case *ast.BinaryExpr:
+			return e.Op.Precedence()
+		case *ast.UnaryExpr, *ast.StarExpr:
+			return token.UnaryPrec
+		}
+		return token.HighestPrec
+	}
+	prec := precedence(new)
+	switch parent := parent.(type) {
+	case *ast.BinaryExpr:
+		if prec < parent.Op.Precedence() {
+			return true
+		}
+		return prec == parent.Op.Precedence() && old == parent.Y
+	case *ast.UnaryExpr, *ast.StarExpr:
+		return prec < token.UnaryPrec
+	case *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr, *ast.SliceExpr, *ast.TypeAssertExpr:
+		return prec < token.HighestPrec
+	case *ast.CallExpr:
+		return parent.Fun == old && prec < token.HighestPrec
+	}
+	return false
+}
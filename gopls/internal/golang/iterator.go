@@ -0,0 +1,469 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines code actions that help migrate callback-style
+// iteration APIs, such as func Walk(f func(T) bool), to Go 1.23
+// iterators: "Add iterator function" generates a wrapper that returns
+// an iter.Seq, and "Convert to range-over-func loop" rewrites a call
+// that passes a function literal into a for/range loop.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/typesinternal"
+	"golang.org/x/tools/internal/versions"
+)
+
+// seqSuffix is appended to the name of a callback-style function to
+// form the name of its iterator function.
+const seqSuffix = "Seq"
+
+// yieldSignature returns the signature of the callback parameter of
+// the callback-style iteration function fn, which must have no
+// results, and whose last parameter must be a non-variadic func(T)
+// bool or func(K, V) bool. It returns nil if fn is not of this form.
+func yieldSignature(fn *types.Func) *types.Signature {
+	sig := fn.Signature()
+	if sig.TypeParams().Len() > 0 || sig.RecvTypeParams().Len() > 0 ||
+		sig.Results().Len() > 0 || sig.Variadic() || sig.Params().Len() == 0 {
+		return nil
+	}
+	yield, ok := sig.Params().At(sig.Params().Len() - 1).Type().Underlying().(*types.Signature)
+	if !ok || yield.Variadic() || yield.Params().Len() < 1 || yield.Params().Len() > 2 ||
+		yield.Results().Len() != 1 || !types.Identical(yield.Results().At(0).Type(), types.Typ[types.Bool]) {
+		return nil
+	}
+	return yield
+}
+
+// iteratorFuncAt returns the declaration of the callback-style
+// iteration function whose name or signature encloses [start, end),
+// if an iterator function may be added for it.
+func iteratorFuncAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.FuncDecl, *types.Func) {
+	if !versions.AtLeast(versions.FileVersion(pkg.TypesInfo(), pgf.File), "go1.23") {
+		return nil, nil // no range-over-func
+	}
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, nil
+	}
+	decl, ok := curSel.Node().(*ast.FuncDecl)
+	if !ok {
+		for cur := range curSel.Ancestors((*ast.FuncDecl)(nil)) {
+			decl, ok = cur.Node().(*ast.FuncDecl)
+			break
+		}
+	}
+	if !ok || !posRangeContains(decl.Pos(), decl.Type.End(), start, end) {
+		return nil, nil
+	}
+	fn, ok := pkg.TypesInfo().Defs[decl.Name].(*types.Func)
+	if !ok || yieldSignature(fn) == nil {
+		return nil, nil
+	}
+	// The callback must be declared by its own field,
+	// which the iterator function omits.
+	params := decl.Type.Params.List
+	if len(params[len(params)-1].Names) > 1 {
+		return nil, nil
+	}
+	// The name of the iterator function must be available.
+	name := fn.Name() + seqSuffix
+	if recv := fn.Signature().Recv(); recv != nil {
+		if obj, _, _ := types.LookupFieldOrMethod(recv.Type(), true, fn.Pkg(), name); obj != nil {
+			return nil, nil
+		}
+	} else if fn.Pkg().Scope().Lookup(name) != nil {
+		return nil, nil
+	}
+	return decl, fn
+}
+
+// addIteratorFunc is a singleFileFixer that adds an iterator function
+// FSeq after the selected callback-style iteration function F:
+//
+//	func FSeq(x X) iter.Seq[T] {
+//		return func(yield func(T) bool) {
+//			F(x, yield)
+//		}
+//	}
+func addIteratorFunc(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	decl, fn := iteratorFuncAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, nil, fmt.Errorf("no callback-style iteration function selected")
+	}
+	yield := yieldSignature(fn)
+
+	text := func(n ast.Node) (string, error) {
+		start, end, err := safetoken.Offsets(pgf.Tok, n.Pos(), n.End())
+		if err != nil {
+			return "", err
+		}
+		return string(pgf.Src[start:end]), nil
+	}
+
+	// Choose names for unnamed or blank parameters, which must be
+	// passed along to fn.
+	var (
+		params  []string // parameter declarations of the iterator func
+		args    []string // arguments of the call to fn
+		fields  = decl.Type.Params.List
+		counter = 0
+	)
+	freshName := func() string {
+		for {
+			name := fmt.Sprintf("arg%d", counter)
+			counter++
+			if _, obj := pkg.TypesInfo().Scopes[decl.Type].LookupParent(name, token.NoPos); obj == nil {
+				return name
+			}
+		}
+	}
+	for _, field := range fields[:len(fields)-1] {
+		typ, err := text(field.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		var names []string
+		if len(field.Names) == 0 {
+			names = append(names, freshName())
+		}
+		for _, id := range field.Names {
+			name := id.Name
+			if name == "_" {
+				name = freshName()
+			}
+			names = append(names, name)
+		}
+		params = append(params, strings.Join(names, ", ")+" "+typ)
+		args = append(args, names...)
+	}
+
+	// Qualify the element types, and iter.Seq, for this file.
+	qual := typesinternal.FileQualifier(pgf.File, pkg.Types())
+	seq := "Seq"
+	if yield.Params().Len() == 2 {
+		seq = "Seq2"
+	}
+	_, prefix, importEdits := analysisinternal.AddImport(pkg.TypesInfo(), pgf.File, "iter", "iter", seq, decl.Pos())
+	var elems []string
+	for v := range yield.Params().Variables() {
+		elems = append(elems, types.TypeString(v.Type(), qual))
+	}
+	yieldType, err := text(fields[len(fields)-1].Type)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf strings.Builder
+	name := fn.Name() + seqSuffix
+	callee := fn.Name()
+	fmt.Fprintf(&buf, "\n\n// %s returns an iterator over the values that %s passes to its callback.\nfunc ", name, fn.Name())
+	if decl.Recv != nil {
+		recvField := decl.Recv.List[0]
+		recvType, err := text(recvField.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		recvName := "recv"
+		if len(recvField.Names) > 0 && recvField.Names[0].Name != "_" {
+			recvName = recvField.Names[0].Name
+		}
+		fmt.Fprintf(&buf, "(%s %s) ", recvName, recvType)
+		callee = recvName + "." + callee
+	}
+	fmt.Fprintf(&buf, "%s(%s) %s%s[%s] {\n", name, strings.Join(params, ", "), prefix, seq, strings.Join(elems, ", "))
+	fmt.Fprintf(&buf, "\treturn func(yield %s) {\n", yieldType)
+	fmt.Fprintf(&buf, "\t\t%s(%s)\n", callee, strings.Join(append(args, "yield"), ", "))
+	fmt.Fprintf(&buf, "\t}\n}")
+
+	return pkg.FileSet(), &analysis.SuggestedFix{
+		TextEdits: append(importEdits, analysis.TextEdit{
+			Pos:     decl.End(),
+			End:     decl.End(),
+			NewText: []byte(buf.String()),
+		}),
+	}, nil
+}
+
+// A rangeOverFuncCall describes a call statement F(x, func(T) bool {
+// ... }) that may be converted to a range-over-func loop.
+type rangeOverFuncCall struct {
+	stmt *ast.ExprStmt
+	call *ast.CallExpr
+	lit  *ast.FuncLit // the callback
+	fn   *types.Func  // the callee F
+	seq  *types.Func  // the iterator FSeq, or nil to range over F itself
+}
+
+// rangeOverFuncCallAt returns the call statement enclosing [start,
+// end) if it may be converted to a range-over-func loop.
+func rangeOverFuncCallAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*rangeOverFuncCall, error) {
+	info := pkg.TypesInfo()
+	if !versions.AtLeast(versions.FileVersion(info, pgf.File), "go1.23") {
+		return nil, fmt.Errorf("range-over-func requires go1.23")
+	}
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, fmt.Errorf("no enclosing call")
+	}
+	stmt, ok := curSel.Node().(*ast.ExprStmt)
+	if !ok {
+		for cur := range curSel.Ancestors((*ast.ExprStmt)(nil)) {
+			stmt, ok = cur.Node().(*ast.ExprStmt)
+			break
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("no enclosing call statement")
+	}
+	call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+	if !ok || call.Ellipsis.IsValid() || len(call.Args) == 0 {
+		return nil, fmt.Errorf("not a call statement")
+	}
+	// The selection must not be within the callback.
+	lit, ok := call.Args[len(call.Args)-1].(*ast.FuncLit)
+	if !ok || posRangeContains(lit.Body.Lbrace+1, lit.Body.Rbrace, start, end) {
+		return nil, fmt.Errorf("last argument is not a function literal")
+	}
+	fn := typeutil.StaticCallee(info, call)
+	if fn == nil || yieldSignature(fn) == nil {
+		return nil, fmt.Errorf("not a call to a callback-style iteration function")
+	}
+	if err := checkRangeOverFuncBody(info, lit); err != nil {
+		return nil, err
+	}
+
+	res := &rangeOverFuncCall{stmt: stmt, call: call, lit: lit, fn: fn}
+	if len(call.Args) == 1 {
+		// Range over F itself, whose parameter must be an unnamed
+		// yield function type.
+		if _, ok := fn.Signature().Params().At(0).Type().(*types.Signature); !ok {
+			return nil, fmt.Errorf("callback parameter has a named type")
+		}
+		return res, nil
+	}
+
+	// Find an iterator function FSeq with the same leading parameters,
+	// returning an iterator over the callback's parameters.
+	var seq types.Object
+	name := fn.Name() + seqSuffix
+	if recv := fn.Signature().Recv(); recv != nil {
+		seq, _, _ = types.LookupFieldOrMethod(recv.Type(), true, pkg.Types(), name)
+	} else {
+		seq = fn.Pkg().Scope().Lookup(name)
+	}
+	seqFn, ok := seq.(*types.Func)
+	if !ok || (fn.Pkg() != pkg.Types() && !seqFn.Exported()) {
+		return nil, fmt.Errorf("no iterator function %s", name)
+	}
+	sig, seqSig := fn.Signature(), seqFn.Signature()
+	if seqSig.Params().Len() != sig.Params().Len()-1 || seqSig.Variadic() || seqSig.Results().Len() != 1 {
+		return nil, fmt.Errorf("%s has an unexpected signature", name)
+	}
+	for i := range seqSig.Params().Len() {
+		if !types.Identical(seqSig.Params().At(i).Type(), sig.Params().At(i).Type()) {
+			return nil, fmt.Errorf("%s has an unexpected signature", name)
+		}
+	}
+	iterSig, ok := seqSig.Results().At(0).Type().Underlying().(*types.Signature)
+	if !ok || iterSig.Params().Len() != 1 || iterSig.Results().Len() != 0 ||
+		!types.Identical(iterSig.Params().At(0).Type().Underlying(), yieldSignature(fn)) {
+		return nil, fmt.Errorf("%s has an unexpected signature", name)
+	}
+	res.seq = seqFn
+	return res, nil
+}
+
+// checkRangeOverFuncBody reports an error if the body of the callback
+// lit cannot become the body of a range-over-func loop: each of its
+// return statements must return a constant, and must not be within a
+// statement that would capture the resulting break or continue;
+// and it must not contain defer statements or labels, whose meaning
+// would change.
+func checkRangeOverFuncBody(info *types.Info, lit *ast.FuncLit) error {
+	var err error
+	var visit func(n ast.Node, nested bool) bool
+	visit = func(n ast.Node, nested bool) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			err = fmt.Errorf("callback contains a defer statement")
+		case *ast.LabeledStmt:
+			err = fmt.Errorf("callback contains a labeled statement")
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			if !nested {
+				ast.Inspect(n, func(n ast.Node) bool { return err == nil && visit(n, true) })
+				return false
+			}
+		case *ast.ReturnStmt:
+			if nested {
+				err = fmt.Errorf("callback returns from within a loop, switch, or select statement")
+			} else if len(n.Results) != 1 || info.Types[n.Results[0]].Value == nil {
+				err = fmt.Errorf("callback returns a non-constant value")
+			}
+		}
+		return err == nil
+	}
+	ast.Inspect(lit.Body, func(n ast.Node) bool { return err == nil && visit(n, false) })
+	return err
+}
+
+// convertToRangeOverFunc is a singleFileFixer that converts a call
+// F(x, func(v T) bool { ... }) to a loop for v := range FSeq(x) { ... },
+// or, if the callback is F's only parameter, for v := range F { ... }.
+func convertToRangeOverFunc(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	info := pkg.TypesInfo()
+	rc, err := rangeOverFuncCallAt(pkg, pgf, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := func(n ast.Node) (string, error) {
+		start, end, err := safetoken.Offsets(pgf.Tok, n.Pos(), n.End())
+		if err != nil {
+			return "", err
+		}
+		return string(pgf.Src[start:end]), nil
+	}
+
+	// Compute the range expression.
+	var rangeExpr string
+	fun, err := text(rc.call.Fun)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rc.seq == nil {
+		rangeExpr = fun
+	} else {
+		var args []string
+		for _, arg := range rc.call.Args[:len(rc.call.Args)-1] {
+			t, err := text(arg)
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, t)
+		}
+		// Replace the final identifier of F by FSeq.
+		switch rc.call.Fun.(type) {
+		case *ast.Ident, *ast.SelectorExpr:
+			fun += seqSuffix
+		default:
+			return nil, nil, fmt.Errorf("cannot find name of called function")
+		}
+		rangeExpr = fun + "(" + strings.Join(args, ", ") + ")"
+	}
+
+	// Compute the loop variables.
+	var vars []string
+	for _, field := range rc.lit.Type.Params.List {
+		if len(field.Names) == 0 {
+			vars = append(vars, "_")
+		}
+		for _, id := range field.Names {
+			vars = append(vars, id.Name)
+		}
+	}
+	for len(vars) > 0 && vars[len(vars)-1] == "_" {
+		vars = vars[:len(vars)-1]
+	}
+	header := "for range " + rangeExpr + " {"
+	if len(vars) > 0 {
+		header = "for " + strings.Join(vars, ", ") + " := range " + rangeExpr + " {"
+	}
+
+	// Replace the call (up to the callback body) by the loop header,
+	// each return statement of the callback by continue or break,
+	// and the end of the call by the end of the loop.
+	offset := func(pos token.Pos) (int, error) { return safetoken.Offset(pgf.Tok, pos) }
+	stmtStart, err := offset(rc.stmt.Pos())
+	if err != nil {
+		return nil, nil, err
+	}
+	lbrace, err := offset(rc.lit.Body.Lbrace)
+	if err != nil {
+		return nil, nil, err
+	}
+	rbrace, err := offset(rc.lit.Body.Rbrace)
+	if err != nil {
+		return nil, nil, err
+	}
+	stmtEnd, err := offset(rc.stmt.End())
+	if err != nil {
+		return nil, nil, err
+	}
+	edits := []diff.Edit{
+		{Start: stmtStart, End: lbrace + len("{"), New: header},
+		{Start: rbrace + len("}"), End: stmtEnd, New: ""},
+	}
+	list := rc.lit.Body.List
+	ast.Inspect(rc.lit.Body, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			var start, end int
+			start, end, err = safetoken.Offsets(pgf.Tok, n.Pos(), n.End())
+			if err != nil {
+				return false
+			}
+			newText := "break"
+			if info.Types[n.Results[0]].Value.ExactString() == "true" {
+				newText = "continue"
+				if n == list[len(list)-1] {
+					// Delete the redundant continue at the end of the
+					// loop body, along with its line if possible.
+					newText = ""
+					prev := rc.lit.Body.Lbrace + 1
+					if len(list) > 1 {
+						prev = list[len(list)-2].End()
+					}
+					if !hasCommentsBetween(pgf.File, prev, n.Pos()) {
+						start, err = safetoken.Offset(pgf.Tok, prev)
+						if err != nil {
+							return false
+						}
+					}
+				}
+			}
+			edits = append(edits, diff.Edit{Start: start, End: end, New: newText})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	textEdits, err := formatEditsWithin(pgf, edits, rc.stmt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: textEdits}, nil
+}
+
+// hasCommentsBetween reports whether file has a comment within [start, end).
+func hasCommentsBetween(file *ast.File, start, end token.Pos) bool {
+	for _, cg := range file.Comments {
+		if start <= cg.Pos() && cg.End() <= end {
+			return true
+		}
+	}
+	return false
+}
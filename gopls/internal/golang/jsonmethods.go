@@ -0,0 +1,252 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Generate MarshalJSON and
+// UnmarshalJSON methods for T".
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+// structDeclAt returns the package-level declaration of the
+// non-generic struct type whose declaration encloses [start, end).
+func structDeclAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.GenDecl, *ast.TypeSpec, *types.Named) {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, nil, nil
+	}
+	curSpec := curSel
+	if !is[*ast.TypeSpec](curSpec.Node()) {
+		for cur := range curSel.Ancestors((*ast.TypeSpec)(nil)) {
+			curSpec = cur
+			break
+		}
+	}
+	spec, ok := curSpec.Node().(*ast.TypeSpec)
+	if !ok || spec.TypeParams != nil || spec.Assign.IsValid() {
+		return nil, nil, nil
+	}
+	decl, ok := curSpec.Parent().Node().(*ast.GenDecl)
+	if !ok || !is[*ast.File](curSpec.Parent().Parent().Node()) {
+		return nil, nil, nil
+	}
+	if _, ok := spec.Type.(*ast.StructType); !ok {
+		return nil, nil, nil
+	}
+	obj, ok := pkg.TypesInfo().Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return nil, nil, nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, nil, nil
+	}
+	return decl, spec, named
+}
+
+// hasMethod reports whether the method set of *T includes the named method.
+func hasMethod(pkg *types.Package, named *types.Named, name string) bool {
+	m, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), false, pkg, name)
+	return m != nil
+}
+
+// jsonStructAt returns the declaration of the struct type enclosing
+// [start, end), as for [structDeclAt], provided it has no MarshalJSON
+// or UnmarshalJSON method and has fields to encode.
+func jsonStructAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.GenDecl, *ast.TypeSpec, *types.Named) {
+	decl, spec, named := structDeclAt(pkg, pgf, start, end)
+	if named == nil ||
+		hasMethod(pkg.Types(), named, "MarshalJSON") ||
+		hasMethod(pkg.Types(), named, "UnmarshalJSON") ||
+		len(jsonFields(pkg.TypesInfo(), spec.Type.(*ast.StructType))) == 0 {
+		return nil, nil, nil
+	}
+	return decl, spec, named
+}
+
+// A jsonField is a field of a struct type that is encoded by
+// encoding/json.
+type jsonField struct {
+	name     string
+	field    *ast.Field
+	embedded bool
+	time     bool // field has type time.Time
+}
+
+// jsonFields returns the fields of st that are encoded by
+// encoding/json: the exported fields and the embedded ones, except
+// those whose tag is "-".
+func jsonFields(info *types.Info, st *ast.StructType) []jsonField {
+	var fields []jsonField
+	for _, field := range st.Fields.List {
+		if field.Tag != nil {
+			if tag, err := strconv.Unquote(field.Tag.Value); err == nil && reflect.StructTag(tag).Get("json") == "-" {
+				continue
+			}
+		}
+		isTime := isTimeType(info.TypeOf(field.Type))
+		if len(field.Names) == 0 {
+			if id := embeddedIdent(field.Type); id != nil {
+				fields = append(fields, jsonField{name: id.Name, field: field, embedded: true})
+			}
+			continue
+		}
+		for _, id := range field.Names {
+			if id.IsExported() {
+				fields = append(fields, jsonField{name: id.Name, field: field, time: isTime})
+			}
+		}
+	}
+	return fields
+}
+
+// addJSONMethods is a singleFileFixer that adds MarshalJSON and
+// UnmarshalJSON methods for the selected struct type T, which encode
+// T using an auxiliary struct type with the same fields, except that
+// fields of type time.Time are formatted as strings.
+func addJSONMethods(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	decl, spec, named := jsonStructAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, nil, fmt.Errorf("no struct type selected")
+	}
+	info := pkg.TypesInfo()
+	fields := jsonFields(info, spec.Type.(*ast.StructType))
+
+	text := func(n ast.Node) (string, error) {
+		start, end, err := pgf.NodeOffsets(n)
+		if err != nil {
+			return "", err
+		}
+		return string(pgf.Src[start:end]), nil
+	}
+
+	// Choose names that do not conflict with those of the package.
+	name := named.Obj().Name()
+	scope := pkg.Types().Scope()
+	aux := lowerFirst(name) + "JSON"
+	for i := 0; scope.Lookup(aux) != nil; i++ {
+		aux = fmt.Sprintf("%sJSON%d", lowerFirst(name), i)
+	}
+	recv := receiverName(named)
+	if recv == "" || recv == "_" || recv == "data" || recv == "v" || recv == "err" {
+		recv = strings.ToLower(name[:1])
+		if recv == "v" {
+			recv = "x"
+		}
+	}
+
+	var edits []analysis.TextEdit
+	jsonName, jsonPrefix, importEdits := analysisinternal.AddImport(info, pgf.File, "json", "encoding/json", "Marshal", decl.Pos())
+	edits = append(edits, importEdits...)
+	timeName, timePrefix := "", ""
+	for _, f := range fields {
+		if f.time {
+			var importEdits []analysis.TextEdit
+			timeName, timePrefix, importEdits = analysisinternal.AddImport(info, pgf.File, "time", "time", "RFC3339", decl.Pos())
+			edits = append(edits, importEdits...)
+			break
+		}
+	}
+	for _, n := range []string{"data", "v", "err", recv} {
+		if n == jsonName || n == timeName {
+			return nil, nil, fmt.Errorf("name %s conflicts with an imported package", n)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// %s is the JSON representation of %s.\n", aux, name)
+	fmt.Fprintf(&buf, "type %s struct {\n", aux)
+	for _, f := range fields {
+		typ, err := text(f.field.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		if f.time {
+			typ = "string"
+		}
+		if !f.embedded {
+			fmt.Fprintf(&buf, "%s ", f.name)
+		}
+		buf.WriteString(typ)
+		if f.field.Tag != nil {
+			fmt.Fprintf(&buf, " %s", f.field.Tag.Value)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&buf, "// MarshalJSON implements [%sMarshaler].\n", jsonPrefix)
+	fmt.Fprintf(&buf, "func (%s %s) MarshalJSON() ([]byte, error) {\n", recv, name)
+	fmt.Fprintf(&buf, "return %sMarshal(%s{\n", jsonPrefix, aux)
+	for _, f := range fields {
+		if f.time {
+			fmt.Fprintf(&buf, "%s: %s.%s.Format(%sRFC3339),\n", f.name, recv, f.name, timePrefix)
+		} else {
+			fmt.Fprintf(&buf, "%s: %s.%s,\n", f.name, recv, f.name)
+		}
+	}
+	buf.WriteString("})\n}\n\n")
+
+	fmt.Fprintf(&buf, "// UnmarshalJSON implements [%sUnmarshaler].\n", jsonPrefix)
+	fmt.Fprintf(&buf, "func (%s *%s) UnmarshalJSON(data []byte) error {\n", recv, name)
+	fmt.Fprintf(&buf, "var v %s\n", aux)
+	fmt.Fprintf(&buf, "err := %sUnmarshal(data, &v)\n", jsonPrefix)
+	buf.WriteString("if err != nil {\nreturn err\n}\n")
+	for _, f := range fields {
+		if f.time {
+			// An absent time is left unchanged.
+			fmt.Fprintf(&buf, "if v.%s != \"\" {\n", f.name)
+			fmt.Fprintf(&buf, "if %s.%s, err = %sParse(%sRFC3339, v.%s); err != nil {\nreturn err\n}\n}\n",
+				recv, f.name, timePrefix, timePrefix, f.name)
+		} else {
+			fmt.Fprintf(&buf, "%s.%s = v.%s\n", recv, f.name, f.name)
+		}
+	}
+	buf.WriteString("return nil\n}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting generated methods: %v", err)
+	}
+	edits = append(edits, analysis.TextEdit{
+		Pos:     decl.End(),
+		End:     decl.End(),
+		NewText: append([]byte("\n\n"), bytes.TrimSuffix(src, []byte("\n"))...),
+	})
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// receiverName returns the name of the receiver of the first named
+// method of T, if any.
+func receiverName(named *types.Named) string {
+	for m := range named.Methods() {
+		if name := m.Signature().Recv().Name(); name != "" && name != "_" {
+			return name
+		}
+	}
+	return ""
+}
+
+// lowerFirst returns s with its first letter in lower case.
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
@@ -6,8 +6,6 @@ package golang
 
 import (
 	"context"
-	"go/parser"
-	"go/token"
 	"sort"
 	"strings"
 	"sync"
@@ -37,16 +35,12 @@ func KnownPackagePaths(ctx context.Context, snapshot *cache.Snapshot, fh file.Ha
 
 	// Parse the file's imports so we can compute which
 	// PackagePaths are imported by this specific file.
-	src, err := fh.Content()
-	if err != nil {
-		return nil, err
-	}
-	file, err := parser.ParseFile(token.NewFileSet(), fh.URI().Path(), src, parser.ImportsOnly)
+	pgf, err := snapshot.ParseHeader(ctx, fh)
 	if err != nil {
 		return nil, err
 	}
 	imported := make(map[PackagePath]bool)
-	for _, imp := range file.Imports {
+	for _, imp := range pgf.File.Imports {
 		if id := current.DepsByImpPath[metadata.UnquoteImportPath(imp)]; id != "" {
 			if mp := snapshot.Metadata(id); mp != nil {
 				imported[mp.PkgPath] = true
@@ -0,0 +1,164 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Merge tests into a table-driven
+// test", which merges sibling tests such as TestFoo_A and TestFoo_B
+// into a single table-driven test TestFoo.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/diff"
+)
+
+// A testMerge describes the merge of sibling tests into a single
+// table-driven test.
+type testMerge struct {
+	name  string // name of the merged test, such as TestFoo
+	decls []*ast.FuncDecl
+	table *tableTest
+}
+
+// testMergeOf returns the merge of the sibling tests of the test
+// function enclosing [start, end) in a _test.go file: the tests of the
+// file whose names consist of the same subject followed by an
+// underscore and a suffix, such as TestFoo_Empty and TestFoo_Large,
+// whose bodies have the same structure, and differ only in the values
+// of their literals. The merged test is named after the subject, and
+// its test cases after the suffixes.
+func testMergeOf(info *types.Info, pgf *parsego.File, start, end token.Pos) (*testMerge, error) {
+	if !strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+		return nil, fmt.Errorf("not a test file")
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	if len(path) < 2 {
+		return nil, fmt.Errorf("no enclosing test function")
+	}
+	decl, ok := path[len(path)-2].(*ast.FuncDecl)
+	if !ok || !isTestFuncDecl(info, decl) {
+		return nil, fmt.Errorf("no enclosing test function")
+	}
+	i := strings.LastIndex(decl.Name.Name, "_")
+	if i < 0 {
+		return nil, fmt.Errorf("test name has no suffix")
+	}
+	name := decl.Name.Name[:i]
+	if obj := info.Defs[decl.Name]; obj == nil || obj.Parent() == nil || obj.Parent().Lookup(name) != nil {
+		return nil, fmt.Errorf("%s is already declared", name)
+	}
+
+	// Find the siblings, which must have the same parameter.
+	param := decl.Type.Params.List[0]
+	paramText, _ := nodeText(pgf, param)
+	var (
+		decls  []*ast.FuncDecl
+		groups [][]ast.Stmt
+		names  []string
+	)
+	for _, d := range pgf.File.Decls {
+		d, ok := d.(*ast.FuncDecl)
+		if !ok || !isTestFuncDecl(info, d) {
+			continue
+		}
+		suffix, ok := strings.CutPrefix(d.Name.Name, name+"_")
+		if !ok || suffix == "" || strings.Contains(suffix, "_") {
+			continue
+		}
+		if text, _ := nodeText(pgf, d.Type.Params.List[0]); text != paramText {
+			return nil, fmt.Errorf("%s has a different parameter", d.Name.Name)
+		}
+		if len(d.Body.List) == 0 || refersToTable(d.Body) {
+			return nil, fmt.Errorf("%s cannot be a test case", d.Name.Name)
+		}
+		decls = append(decls, d)
+		groups = append(groups, d.Body.List)
+		names = append(names, suffix)
+	}
+	if len(decls) < 2 {
+		return nil, fmt.Errorf("%s has no siblings", decl.Name.Name)
+	}
+	units, ok := varyingLiterals(info, pgf, groups)
+	if !ok {
+		return nil, fmt.Errorf("tests differ in more than literals")
+	}
+	return &testMerge{
+		name:  name,
+		decls: decls,
+		table: &tableTest{
+			decl:   decls[0],
+			groups: groups,
+			units:  units,
+			fields: testCaseFields(info, pgf, units),
+			names:  names,
+		},
+	}, nil
+}
+
+// mergeTests replaces the sibling tests of the test function enclosing
+// [start, end) by a single table-driven test (see [testMergeOf]), in
+// the place of the first of them, whose test cases are the original
+// tests and whose subtests run the body of the first of them with the
+// literals that vary replaced by the fields of the test case.
+func mergeTests(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	edits, err := mergeTestsEdits(pkg.TypesInfo(), pkg.Types(), pgf, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// mergeTestsEdits returns the edits of [mergeTests].
+func mergeTestsEdits(info *types.Info, pkg *types.Package, pgf *parsego.File, start, end token.Pos) ([]analysis.TextEdit, error) {
+	m, err := testMergeOf(info, pgf, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the first test, without its doc comment, which no longer
+	// describes it.
+	first := m.decls[0]
+	param, _ := nodeText(pgf, first.Type.Params.List[0])
+	declStart, declEnd, err := safetoken.Offsets(pgf.Tok, first.Pos(), first.End())
+	if err != nil {
+		return nil, err
+	}
+	text := fmt.Sprintf("func %s(%s) {%s}", m.name, param, m.table.body(info, pkg, pgf))
+	edits, err := formatEditsWithin(pgf, []diff.Edit{{Start: declStart, End: declEnd, New: text}}, first)
+	if err != nil {
+		return nil, err
+	}
+	if first.Doc != nil {
+		edits = append(edits, analysis.TextEdit{Pos: first.Doc.Pos(), End: first.Pos()})
+	}
+
+	// Delete the others, along with the blank lines that follow them,
+	// or that precede them at the end of the file.
+	for _, decl := range m.decls[1:] {
+		start, end, err := declOffsets(pgf, decl)
+		if err != nil {
+			return nil, err
+		}
+		for end < len(pgf.Src) && pgf.Src[end] == '\n' {
+			end++
+		}
+		if end == len(pgf.Src) {
+			for start > 1 && pgf.Src[start-1] == '\n' && pgf.Src[start-2] == '\n' {
+				start--
+			}
+		}
+		edits = append(edits, analysis.TextEdit{Pos: pgf.Tok.Pos(start), End: pgf.Tok.Pos(end)})
+	}
+	return edits, nil
+}
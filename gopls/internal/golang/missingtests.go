@@ -0,0 +1,215 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the report of the functions without tests of the
+// workspace (see the MissingTests command), and the diagnostics of the
+// missingtest analyzer.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"html"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/analysis/missingtest"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/settings"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+)
+
+// MissingTestDiagnostics returns the diagnostics of the missingtest
+// analyzer, if it is enabled, for the non-test files of the ordinary
+// packages among the specified ones.
+//
+// The analyzer cannot be run by the analysis driver, as a package is
+// analyzed along with its tests only in its test variant, whereas
+// gopls reports the diagnostics of a file for its narrowest package.
+// Instead, the tests of each package are found in its test relation
+// (see [cache.Snapshot.TestRelation]), which also includes those of
+// its external test package, and the diagnostics are computed from
+// the syntax of the package, without type-checking it.
+func MissingTestDiagnostics(ctx context.Context, snapshot *cache.Snapshot, pkgs map[metadata.PackageID]*metadata.Package) (map[protocol.DocumentURI][]*cache.Diagnostic, error) {
+	a := settings.DefaultAnalyzers[missingtest.Analyzer.Name]
+	if enabled, ok := snapshot.Options().Analyses[a.Analyzer().Name]; !enabled && (ok || !a.EnabledByDefault()) {
+		return nil, nil
+	}
+	reports := make(map[protocol.DocumentURI][]*cache.Diagnostic)
+	for _, mp := range pkgs {
+		if mp.ForTest != "" || mp.Name == "main" || metadata.IsCommandLineArguments(mp.ID) {
+			continue
+		}
+		rel, err := snapshot.TestRelation(ctx, mp.PkgPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(rel.Tests()) == 0 {
+			continue // the package has no tests
+		}
+		tested := func(name string) bool { return len(testsOf(rel, name)) > 0 }
+		for _, uri := range mp.CompiledGoFiles {
+			if strings.HasSuffix(uri.Path(), "_test.go") {
+				continue
+			}
+			fh, err := snapshot.ReadFile(ctx, uri)
+			if err != nil {
+				return nil, err
+			}
+			pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+			if err != nil {
+				return nil, err
+			}
+			for _, diag := range missingtest.Untested([]*ast.File{pgf.File}, tested) {
+				loc, err := pgf.PosLocation(diag.Pos, diag.End)
+				if err != nil {
+					return nil, err
+				}
+				fix := diag.SuggestedFixes[0]
+				cmd := command.NewApplyFixCommand(fix.Message, command.ApplyFixArgs{
+					Fix:      diag.Category,
+					Location: loc,
+				})
+				reports[uri] = append(reports[uri], &cache.Diagnostic{
+					URI:            uri,
+					Range:          loc.Range,
+					Severity:       a.Severity(),
+					Code:           diag.Category,
+					Source:         cache.DiagnosticSource(a.Analyzer().Name),
+					Message:        diag.Message,
+					SuggestedFixes: []cache.SuggestedFix{cache.SuggestedFixFromCommand(cmd, protocol.QuickFix)},
+				})
+			}
+		}
+	}
+	return reports, nil
+}
+
+// MissingTestsHTML returns an HTML document that lists, grouped by
+// package, the functions and methods declared in the workspace
+// packages of the snapshot that have no tests, as identified by their
+// names (see [testsOf]). Each entry links to the declaration, and to a
+// URL that adds a test for it (see [Web.AddTestURL]).
+//
+// The report depends only on the syntax of the packages and on their
+// test relations, so it does not type-check the workspace.
+func MissingTestsHTML(ctx context.Context, snapshot *cache.Snapshot, viewID string, web Web) ([]byte, error) {
+	mps, err := snapshot.WorkspaceMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// -- model --
+
+	type Func struct {
+		Name      string
+		Filename  string
+		Line, Col int // 1-based UTF-8
+	}
+	type Package struct {
+		Path    metadata.PackagePath
+		Total   int // the number of testable functions
+		Missing []Func
+	}
+	var (
+		pkgs    []*Package
+		total   int
+		missing int
+	)
+	seen := make(map[metadata.PackagePath]bool)
+	slices.SortFunc(mps, func(x, y *metadata.Package) int { return strings.Compare(string(x.PkgPath), string(y.PkgPath)) })
+	for _, mp := range mps {
+		if mp.ForTest != "" || metadata.IsCommandLineArguments(mp.ID) || seen[mp.PkgPath] {
+			continue
+		}
+		seen[mp.PkgPath] = true
+		rel, err := snapshot.TestRelation(ctx, mp.PkgPath)
+		if err != nil {
+			return nil, err
+		}
+		pkg := &Package{Path: mp.PkgPath}
+		for _, uri := range mp.CompiledGoFiles {
+			if strings.HasSuffix(uri.Path(), "_test.go") {
+				continue
+			}
+			fh, err := snapshot.ReadFile(ctx, uri)
+			if err != nil {
+				return nil, err
+			}
+			pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+			if err != nil {
+				return nil, err
+			}
+			for _, decl := range pgf.File.Decls {
+				decl, ok := decl.(*ast.FuncDecl)
+				if !ok {
+					continue
+				}
+				name, ok := testableName(pgf.File, decl)
+				if !ok {
+					continue
+				}
+				pkg.Total++
+				if len(testsOf(rel, name)) > 0 {
+					continue
+				}
+				posn := safetoken.Position(pgf.Tok, decl.Name.Pos())
+				pkg.Missing = append(pkg.Missing, Func{
+					Name:     name,
+					Filename: uri.Path(),
+					Line:     posn.Line,
+					Col:      posn.Column,
+				})
+			}
+		}
+		total += pkg.Total
+		missing += len(pkg.Missing)
+		if len(pkg.Missing) > 0 {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	// -- presentation --
+
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<style>
+li { font-family: monospace; }
+p { max-width: 6in; }
+.count { color: #808080; }
+</style>
+  <script src="/assets/common.js"></script>
+  <link rel="stylesheet" href="/assets/common.css">
+</head>
+<body>
+<h1>Missing tests</h1>
+`)
+	fmt.Fprintf(&buf, "<p>\n  %d of the %d functions and methods of the workspace have no tests.\n", missing, total)
+	buf.WriteString("  Click a name to open its declaration, or <i>add test</i> to add a table-driven test for it.\n</p>\n")
+	for _, pkg := range pkgs {
+		fmt.Fprintf(&buf, "<h2>%s <span class='count'>(%d of %d untested)</span></h2>\n",
+			html.EscapeString(string(pkg.Path)), len(pkg.Missing), pkg.Total)
+		buf.WriteString("<ul>\n")
+		for _, fn := range pkg.Missing {
+			fmt.Fprintf(&buf, "<li>%s — %s</li>\n",
+				sourceLink(html.EscapeString(fn.Name), web.SrcURL(fn.Filename, fn.Line, fn.Col)),
+				sourceLink("add test", web.AddTestURL(viewID, fn.Filename, fn.Line, fn.Col)))
+		}
+		buf.WriteString("</ul>\n")
+	}
+	if len(pkgs) == 0 {
+		buf.WriteString("<p>All functions have tests.</p>\n")
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,352 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Generate mock for I".
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+)
+
+// mockInterfaceAt returns the declaration of the non-generic
+// interface type with methods enclosing [start, end).
+func mockInterfaceAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.TypeSpec, *types.Named) {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, nil
+	}
+	curSpec := curSel
+	if !is[*ast.TypeSpec](curSpec.Node()) {
+		for cur := range curSel.Ancestors((*ast.TypeSpec)(nil)) {
+			curSpec = cur
+			break
+		}
+	}
+	spec, ok := curSpec.Node().(*ast.TypeSpec)
+	if !ok || spec.TypeParams != nil || spec.Assign.IsValid() || !is[*ast.File](curSpec.Parent().Parent().Node()) {
+		return nil, nil
+	}
+	if _, ok := spec.Type.(*ast.InterfaceType); !ok {
+		return nil, nil
+	}
+	obj, ok := pkg.TypesInfo().Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return nil, nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, nil
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() == 0 || !iface.IsMethodSet() {
+		return nil, nil
+	}
+	return spec, named
+}
+
+// mockDirective returns the text of the go:generate directive of the
+// package that runs a mock generator, mockgen or moq, for the named
+// interface declared in the file pgf, and the name of the generator.
+// A directive runs a generator for the interface if it names the
+// interface, or if it runs mockgen in source mode on the file.
+func mockDirective(pkg *cache.Package, pgf *parsego.File, name string) (directive, tool string) {
+	for _, f := range pkg.CompiledGoFiles() {
+		for _, group := range f.File.Comments {
+			for _, c := range group.List {
+				text, ok := strings.CutPrefix(c.Text, "//go:generate ")
+				if !ok {
+					continue
+				}
+				words := strings.Fields(text)
+				tool := ""
+				for _, word := range words {
+					base, _, _ := strings.Cut(path.Base(word), "@")
+					if base == "mockgen" || base == "moq" {
+						tool = base
+						break
+					}
+				}
+				if tool == "" {
+					continue
+				}
+				for _, word := range words {
+					if slices.Contains(strings.Split(word, ","), name) ||
+						tool == "mockgen" && f == pgf && word == "-source="+filepath.Base(pgf.URI.Path()) {
+						return strings.TrimSpace(c.Text), tool
+					}
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+// addMock returns the changes that write a mock implementation of
+// the selected interface type I: a struct type IMock with a field
+// IFunc of function type for each method M of I, which the method M of
+// IMock calls after recording its arguments; the method MCalls
+// returns the recorded calls.
+//
+// The mock is written to the file i.go of the mocks package in the
+// mocks subdirectory of the package of I, replacing the file if it
+// already exists. If I or its methods refer to unexported names, the
+// mock is instead written to the file i_mock_test.go of the package
+// of I, where only the tests of the package can use it.
+func addMock(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng protocol.Range) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(rng)
+	if err != nil {
+		return nil, err
+	}
+	_, named := mockInterfaceAt(pkg, pgf, start, end)
+	if named == nil {
+		return nil, fmt.Errorf("no interface type selected")
+	}
+	iface := named.Underlying().(*types.Interface)
+	name := named.Obj().Name()
+
+	// Choose the file and package of the mock.
+	inPackage := !named.Obj().Exported()
+	seen := make(map[types.Type]bool)
+	for m := range iface.Methods() {
+		if !m.Exported() || typeRefsUnexported(m.Signature(), pkg.Types(), seen) {
+			inPackage = true
+		}
+	}
+	var (
+		dir     = filepath.Join(pgf.URI.DirPath(), "mocks")
+		base    = strings.ToLower(name) + ".go"
+		pkgName = "mocks"
+	)
+	if inPackage {
+		dir, base, pkgName = pgf.URI.DirPath(), strings.ToLower(name)+"_mock_test.go", pgf.File.Name.Name
+	} else {
+		// Use the name of an existing mocks package.
+		for _, mp := range snapshot.MetadataGraph().Packages {
+			if len(mp.CompiledGoFiles) > 0 && mp.CompiledGoFiles[0].DirPath() == dir {
+				pkgName = string(mp.Name)
+				break
+			}
+		}
+	}
+
+	src, err := mockSource(pkg.Types(), named, pkgName, inPackage)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := protocol.URIFromPath(filepath.Join(dir, base))
+	mockFH, err := snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	var changes []protocol.DocumentChange
+	rng = protocol.Range{}
+	if content, err := mockFH.Content(); err != nil {
+		changes = append(changes, protocol.DocumentChangeCreate(uri))
+	} else {
+		// Regenerate the existing file.
+		if rng, err = protocol.NewMapper(uri, content).OffsetRange(0, len(content)); err != nil {
+			return nil, err
+		}
+	}
+	changes = append(changes, protocol.DocumentChangeEdit(mockFH, []protocol.TextEdit{{Range: rng, NewText: string(src)}}))
+	return changes, nil
+}
+
+// mockSource returns the source of a file of the named package that
+// declares the mock of the interface type named, which belongs to
+// pkg; the file belongs to pkg itself if inPackage. See [addMock].
+func mockSource(pkg *types.Package, named *types.Named, pkgName string, inPackage bool) ([]byte, error) {
+	iface := named.Underlying().(*types.Interface)
+	name := named.Obj().Name()
+	mock := name + "Mock"
+
+	// The names of the methods, fields, and types that the mock
+	// declares must not conflict.
+	decls := map[string]bool{"mu": true, "calls": true}
+	for m := range iface.Methods() {
+		for _, decl := range []string{m.Name(), m.Name() + "Func", m.Name() + "Calls"} {
+			if decls[decl] {
+				return nil, fmt.Errorf("cannot generate mock: the name %s is used twice", decl)
+			}
+			decls[decl] = true
+		}
+	}
+
+	// Qualify the references to packages, choosing distinct names.
+	type importSpec struct{ name, path string }
+	var imports []importSpec
+	qual := func(p *types.Package) string {
+		if inPackage && p == pkg {
+			return ""
+		}
+		for _, imp := range imports {
+			if imp.path == p.Path() {
+				return imp.name
+			}
+		}
+		name := p.Name()
+		for i := 2; slices.ContainsFunc(imports, func(imp importSpec) bool { return imp.name == name }); i++ {
+			name = fmt.Sprintf("%s%d", p.Name(), i)
+		}
+		imports = append(imports, importSpec{name, p.Path()})
+		return name
+	}
+	syncName := qual(types.NewPackage("sync", "sync"))
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "// %s is a mock implementation of [%s].\n", mock, types.TypeString(named, qual))
+	fmt.Fprintf(&body, "// Each method calls the function in the corresponding field,\n")
+	fmt.Fprintf(&body, "// after recording its arguments.\n")
+	fmt.Fprintf(&body, "type %s struct {\n", mock)
+	for m := range iface.Methods() {
+		params, _, _, results := mockSignature(m.Signature(), qual, "mock")
+		fmt.Fprintf(&body, "// %sFunc is called by %s.\n", m.Name(), m.Name())
+		fmt.Fprintf(&body, "%sFunc func(%s) %s\n\n", m.Name(), strings.Join(params, ", "), results)
+	}
+	fmt.Fprintf(&body, "mu %s.Mutex\n", syncName)
+	fmt.Fprintf(&body, "calls struct {\n")
+	for m := range iface.Methods() {
+		fmt.Fprintf(&body, "%s []%s%sCall\n", m.Name(), mock, m.Name())
+	}
+	fmt.Fprintf(&body, "}\n}\n\n")
+	fmt.Fprintf(&body, "var _ %s = (*%s)(nil)\n", types.TypeString(named, qual), mock)
+
+	for m := range iface.Methods() {
+		params, args, fieldTypes, results := mockSignature(m.Signature(), qual, "mock")
+		call := mock + m.Name() + "Call"
+		fmt.Fprintf(&body, "\n// %s holds the arguments of a call of [%s.%s].\n", call, mock, m.Name())
+		fmt.Fprintf(&body, "type %s struct", call)
+		var fields []string
+		for i, typ := range fieldTypes {
+			if i == 0 {
+				body.WriteString(" {\n")
+			}
+			arg := strings.TrimSuffix(args[i], "...")
+			field := exportedName(arg)
+			fmt.Fprintf(&body, "%s %s\n", field, typ)
+			fields = append(fields, fmt.Sprintf("%s: %s", field, arg))
+		}
+		if len(fields) == 0 {
+			body.WriteString("{}\n\n")
+		} else {
+			body.WriteString("}\n\n")
+		}
+
+		fmt.Fprintf(&body, "// %s calls %sFunc, after recording the call.\n", m.Name(), m.Name())
+		fmt.Fprintf(&body, "func (mock *%s) %s(%s) %s {\n", mock, m.Name(), strings.Join(params, ", "), results)
+		fmt.Fprintf(&body, "mock.mu.Lock()\n")
+		fmt.Fprintf(&body, "mock.calls.%s = append(mock.calls.%s, %s{%s})\n", m.Name(), m.Name(), call, strings.Join(fields, ", "))
+		fmt.Fprintf(&body, "mock.mu.Unlock()\n")
+		fmt.Fprintf(&body, "if mock.%sFunc == nil {\n", m.Name())
+		fmt.Fprintf(&body, "panic(%q)\n", fmt.Sprintf("%s.%sFunc is nil but %s.%s was called", mock, m.Name(), mock, m.Name()))
+		fmt.Fprintf(&body, "}\n")
+		if results != "" {
+			body.WriteString("return ")
+		}
+		fmt.Fprintf(&body, "mock.%sFunc(%s)\n}\n\n", m.Name(), strings.Join(args, ", "))
+
+		fmt.Fprintf(&body, "// %sCalls returns the calls of %s so far.\n", m.Name(), m.Name())
+		fmt.Fprintf(&body, "func (mock *%s) %sCalls() []%s {\n", mock, m.Name(), call)
+		fmt.Fprintf(&body, "mock.mu.Lock()\n")
+		fmt.Fprintf(&body, "defer mock.mu.Unlock()\n")
+		fmt.Fprintf(&body, "return append([]%s(nil), mock.calls.%s...)\n}\n", call, m.Name())
+	}
+
+	// Write the header and imports, the standard packages first.
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gopls. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	slices.SortFunc(imports, func(x, y importSpec) int { return strings.Compare(x.path, y.path) })
+	isStd := func(imp importSpec) bool {
+		first, _, _ := strings.Cut(imp.path, "/")
+		return !strings.Contains(first, ".")
+	}
+	slices.SortStableFunc(imports, func(x, y importSpec) int {
+		return -cmp.Compare(btoi(isStd(x)), btoi(isStd(y)))
+	})
+	buf.WriteString("import (\n")
+	for i, imp := range imports {
+		if i > 0 && isStd(imp) != isStd(imports[i-1]) {
+			buf.WriteString("\n")
+		}
+		if path.Base(imp.path) != imp.name {
+			fmt.Fprintf(&buf, "%s ", imp.name)
+		}
+		fmt.Fprintf(&buf, "%q\n", imp.path)
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated mock: %v", err)
+	}
+	return src, nil
+}
+
+// mockSignature returns the parameters, the arguments that forward
+// them, the types of the fields that record them, and the results
+// of a mock of a method of signature sig, whose receiver is named recv.
+// Unnamed parameters, and those named recv, are named after their
+// position.
+func mockSignature(sig *types.Signature, qual types.Qualifier, recv string) (params, args, fieldTypes []string, results string) {
+	for i := range sig.Params().Len() {
+		param := sig.Params().At(i)
+		name := param.Name()
+		if name == "" || name == "_" || name == recv {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		typ := types.TypeString(param.Type(), qual)
+		fieldTypes = append(fieldTypes, typ)
+		arg := name
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			typ = "..." + types.TypeString(param.Type().(*types.Slice).Elem(), qual)
+			arg += "..."
+		}
+		params = append(params, name+" "+typ)
+		args = append(args, arg)
+	}
+	var resultTypes []string
+	for res := range sig.Results().Variables() {
+		resultTypes = append(resultTypes, types.TypeString(res.Type(), qual))
+	}
+	switch len(resultTypes) {
+	case 0:
+	case 1:
+		results = resultTypes[0]
+	default:
+		results = "(" + strings.Join(resultTypes, ", ") + ")"
+	}
+	return params, args, fieldTypes, results
+}
+
+// exportedName returns name with its first letter in upper case.
+func exportedName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
@@ -0,0 +1,255 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Extract parameters of F into
+// struct".
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+// paramStructAt returns the declaration of the selected function F,
+// if its parameters, after a leading context.Context parameter if
+// any, can be extracted into a struct type, FOptions. It also returns
+// the index of the first such parameter.
+func paramStructAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.FuncDecl, *types.Func, int) {
+	decl, fn := funcDeclAt(pkg, pgf, start, end)
+	if decl == nil || decl.Type.TypeParams != nil || fn.Signature().Variadic() {
+		return nil, nil, 0
+	}
+	params := fn.Signature().Params()
+	first := 0
+	if params.Len() > 0 && analysisinternal.IsTypeNamed(params.At(0).Type(), "context", "Context") {
+		first = 1
+	}
+	if params.Len()-first < 2 {
+		return nil, nil, 0 // too few to be worth it
+	}
+	// Each parameter must have a distinct field name. A context
+	// does not belong in a struct.
+	seen := make(map[string]bool)
+	for i := first; i < params.Len(); i++ {
+		name := params.At(i).Name()
+		if name == "" || name == "_" || seen[paramFieldName(fn, name)] ||
+			analysisinternal.IsTypeNamed(params.At(i).Type(), "context", "Context") {
+			return nil, nil, 0
+		}
+		seen[paramFieldName(fn, name)] = true
+	}
+	if pkg.Types().Scope().Lookup(paramStructName(fn)) != nil {
+		return nil, nil, 0 // name is taken
+	}
+	return decl, fn, first
+}
+
+// paramStructName returns the name of the struct type that holds the
+// parameters of fn.
+func paramStructName(fn *types.Func) string {
+	return fn.Name() + "Options"
+}
+
+// paramFieldName returns the name of the field of the struct type for
+// the parameter of fn named name, which is exported if fn is.
+func paramFieldName(fn *types.Func, name string) string {
+	if fn.Exported() {
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+	return name
+}
+
+// ExtractParamStruct replaces the parameters of the selected function
+// F, after a leading context.Context parameter if any, by a single
+// parameter of a new struct type, FOptions, whose fields hold them.
+// Each call F(x, y) becomes F(FOptions{X: x, Y: y}).
+func ExtractParamStruct(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	decl, fn, first := paramStructAt(pkg, pgf, start, end)
+	if decl == nil {
+		return nil, fmt.Errorf("cannot extract the parameters of the selected function")
+	}
+	var (
+		info       = pkg.TypesInfo()
+		params     = fn.Signature().Params()
+		structName = paramStructName(fn)
+		structObj  = types.NewTypeName(token.NoPos, fn.Pkg(), structName, nil)
+		c          = newWorkspaceChange()
+	)
+
+	// Choose a name for the new parameter that is not used in decl.
+	used := make(map[string]bool)
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			used[id.Name] = true
+		}
+		return true
+	})
+	opts, _ := generateName(0, "opts", func(name string) bool { return used[name] })
+
+	// Declare the struct type before the function.
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// %s holds the parameters of %s.\n", structName, fn.Name())
+	fmt.Fprintf(&buf, "type %s struct {\n", structName)
+	fields := make(map[*types.Var]string) // field name of each parameter
+	for i := first; i < params.Len(); i++ {
+		v := params.At(i)
+		fields[v] = paramFieldName(fn, v.Name())
+	}
+	var firstField *ast.Field // first field of decl with an extracted parameter
+	i := 0
+	for _, field := range decl.Type.Params.List {
+		for range field.Names {
+			if i >= first {
+				if firstField == nil {
+					firstField = field
+				}
+				typ, err := nodeText(pgf, field.Type)
+				if err != nil {
+					return nil, err
+				}
+				fmt.Fprintf(&buf, "%s %s\n", fields[params.At(i)], typ)
+			}
+			i++
+		}
+	}
+	buf.WriteString("}\n")
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting struct type: %v", err)
+	}
+	declStart := decl.Pos()
+	if decl.Doc != nil {
+		declStart = decl.Doc.Pos()
+	}
+	if err := c.addEdit(pgf, declStart, declStart, string(src)+"\n"); err != nil {
+		return nil, err
+	}
+
+	// Replace the parameters.
+	paramsStart := firstField.Pos()
+	if first == 1 && firstField == decl.Type.Params.List[0] {
+		// The first field declares the context too: F(ctx, x context.Context, ...).
+		paramsStart = firstField.Names[1].Pos()
+	}
+	if err := c.addEdit(pgf, paramsStart, decl.Type.Params.Closing, opts+" "+structName); err != nil {
+		return nil, err
+	}
+
+	// Replace each use of a parameter p by opts.P.
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if v, ok := info.Uses[id].(*types.Var); ok {
+				if field, ok := fields[v]; ok {
+					if err == nil {
+						err = c.addEdit(pgf, id.Pos(), id.End(), opts+"."+field)
+					}
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the calls.
+	pkgs, err := typeCheckReverseDependencies(ctx, snapshot, pgf.URI, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		info := pkg.TypesInfo()
+		for _, pgf := range pkg.CompiledGoFiles() {
+			for cur := range pgf.Cursor.Preorder((*ast.Ident)(nil)) {
+				id := cur.Node().(*ast.Ident)
+				if !isSameFunc(info.Uses[id], fn) {
+					continue
+				}
+				call := calleeCall(cur)
+				if call == nil {
+					c.addConflict(pkg, id.Pos(), "reference to %s is not a call", fn.Name())
+					continue
+				}
+				args := call.Args
+				if len(args) == 1 && is[*types.Tuple](info.TypeOf(args[0])) {
+					c.addConflict(pkg, call.Pos(), "call to %s has a multi-valued argument", fn.Name())
+					continue
+				}
+				if sel, ok := cur.Parent().Node().(*ast.SelectorExpr); ok &&
+					info.Selections[sel] != nil && info.Selections[sel].Kind() == types.MethodExpr {
+					args = args[1:] // T.F(recv, ...)
+				}
+				args = args[first:]
+
+				prefix, ok, err := c.qualifier(pkg, pgf, structObj, call.Pos())
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					c.addConflict(pkg, call.Pos(), "type %s would not be accessible", structName)
+					continue
+				}
+				var lit strings.Builder
+				fmt.Fprintf(&lit, "%s%s{", prefix, structName)
+				for i, arg := range args {
+					if i > 0 {
+						lit.WriteString(", ")
+					}
+					text, err := nodeText(pgf, arg)
+					if err != nil {
+						return nil, err
+					}
+					fmt.Fprintf(&lit, "%s: %s", fields[params.At(first+i)], text)
+				}
+				lit.WriteString("}")
+				if err := c.addEdit(pgf, args[0].Pos(), args[len(args)-1].End(), lit.String()); err != nil {
+					return nil, err
+				}
+			}
+
+			// A method with new parameters no longer implements
+			// interfaces that it used to.
+			if decl.Recv != nil {
+				forEachInterfaceConversion(info, pgf.File, func(e ast.Expr, iface types.Type) {
+					t := info.TypeOf(e)
+					if t == nil || types.IsInterface(t) {
+						return
+					}
+					if obj, _, _ := types.LookupFieldOrMethod(iface, false, fn.Pkg(), fn.Name()); obj == nil {
+						return
+					}
+					if obj, _, _ := types.LookupFieldOrMethod(t, false, fn.Pkg(), fn.Name()); obj != nil && isSameFunc(obj, fn) {
+						c.addConflict(pkg, e.Pos(), "value of type %s would no longer implement %s",
+							types.TypeString(t, types.RelativeTo(pkg.Types())), types.TypeString(iface, types.RelativeTo(pkg.Types())))
+					}
+				})
+			}
+		}
+	}
+
+	if err := c.conflictError("cannot extract the parameters of %s", fn.Name()); err != nil {
+		return nil, err
+	}
+	return c.documentChanges(ctx, snapshot)
+}
@@ -262,6 +262,10 @@ type Web interface {
 
 	// SrcURL forms URLs that cause the editor to open a file at a specific position.
 	SrcURL(filename string, line, col8 int) protocol.URI
+
+	// AddTestURL forms URLs that cause gopls to add a test for the
+	// function declared at a specific position.
+	AddTestURL(viewID, filename string, line, col8 int) protocol.URI
 }
 
 // PackageDocHTML formats the package documentation page.
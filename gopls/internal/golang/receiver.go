@@ -0,0 +1,683 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code actions "Convert all methods of T to
+// pointer receivers" and "Convert all methods of T to value receivers".
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/astutil/edge"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// receiverTypeAt returns the named type T of the method declaration
+// whose receiver encloses [start, end), provided T is declared in pkg,
+// along with the number of methods of T that have value and pointer
+// receivers.
+func receiverTypeAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (named *types.Named, nvalue, nptr int) {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, 0, 0
+	}
+	curDecl := curSel
+	if !is[*ast.FuncDecl](curDecl.Node()) {
+		for cur := range curSel.Ancestors((*ast.FuncDecl)(nil)) {
+			curDecl = cur
+			break
+		}
+	}
+	decl, ok := curDecl.Node().(*ast.FuncDecl)
+	if !ok || decl.Recv == nil || !posRangeContains(decl.Recv.Pos(), decl.Recv.End(), start, end) {
+		return nil, 0, 0
+	}
+	fn, ok := pkg.TypesInfo().Defs[decl.Name].(*types.Func)
+	if !ok {
+		return nil, 0, 0
+	}
+	_, named = typesinternal.ReceiverNamed(fn.Signature().Recv())
+	if named == nil || named.Obj().Pkg() != pkg.Types() {
+		return nil, 0, 0
+	}
+	for m := range named.Origin().Methods() {
+		if isPtr, _ := typesinternal.ReceiverNamed(m.Signature().Recv()); isPtr {
+			nptr++
+		} else {
+			nvalue++
+		}
+	}
+	return named.Origin(), nvalue, nptr
+}
+
+// ChangeReceivers changes the receivers of all the methods of the type
+// T of the selected method's receiver to pointers (*T) if pointer is
+// set, or to values (T) otherwise.
+//
+// When converting to pointer receivers, a method that modifies its
+// receiver, or uses it other than to select a field or call a method,
+// is given a local copy of the receiver so that its behavior is
+// unchanged; a composite literal T{...} that is converted to an
+// interface is replaced by &T{...}. When converting to value receivers,
+// expressions *r that dereference the receiver r are replaced by r.
+//
+// The refactoring fails, reporting each conflict, if it cannot preserve
+// the behavior of the program: for example, if a method with a pointer
+// receiver modifies its receiver, or if a method is called on a value
+// that is not addressable.
+func ChangeReceivers(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location, pointer bool) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	named, _, _ := receiverTypeAt(pkg, pgf, start, end)
+	if named == nil {
+		return nil, fmt.Errorf("no method receiver selected")
+	}
+
+	c := &receiverChange{
+		workspaceChange: newWorkspaceChange(),
+		named:           named,
+		pointer:         pointer,
+		changed:         make(map[string]bool),
+	}
+	for m := range named.Methods() {
+		if isPtr, _ := typesinternal.ReceiverNamed(m.Signature().Recv()); isPtr != pointer {
+			c.changed[m.Name()] = true
+		}
+	}
+	if len(c.changed) == 0 {
+		return nil, fmt.Errorf("all methods of %s already have %s receivers", named.Obj().Name(), c.kind())
+	}
+
+	// Update the method declarations.
+	for _, pgf := range pkg.CompiledGoFiles() {
+		for _, decl := range pgf.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv != nil {
+				if fn, ok := pkg.TypesInfo().Defs[decl.Name].(*types.Func); ok && c.isChanged(fn) {
+					if err := c.changeDecl(pkg, pgf, decl); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	// Converting methods to pointer receivers changes the method set
+	// of T, so uses of T's methods in the workspace must be checked.
+	if pointer {
+		pkgs, err := typeCheckReverseDependencies(ctx, snapshot, pgf.URI, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			for _, pgf := range pkg.CompiledGoFiles() {
+				if err := c.checkUses(pkg, pgf); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := c.conflictError("cannot convert methods of %s to %s receivers", named.Obj().Name(), c.kind()); err != nil {
+		return nil, err
+	}
+	return c.documentChanges(ctx, snapshot)
+}
+
+// A receiverChange holds the state of the ChangeReceivers refactoring.
+type receiverChange struct {
+	*workspaceChange
+	named   *types.Named
+	pointer bool            // whether to convert to pointer receivers
+	changed map[string]bool // names of methods whose receivers change
+}
+
+// kind returns the kind of receiver that methods are converted to.
+func (c *receiverChange) kind() string {
+	if c.pointer {
+		return "pointer"
+	}
+	return "value"
+}
+
+// isChanged reports whether obj is a method of T whose receiver changes.
+// Objects are compared by package path and name, as the packages of
+// the workspace may not share a types.Package for T.
+func (c *receiverChange) isChanged(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || !c.changed[fn.Name()] {
+		return false
+	}
+	recv := fn.Signature().Recv()
+	if recv == nil {
+		return false
+	}
+	_, named := typesinternal.ReceiverNamed(recv)
+	return named != nil && named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == c.named.Obj().Pkg().Path() &&
+		named.Obj().Name() == c.named.Obj().Name()
+}
+
+// A workspaceChange accumulates the edits of a refactoring that spans
+// the packages of the workspace, and the conflicts that prevent it.
+type workspaceChange struct {
+	edits     map[protocol.DocumentURI][]diff.Edit
+	files     map[protocol.DocumentURI]*parsego.File
+	format    map[protocol.DocumentURI][]ast.Node // declarations to reformat
+	conflicts []string
+}
+
+func newWorkspaceChange() *workspaceChange {
+	return &workspaceChange{
+		edits:  make(map[protocol.DocumentURI][]diff.Edit),
+		files:  make(map[protocol.DocumentURI]*parsego.File),
+		format: make(map[protocol.DocumentURI][]ast.Node),
+	}
+}
+
+// addEdit records an edit replacing the text of pgf in [start, end).
+func (c *workspaceChange) addEdit(pgf *parsego.File, start, end token.Pos, newText string) error {
+	startOffset, endOffset, err := safetoken.Offsets(pgf.Tok, start, end)
+	if err != nil {
+		return err
+	}
+	// The declarations are visited first, so the file of each
+	// reformatted declaration is the one recorded here.
+	if _, ok := c.files[pgf.URI]; !ok {
+		c.files[pgf.URI] = pgf
+	}
+	c.edits[pgf.URI] = append(c.edits[pgf.URI], diff.Edit{Start: startOffset, End: endOffset, New: newText})
+	return nil
+}
+
+// addConflict records a conflict at the specified position.
+func (c *workspaceChange) addConflict(pkg *cache.Package, pos token.Pos, format string, args ...any) {
+	posn := safetoken.StartPosition(pkg.FileSet(), pos)
+	c.conflicts = append(c.conflicts, fmt.Sprintf("%s: %s", posn, fmt.Sprintf(format, args...)))
+}
+
+// conflictError returns an error with the specified message that
+// lists the conflicts, or nil if there are none.
+func (c *workspaceChange) conflictError(format string, args ...any) error {
+	if len(c.conflicts) == 0 {
+		return nil
+	}
+	slices.Sort(c.conflicts)
+	c.conflicts = slices.Compact(c.conflicts)
+	return fmt.Errorf("%s:\n%s", fmt.Sprintf(format, args...), strings.Join(c.conflicts, "\n"))
+}
+
+// changeDecl changes the receiver of the method declaration decl,
+// updating its body as needed.
+func (c *receiverChange) changeDecl(pkg *cache.Package, pgf *parsego.File, decl *ast.FuncDecl) error {
+	info := pkg.TypesInfo()
+	field := decl.Recv.List[0]
+	if c.pointer {
+		if err := c.addEdit(pgf, field.Type.Pos(), field.Type.Pos(), "*"); err != nil {
+			return err
+		}
+	} else {
+		star, ok := ast.Unparen(field.Type).(*ast.StarExpr)
+		if !ok {
+			return fmt.Errorf("unexpected receiver type %s", types.ExprString(field.Type))
+		}
+		if err := c.addEdit(pgf, star.Star, star.X.Pos(), ""); err != nil {
+			return err
+		}
+	}
+
+	if len(field.Names) == 0 || decl.Body == nil {
+		return nil
+	}
+	recv, ok := info.Defs[field.Names[0]].(*types.Var)
+	if !ok {
+		return nil // blank receiver
+	}
+	mutated, derefs, others := c.receiverUses(info, pgf, decl.Body, recv)
+
+	if c.pointer {
+		// Preserve the behavior of a method that modifies or copies
+		// its receiver by giving it a local copy:
+		//
+		//	func (r0 *T) f() { r := *r0; ... }
+		if !mutated && len(others) == 0 {
+			return nil
+		}
+		name := freshReceiverName(decl, recv.Name())
+		if err := c.addEdit(pgf, field.Names[0].Pos(), field.Names[0].End(), name); err != nil {
+			return err
+		}
+		if err := c.addEdit(pgf, decl.Body.Lbrace+1, decl.Body.Lbrace+1, fmt.Sprintf("\n%s := *%s;", recv.Name(), name)); err != nil {
+			return err
+		}
+		c.format[pgf.URI] = append(c.format[pgf.URI], decl)
+		return nil
+	}
+
+	if mutated {
+		c.addConflict(pkg, decl.Name.Pos(), "method %s modifies its receiver", decl.Name.Name)
+	}
+	for _, id := range others {
+		c.addConflict(pkg, id.Pos(), "method %s uses its receiver as a pointer", decl.Name.Name)
+	}
+	for _, e := range derefs {
+		if err := c.addEdit(pgf, e.Pos(), e.End(), recv.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freshReceiverName returns a name for the receiver of decl, derived
+// from name, that is not used within the declaration.
+func freshReceiverName(decl *ast.FuncDecl, name string) string {
+	used := make(map[string]bool)
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			used[id.Name] = true
+		}
+		return true
+	})
+	for i := 0; ; i++ {
+		if fresh := fmt.Sprintf("%s%d", name, i); !used[fresh] {
+			return fresh
+		}
+	}
+}
+
+// receiverUses classifies the uses of the receiver variable recv
+// within body. It reports whether the body modifies the variable
+// denoted by the receiver (r in the case of a value receiver, *r in
+// the case of a pointer receiver) or any part of it, or takes its
+// address; it also returns the expressions *r that dereference the
+// receiver (including any enclosing parentheses), and the uses of the
+// receiver other than to select a field or call a method.
+func (c *receiverChange) receiverUses(info *types.Info, pgf *parsego.File, body *ast.BlockStmt, recv *types.Var) (mutated bool, derefs []ast.Expr, others []*ast.Ident) {
+	curBody, ok := pgf.Cursor.FindNode(body)
+	if !ok {
+		return false, nil, nil
+	}
+	for cur := range curBody.Preorder() {
+		switch n := cur.Node().(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if withinReceiver(info, lhs, recv) {
+						mutated = true
+					}
+				}
+			}
+		case *ast.IncDecStmt:
+			if withinReceiver(info, n.X, recv) {
+				mutated = true
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN &&
+				(n.Key != nil && withinReceiver(info, n.Key, recv) ||
+					n.Value != nil && withinReceiver(info, n.Value, recv)) {
+				mutated = true
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND && withinReceiver(info, n.X, recv) {
+				mutated = true
+			}
+		case *ast.SelectorExpr:
+			// A call of a pointer method on (part of) the receiver
+			// implicitly takes its address.
+			if sel, ok := info.Selections[n]; ok && sel.Kind() == types.MethodVal &&
+				!c.isChanged(sel.Obj()) && withinReceiver(info, n.X, recv) {
+				if isPtr, _ := typesinternal.ReceiverNamed(sel.Obj().(*types.Func).Signature().Recv()); isPtr &&
+					!pathHasPointer(info.TypeOf(n.X), sel.Index()) {
+					mutated = true
+				}
+			}
+		case *ast.Ident:
+			if info.Uses[n] != recv {
+				continue
+			}
+			switch ek, _ := cur.Edge(); ek {
+			case edge.SelectorExpr_X:
+				// A method value r.f (other than a call r.f())
+				// binds the receiver.
+				sel := info.Selections[cur.Parent().Node().(*ast.SelectorExpr)]
+				if sel != nil && sel.Kind() == types.MethodVal {
+					if ek, _ := cur.Parent().Edge(); ek != edge.CallExpr_Fun {
+						others = append(others, n)
+					}
+				}
+			case edge.StarExpr_X:
+				// Include the parens of (*r).f.
+				curStar := cur.Parent()
+				if ek, _ := curStar.Edge(); ek == edge.ParenExpr_X {
+					curStar = curStar.Parent()
+				}
+				derefs = append(derefs, curStar.Node().(ast.Expr))
+			default:
+				others = append(others, n)
+			}
+		}
+	}
+	return mutated, derefs, others
+}
+
+// withinReceiver reports whether e denotes the variable of the
+// receiver recv (r, or *r if r is a pointer) or a part of it that is
+// reachable without indirection through another pointer: a field, or
+// an element of an array.
+func withinReceiver(info *types.Info, e ast.Expr, recv *types.Var) bool {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		return info.Uses[e] == recv
+	case *ast.StarExpr:
+		id, ok := ast.Unparen(e.X).(*ast.Ident)
+		return ok && info.Uses[id] == recv
+	case *ast.SelectorExpr:
+		sel, ok := info.Selections[e]
+		if !ok || sel.Kind() != types.FieldVal || !withinReceiver(info, e.X, recv) {
+			return false
+		}
+		// Only the receiver itself may be a pointer.
+		if _, ok := types.Unalias(info.TypeOf(e.X)).(*types.Pointer); ok {
+			if id, ok := ast.Unparen(e.X).(*ast.Ident); !ok || info.Uses[id] != recv {
+				return false
+			}
+		}
+		return !pathHasPointer(info.TypeOf(e.X), sel.Index())
+	case *ast.IndexExpr:
+		_, ok := info.TypeOf(e.X).Underlying().(*types.Array)
+		return ok && withinReceiver(info, e.X, recv)
+	}
+	return false
+}
+
+// pathHasPointer reports whether any of the embedded fields
+// traversed by a selection with the specified index from type t is a
+// pointer.
+func pathHasPointer(t types.Type, index []int) bool {
+	t = typesinternal.Unpointer(t)
+	for _, i := range index[:len(index)-1] {
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return true
+		}
+		t = st.Field(i).Type()
+		if _, ok := types.Unalias(t).(*types.Pointer); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUses checks the uses of the methods of T in pgf, which become
+// pointer methods, recording edits and conflicts.
+func (c *receiverChange) checkUses(pkg *cache.Package, pgf *parsego.File) error {
+	info := pkg.TypesInfo()
+	for cur := range pgf.Cursor.Preorder((*ast.SelectorExpr)(nil)) {
+		n := cur.Node().(*ast.SelectorExpr)
+		sel, ok := info.Selections[n]
+		if !ok || !c.isChanged(sel.Obj()) {
+			continue
+		}
+		switch sel.Kind() {
+		case types.MethodExpr:
+			if _, ok := types.Unalias(sel.Recv()).(*types.Pointer); !ok {
+				c.addConflict(pkg, n.Pos(), "method expression %s would require a pointer receiver", types.ExprString(n))
+			}
+		case types.MethodVal:
+			if sel.Indirect() {
+				continue
+			}
+			if !isAddressable(info, n.X) {
+				c.addConflict(pkg, n.Pos(), "%s requires an addressable value", types.ExprString(n))
+			} else if ek, _ := cur.Edge(); ek != edge.CallExpr_Fun {
+				c.addConflict(pkg, n.Pos(), "method value %s would refer to %s instead of a copy", types.ExprString(n), types.ExprString(n.X))
+			}
+		}
+	}
+
+	var err error
+	forEachInterfaceConversion(info, pgf.File, func(e ast.Expr, iface types.Type) {
+		t := info.TypeOf(e)
+		if t == nil || types.IsInterface(t) || err != nil {
+			return
+		}
+		for m := range iface.Underlying().(*types.Interface).Methods() {
+			if obj, _, _ := types.LookupFieldOrMethod(t, false, m.Pkg(), m.Name()); obj != nil && c.isChanged(obj) {
+				if lit, ok := ast.Unparen(e).(*ast.CompositeLit); ok {
+					err = c.addEdit(pgf, lit.Pos(), lit.Pos(), "&")
+				} else {
+					c.addConflict(pkg, e.Pos(), "value of type %s would no longer implement %s",
+						types.TypeString(t, types.RelativeTo(pkg.Types())), types.TypeString(iface, types.RelativeTo(pkg.Types())))
+				}
+				return
+			}
+		}
+	})
+	return err
+}
+
+// isAddressable reports whether e denotes an addressable variable.
+func isAddressable(info *types.Info, e ast.Expr) bool {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		_, ok := info.Uses[e].(*types.Var)
+		return ok
+	case *ast.SelectorExpr:
+		sel, ok := info.Selections[e]
+		if !ok {
+			// qualified identifier pkg.V
+			_, ok := info.Uses[e.Sel].(*types.Var)
+			return ok
+		}
+		return sel.Kind() == types.FieldVal && (sel.Indirect() || isAddressable(info, e.X))
+	case *ast.IndexExpr:
+		switch t := info.TypeOf(e.X).Underlying().(type) {
+		case *types.Slice:
+			return true
+		case *types.Pointer:
+			_, ok := t.Elem().Underlying().(*types.Array)
+			return ok
+		case *types.Array:
+			return isAddressable(info, e.X)
+		}
+	case *ast.StarExpr:
+		return true
+	}
+	return false
+}
+
+// forEachInterfaceConversion calls f for each expression e in file
+// that is converted, implicitly or explicitly, to the interface type
+// iface: the operands of assignments, variable declarations, return
+// statements, send statements, function calls and conversions, and the
+// elements of composite literals.
+func forEachInterfaceConversion(info *types.Info, file *ast.File, f func(e ast.Expr, iface types.Type)) {
+	conv := func(target types.Type, e ast.Expr) {
+		if target != nil && types.IsInterface(target) {
+			if _, ok := target.(*types.TypeParam); !ok {
+				f(e, target)
+			}
+		}
+	}
+	var stack []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.ASSIGN && len(n.Lhs) == len(n.Rhs) {
+				for i, lhs := range n.Lhs {
+					conv(info.TypeOf(lhs), n.Rhs[i])
+				}
+			}
+
+		case *ast.ValueSpec:
+			if n.Type != nil && len(n.Names) == len(n.Values) {
+				for _, v := range n.Values {
+					conv(info.TypeOf(n.Type), v)
+				}
+			}
+
+		case *ast.ReturnStmt:
+			for _, n2 := range slices.Backward(stack) {
+				var sig *types.Signature
+				switch n2 := n2.(type) {
+				case *ast.FuncDecl:
+					sig, _ = info.TypeOf(n2.Name).(*types.Signature)
+				case *ast.FuncLit:
+					sig, _ = info.TypeOf(n2).(*types.Signature)
+				default:
+					continue
+				}
+				if sig != nil && sig.Results().Len() == len(n.Results) {
+					for i, res := range n.Results {
+						conv(sig.Results().At(i).Type(), res)
+					}
+				}
+				break
+			}
+
+		case *ast.SendStmt:
+			if ch, ok := info.TypeOf(n.Chan).Underlying().(*types.Chan); ok {
+				conv(ch.Elem(), n.Value)
+			}
+
+		case *ast.CallExpr:
+			if tv, ok := info.Types[n.Fun]; ok && tv.IsType() {
+				if len(n.Args) == 1 {
+					conv(tv.Type, n.Args[0])
+				}
+				break
+			}
+			sig, ok := info.TypeOf(n.Fun).Underlying().(*types.Signature)
+			if !ok {
+				break
+			}
+			params := sig.Params()
+			for i, arg := range n.Args {
+				switch {
+				case sig.Variadic() && i >= params.Len()-1:
+					if slice, ok := params.At(params.Len() - 1).Type().Underlying().(*types.Slice); ok && !n.Ellipsis.IsValid() {
+						conv(slice.Elem(), arg)
+					}
+				case i < params.Len() && len(n.Args) >= params.Len():
+					conv(params.At(i).Type(), arg)
+				}
+			}
+
+		case *ast.CompositeLit:
+			t := info.TypeOf(n)
+			if t == nil {
+				break
+			}
+			switch t := typesinternal.Unpointer(t).Underlying().(type) {
+			case *types.Struct:
+				for i, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if id, ok := kv.Key.(*ast.Ident); ok {
+							if v, ok := info.Uses[id].(*types.Var); ok {
+								conv(v.Type(), kv.Value)
+							}
+						}
+					} else if i < t.NumFields() {
+						conv(t.Field(i).Type(), elt)
+					}
+				}
+			case *types.Slice, *types.Array, *types.Map:
+				var key, elem types.Type
+				switch t := t.(type) {
+				case *types.Slice:
+					elem = t.Elem()
+				case *types.Array:
+					elem = t.Elem()
+				case *types.Map:
+					key, elem = t.Key(), t.Elem()
+				}
+				for _, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key != nil {
+							conv(key, kv.Key)
+						}
+						elt = kv.Value
+					}
+					conv(elem, elt)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// documentChanges returns the document changes for the edits of c,
+// reformatting the declarations recorded in c.format.
+func (c *workspaceChange) documentChanges(ctx context.Context, snapshot *cache.Snapshot) ([]protocol.DocumentChange, error) {
+	var changes []protocol.DocumentChange
+	for uri, edits := range c.edits {
+		pgf := c.files[uri]
+
+		// The same edit may be computed for several variants of a package.
+		diff.SortEdits(edits)
+		edits = slices.Compact(edits)
+
+		for _, decl := range c.format[uri] {
+			start, end, err := safetoken.Offsets(pgf.Tok, decl.Pos(), decl.End())
+			if err != nil {
+				return nil, err
+			}
+			var within, rest []diff.Edit
+			for _, edit := range edits {
+				if start <= edit.Start && edit.End <= end {
+					within = append(within, edit)
+				} else {
+					rest = append(rest, edit)
+				}
+			}
+			formatted, err := formatEditsWithin(pgf, within, decl)
+			if err != nil {
+				return nil, err
+			}
+			for _, edit := range formatted {
+				startOffset, endOffset, err := safetoken.Offsets(pgf.Tok, edit.Pos, edit.End)
+				if err != nil {
+					return nil, err
+				}
+				rest = append(rest, diff.Edit{Start: startOffset, End: endOffset, New: string(edit.NewText)})
+			}
+			edits = rest
+		}
+		diff.SortEdits(edits)
+
+		protocolEdits, err := protocol.EditsFromDiffEdits(pgf.Mapper, edits)
+		if err != nil {
+			return nil, err
+		}
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, protocol.DocumentChangeEdit(fh, protocolEdits))
+	}
+	return changes, nil
+}
@@ -165,7 +165,7 @@ func packageReferences(ctx context.Context, snapshot *cache.Snapshot, uri protoc
 				if err != nil {
 					return nil, err
 				}
-				f, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+				f, err := snapshot.ParseHeader(ctx, fh)
 				if err != nil {
 					return nil, err
 				}
@@ -194,7 +194,7 @@ func packageReferences(ctx context.Context, snapshot *cache.Snapshot, uri protoc
 		if err != nil {
 			return nil, err
 		}
-		f, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+		f, err := snapshot.ParseHeader(ctx, fh)
 		if err != nil {
 			return nil, err
 		}
@@ -54,9 +54,12 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/ast/astutil"
@@ -65,8 +68,10 @@ import (
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	goplsastutil "golang.org/x/tools/gopls/internal/util/astutil"
 	"golang.org/x/tools/gopls/internal/util/bug"
 	"golang.org/x/tools/gopls/internal/util/safetoken"
@@ -322,26 +327,26 @@ func renameFuncSignature(ctx context.Context, snapshot *cache.Snapshot, f file.H
 		}
 	}
 
-	var newParams []int
+	// Parameters not found in the original signature are added, and
+	// parameters whose type changed are retyped (see [ChangeSignature]).
+	var newParams []command.ChangeSignatureParam
 	for name, field := range goplsastutil.FlatFields(newType.Params) {
 		if name == nil {
 			return nil, fmt.Errorf("need named fields")
 		}
-		info, ok := oldParams[name.Name]
-		if !ok {
-			return nil, fmt.Errorf("couldn't find name %s: adding parameters not yet supported", name)
-		}
-		if newType := types.ExprString(field.Type); newType != info.typ {
-			return nil, fmt.Errorf("changing types (%s to %s) not yet supported", info.typ, newType)
+		typ := types.ExprString(field.Type)
+		if info, ok := oldParams[name.Name]; ok && typ == info.typ {
+			newParams = append(newParams, command.ChangeSignatureParam{OldIndex: info.idx})
+		} else {
+			newParams = append(newParams, command.ChangeSignatureParam{NewField: name.Name + " " + typ})
 		}
-		newParams = append(newParams, info.idx)
 	}
 
 	rng, err := pgf.PosRange(ftyp.Func, ftyp.Func)
 	if err != nil {
 		return nil, err
 	}
-	changes, err := ChangeSignature(ctx, snapshot, pkg, pgf, rng, newParams)
+	changes, err := ChangeSignature(ctx, snapshot, pkg, pgf, rng, newParams, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -392,34 +397,46 @@ func checkRenamable(obj types.Object) error {
 // Rename returns a map of TextEdits for each file modified when renaming a
 // given identifier within a package and a boolean value of true for renaming
 // package and false otherwise.
-func Rename(ctx context.Context, snapshot *cache.Snapshot, f file.Handle, pp protocol.Position, newName string) (map[protocol.DocumentURI][]protocol.TextEdit, bool, error) {
+//
+// Renaming an exported symbol updates its references in all packages of
+// the workspace, including those of other modules of a go.work file. If
+// the renaming would also break packages outside the workspace that
+// cannot be edited (for example, a dependency in the module cache that
+// imports a workspace module), the resulting warning describes them.
+func Rename(ctx context.Context, snapshot *cache.Snapshot, f file.Handle, pp protocol.Position, newName string) (_ map[protocol.DocumentURI][]protocol.TextEdit, isPkgRenaming bool, warning string, _ error) {
 	ctx, done := event.Start(ctx, "golang.Rename")
 	defer done()
 
 	if edits, err := renameFuncSignature(ctx, snapshot, f, pp, newName); err != nil {
-		return nil, false, err
+		return nil, false, "", err
 	} else if edits != nil {
-		return edits, false, nil
+		return edits, false, "", nil
 	}
 
 	if !isValidIdentifier(newName) {
-		return nil, false, fmt.Errorf("invalid identifier to rename: %q", newName)
+		return nil, false, "", fmt.Errorf("invalid identifier to rename: %q", newName)
 	}
 
 	// Cursor within package name declaration?
 	_, inPackageName, err := parsePackageNameDecl(ctx, snapshot, f, pp)
 	if err != nil {
-		return nil, false, err
+		return nil, false, "", err
 	}
 
-	var editMap map[protocol.DocumentURI][]diff.Edit
+	var (
+		editMap  map[protocol.DocumentURI][]diff.Edit
+		external []PackagePath // packages outside the workspace broken by the renaming
+	)
 	if inPackageName {
 		editMap, err = renamePackageName(ctx, snapshot, f, PackageName(newName))
 	} else {
-		editMap, err = renameOrdinary(ctx, snapshot, f, pp, newName)
+		editMap, external, err = renameOrdinary(ctx, snapshot, f, pp, newName)
 	}
 	if err != nil {
-		return nil, false, err
+		return nil, false, "", err
+	}
+	if len(external) > 0 {
+		warning = externalImportersWarning(newName, external)
 	}
 
 	// Convert edits to protocol form.
@@ -454,25 +471,47 @@ func Rename(ctx context.Context, snapshot *cache.Snapshot, f file.Handle, pp pro
 		// vendor/k8s.io/kubectl -> ../../staging/src/k8s.io/kubectl.
 		fh, err := snapshot.ReadFile(ctx, uri)
 		if err != nil {
-			return nil, false, err
+			return nil, false, "", err
 		}
 		data, err := fh.Content()
 		if err != nil {
-			return nil, false, err
+			return nil, false, "", err
 		}
 		m := protocol.NewMapper(uri, data)
 		textedits, err := protocol.EditsFromDiffEdits(m, edits)
 		if err != nil {
-			return nil, false, err
+			return nil, false, "", err
 		}
 		result[uri] = textedits
 	}
 
-	return result, inPackageName, nil
+	return result, inPackageName, warning, nil
 }
 
-// renameOrdinary renames an ordinary (non-package) name throughout the workspace.
-func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle, pp protocol.Position, newName string) (map[protocol.DocumentURI][]diff.Edit, error) {
+// externalImportersWarning returns a message describing the packages
+// outside the workspace that will no longer compile after renaming a
+// symbol to newName.
+func externalImportersWarning(newName string, external []PackagePath) string {
+	const maxListed = 5 // maximum number of packages to list
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Renaming to %s will break %d package(s) outside the workspace that cannot be updated:", newName, len(external))
+	for i, path := range external {
+		if i == maxListed {
+			fmt.Fprintf(&buf, " and %d more", len(external)-maxListed)
+			break
+		}
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, " %s", path)
+	}
+	return buf.String()
+}
+
+// renameOrdinary renames an ordinary (non-package) name throughout the
+// workspace. It also returns the paths of packages outside the workspace
+// that refer to the renamed symbol, and so would be broken by the renaming.
+func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle, pp protocol.Position, newName string) (map[protocol.DocumentURI][]diff.Edit, []PackagePath, error) {
 	// Type-check the referring package and locate the object(s).
 	//
 	// Unlike NarrowestPackageForFile, this operation prefers the
@@ -485,29 +524,29 @@ func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle
 	{
 		mps, err := snapshot.MetadataForFile(ctx, f.URI())
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		metadata.RemoveIntermediateTestVariants(&mps)
 		if len(mps) == 0 {
-			return nil, fmt.Errorf("no package metadata for file %s", f.URI())
+			return nil, nil, fmt.Errorf("no package metadata for file %s", f.URI())
 		}
 		widest := mps[len(mps)-1] // widest variant may include _test.go files
 		pkgs, err := snapshot.TypeCheck(ctx, widest.ID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		pkg = pkgs[0]
 		pgf, err := pkg.File(f.URI())
 		if err != nil {
-			return nil, err // "can't happen"
+			return nil, nil, err // "can't happen"
 		}
 		pos, err := pgf.PositionPos(pp)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		objects, _, err := objectsAt(pkg.TypesInfo(), pgf.File, pos)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		targets = objects
 	}
@@ -519,10 +558,10 @@ func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle
 		break
 	}
 	if obj.Name() == newName {
-		return nil, fmt.Errorf("old and new names are the same: %s", newName)
+		return nil, nil, fmt.Errorf("old and new names are the same: %s", newName)
 	}
 	if err := checkRenamable(obj); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Find objectpath, if object is exported ("" otherwise).
@@ -572,7 +611,13 @@ func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle
 			objects = append(objects, obj)
 		}
 		editMap, _, err := renameObjects(newName, pkg, objects...)
-		return editMap, err
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := renameTests(ctx, snapshot, pkg.FileSet(), obj, newName, editMap); err != nil {
+			return nil, nil, err
+		}
+		return editMap, nil, nil
 	}
 
 	// Exported: search globally.
@@ -624,12 +669,189 @@ func renameOrdinary(ctx context.Context, snapshot *cache.Snapshot, f file.Handle
 	declURI := protocol.URIFromPath(pkg.FileSet().File(obj.Pos()).Name())
 	pkgs, err := typeCheckReverseDependencies(ctx, snapshot, declURI, transitive)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Apply the renaming to the (initial) object.
 	declPkgPath := PackagePath(obj.Pkg().Path())
-	return renameExported(pkgs, declPkgPath, declObjPath, newName)
+	editMap, err := renameExported(pkgs, declPkgPath, declObjPath, newName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The reverse dependencies may include packages outside the
+	// workspace, such as a dependency in the module cache that
+	// imports one of the modules of a go.work file. Such packages
+	// cannot be edited, so discard their edits and report them.
+	var external []PackagePath
+	for _, pkg := range pkgs {
+		mp := pkg.Metadata()
+		if mp.PkgPath == declPkgPath || snapshot.IsWorkspacePackage(mp.ID) {
+			continue
+		}
+		for _, pgf := range pkg.CompiledGoFiles() {
+			if _, ok := editMap[pgf.URI]; ok {
+				delete(editMap, pgf.URI)
+				external = append(external, mp.PkgPath)
+			}
+		}
+	}
+	slices.Sort(external)
+	external = slices.Compact(external)
+
+	if err := renameTests(ctx, snapshot, pkg.FileSet(), obj, newName, editMap); err != nil {
+		return nil, nil, err
+	}
+
+	return editMap, external, nil
+}
+
+// renameTests adds to editMap the edits that rename the tests named
+// after obj, a function, method, or type declared in a non-test file,
+// to match its new name, such as TestFoo, TestFooError, BenchmarkFoo,
+// FuzzFoo, and ExampleFoo for a function Foo, or TestT_M for a method
+// T.M. Within such a test, it also renames the direct subtests whose
+// literal names begin with the old name, as in t.Run("Foo", ...).
+//
+// A test is not renamed if its new name is already taken.
+func renameTests(ctx context.Context, snapshot *cache.Snapshot, fset *token.FileSet, obj types.Object, newName string, editMap map[protocol.DocumentURI][]diff.Edit) error {
+	if obj.Pkg() == nil || strings.HasSuffix(fset.File(obj.Pos()).Name(), "_test.go") {
+		return nil
+	}
+	var subject string // "F", "T", or "T.M"; see [testfuncs.Subjects]
+	switch obj := obj.(type) {
+	case *types.Func:
+		if recv := obj.Signature().Recv(); recv != nil {
+			if _, named := typesinternal.ReceiverNamed(recv); named != nil {
+				subject = named.Obj().Name() + "." + obj.Name()
+			}
+		} else if obj.Parent() == obj.Pkg().Scope() {
+			subject = obj.Name()
+		}
+	case *types.TypeName:
+		if obj.Parent() == obj.Pkg().Scope() {
+			subject = obj.Name()
+		}
+	}
+	if subject == "" {
+		return nil
+	}
+
+	rel, err := snapshot.TestRelation(ctx, PackagePath(obj.Pkg().Path()))
+	if err != nil {
+		return err
+	}
+	taken := make(map[string]bool)
+	for _, test := range rel.Tests() {
+		taken[test.Name] = true
+	}
+	for _, test := range rel.Tests() {
+		testName, ok := renamedTestName(test, subject, newName)
+		if !ok || taken[testName] {
+			continue
+		}
+		taken[testName] = true
+
+		fh, err := snapshot.ReadFile(ctx, test.Location.URI)
+		if err != nil {
+			return err
+		}
+		pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+		if err != nil {
+			return err
+		}
+		edit := func(pos token.Pos, n int, new string) error {
+			start, err := safetoken.Offset(pgf.Tok, pos)
+			if err != nil {
+				return err
+			}
+			editMap[pgf.URI] = append(editMap[pgf.URI], diff.Edit{Start: start, End: start + n, New: new})
+			return nil
+		}
+		for _, decl := range pgf.File.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil || decl.Name.Name != test.Name {
+				continue
+			}
+			if err := edit(decl.Name.Pos(), len(test.Name), testName); err != nil {
+				return err
+			}
+			if decl.Body == nil {
+				break
+			}
+			for _, stmt := range decl.Body.List {
+				// t.Run("Foo...", ...)
+				stmt, ok := stmt.(*ast.ExprStmt)
+				if !ok {
+					continue
+				}
+				call, ok := stmt.X.(*ast.CallExpr)
+				if !ok || len(call.Args) != 2 {
+					continue
+				}
+				if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Run" {
+					continue
+				}
+				lit, ok := call.Args[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				// An identifier needs no escaping, so the old name
+				// appears verbatim after the opening quote.
+				rest, ok := strings.CutPrefix(lit.Value[1:], obj.Name())
+				if !ok {
+					continue
+				}
+				if r, _ := utf8.DecodeRuneInString(rest); unicode.IsLetter(r) && !unicode.IsUpper(r) {
+					continue // a longer word, as in "Parser"
+				}
+				if err := edit(lit.Pos()+1, len(obj.Name()), newName); err != nil {
+					return err
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// renamedTestName returns the new name of test if it is named after
+// its subject, "F", "T", or "T.M", which is being renamed to newName.
+func renamedTestName(test testfuncs.Result, subject, newName string) (string, bool) {
+	if len(test.Subjects) == 0 {
+		return "", false
+	}
+	// The first subject is the one after which the test is named, if any.
+	if first := test.Subjects[0]; first != subject && !strings.HasPrefix(first, subject+".") {
+		return "", false
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		rest, ok := strings.CutPrefix(test.Name, prefix)
+		if !ok {
+			continue
+		}
+		if r, ok := strings.CutPrefix(rest, "_"); ok {
+			prefix, rest = prefix+"_", r // Test_f tests an unexported f
+		}
+		old, before := subject, ""
+		if typ, method, ok := strings.Cut(subject, "."); ok {
+			r, ok := strings.CutPrefix(rest, typ+"_")
+			if !ok {
+				return "", false
+			}
+			old, before, rest = method, typ+"_", r
+		}
+		word, _, _ := strings.Cut(rest, "_")
+		if !testfuncs.IsSubjectPrefix(word, old) {
+			return "", false
+		}
+		// TestFoo must become Test_foo, not Testfoo, which is not a test.
+		if r, _ := utf8.DecodeRuneInString(newName); before == "" && !strings.HasSuffix(prefix, "_") && unicode.IsLower(r) {
+			prefix += "_"
+		}
+		return prefix + before + newName + rest[len(old):], true
+	}
+	return "", false
 }
 
 // typeCheckReverseDependencies returns the type-checked packages for
@@ -977,7 +1199,7 @@ func renamePackageClause(ctx context.Context, mp *metadata.Package, snapshot *ca
 		if err != nil {
 			return err
 		}
-		f, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+		f, err := snapshot.ParseHeader(ctx, fh)
 		if err != nil {
 			return err
 		}
@@ -1018,7 +1240,7 @@ func renameImports(ctx context.Context, snapshot *cache.Snapshot, mp *metadata.P
 			if err != nil {
 				return err
 			}
-			f, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+			f, err := snapshot.ParseHeader(ctx, fh)
 			if err != nil {
 				return err
 			}
@@ -1590,11 +1812,11 @@ func (r *renamer) updatePkgName(pgf *parsego.File, pkgName *types.PkgName) (diff
 //
 // Note: also used by references.
 func parsePackageNameDecl(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, ppos protocol.Position) (*parsego.File, bool, error) {
-	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+	pgf, err := snapshot.ParseHeader(ctx, fh)
 	if err != nil {
 		return nil, false, err
 	}
-	// Careful: because we used parsego.Header,
+	// Careful: because pgf may be a parse of the header only,
 	// pgf.Pos(ppos) may be beyond EOF => (0, err).
 	pos, _ := pgf.PositionPos(ppos)
 	return pgf, pgf.File.Name.Pos() <= pos && pos <= pgf.File.Name.End(), nil
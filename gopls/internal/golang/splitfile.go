@@ -0,0 +1,480 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Split file into N files".
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// A splitUnit is a set of top-level declarations that are moved
+// together: a type declaration and the methods of its types, or a
+// single other declaration.
+type splitUnit struct {
+	decls []ast.Decl
+	refs  map[*splitUnit]bool // units whose declarations this one refers to
+	group *splitGroup         // nil if the unit stays in the original file
+}
+
+// isInit reports whether u is an init function, whose order relative
+// to those of other files must be preserved.
+func (u *splitUnit) isInit() bool {
+	decl, ok := u.decls[0].(*ast.FuncDecl)
+	return ok && decl.Recv == nil && decl.Name.Name == "init"
+}
+
+// A splitGroup is a set of units that are moved to the same file,
+// named after the type that anchors it.
+type splitGroup struct {
+	name  string
+	units []*splitUnit
+}
+
+// splitFileGroups partitions the top-level declarations of pgf, other
+// than imports, into groups: one for each type declaration, holding
+// the type, its methods, and each other declaration that belongs with
+// them because it refers only to that group or is referred to only by
+// it. Init functions belong to no group. It returns the groups to move
+// to new files, and the units that stay in pgf, or no groups if the
+// file cannot be split.
+func splitFileGroups(pkg *cache.Package, pgf *parsego.File) ([]*splitGroup, []*splitUnit) {
+	if ast.IsGenerated(pgf.File) {
+		return nil, nil
+	}
+	for _, spec := range pgf.File.Imports {
+		if spec.Path.Value == `"C"` || spec.Name != nil && spec.Name.Name == "." {
+			return nil, nil // cgo preambles and dot imports are not supported
+		}
+	}
+	info := pkg.TypesInfo()
+
+	// Form the units.
+	var (
+		units     []*splitUnit
+		typeUnits []*splitUnit
+		unitOf    = make(map[types.Object]*splitUnit) // unit that declares each object
+	)
+	define := func(u *splitUnit, id *ast.Ident) {
+		if obj := info.Defs[id]; obj != nil {
+			unitOf[obj] = u
+		}
+	}
+	for _, decl := range pgf.File.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.IMPORT {
+			continue
+		}
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv != nil {
+			continue // see below
+		}
+		u := &splitUnit{decls: []ast.Decl{decl}, refs: make(map[*splitUnit]bool)}
+		units = append(units, u)
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			define(u, decl.Name)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					define(u, spec.Name)
+				case *ast.ValueSpec:
+					for _, id := range spec.Names {
+						define(u, id)
+					}
+				}
+			}
+			if decl.Tok == token.TYPE {
+				typeUnits = append(typeUnits, u)
+			}
+		}
+	}
+	// Methods belong to the unit of their receiver type, if any.
+	for _, decl := range pgf.File.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv != nil {
+			fn, _ := info.Defs[decl.Name].(*types.Func)
+			if fn == nil {
+				return nil, nil // ill-typed
+			}
+			var u *splitUnit
+			if _, named := typesinternal.ReceiverNamed(fn.Signature().Recv()); named != nil {
+				u = unitOf[named.Obj()]
+			}
+			if u == nil {
+				u = &splitUnit{refs: make(map[*splitUnit]bool)}
+				units = append(units, u)
+			}
+			u.decls = append(u.decls, decl)
+			define(u, decl.Name)
+		}
+	}
+
+	// Record the references between units.
+	referrers := make(map[*splitUnit][]*splitUnit)
+	for _, u := range units {
+		for _, decl := range u.decls {
+			ast.Inspect(decl, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					if v := unitOf[info.Uses[id]]; v != nil && v != u && !u.refs[v] {
+						u.refs[v] = true
+						referrers[v] = append(referrers[v], u)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	// Each type anchors a group.
+	var groups []*splitGroup
+	for _, u := range typeUnits {
+		g := &splitGroup{name: u.decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Name.Name}
+		u.group = g
+		groups = append(groups, g)
+	}
+	// A function that returns values of one of the types belongs with
+	// it, as in go/doc.
+	for _, u := range units {
+		if decl, ok := u.decls[0].(*ast.FuncDecl); ok && decl.Recv == nil && !u.isInit() {
+			var g *splitGroup
+			fn := info.Defs[decl.Name].(*types.Func)
+			for v := range fn.Signature().Results().Variables() {
+				t := v.Type()
+				if ptr, ok := t.(*types.Pointer); ok {
+					t = ptr.Elem()
+				}
+				if named, ok := t.(*types.Named); ok {
+					if v := unitOf[named.Obj()]; v != nil && v.group != nil {
+						if g != nil && g != v.group {
+							g = nil
+							break
+						}
+						g = v.group
+					}
+				}
+			}
+			u.group = g
+		}
+	}
+
+	// Add each other unit to the group g if all its references to
+	// other units are to g, or if it refers to no other unit and all
+	// the references to it are from g, until no more can be added.
+	const unassigned = "" // pseudo-group of units not (yet) in a group
+	groupName := func(u *splitUnit) string {
+		if u.group != nil {
+			return u.group.name
+		}
+		return unassigned
+	}
+	// only returns the group in m, if it is the only one.
+	only := func(m map[string]*splitGroup) *splitGroup {
+		if len(m) == 1 {
+			for _, g := range m {
+				return g
+			}
+		}
+		return nil
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, u := range units {
+			if u.group != nil || u.isInit() {
+				continue
+			}
+			out := make(map[string]*splitGroup)
+			for v := range u.refs {
+				out[groupName(v)] = v.group
+			}
+			in := make(map[string]*splitGroup)
+			for _, v := range referrers[u] {
+				in[groupName(v)] = v.group
+			}
+			g := only(out)
+			if len(out) == 0 {
+				g = only(in)
+			}
+			if g != nil {
+				u.group = g
+				changed = true
+			}
+		}
+	}
+
+	// The group named after the file, if any, stays in the file, as do
+	// the units in no group, or if there are none, the first group.
+	base := filepath.Base(pgf.URI.Path())
+	var stay *splitGroup
+	for _, g := range groups {
+		if strings.ToLower(g.name)+splitFileSuffix(pgf)+".go" == base {
+			stay = g
+		}
+	}
+	var rest []*splitUnit
+	for _, u := range units {
+		if u.group != nil && u.group != stay {
+			u.group.units = append(u.group.units, u)
+		} else {
+			rest = append(rest, u)
+		}
+	}
+	if len(rest) == 0 && len(groups) > 0 {
+		stay = groups[0]
+		rest = stay.units
+	}
+	groups = slices.DeleteFunc(groups, func(g *splitGroup) bool { return g == stay })
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	return groups, rest
+}
+
+// splitFileSuffix returns the suffix of the base name of the files
+// split from pgf: "_test" for a test file.
+func splitFileSuffix(pgf *parsego.File) string {
+	if strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+		return "_test"
+	}
+	return ""
+}
+
+// SplitFile moves the declarations of each group of the file (see
+// [splitFileGroups]) into a new file named after the group, with the
+// same copyright header, build constraint, and package clause as the
+// original, and the imports it needs. Declarations that belong to no
+// group stay in the original file.
+func SplitFile(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	groups, rest := splitFileGroups(pkg, pgf)
+	if groups == nil {
+		return nil, fmt.Errorf("file cannot be split")
+	}
+
+	// Prepare the header of each new file.
+	var header bytes.Buffer
+	if c := copyrightComment(pgf.File); c != nil {
+		start, end, err := pgf.NodeOffsets(c)
+		if err != nil {
+			return nil, err
+		}
+		header.Write(pgf.Src[start:end])
+		header.WriteString("\n\n")
+	}
+	if c := buildConstraintComment(pgf.File); c != nil {
+		start, end, err := pgf.NodeOffsets(c)
+		if err != nil {
+			return nil, err
+		}
+		header.Write(pgf.Src[start:end])
+		header.WriteString("\n\n")
+	}
+	fmt.Fprintf(&header, "package %s\n", pgf.File.Name.Name)
+
+	var (
+		info    = pkg.TypesInfo()
+		changes []protocol.DocumentChange
+		deletes []protocol.TextEdit // edits to the original file
+		taken   = make(map[string]bool)
+	)
+	for _, g := range groups {
+		var buf bytes.Buffer
+		buf.Write(header.Bytes())
+		used := pkgNamesUsed(info, g.units)
+		if len(used) > 0 {
+			buf.WriteString("import (\n")
+			for _, spec := range pgf.File.Imports {
+				if used[info.PkgNameOf(spec)] {
+					if spec.Name != nil {
+						fmt.Fprintf(&buf, "%s %s\n", spec.Name.Name, spec.Path.Value)
+					} else {
+						fmt.Fprintf(&buf, "%s\n", spec.Path.Value)
+					}
+				}
+			}
+			buf.WriteString(")\n")
+		}
+
+		var decls []ast.Decl
+		for _, u := range g.units {
+			decls = append(decls, u.decls...)
+		}
+		sort.Slice(decls, func(i, j int) bool { return decls[i].Pos() < decls[j].Pos() })
+		for _, decl := range decls {
+			start, end, err := splitDeclOffsets(pgf, decl)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString("\n")
+			buf.Write(pgf.Src[start:end])
+			buf.WriteString("\n")
+
+			edit, err := splitDeletion(pgf, start, end)
+			if err != nil {
+				return nil, err
+			}
+			deletes = append(deletes, edit)
+		}
+		content, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		newFile, err := chooseSplitFile(ctx, snapshot, pgf.URI.DirPath(), strings.ToLower(g.name)+splitFileSuffix(pgf), taken)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes,
+			protocol.DocumentChangeCreate(newFile.URI()),
+			protocol.DocumentChangeEdit(newFile, []protocol.TextEdit{{NewText: string(content)}}))
+	}
+
+	// Delete the imports that the original file no longer needs,
+	// or their entire declarations.
+	used := pkgNamesUsed(info, rest)
+	for _, decl := range pgf.File.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		var unused []ast.Node
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ImportSpec)
+			if pkgname := info.PkgNameOf(spec); pkgname != nil && !used[pkgname] &&
+				(spec.Name == nil || spec.Name.Name != "_") {
+				unused = append(unused, spec)
+			}
+		}
+		if len(unused) == len(decl.Specs) {
+			unused = []ast.Node{decl}
+		}
+		for _, n := range unused {
+			start, end, err := safetoken.Offsets(pgf.Tok, n.Pos(), n.End())
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := n.(*ast.ImportSpec); ok {
+				// Delete the spec's line, if it has no other specs.
+				lineStart, err := safetoken.Offset(pgf.Tok, pgf.Tok.LineStart(safetoken.Line(pgf.Tok, n.Pos())))
+				if err != nil {
+					return nil, err
+				}
+				if eol := bytes.IndexByte(pgf.Src[end:], '\n'); eol >= 0 &&
+					len(bytes.TrimSpace(pgf.Src[lineStart:start])) == 0 &&
+					len(bytes.TrimSpace(pgf.Src[end:end+eol])) == 0 {
+					start, end = lineStart, end+eol+1
+				}
+				rng, err := pgf.Mapper.OffsetRange(start, end)
+				if err != nil {
+					return nil, err
+				}
+				deletes = append(deletes, protocol.TextEdit{Range: rng})
+				continue
+			}
+			edit, err := splitDeletion(pgf, start, end)
+			if err != nil {
+				return nil, err
+			}
+			deletes = append(deletes, edit)
+		}
+	}
+	return append([]protocol.DocumentChange{protocol.DocumentChangeEdit(fh, deletes)}, changes...), nil
+}
+
+// splitDeletion returns an edit that deletes the text of pgf between
+// the offsets start and end, and the space after it.
+func splitDeletion(pgf *parsego.File, start, end int) (protocol.TextEdit, error) {
+	rest := pgf.Src[end:]
+	end += len(rest) - len(bytes.TrimLeft(rest, " \t\n"))
+	rng, err := pgf.Mapper.OffsetRange(start, end)
+	if err != nil {
+		return protocol.TextEdit{}, err
+	}
+	return protocol.TextEdit{Range: rng}, nil
+}
+
+// pkgNamesUsed returns the set of imported package names used by the
+// declarations of units.
+func pkgNamesUsed(info *types.Info, units []*splitUnit) map[*types.PkgName]bool {
+	used := make(map[*types.PkgName]bool)
+	for _, u := range units {
+		for _, decl := range u.decls {
+			ast.Inspect(decl, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok {
+					if pkgname, ok := info.Uses[id].(*types.PkgName); ok {
+						used[pkgname] = true
+					}
+				}
+				return true
+			})
+		}
+	}
+	return used
+}
+
+// splitDeclOffsets returns the offsets of the extent of decl in pgf,
+// including its doc comment and any comment on the line where it ends.
+func splitDeclOffsets(pgf *parsego.File, decl ast.Decl) (int, int, error) {
+	start, end := decl.Pos(), decl.End()
+	var doc *ast.CommentGroup
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		doc = decl.Doc
+	case *ast.GenDecl:
+		doc = decl.Doc
+	}
+	if doc != nil {
+		start = doc.Pos()
+	}
+	line := safetoken.Line(pgf.Tok, end)
+	for _, c := range pgf.File.Comments {
+		if c.Pos() >= end && safetoken.Line(pgf.Tok, c.Pos()) == line {
+			end = c.End()
+			break
+		}
+	}
+	return safetoken.Offsets(pgf.Tok, start, end)
+}
+
+// chooseSplitFile chooses a new file in dir named after basename, with
+// a numeric suffix if necessary to disambiguate it from existing
+// files, and from the names already taken.
+func chooseSplitFile(ctx context.Context, snapshot *cache.Snapshot, dir, basename string, taken map[string]bool) (file.Handle, error) {
+	name := basename + ".go"
+	for count := 1; count < 5; count++ {
+		if !taken[name] {
+			fh, err := snapshot.ReadFile(ctx, protocol.URIFromPath(filepath.Join(dir, name)))
+			if err != nil {
+				return nil, err // canceled
+			}
+			if _, err := fh.Content(); errors.Is(err, os.ErrNotExist) {
+				taken[name] = true
+				return fh, nil
+			}
+		}
+		name = fmt.Sprintf("%s.%d.go", basename, count)
+	}
+	return nil, fmt.Errorf("chooseSplitFile: exceeded retry limit")
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Generate String method for T",
+// an in-editor alternative to running the stringer tool.
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+)
+
+// enumTypeAt returns the named integer type of the constants declared
+// by the const declaration enclosing [start, end), provided it is
+// declared in the current package and has no String method.
+// If the declaration has constants of several such types, the type
+// of the selected spec, or failing that the first one, is returned.
+func enumTypeAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) *types.Named {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil
+	}
+	curDecl := curSel
+	if !is[*ast.GenDecl](curDecl.Node()) {
+		for cur := range curSel.Ancestors((*ast.GenDecl)(nil)) {
+			curDecl = cur
+			break
+		}
+	}
+	decl, ok := curDecl.Node().(*ast.GenDecl)
+	if !ok || decl.Tok != token.CONST {
+		return nil
+	}
+	// Only package-level declarations may define an enum.
+	if !is[*ast.File](curDecl.Parent().Node()) {
+		return nil
+	}
+
+	var enum *types.Named
+	for _, spec := range decl.Specs {
+		for _, id := range spec.(*ast.ValueSpec).Names {
+			if named := enumType(pkg.Types(), pkg.TypesInfo().Defs[id]); named != nil {
+				if enum == nil || posRangeContains(spec.Pos(), spec.End(), start, end) {
+					enum = named
+				}
+			}
+		}
+	}
+	return enum
+}
+
+// enumType returns the type of the constant obj if it is a named
+// integer type declared in pkg without a String method.
+func enumType(pkg *types.Package, obj types.Object) *types.Named {
+	c, ok := obj.(*types.Const)
+	if !ok || c.Name() == "_" {
+		return nil
+	}
+	named, ok := types.Unalias(c.Type()).(*types.Named)
+	if !ok || named.Obj().Pkg() != pkg || named.TypeParams() != nil {
+		return nil
+	}
+	if basic, ok := named.Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 {
+		return nil
+	}
+	if m, _, _ := types.LookupFieldOrMethod(named, true, pkg, "String"); m != nil {
+		return nil
+	}
+	return named
+}
+
+// AddStringMethod generates, in a new file named after the enum type
+// T of the selected const declaration, a String method for T that
+// returns the name of each constant of type T.
+func AddStringMethod(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) ([]protocol.DocumentChange, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	enum := enumTypeAt(pkg, pgf, start, end)
+	if enum == nil {
+		return nil, fmt.Errorf("no enum type declared by selected constants")
+	}
+
+	// Gather the package-level constants of the enum type,
+	// ordered by value. As with stringer, when several constants
+	// have the same value, the first one declared gives its name.
+	scope := pkg.Types().Scope()
+	var consts []*types.Const
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(c.Type(), enum) {
+			consts = append(consts, c)
+		}
+	}
+	// Package-level constants may be declared in different files;
+	// order those of equal value by file name and then offset.
+	posns := make(map[*types.Const]token.Position, len(consts))
+	for _, c := range consts {
+		posns[c] = safetoken.StartPosition(pkg.FileSet(), c.Pos())
+	}
+	slices.SortFunc(consts, func(x, y *types.Const) int {
+		if constant.Compare(x.Val(), token.LSS, y.Val()) {
+			return -1
+		} else if constant.Compare(x.Val(), token.GTR, y.Val()) {
+			return +1
+		}
+		px, py := posns[x], posns[y]
+		return cmp.Or(strings.Compare(px.Filename, py.Filename), cmp.Compare(px.Offset, py.Offset))
+	})
+	consts = slices.CompactFunc(consts, func(x, y *types.Const) bool {
+		return constant.Compare(x.Val(), token.EQL, y.Val())
+	})
+
+	// Choose a receiver name that doesn't shadow any of the constants.
+	recv := "i"
+	for i := 0; scope.Lookup(recv) != nil; i++ {
+		recv = fmt.Sprintf("i%d", i)
+	}
+
+	var buf bytes.Buffer
+	if c := copyrightComment(pgf.File); c != nil {
+		start, end, err := pgf.NodeOffsets(c)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(pgf.Src[start:end])
+		buf.WriteString("\n\n")
+	}
+	if c := buildConstraintComment(pgf.File); c != nil {
+		start, end, err := pgf.NodeOffsets(c)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(pgf.Src[start:end])
+		buf.WriteString("\n\n")
+	}
+
+	name := enum.Obj().Name()
+	format1, conv := "FormatInt", "int64"
+	if enum.Underlying().(*types.Basic).Info()&types.IsUnsigned != 0 {
+		format1, conv = "FormatUint", "uint64"
+	}
+	fmt.Fprintf(&buf, "package %s\n\n", pgf.File.Name.Name)
+	fmt.Fprintf(&buf, "import \"strconv\"\n\n")
+	fmt.Fprintf(&buf, "func (%s %s) String() string {\n", recv, name)
+	fmt.Fprintf(&buf, "switch %s {\n", recv)
+	for _, c := range consts {
+		fmt.Fprintf(&buf, "case %s:\nreturn %q\n", c.Name(), c.Name())
+	}
+	fmt.Fprintf(&buf, "default:\nreturn \"%s(\" + strconv.%s(%s(%s), 10) + \")\"\n", name, format1, conv, recv)
+	fmt.Fprintf(&buf, "}\n}\n")
+
+	newFileContent, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	newFile, err := chooseNewFile(ctx, snapshot, pgf.URI.DirPath(), name+"_string")
+	if err != nil {
+		return nil, fmt.Errorf("AddStringMethod: %w", err)
+	}
+	return []protocol.DocumentChange{
+		protocol.DocumentChangeCreate(newFile.URI()),
+		protocol.DocumentChangeEdit(newFile, []protocol.TextEdit{
+			{Range: protocol.Range{}, NewText: string(newFileContent)},
+		}),
+	}, nil
+}
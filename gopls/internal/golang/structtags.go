@@ -0,0 +1,117 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the refactor.rewrite.addStructTags.* code actions,
+// which add or update the json, yaml, or db tags of struct fields.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/gopls/internal/util/structtag"
+	"golang.org/x/tools/internal/diff"
+)
+
+// structTypeAt returns the innermost struct type enclosing [start,
+// end), or the struct type of the type declaration whose name is
+// selected, provided it has at least one field that can be tagged.
+func structTypeAt(pgf *parsego.File, start, end token.Pos) *ast.StructType {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil
+	}
+	n := curSel.Node()
+	if !is[*ast.StructType](n) && !is[*ast.TypeSpec](n) {
+		n = nil
+		for cur := range curSel.Ancestors((*ast.StructType)(nil), (*ast.TypeSpec)(nil)) {
+			n = cur.Node()
+			break
+		}
+	}
+	var st *ast.StructType
+	switch n := n.(type) {
+	case *ast.StructType:
+		st = n
+	case *ast.TypeSpec:
+		st, _ = n.Type.(*ast.StructType)
+	}
+	if st == nil || len(taggableFields(st)) == 0 {
+		return nil
+	}
+	return st
+}
+
+// taggableFields returns the fields of st that declare a single
+// non-blank name, and thus may be given a tag derived from it.
+func taggableFields(st *ast.StructType) []*ast.Field {
+	var fields []*ast.Field
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 1 && field.Names[0].Name != "_" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// addStructTags returns a fixer that adds a tag with the specified
+// key to each field of the selected struct type, or updates the
+// existing one, according to the StructTagCase and StructTagOmitEmpty
+// options.
+func addStructTags(key string) fixer {
+	return func(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+		st := structTypeAt(pgf, start, end)
+		if st == nil {
+			return nil, nil, fmt.Errorf("no struct type selected")
+		}
+		opts := snapshot.Options()
+
+		var edits []diff.Edit
+		for _, field := range taggableFields(st) {
+			name := structtag.Name(field.Names[0].Name, structtag.Case(opts.StructTagCase))
+			if field.Tag == nil {
+				offset, err := safetoken.Offset(pgf.Tok, field.Type.End())
+				if err != nil {
+					return nil, nil, err
+				}
+				tag := structtag.Update("", key, name, opts.StructTagOmitEmpty)
+				edits = append(edits, diff.Edit{Start: offset, End: offset, New: " `" + tag + "`"})
+				continue
+			}
+			old, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue // malformed tag
+			}
+			tag := structtag.Update(old, key, name, opts.StructTagOmitEmpty)
+			if tag == old {
+				continue
+			}
+			lit := strconv.Quote(tag)
+			if strings.HasPrefix(field.Tag.Value, "`") && !strings.Contains(tag, "`") {
+				lit = "`" + tag + "`"
+			}
+			startOffset, endOffset, err := safetoken.Offsets(pgf.Tok, field.Tag.Pos(), field.Tag.End())
+			if err != nil {
+				return nil, nil, err
+			}
+			edits = append(edits, diff.Edit{Start: startOffset, End: endOffset, New: lit})
+		}
+
+		// Reformat the struct so that the tags are aligned.
+		textEdits, err := formatEditsWithin(pgf, edits, st)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: textEdits}, nil
+	}
+}
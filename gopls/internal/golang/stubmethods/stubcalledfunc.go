@@ -105,8 +105,8 @@ func (si *CallStubInfo) Emit(out *bytes.Buffer, qual types.Qualifier) error {
 
 	// Choose receiver name.
 	// If any method has a named receiver, choose the first one.
-	// Otherwise, use lowercase for the first letter of the object.
-	recvName := strings.ToLower(fmt.Sprintf("%.1s", recv.Name()))
+	// Otherwise, derive a name from the type.
+	recvName := typesutil.VarName(si.Receiver, nil)
 	if named, ok := types.Unalias(si.Receiver).(*types.Named); ok {
 		for i := 0; i < named.NumMethods(); i++ {
 			if recv := named.Method(i).Type().(*types.Signature).Recv(); recv.Name() != "" {
@@ -161,8 +161,8 @@ func (si *IfaceStubInfo) Emit(out *bytes.Buffer, qual types.Qualifier) error {
 	}
 
 	// If there are any that have named receiver, choose the first one.
-	// Otherwise, use lowercase for the first letter of the object.
-	rn := strings.ToLower(si.Concrete.Obj().Name()[0:1])
+	// Otherwise, derive a name from the type.
+	rn := typesutil.VarName(si.Concrete, nil)
 	if named, ok := types.Unalias(si.Concrete).(*types.Named); ok {
 		for i := 0; i < named.NumMethods(); i++ {
 			if recv := named.Method(i).Type().(*types.Signature).Recv(); recv.Name() != "" {
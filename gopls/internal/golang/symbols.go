@@ -10,6 +10,8 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"strconv"
+	"strings"
 
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
@@ -46,6 +48,9 @@ func DocumentSymbols(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 				if decl.Recv != nil && len(decl.Recv.List) > 0 {
 					fs.Name = fmt.Sprintf("(%s).%s", types.ExprString(decl.Recv.List[0].Type), fs.Name)
 				}
+				if strings.HasSuffix(fh.URI().Path(), "_test.go") {
+					fs.Children = subtestSymbols(pgf.Mapper, pgf.Tok, decl)
+				}
 				symbols = append(symbols, fs)
 			}
 		case *ast.GenDecl:
@@ -342,3 +347,294 @@ func varSymbol(m *protocol.Mapper, tf *token.File, spec *ast.ValueSpec, name *as
 	}
 	return s, nil
 }
+
+// subtestSymbols returns the symbols of the subtests of the Test or
+// Benchmark function decl whose names are statically determinable:
+// those of each call t.Run(name, f) whose name is a string literal,
+// and of each case of a table of test cases over whose elements such
+// a call ranges, as in:
+//
+//	tests := []struct{ name string; ... }{{name: "empty"}, ...}
+//	for _, tt := range tests {
+//		t.Run(tt.name, func(t *testing.T) { ... })
+//	}
+//
+// The analysis is purely syntactic, as the file is not type-checked.
+func subtestSymbols(m *protocol.Mapper, tf *token.File, decl *ast.FuncDecl) []protocol.DocumentSymbol {
+	if decl.Recv != nil || decl.Body == nil ||
+		!testRe.MatchString(decl.Name.Name) && !benchmarkRe.MatchString(decl.Name.Name) {
+		return nil
+	}
+	t := testingParam(decl.Type)
+	if t == nil {
+		return nil
+	}
+	return subtests(m, tf, decl.Body, t.Name)
+}
+
+// subtests returns the symbols of the subtests started by calls to the
+// Run method of the variable named t within body.
+func subtests(m *protocol.Mapper, tf *token.File, body *ast.BlockStmt, t string) []protocol.DocumentSymbol {
+	var (
+		symbols []protocol.DocumentSymbol
+		stack   []ast.Node
+	)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			stack = append(stack, n)
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Run" || !isIdent(sel.X, t) {
+			stack = append(stack, n)
+			return true
+		}
+
+		// The subtests of a subtest are those of its function literal.
+		var children func() []protocol.DocumentSymbol
+		if lit, ok := call.Args[1].(*ast.FuncLit); ok {
+			if t := testingParam(lit.Type); t != nil {
+				children = func() []protocol.DocumentSymbol { return subtests(m, tf, lit.Body, t.Name) }
+			}
+		}
+
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if s, ok := subtestSymbol(m, tf, lit, call); ok {
+				if children != nil {
+					s.Children = children()
+				}
+				symbols = append(symbols, s)
+			}
+		} else {
+			for _, c := range tableCases(stack, call.Args[0]) {
+				if s, ok := subtestSymbol(m, tf, c.name, c.node); ok {
+					symbols = append(symbols, s)
+				}
+			}
+		}
+		return false // the function literal was visited above
+	})
+	return symbols
+}
+
+// subtestSymbol returns the symbol of the subtest named by the string
+// literal name and declared by node.
+func subtestSymbol(m *protocol.Mapper, tf *token.File, name *ast.BasicLit, node ast.Node) (protocol.DocumentSymbol, bool) {
+	value, err := strconv.Unquote(name.Value)
+	if err != nil || value == "" {
+		return protocol.DocumentSymbol{}, false
+	}
+	s := protocol.DocumentSymbol{
+		Name: value,
+		Kind: protocol.Function,
+	}
+	if s.Range, err = m.NodeRange(tf, node); err != nil {
+		return protocol.DocumentSymbol{}, false
+	}
+	if s.SelectionRange, err = m.NodeRange(tf, name); err != nil {
+		return protocol.DocumentSymbol{}, false
+	}
+	return s, true
+}
+
+// A testCase is a case of a table of test cases.
+type testCase struct {
+	name *ast.BasicLit // the literal name of the case
+	node ast.Node      // the element of the table declaring the case
+}
+
+// tableCases returns the cases of the table of test cases named by
+// the argument arg of a call to t.Run, given the stack of nodes that
+// enclose the call. The argument must be a field tt.name of the value
+// tt, or the key name, of an enclosing range statement over a slice,
+// array, or map composite literal, or over a variable initialized
+// with one.
+func tableCases(stack []ast.Node, arg ast.Expr) []testCase {
+	var (
+		key   bool   // whether arg is the key of the range statement
+		field string // the field selected from its value, otherwise
+		rng   *ast.RangeStmt
+	)
+	for i := len(stack) - 1; i >= 0 && rng == nil; i-- {
+		r, ok := stack[i].(*ast.RangeStmt)
+		if !ok {
+			continue
+		}
+		switch arg := arg.(type) {
+		case *ast.Ident:
+			if r.Key != nil && isIdent(r.Key, arg.Name) {
+				rng, key = r, true
+			}
+		case *ast.SelectorExpr:
+			if id, ok := arg.X.(*ast.Ident); ok && r.Value != nil && isIdent(r.Value, id.Name) {
+				rng, field = r, arg.Sel.Name
+			}
+		}
+	}
+	if rng == nil {
+		return nil
+	}
+	table := tableLit(stack, rng)
+	if table == nil {
+		return nil
+	}
+
+	var cases []testCase
+	add := func(name ast.Expr, node ast.Node) {
+		if lit, ok := name.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			cases = append(cases, testCase{lit, node})
+		}
+	}
+	var elemType ast.Expr
+	switch t := table.Type.(type) {
+	case *ast.ArrayType:
+		if key {
+			return nil // the key is an index
+		}
+		elemType = t.Elt
+	case *ast.MapType:
+		elemType = t.Value
+	default:
+		return nil
+	}
+	index := fieldIndex(elemType, field) // of the field in positional elements
+	for _, elt := range table.Elts {
+		kv, isKV := elt.(*ast.KeyValueExpr)
+		if key {
+			if isKV {
+				add(kv.Key, elt)
+			}
+			continue
+		}
+		value := elt
+		if isKV {
+			value = kv.Value
+		}
+		if u, ok := value.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			value = u.X
+		}
+		lit, ok := value.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		for i, e := range lit.Elts {
+			if kv, ok := e.(*ast.KeyValueExpr); ok {
+				if isIdent(kv.Key, field) {
+					add(kv.Value, elt)
+				}
+			} else if i == index {
+				add(e, elt)
+			}
+		}
+	}
+	return cases
+}
+
+// tableLit returns the composite literal over which the range
+// statement rng ranges, either directly or through a variable
+// initialized with it by a declaration that precedes rng in one of the
+// enclosing blocks of the stack.
+func tableLit(stack []ast.Node, rng *ast.RangeStmt) *ast.CompositeLit {
+	switch x := rng.X.(type) {
+	case *ast.CompositeLit:
+		return x
+	case *ast.Ident:
+		for i := len(stack) - 1; i >= 0; i-- {
+			block, ok := stack[i].(*ast.BlockStmt)
+			if !ok {
+				continue
+			}
+			var lit *ast.CompositeLit
+			for _, stmt := range block.List {
+				if stmt.Pos() >= rng.Pos() {
+					break
+				}
+				if rhs := initializer(stmt, x.Name); rhs != nil {
+					lit, _ = rhs.(*ast.CompositeLit)
+				}
+			}
+			if lit != nil {
+				return lit
+			}
+		}
+	}
+	return nil
+}
+
+// initializer returns the expression by which the statement stmt
+// declares or assigns the variable of the specified name, if any.
+func initializer(stmt ast.Stmt, name string) ast.Expr {
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		if len(stmt.Lhs) == len(stmt.Rhs) {
+			for i, lhs := range stmt.Lhs {
+				if isIdent(lhs, name) {
+					return stmt.Rhs[i]
+				}
+			}
+		}
+	case *ast.DeclStmt:
+		if decl, ok := stmt.Decl.(*ast.GenDecl); ok && decl.Tok == token.VAR {
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.ValueSpec)
+				if len(spec.Names) == len(spec.Values) {
+					for i, id := range spec.Names {
+						if id.Name == name {
+							return spec.Values[i]
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fieldIndex returns the index of the named field among the fields of
+// the struct type expression typ, or -1 if typ is not a struct type
+// literal or it has no such field.
+func fieldIndex(typ ast.Expr, name string) int {
+	if t, ok := typ.(*ast.StructType); ok {
+		i := 0
+		for _, field := range t.Fields.List {
+			if len(field.Names) == 0 {
+				i++ // embedded field
+				continue
+			}
+			for _, id := range field.Names {
+				if id.Name == name {
+					return i
+				}
+				i++
+			}
+		}
+	}
+	return -1
+}
+
+// testingParam returns the identifier of the sole parameter of the
+// function type ftype, if its type is *testing.T or *testing.B.
+func testingParam(ftype *ast.FuncType) *ast.Ident {
+	if ftype.Params == nil || len(ftype.Params.List) != 1 || len(ftype.Params.List[0].Names) != 1 {
+		return nil
+	}
+	param := ftype.Params.List[0]
+	if star, ok := param.Type.(*ast.StarExpr); ok {
+		if sel, ok := star.X.(*ast.SelectorExpr); ok && isIdent(sel.X, "testing") &&
+			(sel.Sel.Name == "T" || sel.Sel.Name == "B") && param.Names[0].Name != "_" {
+			return param.Names[0]
+		}
+	}
+	return nil
+}
+
+// isIdent reports whether e is an identifier of the specified name.
+func isIdent(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
@@ -0,0 +1,679 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Convert to table-driven test",
+// which rewrites a test made of repeated blocks of statements that
+// differ only in literals into a loop over a table of test cases.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// A tableTest describes the conversion of a test function whose body
+// consists of blocks of statements that differ only in literals.
+type tableTest struct {
+	decl   *ast.FuncDecl
+	groups [][]ast.Stmt     // the blocks, in order
+	units  [][]ast.Expr     // units[i][g] is the i-th varying literal of group g
+	fields []*testCaseField // fields of the table struct
+	names  []string         // names of the test cases, if not those of their inputs
+}
+
+// A testCaseField is a field of the struct of the test cases, whose
+// values are the literals at one or more positions of the blocks.
+type testCaseField struct {
+	name  string
+	typ   types.Type
+	units []int // indexes in tableTest.units
+}
+
+// testMessageMethods are the methods of testing.TB whose first
+// argument is a message that may mention the values of a test case.
+var testMessageMethods = map[string]bool{
+	"Error": true, "Errorf": true,
+	"Fatal": true, "Fatalf": true,
+	"Log": true, "Logf": true,
+	"Skip": true, "Skipf": true,
+}
+
+// tableTestOf returns the conversion of the test function enclosing
+// [start, end) in a _test.go file into a table-driven test. The body
+// of the function must consist of two or more blocks of statements of
+// the same structure, which differ only in the values of some of
+// their literals, such as
+//
+//	if got := Abs(-1); got != 1 {
+//		t.Errorf("Abs(-1) = %v, want 1", got)
+//	}
+//	if got := Abs(2); got != 2 {
+//		t.Errorf("Abs(2) = %v, want 2", got)
+//	}
+func tableTestOf(info *types.Info, pgf *parsego.File, start, end token.Pos) (*tableTest, error) {
+	if !strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+		return nil, fmt.Errorf("not a test file")
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	if len(path) < 2 {
+		return nil, fmt.Errorf("no enclosing test function")
+	}
+	decl, ok := path[len(path)-2].(*ast.FuncDecl)
+	if !ok || !isTestFuncDecl(info, decl) {
+		return nil, fmt.Errorf("no enclosing test function")
+	}
+	if refersToTable(decl.Body) {
+		return nil, fmt.Errorf("test refers to tests or tt")
+	}
+
+	// Find the shortest blocks into which the body splits evenly.
+	stmts := decl.Body.List
+	for n := 1; n <= len(stmts)/2; n++ {
+		if len(stmts)%n != 0 {
+			continue
+		}
+		var groups [][]ast.Stmt
+		for i := 0; i < len(stmts); i += n {
+			groups = append(groups, stmts[i:i+n])
+		}
+		if units, ok := varyingLiterals(info, pgf, groups); ok && len(units) > 0 {
+			return &tableTest{
+				decl:   decl,
+				groups: groups,
+				units:  units,
+				fields: testCaseFields(info, pgf, units),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("test has no repeated blocks of statements")
+}
+
+// isTestFuncDecl reports whether decl declares a test function, such
+// as func TestF(t *testing.T).
+func isTestFuncDecl(info *types.Info, decl *ast.FuncDecl) bool {
+	return decl.Recv == nil && decl.Body != nil && strings.HasPrefix(decl.Name.Name, "Test") &&
+		len(decl.Type.Params.List) == 1 && len(decl.Type.Params.List[0].Names) == 1 &&
+		isTestingTB(info.TypeOf(decl.Type.Params.List[0].Type))
+}
+
+// refersToTable reports whether the body of a test refers to
+// identifiers named tests or tt, the names of the table and of the
+// test case of a table-driven test.
+func refersToTable(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && (id.Name == "tests" || id.Name == "tt") {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// varyingLiterals returns the literals of the groups of statements at
+// the positions at which they differ, indexed by position and then by
+// group. It reports false if the groups differ otherwise.
+func varyingLiterals(info *types.Info, pgf *parsego.File, groups [][]ast.Stmt) ([][]ast.Expr, bool) {
+	var sigs [][]string
+	var lits [][]ast.Expr
+	for _, group := range groups {
+		sig, glits := literalSignature(group)
+		if len(sigs) > 0 && !slices.Equal(sig, sigs[0]) {
+			return nil, false
+		}
+		sigs = append(sigs, sig)
+		lits = append(lits, glits)
+	}
+	var units [][]ast.Expr
+	for i, lit := range lits[0] {
+		typ := literalType(info, lit)
+		text, _ := nodeText(pgf, lit)
+		varying := false
+		column := []ast.Expr{lit}
+		for _, glits := range lits[1:] {
+			if !types.Identical(literalType(info, glits[i]), typ) {
+				return nil, false
+			}
+			if other, _ := nodeText(pgf, glits[i]); other != text {
+				varying = true
+			}
+			column = append(column, glits[i])
+		}
+		if varying {
+			units = append(units, column)
+		}
+	}
+	return units, true
+}
+
+// literalSignature returns a description of the structure of the
+// statements, in which each literal is a placeholder, along with the
+// literals in order. The declaration x := ... and the assignment
+// x = ... have the same structure, so that a block that declares a
+// variable such as got matches one that assigns it.
+func literalSignature(stmts []ast.Stmt) ([]string, []ast.Expr) {
+	var (
+		sig  []string
+		lits []ast.Expr
+	)
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if n == nil {
+				sig = append(sig, ")")
+				return true
+			}
+			if e, ok := n.(ast.Expr); ok && isLiteralUnit(e) {
+				sig = append(sig, "lit", ")")
+				lits = append(lits, e)
+				return false
+			}
+			s := fmt.Sprintf("%T", n)
+			switch n := n.(type) {
+			case *ast.Ident:
+				s += " " + n.Name
+			case *ast.BinaryExpr:
+				s += " " + n.Op.String()
+			case *ast.UnaryExpr:
+				s += " " + n.Op.String()
+			case *ast.AssignStmt:
+				tok := n.Tok
+				if tok == token.DEFINE {
+					tok = token.ASSIGN
+				}
+				s += " " + tok.String()
+			case *ast.RangeStmt:
+				s += " " + n.Tok.String()
+			case *ast.IncDecStmt:
+				s += " " + n.Tok.String()
+			case *ast.BranchStmt:
+				s += " " + n.Tok.String()
+			case *ast.GenDecl:
+				s += " " + n.Tok.String()
+			case *ast.ChanType:
+				s += fmt.Sprint(" ", n.Dir)
+			case *ast.CallExpr:
+				s += fmt.Sprint(" ", n.Ellipsis.IsValid())
+			}
+			sig = append(sig, s)
+			return true
+		})
+	}
+	return sig, lits
+}
+
+// isLiteralUnit reports whether e is a basic literal, or a signed
+// numeric literal such as -1.
+func isLiteralUnit(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.UnaryExpr:
+		if lit, ok := e.X.(*ast.BasicLit); ok && (e.Op == token.SUB || e.Op == token.ADD) {
+			return lit.Kind == token.INT || lit.Kind == token.FLOAT || lit.Kind == token.IMAG
+		}
+	}
+	return false
+}
+
+// literalType returns the type of the value of the literal, or its
+// default type if it is an untyped constant.
+func literalType(info *types.Info, e ast.Expr) types.Type {
+	t := info.TypeOf(e)
+	if t == nil {
+		return types.Typ[types.Invalid]
+	}
+	return types.Default(t)
+}
+
+// testCaseFields returns the fields of the table struct for the varying
+// literals, named after their roles in the first group, such as the
+// parameter that a literal argument of a call sets, or want for a
+// literal with which a result is compared. Positions at which the
+// literals are the same in every group and that play the same role
+// share a field.
+func testCaseFields(info *types.Info, pgf *parsego.File, units [][]ast.Expr) []*testCaseField {
+	var fields []*testCaseField
+	used := map[string]bool{"name": true}
+	byValues := make(map[string]*testCaseField)
+	for i, column := range units {
+		base := literalRole(info, pgf, column[0])
+		key := base
+		for _, lit := range column {
+			text, _ := nodeText(pgf, lit)
+			key += "\x00" + text
+		}
+		typ := literalType(info, column[0])
+		if f, ok := byValues[key]; ok && types.Identical(f.typ, typ) {
+			f.units = append(f.units, i)
+			continue
+		}
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		used[name] = true
+		f := &testCaseField{name: name, typ: typ, units: []int{i}}
+		byValues[key] = f
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// literalRole returns a name for the role of the literal lit.
+func literalRole(info *types.Info, pgf *parsego.File, lit ast.Expr) string {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, lit.Pos(), lit.End())
+	for len(path) > 1 && path[0] != lit {
+		path = path[1:]
+	}
+	var parent ast.Node
+	for _, n := range path[1:] {
+		if _, ok := n.(*ast.ParenExpr); !ok {
+			parent = n
+			break
+		}
+	}
+	name := ""
+	switch parent := parent.(type) {
+	case *ast.CallExpr:
+		if isTestMessage(info, parent, lit) {
+			return "msg"
+		}
+		if sig, ok := info.TypeOf(parent.Fun).(*types.Signature); ok && sig.Params().Len() > 0 {
+			i := slices.Index(parent.Args, lit)
+			if i < 0 {
+				// lit is parenthesized.
+				i = slices.IndexFunc(parent.Args, func(arg ast.Expr) bool {
+					return arg.Pos() <= lit.Pos() && lit.End() <= arg.End()
+				})
+			}
+			i = min(i, sig.Params().Len()-1)
+			if i >= 0 {
+				name = sig.Params().At(i).Name()
+			}
+		}
+		if name == "expected" {
+			name = "want"
+		}
+	case *ast.BinaryExpr:
+		switch parent.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			name = "want"
+		}
+	case *ast.KeyValueExpr:
+		if key, ok := parent.Key.(*ast.Ident); ok {
+			name = key.Name
+		}
+	case *ast.AssignStmt:
+		if i := slices.Index(parent.Rhs, lit); i >= 0 && len(parent.Lhs) == len(parent.Rhs) {
+			if id, ok := parent.Lhs[i].(*ast.Ident); ok {
+				name = id.Name
+			}
+		}
+	case *ast.ValueSpec:
+		if i := slices.Index(parent.Values, lit); i >= 0 && len(parent.Names) == len(parent.Values) {
+			name = parent.Names[i].Name
+		}
+	}
+	if name == "" || name == "_" {
+		return "v"
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	name = string(unicode.ToLower(r)) + name[size:]
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// isTestMessage reports whether lit is the message argument of a call
+// to a method of testing.TB such as Errorf.
+func isTestMessage(info *types.Info, call *ast.CallExpr, lit ast.Expr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && len(call.Args) > 0 && ast.Unparen(call.Args[0]) == lit &&
+		testMessageMethods[sel.Sel.Name] && isTestingTB(info.TypeOf(sel.X))
+}
+
+// A tableReplacement replaces the text of the file between two
+// offsets in the body of the table-driven test.
+type tableReplacement struct {
+	start, end int
+	text       string
+}
+
+// convertToTableTest rewrites the test function enclosing [start, end)
+// into a table-driven test (see [tableTestOf]), in the form of the
+// tests generated by "Add test": a table of test cases with a name
+// and a field for each varying literal, and a loop that runs a subtest
+// for each case, whose body is the first block of the original test
+// with the literals replaced by the fields of the test case.
+func convertToTableTest(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	edits, err := tableTestEdits(pkg.TypesInfo(), pkg.Types(), pgf, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
+
+// tableTestEdits returns the edits of [convertToTableTest].
+func tableTestEdits(info *types.Info, pkg *types.Package, pgf *parsego.File, start, end token.Pos) ([]analysis.TextEdit, error) {
+	tt, err := tableTestOf(info, pgf, start, end)
+	if err != nil {
+		return nil, err
+	}
+	lbrace, rbrace, err := safetoken.Offsets(pgf.Tok, tt.decl.Body.Lbrace, tt.decl.Body.Rbrace)
+	if err != nil {
+		return nil, err
+	}
+	// Preserve a comment that follows the opening brace on its line.
+	from := lbrace + 1
+	if eol := strings.IndexByte(string(pgf.Src[from:rbrace]), '\n'); eol >= 0 {
+		if line := safetoken.Line(pgf.Tok, tt.decl.Body.List[0].Pos()); line > safetoken.Line(pgf.Tok, tt.decl.Body.Lbrace) {
+			from += eol
+		}
+	}
+	return formatEditsWithin(pgf, []diff.Edit{{Start: from, End: rbrace, New: tt.body(info, pkg, pgf)}}, tt.decl)
+}
+
+// body returns the unformatted body of the table-driven test, without
+// its braces.
+func (tt *tableTest) body(info *types.Info, pkg *types.Package, pgf *parsego.File) string {
+	text := func(n ast.Node) string {
+		s, _ := nodeText(pgf, n)
+		return s
+	}
+	offsets := func(n ast.Node) (int, int) {
+		start, end, _ := safetoken.Offsets(pgf.Tok, n.Pos(), n.End())
+		return start, end
+	}
+	// value returns the value of the field in group g as it may appear
+	// in a message: the value of a string, or the literal otherwise.
+	value := func(f *testCaseField, g int) string {
+		lit := tt.units[f.units[0]][g]
+		if tv, ok := info.Types[lit]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			return constant.StringVal(tv.Value)
+		}
+		return text(lit)
+	}
+
+	// Replace the literals of the first group by the fields, and then
+	// the messages that mention the values of the fields by formats.
+	var (
+		repls    []tableReplacement
+		fields   []*testCaseField // fields of the table
+		messages = make(map[*testCaseField]*ast.CallExpr)
+	)
+	replace := func(f *testCaseField) {
+		fields = append(fields, f)
+		for _, i := range f.units {
+			start, end := offsets(tt.units[i][0])
+			repls = append(repls, tableReplacement{start, end, "tt." + f.name})
+		}
+	}
+	for _, f := range tt.fields {
+		if call := messageCall(info, pgf, tt.units[f.units[0]][0]); call != nil {
+			messages[f] = call
+		} else {
+			replace(f)
+		}
+	}
+	sortRepls := func() {
+		slices.SortFunc(repls, func(x, y tableReplacement) int { return x.start - y.start })
+	}
+	sortRepls()
+	render := func(n ast.Node) string {
+		start, end := offsets(n)
+		return replaceWithin(pgf.Src, start, end, repls)
+	}
+	var formats []tableReplacement
+	for _, f := range tt.fields {
+		if call, ok := messages[f]; ok {
+			if s, ok := messageFormat(tt, f, call, messages, value, render); ok {
+				start, end := offsets(call)
+				formats = append(formats, tableReplacement{start, end, s})
+			} else {
+				replace(f) // a field of messages
+			}
+		}
+	}
+	repls = append(repls, formats...)
+	sortRepls()
+
+	// The names of the test cases are the values of their inputs.
+	inputs := slices.DeleteFunc(slices.Clone(fields), func(f *testCaseField) bool {
+		return strings.HasPrefix(f.name, "want") || messages[f] != nil
+	})
+	if len(inputs) == 0 {
+		inputs = fields
+	}
+
+	qual := typesinternal.FileQualifier(pgf.File, pkg)
+	param := tt.decl.Type.Params.List[0]
+	var b strings.Builder
+	b.WriteString("\ntests := []struct {\n")
+	b.WriteString("name string // description of this test case\n")
+	for _, f := range fields {
+		fmt.Fprintf(&b, "%s %s\n", f.name, types.TypeString(f.typ, qual))
+	}
+	b.WriteString("}{\n")
+	for g := range tt.groups {
+		var name string
+		if tt.names != nil {
+			name = tt.names[g]
+		} else {
+			var values []string
+			for _, f := range inputs {
+				values = append(values, value(f, g))
+			}
+			name = strings.Join(values, ", ")
+		}
+		fmt.Fprintf(&b, "{\nname: %s,\n", strconv.Quote(name))
+		for _, f := range fields {
+			fmt.Fprintf(&b, "%s: %s,\n", f.name, text(tt.units[f.units[0]][g]))
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "for _, tt := range tests {\n%s.Run(tt.name, func(%s %s) {\n", param.Names[0].Name, param.Names[0].Name, text(param.Type))
+	group := tt.groups[0]
+	first, _ := offsets(group[0])
+	_, last := offsets(group[len(group)-1])
+	b.WriteString(replaceWithin(pgf.Src, first, last, repls))
+	b.WriteString("\n})\n}\n")
+	return b.String()
+}
+
+// messageCall returns the call of a method of testing.TB of which lit
+// is the message, or nil.
+func messageCall(info *types.Info, pgf *parsego.File, lit ast.Expr) *ast.CallExpr {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, lit.Pos(), lit.End())
+	for _, n := range path {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if isTestMessage(info, call, lit) {
+				return call
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// messageFormat returns the text of a call that replaces the message
+// call of the first group, in which the mentions of the values of the
+// fields of the test case in the message f are formatted from the
+// fields, such as t.Errorf("Abs(%v) = %v, want %v", tt.x, got, tt.want)
+// for t.Errorf("Abs(-1) = %v, want 1", got). It reports false if the
+// mentions are not the same in every group.
+func messageFormat(tt *tableTest, f *testCaseField, call *ast.CallExpr, messages map[*testCaseField]*ast.CallExpr, value func(*testCaseField, int) string, text func(ast.Node) string) (string, bool) {
+	// A piece of a message is a run of text or a mention of a field.
+	type piece struct {
+		text  string
+		field *testCaseField
+	}
+	isWordByte := func(c byte) bool {
+		return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+	}
+	// mentionAt reports whether msg mentions the value v at offset i,
+	// other than as part of a word.
+	mentionAt := func(msg string, i int, v string) bool {
+		return v != "" && strings.HasPrefix(msg[i:], v) &&
+			!(isWordByte(v[0]) && i > 0 && isWordByte(msg[i-1])) &&
+			!(isWordByte(v[len(v)-1]) && i+len(v) < len(msg) && isWordByte(msg[i+len(v)]))
+	}
+	var msgs []string
+	for g := range tt.groups {
+		msgs = append(msgs, value(f, g))
+	}
+
+	// Split the messages of all groups into the same pieces, trying
+	// mentions before text, as a value such as 2 may be that of
+	// several fields in one group but not in the others.
+	var (
+		pieces []piece
+		failed = make(map[string]bool) // offsets from which no split exists
+	)
+	var split func(offsets []int) bool
+	split = func(offsets []int) bool {
+		ended := 0
+		for g, i := range offsets {
+			if i == len(msgs[g]) {
+				ended++
+			}
+		}
+		if ended == len(offsets) {
+			return true
+		}
+		key := fmt.Sprint(offsets)
+		if ended > 0 || failed[key] {
+			return false
+		}
+		next := make([]int, len(offsets))
+	fields:
+		for _, other := range tt.fields {
+			if messages[other] != nil {
+				continue
+			}
+			for g, i := range offsets {
+				v := value(other, g)
+				if !mentionAt(msgs[g], i, v) {
+					continue fields
+				}
+				next[g] = i + len(v)
+			}
+			pieces = append(pieces, piece{field: other})
+			if split(next) {
+				return true
+			}
+			pieces = pieces[:len(pieces)-1]
+		}
+		c := msgs[0][offsets[0]]
+		for g, i := range offsets {
+			if msgs[g][i] != c {
+				failed[key] = true
+				return false
+			}
+			next[g] = i + 1
+		}
+		pieces = append(pieces, piece{text: string(c)})
+		if split(next) {
+			return true
+		}
+		pieces = pieces[:len(pieces)-1]
+		failed[key] = true
+		return false
+	}
+	if !split(make([]int, len(msgs))) || !slices.ContainsFunc(pieces, func(p piece) bool { return p.field != nil }) {
+		return "", false
+	}
+	// Join the runs of text.
+	var joined []piece
+	for _, p := range pieces {
+		if n := len(joined); n > 0 && p.field == nil && joined[n-1].field == nil {
+			joined[n-1].text += p.text
+		} else {
+			joined = append(joined, p)
+		}
+	}
+	pieces = joined
+
+	sel := call.Fun.(*ast.SelectorExpr)
+	method := sel.Sel.Name
+	formatted := strings.HasSuffix(method, "f")
+	if !formatted {
+		if len(call.Args) != 1 {
+			return "", false // arguments are joined by spaces
+		}
+		method += "f"
+	}
+	var (
+		format strings.Builder
+		args   []string
+		rest   = call.Args[1:]
+	)
+	for _, p := range pieces {
+		if p.field != nil {
+			format.WriteString("%v")
+			args = append(args, "tt."+p.field.name)
+			continue
+		}
+		if !formatted {
+			format.WriteString(strings.ReplaceAll(p.text, "%", "%%"))
+			continue
+		}
+		// Keep the arguments of the verbs of the text in order.
+		for i := 0; i < len(p.text); i++ {
+			if p.text[i] != '%' {
+				continue
+			}
+			if i+1 < len(p.text) && p.text[i+1] == '%' {
+				i++
+				continue
+			}
+			if strings.ContainsAny(p.text[i:], "[*") || len(rest) == 0 {
+				return "", false // indexed or starred arguments
+			}
+			args = append(args, text(rest[0]))
+			rest = rest[1:]
+		}
+		format.WriteString(p.text)
+	}
+	for _, arg := range rest {
+		args = append(args, text(arg))
+	}
+	return fmt.Sprintf("%s.%s(%s)", text(sel.X), method, strings.Join(append([]string{strconv.Quote(format.String())}, args...), ", ")), true
+}
+
+// replaceWithin returns the text of src between the offsets start and
+// end with the replacements, sorted by offset, that lie within it.
+// Replacements within another replacement are ignored.
+func replaceWithin(src []byte, start, end int, repls []tableReplacement) string {
+	var b strings.Builder
+	pos := start
+	for _, r := range repls {
+		if r.start < pos || r.end > end {
+			continue
+		}
+		b.Write(src[pos:r.start])
+		b.WriteString(r.text)
+		pos = r.end
+	}
+	b.Write(src[pos:end])
+	return b.String()
+}
@@ -0,0 +1,286 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/diff"
+)
+
+func TestTableTest(t *testing.T) {
+	const prefix = `package p
+
+import (
+	"strings"
+	"testing"
+)
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+var _ = strings.ToUpper
+
+`
+	for _, test := range []struct {
+		name string
+		src  string
+		want string // empty if the test cannot be converted
+	}{
+		{
+			name: "mentions",
+			src: `func TestAbs(t *testing.T) {
+	got := Abs(-1)
+	if got != 1 {
+		t.Errorf("Abs(-1) = %d, want 1", got)
+	}
+	got = Abs(2)
+	if got != 2 {
+		t.Errorf("Abs(2) = %d, want 2", got)
+	}
+}
+`,
+			want: `func TestAbs(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		x    int
+		want int
+	}{
+		{
+			name: "-1",
+			x:    -1,
+			want: 1,
+		},
+		{
+			name: "2",
+			x:    2,
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Abs(tt.x)
+			if got != tt.want {
+				t.Errorf("Abs(%v) = %d, want %v", tt.x, got, tt.want)
+			}
+		})
+	}
+}
+`,
+		},
+		{
+			name: "strings",
+			src: `func TestUpper(t *testing.T) {
+	if got := strings.ToUpper("ab"); got != "AB" {
+		t.Error("wrong result")
+	}
+	if got := strings.ToUpper("x"); got != "X" {
+		t.Error("wrong result")
+	}
+}
+`,
+			want: `func TestUpper(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		s    string
+		want string
+	}{
+		{
+			name: "ab",
+			s:    "ab",
+			want: "AB",
+		},
+		{
+			name: "x",
+			s:    "x",
+			want: "X",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.ToUpper(tt.s); got != tt.want {
+				t.Error("wrong result")
+			}
+		})
+	}
+}
+`,
+		},
+		{
+			name: "different calls",
+			src: `func TestAbs(t *testing.T) {
+	if Abs(-1) != 1 {
+		t.Error("Abs(-1)")
+	}
+	if strings.ToUpper("x") != "X" {
+		t.Error("ToUpper")
+	}
+}
+`,
+		},
+		{
+			name: "identical blocks",
+			src: `func TestAbs(t *testing.T) {
+	if Abs(-1) != 1 {
+		t.Error("Abs(-1)")
+	}
+	if Abs(-1) != 1 {
+		t.Error("Abs(-1)")
+	}
+}
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			src := prefix + test.src
+			info, pkg, pgf := checkTestFile(t, src)
+			pos := pgf.File.Decls[len(pgf.File.Decls)-1].(*ast.FuncDecl).Name.Pos()
+			edits, err := tableTestEdits(info, pkg, pgf, pos, pos)
+			if test.want == "" {
+				if err == nil {
+					t.Fatal("test was converted, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimPrefix(applyTextEdits(t, pgf, edits), prefix)
+			if got != test.want {
+				t.Errorf("converted test:\n%s\nwant:\n%s\ndiff:\n%s", got, test.want, diff.Unified("want", "got", test.want, got))
+			}
+		})
+	}
+}
+
+func TestMergeTests(t *testing.T) {
+	const src = `package p
+
+import "testing"
+
+func Double(x int) int { return 2 * x }
+
+// TestDouble_Zero tests Double(0).
+func TestDouble_Zero(t *testing.T) {
+	if got := Double(0); got != 0 {
+		t.Errorf("Double(0) = %d", got)
+	}
+}
+
+func TestDouble_Negative(t *testing.T) {
+	if got := Double(-2); got != -4 {
+		t.Errorf("Double(-2) = %d", got)
+	}
+}
+
+func TestOther(t *testing.T) {}
+
+func TestDouble_Large(t *testing.T) {
+	if got := Double(1000); got != 2000 {
+		t.Errorf("Double(1000) = %d", got)
+	}
+}
+`
+	const want = `package p
+
+import "testing"
+
+func Double(x int) int { return 2 * x }
+
+func TestDouble(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		x    int
+		want int
+	}{
+		{
+			name: "Zero",
+			x:    0,
+			want: 0,
+		},
+		{
+			name: "Negative",
+			x:    -2,
+			want: -4,
+		},
+		{
+			name: "Large",
+			x:    1000,
+			want: 2000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Double(tt.x); got != tt.want {
+				t.Errorf("Double(%v) = %d", tt.x, got)
+			}
+		})
+	}
+}
+
+func TestOther(t *testing.T) {}
+`
+	info, pkg, pgf := checkTestFile(t, src)
+	pos := pgf.File.Decls[len(pgf.File.Decls)-1].(*ast.FuncDecl).Name.Pos()
+	edits, err := mergeTestsEdits(info, pkg, pgf, pos, pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := applyTextEdits(t, pgf, edits); got != want {
+		t.Errorf("merged tests:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff.Unified("want", "got", want, got))
+	}
+}
+
+// checkTestFile parses and type-checks the test file of package p
+// with the given source.
+func checkTestFile(t *testing.T, src string) (*types.Info, *types.Package, *parsego.File) {
+	t.Helper()
+	fset := token.NewFileSet()
+	uri := protocol.URIFromPath("/p/p_test.go")
+	pgf, _ := parsego.Parse(context.Background(), fset, uri, []byte(src), parsego.Full, false)
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("p", fset, []*ast.File{pgf.File}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info, pkg, pgf
+}
+
+// applyTextEdits returns the source of the file with the edits applied.
+func applyTextEdits(t *testing.T, pgf *parsego.File, edits []analysis.TextEdit) string {
+	t.Helper()
+	var dedits []diff.Edit
+	for _, edit := range edits {
+		start, end, err := safetoken.Offsets(pgf.Tok, edit.Pos, edit.End)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dedits = append(dedits, diff.Edit{Start: start, End: end, New: string(edit.NewText)})
+	}
+	got, err := diff.Apply(string(pgf.Src), dedits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code action "Add test case", which appends an
+// empty row to a table of test cases.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// testCaseTable returns the innermost composite literal of a table of
+// test cases enclosing [start, end) in a _test.go file, that is, a
+// slice or array of structs, such as
+//
+//	tests := []struct{ name string; in, want int }{ ... }
+//
+// and the struct type of its elements.
+func testCaseTable(info *types.Info, pgf *parsego.File, start, end token.Pos) (*ast.CompositeLit, *types.Struct, error) {
+	if !strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+		return nil, nil, fmt.Errorf("not a test file")
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	for _, n := range path {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		t := info.TypeOf(lit)
+		if t == nil {
+			continue
+		}
+		var elem types.Type
+		switch t := t.Underlying().(type) {
+		case *types.Slice:
+			elem = t.Elem()
+		case *types.Array:
+			elem = t.Elem()
+		default:
+			continue
+		}
+		if st, ok := elem.Underlying().(*types.Struct); ok && st.NumFields() > 0 {
+			return lit, st, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no enclosing table of test cases")
+}
+
+// addTestCase appends to the table of test cases enclosing [start,
+// end) an element whose fields are all keyed and set to their zero
+// values, with the name of the test case, if any, first.
+func addTestCase(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	lit, st, err := testCaseTable(pkg.TypesInfo(), pgf, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	qual := typesinternal.FileQualifier(pgf.File, pkg.Types())
+
+	// List the name field first, as do generated tests.
+	var fields []*types.Var
+	for f := range st.Fields() {
+		if f.Name() == "_" {
+			continue
+		}
+		if f.Name() == "name" || f.Name() == "Name" {
+			fields = append([]*types.Var{f}, fields...)
+		} else {
+			fields = append(fields, f)
+		}
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, f := range fields {
+		zero, _ := typesinternal.ZeroString(f.Type(), qual)
+		fmt.Fprintf(&b, "%s: %s,\n", f.Name(), zero)
+	}
+	b.WriteString("},\n")
+
+	// Insert the element before the closing brace, after a comma
+	// following the last element if there is none.
+	rbrace, err := safetoken.Offset(pgf.Tok, lit.Rbrace)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := b.String()
+	if n := len(lit.Elts); n > 0 {
+		last, err := safetoken.Offset(pgf.Tok, lit.Elts[n-1].End())
+		if err != nil {
+			return nil, nil, err
+		}
+		if !strings.Contains(string(pgf.Src[last:rbrace]), ",") {
+			text = ",\n" + text
+		}
+	}
+	edits, err := formatEditsWithin(pgf, []diff.Edit{{Start: rbrace, End: rbrace, New: text}}, lit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{TextEdits: edits}, nil
+}
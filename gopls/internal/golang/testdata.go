@@ -0,0 +1,262 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the diagnostics for string literals of _test.go
+// files that refer to missing testdata files, and their quick fix, and
+// the definition and hover of string literals that name files of the
+// package directory, such as testdata fixtures and golden files.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+)
+
+// A testdataRef is a string literal of a _test.go file that refers to
+// a file of the testdata directory of its package.
+type testdataRef struct {
+	lit  *ast.BasicLit
+	name string               // slash-separated name relative to the package directory, such as "testdata/a.golden"
+	uri  protocol.DocumentURI // of the referenced file
+}
+
+// testdataRefs returns the references of the string literals of the
+// _test.go file to files of the testdata directory of its package:
+// those whose value is a clean relative path starting with
+// "testdata/". Literals that look like patterns, such as
+// "testdata/*.txt" or "testdata/%s.golden", are not references.
+func testdataRefs(pgf *parsego.File) []testdataRef {
+	if !strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+		return nil
+	}
+	var refs []testdataRef
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.BasicLit:
+			if n.Kind != token.STRING {
+				break
+			}
+			s, err := strconv.Unquote(n.Value)
+			if err != nil {
+				break
+			}
+			name := strings.TrimPrefix(s, "./")
+			if !strings.HasPrefix(name, "testdata/") ||
+				path.Clean(name) != name ||
+				strings.ContainsAny(name, "*?[{%\\\n") {
+				break
+			}
+			refs = append(refs, testdataRef{
+				lit:  n,
+				name: name,
+				uri:  protocol.URIFromPath(filepath.Join(pgf.URI.DirPath(), filepath.FromSlash(name))),
+			})
+		}
+		return true
+	})
+	return refs
+}
+
+// missingTestdataRefs returns the references of the _test.go file to
+// testdata files that do not exist (see [testdataRefs]).
+//
+// Open files are consulted in the snapshot, so that a fixture that has
+// not yet been saved exists; other files are looked up on disk, as
+// changes to files outside the watched patterns, such as testdata
+// fixtures, are not reported to the snapshot.
+func missingTestdataRefs(snapshot *cache.Snapshot, pgf *parsego.File) []testdataRef {
+	var missing []testdataRef
+	for _, ref := range testdataRefs(pgf) {
+		if snapshot.IsOpen(ref.uri) {
+			continue
+		}
+		if _, err := os.Stat(ref.uri.Path()); err == nil || !os.IsNotExist(err) {
+			continue
+		}
+		missing = append(missing, ref)
+	}
+	return missing
+}
+
+// TestdataDiagnostics returns diagnostics for the references to
+// missing testdata files of the open _test.go files of the specified
+// packages.
+func TestdataDiagnostics(ctx context.Context, snapshot *cache.Snapshot, pkgs map[metadata.PackageID]*metadata.Package) (map[protocol.DocumentURI][]*cache.Diagnostic, error) {
+	reports := make(map[protocol.DocumentURI][]*cache.Diagnostic)
+	for _, mp := range pkgs {
+		for _, uri := range mp.CompiledGoFiles {
+			if _, seen := reports[uri]; seen || !strings.HasSuffix(uri.Path(), "_test.go") || !snapshot.IsOpen(uri) {
+				continue
+			}
+			fh, err := snapshot.ReadFile(ctx, uri)
+			if err != nil {
+				return nil, err
+			}
+			pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+			if err != nil {
+				return nil, err
+			}
+			diags := []*cache.Diagnostic{} // non-nil, to mark the file as seen
+			for _, ref := range missingTestdataRefs(snapshot, pgf) {
+				rng, err := pgf.NodeRange(ref.lit)
+				if err != nil {
+					return nil, err
+				}
+				diags = append(diags, &cache.Diagnostic{
+					URI:      uri,
+					Range:    rng,
+					Severity: protocol.SeverityWarning,
+					Source:   cache.TestdataError,
+					Message:  fmt.Sprintf("testdata file %s does not exist", ref.name),
+				})
+			}
+			reports[uri] = diags
+		}
+	}
+	return reports, nil
+}
+
+// createTestdataFiles produces the quick fixes that create the missing
+// testdata files referred to by the diagnostics of the request (see
+// [TestdataDiagnostics]), as empty files.
+func createTestdataFiles(req *codeActionsRequest) {
+	for _, ref := range missingTestdataRefs(req.snapshot, req.pgf) {
+		rng, err := req.pgf.NodeRange(ref.lit)
+		if err != nil {
+			continue
+		}
+		var fixed []protocol.Diagnostic
+		for _, diag := range req.diagnostics {
+			if diag.Source == string(cache.TestdataError) && diag.Range == rng {
+				fixed = append(fixed, diag)
+			}
+		}
+		if len(fixed) > 0 {
+			req.addEditAction(fmt.Sprintf("Create empty %s", ref.name), fixed, protocol.DocumentChangeCreate(ref.uri))
+		}
+	}
+}
+
+// packageFileAt returns the string literal of the file enclosing pos,
+// and the file it names, if its value is a relative path to an existing
+// file, not a directory, of the directory of the file, such as
+// "testdata/a.golden". Like testdata references, an open file exists
+// even if it has not been saved (see [missingTestdataRefs]).
+func packageFileAt(snapshot *cache.Snapshot, pgf *parsego.File, pos token.Pos) (*ast.BasicLit, protocol.DocumentURI) {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+	if len(path) < 2 {
+		return nil, ""
+	}
+	lit, ok := path[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return nil, ""
+	}
+	switch parent := path[1].(type) {
+	case *ast.ImportSpec:
+		return nil, ""
+	case *ast.Field:
+		if parent.Tag == lit {
+			return nil, ""
+		}
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil || !filepath.IsLocal(filepath.FromSlash(s)) || strings.ContainsAny(s, "\\\n") {
+		return nil, ""
+	}
+	uri := protocol.URIFromPath(filepath.Join(pgf.URI.DirPath(), filepath.FromSlash(s)))
+	if uri == pgf.URI || uri.Dir() == pgf.URI.Dir() && strings.HasSuffix(s, ".go") {
+		return nil, "" // a Go file of the package is not a fixture
+	}
+	if !snapshot.IsOpen(uri) {
+		if info, err := os.Stat(uri.Path()); err != nil || info.IsDir() {
+			return nil, ""
+		}
+	}
+	return lit, uri
+}
+
+// packageFileDefinition returns the location of the start of the file
+// named by the string literal at pos (see [packageFileAt]), if any.
+func packageFileDefinition(snapshot *cache.Snapshot, pgf *parsego.File, pos token.Pos) (protocol.Location, bool) {
+	lit, uri := packageFileAt(snapshot, pgf, pos)
+	if lit == nil {
+		return protocol.Location{}, false
+	}
+	return protocol.Location{URI: uri}, true
+}
+
+// maxFilePreviewLines is the maximum number of lines of the preview of
+// a file in the hover of a string literal that names it.
+const maxFilePreviewLines = 20
+
+// hoverPackageFile computes hover information for the string literal
+// lit naming the file uri of the package directory: its size, and a
+// preview of its first lines, if it is a text file.
+func hoverPackageFile(ctx context.Context, snapshot *cache.Snapshot, pgf *parsego.File, lit *ast.BasicLit, uri protocol.DocumentURI) (protocol.Range, *hoverResult, error) {
+	rng, err := pgf.NodeRange(lit)
+	if err != nil {
+		return protocol.Range{}, nil, err
+	}
+	var content []byte
+	if snapshot.IsOpen(uri) {
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return protocol.Range{}, nil, err
+		}
+		content, err = fh.Content()
+		if err != nil {
+			return protocol.Range{}, nil, err
+		}
+	} else {
+		content, err = os.ReadFile(uri.Path())
+		if err != nil {
+			return protocol.Range{}, nil, err
+		}
+	}
+
+	rel, err := filepath.Rel(pgf.URI.DirPath(), uri.Path())
+	if err != nil {
+		return protocol.Range{}, nil, err
+	}
+	synopsis := fmt.Sprintf("%d bytes", len(content))
+	doc := synopsis
+	if len(content) > 0 && utf8.Valid(content) && !strings.ContainsRune(string(content), 0) {
+		lines := strings.SplitAfter(string(content), "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		var preview strings.Builder
+		for i, line := range lines {
+			if i == maxFilePreviewLines {
+				fmt.Fprintf(&preview, "\t... (%d more lines)\n", len(lines)-i)
+				break
+			}
+			fmt.Fprintf(&preview, "\t%s", strings.TrimSuffix(line, "\n"))
+			preview.WriteString("\n")
+		}
+		doc = fmt.Sprintf("%s, %d lines:\n\n%s", synopsis, len(lines), preview.String())
+	}
+	return rng, &hoverResult{
+		Signature:         fmt.Sprintf("File %q", filepath.ToSlash(rel)),
+		Synopsis:          synopsis,
+		FullDocumentation: doc,
+	}, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+)
+
+func TestTestdataRefs(t *testing.T) {
+	const src = `package p
+
+import "testdata/notaref"
+
+var (
+	_ = "testdata/a.golden"
+	_ = "./testdata/b/c.txt"
+	_ = ` + "`testdata/d.json`" + `
+	_ = "testdata/*.txt"
+	_ = "testdata/%s.golden"
+	_ = "testdata/../e.txt"
+	_ = "other/f.txt"
+	_ = 'x'
+)
+`
+	for _, test := range []struct {
+		filename string
+		want     []string
+	}{
+		{"p_test.go", []string{"testdata/a.golden", "testdata/b/c.txt", "testdata/d.json"}},
+		{"p.go", nil},
+	} {
+		uri := protocol.URIFromPath("/p/" + test.filename)
+		pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), uri, []byte(src), parsego.Full, false)
+		var got []string
+		for _, ref := range testdataRefs(pgf) {
+			if want := protocol.URIFromPath("/p/" + ref.name); ref.uri != want {
+				t.Errorf("%s: URI of %s = %s, want %s", test.filename, ref.name, ref.uri, want)
+			}
+			got = append(got, ref.name)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: testdataRefs = %q, want %q", test.filename, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the registry of the generators of the functions
+// of test files, such as the table-driven test of a function, and the
+// adjustment of the comments of the code they generate.
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strings"
+	"text/template"
+
+	"golang.org/x/tools/gopls/internal/settings"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+)
+
+// A generatorKind identifies a kind of generated function of a test
+// file, such as a test or a benchmark.
+type generatorKind string
+
+// testGenerator generates table-driven tests (see [TestFuncSource]).
+const testGenerator generatorKind = "test"
+
+// A generator produces the source of a function of a test file that
+// exercises a function or method fn. Its template, parsed once, when
+// the generator is registered, is executed with the data built by
+// [newTestInfo], which holds the qualified names, the fields of the
+// test cases, and the construction of the receiver that generators
+// share, so that each generator need only define the form of the
+// function it produces.
+type generator struct {
+	// name returns the name of the function generated for fn,
+	// such as TestF or TestT_M.
+	name func(fn *types.Func) (string, error)
+	tmpl *template.Template
+}
+
+// generators holds the registered generators, by kind.
+var generators = make(map[generatorKind]*generator)
+
+// templateFuncs are the functions available to the templates of all
+// generators.
+var templateFuncs = template.FuncMap{
+	"last": func(slice []field) field {
+		if len(slice) == 0 {
+			return field{}
+		}
+		return slice[len(slice)-1]
+	},
+	"fieldNames": func(fields []field, qualifier string) (res string) {
+		var names []string
+		for _, f := range fields {
+			names = append(names, qualifier+f.Name)
+		}
+		return strings.Join(names, ", ")
+	},
+}
+
+// registerGenerator registers the generator of the specified kind,
+// which names the functions it generates by name and renders them with
+// the template src, which may use [templateFuncs]. It panics if the
+// kind is already registered or the template is invalid.
+func registerGenerator(kind generatorKind, name func(*types.Func) (string, error), src string) {
+	if _, ok := generators[kind]; ok {
+		panic(fmt.Sprintf("duplicate generator %q", kind))
+	}
+	generators[kind] = &generator{
+		name: name,
+		tmpl: template.Must(template.New(string(kind)).Funcs(templateFuncs).Parse(src)),
+	}
+}
+
+func init() {
+	registerGenerator(testGenerator, testName, testTmplString)
+}
+
+// generate returns the formatted source of the function of the
+// specified kind that exercises fn, as described by params (see
+// [TestFuncSource]).
+func generate(kind generatorKind, fn *types.Func, params TestFuncParams) ([]byte, error) {
+	g, ok := generators[kind]
+	if !ok {
+		return nil, fmt.Errorf("no generator of %ss", kind)
+	}
+	name, err := g.name(fn)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newTestInfo(name, fn, params)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := g.tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return params.Options.adjustComments(src)
+}
+
+// todoPrefix begins the comments of generated code that mark the sites
+// the user must complete, such as the table of test cases.
+const todoPrefix = "// TODO: "
+
+// adjustComments returns src, the formatted source of generated test
+// code, with its comments adjusted to the Comments and TodoMarker
+// options: the comments that mark the sites the user must complete
+// begin with TodoMarker rather than "TODO:", and the other comments,
+// which explain the parts of the test, are removed if Comments is
+// minimal, as are all comments if it is none.
+func (opts TestOptions) adjustComments(src []byte) ([]byte, error) {
+	marker := cmp.Or(opts.TodoMarker, "TODO:")
+	explain := opts.Comments == "" || opts.Comments == settings.TestCommentsFull
+	if explain && marker == "TODO:" {
+		return src, nil
+	}
+
+	var (
+		file = token.NewFileSet().AddFile("", -1, len(src))
+		s    scanner.Scanner
+		buf  bytes.Buffer
+		pos  = 0 // end of the source written so far
+	)
+	s.Init(file, src, nil, scanner.ScanComments)
+	for {
+		p, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.COMMENT {
+			continue
+		}
+		start, err := safetoken.Offset(file, p)
+		if err != nil {
+			return nil, err
+		}
+		end := start + len(lit)
+		text, todo := strings.CutPrefix(lit, todoPrefix)
+		switch {
+		case todo && opts.Comments != settings.TestCommentsNone:
+			buf.Write(src[pos:start])
+			fmt.Fprintf(&buf, "// %s %s", marker, text)
+			pos = end
+		case !todo && explain:
+			// Keep the comment.
+		default:
+			// Remove the line of a comment that is alone on its
+			// line, or else the comment and the space before it.
+			lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+			if len(bytes.TrimSpace(src[lineStart:start])) == 0 && (end == len(src) || src[end] == '\n') {
+				start = lineStart
+				if end < len(src) {
+					end++
+				}
+			} else {
+				start = len(bytes.TrimRight(src[:start], " \t"))
+			}
+			buf.Write(src[pos:max(start, pos)])
+			pos = end
+		}
+	}
+	buf.Write(src[pos:])
+	return format.Source(buf.Bytes())
+}
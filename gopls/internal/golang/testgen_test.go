@@ -0,0 +1,152 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/cache/constructors"
+	"golang.org/x/tools/gopls/internal/settings"
+)
+
+func TestGeneratorRegistry(t *testing.T) {
+	const kind generatorKind = "benchmark"
+	registerGenerator(kind, func(fn *types.Func) (string, error) { return "Benchmark" + fn.Name(), nil }, `
+func {{.TestFuncName}}(b *{{.TestingPackageName}}.B) {
+	for b.Loop() {
+		{{.Func.Name}}({{range $i, $arg := .Func.Args}}{{if $i}}, {{end}}{{$arg.Type}}(0){{end}})
+	}
+}
+`)
+	defer delete(generators, kind)
+
+	_, pkg, _ := checkTestFile(t, `package p
+
+func Add(x, y int) int { return x + y }
+`)
+	fn := pkg.Scope().Lookup("Add").(*types.Func)
+	qual := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+	got, err := generate(kind, fn, TestFuncParams{Constructors: constructors.NewIndex(nil), Qual: qual})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `
+func BenchmarkAdd(b *testing.B) {
+	for b.Loop() {
+		Add(int(0), int(0))
+	}
+}
+`
+	if string(got) != want {
+		t.Errorf("generated benchmark:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := generate("example", fn, TestFuncParams{Constructors: constructors.NewIndex(nil), Qual: qual}); err == nil {
+		t.Error("generate with an unregistered kind succeeded, want error")
+	}
+}
+
+func TestAdjustComments(t *testing.T) {
+	const src = `
+func TestF(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		// Named input parameters for target function.
+		in   string
+		want string
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := F(tt.in)
+			// TODO: update the condition below to compare got with tt.want.
+			if true {
+				t.Errorf("F() = %v, want %v // not a comment", got, tt.want)
+			}
+		})
+	}
+}
+`
+	for _, test := range []struct {
+		name string
+		opts TestOptions
+		want string
+	}{
+		{"full", TestOptions{Comments: settings.TestCommentsFull}, src},
+		{"marker", TestOptions{TodoMarker: "FIXME(owner):"}, `
+func TestF(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		// Named input parameters for target function.
+		in   string
+		want string
+	}{
+		// FIXME(owner): Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := F(tt.in)
+			// FIXME(owner): update the condition below to compare got with tt.want.
+			if true {
+				t.Errorf("F() = %v, want %v // not a comment", got, tt.want)
+			}
+		})
+	}
+}
+`},
+		{"minimal", TestOptions{Comments: settings.TestCommentsMinimal}, `
+func TestF(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := F(tt.in)
+			// TODO: update the condition below to compare got with tt.want.
+			if true {
+				t.Errorf("F() = %v, want %v // not a comment", got, tt.want)
+			}
+		})
+	}
+}
+`},
+		{"none", TestOptions{Comments: settings.TestCommentsNone}, `
+func TestF(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := F(tt.in)
+			if true {
+				t.Errorf("F() = %v, want %v // not a comment", got, tt.want)
+			}
+		})
+	}
+}
+`},
+	} {
+		got, err := test.opts.adjustComments([]byte(src))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: adjusted comments:\n%s\nwant:\n%s", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the helpers that construct the receivers of
+// generated tests of methods.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/constructors"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/typesutil"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+// TestHelpers records the helpers declared in test files that
+// construct a value of a type for use by tests: functions such as
+// newTestServer(t *testing.T) *Server, whose only parameter is a
+// *testing.T or testing.TB and whose only result is of type T or *T.
+// TestHelpers maps each such type T, as written in the files, such as
+// Server or p.Server, to the name of its helper.
+type TestHelpers map[string]string
+
+// TestHelpersOf returns the helpers declared in the specified test
+// files, which belong to the same package. If several helpers
+// construct the same type, the first is chosen.
+func TestHelpersOf(files ...*ast.File) TestHelpers {
+	helpers := make(TestHelpers)
+	for _, file := range files {
+		addTestHelpers(helpers, file)
+	}
+	return helpers
+}
+
+// addTestHelpers adds the helpers declared in the file to helpers.
+func addTestHelpers(helpers TestHelpers, file *ast.File) {
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || decl.Recv != nil || decl.Type.TypeParams != nil ||
+			decl.Type.Params.NumFields() != 1 || decl.Type.Results.NumFields() != 1 ||
+			!isTestingT(decl.Type.Params.List[0].Type) {
+			continue
+		}
+		result := decl.Type.Results.List[0].Type
+		if star, ok := result.(*ast.StarExpr); ok {
+			result = star.X
+		}
+		var typ string
+		switch result := result.(type) {
+		case *ast.Ident:
+			typ = result.Name
+		case *ast.SelectorExpr:
+			if pkg, ok := result.X.(*ast.Ident); ok {
+				typ = pkg.Name + "." + result.Sel.Name
+			}
+		}
+		if _, ok := helpers[typ]; !ok && typ != "" {
+			helpers[typ] = decl.Name.Name
+		}
+	}
+}
+
+// testPackageHelpers returns the helpers declared in the test files of
+// the package tested, or of its external test package if xtest (see
+// [testPackageFiles]).
+func testPackageHelpers(ctx context.Context, snapshot *cache.Snapshot, tested *metadata.Package, xtest bool) (TestHelpers, error) {
+	helpers := make(TestHelpers)
+	for _, uri := range testPackageFiles(snapshot, tested, xtest) {
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+		if err != nil {
+			return nil, err
+		}
+		addTestHelpers(helpers, pgf.File)
+	}
+	return helpers, nil
+}
+
+// testPackageFiles returns the _test.go files of the package tested, or
+// of its external test package if xtest, sorted. The test packages are
+// those of the module of tested (see [metadata.SameModule]).
+func testPackageFiles(snapshot *cache.Snapshot, tested *metadata.Package, xtest bool) []protocol.DocumentURI {
+	testPath := tested.PkgPath
+	if xtest {
+		testPath += "_test"
+	}
+	var uris []protocol.DocumentURI
+	for _, mp := range snapshot.MetadataGraph().Packages {
+		if mp.ForTest == tested.PkgPath && mp.PkgPath == testPath && metadata.SameModule(mp, tested) {
+			for _, uri := range mp.CompiledGoFiles {
+				if strings.HasSuffix(uri.Path(), "_test.go") {
+					uris = append(uris, uri)
+				}
+			}
+		}
+	}
+	slices.Sort(uris) // for determinism
+	return slices.Compact(uris)
+}
+
+// usedIn returns the subset of the helpers that node n calls.
+func (helpers TestHelpers) usedIn(n ast.Node) TestHelpers {
+	used := make(TestHelpers)
+	ast.Inspect(n, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if id, ok := call.Fun.(*ast.Ident); ok {
+				for typ, helper := range helpers {
+					if helper == id.Name {
+						used[typ] = helper
+					}
+				}
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// receiverHelperSource returns the formatted source of a helper named
+// name that constructs a value of type t, the receiver type of methods
+// of package pkg, as it appears in a test file of pkg, or of its
+// external test package if xtest. The helper calls the first suitable
+// constructor of ctors, the constructor index of pkg, or else its first
+// factory method, with default arguments (see [tempDefault] and
+// [httpDefault]), failing the test if it returns an error, and releases
+// the resources of the result when the test completes (see
+// [cleanupStmt]); if there is neither, it returns a new zero value of
+// t. The factory is constructed likewise, by its own constructor, if
+// any, or else as a zero value.
+func receiverHelperSource(name string, t typesinternal.NamedOrAlias, pkg *types.Package, ctors *constructors.Index, xtest bool, qual types.Qualifier) ([]byte, error) {
+	testingName := qual(types.NewPackage("testing", "testing"))
+	typeName := types.TypeString(t, qual)
+
+	var named *types.Named
+	if alias, ok := t.(*types.Alias); ok {
+		named, _ = types.Unalias(alias).(*types.Named)
+	} else {
+		named = t.(*types.Named)
+	}
+	var constructor *types.Func
+	if named != nil {
+		constructor = receiverConstructor(ctors, pkg, named, xtest)
+		if constructor == nil {
+			constructor = receiverFactory(ctors, pkg, named, xtest)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\n// %s returns a new %s for use by tests.\n", name, t.Obj().Name())
+	if constructor == nil {
+		fmt.Fprintf(&buf, "func %s(t *%s.T) *%s {\n", name, testingName, typeName)
+		fmt.Fprintf(&buf, "// TODO: construct the receiver type.\n")
+		fmt.Fprintf(&buf, "return new(%s)\n}\n", typeName)
+	} else {
+		values := &typeutil.ValueConfig{Package: pkg, Qualifier: qual}
+		if xtest {
+			values.Package = types.NewPackage(pkg.Path()+"_test", pkg.Name()+"_test")
+		}
+		server := false // some argument refers to the test HTTP server
+
+		// call returns the call of the function or method fn, through
+		// the variable recv if it is a method, with default arguments.
+		// The variadic parameter, if any, takes none.
+		call := func(fn *types.Func, recv string) string {
+			sig := fn.Signature()
+			n := sig.Params().Len()
+			if sig.Variadic() {
+				n--
+			}
+			var args []string
+			for i := range n {
+				param := sig.Params().At(i)
+				arg, _ := typeutil.DefaultValue(param.Type(), values)
+				if isFSType(param.Type()) {
+					arg = qual(types.NewPackage("testing/fstest", "fstest")) + ".MapFS{}"
+				} else if temp, _ := tempDefault(param.Name(), param.Type(), qual); temp != "" {
+					arg = temp
+				} else if expr, _ := httpDefault(param.Name(), param.Type(), values.Package, qual); expr != "" {
+					arg, server = expr, true
+				}
+				args = append(args, arg)
+			}
+			call := fmt.Sprintf("%s(%s)", fn.Name(), strings.Join(args, ", "))
+			if recv != "" {
+				call = recv + "." + call
+			} else if prefix := qual(pkg); prefix != "" {
+				call = prefix + "." + call
+			}
+			return call
+		}
+
+		errorType := types.Universe.Lookup("error").Type()
+		var (
+			stmts  bytes.Buffer // the statements of the helper
+			helper bool         // t.Helper has been called
+		)
+
+		// assign writes the statements that assign the results of the
+		// call of the constructor fn to the variable v, failing the
+		// test with the message msg if it returns an error, and
+		// releasing the resources of v when the test completes. Unless
+		// force is set, it writes nothing if the call may instead be
+		// returned directly. It reports whether it wrote anything.
+		assign := func(v string, fn *types.Func, call, msg string, force bool) bool {
+			sig := fn.Signature()
+			n := sig.Results().Len()
+			cleanup := cleanupStmt(v, sig.Results().At(0).Type(), qual)
+			if n == 1 && cleanup == "" && !force {
+				return false
+			}
+			lhs := []string{v}
+			for i := 1; i < n; i++ {
+				if i == n-1 && types.Identical(sig.Results().At(i).Type(), errorType) {
+					lhs = append(lhs, "err")
+				} else {
+					lhs = append(lhs, "_")
+				}
+			}
+			if lhs[n-1] == "err" && !helper {
+				stmts.WriteString("t.Helper()\n")
+				helper = true
+			}
+			fmt.Fprintf(&stmts, "%s := %s\n", strings.Join(lhs, ", "), call)
+			if lhs[n-1] == "err" {
+				stmts.WriteString("if err != nil {\n")
+				fmt.Fprintf(&stmts, "t.Fatalf(%q, err)\n", msg+": %v")
+				stmts.WriteString("}\n")
+			}
+			if cleanup != "" {
+				fmt.Fprintf(&stmts, "%s\n", cleanup)
+			}
+			return true
+		}
+
+		avoid := map[string]bool{"t": true, "err": true, serverVar: true}
+		v := typesutil.VarName(t, avoid)
+		recv := ""
+		if sig := constructor.Signature(); sig.Recv() != nil {
+			// Construct the factory, one level deep: by its own
+			// constructor, if any, or else as a zero value.
+			_, factory := typesinternal.ReceiverNamed(sig.Recv())
+			avoid[v] = true
+			recv = typesutil.VarName(factory, avoid)
+			if ctor := receiverConstructor(ctors, pkg, factory, xtest); ctor != nil {
+				assign(recv, ctor, call(ctor, ""), "could not construct factory", true)
+			} else {
+				fmt.Fprintf(&stmts, "var %s %s\n", recv, types.TypeString(factory, qual))
+			}
+		}
+		ctorCall := call(constructor, recv)
+		result := types.TypeString(constructor.Signature().Results().At(0).Type(), qual)
+		fmt.Fprintf(&buf, "func %s(t *%s.T) %s {\n", name, testingName, result)
+		if server {
+			fmt.Fprintf(&buf, "%s\n", serverStmt(qual))
+		}
+		ret := ctorCall
+		if assign(v, constructor, ctorCall, "could not construct receiver type", false) {
+			ret = v
+		}
+		buf.Write(stmts.Bytes())
+		fmt.Fprintf(&buf, "return %s\n}\n", ret)
+	}
+	return format.Source(buf.Bytes())
+}
+
+// cleanupMethods are the names of the methods that release the
+// resources of a value, in order of preference.
+var cleanupMethods = []string{"Close", "Shutdown", "Stop"}
+
+// cleanupStmt returns the statement that releases the resources of
+// the variable v of type typ when the test t and its subtests
+// complete, by calling its Close, Shutdown, or Stop method, or "" if it
+// has none. The method must take no arguments, or just a context, and
+// return nothing, or just an error, which is ignored.
+func cleanupStmt(v string, typ types.Type, qual types.Qualifier) string {
+	for _, name := range cleanupMethods {
+		obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, name)
+		m, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := m.Signature()
+		var arg string
+		switch sig.Params().Len() {
+		case 0:
+		case 1:
+			if !analysisinternal.IsTypeNamed(sig.Params().At(0).Type(), "context", "Context") {
+				continue
+			}
+			arg = qual(types.NewPackage("context", "context")) + ".Background()"
+		default:
+			continue
+		}
+		switch sig.Results().Len() {
+		case 0:
+			return fmt.Sprintf("t.Cleanup(func() { %s.%s(%s) })", v, name, arg)
+		case 1:
+			if types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type()) {
+				return fmt.Sprintf("t.Cleanup(func() { _ = %s.%s(%s) })", v, name, arg)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the choice of the names of the imports that
+// generated tests add to test files, which must not be shadowed by the
+// declarations of the test package or of the tests themselves.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/internal/imports"
+)
+
+// testScopeNames returns the names that the new imports of a test file
+// of the package tp must not take, lest they shadow or be shadowed by
+// them: the names declared at package level by the files of its test
+// package, that is, the _test.go files of the package, or of its
+// external test package if xtest, and, unless xtest, its other files.
+func testScopeNames(ctx context.Context, snapshot *cache.Snapshot, tp testedPackage, xtest bool) (map[string]bool, error) {
+	uris := testPackageFiles(snapshot, tp.mp, xtest)
+	if !xtest {
+		for _, uri := range tp.mp.CompiledGoFiles {
+			if !strings.HasSuffix(uri.Path(), "_test.go") {
+				uris = append(uris, uri)
+			}
+		}
+	}
+	names := make(map[string]bool)
+	for _, uri := range uris {
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+		if err != nil {
+			return nil, err
+		}
+		addPackageNames(names, pgf.File)
+	}
+	return names, nil
+}
+
+// addPackageNames adds to names the names declared at package level by
+// the file.
+func addPackageNames(names map[string]bool, file *ast.File) {
+	add := func(id *ast.Ident) {
+		if id != nil && id.Name != "_" {
+			names[id.Name] = true
+		}
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				add(decl.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add(spec.Name)
+				case *ast.ValueSpec:
+					for _, id := range spec.Names {
+						add(id)
+					}
+				}
+			}
+		}
+	}
+}
+
+// localNames returns the names declared within the functions of src, a
+// sequence of function declarations such as a generated test and its
+// helper, by which they may shadow imports of the same names.
+func localNames(src []byte) map[string]bool {
+	names := make(map[string]bool)
+	f, err := parser.ParseFile(token.NewFileSet(), "", append([]byte("package p\n"), src...), parser.SkipObjectResolution)
+	if err != nil {
+		return names
+	}
+	add := func(exprs ...ast.Expr) {
+		for _, e := range exprs {
+			if id, ok := e.(*ast.Ident); ok && id.Name != "_" {
+				names[id.Name] = true
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncType:
+			for _, list := range []*ast.FieldList{n.Params, n.Results} {
+				if list == nil {
+					continue
+				}
+				for _, field := range list.List {
+					for _, id := range field.Names {
+						add(id)
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				add(n.Lhs...)
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				add(n.Key, n.Value)
+			}
+		case *ast.ValueSpec:
+			for _, id := range n.Names {
+				add(id)
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// importNames returns the names by which a file refers to the packages
+// it imports, given the map imps from their paths to their local names,
+// if explicit: the local name, or else the name of the package, if the
+// graph holds it, or else the name assumed from its path. Names that
+// two imports of different paths share, such as the log of a/log in
+// foo.go and that of b/log in foo_test.go, thus conflict even if the
+// packages are not named after their paths.
+func importNames(graph *metadata.Graph, imps map[string]string) map[string]string {
+	names := make(map[string]string)
+	for path, local := range imps {
+		if local != "" {
+			names[path] = local
+		}
+	}
+	if len(names) < len(imps) {
+		for _, mp := range graph.Packages {
+			path := string(mp.PkgPath)
+			if local, ok := imps[path]; ok && local == "" && mp.Name != "" {
+				names[path] = string(mp.Name)
+			}
+		}
+	}
+	for path := range imps {
+		if _, ok := names[path]; !ok {
+			names[path] = imports.ImportPathToAssumedName(path)
+		}
+	}
+	return names
+}
+
+// freshImportName returns name, if not taken, or else the first of
+// name2, name3, and so on, that is not, such as context2.
+func freshImportName(name string, taken map[string]bool) string {
+	fresh := name
+	for i := 2; taken[fresh]; i++ {
+		fresh = fmt.Sprintf("%s%d", name, i)
+	}
+	return fresh
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/util/moremaps"
+)
+
+func TestLocalNames(t *testing.T) {
+	const src = `
+func TestF(t *testing.T) {
+	tests := []struct{ name string }{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var url string
+			got, gotErr := F(url)
+			_ = got
+			_ = gotErr
+		})
+	}
+}
+`
+	got := moremaps.KeySlice(localNames([]byte(src)))
+	slices.Sort(got)
+	want := []string{"got", "gotErr", "t", "tests", "tt", "url"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("localNames = %q, want %q", got, want)
+	}
+}
+
+func TestAddPackageNames(t *testing.T) {
+	const src = `package p
+
+import "context"
+
+type T int
+
+func (T) M() {}
+
+func F() {}
+
+var x, _ = 1, 2
+
+const c = 3
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	addPackageNames(names, f)
+	want := map[string]bool{"T": true, "F": true, "x": true, "c": true}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("addPackageNames = %v, want %v", names, want)
+	}
+}
+
+func TestImportNames(t *testing.T) {
+	graph := &metadata.Graph{Packages: map[metadata.PackageID]*metadata.Package{
+		"b/log":       {ID: "b/log", PkgPath: "b/log", Name: "log"},
+		"c/go-yaml":   {ID: "c/go-yaml", PkgPath: "c/go-yaml", Name: "yamlv2"},
+		"d/unrelated": {ID: "d/unrelated", PkgPath: "d/unrelated", Name: "unrelated"},
+	}}
+	got := importNames(graph, map[string]string{
+		"a/log":     "alog",
+		"b/log":     "",
+		"c/go-yaml": "",
+		"e/go-json": "",
+	})
+	want := map[string]string{
+		"a/log":     "alog",
+		"b/log":     "log",
+		"c/go-yaml": "yamlv2",
+		"e/go-json": "json",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("importNames = %v, want %v", got, want)
+	}
+}
+
+func TestFreshImportName(t *testing.T) {
+	taken := map[string]bool{"context": true, "context2": true, "url": true}
+	for name, want := range map[string]string{
+		"context": "context3",
+		"url":     "url2",
+		"http":    "http",
+	} {
+		if got := freshImportName(name, taken); got != want {
+			t.Errorf("freshImportName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the values of the input parameters of generated
+// tests that the test itself must provide, such as temporary files,
+// file systems, HTTP servers, and mock databases.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/analysisinternal"
+)
+
+// tempDefault returns an expression for a temporary directory or file
+// that is a suitable value for a parameter of a function under test
+// of the specified name and type, and the zero value of the type, or
+// two empty strings if the parameter denotes no such thing. The
+// expression refers to the *testing.T of the test as t, whose
+// TempDir is removed when the test completes.
+//
+// A parameter of type string denotes a directory if its name, or its
+// last word, is dir, directory, or root, such as baseDir, and a file
+// if it is path, file, or filename, such as configPath.
+func tempDefault(name string, typ types.Type, qual types.Qualifier) (expr, zero string) {
+	if !types.Identical(typ, types.Typ[types.String]) {
+		return "", ""
+	}
+	switch word := lastWord(name); {
+	case strings.HasSuffix(word, "dir") || word == "directory" || word == "root":
+		return "t.TempDir()", `""`
+	case word == "path" || word == "file" || word == "filename" || word == "filepath":
+		filepath := qual(types.NewPackage("path/filepath", "filepath"))
+		return filepath + `.Join(t.TempDir(), "` + word + `")`, `""`
+	}
+	return "", ""
+}
+
+// lastWord returns the last word of a name in camel or snake case,
+// in lower case: for example, "dir" for baseDir and base_dir.
+func lastWord(name string) string {
+	name = name[strings.LastIndex(name, "_")+1:]
+	start := 0
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 && !unicode.IsUpper(rune(name[i-1])) {
+			start = i
+		}
+	}
+	return strings.ToLower(name[start:])
+}
+
+// isFSType reports whether t is the fs.FS interface type.
+func isFSType(t types.Type) bool {
+	return analysisinternal.IsTypeNamed(t, "io/fs", "FS")
+}
+
+// derivedField returns the name of the test case field from which the
+// test derives the argument of the ith of the parameters, a named
+// parameter whose type satisfies match: name, such as files, unless
+// another named parameter has such a type or is itself so named, in
+// which case the name of the parameter followed by name, such as
+// srcFiles.
+func derivedField(params *types.Tuple, i int, name string, match func(types.Type) bool) string {
+	for j := range params.Len() {
+		if other := params.At(j); j != i && other.Name() != "" && other.Name() != "_" &&
+			(match(other.Type()) || other.Name() == name) {
+			return params.At(i).Name() + exportedName(name)
+		}
+	}
+	return name
+}
+
+// mapFSStmt returns the statements that declare the variable v, an
+// fstest.MapFS of the files of the expression files, of type
+// map[string]string, which maps the name of each file to its contents.
+func mapFSStmt(v, files string, qual types.Qualifier) string {
+	fstest := qual(types.NewPackage("testing/fstest", "fstest"))
+	return fmt.Sprintf(`%[1]s := make(%[2]s.MapFS)
+for name, data := range %[3]s {
+	%[1]s[name] = &%[2]s.MapFile{Data: []byte(data)}
+}`, v, fstest, files)
+}
+
+// serverVar is the name of the variable of the test HTTP server that a
+// generated test starts for the parameters that denote one.
+const serverVar = "srv"
+
+// httpDefault returns an expression for the base URL or a client of
+// the test HTTP server [serverVar] that is a suitable value for a
+// parameter of a function under test of the specified name and type,
+// as seen from package from, and the zero value of the type, or two
+// empty strings if the parameter denotes no such thing.
+//
+// A parameter of type string denotes the base URL of a server if its
+// name, or its last word, is url or endpoint, such as baseURL. A
+// parameter of type *http.Client denotes a client of the server, as
+// does a pointer to a struct type with a field URL or BaseURL of type
+// string, such as *Client, which is set to the server's URL.
+func httpDefault(name string, typ types.Type, from *types.Package, qual types.Qualifier) (expr, zero string) {
+	if types.Identical(typ, types.Typ[types.String]) {
+		if word := lastWord(name); word == "url" || word == "endpoint" {
+			return serverVar + ".URL", `""`
+		}
+		return "", ""
+	}
+	ptr, ok := types.Unalias(typ).(*types.Pointer)
+	if !ok {
+		return "", ""
+	}
+	if analysisinternal.IsTypeNamed(ptr.Elem(), "net/http", "Client") {
+		return serverVar + ".Client()", "nil"
+	}
+	named, ok := types.Unalias(ptr.Elem()).(*types.Named)
+	if !ok || named.TypeParams() != nil || !named.Obj().Exported() && named.Obj().Pkg() != from {
+		return "", ""
+	}
+	if st, ok := named.Underlying().(*types.Struct); ok {
+		for field := range st.Fields() {
+			if (field.Name() == "URL" || field.Name() == "BaseURL") && !field.Embedded() &&
+				types.Identical(field.Type(), types.Typ[types.String]) {
+				return fmt.Sprintf("&%s{%s: %s.URL}", types.TypeString(named, qual), field.Name(), serverVar), "nil"
+			}
+		}
+	}
+	return "", ""
+}
+
+// serverStmt returns the statements that start the test HTTP server
+// [serverVar], with a handler for the user to complete, and close it
+// when the test completes.
+func serverStmt(qual types.Qualifier) string {
+	http := qual(types.NewPackage("net/http", "http"))
+	httptest := qual(types.NewPackage("net/http/httptest", "httptest"))
+	return fmt.Sprintf(`%[1]s := %[3]s.NewServer(%[2]s.HandlerFunc(func(w %[2]s.ResponseWriter, r *%[2]s.Request) {
+	// TODO: respond to the requests of the test case.
+}))
+t.Cleanup(%[1]s.Close)`, serverVar, http, httptest)
+}
+
+// sqlmockPath is the path of the go-sqlmock package, which generated
+// tests use to construct the *sql.DB parameters of functions, if the
+// module of the test requires it.
+const sqlmockPath = "github.com/DATA-DOG/go-sqlmock"
+
+// optionalTestPackages maps the path of each package that generated
+// tests use if it is available to the name of the package.
+var optionalTestPackages = map[string]string{sqlmockPath: "sqlmock"}
+
+// TestInputs records the optional packages that generated tests may
+// use to construct the inputs of the functions they test, such as
+// [sqlmockPath], by path.
+type TestInputs map[string]bool
+
+// TestInputsOf returns the optional packages that the test file
+// imports.
+func TestInputsOf(file *ast.File) TestInputs {
+	inputs := make(TestInputs)
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil && optionalTestPackages[path] != "" {
+			inputs[path] = true
+		}
+	}
+	return inputs
+}
+
+// moduleTestInputs returns the optional packages that the module of
+// package mp requires.
+func moduleTestInputs(ctx context.Context, snapshot *cache.Snapshot, mp *metadata.Package) (TestInputs, error) {
+	inputs := make(TestInputs)
+	if mp.Module == nil || mp.Module.GoMod == "" {
+		return inputs, nil
+	}
+	fh, err := snapshot.ReadFile(ctx, protocol.URIFromPath(mp.Module.GoMod))
+	if err != nil {
+		return nil, err
+	}
+	pm, err := snapshot.ParseMod(ctx, fh)
+	if err != nil || pm.File == nil {
+		return inputs, nil // ignore malformed go.mod files
+	}
+	for _, req := range pm.File.Require {
+		if optionalTestPackages[req.Mod.Path] != "" {
+			inputs[req.Mod.Path] = true
+		}
+	}
+	return inputs, nil
+}
+
+// usedIn returns the subset of the optional packages to which node n
+// refers, by their package names.
+func (inputs TestInputs) usedIn(n ast.Node) TestInputs {
+	used := make(TestInputs)
+	ast.Inspect(n, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				for path := range inputs {
+					if optionalTestPackages[path] == id.Name {
+						used[path] = true
+					}
+				}
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// isSQLDBType reports whether t is the type *sql.DB.
+func isSQLDBType(t types.Type) bool {
+	ptr, ok := types.Unalias(t).(*types.Pointer)
+	return ok && analysisinternal.IsTypeNamed(ptr.Elem(), "database/sql", "DB")
+}
+
+// sqlMockStmt returns the statements that declare the variable v, a
+// *sql.DB backed by go-sqlmock, and its mock, and apply to the mock
+// the expectations of the expression expect, of type
+// func(sqlmock.Sqlmock), if set. When the test completes, they check
+// that the expectations were met, and close the database.
+func sqlMockStmt(v, expect string, qual types.Qualifier) string {
+	sqlmock := qual(types.NewPackage(sqlmockPath, "sqlmock"))
+	return fmt.Sprintf(`%[1]s, %[1]sMock, err := %[2]s.New()
+if err != nil {
+	t.Fatalf("could not create mock database: %%v", err)
+}
+t.Cleanup(func() {
+	if err := %[1]sMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet database expectations: %%v", err)
+	}
+	%[1]s.Close()
+})
+if %[3]s != nil {
+	%[3]s(%[1]sMock)
+}`, v, sqlmock, expect)
+}
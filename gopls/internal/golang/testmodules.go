@@ -0,0 +1,222 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the updates to the go.mod and go.sum files of a
+// module that the imports of generated tests require, so that the
+// tests build immediately, in module mode as well as in the workspace.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/metadata"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/util/moremaps"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/event"
+)
+
+// zeroPseudoVersion is the version at which a go.mod file requires a
+// module that it replaces by a directory, as written by go mod tidy.
+const zeroPseudoVersion = "v0.0.0-00010101000000-000000000000"
+
+// testModFile returns the go.mod file of the module to which the test
+// files of package mp belong, which is that of mp, or else that of the
+// innermost workspace module whose directory contains the package, or
+// "" if there is none.
+func testModFile(snapshot *cache.Snapshot, mp *metadata.Package) protocol.DocumentURI {
+	if mp.Module != nil && mp.Module.GoMod != "" {
+		return protocol.URIFromPath(mp.Module.GoMod)
+	}
+	if len(mp.CompiledGoFiles) == 0 {
+		return ""
+	}
+	dir := mp.CompiledGoFiles[0].Dir()
+	var modURI protocol.DocumentURI
+	for _, uri := range snapshot.View().ModFiles() {
+		if uri.Dir().Encloses(dir) && len(uri) > len(modURI) {
+			modURI = uri
+		}
+	}
+	return modURI
+}
+
+// testPackageModules maps the path of each third-party package that
+// generated tests may import to the path of its module.
+var testPackageModules = map[string]string{
+	testifyAssertPath:              testifyModule,
+	testifyRequirePath:             testifyModule,
+	"github.com/google/go-cmp/cmp": "github.com/google/go-cmp",
+	"go.uber.org/goleak":           "go.uber.org/goleak",
+	sqlmockPath:                    sqlmockPath,
+}
+
+// testModuleChanges returns the changes to the go.mod file modURI, and
+// to its go.sum file, that the packages of the specified paths, which
+// the tests of its module import anew, require, or nil if there are
+// none.
+//
+// For each workspace module, other than its own, that provides one of
+// the packages and that the file does not require, it adds a
+// requirement of the module, and, as the requirement is satisfied by
+// the workspace, it replaces the module by its directory, unless it
+// replaces it already.
+//
+// For each third-party module that provides one of the packages (see
+// [testPackageModules]) and that the file does not require, it runs go
+// get, which adds the requirement of its latest version, and updates
+// the go.sum file. If go get fails, such as for want of network
+// access, the imports are left unresolved, and their diagnostics offer
+// to go get the packages.
+func testModuleChanges(ctx context.Context, snapshot *cache.Snapshot, modURI protocol.DocumentURI, paths iter.Seq[string]) ([]protocol.DocumentChange, error) {
+	if modURI == "" {
+		return nil, nil
+	}
+	mods := workspaceModules(snapshot, paths) // module directories, by path
+	third := make(map[string]bool)            // third-party modules
+	for path := range paths {
+		if mod, ok := testPackageModules[path]; ok && mods[mod] == "" {
+			third[mod] = true
+		}
+	}
+	if len(mods) == 0 && len(third) == 0 {
+		return nil, nil
+	}
+	fh, err := snapshot.ReadFile(ctx, modURI)
+	if err != nil {
+		return nil, err
+	}
+	pm, err := snapshot.ParseMod(ctx, fh)
+	if err != nil || pm.File == nil || pm.File.Module == nil {
+		return nil, nil // leave malformed go.mod files alone
+	}
+	delete(mods, pm.File.Module.Mod.Path)
+	for _, req := range pm.File.Require {
+		delete(mods, req.Mod.Path)
+		delete(third, req.Mod.Path)
+	}
+	if len(mods) == 0 && len(third) == 0 {
+		return nil, nil
+	}
+
+	var changes []protocol.DocumentChange
+	content := pm.Mapper.Content
+	if len(third) > 0 {
+		modBytes, sumBytes, err := snapshot.RunGoModUpdateCommands(ctx, modURI, func(invoke func(...string) (*bytes.Buffer, error)) error {
+			_, err := invoke(append([]string{"get"}, slices.Sorted(maps.Keys(third))...)...)
+			return err
+		})
+		if err != nil {
+			event.Error(ctx, "requiring the modules of generated tests", err)
+		} else {
+			content = modBytes
+			sumURI := protocol.URIFromPath(strings.TrimSuffix(modURI.Path(), ".mod") + ".sum")
+			sumChanges, err := replaceContentChanges(ctx, snapshot, sumURI, sumBytes)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, sumChanges...)
+		}
+	}
+
+	if len(mods) > 0 {
+		replaced := make(map[string]bool)
+		for _, r := range pm.File.Replace {
+			replaced[r.Old.Path] = true
+		}
+		// We need a private copy of the parsed go.mod file, since we're
+		// going to modify it.
+		copied, err := modfile.Parse("", content, nil)
+		if err != nil {
+			return nil, err
+		}
+		modDir := modURI.DirPath()
+		for path, dir := range moremaps.Sorted(mods) {
+			if err := copied.AddRequire(path, zeroPseudoVersion); err != nil {
+				return nil, err
+			}
+			if replaced[path] {
+				continue
+			}
+			rel, err := filepath.Rel(modDir, dir)
+			if err != nil {
+				return nil, err
+			}
+			rel = filepath.ToSlash(rel)
+			if !strings.HasPrefix(rel, "../") {
+				rel = "./" + rel
+			}
+			if err := copied.AddReplace(path, "", rel, ""); err != nil {
+				return nil, err
+			}
+		}
+		copied.Cleanup()
+		if content, err = copied.Format(); err != nil {
+			return nil, fmt.Errorf("formatting %s: %v", modURI.Path(), err)
+		}
+	}
+	modChanges, err := replaceContentChanges(ctx, snapshot, modURI, content)
+	if err != nil {
+		return nil, err
+	}
+	return append(modChanges, changes...), nil
+}
+
+// replaceContentChanges returns the changes that replace the content
+// of the file uri, which need not exist, by the specified content, or
+// nil if it has that content already.
+func replaceContentChanges(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI, content []byte) ([]protocol.DocumentChange, error) {
+	fh, err := snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	old, err := fh.Content()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if bytes.Equal(old, content) {
+		return nil, nil
+	}
+	var changes []protocol.DocumentChange
+	if err != nil {
+		changes = append(changes, protocol.DocumentChangeCreate(uri))
+	}
+	edits, err := protocol.EditsFromDiffEdits(protocol.NewMapper(uri, old), diff.Bytes(old, content))
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, protocol.DocumentChangeEdit(fh, edits)), nil
+}
+
+// workspaceModules returns the directories, by module path, of the
+// workspace modules that provide the packages of the specified paths.
+func workspaceModules(snapshot *cache.Snapshot, paths iter.Seq[string]) map[string]string {
+	workspace := make(map[protocol.DocumentURI]bool)
+	for _, uri := range snapshot.View().ModFiles() {
+		workspace[uri] = true
+	}
+	want := make(map[PackagePath]bool)
+	for path := range paths {
+		want[PackagePath(path)] = true
+	}
+	mods := make(map[string]string)
+	for _, mp := range snapshot.MetadataGraph().Packages {
+		if want[mp.PkgPath] && mp.ForTest == "" && mp.Module != nil && mp.Module.GoMod != "" &&
+			workspace[protocol.URIFromPath(mp.Module.GoMod)] {
+			mods[mp.Module.Path] = mp.Module.Dir
+		}
+	}
+	return mods
+}
@@ -0,0 +1,261 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines navigation between functions and their tests.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
+	"golang.org/x/tools/gopls/internal/protocol"
+	goplsastutil "golang.org/x/tools/gopls/internal/util/astutil"
+)
+
+var (
+	fuzzRe    = regexp.MustCompile(`^Fuzz([^a-z]|$)`)
+	exampleRe = regexp.MustCompile(`^Example([^a-z]|$)`)
+)
+
+// TestCounterparts returns the locations of the counterparts of the
+// function or method declaration enclosing loc: the functions and
+// methods exercised by a Test, Benchmark, Fuzz, or Example function,
+// or otherwise the tests that exercise them.
+//
+// A test exercises the function or method after which it is named,
+// such as Parse for TestParse or TestParseError, or T.M for TestT_M,
+// and each function or method of the package under test that it calls
+// directly, including from function literals; see
+// [testfuncs.Subjects].
+func TestCounterparts(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) ([]protocol.Location, error) {
+	nav, pgf, err := newTestNavigator(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	var decl *ast.FuncDecl
+	for _, n := range path {
+		if n, ok := n.(*ast.FuncDecl); ok {
+			decl = n
+			break
+		}
+	}
+	if decl == nil || decl.Body == nil {
+		return nil, fmt.Errorf("no function declaration selected")
+	}
+	locs, isTest, err := nav.counterparts(ctx, pgf, decl)
+	if err != nil {
+		return nil, err
+	}
+	if len(locs) == 0 {
+		if isTest {
+			return nil, fmt.Errorf("no functions exercised by %s found", decl.Name.Name)
+		}
+		return nil, fmt.Errorf("no tests of %s found", decl.Name.Name)
+	}
+	return locs, nil
+}
+
+// A testNavigator relates the functions of a package to their tests,
+// using the snapshot's relation between the two.
+type testNavigator struct {
+	snapshot *cache.Snapshot
+	path     PackagePath                            // of the package under test
+	rel      *testfuncs.Relation                    // of the package under test
+	subjects map[string]protocol.Location           // declarations of the package under test, by name; see subject
+	files    map[protocol.DocumentURI]*parsego.File // parsed files, by URI
+}
+
+// newTestNavigator returns a navigator for the package under test of
+// the specified file, along with the parsed file.
+func newTestNavigator(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI) (*testNavigator, *parsego.File, error) {
+	mps, err := snapshot.MetadataForFile(ctx, uri)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(mps) == 0 {
+		return nil, nil, fmt.Errorf("no package metadata for file %s", uri)
+	}
+	nav := &testNavigator{
+		snapshot: snapshot,
+		path:     mps[0].PkgPath,
+		files:    make(map[protocol.DocumentURI]*parsego.File),
+	}
+	if mps[0].ForTest != "" {
+		nav.path = mps[0].ForTest
+	}
+	nav.rel, err = snapshot.TestRelation(ctx, nav.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	pgf, err := nav.parse(ctx, uri)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nav, pgf, nil
+}
+
+// counterparts returns the locations of the counterparts of the
+// function declaration decl in the specified file, and reports whether
+// decl is a test, in which case they are the functions that it
+// exercises; see [TestCounterparts].
+func (nav *testNavigator) counterparts(ctx context.Context, pgf *parsego.File, decl *ast.FuncDecl) (_ []protocol.Location, isTest bool, _ error) {
+	var locs []protocol.Location
+	if strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+		if decl.Recv != nil {
+			return nil, false, nil
+		}
+		for _, test := range nav.rel.Tests() {
+			if test.Location.URI == pgf.URI && test.Name == decl.Name.Name {
+				for _, subject := range test.Subjects {
+					loc, err := nav.subject(ctx, subject)
+					if err != nil {
+						return nil, false, err
+					}
+					if loc != (protocol.Location{}) {
+						locs = append(locs, loc)
+					}
+				}
+				return locs, true, nil
+			}
+		}
+		return nil, false, nil // not a test, and declarations of test files are never tested
+	}
+
+	name, ok := testableName(pgf.File, decl)
+	if !ok {
+		return nil, false, nil
+	}
+	for _, test := range nav.rel.TestsOf(name) {
+		testPGF, err := nav.parse(ctx, test.Location.URI)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, decl := range testPGF.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil && decl.Name.Name == test.Name {
+				loc, err := testPGF.NodeLocation(decl.Name)
+				if err != nil {
+					return nil, false, err
+				}
+				locs = append(locs, loc)
+				break
+			}
+		}
+	}
+	return locs, false, nil
+}
+
+// subject returns the location of the name of the declaration in the
+// non-test files of the package under test of the specified subject:
+// a function or type F, or a method T.M, including the method M of an
+// interface type T. It returns the zero location if there is none.
+func (nav *testNavigator) subject(ctx context.Context, subject string) (protocol.Location, error) {
+	if nav.subjects == nil {
+		nav.subjects = make(map[string]protocol.Location)
+		var uris []protocol.DocumentURI
+		for _, mp := range nav.snapshot.MetadataGraph().Packages {
+			if mp.PkgPath == nav.path {
+				for _, uri := range mp.CompiledGoFiles {
+					if !strings.HasSuffix(uri.Path(), "_test.go") {
+						uris = append(uris, uri)
+					}
+				}
+			}
+		}
+		slices.Sort(uris)
+		for _, uri := range slices.Compact(uris) {
+			pgf, err := nav.parse(ctx, uri)
+			if err != nil {
+				return protocol.Location{}, err
+			}
+			add := func(name string, id *ast.Ident) error {
+				loc, err := pgf.NodeLocation(id)
+				if err != nil {
+					return err
+				}
+				nav.subjects[name] = loc
+				return nil
+			}
+			for _, decl := range pgf.File.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					name := decl.Name.Name
+					if decl.Recv != nil {
+						_, recv, _ := goplsastutil.UnpackRecv(decl.Recv.List[0].Type)
+						if recv == nil {
+							continue
+						}
+						name = recv.Name + "." + name
+					}
+					if err := add(name, decl.Name); err != nil {
+						return protocol.Location{}, err
+					}
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						spec, ok := spec.(*ast.TypeSpec)
+						if !ok {
+							continue
+						}
+						if err := add(spec.Name.Name, spec.Name); err != nil {
+							return protocol.Location{}, err
+						}
+						if iface, ok := spec.Type.(*ast.InterfaceType); ok {
+							for _, field := range iface.Methods.List {
+								for _, id := range field.Names {
+									if err := add(spec.Name.Name+"."+id.Name, id); err != nil {
+										return protocol.Location{}, err
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return nav.subjects[subject], nil
+}
+
+// parse returns the parsed file of the specified URI.
+func (nav *testNavigator) parse(ctx context.Context, uri protocol.DocumentURI) (*parsego.File, error) {
+	if pgf, ok := nav.files[uri]; ok {
+		return pgf, nil
+	}
+	fh, err := nav.snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	pgf, err := nav.snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
+	}
+	nav.files[uri] = pgf
+	return pgf, nil
+}
+
+// testsOf returns the names of the tests, other than benchmarks, that
+// exercise the function or method of the specified name, F or T.M,
+// according to rel; see [testfuncs.Subjects].
+func testsOf(rel *testfuncs.Relation, name string) []string {
+	var tests []string
+	for _, test := range rel.TestsOf(name) {
+		if !benchmarkRe.MatchString(test.Name) {
+			tests = append(tests, test.Name)
+		}
+	}
+	return tests
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the "Reorder tests to match source order" code action.
+
+import (
+	"cmp"
+	"context"
+	"go/ast"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/diff"
+)
+
+// organizeTests produces the "Reorder tests to match source order"
+// code action in a _test.go file whose tests are not in that order.
+func organizeTests(ctx context.Context, req *codeActionsRequest) error {
+	if !strings.HasSuffix(req.fh.URI().Path(), "_test.go") {
+		return nil
+	}
+	edits, err := testOrderEdits(ctx, req.snapshot, req.fh.URI())
+	if err != nil || len(edits) == 0 {
+		return nil
+	}
+	req.addEditAction("Reorder tests to match source order", nil, protocol.DocumentChangeEdit(req.fh, edits))
+	return nil
+}
+
+// testOrderEdits returns the edits that reorder the Test, Benchmark,
+// Fuzz, and Example functions of the specified test file to follow
+// the order of declaration of their subjects (see
+// [testfuncs.Subjects]) in the package under test, tests first, then
+// benchmarks, fuzz targets, and examples. Functions without subjects
+// follow those of the same kind with subjects, in their original
+// order. Each function moves with its doc comment; other declarations,
+// and comments between declarations, stay in place.
+//
+// It returns no edits if the tests are already in order.
+func testOrderEdits(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI) ([]protocol.TextEdit, error) {
+	nav, pgf, err := newTestNavigator(ctx, snapshot, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	// A testDecl is a test function of the file.
+	type testDecl struct {
+		decl       *ast.FuncDecl
+		start, end int               // offsets of the declaration and its doc comment
+		kind       int               // 0: Test, 1: Benchmark, 2: Fuzz, 3: Example
+		subject    protocol.Location // zero if none
+	}
+	var tests []testDecl
+	for _, decl := range pgf.File.Decls {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || decl.Recv != nil {
+			continue
+		}
+		i := slices.IndexFunc(nav.rel.Tests(), func(test testfuncs.Result) bool {
+			return test.Location.URI == uri && test.Name == decl.Name.Name
+		})
+		if i < 0 {
+			continue // not a test
+		}
+		test := nav.rel.Tests()[i]
+		td := testDecl{decl: decl}
+		switch {
+		case benchmarkRe.MatchString(decl.Name.Name):
+			td.kind = 1
+		case fuzzRe.MatchString(decl.Name.Name):
+			td.kind = 2
+		case exampleRe.MatchString(decl.Name.Name):
+			td.kind = 3
+		}
+		if len(test.Subjects) > 0 {
+			td.subject, err = nav.subject(ctx, test.Subjects[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+		td.start, td.end, err = declOffsets(pgf, decl)
+		if err != nil {
+			return nil, err
+		}
+		tests = append(tests, td)
+	}
+
+	sorted := slices.Clone(tests)
+	slices.SortStableFunc(sorted, func(x, y testDecl) int {
+		if c := cmp.Compare(x.kind, y.kind); c != 0 {
+			return c
+		}
+		// Tests without subjects come last.
+		if x.subject.URI == "" || y.subject.URI == "" {
+			return -strings.Compare(string(x.subject.URI), string(y.subject.URI))
+		}
+		if c := cmp.Compare(x.subject.URI, y.subject.URI); c != 0 {
+			return c
+		}
+		return protocol.ComparePosition(x.subject.Range.Start, y.subject.Range.Start)
+	})
+
+	// Put the sorted tests in the places of the original ones.
+	var edits []diff.Edit
+	for i, test := range tests {
+		if sorted[i].decl != test.decl {
+			edits = append(edits, diff.Edit{
+				Start: test.start,
+				End:   test.end,
+				New:   string(pgf.Src[sorted[i].start:sorted[i].end]),
+			})
+		}
+	}
+	return protocol.EditsFromDiffEdits(pgf.Mapper, edits)
+}
+
+// declOffsets returns the offsets of the function declaration decl,
+// including its doc comment.
+func declOffsets(pgf *parsego.File, decl *ast.FuncDecl) (int, int, error) {
+	start, end, err := pgf.NodeOffsets(decl)
+	if err != nil {
+		return 0, 0, err
+	}
+	if decl.Doc != nil {
+		start, _, err = pgf.NodeOffsets(decl.Doc)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, end, nil
+}
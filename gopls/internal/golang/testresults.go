@@ -0,0 +1,231 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the correlation of the events of "go test -json"
+// into the results of packages and tests.
+
+import (
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/protocol/command"
+)
+
+// TestResults correlates the events of one or more runs of "go test
+// -json" into the results of each package and test, as reported by
+// the StreamTests command.
+//
+// The events of the tests of a package may be interleaved, as when
+// tests run in parallel, as may those of several packages; each event
+// is attributed to its package and test. A test that has not finished
+// when its package does, such as one still running when another test
+// panicked, fails, as does a package that has not finished when the
+// results are requested, such as one whose test binary was killed.
+//
+// The results of several runs that test the same package, such as
+// runs of different subtests, are merged.
+//
+// The zero value is ready to use.
+type TestResults struct {
+	pkgs        []*packageResult
+	byPath      map[string]*packageResult
+	buildOutput map[string]*strings.Builder // output of test binary builds, by ImportPath
+}
+
+// A packageResult accumulates the result of a package.
+type packageResult struct {
+	result command.PackageTestResult
+	output strings.Builder
+	tests  []*testResult // top-level tests
+	byName map[string]*testResult
+	done   bool // the current run has finished
+}
+
+// A testResult accumulates the result of a test.
+type testResult struct {
+	result   command.TestResult
+	output   strings.Builder
+	subtests []*testResult
+	running  bool // the test has started but not finished
+}
+
+// Add records an event of "go test -json".
+func (r *TestResults) Add(ev command.TestEvent) {
+	switch ev.Action {
+	case "build-output":
+		if r.buildOutput == nil {
+			r.buildOutput = make(map[string]*strings.Builder)
+		}
+		b := r.buildOutput[ev.ImportPath]
+		if b == nil {
+			b = new(strings.Builder)
+			r.buildOutput[ev.ImportPath] = b
+		}
+		b.WriteString(ev.Output)
+		return
+	case "build-fail":
+		return
+	}
+	if ev.Package == "" {
+		return // not an event of a test binary
+	}
+
+	pkg := r.pkg(ev.Package)
+	if ev.Action != "output" {
+		pkg.done = false // a subsequent run may test the package again
+	}
+	if ev.Test == "" {
+		switch ev.Action {
+		case "output":
+			if !isTestFrame(ev, true) {
+				pkg.output.WriteString(ev.Output)
+			}
+		case "pass", "fail", "skip":
+			pkg.result.Action = mergeTestAction(pkg.result.Action, ev.Action)
+			pkg.result.Elapsed += ev.Elapsed
+			pkg.done = true
+			if b := r.buildOutput[ev.FailedBuild]; b != nil {
+				pkg.output.WriteString(b.String())
+			}
+			pkg.finish()
+		}
+		return
+	}
+
+	test := pkg.test(ev.Test)
+	switch ev.Action {
+	case "run":
+		test.running = true
+	case "output":
+		if !isTestFrame(ev, false) {
+			test.output.WriteString(ev.Output)
+		}
+	case "pass", "fail", "skip":
+		test.result.Action = mergeTestAction(test.result.Action, ev.Action)
+		test.result.Elapsed += ev.Elapsed
+		test.running = false
+	}
+}
+
+// mergeTestAction returns the outcome of a package or test that is
+// run several times, as when its subtests are selected by separate
+// runs: it fails if any run failed, and is skipped only if every run
+// skipped it.
+func mergeTestAction(x, y string) string {
+	rank := map[string]int{"": 0, "skip": 1, "pass": 2, "fail": 3}
+	if rank[y] > rank[x] {
+		return y
+	}
+	return x
+}
+
+// pkg returns the result of the package with the specified path,
+// creating it if needed.
+func (r *TestResults) pkg(path string) *packageResult {
+	pkg := r.byPath[path]
+	if pkg == nil {
+		if r.byPath == nil {
+			r.byPath = make(map[string]*packageResult)
+		}
+		pkg = &packageResult{
+			result: command.PackageTestResult{Package: path},
+			byName: make(map[string]*testResult),
+		}
+		r.byPath[path] = pkg
+		r.pkgs = append(r.pkgs, pkg)
+	}
+	return pkg
+}
+
+// test returns the result of the test with the specified name,
+// creating it as a subtest of its nearest known parent, or as a
+// top-level test, if needed. (The names of subtests may themselves
+// contain slashes.)
+func (pkg *packageResult) test(name string) *testResult {
+	test := pkg.byName[name]
+	if test == nil {
+		test = &testResult{result: command.TestResult{Name: name}}
+		pkg.byName[name] = test
+		parent := name
+		for {
+			i := strings.LastIndexByte(parent, '/')
+			if i < 0 {
+				pkg.tests = append(pkg.tests, test)
+				break
+			}
+			parent = parent[:i]
+			if p := pkg.byName[parent]; p != nil {
+				p.subtests = append(p.subtests, test)
+				break
+			}
+		}
+	}
+	return test
+}
+
+// finish fails the unfinished tests of a finished package.
+func (pkg *packageResult) finish() {
+	for _, test := range pkg.byName {
+		if test.running || test.result.Action == "" {
+			test.result.Action = "fail"
+			test.running = false
+		}
+	}
+}
+
+// Packages returns the results of the packages, in the order in which
+// their first events were added.
+func (r *TestResults) Packages() []command.PackageTestResult {
+	var results []command.PackageTestResult
+	for _, pkg := range r.pkgs {
+		if !pkg.done {
+			pkg.result.Action = "fail"
+			pkg.done = true
+			pkg.finish()
+		}
+		result := pkg.result
+		result.Output = pkg.output.String()
+		result.Tests = testResultsOf(pkg.tests)
+		results = append(results, result)
+	}
+	return results
+}
+
+func testResultsOf(tests []*testResult) []command.TestResult {
+	var results []command.TestResult
+	for _, test := range tests {
+		result := test.result
+		result.Output = test.output.String()
+		result.Subtests = testResultsOf(test.subtests)
+		results = append(results, result)
+	}
+	return results
+}
+
+// isTestFrame reports whether the output event ev is a framing line
+// of go test, such as "=== RUN   TestFoo" or "--- PASS: TestFoo", or,
+// for a package, "ok  \tpkg\t0.1s". Older versions of Go do not
+// classify output, so such lines are recognized by their prefixes.
+func isTestFrame(ev command.TestEvent, pkg bool) bool {
+	if ev.OutputType != "" {
+		return ev.OutputType == "frame"
+	}
+	line := ev.Output
+	if pkg {
+		for _, prefix := range []string{"PASS\n", "FAIL\n", "ok  \t", "FAIL\t", "?   \t"} {
+			if strings.HasPrefix(line, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	line = strings.TrimLeft(line, " ")
+	for _, prefix := range []string{"=== RUN ", "=== PAUSE ", "=== CONT ", "=== NAME ", "--- PASS: ", "--- FAIL: ", "--- SKIP: "} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+)
+
+func TestTestResults(t *testing.T) {
+	// The events of two packages: a, whose parallel tests interleave
+	// and one of which panics while another is still running, as
+	// reported by recent versions of Go, which classify output; and
+	// b, whose test binary fails to build.
+	const events = `
+{"Action":"start","Package":"a"}
+{"Action":"run","Package":"a","Test":"TestA"}
+{"Action":"output","Package":"a","Test":"TestA","Output":"=== RUN   TestA\n","OutputType":"frame"}
+{"Action":"run","Package":"a","Test":"TestA/x"}
+{"Action":"output","Package":"a","Test":"TestA/x","Output":"=== RUN   TestA/x\n","OutputType":"frame"}
+{"Action":"run","Package":"a","Test":"TestA/y"}
+{"Action":"output","Package":"a","Test":"TestA/y","Output":"=== RUN   TestA/y\n","OutputType":"frame"}
+{"ImportPath":"b [b.test]","Action":"build-output","Output":"# b [b.test]\n"}
+{"Action":"output","Package":"a","Test":"TestA/x","Output":"=== CONT  TestA/x\n","OutputType":"frame"}
+{"Action":"output","Package":"a","Test":"TestA/x","Output":"hello x\n"}
+{"ImportPath":"b [b.test]","Action":"build-output","Output":"b_test.go:5:28: undefined: f\n"}
+{"Action":"output","Package":"a","Test":"TestA/y","Output":"=== CONT  TestA/y\n","OutputType":"frame"}
+{"Action":"output","Package":"a","Test":"TestA/y","Output":"    a_test.go:11: bad y\n","OutputType":"error"}
+{"ImportPath":"b [b.test]","Action":"build-fail"}
+{"Action":"start","Package":"b"}
+{"Action":"output","Package":"b","Output":"FAIL\tb [build failed]\n","OutputType":"frame"}
+{"Action":"fail","Package":"b","Elapsed":0,"FailedBuild":"b [b.test]"}
+{"Action":"output","Package":"a","Test":"TestA/y","Output":"--- FAIL: TestA/y (0.00s)\n","OutputType":"frame"}
+{"Action":"fail","Package":"a","Test":"TestA/y","Elapsed":0.02}
+{"Action":"output","Package":"a","Test":"TestA/x","Output":"--- PASS: TestA/x (0.01s)\n","OutputType":"frame"}
+{"Action":"pass","Package":"a","Test":"TestA/x","Elapsed":0.01}
+{"Action":"output","Package":"a","Test":"TestA","Output":"--- FAIL: TestA (0.03s)\n","OutputType":"frame"}
+{"Action":"fail","Package":"a","Test":"TestA","Elapsed":0.03}
+{"Action":"run","Package":"a","Test":"TestB"}
+{"Action":"run","Package":"a","Test":"TestC"}
+{"Action":"output","Package":"a","Test":"TestC","Output":"--- FAIL: TestC (0.01s)\n","OutputType":"frame"}
+{"Action":"output","Package":"a","Test":"TestC","Output":"panic: boom\n"}
+{"Action":"fail","Package":"a","Test":"TestC","Elapsed":0.01}
+{"Action":"output","Package":"a","Output":"FAIL\ta\t0.071s\n","OutputType":"frame"}
+{"Action":"fail","Package":"a","Elapsed":0.071}
+`
+	var results TestResults
+	for _, line := range strings.Split(strings.TrimSpace(events), "\n") {
+		var ev command.TestEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatal(err)
+		}
+		results.Add(ev)
+	}
+	want := []command.PackageTestResult{
+		{
+			Package: "a",
+			Action:  "fail",
+			Elapsed: 0.071,
+			Tests: []command.TestResult{
+				{
+					Name:    "TestA",
+					Action:  "fail",
+					Elapsed: 0.03,
+					Subtests: []command.TestResult{
+						{Name: "TestA/x", Action: "pass", Elapsed: 0.01, Output: "hello x\n"},
+						{Name: "TestA/y", Action: "fail", Elapsed: 0.02, Output: "    a_test.go:11: bad y\n"},
+					},
+				},
+				{Name: "TestB", Action: "fail"},
+				{Name: "TestC", Action: "fail", Elapsed: 0.01, Output: "panic: boom\n"},
+			},
+		},
+		{
+			Package: "b",
+			Action:  "fail",
+			Output:  "# b [b.test]\nb_test.go:5:28: undefined: f\n",
+		},
+	}
+	if diff := cmp.Diff(want, results.Packages()); diff != "" {
+		t.Errorf("Packages() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTestResultsUnclassified(t *testing.T) {
+	// Older versions of Go do not classify output. The package is
+	// tested twice, for two subtests, and the test binary of the
+	// second run exits without reporting its outcome.
+	var results TestResults
+	for _, ev := range []command.TestEvent{
+		{Action: "run", Package: "a", Test: "TestA"},
+		{Action: "output", Package: "a", Test: "TestA", Output: "=== RUN   TestA\n"},
+		{Action: "run", Package: "a", Test: "TestA/x/1"},
+		{Action: "output", Package: "a", Test: "TestA/x/1", Output: "=== RUN   TestA/x/1\n"},
+		{Action: "output", Package: "a", Test: "TestA/x/1", Output: "    a_test.go:7: log\n"},
+		{Action: "output", Package: "a", Test: "TestA/x/1", Output: "    --- PASS: TestA/x/1 (0.00s)\n"},
+		{Action: "pass", Package: "a", Test: "TestA/x/1"},
+		{Action: "output", Package: "a", Test: "TestA", Output: "--- PASS: TestA (0.00s)\n"},
+		{Action: "pass", Package: "a", Test: "TestA"},
+		{Action: "output", Package: "a", Output: "PASS\n"},
+		{Action: "output", Package: "a", Output: "ok  \ta\t0.010s\n"},
+		{Action: "pass", Package: "a", Elapsed: 0.01},
+
+		{Action: "run", Package: "a", Test: "TestA"},
+		{Action: "run", Package: "a", Test: "TestA/y"},
+		{Action: "output", Package: "a", Test: "TestA/y", Output: "panic: test timed out after 1s\n"},
+	} {
+		results.Add(ev)
+	}
+	want := []command.PackageTestResult{{
+		Package: "a",
+		Action:  "fail",
+		Elapsed: 0.01,
+		Tests: []command.TestResult{{
+			Name:   "TestA",
+			Action: "fail",
+			Subtests: []command.TestResult{
+				{Name: "TestA/x/1", Action: "pass", Output: "    a_test.go:7: log\n"},
+				{Name: "TestA/y", Action: "fail", Output: "panic: test timed out after 1s\n"},
+			},
+		}},
+	}}
+	if diff := cmp.Diff(want, results.Packages()); diff != "" {
+		t.Errorf("Packages() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,384 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the hover of subtest names, which shows the
+// "go test" command that runs the subtest, the "Copy go test command"
+// code action, and the configuration of debug sessions of tests.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/cache/testfuncs"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+)
+
+// TestRunPattern returns the pattern for the -run flag of go test that
+// selects exactly the named test, such as TestFoo, or subtest, such as
+// TestFoo/case_name, whose slash-separated elements are matched
+// separately.
+func TestRunPattern(name string) string {
+	elems := strings.Split(name, "/")
+	for i, elem := range elems {
+		elems[i] = "^" + regexp.QuoteMeta(elem) + "$"
+	}
+	return strings.Join(elems, "/")
+}
+
+// testCommand returns the shell command that runs only the named
+// subtest of the package in the current directory.
+func testCommand(name string) string {
+	return "go test -run " + shellQuote(TestRunPattern(name))
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// subtestAt returns the string literal enclosing pos that names a
+// subtest, and the full name of the subtest, such as TestFoo/some_name.
+// The literal is either the name argument of a t.Run call, or the name
+// field of a case of a table of test cases, in a function that runs a
+// subtest named by that field of each case, as in
+//
+//	for _, tt := range tests {
+//		t.Run(tt.name, func(t *testing.T) { ... })
+//	}
+//
+// It returns nil if there is no such literal.
+func subtestAt(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, pgf *parsego.File, pos token.Pos) (*ast.BasicLit, string, error) {
+	if !strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+		return nil, "", nil
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+	if len(path) < 3 {
+		return nil, "", nil
+	}
+	lit, ok := path[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return nil, "", nil
+	}
+	info := pkg.TypesInfo()
+	val := info.Types[lit].Value
+	if val == nil || val.Kind() != constant.String {
+		return nil, "", nil
+	}
+
+	// The subtest is run by run, whose enclosing test or subtest is
+	// its parent.
+	var run *ast.CallExpr
+	if call, ok := path[1].(*ast.CallExpr); ok && isRunCall(info, call) && call.Args[0] == lit {
+		run = call
+	} else if run = tableRunCall(info, pgf, path); run == nil {
+		return nil, "", nil
+	}
+	indexes, err := snapshot.Tests(ctx, pkg.Metadata().ID)
+	if err != nil {
+		return nil, "", err
+	}
+	rng, err := pgf.NodeRange(run)
+	if err != nil {
+		return nil, "", err
+	}
+	var (
+		parent testfuncs.Result
+		found  bool
+	)
+	for _, test := range indexes[0].All() {
+		if test.Location.URI != pgf.URI || !protocol.Intersect(test.Location.Range, rng) {
+			continue
+		}
+		if test.Location.Range == rng {
+			return lit, test.Name, nil // a t.Run call, indexed as a subtest
+		}
+		// The innermost test or subtest enclosing the call is the parent.
+		if protocol.ComparePosition(test.Location.Range.Start, rng.Start) <= 0 &&
+			protocol.ComparePosition(rng.End, test.Location.Range.End) <= 0 &&
+			(!found || protocol.ComparePosition(parent.Location.Range.Start, test.Location.Range.Start) <= 0) {
+			parent, found = test, true
+		}
+	}
+	if !found || run.Args[0] == lit {
+		return nil, "", nil // the parent or the name of the t.Run call is not known statically
+	}
+	return lit, parent.Name + "/" + testfuncs.Rewrite(constant.StringVal(val)), nil
+}
+
+// isRunCall reports whether call is a call t.Run(name, f) of the Run
+// method of a *testing.T or *testing.B.
+func isRunCall(info *types.Info, call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Run" && len(call.Args) == 2 && isTestingTB(info.TypeOf(sel.X))
+}
+
+// tableRunCall returns the t.Run call that runs the subtest named by
+// the string literal path[0], if it is the name of a case of a table
+// of test cases, or nil.
+func tableRunCall(info *types.Info, pgf *parsego.File, path []ast.Node) *ast.CallExpr {
+	lit := path[0].(*ast.BasicLit)
+	table, st, err := testCaseTable(info, pgf, lit.Pos(), lit.End())
+	if err != nil {
+		return nil
+	}
+
+	// Find the field of the case named by the literal.
+	var field string
+	switch parent := path[1].(type) {
+	case *ast.KeyValueExpr:
+		key, ok := parent.Key.(*ast.Ident)
+		if !ok || parent.Value != lit || len(path) < 4 || path[3] != table {
+			return nil
+		}
+		field = key.Name
+	case *ast.CompositeLit:
+		if len(path) < 3 || path[2] != table {
+			return nil
+		}
+		for i, elt := range parent.Elts {
+			if elt == lit && i < st.NumFields() {
+				field = st.Field(i).Name()
+			}
+		}
+	}
+	if field == "" {
+		return nil
+	}
+
+	// Find the t.Run(tt.field, ...) call in the enclosing function.
+	var body *ast.BlockStmt
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			body = n.Body
+		case *ast.FuncDecl:
+			body = n.Body
+		}
+		if body != nil {
+			break
+		}
+	}
+	if body == nil {
+		return nil
+	}
+	var run *ast.CallExpr
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && run == nil && isRunCall(info, call) {
+			if sel, ok := call.Args[0].(*ast.SelectorExpr); ok && sel.Sel.Name == field {
+				run = call
+			}
+		}
+		return run == nil
+	})
+	return run
+}
+
+// hoverSubtest computes hover information for the string literal lit
+// naming the subtest name: the go test command that runs it.
+func hoverSubtest(pgf *parsego.File, lit *ast.BasicLit, name string) (protocol.Range, *hoverResult, error) {
+	rng, err := pgf.NodeRange(lit)
+	if err != nil {
+		return protocol.Range{}, nil, err
+	}
+	doc := fmt.Sprintf("Run it with:\n\n\t%s\n", testCommand(name))
+	return rng, &hoverResult{
+		Signature:         fmt.Sprintf("Subtest %q", name),
+		Synopsis:          doc,
+		FullDocumentation: doc,
+	}, nil
+}
+
+// goCopyTestCommand produces the "Copy go test command" code action,
+// on the string literal naming a subtest (see [subtestAt]).
+// See [server.commandHandler.CopyTestCommand] for command implementation.
+func goCopyTestCommand(ctx context.Context, req *codeActionsRequest) error {
+	lit, _, err := subtestAt(ctx, req.snapshot, req.pkg, req.pgf, req.start)
+	if err != nil || lit == nil {
+		return err
+	}
+	cmd := command.NewCopyTestCommandCommand("Copy go test command", req.loc)
+	req.addCommandAction(cmd, false)
+	return nil
+}
+
+// SubtestCommand returns the go test command that runs the subtest
+// named by the string literal at loc (see [subtestAt]).
+func SubtestCommand(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) (string, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return "", err
+	}
+	pos, err := pgf.PositionPos(loc.Range.Start)
+	if err != nil {
+		return "", err
+	}
+	lit, name, err := subtestAt(ctx, snapshot, pkg, pgf, pos)
+	if err != nil {
+		return "", err
+	}
+	if lit == nil {
+		return "", fmt.Errorf("no subtest name at %v", loc.Range.Start)
+	}
+	return testCommand(name), nil
+}
+
+// TestAt returns the name of the test or subtest at loc, in a _test.go
+// file: the subtest named by the string literal at loc (see
+// [subtestAt]), or else the Test function whose declaration encloses
+// loc.
+func TestAt(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) (string, error) {
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return "", err
+	}
+	pos, err := pgf.PositionPos(loc.Range.Start)
+	if err != nil {
+		return "", err
+	}
+	lit, name, err := subtestAt(ctx, snapshot, pkg, pgf, pos)
+	if err != nil {
+		return "", err
+	}
+	if lit != nil {
+		return name, nil
+	}
+	tests, _, err := testsAndBenchmarks(pkg.TypesInfo(), pgf)
+	if err != nil {
+		return "", err
+	}
+	for _, test := range tests {
+		if protocol.Intersect(test.rng, loc.Range) {
+			return test.name, nil
+		}
+	}
+	return "", fmt.Errorf("no test at %v", loc.Range.Start)
+}
+
+// DebugTest returns the configuration of a debug session of the test
+// or subtest specified by args, for the DebugTest command.
+func DebugTest(ctx context.Context, snapshot *cache.Snapshot, args command.DebugTestArgs) (command.DebugTestResult, error) {
+	uri, test := args.URI, args.Test
+	if test == "" && args.Location.URI != "" {
+		uri = args.Location.URI
+		var err error
+		if test, err = TestAt(ctx, snapshot, args.Location); err != nil {
+			return command.DebugTestResult{}, err
+		}
+	}
+	if test == "" {
+		return command.DebugTestResult{}, fmt.Errorf("no test specified")
+	}
+	fh, err := snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return command.DebugTestResult{}, err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+	if err != nil {
+		return command.DebugTestResult{}, err
+	}
+	integration := isIntegrationTestFile(pgf.File)
+
+	pkgPath, err := debugTestPackage(ctx, snapshot, uri, integration)
+	if err != nil {
+		return command.DebugTestResult{}, err
+	}
+	return debugTestConfig(snapshot, pkgPath, uri.DirPath(), test, integration), nil
+}
+
+// debugTestPackage returns the path of the package whose tests include
+// the specified file. An integration test file is excluded by the
+// build configuration, so it has no metadata: it is tested with the
+// package of its directory.
+func debugTestPackage(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI, integration bool) (PackagePath, error) {
+	mp, err := NarrowestMetadataForFile(ctx, snapshot, uri)
+	if err != nil && integration {
+		mps, err2 := snapshot.WorkspaceMetadata(ctx)
+		if err2 != nil {
+			return "", err2
+		}
+		for _, m := range mps {
+			if slices.ContainsFunc(m.CompiledGoFiles, func(f protocol.DocumentURI) bool { return f.Dir() == uri.Dir() }) {
+				mp, err = m, nil
+				break
+			}
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if mp.ForTest != "" {
+		return mp.ForTest, nil
+	}
+	return mp.PkgPath, nil
+}
+
+// debugTestConfig returns the configuration of a debug session of the
+// named test or subtest of the package pkgPath, in directory dir. If
+// integration is set, the test is declared in an integration test file
+// (see [integrationTag]), which the build flags must select.
+func debugTestConfig(snapshot *cache.Snapshot, pkgPath PackagePath, dir, test string, integration bool) command.DebugTestResult {
+	run := TestRunPattern(test)
+	top, _, _ := strings.Cut(test, "/")
+	opts := snapshot.Options()
+	buildFlags := opts.BuildFlags
+	if integration && !slices.ContainsFunc(buildFlags, func(flag string) bool {
+		return strings.HasPrefix(strings.TrimLeft(flag, "-"), "tags")
+	}) {
+		buildFlags = append(slices.Clip(buildFlags), "-tags="+integrationTag)
+	}
+	env := opts.EnvSlice()
+	slices.Sort(env)
+	return command.DebugTestResult{
+		PackagePath: string(pkgPath),
+		Dir:         dir,
+		Test:        top,
+		Run:         run,
+		Args:        []string{"-test.run", run},
+		BuildFlags:  buildFlags,
+		Env:         env,
+	}
+}
+
+// isIntegrationTestFile reports whether the build constraint of the
+// file mentions the build tag of integration tests (see
+// [integrationTag]).
+func isIntegrationTestFile(file *ast.File) bool {
+	c := buildConstraintComment(file)
+	if c == nil {
+		return false
+	}
+	expr, err := constraint.Parse(c.Text)
+	if err != nil {
+		return false
+	}
+	var hasTag func(constraint.Expr) bool
+	hasTag = func(expr constraint.Expr) bool {
+		switch expr := expr.(type) {
+		case *constraint.TagExpr:
+			return expr.Tag == integrationTag
+		case *constraint.NotExpr:
+			return hasTag(expr.X)
+		case *constraint.AndExpr:
+			return hasTag(expr.X) || hasTag(expr.Y)
+		case *constraint.OrExpr:
+			return hasTag(expr.X) || hasTag(expr.Y)
+		}
+		return false
+	}
+	return hasTag(expr)
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the "Unexport X" refactoring, which renames an
+// exported identifier to an unexported one, provided it is not used
+// outside its package.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+)
+
+// unexportableAt returns the identifier at [start, end) if it refers
+// to an exported package-level object, method, or field declared in
+// pkg, along with that object.
+func unexportableAt(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*ast.Ident, types.Object) {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, nil
+	}
+	// The FuncType of a FuncDecl spans its name.
+	if ftype, ok := curSel.Node().(*ast.FuncType); ok {
+		if decl, ok := curSel.Parent().Node().(*ast.FuncDecl); ok && decl.Type == ftype &&
+			posRangeContains(decl.Name.Pos(), decl.Name.End(), start, end) {
+			curSel, _ = curSel.Parent().FindNode(decl.Name)
+		}
+	}
+	id, ok := curSel.Node().(*ast.Ident)
+	if !ok || !id.IsExported() {
+		return nil, nil
+	}
+	info := pkg.TypesInfo()
+	obj := info.ObjectOf(id)
+	if obj == nil || obj.Pkg() != pkg.Types() {
+		return nil, nil
+	}
+	switch obj := obj.(type) {
+	case *types.Func:
+		if recv := obj.Signature().Recv(); recv == nil {
+			if obj.Parent() != pkg.Types().Scope() {
+				return nil, nil
+			}
+			// Test functions must remain exported.
+			if strings.HasSuffix(pgf.URI.Path(), "_test.go") {
+				for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+					if strings.HasPrefix(obj.Name(), prefix) {
+						return nil, nil
+					}
+				}
+			}
+		}
+	case *types.Var:
+		if obj.IsField() {
+			// Renaming an embedded field would rename its type.
+			if obj.Embedded() {
+				return nil, nil
+			}
+		} else if obj.Parent() != pkg.Types().Scope() {
+			return nil, nil
+		}
+	case *types.Const, *types.TypeName:
+		if obj.Parent() != pkg.Types().Scope() {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+	return id, obj
+}
+
+// unexportedName returns the unexported form of the exported name,
+// which lowers the case of its leading initialism, if any, such as
+// HTTPServer -> httpServer, or else of its first letter.
+func unexportedName(name string) string {
+	// Find the run of leading upper-case letters.
+	n := 0 // length in bytes
+	for n < len(name) {
+		r, size := utf8.DecodeRuneInString(name[n:])
+		if !unicode.IsUpper(r) {
+			break
+		}
+		n += size
+	}
+	// In "HTTPServer", the S begins the next word.
+	if n < len(name) && n > 0 {
+		r, _ := utf8.DecodeRuneInString(name[n:])
+		if unicode.IsLower(r) {
+			_, size := utf8.DecodeLastRuneInString(name[:n])
+			if n-size > 0 {
+				n -= size
+			}
+		}
+	}
+	return strings.ToLower(name[:n]) + name[n:]
+}
+
+// Unexport renames the exported identifier at loc to its unexported
+// form throughout the workspace, after checking that it is not used
+// outside its package, including by the package's external tests.
+// If it is, the resulting error lists those uses.
+func Unexport(ctx context.Context, snapshot *cache.Snapshot, loc protocol.Location) ([]protocol.DocumentChange, error) {
+	fh, err := snapshot.ReadFile(ctx, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, loc.URI)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(loc.Range)
+	if err != nil {
+		return nil, err
+	}
+	id, obj := unexportableAt(pkg, pgf, start, end)
+	if obj == nil {
+		return nil, fmt.Errorf("no exported identifier selected")
+	}
+	newName := unexportedName(obj.Name())
+	if token.IsKeyword(newName) {
+		return nil, fmt.Errorf("cannot unexport %s: %s is a keyword", obj.Name(), newName)
+	}
+	pp, err := pgf.PosPosition(id.Pos())
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for uses in other packages.
+	refs, err := references(ctx, snapshot, fh, pp, false)
+	if err != nil {
+		return nil, err
+	}
+	var blockers []string
+	for _, ref := range refs {
+		metas, err := snapshot.MetadataForFile(ctx, ref.location.URI)
+		if err != nil {
+			return nil, err
+		}
+		internal := false
+		for _, mp := range metas {
+			if PackagePath(mp.PkgPath) == ref.pkgPath {
+				internal = true
+				break
+			}
+		}
+		if !internal {
+			posn := ref.location.Range.Start
+			blockers = append(blockers, fmt.Sprintf("%s:%d:%d", ref.location.URI.Path(), posn.Line+1, posn.Character+1))
+		}
+	}
+	if len(blockers) > 0 {
+		return nil, fmt.Errorf("cannot unexport %s: it is used outside package %s:\n%s",
+			obj.Name(), obj.Pkg().Name(), strings.Join(blockers, "\n"))
+	}
+
+	edits, _, _, err := Rename(ctx, snapshot, fh, pp, newName)
+	if err != nil {
+		return nil, err
+	}
+	var changes []protocol.DocumentChange
+	for uri, e := range edits {
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, protocol.DocumentChangeEdit(fh, e))
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].TextDocumentEdit.TextDocument.URI < changes[j].TextDocumentEdit.TextDocument.URI
+	})
+	return changes, nil
+}
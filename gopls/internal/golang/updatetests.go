@@ -0,0 +1,477 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the "Update generated tests" command.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/constructors"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/diff"
+)
+
+// UpdateTests realigns the table-driven tests of the functions and
+// methods declared in the specified Go file, or in the files of the
+// package in the specified directory, with their current signatures.
+// If args.Recursive is set, it also processes the workspace packages
+// in the subdirectories of the directory.
+//
+// The tests considered are those named as by [TestFuncSource], such
+// as TestF or TestT_M, whose first statement declares the table of
+// test cases as it generates them: tests := []struct{...}{...}. If
+// the fields of the table's struct type differ from those that would
+// be generated now, because parameters or results were added, removed,
+// or retyped, UpdateTests replaces the struct type, deletes the values
+// of removed fields from the test cases, and updates the arguments of
+// the calls to the function and to the receiver's constructor, and the
+// variables to which their results are assigned. The test cases that
+// need attention, such as those with values of retyped fields, are
+// reported in the result, whose Edit field is unset.
+func UpdateTests(ctx context.Context, snapshot *cache.Snapshot, args command.UpdateTestsArgs) ([]protocol.DocumentChange, command.UpdateTestsResult, error) {
+	var result command.UpdateTestsResult
+	uris, err := subjectFiles(ctx, snapshot, args.URI, args.Recursive)
+	if err != nil {
+		return nil, result, err
+	}
+
+	batch, err := newPackageBatch(ctx, snapshot, uris)
+	if err != nil {
+		return nil, result, err
+	}
+
+	var (
+		updates = make(map[protocol.DocumentURI]*testFileUpdate)
+		order   []protocol.DocumentURI // keys of updates, in order of creation
+	)
+	for _, uri := range uris {
+		if err := ctx.Err(); err != nil {
+			return nil, result, err
+		}
+		file := batch.files[uri]
+		pgf, rel := file.pgf, file.pkg.rel
+		var tp testedPackage // checked on demand
+		for _, decl := range pgf.File.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			name, ok := testableName(pgf.File, decl)
+			if !ok || len(rel.TestsOf(name)) == 0 {
+				continue
+			}
+			if tp.types == nil {
+				tp, err = file.pkg.testedPackage()
+				if err != nil {
+					return nil, result, fmt.Errorf("updating tests for %s: %w", filepath.Base(uri.Path()), err)
+				}
+			}
+			fn := tp.funcOf(decl)
+			if fn == nil {
+				continue
+			}
+			testName, err := testName(fn)
+			if err != nil {
+				continue
+			}
+			for _, test := range rel.TestsOf(name) {
+				if test.Name != testName {
+					continue
+				}
+				u, ok := updates[test.Location.URI]
+				if !ok {
+					fh, err := snapshot.ReadFile(ctx, test.Location.URI)
+					if err != nil {
+						return nil, result, err
+					}
+					pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+					if err != nil {
+						return nil, result, err
+					}
+					xtest := strings.HasSuffix(pgf.File.Name.Name, "_test")
+					helpers, err := tp.testHelpers(ctx, snapshot, xtest)
+					if err != nil {
+						return nil, result, err
+					}
+					u = &testFileUpdate{fh: fh, pgf: pgf, helpers: helpers, imports: NewImportFixBatch(snapshot.Options().Local, pgf.Src)}
+					updates[test.Location.URI] = u
+					order = append(order, test.Location.URI)
+				}
+				ctors, err := snapshot.Constructors(ctx, tp.mp, tp.mp.PkgPath)
+				if err != nil {
+					return nil, result, err
+				}
+				updated, attention, err := u.updateTest(tp, fn, ctors, testName)
+				if err != nil {
+					return nil, result, fmt.Errorf("updating %s: %w", testName, err)
+				}
+				if updated {
+					result.Updated++
+				}
+				result.Attention = append(result.Attention, attention...)
+			}
+		}
+	}
+
+	var changes []protocol.DocumentChange
+	for _, uri := range order {
+		u := updates[uri]
+		if len(u.edits) == 0 {
+			continue
+		}
+		diff.SortEdits(u.edits)
+		edits, err := protocol.EditsFromDiffEdits(u.pgf.Mapper, u.edits)
+		if err != nil {
+			return nil, result, err
+		}
+		importEdits, err := u.imports.Edits()
+		if err != nil {
+			return nil, result, fmt.Errorf("could not compute the import fix edits: %w", err)
+		}
+		edits = append(importEdits, edits...)
+		changes = append(changes, protocol.DocumentChangeEdit(u.fh, edits))
+	}
+	return changes, result, nil
+}
+
+// A testFileUpdate accumulates the edits that update the tests of a
+// test file.
+type testFileUpdate struct {
+	fh      file.Handle
+	pgf     *parsego.File
+	helpers TestHelpers // receiver helpers of the test package
+	edits   []diff.Edit
+	imports *ImportFixBatch // imports of packages used by the updated tests
+}
+
+// updateTest realigns the test of the specified name with the current
+// signature of its subject fn, and reports whether it changed it, and
+// which of its test cases need attention; see [UpdateTests].
+func (u *testFileUpdate) updateTest(tp testedPackage, fn *types.Func, ctors *constructors.Index, testName string) (bool, []command.TestAttention, error) {
+	pgf := u.pgf
+	var decl *ast.FuncDecl
+	for _, d := range pgf.File.Decls {
+		if d, ok := d.(*ast.FuncDecl); ok && d.Recv == nil && d.Name.Name == testName {
+			decl = d
+			break
+		}
+	}
+	table, cases := testTable(decl)
+	if table == nil {
+		return false, nil, nil // not a generated test
+	}
+
+	// Generate the test afresh, and compare the fields of its table.
+	xtest := strings.HasSuffix(pgf.File.Name.Name, "_test")
+	testImports := make(map[string]string) // path -> local name, or ""
+	for _, spec := range pgf.File.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		testImports[path] = ""
+		if spec.Name != nil {
+			testImports[path] = spec.Name.Name
+		}
+	}
+	var newImports []string // paths of packages that the generated test would import
+	qual := func(p *types.Package) string {
+		if !xtest && p == tp.types {
+			return ""
+		}
+		if local, ok := testImports[p.Path()]; ok {
+			if local != "" {
+				return local
+			}
+			return p.Name()
+		}
+		newImports = append(newImports, p.Path())
+		return p.Name()
+	}
+	// Use only the fakes and optional packages that the table already
+	// refers to, and the helpers that the test already calls, so that
+	// declaring them does not by itself make the test outdated.
+	doubles := TestDoublesOf(pgf.File).usedIn(table)
+	helpers := u.helpers.usedIn(decl)
+	inputs := TestInputsOf(pgf.File).usedIn(table)
+	src, err := TestFuncSource(fn, TestFuncParams{
+		Constructors: ctors,
+		XTest:        xtest,
+		Qual:         qual,
+		Style:        AssertionStyleOf(pgf.File),
+		Doubles:      doubles,
+		Helpers:      helpers,
+		Inputs:       inputs,
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	genSrc := append([]byte("package p\n"), src...)
+	genFile, err := parser.ParseFile(token.NewFileSet(), "", genSrc, parser.SkipObjectResolution)
+	if err != nil {
+		return false, nil, err // can't happen
+	}
+	genDecl := genFile.Decls[0].(*ast.FuncDecl)
+	genTable, _ := testTable(genDecl)
+	if genTable == nil {
+		return false, nil, fmt.Errorf("generated test lacks a table") // can't happen
+	}
+	genText := func(n ast.Node) string {
+		return string(genSrc[int(n.Pos())-1 : int(n.End())-1])
+	}
+	text := func(n ast.Node) string {
+		start, end, _ := pgf.NodeOffsets(n)
+		return string(pgf.Src[start:end])
+	}
+	oldFields := tableFields(table, text)
+	newFields := tableFields(genTable, genText)
+	if slices.Equal(oldFields, newFields) {
+		return false, nil, nil // up to date
+	}
+	for _, path := range newImports {
+		u.imports.AddImport(path, "")
+	}
+
+	edit := func(start, end token.Pos, new string) {
+		startOffset, endOffset, _ := safetoken.Offsets(pgf.Tok, start, end)
+		u.edits = append(u.edits, diff.Edit{Start: startOffset, End: endOffset, New: new})
+	}
+	edit(table.Pos(), table.End(), genText(genTable))
+
+	typeOf := func(fields []tableField, name string) (string, bool) {
+		for _, f := range fields {
+			if f.name == name {
+				return f.typ, true
+			}
+		}
+		return "", false
+	}
+
+	// Update the test cases.
+	var attention []command.TestAttention
+	for _, elt := range cases.Elts {
+		lit, ok := elt.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		var reasons []string
+		if slices.ContainsFunc(lit.Elts, func(e ast.Expr) bool { _, ok := e.(*ast.KeyValueExpr); return !ok }) {
+			reasons = append(reasons, "unkeyed fields")
+		} else {
+			keys := make(map[string]bool)
+			for _, elt := range lit.Elts {
+				kv := elt.(*ast.KeyValueExpr)
+				key, ok := kv.Key.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				keys[key.Name] = true
+				oldType, _ := typeOf(oldFields, key.Name)
+				if newType, ok := typeOf(newFields, key.Name); !ok {
+					u.deleteElt(kv)
+					reasons = append(reasons, fmt.Sprintf("removed field %s", key.Name))
+				} else if newType != oldType {
+					reasons = append(reasons, fmt.Sprintf("field %s changed type from %s to %s", key.Name, oldType, newType))
+				}
+			}
+			for _, f := range newFields {
+				if _, ok := typeOf(oldFields, f.name); !ok && !keys[f.name] {
+					reasons = append(reasons, fmt.Sprintf("new field %s", f.name))
+				}
+			}
+		}
+		if len(reasons) > 0 {
+			loc, err := pgf.NodeLocation(lit)
+			if err != nil {
+				return false, nil, err
+			}
+			attention = append(attention, command.TestAttention{
+				Location: loc,
+				Test:     testName,
+				Reason:   strings.Join(reasons, "; "),
+			})
+		}
+	}
+
+	// Update the calls to the function and to the receiver's
+	// constructor, which are the calls in the statements of the
+	// generated subtest whose results are assigned or discarded.
+	var subtest *ast.FuncLit
+	ast.Inspect(genDecl.Body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok && subtest == nil {
+			subtest = lit
+		}
+		return subtest == nil
+	})
+	if subtest == nil {
+		return true, attention, nil
+	}
+	for _, stmt := range subtest.Body.List {
+		var (
+			genCall *ast.CallExpr
+			genLHS  []ast.Expr
+		)
+		switch stmt := stmt.(type) {
+		case *ast.AssignStmt:
+			genCall, _ = stmt.Rhs[0].(*ast.CallExpr)
+			genLHS = stmt.Lhs
+		case *ast.ExprStmt:
+			genCall, _ = stmt.X.(*ast.CallExpr)
+		}
+		if genCall == nil {
+			continue
+		}
+		name := calleeName(genCall)
+		if name == "" {
+			continue
+		}
+		var (
+			call *ast.CallExpr
+			lhs  []ast.Expr // variables of the call's results, if assigned
+		)
+		for _, stmt := range decl.Body.List[1:] { // skip the table
+			ast.Inspect(stmt, func(n ast.Node) bool {
+				if call != nil {
+					return false
+				}
+				switch n := n.(type) {
+				case *ast.AssignStmt:
+					if c, ok := n.Rhs[0].(*ast.CallExpr); ok && len(n.Rhs) == 1 && n.Tok == token.DEFINE && calleeName(c) == name {
+						call, lhs = c, n.Lhs
+						return false
+					}
+				case *ast.CallExpr:
+					if calleeName(n) == name {
+						call = n
+						return false
+					}
+				}
+				return true
+			})
+		}
+		if call == nil {
+			continue
+		}
+		join := func(exprs []ast.Expr, text func(ast.Node) string) string {
+			var strs []string
+			for _, e := range exprs {
+				strs = append(strs, text(e))
+			}
+			return strings.Join(strs, ", ")
+		}
+		if oldArgs, newArgs := join(call.Args, text), join(genCall.Args, genText); oldArgs != newArgs {
+			edit(call.Lparen+1, call.Rparen, newArgs)
+		}
+		if oldLHS, newLHS := join(lhs, text), join(genLHS, genText); oldLHS != newLHS {
+			switch {
+			case oldLHS == "":
+				edit(call.Pos(), call.Pos(), newLHS+" := ")
+			case newLHS == "":
+				edit(lhs[0].Pos(), call.Pos(), "")
+			default:
+				edit(lhs[0].Pos(), lhs[len(lhs)-1].End(), newLHS)
+			}
+			loc, err := pgf.NodeLocation(call)
+			if err != nil {
+				return false, nil, err
+			}
+			attention = append(attention, command.TestAttention{
+				Location: loc,
+				Test:     testName,
+				Reason:   fmt.Sprintf("results of %s changed; update the comparisons", name),
+			})
+		}
+	}
+	return true, attention, nil
+}
+
+// deleteElt deletes the element kv of a composite literal, along with
+// its comma, and its line if it occupies it alone.
+func (u *testFileUpdate) deleteElt(kv *ast.KeyValueExpr) {
+	src := u.pgf.Src
+	start, end, _ := u.pgf.NodeOffsets(kv)
+	if end < len(src) && src[end] == ',' {
+		end++
+	}
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+	if end < len(src) && src[end] == '\n' && len(bytes.TrimSpace(src[lineStart:start])) == 0 {
+		start, end = lineStart, end+1
+	}
+	u.edits = append(u.edits, diff.Edit{Start: start, End: end})
+}
+
+// testTable returns the struct type of the table of test cases
+// declared by the first statement of the test decl, and the composite
+// literal of the cases, or nil if the test has no such table.
+func testTable(decl *ast.FuncDecl) (*ast.StructType, *ast.CompositeLit) {
+	if decl == nil || decl.Body == nil || len(decl.Body.List) == 0 {
+		return nil, nil
+	}
+	assign, ok := decl.Body.List[0].(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Rhs) != 1 {
+		return nil, nil
+	}
+	lit, ok := assign.Rhs[0].(*ast.CompositeLit)
+	if !ok {
+		return nil, nil
+	}
+	array, ok := lit.Type.(*ast.ArrayType)
+	if !ok || array.Len != nil {
+		return nil, nil
+	}
+	st, ok := array.Elt.(*ast.StructType)
+	if !ok {
+		return nil, nil
+	}
+	return st, lit
+}
+
+// A tableField is a field of the struct type of a table of test cases.
+type tableField struct {
+	name, typ string
+}
+
+// tableFields returns the fields of the struct type st, whose types
+// are rendered by text.
+func tableFields(st *ast.StructType, text func(ast.Node) string) []tableField {
+	var fields []tableField
+	for _, f := range st.Fields.List {
+		for _, name := range f.Names {
+			fields = append(fields, tableField{name.Name, text(f.Type)})
+		}
+	}
+	return fields
+}
+
+// calleeName returns the name of the function called by call, F for
+// F(...) or x.F(...), or "" if it is not named.
+func calleeName(call *ast.CallExpr) string {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/cache/constructors"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/internal/diff"
+)
+
+func TestUpdateTest(t *testing.T) {
+	// F gained a parameter y, lost the parameter x, retyped z, and
+	// gained an error result.
+	const src = `package p
+
+func F(y int, z string) (int, error) { return 0, nil }
+`
+	const test = `package p
+
+import "testing"
+
+func TestF(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		// Named input parameters for target function.
+		x    int
+		z    int
+		want int
+	}{
+		{
+			name: "one",
+			x:    1,
+			z:    2,
+			want: 3,
+		},
+		{"two", 2, 3, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := F(tt.x, tt.z)
+			if got != tt.want {
+				t.Errorf("F() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+`
+	const want = `package p
+
+import "testing"
+
+func TestF(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		// Named input parameters for target function.
+		y       int
+		z       string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "one",
+			z:    2,
+			want: 3,
+		},
+		{"two", 2, 3, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := F(tt.y, tt.z)
+			if got != tt.want {
+				t.Errorf("F() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp := testedPackage{types: pkg, info: info}
+	fn := pkg.Scope().Lookup("F").(*types.Func)
+
+	uri := protocol.URIFromPath("/p/p_test.go")
+	pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), uri, []byte(test), parsego.Full, false)
+	u := &testFileUpdate{pgf: pgf, imports: NewImportFixBatch("", pgf.Src)}
+	updated, attention, err := u.updateTest(tp, fn, constructors.NewIndex([]*ast.File{f}), "TestF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated {
+		t.Fatal("updateTest did not update the test")
+	}
+	got, err := diff.Apply(test, u.edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("updated test:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff.Unified("want", "got", want, got))
+	}
+
+	wantReasons := []string{
+		"removed field x; field z changed type from int to string; new field y; new field wantErr",
+		"unkeyed fields",
+		"results of F changed; update the comparisons",
+	}
+	if len(attention) != len(wantReasons) {
+		t.Fatalf("got %d places needing attention, want %d: %v", len(attention), len(wantReasons), attention)
+	}
+	for i, a := range attention {
+		if a.Test != "TestF" || a.Reason != wantReasons[i] {
+			t.Errorf("attention[%d] = %s: %q, want TestF: %q", i, a.Test, a.Reason, wantReasons[i])
+		}
+	}
+}
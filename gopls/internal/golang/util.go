@@ -7,18 +7,21 @@ package golang
 import (
 	"context"
 	"go/ast"
+	"go/format"
 	"go/printer"
 	"go/token"
 	"go/types"
 	"regexp"
 	"strings"
-	"unicode"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
 	"golang.org/x/tools/gopls/internal/cache/parsego"
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/gopls/internal/util/bug"
+	"golang.org/x/tools/gopls/internal/util/safetoken"
+	"golang.org/x/tools/internal/diff"
 	"golang.org/x/tools/internal/tokeninternal"
 )
 
@@ -29,7 +32,7 @@ func IsGenerated(ctx context.Context, snapshot *cache.Snapshot, uri protocol.Doc
 	if err != nil {
 		return false
 	}
-	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+	pgf, err := snapshot.ParseHeader(ctx, fh)
 	if err != nil {
 		return false
 	}
@@ -328,39 +331,6 @@ func boolCompare(x, y bool) int {
 	return btoi(x) - btoi(y)
 }
 
-// AbbreviateVarName returns an abbreviated var name based on the given full
-// name (which may be a type name, for example).
-//
-// See the simple heuristics documented in line.
-func AbbreviateVarName(s string) string {
-	var (
-		b            strings.Builder
-		useNextUpper bool
-	)
-	for i, r := range s {
-		// Stop if we encounter a non-identifier rune.
-		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
-			break
-		}
-
-		// Otherwise, take the first letter from word boundaries, assuming
-		// camelCase.
-		if i == 0 {
-			b.WriteRune(unicode.ToLower(r))
-		}
-
-		if unicode.IsUpper(r) {
-			if useNextUpper {
-				b.WriteRune(unicode.ToLower(r))
-				useNextUpper = false
-			}
-		} else {
-			useNextUpper = true
-		}
-	}
-	return b.String()
-}
-
 // copyrightComment returns the copyright comment group from the input file, or
 // nil if not found.
 func copyrightComment(file *ast.File) *ast.CommentGroup {
@@ -402,3 +372,30 @@ func buildConstraintComment(file *ast.File) *ast.Comment {
 
 	return nil
 }
+
+// formatEditsWithin applies the edits to the content of pgf and
+// formats the result, returning the edits that transform the original
+// content into the formatted one within the node n. Changes that
+// formatting would make outside n are discarded, so that unrelated
+// code is unaffected.
+func formatEditsWithin(pgf *parsego.File, edits []diff.Edit, n ast.Node) ([]analysis.TextEdit, error) {
+	src, err := diff.ApplyBytes(pgf.Src, edits)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := safetoken.Offsets(pgf.Tok, n.Pos(), n.End())
+	if err != nil {
+		return nil, err
+	}
+	var within []diff.Edit
+	for _, edit := range diff.Bytes(pgf.Src, formatted) {
+		if start <= edit.Start && edit.End <= end {
+			within = append(within, edit)
+		}
+	}
+	return diffToTextEdits(pgf.Tok, within), nil
+}
@@ -41,6 +41,9 @@ const maxSymbols = 100
 // assumed that "project-wide" means "across all workspaces".  Hence why
 // WorkspaceSymbols receives the views []View.
 //
+// A query may begin with a prefix that restricts the kind of symbols it
+// matches; see [symbolFilter].
+//
 // However, it then becomes unclear what it would mean to call WorkspaceSymbols
 // with a different configured SymbolMatcher per View. Therefore we assume that
 // Session level configuration will define the SymbolMatcher to be used for the
@@ -48,6 +51,7 @@ const maxSymbols = 100
 func WorkspaceSymbols(ctx context.Context, matcher settings.SymbolMatcher, style settings.SymbolStyle, snapshots []*cache.Snapshot, query string) ([]protocol.SymbolInformation, error) {
 	ctx, done := event.Start(ctx, "golang.WorkspaceSymbols")
 	defer done()
+	filter, query := parseSymbolFilter(query)
 	if query == "" {
 		return nil, nil
 	}
@@ -64,7 +68,59 @@ func WorkspaceSymbols(ctx context.Context, matcher settings.SymbolMatcher, style
 		panic(fmt.Errorf("unknown symbol style: %v", style))
 	}
 
-	return collectSymbols(ctx, snapshots, matcher, s, query)
+	return collectSymbols(ctx, snapshots, matcher, s, filter, query)
+}
+
+// A symbolFilter restricts the symbols matched by a workspace symbol
+// query, according to the prefix of the query:
+//
+//	test:    Test, Fuzz, and Example functions
+//	bench:   Benchmark functions
+//	notest:  symbols not declared in _test.go files
+//
+// Large workspaces declare many tests, whose names often resemble
+// those of the functions that they test.
+type symbolFilter int
+
+const (
+	allSymbols       symbolFilter = iota
+	testSymbols                   // "test:"
+	benchmarkSymbols              // "bench:"
+	nonTestSymbols                // "notest:"
+)
+
+// parseSymbolFilter returns the filter denoted by the prefix of a
+// workspace symbol query, and the rest of the query.
+func parseSymbolFilter(query string) (symbolFilter, string) {
+	for prefix, filter := range map[string]symbolFilter{
+		"test:":   testSymbols,
+		"bench:":  benchmarkSymbols,
+		"notest:": nonTestSymbols,
+	} {
+		if rest, ok := strings.CutPrefix(query, prefix); ok {
+			return filter, strings.TrimSpace(rest)
+		}
+	}
+	return allSymbols, query
+}
+
+// allows reports whether the filter allows the symbol declared in the
+// specified file.
+func (filter symbolFilter) allows(uri protocol.DocumentURI, sym symbols.Symbol) bool {
+	if filter == allSymbols {
+		return true
+	}
+	inTestFile := strings.HasSuffix(uri.Path(), "_test.go")
+	if filter == nonTestSymbols {
+		return !inTestFile
+	}
+	if !inTestFile || sym.Kind != protocol.Function {
+		return false
+	}
+	if filter == benchmarkSymbols {
+		return benchmarkRe.MatchString(sym.Name)
+	}
+	return testRe.MatchString(sym.Name) || fuzzRe.MatchString(sym.Name) || exampleRe.MatchString(sym.Name)
 }
 
 // A matcherFunc returns the index and score of a symbol match.
@@ -290,7 +346,7 @@ func (c comboMatcher) match(chunks []string) (int, float64) {
 //     of zero indicates no match.
 //   - A symbolizer determines how we extract the symbol for an object. This
 //     enables the 'symbolStyle' configuration option.
-func collectSymbols(ctx context.Context, snapshots []*cache.Snapshot, matcherType settings.SymbolMatcher, symbolizer symbolizer, query string) ([]protocol.SymbolInformation, error) {
+func collectSymbols(ctx context.Context, snapshots []*cache.Snapshot, matcherType settings.SymbolMatcher, symbolizer symbolizer, filter symbolFilter, query string) ([]protocol.SymbolInformation, error) {
 	// Extract symbols from all files.
 	var work []symbolFile
 	seen := make(map[protocol.DocumentURI]*metadata.Package) // only scan each file once
@@ -396,7 +452,7 @@ func collectSymbols(ctx context.Context, snapshots []*cache.Snapshot, matcherTyp
 			store := new(symbolStore)
 			// Assign files to workers in round-robin fashion.
 			for j := i; j < len(work); j += nmatchers {
-				matchFile(store, symbolizer, matcher, work[j])
+				matchFile(store, symbolizer, matcher, filter, work[j])
 			}
 			results <- store
 		}(i)
@@ -424,9 +480,12 @@ type symbolFile struct {
 }
 
 // matchFile scans a symbol file and adds matching symbols to the store.
-func matchFile(store *symbolStore, symbolizer symbolizer, matcher matcherFunc, f symbolFile) {
+func matchFile(store *symbolStore, symbolizer symbolizer, matcher matcherFunc, filter symbolFilter, f symbolFile) {
 	space := make([]string, 0, 3)
 	for _, sym := range f.syms {
+		if !filter.allows(f.uri, sym) {
+			continue
+		}
 		symbolParts, score := symbolizer(space, sym.Name, f.mp, matcher)
 
 		// Check if the score is too low before applying any downranking.
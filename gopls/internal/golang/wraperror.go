@@ -0,0 +1,172 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines the code actions "Wrap error with fmt.Errorf",
+// which rewrites return err as return fmt.Errorf("f: %w", err).
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/astutil/cursor"
+	"golang.org/x/tools/internal/astutil/edge"
+)
+
+// A bareErrorReturn is a return statement whose final operand is an
+// error variable err that is known to be non-nil, such as
+//
+//	if err != nil {
+//		return nil, err
+//	}
+type bareErrorReturn struct {
+	ret      *ast.ReturnStmt
+	err      *ast.Ident // the error variable
+	funcName string     // name of the enclosing function declaration
+}
+
+// bareErrorReturnAt returns the bare error return statement that
+// encloses the cursor cur, if any.
+func bareErrorReturnAt(info *types.Info, cur cursor.Cursor) (bareErrorReturn, bool) {
+	if !is[*ast.ReturnStmt](cur.Node()) {
+		for c := range cur.Ancestors((*ast.ReturnStmt)(nil)) {
+			cur = c
+			break
+		}
+	}
+	if !is[*ast.ReturnStmt](cur.Node()) {
+		return bareErrorReturn{}, false
+	}
+	return isBareErrorReturn(info, cur)
+}
+
+// isBareErrorReturn reports whether the return statement at cur
+// returns an error variable that is known to be non-nil because the
+// statement is within the body of an if statement whose condition
+// tests err != nil.
+func isBareErrorReturn(info *types.Info, cur cursor.Cursor) (bareErrorReturn, bool) {
+	ret := cur.Node().(*ast.ReturnStmt)
+	if len(ret.Results) == 0 {
+		return bareErrorReturn{}, false
+	}
+	id, ok := ret.Results[len(ret.Results)-1].(*ast.Ident)
+	if !ok {
+		return bareErrorReturn{}, false
+	}
+	v, ok := info.Uses[id].(*types.Var)
+	if !ok || !types.Identical(v.Type(), types.Universe.Lookup("error").Type()) {
+		return bareErrorReturn{}, false
+	}
+
+	// Find the enclosing function declaration,
+	// and an if statement that guards the return.
+	var (
+		guarded  = false
+		funcName string
+	)
+	for c := cur; ; c = c.Parent() {
+		ek, _ := c.Edge()
+		parent := c.Parent().Node()
+		switch parent := parent.(type) {
+		case *ast.IfStmt:
+			if ek == edge.IfStmt_Body && testsNonNil(info, parent.Cond, v) {
+				guarded = true
+			}
+		case *ast.FuncLit:
+			if !guarded {
+				return bareErrorReturn{}, false
+			}
+		case *ast.FuncDecl:
+			funcName = parent.Name.Name
+		case *ast.File, nil:
+			if !guarded || funcName == "" {
+				return bareErrorReturn{}, false
+			}
+			return bareErrorReturn{ret: ret, err: id, funcName: funcName}, true
+		}
+	}
+}
+
+// testsNonNil reports whether cond is v != nil, or a conjunction
+// including it.
+func testsNonNil(info *types.Info, cond ast.Expr, v *types.Var) bool {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+	switch bin.Op {
+	case token.LAND:
+		return testsNonNil(info, bin.X, v) || testsNonNil(info, bin.Y, v)
+	case token.NEQ:
+		for _, pair := range [][2]ast.Expr{{bin.X, bin.Y}, {bin.Y, bin.X}} {
+			if id, ok := ast.Unparen(pair[0]).(*ast.Ident); ok && info.Uses[id] == v &&
+				info.Types[pair[1]].IsNil() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bareErrorReturns returns all the bare error returns in the file.
+func bareErrorReturns(info *types.Info, pgf *parsego.File) []bareErrorReturn {
+	var rets []bareErrorReturn
+	for cur := range pgf.Cursor.Preorder((*ast.ReturnStmt)(nil)) {
+		if r, ok := isBareErrorReturn(info, cur); ok {
+			rets = append(rets, r)
+		}
+	}
+	return rets
+}
+
+// wrapError is a singleFileFixer that wraps the error returned by the
+// selected bare error return statement using fmt.Errorf.
+func wrapError(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	curSel, ok := pgf.Cursor.FindPos(start, end)
+	if !ok {
+		return nil, nil, fmt.Errorf("no return statement selected")
+	}
+	r, ok := bareErrorReturnAt(pkg.TypesInfo(), curSel)
+	if !ok {
+		return nil, nil, fmt.Errorf("no bare error return statement selected")
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{
+		TextEdits: wrapErrorEdits(pkg.TypesInfo(), pgf, []bareErrorReturn{r}),
+	}, nil
+}
+
+// wrapErrorAll is a singleFileFixer that wraps the errors returned
+// by all bare error return statements in the file using fmt.Errorf.
+func wrapErrorAll(pkg *cache.Package, pgf *parsego.File, start, end token.Pos) (*token.FileSet, *analysis.SuggestedFix, error) {
+	rets := bareErrorReturns(pkg.TypesInfo(), pgf)
+	if len(rets) == 0 {
+		return nil, nil, fmt.Errorf("no bare error return statements")
+	}
+	return pkg.FileSet(), &analysis.SuggestedFix{
+		TextEdits: wrapErrorEdits(pkg.TypesInfo(), pgf, rets),
+	}, nil
+}
+
+// wrapErrorEdits returns the edits that wrap the error of each return
+// statement, adding an import of fmt if needed.
+func wrapErrorEdits(info *types.Info, pgf *parsego.File, rets []bareErrorReturn) []analysis.TextEdit {
+	_, prefix, edits := analysisinternal.AddImport(info, pgf.File, "fmt", "fmt", "Errorf", rets[0].ret.Pos())
+	for _, r := range rets {
+		format := strconv.Quote(r.funcName + ": %w")
+		edits = append(edits, analysis.TextEdit{
+			Pos:     r.err.Pos(),
+			End:     r.err.End(),
+			NewText: fmt.Appendf(nil, "%sErrorf(%s, %s)", prefix, format, r.err.Name),
+		})
+	}
+	return edits
+}
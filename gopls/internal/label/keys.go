@@ -19,6 +19,9 @@ var (
 	Operation = keys.NewString("operation", "")
 	Duration  = keys.New("duration", "Elapsed time")
 
+	Command       = keys.NewString("command", "The name of an executed command")
+	CommandStatus = keys.NewString("command_status", "The outcome of an executed command")
+
 	Position     = keys.New("position", "")
 	PackageCount = keys.NewInt("packages", "")
 	Files        = keys.New("files", "")
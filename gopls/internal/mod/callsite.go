@@ -0,0 +1,304 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mod
+
+// This file defines the diagnostics and hover for the calls, in the
+// main module, that reach a vulnerable symbol according to govulncheck.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/cache/parsego"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/vulncheck"
+	"golang.org/x/tools/gopls/internal/vulncheck/govulncheck"
+	"golang.org/x/tools/internal/event"
+)
+
+// A callSite is a call in the main module through which a vulnerable
+// symbol is reached.
+type callSite struct {
+	uri     protocol.DocumentURI
+	rng     protocol.Range
+	finding *govulncheck.Finding
+	frame   int // index in finding.Trace of the frame making the call
+}
+
+// callSites returns the call sites, in the Go files of the module of
+// the go.mod file pm, of the findings of the govulncheck result vs. If
+// only is non-empty, it returns only those in that file.
+//
+// The positions in the findings are those at the time govulncheck
+// ran; a frame is ignored unless its position is still that of a
+// call expression.
+func callSites(ctx context.Context, snapshot *cache.Snapshot, pm *cache.ParsedModule, vs *vulncheck.Result, only protocol.DocumentURI) ([]callSite, error) {
+	if vs == nil || pm.File.Module == nil {
+		return nil, nil
+	}
+	modPath := pm.File.Module.Mod.Path
+	modDir := pm.URI.DirPath()
+
+	// Group the frames of the main module by file.
+	type frameRef struct {
+		finding *govulncheck.Finding
+		frame   int
+	}
+	byFile := make(map[protocol.DocumentURI][]frameRef)
+	for _, finding := range vs.Findings {
+		if _, typ := foundVuln(finding); typ != vulnCalled {
+			continue
+		}
+		for i, fr := range finding.Trace[1:] {
+			if fr.Module != modPath || fr.Position == nil || fr.Position.Line <= 0 || fr.Position.Filename == "" {
+				continue
+			}
+			filename := fr.Position.Filename
+			if !filepath.IsAbs(filename) {
+				filename = filepath.Join(modDir, filename)
+			}
+			uri := protocol.URIFromPath(filename)
+			if only != "" && uri != only {
+				continue
+			}
+			byFile[uri] = append(byFile[uri], frameRef{finding, i + 1})
+		}
+	}
+
+	var sites []callSite
+	for uri, refs := range byFile {
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			offset := ref.finding.Trace[ref.frame].Position.Offset
+			if offset < 0 || offset >= pgf.Tok.Size() {
+				continue // stale
+			}
+			call := callAt(pgf, offset)
+			if call == nil {
+				continue // stale
+			}
+			// Report the name of the callee, if it has one.
+			var node ast.Node = call
+			switch fun := ast.Unparen(call.Fun).(type) {
+			case *ast.Ident:
+				node = fun
+			case *ast.SelectorExpr:
+				node = fun.Sel
+			}
+			rng, err := pgf.NodeRange(node)
+			if err != nil {
+				return nil, err
+			}
+			sites = append(sites, callSite{uri, rng, ref.finding, ref.frame})
+		}
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if x, y := sites[i], sites[j]; x.uri != y.uri {
+			return x.uri < y.uri
+		} else if c := protocol.CompareRange(x.rng, y.rng); c != 0 {
+			return c < 0
+		} else {
+			return x.finding.OSV < y.finding.OSV
+		}
+	})
+	return sites, nil
+}
+
+// callAt returns the call expression whose opening parenthesis, the
+// position of a call reported by govulncheck, is at offset.
+func callAt(pgf *parsego.File, offset int) *ast.CallExpr {
+	pos := pgf.Tok.Pos(offset)
+	path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+	for _, n := range path {
+		if call, ok := n.(*ast.CallExpr); ok && call.Lparen == pos {
+			return call
+		}
+	}
+	return nil
+}
+
+// callSiteDiagnostics returns a diagnostic for each call, in the
+// module of the go.mod file fh, through which a vulnerable symbol is
+// reached, with fixes to upgrade the vulnerable module to the version
+// that fixes it, if any.
+func callSiteDiagnostics(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pm *cache.ParsedModule, vs *vulncheck.Result, reset cache.SuggestedFix) ([]*cache.Diagnostic, error) {
+	sites, err := callSites(ctx, snapshot, pm, vs, "")
+	if err != nil {
+		return nil, err
+	}
+	var diags []*cache.Diagnostic
+	type key struct {
+		uri protocol.DocumentURI
+		rng protocol.Range
+		osv string
+	}
+	seen := make(map[key]bool)
+	for _, site := range sites {
+		k := key{site.uri, site.rng, site.finding.OSV}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		sink := site.finding.Trace[0]
+		req := findRequire(pm, sink.Module)
+		if req != nil && isFixed(site.finding, req.Mod.Version) {
+			continue // stale: see vulnerabilityDiagnostics
+		}
+		var fixes []cache.SuggestedFix
+		if req != nil && semver.IsValid(site.finding.FixedVersion) {
+			cmd := getUpgradeCodeAction(fh, req, site.finding.FixedVersion)
+			cmd.Title = fmt.Sprintf("Upgrade %s to %s", req.Mod.Path, site.finding.FixedVersion)
+			fixes = append(fixes, cache.SuggestedFixFromCommand(cmd, protocol.QuickFix))
+		}
+		fixes = append(fixes, reset)
+		diags = append(diags, &cache.Diagnostic{
+			URI:            site.uri,
+			Range:          site.rng,
+			Severity:       protocol.SeverityWarning,
+			Source:         cache.Govulncheck,
+			Message:        callSiteMessage(site),
+			SuggestedFixes: fixes,
+		})
+	}
+	return diags, nil
+}
+
+// callSiteMessage returns the diagnostic message for a call site.
+func callSiteMessage(site callSite) string {
+	callee := site.finding.Trace[site.frame-1]
+	sink := site.finding.Trace[0]
+	if site.frame == 1 {
+		return fmt.Sprintf("call to %s has a vulnerability: %s", frameName(sink), site.finding.OSV)
+	}
+	return fmt.Sprintf("call to %s reaches %s, which has a vulnerability: %s", frameName(callee), frameName(sink), site.finding.OSV)
+}
+
+// frameName returns the qualified name of the function of a frame,
+// such as "bvuln.Vuln" or "avuln.VulnData.Vuln1".
+func frameName(fr *govulncheck.Frame) string {
+	name := fr.Function
+	if fr.Receiver != "" {
+		name = strings.TrimPrefix(fr.Receiver, "*") + "." + name
+	}
+	if fr.Package != "" {
+		name = path.Base(fr.Package) + "." + name
+	}
+	return name
+}
+
+// findRequire returns the require directive of pm for the module
+// modPath, or nil.
+func findRequire(pm *cache.ParsedModule, modPath string) *modfile.Require {
+	for _, req := range pm.File.Require {
+		if req.Mod.Path == modPath {
+			return req
+		}
+	}
+	return nil
+}
+
+// isFixed reports whether version is at least the version that fixes
+// the vulnerability of the finding.
+func isFixed(finding *govulncheck.Finding, version string) bool {
+	return finding.FixedVersion != "" && semver.IsValid(version) && semver.Compare(finding.FixedVersion, version) <= 0
+}
+
+// CallSiteHover returns the hover for a call, in the Go file fh,
+// through which a vulnerable symbol is reached according to the
+// results of govulncheck: it describes the vulnerabilities and the
+// path of calls that reaches them. It returns nil if there is no such
+// call at the position.
+func CallSiteHover(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, position protocol.Position) (*protocol.Hover, error) {
+	ctx, done := event.Start(ctx, "mod.CallSiteHover")
+	defer done()
+
+	var (
+		rng   protocol.Range
+		found []callSite
+		osvs  = make(map[string]string) // summaries, by OSV ID
+	)
+	for modURI, vs := range snapshot.Vulnerabilities() {
+		if vs == nil || !modURI.Dir().Encloses(fh.URI()) {
+			continue
+		}
+		modFH, err := snapshot.ReadFile(ctx, modURI)
+		if err != nil {
+			return nil, err
+		}
+		pm, err := snapshot.ParseMod(ctx, modFH)
+		if err != nil {
+			continue
+		}
+		sites, err := callSites(ctx, snapshot, pm, vs, fh.URI())
+		if err != nil {
+			return nil, err
+		}
+		for _, site := range sites {
+			if !protocol.Intersect(site.rng, protocol.Range{Start: position, End: position}) {
+				continue
+			}
+			if req := findRequire(pm, site.finding.Trace[0].Module); req != nil && isFixed(site.finding, req.Mod.Version) {
+				continue
+			}
+			rng = site.rng
+			found = append(found, site)
+			if entry := vs.Entries[site.finding.OSV]; entry != nil {
+				osvs[site.finding.OSV] = entry.Summary
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	options := snapshot.Options()
+	useMarkdown := options.PreferredContentFormat == protocol.Markdown
+	var b strings.Builder
+	fmt.Fprintf(&b, "**WARNING:** This call reaches vulnerable code.\n")
+	seen := make(map[string]bool)
+	for _, site := range found {
+		id := site.finding.OSV
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if useMarkdown {
+			fmt.Fprintf(&b, "\n[**%v**](%v) %v\n%v\n", id, href(id), osvs[id], fixedVersion(site.finding.FixedVersion))
+		} else {
+			fmt.Fprintf(&b, "\n[%v] %v (%v)\n%v\n", id, osvs[id], href(id), fixedVersion(site.finding.FixedVersion))
+		}
+		// Show the calls from the caller to the vulnerable symbol.
+		b.WriteString("\n```text\n")
+		for i, depth := site.frame, 0; i >= 0; i, depth = i-1, depth+1 {
+			fmt.Fprintf(&b, "%s%s\n", strings.Repeat("  ", depth), frameName(site.finding.Trace[i]))
+		}
+		b.WriteString("```\n")
+	}
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  options.PreferredContentFormat,
+			Value: b.String(),
+		},
+		Range: rng,
+	}, nil
+}
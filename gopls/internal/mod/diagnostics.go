@@ -81,7 +81,7 @@ func collectDiagnostics(ctx context.Context, snapshot *cache.Snapshot, diagFn fu
 			}
 			for _, d := range diagnostics {
 				mu.Lock()
-				reports[d.URI] = append(reports[fh.URI()], d)
+				reports[d.URI] = append(reports[d.URI], d)
 				mu.Unlock()
 			}
 			return nil
@@ -323,6 +323,16 @@ func vulnerabilityDiagnostics(ctx context.Context, snapshot *cache.Snapshot, fh
 		}
 	}
 
+	// Add diagnostics at the calls, in the Go files of the module,
+	// through which govulncheck found the vulnerable symbols reachable.
+	if diagSource == cache.Govulncheck {
+		diags, err := callSiteDiagnostics(ctx, snapshot, fh, pm, vs, suggestRunOrResetGovulncheck)
+		if err != nil {
+			return nil, err
+		}
+		vulnDiagnostics = append(vulnDiagnostics, diags...)
+	}
+
 	// TODO(hyangah): place this diagnostic on the `go` directive or `toolchain` directive
 	// after https://go.dev/issue/57001.
 	const diagnoseStdLib = false
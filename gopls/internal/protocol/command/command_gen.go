@@ -24,43 +24,65 @@ import (
 // These commands may be obtained from a CodeLens or CodeAction request
 // and executed by an ExecuteCommand request.
 const (
+	AddContextParam         Command = "gopls.add_context_param"
 	AddDependency           Command = "gopls.add_dependency"
 	AddImport               Command = "gopls.add_import"
+	AddStringMethod         Command = "gopls.add_string_method"
 	AddTelemetryCounters    Command = "gopls.add_telemetry_counters"
 	AddTest                 Command = "gopls.add_test"
+	AddTests                Command = "gopls.add_tests"
+	AffectedTests           Command = "gopls.affected_tests"
+	ApplyAnalyzerFixes      Command = "gopls.apply_analyzer_fixes"
 	ApplyFix                Command = "gopls.apply_fix"
 	Assembly                Command = "gopls.assembly"
+	BrowseCoverage          Command = "gopls.browse_coverage"
+	ChangeReceivers         Command = "gopls.change_receivers"
 	ChangeSignature         Command = "gopls.change_signature"
 	CheckUpgrades           Command = "gopls.check_upgrades"
 	ClientOpenURL           Command = "gopls.client_open_url"
+	ConvertFuncToMethod     Command = "gopls.convert_func_to_method"
+	ConvertMethodToFunc     Command = "gopls.convert_method_to_func"
+	CopyTestCommand         Command = "gopls.copy_test_command"
+	DebugTest               Command = "gopls.debug_test"
 	DiagnoseFiles           Command = "gopls.diagnose_files"
 	Doc                     Command = "gopls.doc"
+	DocumentCoverage        Command = "gopls.document_coverage"
 	EditGoDirective         Command = "gopls.edit_go_directive"
+	ExtractParamStruct      Command = "gopls.extract_param_struct"
 	ExtractToNewFile        Command = "gopls.extract_to_new_file"
 	FetchVulncheckResult    Command = "gopls.fetch_vulncheck_result"
 	FreeSymbols             Command = "gopls.free_symbols"
+	FunctionCoverage        Command = "gopls.function_coverage"
 	GCDetails               Command = "gopls.gc_details"
 	Generate                Command = "gopls.generate"
 	GoGetPackage            Command = "gopls.go_get_package"
+	GoToTestOrSubject       Command = "gopls.go_to_test_or_subject"
 	ListImports             Command = "gopls.list_imports"
 	ListKnownPackages       Command = "gopls.list_known_packages"
+	LoadCoverage            Command = "gopls.load_coverage"
 	MaybePromptForTelemetry Command = "gopls.maybe_prompt_for_telemetry"
 	MemStats                Command = "gopls.mem_stats"
+	MissingTests            Command = "gopls.missing_tests"
 	Modules                 Command = "gopls.modules"
 	PackageSymbols          Command = "gopls.package_symbols"
 	Packages                Command = "gopls.packages"
 	RegenerateCgo           Command = "gopls.regenerate_cgo"
 	RemoveDependency        Command = "gopls.remove_dependency"
 	ResetGoModDiagnostics   Command = "gopls.reset_go_mod_diagnostics"
+	RunBenchmark            Command = "gopls.run_benchmark"
 	RunGoWorkCommand        Command = "gopls.run_go_work_command"
 	RunGovulncheck          Command = "gopls.run_govulncheck"
 	RunTests                Command = "gopls.run_tests"
 	ScanImports             Command = "gopls.scan_imports"
+	SplitFile               Command = "gopls.split_file"
 	StartDebugging          Command = "gopls.start_debugging"
 	StartProfile            Command = "gopls.start_profile"
 	StopProfile             Command = "gopls.stop_profile"
+	StreamTests             Command = "gopls.stream_tests"
 	Tidy                    Command = "gopls.tidy"
+	Unexport                Command = "gopls.unexport"
 	UpdateGoSum             Command = "gopls.update_go_sum"
+	UpdateTests             Command = "gopls.update_tests"
 	UpgradeDependency       Command = "gopls.upgrade_dependency"
 	Vendor                  Command = "gopls.vendor"
 	Views                   Command = "gopls.views"
@@ -69,43 +91,65 @@ const (
 )
 
 var Commands = []Command{
+	AddContextParam,
 	AddDependency,
 	AddImport,
+	AddStringMethod,
 	AddTelemetryCounters,
 	AddTest,
+	AddTests,
+	AffectedTests,
+	ApplyAnalyzerFixes,
 	ApplyFix,
 	Assembly,
+	BrowseCoverage,
+	ChangeReceivers,
 	ChangeSignature,
 	CheckUpgrades,
 	ClientOpenURL,
+	ConvertFuncToMethod,
+	ConvertMethodToFunc,
+	CopyTestCommand,
+	DebugTest,
 	DiagnoseFiles,
 	Doc,
+	DocumentCoverage,
 	EditGoDirective,
+	ExtractParamStruct,
 	ExtractToNewFile,
 	FetchVulncheckResult,
 	FreeSymbols,
+	FunctionCoverage,
 	GCDetails,
 	Generate,
 	GoGetPackage,
+	GoToTestOrSubject,
 	ListImports,
 	ListKnownPackages,
+	LoadCoverage,
 	MaybePromptForTelemetry,
 	MemStats,
+	MissingTests,
 	Modules,
 	PackageSymbols,
 	Packages,
 	RegenerateCgo,
 	RemoveDependency,
 	ResetGoModDiagnostics,
+	RunBenchmark,
 	RunGoWorkCommand,
 	RunGovulncheck,
 	RunTests,
 	ScanImports,
+	SplitFile,
 	StartDebugging,
 	StartProfile,
 	StopProfile,
+	StreamTests,
 	Tidy,
+	Unexport,
 	UpdateGoSum,
+	UpdateTests,
 	UpgradeDependency,
 	Vendor,
 	Views,
@@ -115,6 +159,12 @@ var Commands = []Command{
 
 func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Interface) (any, error) {
 	switch Command(params.Command) {
+	case AddContextParam:
+		var a0 AddContextParamArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.AddContextParam(ctx, a0)
 	case AddDependency:
 		var a0 DependencyArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -127,6 +177,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.AddImport(ctx, a0)
+	case AddStringMethod:
+		var a0 AddStringMethodArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.AddStringMethod(ctx, a0)
 	case AddTelemetryCounters:
 		var a0 AddTelemetryCountersArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -134,11 +190,29 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 		}
 		return nil, s.AddTelemetryCounters(ctx, a0)
 	case AddTest:
-		var a0 protocol.Location
+		var a0 AddTestArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
 			return nil, err
 		}
 		return s.AddTest(ctx, a0)
+	case AddTests:
+		var a0 AddTestsArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.AddTests(ctx, a0)
+	case AffectedTests:
+		var a0 AffectedTestsArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.AffectedTests(ctx, a0)
+	case ApplyAnalyzerFixes:
+		var a0 ApplyAnalyzerFixesArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.ApplyAnalyzerFixes(ctx, a0)
 	case ApplyFix:
 		var a0 ApplyFixArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -153,6 +227,18 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.Assembly(ctx, a0, a1, a2)
+	case BrowseCoverage:
+		var a0 URIArg
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.BrowseCoverage(ctx, a0)
+	case ChangeReceivers:
+		var a0 ChangeReceiversArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ChangeReceivers(ctx, a0)
 	case ChangeSignature:
 		var a0 ChangeSignatureArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -171,6 +257,30 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.ClientOpenURL(ctx, a0)
+	case ConvertFuncToMethod:
+		var a0 protocol.Location
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ConvertFuncToMethod(ctx, a0)
+	case ConvertMethodToFunc:
+		var a0 protocol.Location
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ConvertMethodToFunc(ctx, a0)
+	case CopyTestCommand:
+		var a0 protocol.Location
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.CopyTestCommand(ctx, a0)
+	case DebugTest:
+		var a0 DebugTestArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.DebugTest(ctx, a0)
 	case DiagnoseFiles:
 		var a0 DiagnoseFilesArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -183,12 +293,24 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.Doc(ctx, a0)
+	case DocumentCoverage:
+		var a0 protocol.TextDocumentIdentifier
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.DocumentCoverage(ctx, a0)
 	case EditGoDirective:
 		var a0 EditGoDirectiveArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
 			return nil, err
 		}
 		return nil, s.EditGoDirective(ctx, a0)
+	case ExtractParamStruct:
+		var a0 protocol.Location
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ExtractParamStruct(ctx, a0)
 	case ExtractToNewFile:
 		var a0 protocol.Location
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -208,6 +330,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.FreeSymbols(ctx, a0, a1)
+	case FunctionCoverage:
+		var a0 FunctionCoverageArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.FunctionCoverage(ctx, a0)
 	case GCDetails:
 		var a0 protocol.DocumentURI
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -226,6 +354,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.GoGetPackage(ctx, a0)
+	case GoToTestOrSubject:
+		var a0 protocol.Location
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.GoToTestOrSubject(ctx, a0)
 	case ListImports:
 		var a0 URIArg
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -238,10 +372,22 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.ListKnownPackages(ctx, a0)
+	case LoadCoverage:
+		var a0 URIArg
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.LoadCoverage(ctx, a0)
 	case MaybePromptForTelemetry:
 		return nil, s.MaybePromptForTelemetry(ctx)
 	case MemStats:
 		return s.MemStats(ctx)
+	case MissingTests:
+		var a0 URIArg
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.MissingTests(ctx, a0)
 	case Modules:
 		var a0 ModulesArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -278,6 +424,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.ResetGoModDiagnostics(ctx, a0)
+	case RunBenchmark:
+		var a0 RunBenchmarkArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.RunBenchmark(ctx, a0)
 	case RunGoWorkCommand:
 		var a0 RunGoWorkArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -298,6 +450,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 		return nil, s.RunTests(ctx, a0)
 	case ScanImports:
 		return nil, s.ScanImports(ctx)
+	case SplitFile:
+		var a0 protocol.Location
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.SplitFile(ctx, a0)
 	case StartDebugging:
 		var a0 DebuggingArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -316,18 +474,36 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.StopProfile(ctx, a0)
+	case StreamTests:
+		var a0 StreamTestsArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.StreamTests(ctx, a0)
 	case Tidy:
 		var a0 URIArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
 			return nil, err
 		}
 		return nil, s.Tidy(ctx, a0)
+	case Unexport:
+		var a0 protocol.Location
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.Unexport(ctx, a0)
 	case UpdateGoSum:
 		var a0 URIArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
 			return nil, err
 		}
 		return nil, s.UpdateGoSum(ctx, a0)
+	case UpdateTests:
+		var a0 UpdateTestsArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.UpdateTests(ctx, a0)
 	case UpgradeDependency:
 		var a0 DependencyArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -354,6 +530,14 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 	return nil, fmt.Errorf("unsupported command %q", params.Command)
 }
 
+func NewAddContextParamCommand(title string, a0 AddContextParamArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   AddContextParam.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewAddDependencyCommand(title string, a0 DependencyArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -370,6 +554,14 @@ func NewAddImportCommand(title string, a0 AddImportArgs) *protocol.Command {
 	}
 }
 
+func NewAddStringMethodCommand(title string, a0 AddStringMethodArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   AddStringMethod.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewAddTelemetryCountersCommand(title string, a0 AddTelemetryCountersArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -378,7 +570,7 @@ func NewAddTelemetryCountersCommand(title string, a0 AddTelemetryCountersArgs) *
 	}
 }
 
-func NewAddTestCommand(title string, a0 protocol.Location) *protocol.Command {
+func NewAddTestCommand(title string, a0 AddTestArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
 		Command:   AddTest.String(),
@@ -386,6 +578,34 @@ func NewAddTestCommand(title string, a0 protocol.Location) *protocol.Command {
 	}
 }
 
+func NewAddTestsCommand(title string, a0 AddTestsArgs) (*protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.Command{
+		Title:     title,
+		Command:   AddTests.String(),
+		Arguments: args,
+	}, nil
+}
+
+func NewAffectedTestsCommand(title string, a0 AffectedTestsArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   AffectedTests.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
+func NewApplyAnalyzerFixesCommand(title string, a0 ApplyAnalyzerFixesArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   ApplyAnalyzerFixes.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewApplyFixCommand(title string, a0 ApplyFixArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -402,6 +622,22 @@ func NewAssemblyCommand(title string, a0 string, a1 string, a2 string) *protocol
 	}
 }
 
+func NewBrowseCoverageCommand(title string, a0 URIArg) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   BrowseCoverage.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
+func NewChangeReceiversCommand(title string, a0 ChangeReceiversArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   ChangeReceivers.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewChangeSignatureCommand(title string, a0 ChangeSignatureArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -426,6 +662,38 @@ func NewClientOpenURLCommand(title string, a0 string) *protocol.Command {
 	}
 }
 
+func NewConvertFuncToMethodCommand(title string, a0 protocol.Location) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   ConvertFuncToMethod.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
+func NewConvertMethodToFuncCommand(title string, a0 protocol.Location) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   ConvertMethodToFunc.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
+func NewCopyTestCommandCommand(title string, a0 protocol.Location) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   CopyTestCommand.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
+func NewDebugTestCommand(title string, a0 DebugTestArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   DebugTest.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewDiagnoseFilesCommand(title string, a0 DiagnoseFilesArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -442,6 +710,14 @@ func NewDocCommand(title string, a0 DocArgs) *protocol.Command {
 	}
 }
 
+func NewDocumentCoverageCommand(title string, a0 protocol.TextDocumentIdentifier) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   DocumentCoverage.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewEditGoDirectiveCommand(title string, a0 EditGoDirectiveArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -450,6 +726,14 @@ func NewEditGoDirectiveCommand(title string, a0 EditGoDirectiveArgs) *protocol.C
 	}
 }
 
+func NewExtractParamStructCommand(title string, a0 protocol.Location) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   ExtractParamStruct.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewExtractToNewFileCommand(title string, a0 protocol.Location) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -474,6 +758,14 @@ func NewFreeSymbolsCommand(title string, a0 string, a1 protocol.Location) *proto
 	}
 }
 
+func NewFunctionCoverageCommand(title string, a0 FunctionCoverageArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   FunctionCoverage.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewGCDetailsCommand(title string, a0 protocol.DocumentURI) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -498,6 +790,14 @@ func NewGoGetPackageCommand(title string, a0 GoGetPackageArgs) *protocol.Command
 	}
 }
 
+func NewGoToTestOrSubjectCommand(title string, a0 protocol.Location) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   GoToTestOrSubject.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewListImportsCommand(title string, a0 URIArg) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -514,6 +814,14 @@ func NewListKnownPackagesCommand(title string, a0 URIArg) *protocol.Command {
 	}
 }
 
+func NewLoadCoverageCommand(title string, a0 URIArg) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   LoadCoverage.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewMaybePromptForTelemetryCommand(title string) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -530,6 +838,14 @@ func NewMemStatsCommand(title string) *protocol.Command {
 	}
 }
 
+func NewMissingTestsCommand(title string, a0 URIArg) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   MissingTests.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewModulesCommand(title string, a0 ModulesArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -578,6 +894,14 @@ func NewResetGoModDiagnosticsCommand(title string, a0 ResetGoModDiagnosticsArgs)
 	}
 }
 
+func NewRunBenchmarkCommand(title string, a0 RunBenchmarkArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   RunBenchmark.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewRunGoWorkCommandCommand(title string, a0 RunGoWorkArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -610,6 +934,14 @@ func NewScanImportsCommand(title string) *protocol.Command {
 	}
 }
 
+func NewSplitFileCommand(title string, a0 protocol.Location) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   SplitFile.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewStartDebuggingCommand(title string, a0 DebuggingArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -634,6 +966,18 @@ func NewStopProfileCommand(title string, a0 StopProfileArgs) *protocol.Command {
 	}
 }
 
+func NewStreamTestsCommand(title string, a0 StreamTestsArgs) (*protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.Command{
+		Title:     title,
+		Command:   StreamTests.String(),
+		Arguments: args,
+	}, nil
+}
+
 func NewTidyCommand(title string, a0 URIArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -642,6 +986,14 @@ func NewTidyCommand(title string, a0 URIArgs) *protocol.Command {
 	}
 }
 
+func NewUnexportCommand(title string, a0 protocol.Location) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   Unexport.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewUpdateGoSumCommand(title string, a0 URIArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -650,6 +1002,14 @@ func NewUpdateGoSumCommand(title string, a0 URIArgs) *protocol.Command {
 	}
 }
 
+func NewUpdateTestsCommand(title string, a0 UpdateTestsArgs) *protocol.Command {
+	return &protocol.Command{
+		Title:     title,
+		Command:   UpdateTests.String(),
+		Arguments: MustMarshalArgs(a0),
+	}
+}
+
 func NewUpgradeDependencyCommand(title string, a0 DependencyArgs) *protocol.Command {
 	return &protocol.Command{
 		Title:     title,
@@ -47,6 +47,19 @@ type Interface interface {
 	// Applies a fix to a region of source code.
 	ApplyFix(context.Context, ApplyFixArgs) (*protocol.WorkspaceEdit, error)
 
+	// ApplyAnalyzerFixes: Apply the suggested fixes of analyzers to packages
+	//
+	// Runs the enabled analyzers over the workspace packages in the
+	// specified directory, or in the specified Go file, and applies the
+	// first suggested fix of each diagnostic, optionally restricted to
+	// particular analyzers and categories. A fix that conflicts with an
+	// earlier one is skipped, as are lazy fixes that create or delete
+	// files. With Recursive, the packages in the subdirectories of the
+	// directory are processed too, as with the ... pattern.
+	//
+	// This command is needed by the 'gopls fix' CLI subcommand.
+	ApplyAnalyzerFixes(context.Context, ApplyAnalyzerFixesArgs) (ApplyAnalyzerFixesResult, error)
+
 	// RunTests: Run tests
 	//
 	// Runs `go test` for a specific set of test or benchmark functions.
@@ -54,6 +67,19 @@ type Interface interface {
 	// This command is asynchronous; clients must wait for the 'end' progress notification.
 	RunTests(context.Context, RunTestsArgs) error
 
+	// RunBenchmark: Run a benchmark and compare it with its previous run
+	//
+	// Runs "go test -bench" several times for the specified Benchmark
+	// function and its sub-benchmarks, records their measurements, and
+	// compares them with those of the previous run of the function, in
+	// the manner of benchstat: the median and variation of each metric,
+	// and the change of the median, if significant. The comparison is
+	// shown in a message and returned, and the "benchmark" code lens of
+	// the function shows its summary.
+	//
+	// This command is asynchronous; clients must wait for the 'end' progress notification.
+	RunBenchmark(context.Context, RunBenchmarkArgs) (RunBenchmarkResult, error)
+
 	// Generate: Run go generate
 	//
 	// Runs `go generate` for a given directory.
@@ -153,6 +179,21 @@ type Interface interface {
 	// Used by the code action of the same name.
 	ExtractToNewFile(context.Context, protocol.Location) error
 
+	// SplitFile: Split a file into several files
+	//
+	// Moves each type declaration of the file, along with its methods
+	// and the declarations that belong with it, into a new file named
+	// after the type. Used by the "Split file into N files" code action.
+	SplitFile(context.Context, protocol.Location) error
+
+	// ExtractParamStruct: Extract parameters into a struct
+	//
+	// Replaces the parameters of the selected function F, other than
+	// a leading context.Context, by a single parameter of a new
+	// struct type FOptions, and converts each call to pass a keyed
+	// literal of that type.
+	ExtractParamStruct(context.Context, protocol.Location) error
+
 	// StartDebugging: Start the gopls debug server
 	//
 	// Start the gopls debug server if it isn't running, and return the debug
@@ -227,7 +268,215 @@ type Interface interface {
 	AddTelemetryCounters(context.Context, AddTelemetryCountersArgs) error
 
 	// AddTest: add test for the selected function
-	AddTest(context.Context, protocol.Location) (*protocol.WorkspaceEdit, error)
+	//
+	// With Debug, the command also returns the configuration of a
+	// debug session of the new test, as does the DebugTest command, so
+	// that a client may offer to generate and debug a test in a single
+	// gesture.
+	AddTest(context.Context, AddTestArgs) (*AddTestResult, error)
+
+	// AddStringMethod: Generate String method for enum type
+	//
+	// Generates a String method for the integer type of the selected
+	// constant declaration, in a new file named after the type.
+	// Used by the code action of the same name.
+	AddStringMethod(context.Context, AddStringMethodArgs) (*protocol.WorkspaceEdit, error)
+
+	// ChangeReceivers: Change the receivers of all methods of a type
+	//
+	// Changes the receivers of all methods of the type of the selected
+	// method's receiver to pointers or to values, updating the workspace
+	// accordingly. Used by the "Convert all methods of T to pointer
+	// receivers" and "Convert all methods of T to value receivers" code
+	// actions.
+	ChangeReceivers(context.Context, ChangeReceiversArgs) error
+
+	// Unexport: Unexport an identifier
+	//
+	// Renames the selected exported identifier to its unexported form
+	// throughout the workspace, provided that it is not used outside
+	// its package. Otherwise it reports the uses that prevent it.
+	// Used by the "Unexport X" code action.
+	Unexport(context.Context, protocol.Location) error
+
+	// ConvertFuncToMethod: Convert a function to a method
+	//
+	// Converts the selected function, whose first parameter has type T
+	// or *T, to a method of T, updating its references throughout the
+	// workspace. Used by the "Convert function F to method of T" code
+	// action.
+	ConvertFuncToMethod(context.Context, protocol.Location) error
+
+	// ConvertMethodToFunc: Convert a method to a function
+	//
+	// Converts the selected method to a function whose first parameter
+	// is the receiver, updating its references throughout the
+	// workspace. Used by the "Convert method T.F to function" code
+	// action.
+	ConvertMethodToFunc(context.Context, protocol.Location) error
+
+	// AddContextParam: Add a context.Context parameter
+	//
+	// Adds a parameter ctx of type context.Context to the selected
+	// function, and updates its calls throughout the workspace to pass
+	// a context.Context variable in scope, or else
+	// context.Background(). If Callers is set, the parameter is
+	// instead added to each calling function that has no context in
+	// scope, recursively. Used by the "Add context.Context parameter
+	// to F" code actions.
+	AddContextParam(context.Context, AddContextParamArgs) error
+
+	// LoadCoverage: Load a coverage profile
+	//
+	// Reads the coverage profile at the specified URI, such as one
+	// produced by "go test -coverprofile", and records the coverage of
+	// each of its files in the workspace, which hovering over a function
+	// and the DocumentCoverage command then report. When a file changes,
+	// the coverage of the statements that the change touches is
+	// discarded, and that of the others follows them.
+	LoadCoverage(context.Context, URIArg) error
+
+	// DocumentCoverage: Report the covered and uncovered ranges of a file
+	//
+	// Reports the ranges of the statements of the specified Go file
+	// that the tests covered and did not cover, according to the
+	// coverage recorded by the LoadCoverage command or by the RunTests
+	// command with Coverage, so that the client may highlight them, as
+	// it would the ranges of a documentColor request. The ranges follow
+	// the edits to the file, from which edited statements are removed,
+	// so the client should request them again after each change.
+	DocumentCoverage(context.Context, protocol.TextDocumentIdentifier) (DocumentCoverageResult, error)
+
+	// BrowseCoverage: Browse the test coverage of a file in a browser
+	//
+	// Opens a view, in a browser, of the source of the specified Go
+	// file annotated with the coverage recorded by the LoadCoverage
+	// command or by the RunTests command with Coverage, in the manner
+	// of "go tool cover -html": the statements that the tests covered
+	// and did not cover are highlighted, and each line links to its
+	// position in the editor. Hovering over a function whose coverage
+	// is known links to this view.
+	BrowseCoverage(context.Context, URIArg) error
+
+	// FunctionCoverage: Report the test coverage of functions
+	//
+	// Reads the coverage profile at the specified URI, such as one
+	// produced by "go test -coverprofile", and reports the numbers of
+	// covered and uncovered lines of each function and method declared
+	// in the specified Go file, or in the files of the package in the
+	// specified directory. With Recursive, the packages in the
+	// subdirectories of the directory are reported too, as with the
+	// ... pattern. Files absent from the profile are not reported.
+	FunctionCoverage(context.Context, FunctionCoverageArgs) (FunctionCoverageResult, error)
+
+	// GoToTestOrSubject: Go to the tests of a function, or the subjects of a test
+	//
+	// Finds the counterparts of the function or method declaration
+	// enclosing the specified location: the Test, Benchmark, Fuzz, and
+	// Example functions that exercise it, or, for such a test, the
+	// functions and methods that it exercises. A test exercises the
+	// function after which it is named, such as Parse for TestParse or
+	// T.M for TestT_M, and the functions of the package under test that
+	// it calls directly. The client shows the first counterpart, and
+	// the command returns them all. Used by the "test_navigation"
+	// code lens.
+	GoToTestOrSubject(context.Context, protocol.Location) ([]protocol.Location, error)
+
+	// AddTests: Add tests for the functions of a file or package
+	//
+	// Adds a table-driven test for each function and method, declared
+	// in the specified Go file or in the files of the package in the
+	// specified directory, that has no test, as identified by its
+	// name. Each file's tests are added to its _test.go file. The files
+	// are processed concurrently, reporting progress, and the command
+	// may be canceled. The edits for each file are applied as soon as
+	// they are computed, so that the work done is kept if the command
+	// is canceled, and, if a progress token is specified, are also
+	// sent to the client as the value, a WorkspaceEdit, of a
+	// $/progress notification for that token. With Recursive, the
+	// packages in the subdirectories of the directory are processed
+	// too, as with the ... pattern.
+	AddTests(context.Context, AddTestsArgs) (AddTestsResult, error)
+
+	// UpdateTests: Update generated tests to match their subjects
+	//
+	// Realigns the table-driven tests, as generated by "Add test", of
+	// the functions and methods declared in the specified Go file or
+	// in the files of the package in the specified directory with
+	// their current signatures: when parameters or results have been
+	// added, removed, or retyped, it updates the fields of the table of
+	// test cases, removes the values of removed fields from the cases,
+	// and updates the calls to the function. With Recursive, the
+	// packages in the subdirectories of the directory are processed
+	// too, as with the ... pattern. The command returns the number of
+	// updated tests, and the test cases that need manual attention.
+	UpdateTests(context.Context, UpdateTestsArgs) (UpdateTestsResult, error)
+
+	// MissingTests: Browse the functions without tests in a browser
+	//
+	// Opens a report, in a browser, that lists the functions and
+	// methods of the workspace packages of the view of the specified
+	// file or directory that have no test, as identified by their
+	// names, grouped by package, with the number of functions of each
+	// package. Each function links to its declaration in the editor,
+	// and to an action that adds a table-driven test for it, as does
+	// the "Add test" code action.
+	MissingTests(context.Context, URIArg) error
+
+	// AffectedTests: List the tests affected by changes
+	//
+	// Reports the tests of the workspace packages of the view of the
+	// specified file or directory that changes to the specified
+	// files, or, if none are specified, the unsaved changes of the
+	// open files, may affect, grouped by package. A change to a test
+	// affects that test; a change to a function or method affects the
+	// tests that exercise it, or that exercise a function of its
+	// package that calls it, directly or not; and any other change to
+	// a package, such as to a type or a variable, affects all the
+	// tests of the package and of the workspace packages that import
+	// it, directly or not. With Run, the affected tests are also run,
+	// as by the "Run affected tests" command, which requires that all
+	// files be saved.
+	AffectedTests(context.Context, AffectedTestsArgs) (AffectedTestsResult, error)
+
+	// StreamTests: Run tests, streaming their results
+	//
+	// Runs "go test -json" on the package of the specified Go file, or
+	// on the package in the specified directory, selecting the
+	// specified tests and subtests, or all of them if none are
+	// specified. Each event reported by the test binary (see "go doc
+	// test2json"), such as the start, output, or result of a test, is
+	// sent to the client as the value of a $/progress notification
+	// for the specified token, which the client creates, so that the
+	// client may present the results as they arrive. The command
+	// returns the numbers of tests that passed, failed, and were
+	// skipped, and the results of each package and test, correlated
+	// from the events: the outcome, duration, and output of each
+	// test, nested by subtest.
+	StreamTests(context.Context, StreamTestsArgs) (StreamTestsResult, error)
+
+	// DebugTest: Debug a test or subtest
+	//
+	// Returns the configuration with which a client that supports the
+	// Debug Adapter Protocol may launch a debug session of the
+	// specified test or subtest of the package of the specified
+	// _test.go file, or of the test or subtest at the specified
+	// location: the package path and directory, the name of the
+	// top-level test, the -test.run filter that selects the test or
+	// subtest, and the build flags and environment of the workspace.
+	// The command has no effect on the server; it is used by the
+	// "debug_test" code lens.
+	DebugTest(context.Context, DebugTestArgs) (DebugTestResult, error)
+
+	// CopyTestCommand: Copy the go test command of a subtest
+	//
+	// Computes the "go test" command that runs only the subtest whose
+	// name is the string literal at the specified location: the name
+	// argument of a t.Run call, or the name field of a case of a
+	// table-driven test whose subtests are named by that field. The
+	// command, such as go test -run '^TestFoo$/^some_name$', is shown
+	// in a message, from which the user may copy it, and returned.
+	CopyTestCommand(context.Context, protocol.Location) (string, error)
 
 	// MaybePromptForTelemetry: Prompt user to enable telemetry
 	//
@@ -238,7 +487,9 @@ type Interface interface {
 
 	// ChangeSignature: Perform a "change signature" refactoring
 	//
-	// This command is experimental, currently only supporting parameter removal.
+	// This command is experimental, currently only supporting changes to
+	// parameters: they may be added, removed, reordered, or retyped, and
+	// every call site in the workspace is updated accordingly.
 	// Its signature will certainly change in the future (pun intended).
 	ChangeSignature(context.Context, ChangeSignatureArgs) (*protocol.WorkspaceEdit, error)
 
@@ -303,11 +554,86 @@ type RunTestsArgs struct {
 	// The test file containing the tests to run.
 	URI protocol.DocumentURI
 
-	// Specific test names to run, e.g. TestFoo.
+	// Specific test names to run, e.g. TestFoo, or subtest names,
+	// e.g. TestFoo/case_name.
 	Tests []string
 
 	// Specific benchmarks to run, e.g. BenchmarkFoo.
 	Benchmarks []string
+
+	// Coverage reports whether to record the coverage of the tests,
+	// as by the LoadCoverage command, so that the DocumentCoverage
+	// command reports it.
+	Coverage bool `json:"Coverage,omitempty"`
+}
+
+// DocumentCoverageResult holds the coverage of a file reported by the
+// DocumentCoverage command.
+type DocumentCoverageResult struct {
+	// Version is the version of the file to which the ranges apply.
+	Version int32
+
+	// Covered and Uncovered are the ranges of the statements that
+	// the tests executed and did not execute, in order.
+	Covered   []protocol.Range
+	Uncovered []protocol.Range
+}
+
+// RunBenchmarkArgs specifies the benchmark of the RunBenchmark
+// command.
+type RunBenchmarkArgs struct {
+	// The test file containing the benchmark.
+	URI protocol.DocumentURI
+
+	// The name of the Benchmark function, e.g. BenchmarkFoo.
+	Benchmark string
+}
+
+// RunBenchmarkResult holds the comparison of the runs of a benchmark
+// by the RunBenchmark command.
+type RunBenchmarkResult struct {
+	// Metrics holds a comparison for each metric, such as ns/op, B/op,
+	// or allocs/op, of each benchmark, in order of report.
+	Metrics []BenchmarkMetric
+}
+
+// A BenchmarkMetric compares the measurements of a metric of a
+// benchmark by two runs.
+type BenchmarkMetric struct {
+	// Name is the name of the benchmark, e.g. BenchmarkFoo/small.
+	Name string
+
+	// Unit is the unit of the metric, e.g. ns/op.
+	Unit string
+
+	// Old summarizes the measurements of the previous run, if any.
+	Old *BenchmarkSummary `json:"Old,omitempty"`
+
+	// New summarizes the measurements of the latest run.
+	New BenchmarkSummary
+
+	// Delta is the relative change of the median from Old to New,
+	// e.g. -0.1 for a decrease of 10%.
+	Delta float64 `json:"Delta,omitempty"`
+
+	// Significant reports whether the change is significant: the
+	// ranges of the measurements of the two runs do not overlap.
+	Significant bool `json:"Significant,omitempty"`
+}
+
+// A BenchmarkSummary summarizes the measurements of a metric of a
+// benchmark by a run.
+type BenchmarkSummary struct {
+	Median float64
+	Min    float64
+	Max    float64
+
+	// Variation is the largest deviation of a measurement from the
+	// median relative to it, e.g. 0.02 for ±2%.
+	Variation float64
+
+	// N is the number of measurements.
+	N int
 }
 
 type GenerateArgs struct {
@@ -316,6 +642,10 @@ type GenerateArgs struct {
 
 	// Whether to generate recursively (go generate ./...)
 	Recursive bool
+
+	// If nonempty, a regular expression that selects the directives
+	// to run (go generate -run).
+	Run string `json:",omitempty"`
 }
 
 type DocArgs struct {
@@ -534,8 +864,20 @@ type MemStatsResult struct {
 // WorkspaceStatsResult returns information about the size and shape of the
 // workspace.
 type WorkspaceStatsResult struct {
-	Files FileStats   // file stats for the cache
-	Views []ViewStats // stats for each view in the session
+	Files    FileStats      // file stats for the cache
+	Views    []ViewStats    // stats for each view in the session
+	Commands []CommandStats // stats for each command executed by the server, by name
+}
+
+// CommandStats holds information about the executions of a command.
+type CommandStats struct {
+	Command    string  // name of the command, such as "gopls.add_test"
+	Started    int64   // number of executions started
+	Completed  int64   // number of executions completed, successfully or not
+	Failed     int64   // number of executions that failed
+	Canceled   int64   // number of executions that were canceled
+	MeanMillis float64 // mean duration of completed executions, in milliseconds
+	MaxMillis  float64 // maximum duration of completed executions, in milliseconds
 }
 
 // FileStats holds information about a set of files.
@@ -575,6 +917,463 @@ type AddTelemetryCountersArgs struct {
 	Values []int64  // Values added to the corresponding counters. Must be non-negative.
 }
 
+// AddTestArgs specifies a function or method for which to add a test,
+// or a type for whose methods to add tests.
+type AddTestArgs struct {
+	// Location is a range within the declaration of the function,
+	// or of the type.
+	Location protocol.Location
+
+	// Integration reports whether to add an integration test of the
+	// function, in the file foo_integration_test.go guarded by the
+	// build tag "integration", which uses real values rather than
+	// fakes.
+	Integration bool
+
+	// ReceiverForms reports whether the test of a method with a value
+	// receiver of type T calls it in a subtest for each of T, *T, and
+	// the interfaces of its package that T implements. The test of a
+	// method with a pointer receiver calls it through *T and the
+	// interfaces that *T implements.
+	ReceiverForms bool
+
+	// FieldAssertions reports whether the test compares each field of
+	// a struct result of the function with its own want field, such
+	// as wantName, rather than the whole result.
+	FieldAssertions bool
+
+	// Debug reports whether to return the configuration of a debug
+	// session of the new test. It is ignored when the range is within
+	// a type declaration, as a test is then added for each method.
+	Debug bool `json:"Debug,omitempty"`
+
+	// Whether to resolve and return the edits.
+	ResolveEdits bool
+}
+
+// UnmarshalJSON also accepts a bare [protocol.Location], the
+// argument of the AddTest command before gopls v0.19.
+func (a *AddTestArgs) UnmarshalJSON(b []byte) error {
+	var loc protocol.Location
+	if err := json.Unmarshal(b, &loc); err == nil && loc.URI != "" {
+		*a = AddTestArgs{Location: loc}
+		return nil
+	}
+	type args AddTestArgs // without the UnmarshalJSON method
+	return json.Unmarshal(b, (*args)(a))
+}
+
+// AddTestResult is the result of the AddTest command.
+type AddTestResult struct {
+	// Edit holds the edits that add the test, with ResolveEdits.
+	Edit *protocol.WorkspaceEdit `json:"Edit,omitempty"`
+
+	// Debug is the configuration of a debug session of the new test,
+	// if requested.
+	Debug *DebugTestResult `json:"Debug,omitempty"`
+}
+
+// AddTestsArgs specifies the functions and methods for which to add
+// tests.
+type AddTestsArgs struct {
+	// URI is a Go file, or the directory of a package.
+	URI protocol.DocumentURI
+
+	// Recursive reports whether to also add tests for the packages in
+	// the subdirectories of the directory that are loaded in the
+	// workspace.
+	Recursive bool `json:"Recursive,omitempty"`
+
+	// ExportedOnly reports whether to add tests only for exported
+	// functions and for exported methods of exported types.
+	ExportedOnly bool `json:"ExportedOnly,omitempty"`
+
+	// Whether to resolve and return the edits.
+	ResolveEdits bool `json:"ResolveEdits,omitempty"`
+
+	// Token, if set, is the progress token with which to report the
+	// edits for each file as a partial result, as soon as they are
+	// computed.
+	Token protocol.ProgressToken `json:"Token,omitempty"`
+}
+
+// AddTestsResult summarizes the tests added by the AddTests command.
+type AddTestsResult struct {
+	// Edit holds the edits that add the tests, if ResolveEdits was
+	// set.
+	Edit *protocol.WorkspaceEdit `json:"Edit,omitempty"`
+
+	// Processed is the number of functions and methods without tests.
+	Processed int
+
+	// Skipped is the number of them for which no test could be
+	// added, such as unexported functions when the tests belong to
+	// the external test package.
+	Skipped int
+
+	// Created lists the _test.go files created.
+	Created []protocol.DocumentURI
+}
+
+// ApplyAnalyzerFixesArgs specifies the packages whose analyzer fixes
+// to apply.
+type ApplyAnalyzerFixesArgs struct {
+	// URI is a Go file, or the directory of a package.
+	URI protocol.DocumentURI
+
+	// Recursive reports whether to also fix the packages in the
+	// subdirectories of the directory that are loaded in the
+	// workspace.
+	Recursive bool `json:"Recursive,omitempty"`
+
+	// Analyzers, if non-empty, restricts the fixes to those of
+	// diagnostics reported by the named analyzers.
+	Analyzers []string `json:"Analyzers,omitempty"`
+
+	// Categories, if non-empty, restricts the fixes to those of
+	// diagnostics of the specified categories.
+	Categories []string `json:"Categories,omitempty"`
+
+	// Whether to resolve and return the edits.
+	ResolveEdits bool `json:"ResolveEdits,omitempty"`
+}
+
+// ApplyAnalyzerFixesResult summarizes the fixes applied by the
+// ApplyAnalyzerFixes command.
+type ApplyAnalyzerFixesResult struct {
+	// Edit holds the edits of the fixes, if ResolveEdits was set.
+	Edit *protocol.WorkspaceEdit `json:"Edit,omitempty"`
+
+	// Applied is the number of fixes applied.
+	Applied int
+
+	// Conflicts is the number of fixes skipped because their edits
+	// overlap those of a fix already applied.
+	Conflicts int
+
+	// Skipped is the number of fixes that could not be applied in
+	// bulk, such as those that create files.
+	Skipped int
+}
+
+// FunctionCoverageArgs specifies the coverage profile and the
+// functions of the FunctionCoverage command.
+type FunctionCoverageArgs struct {
+	// Profile is the coverage profile.
+	Profile protocol.DocumentURI
+
+	// URI is a Go file, or the directory of a package.
+	URI protocol.DocumentURI
+
+	// Recursive reports whether to also report the packages in the
+	// subdirectories of the directory that are loaded in the
+	// workspace.
+	Recursive bool `json:"Recursive,omitempty"`
+}
+
+// FunctionCoverageResult holds the coverage of the functions reported
+// by the FunctionCoverage command, ordered by file and position.
+type FunctionCoverageResult struct {
+	Functions []FuncCoverage
+}
+
+// FuncCoverage is the test coverage of a function or method.
+type FuncCoverage struct {
+	// Name is the name of the function, F, or of the method, T.M.
+	Name string
+
+	// PkgPath is the path of the function's package.
+	PkgPath string
+
+	// Location is the location of the function's name.
+	Location protocol.Location
+
+	// Covered and Uncovered are the numbers of lines of the
+	// function's body with statements that were executed, and whose
+	// statements were all unexecuted.
+	Covered, Uncovered int
+}
+
+// UpdateTestsArgs specifies the functions and methods whose tests to
+// update.
+type UpdateTestsArgs struct {
+	// URI is a Go file, or the directory of a package.
+	URI protocol.DocumentURI
+
+	// Recursive reports whether to also update the tests of the
+	// packages in the subdirectories of the directory that are loaded
+	// in the workspace.
+	Recursive bool `json:"Recursive,omitempty"`
+
+	// Whether to resolve and return the edits.
+	ResolveEdits bool `json:"ResolveEdits,omitempty"`
+}
+
+// UpdateTestsResult summarizes the tests updated by the UpdateTests
+// command.
+type UpdateTestsResult struct {
+	// Edit holds the edits that update the tests, if ResolveEdits was
+	// set.
+	Edit *protocol.WorkspaceEdit `json:"Edit,omitempty"`
+
+	// Updated is the number of tests updated.
+	Updated int
+
+	// Attention lists the parts of the updated tests that need
+	// manual attention.
+	Attention []TestAttention
+}
+
+// A TestAttention identifies a test case, or a statement, of a test
+// updated by the UpdateTests command that needs manual attention.
+type TestAttention struct {
+	Location protocol.Location
+	Test     string // name of the test, e.g. TestFoo
+	Reason   string // e.g. "field x changed type from int to string"
+}
+
+// AffectedTestsArgs specifies the changes whose tests the
+// AffectedTests command reports.
+type AffectedTestsArgs struct {
+	// URI is a file or directory of the view.
+	URI protocol.DocumentURI
+
+	// Files are the changed files, such as those of a commit. If
+	// empty, the changes are the unsaved changes of the open files.
+	Files []protocol.DocumentURI `json:"Files,omitempty"`
+
+	// Run reports whether to run the affected tests.
+	Run bool `json:"Run,omitempty"`
+}
+
+// AffectedTestsResult holds the tests reported by the AffectedTests
+// command.
+type AffectedTestsResult struct {
+	// Packages holds the affected tests of each package, ordered by
+	// package path.
+	Packages []AffectedPackage
+
+	// Failed is the number of packages whose affected tests failed,
+	// if Run was set.
+	Failed int `json:"Failed,omitempty"`
+}
+
+// AffectedPackage holds the affected tests of a package.
+type AffectedPackage struct {
+	// PkgPath is the path of the package under test.
+	PkgPath string
+
+	// Dir is the directory of the package.
+	Dir protocol.DocumentURI
+
+	// Tests are the names of the affected Test, Fuzz, and Example
+	// functions of the package and of its external test package,
+	// in order. Benchmarks are not reported.
+	Tests []string
+
+	// All reports whether all the tests of the package are
+	// affected, in which case the package need not be restricted
+	// to Tests when testing it.
+	All bool `json:"All,omitempty"`
+}
+
+// StreamTestsArgs specifies the tests of the StreamTests command.
+type StreamTestsArgs struct {
+	// URI is a Go file, or the directory of a package.
+	URI protocol.DocumentURI
+
+	// Tests are the names of the tests to run, e.g. TestFoo, or of
+	// subtests, e.g. TestFoo/case_name. If empty, all tests of the
+	// package are run.
+	Tests []string `json:"Tests,omitempty"`
+
+	// Token is the progress token with which to report each event.
+	Token protocol.ProgressToken
+}
+
+// StreamTestsResult summarizes the results of the StreamTests command.
+type StreamTestsResult struct {
+	// Passed, Failed, and Skipped are the numbers of tests and
+	// subtests that passed, failed, and were skipped.
+	Passed, Failed, Skipped int
+
+	// Packages are the results of the tested packages, in the order
+	// in which they started.
+	Packages []PackageTestResult `json:"Packages,omitempty"`
+}
+
+// A PackageTestResult is the result of the tests of a package, as
+// correlated from the events of "go test -json".
+type PackageTestResult struct {
+	// Package is the import path of the package.
+	Package string
+
+	// Action is the outcome of the package: "pass", "fail", or
+	// "skip" if it has no tests. A package whose test binary could
+	// not be built, or exited without reporting its outcome, such as
+	// after a panic, fails.
+	Action string
+
+	// Elapsed is the duration of the tests of the package, in seconds.
+	Elapsed float64 `json:",omitempty"`
+
+	// Output is the output of the package that no test produced,
+	// such as build errors, without the framing lines of go test.
+	Output string `json:",omitempty"`
+
+	// Tests are the results of the top-level tests of the package,
+	// in the order in which they started.
+	Tests []TestResult `json:",omitempty"`
+}
+
+// A TestResult is the result of a test or subtest, as correlated from
+// the events of "go test -json".
+type TestResult struct {
+	// Name is the full name of the test, e.g. TestFoo/case_name.
+	Name string
+
+	// Action is the outcome of the test: "pass", "fail", or "skip".
+	// A test that did not finish, such as one still running when
+	// another test panicked or the binary timed out, fails.
+	Action string
+
+	// Elapsed is the duration of the test, in seconds.
+	Elapsed float64 `json:",omitempty"`
+
+	// Output is the output of the test, such as its logs and the
+	// stack of a panic, without the framing lines of go test, such
+	// as "=== RUN" and "--- PASS", even if it was interleaved with
+	// the output of parallel tests.
+	Output string `json:",omitempty"`
+
+	// Subtests are the results of the subtests of the test, in the
+	// order in which they started.
+	Subtests []TestResult `json:",omitempty"`
+}
+
+// DebugTestArgs specifies the test of the DebugTest command.
+type DebugTestArgs struct {
+	// URI is the _test.go file that declares the test.
+	URI protocol.DocumentURI
+
+	// Test is the name of the test, e.g. TestFoo, or of a subtest,
+	// e.g. TestFoo/case_name.
+	Test string
+
+	// Location, if Test is empty, selects the test instead: the
+	// subtest named by the string literal at the location, such as
+	// the name argument of a t.Run call, or else the test function
+	// whose declaration encloses it. URI is then ignored.
+	Location protocol.Location
+}
+
+// DebugTestResult is the configuration of a debug session of a test,
+// as returned by the DebugTest command.
+type DebugTestResult struct {
+	// PackagePath is the import path of the package under test,
+	// and Dir is its directory, in which the test runs.
+	PackagePath string
+	Dir         string
+
+	// Test is the name of the top-level test, e.g. TestFoo.
+	Test string
+
+	// Run is the filter that selects the test or subtest, as for the
+	// -run flag of go test, e.g. ^TestFoo$/^case_name$, and Args are
+	// the arguments of the test binary that apply it.
+	Run  string
+	Args []string
+
+	// BuildFlags are the build flags of the workspace, with which to
+	// build the test binary, and the -tags flag that selects the
+	// test's file if it is an integration test.
+	BuildFlags []string `json:"BuildFlags,omitempty"`
+
+	// Env holds the environment variables, of the form NAME=value,
+	// that the workspace adds to those of the go command, with which
+	// to build and run the test binary.
+	Env []string `json:"Env,omitempty"`
+}
+
+// A TestEvent is an event of a test run, as reported by "go test
+// -json"; see "go doc test2json".
+type TestEvent struct {
+	// Time is the time of the event, in RFC3339 format.
+	Time string `json:",omitempty"`
+
+	// Action is the kind of event, such as "run", "output", "pass",
+	// "fail", or "skip".
+	Action string
+
+	// Package and Test are the package and the (sub)test to which
+	// the event applies. Test is empty for events of the package as
+	// a whole.
+	Package string `json:",omitempty"`
+	Test    string `json:",omitempty"`
+
+	// ImportPath identifies the package, e.g. "p [p.test]", of the
+	// "build-output" and "build-fail" events reported while building
+	// its test binary, before the events of the package, whose
+	// "fail" event names it as FailedBuild.
+	ImportPath  string `json:",omitempty"`
+	FailedBuild string `json:",omitempty"`
+
+	// Elapsed is the duration of a test or package, in seconds,
+	// for "pass" and "fail" events.
+	Elapsed float64 `json:",omitempty"`
+
+	// Output is the output text of an "output" event.
+	Output string `json:",omitempty"`
+
+	// OutputType classifies the output of an "output" event, such as
+	// "frame" for the framing lines of go test, e.g. "=== RUN", or
+	// "error" for test failures. Older versions of Go omit it.
+	OutputType string `json:",omitempty"`
+}
+
+// AddStringMethodArgs specifies a type for which to generate a String
+// method.
+type AddStringMethodArgs struct {
+	// Location is a range within a constant declaration of the type.
+	Location protocol.Location
+
+	// Whether to resolve and return the edits.
+	ResolveEdits bool
+}
+
+// UnmarshalJSON also accepts a bare [protocol.Location], the
+// argument of the AddStringMethod command before gopls v0.19.
+func (a *AddStringMethodArgs) UnmarshalJSON(b []byte) error {
+	var loc protocol.Location
+	if err := json.Unmarshal(b, &loc); err == nil && loc.URI != "" {
+		*a = AddStringMethodArgs{Location: loc}
+		return nil
+	}
+	type args AddStringMethodArgs // without the UnmarshalJSON method
+	return json.Unmarshal(b, (*args)(a))
+}
+
+// ChangeReceiversArgs specifies a change to the receivers of the
+// methods of a type.
+type ChangeReceiversArgs struct {
+	// Location is a range within the receiver of a method of the type.
+	Location protocol.Location
+	// Pointer reports whether to change the receivers to pointers
+	// (*T), rather than values (T).
+	Pointer bool
+}
+
+// AddContextParamArgs specifies a function to which to add a
+// context.Context parameter.
+type AddContextParamArgs struct {
+	// Location is a range within the signature of the function.
+	Location protocol.Location
+	// Callers reports whether to add the parameter to callers that
+	// have no context.Context in scope too, recursively, rather than
+	// passing context.Background().
+	Callers bool
+}
+
 // ChangeSignatureArgs specifies a "change signature" refactoring to perform.
 //
 // The new signature is expressed via the NewParams and NewResults fields. The
@@ -724,6 +1523,12 @@ type Module struct {
 type TestFile struct {
 	URI protocol.DocumentURI // a *_test.go file
 
+	// BuildConstraint is the expression of the //go:build constraint
+	// of the file, such as "integration && linux", or empty if none.
+	// Clients may need to pass the corresponding -tags flag to run
+	// the tests of a file whose constraint is not satisfied by default.
+	BuildConstraint string `json:"BuildConstraint,omitempty"`
+
 	// Tests is the list of tests in File, including subtests.
 	//
 	// The set of subtests is not exhaustive as in general they may be
@@ -752,6 +1557,13 @@ type TestCase struct {
 	// e.g. TestToplevel/Inner.Subtest → -run=^TestToplevel$/^Inner\.Subtest$
 	Name string
 
+	// Flags are the flags of "go test" that run only this test,
+	// computed from Name as described above: a -run flag, or for a
+	// benchmark, a -bench flag and the flag -run=^$ that excludes the
+	// tests. For a fuzz test, the -run flag runs only its seed corpus;
+	// to fuzz, clients should use the same pattern with -fuzz.
+	Flags []string `json:"Flags,omitempty"`
+
 	// Loc is the filename and range enclosing this test function
 	// or the subtest. This is used to place the gutter marker
 	// and group tests based on location.
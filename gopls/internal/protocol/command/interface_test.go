@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/protocol/command/gen"
 	"golang.org/x/tools/internal/testenv"
 )
@@ -31,3 +33,38 @@ func TestGenerated(t *testing.T) {
 		t.Errorf("command_gen.go is stale -- regenerate (-generated +on disk)\n%s", diff)
 	}
 }
+
+// TestLegacyLocationArgs checks that the commands whose argument was a
+// bare location before gopls v0.19 still accept it.
+func TestLegacyLocationArgs(t *testing.T) {
+	loc := protocol.Location{
+		URI:   "file:///a/a.go",
+		Range: protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 2, Character: 6}},
+	}
+	legacy := command.MustMarshalArgs(loc)
+
+	var test command.AddTestArgs
+	if err := command.UnmarshalArgs(legacy, &test); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(command.AddTestArgs{Location: loc}, test); diff != "" {
+		t.Errorf("AddTestArgs mismatch (-want +got):\n%s", diff)
+	}
+	var str command.AddStringMethodArgs
+	if err := command.UnmarshalArgs(legacy, &str); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(command.AddStringMethodArgs{Location: loc}, str); diff != "" {
+		t.Errorf("AddStringMethodArgs mismatch (-want +got):\n%s", diff)
+	}
+
+	// The current form of the arguments is unaffected.
+	want := command.AddTestArgs{Location: loc, Integration: true, ResolveEdits: true}
+	test = command.AddTestArgs{}
+	if err := command.UnmarshalArgs(command.MustMarshalArgs(want), &test); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, test); diff != "" {
+		t.Errorf("AddTestArgs mismatch (-want +got):\n%s", diff)
+	}
+}
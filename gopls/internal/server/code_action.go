@@ -67,7 +67,9 @@ func (s *server) CodeAction(ctx context.Context, params *protocol.CodeActionPara
 	codeActionKinds := make(map[protocol.CodeActionKind]bool)
 	if len(params.Context.Only) > 0 {
 		for _, kind := range params.Context.Only { // kind may be "" (=> all)
-			codeActionKinds[kind] = true
+			// Legacy kinds such as "source.addTest" select their
+			// "source.generate" successors.
+			codeActionKinds[settings.CanonicalCodeActionKind(kind)] = true
 		}
 	} else {
 		// No explicit kind specified.
@@ -189,6 +191,7 @@ func (s *server) CodeAction(ctx context.Context, params *protocol.CodeActionPara
 			actions = slices.DeleteFunc(actions, func(a protocol.CodeAction) bool {
 				switch a.Kind {
 				case settings.GoTest,
+					settings.GoCopyTestCommand,
 					settings.GoDoc,
 					settings.GoFreeSymbols,
 					settings.GoAssembly,
@@ -249,8 +252,12 @@ func (s *server) ResolveCodeAction(ctx context.Context, ca *protocol.CodeAction)
 		if err != nil {
 			return nil, err
 		}
-		var ok bool
-		if ca.Edit, ok = edit.(*protocol.WorkspaceEdit); !ok {
+		switch edit := edit.(type) {
+		case *protocol.WorkspaceEdit:
+			ca.Edit = edit
+		case *command.AddTestResult:
+			ca.Edit = edit.Edit
+		default:
 			return nil, fmt.Errorf("unable to resolve code action %q", ca.Title)
 		}
 	}
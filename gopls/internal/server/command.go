@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go/build/constraint"
 	"io"
 	"log"
 	"os"
@@ -21,9 +22,11 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/mod/modfile"
 	"golang.org/x/telemetry/counter"
+	"golang.org/x/tools/cover"
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/gopls/internal/cache"
 	"golang.org/x/tools/gopls/internal/cache/metadata"
@@ -31,6 +34,7 @@ import (
 	"golang.org/x/tools/gopls/internal/debug"
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/golang"
+	"golang.org/x/tools/gopls/internal/label"
 	"golang.org/x/tools/gopls/internal/progress"
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/gopls/internal/protocol/command"
@@ -47,7 +51,7 @@ import (
 )
 
 func (s *server) ExecuteCommand(ctx context.Context, params *protocol.ExecuteCommandParams) (any, error) {
-	ctx, done := event.Start(ctx, "lsp.Server.executeCommand")
+	ctx, done := event.Start(ctx, "lsp.Server.executeCommand", label.Command.Of(params.Command))
 	defer done()
 
 	// For test synchronization, always create a progress notification.
@@ -74,7 +78,19 @@ func (s *server) ExecuteCommand(ctx context.Context, params *protocol.ExecuteCom
 		s:      s,
 		params: params,
 	}
-	return command.Dispatch(ctx, params, handler)
+	res, err := command.Dispatch(ctx, params, handler)
+
+	// Record the outcome of the command in its span, for the command
+	// statistics of the debug page and gopls stats.
+	status := CommandCompleted
+	switch {
+	case errors.Is(err, context.Canceled):
+		status = CommandCanceled
+	case err != nil:
+		status = CommandFailed
+	}
+	event.Label(ctx, label.CommandStatus.Of(status))
+	return res, err
 }
 
 type commandHandler struct {
@@ -241,8 +257,9 @@ func (h *commandHandler) Packages(ctx context.Context, args command.PackagesArgs
 				fileByPath := map[protocol.DocumentURI]*command.TestFile{}
 				for _, test := range tests.All() {
 					test := command.TestCase{
-						Name: test.Name,
-						Loc:  test.Location,
+						Name:  test.Name,
+						Flags: testFlags(test.Name),
+						Loc:   test.Location,
 					}
 
 					file, ok := fileByPath[test.Loc.URI]
@@ -250,6 +267,10 @@ func (h *commandHandler) Packages(ctx context.Context, args command.PackagesArgs
 						f := command.TestFile{
 							URI: test.Loc.URI,
 						}
+						f.BuildConstraint, err = buildConstraint(ctx, snapshot, test.Loc.URI)
+						if err != nil {
+							return err
+						}
 						i := len(pkg.TestFiles)
 						pkg.TestFiles = append(pkg.TestFiles, f)
 						file = &pkg.TestFiles[i]
@@ -265,6 +286,40 @@ func (h *commandHandler) Packages(ctx context.Context, args command.PackagesArgs
 	return result, err
 }
 
+// testFlags returns the flags of "go test" that run only the named
+// test or subtest; see [command.TestCase].
+func testFlags(name string) []string {
+	pattern := golang.TestRunPattern(name)
+	if strings.HasPrefix(name, "Benchmark") {
+		return []string{"-run=^$", "-bench=" + pattern}
+	}
+	return []string{"-run=" + pattern}
+}
+
+// buildConstraint returns the expression of the //go:build constraint
+// of the specified Go file, or "" if it has none.
+func buildConstraint(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI) (string, error) {
+	fh, err := snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return "", err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+	if err != nil {
+		return "", err
+	}
+	for _, cg := range pgf.File.Comments {
+		if cg.Pos() > pgf.File.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if constraint.IsGoBuild(c.Text) {
+				return strings.TrimSpace(strings.TrimPrefix(c.Text, "//go:build")), nil
+			}
+		}
+	}
+	return "", nil
+}
+
 func (h *commandHandler) MaybePromptForTelemetry(ctx context.Context) error {
 	// if the server's TelemetryPrompt is true, it's likely the server already
 	// handled prompting for it. Don't try to prompt again.
@@ -289,24 +344,413 @@ func (*commandHandler) AddTelemetryCounters(_ context.Context, args command.AddT
 	return nil
 }
 
-func (c *commandHandler) AddTest(ctx context.Context, loc protocol.Location) (*protocol.WorkspaceEdit, error) {
-	var result *protocol.WorkspaceEdit
+func (c *commandHandler) AddTest(ctx context.Context, args command.AddTestArgs) (*command.AddTestResult, error) {
+	var result *command.AddTestResult
 	err := c.run(ctx, commandConfig{
-		forURI: loc.URI,
+		forURI: args.Location.URI,
 	}, func(ctx context.Context, deps commandDeps) error {
 		if deps.snapshot.FileKind(deps.fh) != file.Go {
 			return fmt.Errorf("can't add test for non-Go file")
 		}
-		docedits, err := golang.AddTestForFunc(ctx, deps.snapshot, loc)
+		docedits, err := golang.AddTestForFunc(ctx, deps.snapshot, args.Location, args.Integration, golang.TestOptions{
+			ReceiverForms:   args.ReceiverForms,
+			FieldAssertions: args.FieldAssertions,
+		})
 		if err != nil {
 			return err
 		}
-		return applyChanges(ctx, c.s.client, docedits)
+		if args.Debug {
+			// The tests added for the methods of a type have no
+			// single configuration, so the edits are still applied.
+			debug, err := golang.AddedTestDebugConfig(ctx, deps.snapshot, args.Location, args.Integration)
+			if err != nil {
+				event.Error(ctx, "computing the debug configuration of the new test", err)
+			} else {
+				result = &command.AddTestResult{Debug: debug}
+			}
+		}
+		if args.ResolveEdits {
+			if result == nil {
+				result = new(command.AddTestResult)
+			}
+			result.Edit = protocol.NewWorkspaceEdit(docedits...)
+			return nil
+		}
+		return c.s.applyChanges(ctx, docedits)
 	})
 	// TODO(hxjiang): move the cursor to the new test once edits applied.
 	return result, err
 }
 
+func (c *commandHandler) AddTests(ctx context.Context, args command.AddTestsArgs) (command.AddTestsResult, error) {
+	var result command.AddTestsResult
+	err := c.run(ctx, commandConfig{
+		progress: "Adding tests",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		// The changes for each file are sent as a partial result,
+		// and applied, as soon as they are computed, so that the
+		// work done is not lost if the command is canceled.
+		changes, res, err := golang.AddTests(ctx, deps.snapshot, args, func(done, total int, changes []protocol.DocumentChange) error {
+			deps.work.Report(ctx, fmt.Sprintf("%d/%d files", done, total), 100*float64(done)/float64(total))
+			if len(changes) == 0 {
+				return nil
+			}
+			if args.Token != nil {
+				if err := c.s.client.Progress(ctx, &protocol.ProgressParams{
+					Token: args.Token,
+					Value: protocol.NewWorkspaceEdit(changes...),
+				}); err != nil {
+					return err
+				}
+			}
+			if args.ResolveEdits {
+				return nil
+			}
+			return c.s.applyChanges(ctx, changes)
+		})
+		if err != nil {
+			return err
+		}
+		result = res
+		if args.ResolveEdits {
+			result.Edit = protocol.NewWorkspaceEdit(changes...)
+			return nil
+		}
+		if len(changes) == 0 {
+			showMessage(ctx, c.s.client, protocol.Info, "All functions already have tests.")
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (c *commandHandler) UpdateTests(ctx context.Context, args command.UpdateTestsArgs) (command.UpdateTestsResult, error) {
+	var result command.UpdateTestsResult
+	err := c.run(ctx, commandConfig{
+		progress: "Updating tests",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, res, err := golang.UpdateTests(ctx, deps.snapshot, args)
+		if err != nil {
+			return err
+		}
+		result = res
+		if args.ResolveEdits {
+			result.Edit = protocol.NewWorkspaceEdit(changes...)
+			return nil
+		}
+		if len(changes) == 0 {
+			showMessage(ctx, c.s.client, protocol.Info, "All tests are up to date.")
+			return nil
+		}
+		if err := c.s.applyChanges(ctx, changes); err != nil {
+			return err
+		}
+		if len(res.Attention) > 0 {
+			var msg strings.Builder
+			fmt.Fprintf(&msg, "Updated %d tests; %d places need attention:", res.Updated, len(res.Attention))
+			for _, a := range res.Attention {
+				fmt.Fprintf(&msg, "\n%s:%d: %s: %s", a.Location.URI.Path(), a.Location.Range.Start.Line+1, a.Test, a.Reason)
+			}
+			showMessage(ctx, c.s.client, protocol.Warning, msg.String())
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (c *commandHandler) MissingTests(ctx context.Context, args command.URIArg) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		web, err := c.s.getWeb()
+		if err != nil {
+			return err
+		}
+		url := web.missingTestsURL(deps.snapshot.View().ID())
+		openClientBrowser(ctx, c.s.client, "Missing tests", url, c.s.Options())
+		return nil
+	})
+}
+
+func (c *commandHandler) BrowseCoverage(ctx context.Context, args command.URIArg) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		if deps.snapshot.Coverage(args.URI) == nil {
+			return fmt.Errorf("no coverage of %s: load a coverage profile or run its tests with coverage", args.URI.Path())
+		}
+		web, err := c.s.getWeb()
+		if err != nil {
+			return err
+		}
+		url := web.coverageURL(deps.snapshot.View().ID(), args.URI)
+		openClientBrowser(ctx, c.s.client, "Test coverage", url, c.s.Options())
+		return nil
+	})
+}
+
+func (c *commandHandler) AffectedTests(ctx context.Context, args command.AffectedTestsArgs) (command.AffectedTestsResult, error) {
+	var result command.AffectedTestsResult
+	err := c.run(ctx, commandConfig{
+		progress:    "Finding affected tests",
+		requireSave: args.Run, // go test honors overlays, but tests themselves cannot
+		forURI:      args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		pkgs, err := golang.AffectedTests(ctx, deps.snapshot, args.Files)
+		if err != nil {
+			return err
+		}
+		result.Packages = pkgs
+		if !args.Run {
+			return nil
+		}
+		jsonrpc2.Async(ctx) // don't block RPCs behind this command, since it can take a while
+		result.Failed, err = c.runAffectedTests(ctx, deps.snapshot, deps.work, pkgs)
+		return err
+	})
+	return result, err
+}
+
+// runAffectedTests runs the affected tests of each package, and
+// returns the number of packages whose tests failed.
+func (c *commandHandler) runAffectedTests(ctx context.Context, snapshot *cache.Snapshot, work *progress.WorkDone, pkgs []command.AffectedPackage) (int, error) {
+	if len(pkgs) == 0 {
+		showMessage(ctx, c.s.client, protocol.Info, "no tests are affected")
+		return 0, nil
+	}
+
+	buf := &bytes.Buffer{}
+	ew := progress.NewEventWriter(ctx, "test")
+	out := io.MultiWriter(ew, progress.NewWorkDoneWriter(ctx, work), buf)
+
+	// Run `go test -run '^(A|B)$' pkg` on each package.
+	var failed int
+	for _, pkg := range pkgs {
+		args := []string{pkg.PkgPath, "-count=1"}
+		if !pkg.All {
+			names := make([]string, len(pkg.Tests))
+			for i, name := range pkg.Tests {
+				names[i] = regexp.QuoteMeta(name)
+			}
+			args = append(args, "-run=^("+strings.Join(names, "|")+")$")
+		}
+		inv, cleanupInvocation, err := snapshot.GoCommandInvocation(cache.NoNetwork, pkg.Dir.Path(), "test", args)
+		if err != nil {
+			return failed, err
+		}
+		defer cleanupInvocation()
+		if err := snapshot.View().GoCommandRunner().RunPiped(ctx, *inv, out, out); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return failed, err
+			}
+			failed++
+		}
+	}
+
+	message := fmt.Sprintf("the affected tests of all %d packages passed", len(pkgs))
+	if failed > 0 {
+		message = fmt.Sprintf("the affected tests of %d / %d packages failed\n%s", failed, len(pkgs), buf)
+	}
+	showMessage(ctx, c.s.client, protocol.Info, message)
+	return failed, nil
+}
+
+func (c *commandHandler) AddStringMethod(ctx context.Context, args command.AddStringMethodArgs) (*protocol.WorkspaceEdit, error) {
+	var result *protocol.WorkspaceEdit
+	err := c.run(ctx, commandConfig{
+		forURI: args.Location.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		if deps.snapshot.FileKind(deps.fh) != file.Go {
+			return fmt.Errorf("can't add String method for non-Go file")
+		}
+		changes, err := golang.AddStringMethod(ctx, deps.snapshot, args.Location)
+		if err != nil {
+			return err
+		}
+		if args.ResolveEdits {
+			result = protocol.NewWorkspaceEdit(changes...)
+			return nil
+		}
+		return c.s.applyChanges(ctx, changes)
+	})
+	return result, err
+}
+
+func (c *commandHandler) ChangeReceivers(ctx context.Context, args command.ChangeReceiversArgs) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.Location.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := golang.ChangeReceivers(ctx, deps.snapshot, args.Location, args.Pointer)
+		if err != nil {
+			return err
+		}
+		return c.s.applyChanges(ctx, changes)
+	})
+}
+
+func (c *commandHandler) Unexport(ctx context.Context, loc protocol.Location) error {
+	return c.run(ctx, commandConfig{
+		forURI: loc.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := golang.Unexport(ctx, deps.snapshot, loc)
+		if err != nil {
+			return err
+		}
+		return c.s.applyChanges(ctx, changes)
+	})
+}
+
+func (c *commandHandler) ConvertFuncToMethod(ctx context.Context, loc protocol.Location) error {
+	return c.run(ctx, commandConfig{
+		forURI: loc.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := golang.ConvertFuncToMethod(ctx, deps.snapshot, loc)
+		if err != nil {
+			return err
+		}
+		return c.s.applyChanges(ctx, changes)
+	})
+}
+
+func (c *commandHandler) ConvertMethodToFunc(ctx context.Context, loc protocol.Location) error {
+	return c.run(ctx, commandConfig{
+		forURI: loc.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := golang.ConvertMethodToFunc(ctx, deps.snapshot, loc)
+		if err != nil {
+			return err
+		}
+		return c.s.applyChanges(ctx, changes)
+	})
+}
+
+func (c *commandHandler) AddContextParam(ctx context.Context, args command.AddContextParamArgs) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.Location.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := golang.AddContextParam(ctx, deps.snapshot, args.Location, args.Callers)
+		if err != nil {
+			return err
+		}
+		return c.s.applyChanges(ctx, changes)
+	})
+}
+
+func (c *commandHandler) GoToTestOrSubject(ctx context.Context, loc protocol.Location) ([]protocol.Location, error) {
+	var locs []protocol.Location
+	err := c.run(ctx, commandConfig{
+		forURI: loc.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		var err error
+		locs, err = golang.TestCounterparts(ctx, deps.snapshot, loc)
+		if err != nil {
+			return err
+		}
+		openClientEditor(ctx, c.s.client, locs[0], c.s.Options())
+		return nil
+	})
+	return locs, err
+}
+
+func (c *commandHandler) DebugTest(ctx context.Context, args command.DebugTestArgs) (command.DebugTestResult, error) {
+	var result command.DebugTestResult
+	uri := args.URI
+	if args.Test == "" && args.Location.URI != "" {
+		uri = args.Location.URI
+	}
+	err := c.run(ctx, commandConfig{
+		forURI: uri,
+	}, func(ctx context.Context, deps commandDeps) error {
+		var err error
+		result, err = golang.DebugTest(ctx, deps.snapshot, args)
+		return err
+	})
+	return result, err
+}
+
+func (c *commandHandler) CopyTestCommand(ctx context.Context, loc protocol.Location) (string, error) {
+	var result string
+	err := c.run(ctx, commandConfig{
+		forURI: loc.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		var err error
+		result, err = golang.SubtestCommand(ctx, deps.snapshot, loc)
+		if err != nil {
+			return err
+		}
+		showMessage(ctx, c.s.client, protocol.Info, result)
+		return nil
+	})
+	return result, err
+}
+
+func (c *commandHandler) LoadCoverage(ctx context.Context, args command.URIArg) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		content, err := deps.fh.Content()
+		if err != nil {
+			return err
+		}
+		profiles, err := cover.ParseProfilesFromReader(bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("parsing coverage profile: %v", err)
+		}
+		n, err := c.recordCoverage(ctx, deps.snapshot, profiles)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("coverage profile %s names no files in the workspace", args.URI.Path())
+		}
+		return nil
+	})
+}
+
+// recordCoverage records the coverage of the workspace files of the
+// profiles in the view of the snapshot, and returns the number of
+// files.
+func (c *commandHandler) recordCoverage(ctx context.Context, snapshot *cache.Snapshot, profiles []*cover.Profile) (int, error) {
+	coverage := snapshot.CoverageOfProfiles(profiles)
+	if len(coverage) == 0 {
+		return 0, nil
+	}
+	_, release, err := c.s.session.InvalidateView(ctx, snapshot.View(), cache.StateChange{
+		Coverage: coverage,
+	})
+	if err != nil {
+		return 0, err
+	}
+	release()
+	return len(coverage), nil
+}
+
+func (c *commandHandler) DocumentCoverage(ctx context.Context, args protocol.TextDocumentIdentifier) (command.DocumentCoverageResult, error) {
+	var result command.DocumentCoverageResult
+	err := c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		res, err := golang.DocumentCoverage(deps.snapshot, deps.fh)
+		result = res
+		return err
+	})
+	return result, err
+}
+
+func (c *commandHandler) FunctionCoverage(ctx context.Context, args command.FunctionCoverageArgs) (command.FunctionCoverageResult, error) {
+	var result command.FunctionCoverageResult
+	err := c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		res, err := golang.FunctionCoverage(ctx, deps.snapshot, args)
+		result = res
+		return err
+	})
+	return result, err
+}
+
 // commandConfig configures common command set-up and execution.
 type commandConfig struct {
 	requireSave   bool                           // whether all files must be saved for the command to work
@@ -441,7 +885,31 @@ func (c *commandHandler) ApplyFix(ctx context.Context, args command.ApplyFixArgs
 			result = wsedit
 			return nil
 		}
-		return applyChanges(ctx, c.s.client, changes)
+		return c.s.applyChanges(ctx, changes)
+	})
+	return result, err
+}
+
+func (c *commandHandler) ApplyAnalyzerFixes(ctx context.Context, args command.ApplyAnalyzerFixesArgs) (command.ApplyAnalyzerFixesResult, error) {
+	var result command.ApplyAnalyzerFixesResult
+	err := c.run(ctx, commandConfig{
+		progress: "Applying fixes",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, res, err := golang.ApplyAnalyzerFixes(ctx, deps.snapshot, args)
+		if err != nil {
+			return err
+		}
+		result = res
+		if args.ResolveEdits {
+			result.Edit = protocol.NewWorkspaceEdit(changes...)
+			return nil
+		}
+		if len(changes) == 0 {
+			showMessage(ctx, c.s.client, protocol.Info, "No fixes to apply.")
+			return nil
+		}
+		return c.s.applyChanges(ctx, changes)
 	})
 	return result, err
 }
@@ -662,7 +1130,7 @@ func (c *commandHandler) RemoveDependency(ctx context.Context, args command.Remo
 		if err != nil {
 			return err
 		}
-		return applyChanges(ctx, c.s.client, []protocol.DocumentChange{protocol.DocumentChangeEdit(deps.fh, edits)})
+		return c.s.applyChanges(ctx, []protocol.DocumentChange{protocol.DocumentChangeEdit(deps.fh, edits)})
 	})
 }
 
@@ -735,27 +1203,45 @@ func (c *commandHandler) RunTests(ctx context.Context, args command.RunTestsArgs
 		forURI:      args.URI,
 	}, func(ctx context.Context, deps commandDeps) error {
 		jsonrpc2.Async(ctx) // don't block RPCs behind this command, since it can take a while
-		return c.runTests(ctx, deps.snapshot, deps.work, args.URI, args.Tests, args.Benchmarks)
+		return c.runTests(ctx, deps.snapshot, deps.work, args.URI, args.Tests, args.Benchmarks, args.Coverage)
 	})
 }
 
-func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot, work *progress.WorkDone, uri protocol.DocumentURI, tests, benchmarks []string) error {
+func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot, work *progress.WorkDone, uri protocol.DocumentURI, tests, benchmarks []string, coverage bool) error {
 	// TODO: fix the error reporting when this runs async.
 	meta, err := golang.NarrowestMetadataForFile(ctx, snapshot, uri)
 	if err != nil {
 		return err
 	}
 	pkgPath := string(meta.ForTest)
+	if pkgPath == "" {
+		pkgPath = string(meta.PkgPath) // uri is not a test file
+	}
 
 	// create output
 	buf := &bytes.Buffer{}
 	ew := progress.NewEventWriter(ctx, "test")
 	out := io.MultiWriter(ew, progress.NewWorkDoneWriter(ctx, work), buf)
 
-	// Run `go test -run Func` on each test.
-	var failedTests int
+	// Run `go test -run Func` on each test, recording its coverage
+	// in a profile if requested.
+	var (
+		failedTests int
+		profiles    []*cover.Profile
+	)
 	for _, funcName := range tests {
-		args := []string{pkgPath, "-v", "-count=1", fmt.Sprintf("-run=^%s$", regexp.QuoteMeta(funcName))}
+		args := []string{pkgPath, "-v", "-count=1", "-run=" + golang.TestRunPattern(funcName)}
+		var profile string
+		if coverage {
+			f, err := os.CreateTemp("", "gopls-coverage-*.out")
+			if err != nil {
+				return err
+			}
+			f.Close()
+			profile = f.Name()
+			defer os.Remove(profile)
+			args = append(args, "-coverprofile="+profile)
+		}
 		inv, cleanupInvocation, err := snapshot.GoCommandInvocation(cache.NoNetwork, uri.DirPath(), "test", args)
 		if err != nil {
 			return err
@@ -767,6 +1253,17 @@ func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot,
 			}
 			failedTests++
 		}
+		if profile != "" {
+			// A test that failed may still have recorded coverage.
+			if ps, err := cover.ParseProfiles(profile); err == nil {
+				profiles = append(profiles, ps...)
+			}
+		}
+	}
+	if len(profiles) > 0 {
+		if _, err := c.recordCoverage(ctx, snapshot, profiles); err != nil {
+			return err
+		}
 	}
 
 	// Run `go test -run=^$ -bench Func` on each test.
@@ -817,6 +1314,234 @@ func (c *commandHandler) runTests(ctx context.Context, snapshot *cache.Snapshot,
 	return nil
 }
 
+func (c *commandHandler) RunBenchmark(ctx context.Context, args command.RunBenchmarkArgs) (command.RunBenchmarkResult, error) {
+	var result command.RunBenchmarkResult
+	err := c.run(ctx, commandConfig{
+		progress:    "Running go test -bench", // (asynchronous)
+		requireSave: true,                     // go test honors overlays, but tests themselves cannot
+		forURI:      args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		jsonrpc2.Async(ctx) // don't block RPCs behind this command, since it can take a while
+		meta, err := golang.NarrowestMetadataForFile(ctx, deps.snapshot, args.URI)
+		if err != nil {
+			return err
+		}
+		pkgPath := meta.ForTest
+		if pkgPath == "" {
+			pkgPath = meta.PkgPath // uri is not a test file
+		}
+
+		buf := &bytes.Buffer{}
+		ew := progress.NewEventWriter(ctx, "test")
+		out := io.MultiWriter(ew, progress.NewWorkDoneWriter(ctx, deps.work), buf)
+		inv, cleanupInvocation, err := deps.snapshot.GoCommandInvocation(cache.NoNetwork, args.URI.DirPath(), "test", golang.BenchmarkArgs(pkgPath, args.Benchmark))
+		if err != nil {
+			return err
+		}
+		defer cleanupInvocation()
+		if err := deps.snapshot.View().GoCommandRunner().RunPiped(ctx, *inv, out, out); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			return fmt.Errorf("%s failed: %v\n%s", args.Benchmark, err, buf)
+		}
+		last := golang.ParseBenchmarks(buf.Bytes(), args.Benchmark)
+		if last == nil {
+			return fmt.Errorf("%s reported no measurements", args.Benchmark)
+		}
+
+		// Record the result, keeping the last one for comparison.
+		history := &cache.BenchmarkHistory{Last: last}
+		if prev := deps.snapshot.Benchmark(pkgPath, args.Benchmark); prev != nil {
+			history.Previous = prev.Last
+		}
+		_, release, err := c.s.session.InvalidateView(ctx, deps.snapshot.View(), cache.StateChange{
+			Benchmarks: map[string]*cache.BenchmarkHistory{
+				cache.BenchmarkKey(pkgPath, args.Benchmark): history,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		release()
+		if c.s.Options().CodeLensRefreshSupported {
+			// Update the summary shown by the benchmark's code lens.
+			if err := c.s.client.CodeLensRefresh(ctx); err != nil {
+				event.Error(ctx, "refreshing code lenses", err)
+			}
+		}
+
+		result.Metrics = golang.CompareBenchmarks(history.Previous, history.Last)
+		showMessage(ctx, c.s.client, protocol.Info, golang.FormatBenchmarkMetrics(result.Metrics))
+		return nil
+	})
+	return result, err
+}
+
+func (c *commandHandler) StreamTests(ctx context.Context, args command.StreamTestsArgs) (command.StreamTestsResult, error) {
+	var result command.StreamTestsResult
+	err := c.run(ctx, commandConfig{
+		progress:    "Running go test -json",
+		requireSave: true, // go test honors overlays, but tests themselves cannot
+		forURI:      args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		jsonrpc2.Async(ctx) // don't block RPCs behind this command, since it can take a while
+		res, err := c.streamTests(ctx, deps.snapshot, deps.work, args)
+		result = res
+		return err
+	})
+	return result, err
+}
+
+func (c *commandHandler) streamTests(ctx context.Context, snapshot *cache.Snapshot, work *progress.WorkDone, args command.StreamTestsArgs) (command.StreamTestsResult, error) {
+	// The URI is either a Go file, whose package (or package under
+	// test) is tested, or a package directory.
+	dir, pkg := args.URI.Path(), "."
+	if strings.HasSuffix(string(args.URI), ".go") {
+		meta, err := golang.NarrowestMetadataForFile(ctx, snapshot, args.URI)
+		if err != nil {
+			return command.StreamTestsResult{}, err
+		}
+		dir, pkg = args.URI.DirPath(), string(meta.ForTest)
+		if pkg == "" {
+			pkg = string(meta.PkgPath) // uri is not a test file
+		}
+	}
+
+	// Tests are selected by a -run pattern, whose slash-separated
+	// elements match the levels of subtests. The top-level tests are
+	// selected by a single pattern, but each subtest requires its own
+	// go test invocation.
+	var runs, top []string
+	for _, name := range args.Tests {
+		if strings.Contains(name, "/") {
+			runs = append(runs, golang.TestRunPattern(name))
+		} else {
+			top = append(top, regexp.QuoteMeta(name))
+		}
+	}
+	if len(top) > 0 {
+		runs = append([]string{"^(" + strings.Join(top, "|") + ")$"}, runs...)
+	}
+	if len(runs) == 0 {
+		runs = []string{""} // all tests
+	}
+
+	w := &testEventWriter{
+		ctx:    ctx,
+		client: c.s.client,
+		token:  args.Token,
+		work:   work,
+	}
+	for _, run := range runs {
+		goArgs := []string{pkg, "-json", "-count=1"}
+		if run != "" {
+			goArgs = append(goArgs, "-run="+run)
+		}
+		inv, cleanupInvocation, err := snapshot.GoCommandInvocation(cache.NoNetwork, dir, "test", goArgs)
+		if err != nil {
+			return command.StreamTestsResult{}, err
+		}
+		defer cleanupInvocation()
+
+		var stderr bytes.Buffer
+		failed := w.failed
+		w.failed = false
+		runErr := snapshot.View().GoCommandRunner().RunPiped(ctx, *inv, w, &stderr)
+		w.flush()
+		if runErr != nil {
+			if errors.Is(runErr, context.Canceled) {
+				return command.StreamTestsResult{}, runErr
+			}
+			// go test fails when any test fails; that is a result,
+			// not an error. Otherwise, the tests could not be run.
+			if !w.failed {
+				return command.StreamTestsResult{}, fmt.Errorf("go test failed: %v\n%s", runErr, stderr.Bytes())
+			}
+		}
+		w.failed = w.failed || failed
+	}
+	w.result.Packages = w.results.Packages()
+	return w.result, nil
+}
+
+// A testEventWriter decodes the lines of "go test -json" output written
+// to it, sending each event to the client as a $/progress notification,
+// tallying the test results, and correlating the events into the
+// results of each package and test.
+type testEventWriter struct {
+	ctx    context.Context
+	client protocol.Client
+	token  protocol.ProgressToken
+	work   *progress.WorkDone
+
+	buf     []byte // incomplete last line
+	result  command.StreamTestsResult
+	results golang.TestResults
+	failed  bool // a test or package failed
+}
+
+func (w *testEventWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		line, rest, ok := bytes.Cut(w.buf, []byte("\n"))
+		if !ok {
+			break
+		}
+		w.buf = rest
+		w.event(line)
+	}
+	return len(p), nil
+}
+
+// flush reports the final line, if it lacks a newline.
+func (w *testEventWriter) flush() {
+	if len(w.buf) > 0 {
+		w.event(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *testEventWriter) event(line []byte) {
+	var ev command.TestEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		// Not an event, such as a message of the go command:
+		// report it as output.
+		ev = command.TestEvent{Action: "output", Output: string(line) + "\n"}
+	}
+	w.results.Add(ev)
+	switch ev.Action {
+	case "pass", "fail", "skip":
+		if ev.Test == "" {
+			w.failed = w.failed || ev.Action == "fail"
+			break
+		}
+		switch ev.Action {
+		case "pass":
+			w.result.Passed++
+		case "fail":
+			w.result.Failed++
+			w.failed = true
+		case "skip":
+			w.result.Skipped++
+		}
+		if w.work != nil {
+			w.work.Report(w.ctx, fmt.Sprintf("%d passed, %d failed, %d skipped", w.result.Passed, w.result.Failed, w.result.Skipped), 0)
+		}
+	case "build-fail":
+		w.failed = true
+	}
+	if w.token == nil {
+		return
+	}
+	if err := w.client.Progress(w.ctx, &protocol.ProgressParams{
+		Token: w.token,
+		Value: ev,
+	}); err != nil {
+		event.Error(w.ctx, "reporting test event", err)
+	}
+}
+
 func (c *commandHandler) Generate(ctx context.Context, args command.GenerateArgs) error {
 	title := "Running go generate ."
 	if args.Recursive {
@@ -833,7 +1558,11 @@ func (c *commandHandler) Generate(ctx context.Context, args command.GenerateArgs
 		if args.Recursive {
 			pattern = "./..."
 		}
-		inv, cleanupInvocation, err := deps.snapshot.GoCommandInvocation(cache.NetworkOK, args.Dir.Path(), "generate", []string{"-x", pattern})
+		genArgs := []string{"-x", pattern}
+		if args.Run != "" {
+			genArgs = []string{"-x", "-run=" + args.Run, pattern}
+		}
+		inv, cleanupInvocation, err := deps.snapshot.GoCommandInvocation(cache.NetworkOK, args.Dir.Path(), "generate", genArgs)
 		if err != nil {
 			return err
 		}
@@ -915,7 +1644,7 @@ func (s *server) runGoModUpdateCommands(ctx context.Context, snapshot *cache.Sna
 	if sumChange.Valid() {
 		changes = append(changes, sumChange)
 	}
-	return applyChanges(ctx, s.client, changes)
+	return s.applyChanges(ctx, changes)
 }
 
 // computeEditChange computes the edit change required to transform the
@@ -958,22 +1687,37 @@ func computeEditChange(ctx context.Context, snapshot *cache.Snapshot, uri protoc
 	return protocol.DocumentChangeEdit(fh, textedits), nil
 }
 
-func applyChanges(ctx context.Context, cli protocol.Client, changes []protocol.DocumentChange) error {
+// applyChanges applies the changes in the client.
+//
+// The changes of the edited files that the client reports are
+// diagnosed together, once it has reported them all (see
+// [server.deferDiagnosis]).
+func (s *server) applyChanges(ctx context.Context, changes []protocol.DocumentChange) error {
 	if len(changes) == 0 {
 		return nil
 	}
-	response, err := cli.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
+	uris := s.expectEdits(changes)
+	response, err := s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
 		Edit: *protocol.NewWorkspaceEdit(changes...),
 	})
+	if err == nil && !response.Applied {
+		err = fmt.Errorf("edits not applied because of %s", response.FailureReason)
+	}
 	if err != nil {
+		// The client may not report the changes of the files.
+		s.diagnoseDeferredChanges(ctx, uris)
 		return err
 	}
-	if !response.Applied {
-		return fmt.Errorf("edits not applied because of %s", response.FailureReason)
-	}
+	// Don't wait forever for a client that does not report them all.
+	time.AfterFunc(editReportTimeout, func() { s.diagnoseDeferredChanges(ctx, uris) })
 	return nil
 }
 
+// editReportTimeout is how long the server waits for the client to
+// report the changes of the files of an applied edit before it
+// diagnoses those already reported.
+const editReportTimeout = 1 * time.Second
+
 func runGoGetModule(invoke func(...string) (*bytes.Buffer, error), addRequire bool, args []string) error {
 	if addRequire {
 		if err := addModuleRequire(invoke, args); err != nil {
@@ -1112,7 +1856,7 @@ func (c *commandHandler) AddImport(ctx context.Context, args command.AddImportAr
 		if err != nil {
 			return fmt.Errorf("could not add import: %v", err)
 		}
-		return applyChanges(ctx, c.s.client, []protocol.DocumentChange{protocol.DocumentChangeEdit(deps.fh, edits)})
+		return c.s.applyChanges(ctx, []protocol.DocumentChange{protocol.DocumentChangeEdit(deps.fh, edits)})
 	})
 }
 
@@ -1125,7 +1869,33 @@ func (c *commandHandler) ExtractToNewFile(ctx context.Context, args protocol.Loc
 		if err != nil {
 			return err
 		}
-		return applyChanges(ctx, c.s.client, changes)
+		return c.s.applyChanges(ctx, changes)
+	})
+}
+
+func (c *commandHandler) SplitFile(ctx context.Context, args protocol.Location) error {
+	return c.run(ctx, commandConfig{
+		progress: "Split file",
+		forURI:   args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := golang.SplitFile(ctx, deps.snapshot, deps.fh)
+		if err != nil {
+			return err
+		}
+		return c.s.applyChanges(ctx, changes)
+	})
+}
+
+func (c *commandHandler) ExtractParamStruct(ctx context.Context, loc protocol.Location) error {
+	return c.run(ctx, commandConfig{
+		progress: "Extract parameters into struct",
+		forURI:   loc.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		changes, err := golang.ExtractParamStruct(ctx, deps.snapshot, loc)
+		if err != nil {
+			return err
+		}
+		return c.s.applyChanges(ctx, changes)
 	})
 }
 
@@ -1382,6 +2152,9 @@ func (c *commandHandler) WorkspaceStats(ctx context.Context) (command.WorkspaceS
 		}
 		res.Views = append(res.Views, vs)
 	}
+	if di := debug.GetInstance(ctx); di != nil {
+		res.Commands = di.CommandStats()
+	}
 	return res, nil
 }
 
@@ -1620,16 +2393,10 @@ func (c *commandHandler) ChangeSignature(ctx context.Context, args command.Chang
 			return err
 		}
 
-		// For now, gopls only supports parameter permutation or removal.
-		var perm []int
-		for _, newParam := range args.NewParams {
-			if newParam.NewField != "" {
-				return fmt.Errorf("adding new parameters is currently unsupported")
-			}
-			perm = append(perm, newParam.OldIndex)
-		}
-
-		docedits, err := golang.ChangeSignature(ctx, deps.snapshot, pkg, pgf, args.Location.Range, perm)
+		// For now, gopls only supports changes to parameters:
+		// any change to args.NewResults is rejected.
+		// TODO(rfindley): support args.NewResults.
+		docedits, err := golang.ChangeSignature(ctx, deps.snapshot, pkg, pgf, args.Location.Range, args.NewParams, args.NewResults)
 		if err != nil {
 			return err
 		}
@@ -1638,7 +2405,7 @@ func (c *commandHandler) ChangeSignature(ctx context.Context, args command.Chang
 			result = wsedit
 			return nil
 		}
-		return applyChanges(ctx, c.s.client, docedits)
+		return c.s.applyChanges(ctx, docedits)
 	})
 	return result, err
 }
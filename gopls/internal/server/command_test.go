@@ -0,0 +1,77 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+)
+
+// A progressClient records the $/progress notifications sent to it.
+// Its other methods must not be called.
+type progressClient struct {
+	protocol.Client
+	params []*protocol.ProgressParams
+}
+
+func (c *progressClient) Progress(_ context.Context, params *protocol.ProgressParams) error {
+	c.params = append(c.params, params)
+	return nil
+}
+
+func TestTestEventWriter(t *testing.T) {
+	// The output of go test -json, whose last line lacks a newline,
+	// and which includes a message of the go command.
+	const output = `{"Action":"start","Package":"example.com/a"}
+{"Action":"run","Package":"example.com/a","Test":"TestA"}
+{"Action":"output","Package":"example.com/a","Test":"TestA","Output":"--- PASS: TestA (0.00s)\n"}
+{"Action":"pass","Package":"example.com/a","Test":"TestA"}
+{"Action":"run","Package":"example.com/a","Test":"TestB"}
+{"Action":"fail","Package":"example.com/a","Test":"TestB","Elapsed":0.01}
+{"Action":"skip","Package":"example.com/a","Test":"TestC"}
+go: downloading example.com/b v1.0.0
+{"Action":"fail","Package":"example.com/a","Elapsed":0.02}`
+
+	client := new(progressClient)
+	w := &testEventWriter{
+		ctx:    context.Background(),
+		client: client,
+		token:  "tests",
+	}
+	// Write the output in chunks that split its lines.
+	for chunk := range slices.Chunk([]byte(output), 7) {
+		if n, err := w.Write(chunk); n != len(chunk) || err != nil {
+			t.Fatalf("Write(%q) = (%d, %v)", chunk, n, err)
+		}
+	}
+	w.flush()
+
+	if got := w.result; got.Passed != 1 || got.Failed != 1 || got.Skipped != 1 {
+		t.Errorf("got %d passed, %d failed, %d skipped, want 1, 1, 1", got.Passed, got.Failed, got.Skipped)
+	}
+	if !w.failed {
+		t.Errorf("failed = false, want true")
+	}
+
+	var actions []string
+	for _, params := range client.params {
+		if params.Token != "tests" {
+			t.Errorf("event sent with token %v, want tests", params.Token)
+		}
+		ev := params.Value.(command.TestEvent)
+		actions = append(actions, ev.Action)
+		if ev.Package == "" && ev.Output != "go: downloading example.com/b v1.0.0\n" {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	}
+	want := []string{"start", "run", "output", "pass", "run", "fail", "skip", "output", "fail"}
+	if !slices.Equal(actions, want) {
+		t.Errorf("sent events %v, want %v", actions, want)
+	}
+}
@@ -238,6 +238,12 @@ func (s *server) diagnoseSnapshot(ctx context.Context, snapshot *cache.Snapshot,
 		return
 	}
 	s.updateDiagnostics(ctx, snapshot, diagnostics, true)
+
+	// Now that the workspace packages are type-checked, index their
+	// tests, so that the code lenses and test navigation need not.
+	if err := snapshot.IndexTests(ctx); err != nil && ctx.Err() == nil {
+		event.Error(ctx, "warning: while indexing tests", err, snapshot.Labels()...)
+	}
 }
 
 func (s *server) diagnoseChangedFiles(ctx context.Context, snapshot *cache.Snapshot, uris []protocol.DocumentURI) (diagMap, error) {
@@ -449,8 +455,9 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 	//   covering packages, so {x.go} alone would be analyzed
 	//   twice.)
 	var (
-		toDiagnose = make(map[metadata.PackageID]*metadata.Package)
-		toAnalyze  = make(map[metadata.PackageID]*metadata.Package)
+		toDiagnose     = make(map[metadata.PackageID]*metadata.Package)
+		toDiagnoseOpen = make(map[metadata.PackageID]*metadata.Package) // subset with open files
+		toAnalyze      = make(map[metadata.PackageID]*metadata.Package)
 
 		// secondary index, used to eliminate narrower packages.
 		toAnalyzeWidest = make(map[golang.PackagePath]*metadata.Package)
@@ -468,6 +475,7 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 		if hasNonIgnored {
 			toDiagnose[mp.ID] = mp
 			if hasOpenFile {
+				toDiagnoseOpen[mp.ID] = mp
 				if prev, ok := toAnalyzeWidest[mp.PkgPath]; ok {
 					if len(prev.CompiledGoFiles) >= len(mp.CompiledGoFiles) {
 						// Previous entry is not narrower; keep it.
@@ -489,6 +497,22 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 		store("collecting compiler optimization details", compilerOptDetailsDiags, err)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		testdataDiags, err := golang.TestdataDiagnostics(ctx, snapshot, toAnalyze)
+		store("diagnosing testdata references", testdataDiags, err)
+	}()
+
+	// The missingtest analyzer reports through the ordinary package,
+	// not the widest one (see [golang.MissingTestDiagnostics]).
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		missingTestDiags, err := golang.MissingTestDiagnostics(ctx, snapshot, toDiagnoseOpen)
+		store("diagnosing missing tests", missingTestDiags, err)
+	}()
+
 	// Package diagnostics and analysis diagnostics must both be computed and
 	// merged before they can be reported.
 	var pkgDiags, analysisDiags diagMap
@@ -49,7 +49,30 @@ func (s *server) Hover(ctx context.Context, params *protocol.HoverParams) (_ *pr
 				}
 			}
 		}
-		return golang.Hover(ctx, snapshot, fh, params.Position, pkgURL)
+		// Describe the vulnerabilities reached through a call, if any.
+		vulnHover, err := mod.CallSiteHover(ctx, snapshot, fh, params.Position)
+		if err != nil {
+			event.Error(ctx, "computing vulnerability hover", err)
+		}
+		var coverageURL func(protocol.DocumentURI) protocol.URI
+		if snapshot.Options().LinksInHover != settings.LinksInHover_None {
+			coverageURL = func(uri protocol.DocumentURI) protocol.URI {
+				web, err := s.getWeb()
+				if err != nil {
+					event.Error(ctx, "failed to start web server", err)
+					return ""
+				}
+				return web.coverageURL(snapshot.View().ID(), uri)
+			}
+		}
+		hover, err := golang.Hover(ctx, snapshot, fh, params.Position, pkgURL, coverageURL)
+		if vulnHover != nil {
+			if err != nil || hover == nil {
+				return vulnHover, nil
+			}
+			hover.Contents.Value = vulnHover.Contents.Value + "\n" + hover.Contents.Value
+		}
+		return hover, err
 	case file.Tmpl:
 		return template.Hover(ctx, snapshot, fh, params.Position)
 	case file.Work:
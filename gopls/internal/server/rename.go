@@ -33,10 +33,13 @@ func (s *server) Rename(ctx context.Context, params *protocol.RenameParams) (*pr
 	// Because we don't handle directory renaming within golang.Rename, golang.Rename returns
 	// boolean value isPkgRenaming to determine whether any DocumentChanges of type RenameFile should
 	// be added to the return protocol.WorkspaceEdit value.
-	edits, isPkgRenaming, err := golang.Rename(ctx, snapshot, fh, params.Position, params.NewName)
+	edits, isPkgRenaming, warning, err := golang.Rename(ctx, snapshot, fh, params.Position, params.NewName)
 	if err != nil {
 		return nil, err
 	}
+	if warning != "" {
+		showMessage(ctx, s.client, protocol.Warning, warning)
+	}
 
 	var changes []protocol.DocumentChange
 	for uri, e := range edits {
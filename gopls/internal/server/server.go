@@ -178,6 +178,17 @@ type server struct {
 	cancelPrevDiagnostics func()
 	viewsToDiagnose       map[*cache.View]uint64 // View -> modification at which it last required diagnosis
 	lastModificationID    uint64                 // incrementing clock
+
+	// The changes of the files of an edit that the server applies in
+	// the client (see applyChanges) are diagnosed together, once the
+	// client has reported them all, so that an edit of many files, such
+	// as the tests of a package, results in a single diagnosis of the
+	// affected packages rather than a diagnosis per changed file. The
+	// client usually reports them after the command that applied the
+	// edit returns, since notifications are handled in order.
+	editedFiles     map[protocol.DocumentURI]bool          // files of applied edits -> whether their change is yet to be reported
+	deferredChanges map[*cache.View][]protocol.DocumentURI // reported changes to diagnose together
+	deferredCause   ModificationSource                     // cause of the deferred changes
 }
 
 func (s *server) WorkDoneProgressCancel(ctx context.Context, params *protocol.WorkDoneProgressCancelParams) error {
@@ -207,6 +218,8 @@ func (s *server) WorkDoneProgressCancel(ctx context.Context, params *protocol.Wo
 //	pkg/PKGPATH?view=%s               - show doc for package in a given view
 //	assembly?pkg=%s&view=%s&symbol=%s - show assembly of specified func symbol
 //	freesymbols?file=%s&range=%d:%d:%d:%d:&view=%s - show report of free symbols
+//	missingtests?view=%s              - show report of functions without tests
+//	addtest?view=%s&file=%s&line=%d&col=%d - add a test for a function
 type web struct {
 	server *http.Server
 	addr   url.URL // "http://127.0.0.1:PORT/gopls/SECRET"
@@ -450,6 +463,131 @@ func (s *server) initWeb() (*web, error) {
 		golang.AssemblyHTML(ctx, snapshot, w, pkg, symbol, web)
 	})
 
+	// The /missingtests?view=... handler shows the functions of the
+	// workspace that have no tests.
+	webMux.HandleFunc("/missingtests", func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Get snapshot of specified view.
+		view, err := s.session.View(req.Form.Get("view"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		snapshot, release, err := view.Snapshot()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer release()
+
+		// Produce report.
+		html, err := golang.MissingTestsHTML(ctx, snapshot, view.ID(), web)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(html)
+	})
+
+	// The /coverage?view=...&file=... handler shows the source of the
+	// file annotated with its test coverage.
+	webMux.HandleFunc("/coverage", func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Get snapshot of specified view.
+		view, err := s.session.View(req.Form.Get("view"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		snapshot, release, err := view.Snapshot()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer release()
+
+		fh, err := snapshot.ReadFile(ctx, protocol.DocumentURI(req.Form.Get("file")))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Produce report.
+		html, err := golang.CoverageHTML(snapshot, fh, web)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(html)
+	})
+
+	// The /addtest?view=...&file=...&line=...&col=... handler adds a
+	// test for the function declared at the specified position, as
+	// does the "Add test" code action, and opens the function in the
+	// client editor. Failures are reported to the client, as the
+	// browser ignores the response.
+	webMux.HandleFunc("/addtest", func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		addTest := func() error {
+			// Get snapshot of specified view.
+			view, err := s.session.View(req.Form.Get("view"))
+			if err != nil {
+				return err
+			}
+			snapshot, release, err := view.Snapshot()
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			// Map the 1-based UTF-8 position to LSP coordinates.
+			uri := protocol.URIFromPath(req.Form.Get("file"))
+			line, _ := strconv.Atoi(req.Form.Get("line"))
+			col, _ := strconv.Atoi(req.Form.Get("col"))
+			fh, err := snapshot.ReadFile(ctx, uri)
+			if err != nil {
+				return err
+			}
+			content, err := fh.Content()
+			if err != nil {
+				return err
+			}
+			posn, err := protocol.NewMapper(uri, content).LineCol8Position(line, col)
+			if err != nil {
+				return err
+			}
+			loc := protocol.Location{URI: uri, Range: protocol.Range{Start: posn, End: posn}}
+
+			changes, err := golang.AddTestForFunc(ctx, snapshot, loc, false, golang.TestOptions{})
+			if err != nil {
+				return err
+			}
+			if err := s.applyChanges(ctx, changes); err != nil {
+				return err
+			}
+			openClientEditor(ctx, s.client, loc, s.Options())
+			return nil
+		}
+		if err := addTest(); err != nil {
+			showMessage(ctx, s.client, protocol.Error, fmt.Sprintf("Adding test: %v", err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
 	return web, nil
 }
 
@@ -496,6 +634,40 @@ func (w *web) freesymbolsURL(viewID string, loc protocol.Location) protocol.URI
 		"")
 }
 
+// AddTestURL returns an /addtest URL that, when visited, adds a test
+// for the function declared at the specified file/line/column (in
+// 1-based UTF-8 coordinates).
+func (w *web) AddTestURL(viewID, filename string, line, col8 int) protocol.URI {
+	return w.url(
+		"addtest",
+		fmt.Sprintf("view=%s&file=%s&line=%d&col=%d",
+			url.QueryEscape(viewID),
+			url.QueryEscape(filename),
+			line,
+			col8),
+		"")
+}
+
+// missingTestsURL returns a /missingtests URL for a report on the
+// functions of the workspace of the specified view that have no tests.
+func (w *web) missingTestsURL(viewID string) protocol.URI {
+	return w.url(
+		"missingtests",
+		"view="+url.QueryEscape(viewID),
+		"")
+}
+
+// coverageURL returns a /coverage URL for a view of the source of the
+// specified file annotated with its test coverage.
+func (w *web) coverageURL(viewID string, uri protocol.DocumentURI) protocol.URI {
+	return w.url(
+		"coverage",
+		fmt.Sprintf("view=%s&file=%s",
+			url.QueryEscape(viewID),
+			url.QueryEscape(string(uri))),
+		"")
+}
+
 // assemblyURL returns the URL of an assembly listing of the specified function symbol.
 func (w *web) assemblyURL(viewID, packageID, symbol string) protocol.URI {
 	return w.url(
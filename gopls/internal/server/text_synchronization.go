@@ -9,7 +9,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
@@ -17,6 +19,7 @@ import (
 	"golang.org/x/tools/gopls/internal/file"
 	"golang.org/x/tools/gopls/internal/golang"
 	"golang.org/x/tools/gopls/internal/label"
+	"golang.org/x/tools/gopls/internal/progress"
 	"golang.org/x/tools/gopls/internal/protocol"
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/jsonrpc2"
@@ -263,13 +266,16 @@ func (s *server) didModifyFiles(ctx context.Context, modifications []file.Modifi
 		s.mustPublishDiagnostics(mod.URI)
 	}
 
+	cause, deferred := s.deferDiagnosis(modifications, viewsToDiagnose, cause)
 	modCtx, modID := s.needsDiagnosis(ctx, viewsToDiagnose)
 
-	wg.Add(1)
-	go func() {
-		s.diagnoseChangedViews(modCtx, modID, viewsToDiagnose, cause)
-		wg.Done()
-	}()
+	if !deferred {
+		wg.Add(1)
+		go func() {
+			s.diagnoseChangedViews(modCtx, modID, viewsToDiagnose, cause)
+			wg.Done()
+		}()
+	}
 
 	// After any file modifications, we need to update our watched files,
 	// in case something changed. Compute the new set of directories to watch,
@@ -300,6 +306,155 @@ func (s *server) needsDiagnosis(ctx context.Context, viewsToDiagnose map[*cache.
 	return modCtx, modID
 }
 
+// expectEdits records the files of the changes that the server is
+// about to apply in the client, so that the diagnosis of their changes
+// is deferred until the client has reported them all (see
+// [server.deferDiagnosis]). It returns the recorded files.
+func (s *server) expectEdits(changes []protocol.DocumentChange) []protocol.DocumentURI {
+	var uris []protocol.DocumentURI
+	for _, change := range changes {
+		switch {
+		case change.TextDocumentEdit != nil:
+			uris = append(uris, change.TextDocumentEdit.TextDocument.URI)
+		case change.CreateFile != nil:
+			uris = append(uris, change.CreateFile.URI)
+		case change.RenameFile != nil:
+			uris = append(uris, change.RenameFile.OldURI, change.RenameFile.NewURI)
+		case change.DeleteFile != nil:
+			uris = append(uris, change.DeleteFile.URI)
+		}
+	}
+	s.modificationMu.Lock()
+	defer s.modificationMu.Unlock()
+	if s.editedFiles == nil {
+		s.editedFiles = make(map[protocol.DocumentURI]bool)
+	}
+	for _, uri := range uris {
+		s.editedFiles[uri] = true
+	}
+	return uris
+}
+
+// deferDiagnosis defers the diagnosis of the given modifications if
+// they are reported changes of files edited by the server (see
+// [server.expectEdits]) and the client has yet to report the changes
+// of other edited files, and reports whether it did.
+//
+// Otherwise, it adds the changes deferred so far to viewsToDiagnose,
+// to be diagnosed together with the modifications, and returns the
+// cause of their diagnosis. This happens once the client has reported
+// the changes of all the edited files, or if it reports an unrelated
+// change, such as one made by the user.
+func (s *server) deferDiagnosis(modifications []file.Modification, viewsToDiagnose map[*cache.View][]protocol.DocumentURI, cause ModificationSource) (ModificationSource, bool) {
+	s.modificationMu.Lock()
+	defer s.modificationMu.Unlock()
+	if len(s.editedFiles) == 0 {
+		return cause, false
+	}
+	edited := true
+	for _, mod := range modifications {
+		if _, ok := s.editedFiles[mod.URI]; !ok {
+			edited = false
+		}
+	}
+	if edited {
+		for _, mod := range modifications {
+			s.editedFiles[mod.URI] = false // reported
+		}
+		if s.deferredChanges == nil {
+			s.deferredChanges = make(map[*cache.View][]protocol.DocumentURI)
+			s.deferredCause = cause
+		}
+		addChanges(s.deferredChanges, viewsToDiagnose)
+		s.deferredCause = combineCauses(s.deferredCause, cause)
+		if s.awaitingEditsLocked() {
+			return cause, true
+		}
+	}
+	if s.deferredChanges != nil {
+		addChanges(viewsToDiagnose, s.deferredChanges)
+		cause = combineCauses(cause, s.deferredCause)
+		s.deferredChanges = nil
+	}
+	s.forgetReportedEditsLocked()
+	return cause, false
+}
+
+// diagnoseDeferredChanges stops waiting for the client to report the
+// changes of the given edited files (see [server.expectEdits]) and,
+// unless it is waiting for other files, diagnoses the changes deferred
+// meanwhile by [server.deferDiagnosis], all at once.
+func (s *server) diagnoseDeferredChanges(ctx context.Context, uris []protocol.DocumentURI) {
+	s.modificationMu.Lock()
+	for _, uri := range uris {
+		delete(s.editedFiles, uri)
+	}
+	changes, cause := s.deferredChanges, s.deferredCause
+	if s.awaitingEditsLocked() || changes == nil {
+		s.modificationMu.Unlock()
+		return
+	}
+	s.deferredChanges = nil
+	s.forgetReportedEditsLocked()
+	s.modificationMu.Unlock()
+
+	ctx = xcontext.Detach(ctx)
+	var work *progress.WorkDone
+	if s.Options().VerboseWorkDoneProgress {
+		work = s.progress.Start(ctx, DiagnosticWorkTitle(cause), "Calculating file diagnostics...", nil, nil)
+	}
+	modCtx, modID := s.needsDiagnosis(ctx, changes)
+	go func() {
+		s.diagnoseChangedViews(modCtx, modID, changes, cause)
+		if work != nil {
+			work.End(ctx, "Done.")
+		}
+	}()
+}
+
+// awaitingEditsLocked reports whether the client has yet to report the
+// change of an edited file. s.modificationMu must be held.
+func (s *server) awaitingEditsLocked() bool {
+	for _, pending := range s.editedFiles {
+		if pending {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetReportedEditsLocked forgets the edited files whose changes
+// have been diagnosed. s.modificationMu must be held.
+func (s *server) forgetReportedEditsLocked() {
+	maps.DeleteFunc(s.editedFiles, func(_ protocol.DocumentURI, pending bool) bool {
+		return !pending
+	})
+}
+
+// addChanges adds the changed files of src to dst.
+func addChanges(dst, src map[*cache.View][]protocol.DocumentURI) {
+	for v, uris := range src {
+		changed := dst[v]
+		for _, uri := range uris {
+			if !slices.Contains(changed, uri) {
+				changed = append(changed, uri)
+			}
+		}
+		dst[v] = changed
+	}
+}
+
+// combineCauses returns the cause of the diagnosis of changes of the
+// two given causes. Changes from didChange notifications are not
+// diagnosed if the user requested diagnostics on save, so other causes
+// take precedence.
+func combineCauses(x, y ModificationSource) ModificationSource {
+	if x == FromDidChange {
+		return y
+	}
+	return x
+}
+
 // DiagnosticWorkTitle returns the title of the diagnostic work resulting from a
 // file change originating from the given cause.
 func DiagnosticWorkTitle(cause ModificationSource) string {
@@ -20,6 +20,7 @@ import (
 	"golang.org/x/tools/go/analysis/passes/defers"
 	"golang.org/x/tools/go/analysis/passes/directive"
 	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/go/analysis/passes/fieldalignment"
 	"golang.org/x/tools/go/analysis/passes/framepointer"
 	"golang.org/x/tools/go/analysis/passes/httpresponse"
 	"golang.org/x/tools/go/analysis/passes/ifaceassert"
@@ -46,21 +47,33 @@ import (
 	"golang.org/x/tools/go/analysis/passes/unusedresult"
 	"golang.org/x/tools/go/analysis/passes/unusedwrite"
 	"golang.org/x/tools/go/analysis/passes/waitgroup"
+	"golang.org/x/tools/gopls/internal/analysis/contextfield"
+	"golang.org/x/tools/gopls/internal/analysis/deferclose"
 	"golang.org/x/tools/gopls/internal/analysis/deprecated"
 	"golang.org/x/tools/gopls/internal/analysis/embeddirective"
+	"golang.org/x/tools/gopls/internal/analysis/exhaustive"
 	"golang.org/x/tools/gopls/internal/analysis/fillreturns"
 	"golang.org/x/tools/gopls/internal/analysis/gofix"
 	"golang.org/x/tools/gopls/internal/analysis/hostport"
 	"golang.org/x/tools/gopls/internal/analysis/infertypeargs"
+	"golang.org/x/tools/gopls/internal/analysis/joinpath"
+	"golang.org/x/tools/gopls/internal/analysis/jsontag"
+	"golang.org/x/tools/gopls/internal/analysis/missingdoc"
+	"golang.org/x/tools/gopls/internal/analysis/missingtest"
 	"golang.org/x/tools/gopls/internal/analysis/modernize"
 	"golang.org/x/tools/gopls/internal/analysis/nonewvars"
 	"golang.org/x/tools/gopls/internal/analysis/noresultvalues"
+	"golang.org/x/tools/gopls/internal/analysis/orphanedtest"
+	"golang.org/x/tools/gopls/internal/analysis/paralleltest"
 	"golang.org/x/tools/gopls/internal/analysis/simplifycompositelit"
 	"golang.org/x/tools/gopls/internal/analysis/simplifyrange"
 	"golang.org/x/tools/gopls/internal/analysis/simplifyslice"
+	"golang.org/x/tools/gopls/internal/analysis/timeafter"
 	"golang.org/x/tools/gopls/internal/analysis/unusedfunc"
 	"golang.org/x/tools/gopls/internal/analysis/unusedparams"
 	"golang.org/x/tools/gopls/internal/analysis/unusedvariable"
+	"golang.org/x/tools/gopls/internal/analysis/unwrappederr"
+	"golang.org/x/tools/gopls/internal/analysis/wallclock"
 	"golang.org/x/tools/gopls/internal/analysis/yield"
 	"golang.org/x/tools/gopls/internal/protocol"
 )
@@ -74,6 +87,7 @@ type Analyzer struct {
 	actionKinds []protocol.CodeActionKind
 	severity    protocol.DiagnosticSeverity
 	tags        []protocol.DiagnosticTag
+	snapshot    bool
 }
 
 // Analyzer returns the [analysis.Analyzer] that this Analyzer wraps.
@@ -123,6 +137,11 @@ func (a *Analyzer) Severity() protocol.DiagnosticSeverity {
 // reported by this analyzer.
 func (a *Analyzer) Tags() []protocol.DiagnosticTag { return a.tags }
 
+// FromSnapshot reports whether gopls computes the diagnostics of this
+// analyzer from the snapshot, instead of running it on each package,
+// because they depend on files outside the package, such as its tests.
+func (a *Analyzer) FromSnapshot() bool { return a.snapshot }
+
 // String returns the name of this analyzer.
 func (a *Analyzer) String() string { return a.analyzer.String() }
 
@@ -186,7 +205,34 @@ func init() {
 
 		// disabled due to high false positives
 		{analyzer: shadow.Analyzer, nonDefault: true}, // very noisy
-		// fieldalignment is not even off-by-default; see #67762.
+		// fieldalignment's diagnostics rarely indicate a significant problem; see #67762.
+		{analyzer: fieldalignment.Analyzer, nonDefault: true, severity: protocol.SeverityInformation},
+		// missingdoc enforces a convention that not all packages follow.
+		{analyzer: missingdoc.Analyzer, nonDefault: true, severity: protocol.SeverityInformation},
+		// missingtest reports the absence of tests, which many packages choose.
+		// Its diagnostics depend on the tests of the package, so they are
+		// computed from the snapshot (see golang.MissingTestDiagnostics).
+		{analyzer: missingtest.Analyzer, nonDefault: true, severity: protocol.SeverityInformation, snapshot: true},
+		// orphanedtest relies on test naming conventions that not all packages follow.
+		{analyzer: orphanedtest.Analyzer, nonDefault: true, severity: protocol.SeverityWarning},
+		// contextfield reports a practice that some APIs, such as net/http, require.
+		{analyzer: contextfield.Analyzer, nonDefault: true},
+		// deferclose reports the common idiom "defer f.Close()".
+		{analyzer: deferclose.Analyzer, nonDefault: true},
+		// unwrappederr reports errors that many packages deliberately return as is.
+		{analyzer: unwrappederr.Analyzer, nonDefault: true, severity: protocol.SeverityInformation},
+		// jsontag enforces naming conventions of the struct's own choosing.
+		{analyzer: jsontag.Analyzer, nonDefault: true, severity: protocol.SeverityInformation},
+		// paralleltest cannot see the shared state changed by helper functions.
+		{analyzer: paralleltest.Analyzer, nonDefault: true, severity: protocol.SeverityInformation},
+		// joinpath reports paths that are correct on the platforms a program supports.
+		{analyzer: joinpath.Analyzer, nonDefault: true, severity: protocol.SeverityInformation},
+		// exhaustive reports switches that need not handle every constant.
+		{analyzer: exhaustive.Analyzer, nonDefault: true, severity: protocol.SeverityInformation},
+		// wallclock reports reads of the clock that many functions have no need to fake.
+		{analyzer: wallclock.Analyzer, nonDefault: true, severity: protocol.SeverityInformation},
+		// timeafter reports timers that Go 1.23 and later release without a call to Stop.
+		{analyzer: timeafter.Analyzer, nonDefault: true},
 
 		// simplifiers and modernizers
 		//
@@ -4,7 +4,11 @@
 
 package settings
 
-import "golang.org/x/tools/gopls/internal/protocol"
+import (
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+)
 
 // This file defines constants for non-standard CodeActions.
 
@@ -73,32 +77,83 @@ import "golang.org/x/tools/gopls/internal/protocol"
 // are unambiguously safe to apply so that clients may automatically
 // apply all actions matching this category on save. (That said, this
 // is not VS Code's default behavior; see editor.codeActionsOnSave.)
+//
+// # Generators
+//
+// Actions that generate code, such as tests and test doubles, belong
+// to the "source.generate" category, so that clients may offer them
+// in a dedicated "Generate..." menu or bind keys to a whole family
+// (e.g. "source.generate.test"). Before this hierarchy existed, they
+// used flat kinds such as "source.addTest"; see [legacyKinds] for how
+// gopls negotiates with clients that know only those.
 const (
 	// source
 	GoAssembly                 protocol.CodeActionKind = "source.assembly"
 	GoDoc                      protocol.CodeActionKind = "source.doc"
 	GoFreeSymbols              protocol.CodeActionKind = "source.freesymbols"
 	GoTest                     protocol.CodeActionKind = "source.test"
+	GoCopyTestCommand          protocol.CodeActionKind = "source.copyTestCommand"
 	GoToggleCompilerOptDetails protocol.CodeActionKind = "source.toggleCompilerOptDetails"
-	AddTest                    protocol.CodeActionKind = "source.addTest"
+	AddStringMethod            protocol.CodeActionKind = "source.addStringMethod"
+	AddJSONMethods             protocol.CodeActionKind = "source.addJSONMethods"
+	AddCloneMethod             protocol.CodeActionKind = "source.addCloneMethod"
+	AddEqualMethod             protocol.CodeActionKind = "source.addEqualMethod"
+	AddFuncOptions             protocol.CodeActionKind = "source.addFuncOptions"
+	AddFlagsMethod             protocol.CodeActionKind = "source.addFlagsMethod"
+	OrganizeTests              protocol.CodeActionKind = "source.organizeTests"
+	ConvertAssertionsStd       protocol.CodeActionKind = "source.convertAssertions.std"
+	ConvertAssertionsTestify   protocol.CodeActionKind = "source.convertAssertions.testify"
+
+	// source.generate
+	SourceGenerate         protocol.CodeActionKind = "source.generate"
+	AddTest                protocol.CodeActionKind = "source.generate.test"
+	AddIntegrationTest     protocol.CodeActionKind = "source.generate.test.integration"
+	AddReceiverFormsTest   protocol.CodeActionKind = "source.generate.test.receiverForms"
+	AddFieldAssertionsTest protocol.CodeActionKind = "source.generate.test.fieldAssertions"
+	AddMock                protocol.CodeActionKind = "source.generate.mock"
+	AddFake                protocol.CodeActionKind = "source.generate.fake"
 
 	// gopls
 	GoplsDocFeatures protocol.CodeActionKind = "gopls.doc.features"
 
 	// refactor.rewrite
-	RefactorRewriteChangeQuote        protocol.CodeActionKind = "refactor.rewrite.changeQuote"
-	RefactorRewriteFillStruct         protocol.CodeActionKind = "refactor.rewrite.fillStruct"
-	RefactorRewriteFillSwitch         protocol.CodeActionKind = "refactor.rewrite.fillSwitch"
-	RefactorRewriteInvertIf           protocol.CodeActionKind = "refactor.rewrite.invertIf"
-	RefactorRewriteJoinLines          protocol.CodeActionKind = "refactor.rewrite.joinLines"
-	RefactorRewriteRemoveUnusedParam  protocol.CodeActionKind = "refactor.rewrite.removeUnusedParam"
-	RefactorRewriteMoveParamLeft      protocol.CodeActionKind = "refactor.rewrite.moveParamLeft"
-	RefactorRewriteMoveParamRight     protocol.CodeActionKind = "refactor.rewrite.moveParamRight"
-	RefactorRewriteSplitLines         protocol.CodeActionKind = "refactor.rewrite.splitLines"
-	RefactorRewriteEliminateDotImport protocol.CodeActionKind = "refactor.rewrite.eliminateDotImport"
+	RefactorRewriteChangeQuote         protocol.CodeActionKind = "refactor.rewrite.changeQuote"
+	RefactorRewriteFillStruct          protocol.CodeActionKind = "refactor.rewrite.fillStruct"
+	RefactorRewriteFillSwitch          protocol.CodeActionKind = "refactor.rewrite.fillSwitch"
+	RefactorRewriteFillTypeSwitch      protocol.CodeActionKind = "refactor.rewrite.fillTypeSwitch"
+	RefactorRewriteInvertIf            protocol.CodeActionKind = "refactor.rewrite.invertIf"
+	RefactorRewriteIfToSwitch          protocol.CodeActionKind = "refactor.rewrite.ifToSwitch"
+	RefactorRewriteAddIterator         protocol.CodeActionKind = "refactor.rewrite.addIterator"
+	RefactorRewriteRangeOverFunc       protocol.CodeActionKind = "refactor.rewrite.rangeOverFunc"
+	RefactorRewriteWrapError           protocol.CodeActionKind = "refactor.rewrite.wrapError"
+	RefactorRewriteWrapErrorAll        protocol.CodeActionKind = "refactor.rewrite.wrapError-all"
+	RefactorRewritePointerReceivers    protocol.CodeActionKind = "refactor.rewrite.pointerReceivers"
+	RefactorRewriteValueReceivers      protocol.CodeActionKind = "refactor.rewrite.valueReceivers"
+	RefactorRewriteUnexport            protocol.CodeActionKind = "refactor.rewrite.unexport"
+	RefactorRewriteFuncToMethod        protocol.CodeActionKind = "refactor.rewrite.funcToMethod"
+	RefactorRewriteMethodToFunc        protocol.CodeActionKind = "refactor.rewrite.methodToFunc"
+	RefactorRewriteAddContext          protocol.CodeActionKind = "refactor.rewrite.addContext"
+	RefactorRewriteAddContextCallers   protocol.CodeActionKind = "refactor.rewrite.addContext-callers"
+	RefactorRewriteJoinLines           protocol.CodeActionKind = "refactor.rewrite.joinLines"
+	RefactorRewriteRemoveUnusedParam   protocol.CodeActionKind = "refactor.rewrite.removeUnusedParam"
+	RefactorRewriteMoveParamLeft       protocol.CodeActionKind = "refactor.rewrite.moveParamLeft"
+	RefactorRewriteMoveParamRight      protocol.CodeActionKind = "refactor.rewrite.moveParamRight"
+	RefactorRewriteSplitLines          protocol.CodeActionKind = "refactor.rewrite.splitLines"
+	RefactorRewriteEliminateDotImport  protocol.CodeActionKind = "refactor.rewrite.eliminateDotImport"
+	RefactorRewriteAddFieldNames       protocol.CodeActionKind = "refactor.rewrite.addFieldNames"
+	RefactorRewriteAddFieldNamesAll    protocol.CodeActionKind = "refactor.rewrite.addFieldNames-all"
+	RefactorRewriteRemoveFieldNames    protocol.CodeActionKind = "refactor.rewrite.removeFieldNames"
+	RefactorRewriteRemoveFieldNamesAll protocol.CodeActionKind = "refactor.rewrite.removeFieldNames-all"
+	RefactorRewriteAddStructTagsJSON   protocol.CodeActionKind = "refactor.rewrite.addStructTags.json"
+	RefactorRewriteAddStructTagsYAML   protocol.CodeActionKind = "refactor.rewrite.addStructTags.yaml"
+	RefactorRewriteAddStructTagsDB     protocol.CodeActionKind = "refactor.rewrite.addStructTags.db"
+	RefactorRewriteAddTestCase         protocol.CodeActionKind = "refactor.rewrite.addTestCase"
+	RefactorRewriteTableTest           protocol.CodeActionKind = "refactor.rewrite.tableTest"
+	RefactorRewriteMergeTests          protocol.CodeActionKind = "refactor.rewrite.mergeTests"
 
 	// refactor.inline
-	RefactorInlineCall protocol.CodeActionKind = "refactor.inline.call"
+	RefactorInlineCall     protocol.CodeActionKind = "refactor.inline.call"
+	RefactorInlineVariable protocol.CodeActionKind = "refactor.inline.variable"
 
 	// refactor.extract
 	RefactorExtractConstant    protocol.CodeActionKind = "refactor.extract.constant"
@@ -108,9 +163,63 @@ const (
 	RefactorExtractVariable    protocol.CodeActionKind = "refactor.extract.variable"
 	RefactorExtractVariableAll protocol.CodeActionKind = "refactor.extract.variable-all"
 	RefactorExtractToNewFile   protocol.CodeActionKind = "refactor.extract.toNewFile"
+	RefactorExtractSplitFile   protocol.CodeActionKind = "refactor.extract.splitFile"
+	RefactorExtractParamStruct protocol.CodeActionKind = "refactor.extract.paramStruct"
+	RefactorExtractTestHelper  protocol.CodeActionKind = "refactor.extract.testHelper"
 
 	// Note: add new kinds to:
 	// - the SupportedCodeActions map in default.go
 	// - the codeActionProducers table in ../golang/codeaction.go
 	// - the docs in ../../doc/features/transformation.md
 )
+
+// legacyKinds maps each generator kind to the flat kind that
+// preceded it, for clients whose advertised set of code action kinds
+// does not cover "source.generate" (see [Options.ClientCodeActionKind]),
+// and for requests whose Only field names the old kind
+// (see [CanonicalCodeActionKind]).
+var legacyKinds = map[protocol.CodeActionKind]protocol.CodeActionKind{
+	AddTest:                "source.addTest",
+	AddIntegrationTest:     "source.addIntegrationTest",
+	AddReceiverFormsTest:   "source.addReceiverFormsTest",
+	AddFieldAssertionsTest: "source.addFieldAssertionsTest",
+	AddMock:                "source.addMock",
+	AddFake:                "source.addFake",
+}
+
+// CanonicalCodeActionKind returns the generator kind that replaced
+// the specified legacy kind, such as "source.generate.test" for
+// "source.addTest". Other kinds are returned unchanged.
+func CanonicalCodeActionKind(kind protocol.CodeActionKind) protocol.CodeActionKind {
+	for k, legacy := range legacyKinds {
+		if legacy == kind {
+			return k
+		}
+	}
+	return kind
+}
+
+// ClientCodeActionKind returns the kind of code action to report to
+// the client for an action of the specified kind: the generator kinds
+// are replaced by their legacy equivalents if the client advertised a
+// set of code action kinds that neither includes nor falls within
+// "source.generate". Clients that advertise no set at all get the
+// hierarchical kinds.
+func (o *Options) ClientCodeActionKind(kind protocol.CodeActionKind) protocol.CodeActionKind {
+	legacy, ok := legacyKinds[kind]
+	if !ok || o.CodeActionKinds == nil {
+		return kind
+	}
+	for _, k := range o.CodeActionKinds {
+		// Kinds are hierarchical: an advertised "source" (or "")
+		// subsumes "source.generate", and an advertised
+		// "source.generate.test" implies that the client knows
+		// its parent.
+		if k == "" ||
+			strings.HasPrefix(string(SourceGenerate)+".", string(k)+".") ||
+			strings.HasPrefix(string(k), string(SourceGenerate)+".") {
+			return kind
+		}
+	}
+	return legacy
+}
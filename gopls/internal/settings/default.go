@@ -47,28 +47,51 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 						// This should include specific leaves in the tree,
 						// (e.g. refactor.inline.call) not generic branches
 						// (e.g. refactor.inline or refactor).
-						protocol.SourceFixAll:            true,
-						protocol.SourceOrganizeImports:   true,
-						protocol.QuickFix:                true,
-						GoAssembly:                       true,
-						GoDoc:                            true,
-						GoFreeSymbols:                    true,
-						GoplsDocFeatures:                 true,
-						RefactorRewriteChangeQuote:       true,
-						RefactorRewriteFillStruct:        true,
-						RefactorRewriteFillSwitch:        true,
-						RefactorRewriteInvertIf:          true,
-						RefactorRewriteJoinLines:         true,
-						RefactorRewriteRemoveUnusedParam: true,
-						RefactorRewriteSplitLines:        true,
-						RefactorInlineCall:               true,
-						RefactorExtractConstant:          true,
-						RefactorExtractConstantAll:       true,
-						RefactorExtractFunction:          true,
-						RefactorExtractMethod:            true,
-						RefactorExtractVariable:          true,
-						RefactorExtractVariableAll:       true,
-						RefactorExtractToNewFile:         true,
+						protocol.SourceFixAll:              true,
+						protocol.SourceOrganizeImports:     true,
+						protocol.QuickFix:                  true,
+						GoAssembly:                         true,
+						GoDoc:                              true,
+						GoFreeSymbols:                      true,
+						GoplsDocFeatures:                   true,
+						RefactorRewriteChangeQuote:         true,
+						RefactorRewriteFillStruct:          true,
+						RefactorRewriteFillSwitch:          true,
+						RefactorRewriteFillTypeSwitch:      true,
+						RefactorRewriteInvertIf:            true,
+						RefactorRewriteIfToSwitch:          true,
+						RefactorRewriteAddIterator:         true,
+						RefactorRewriteRangeOverFunc:       true,
+						RefactorRewriteWrapError:           true,
+						RefactorRewriteWrapErrorAll:        true,
+						RefactorRewritePointerReceivers:    true,
+						RefactorRewriteValueReceivers:      true,
+						RefactorRewriteUnexport:            true,
+						RefactorRewriteFuncToMethod:        true,
+						RefactorRewriteMethodToFunc:        true,
+						RefactorRewriteAddContext:          true,
+						RefactorRewriteAddContextCallers:   true,
+						RefactorRewriteJoinLines:           true,
+						RefactorRewriteRemoveUnusedParam:   true,
+						RefactorRewriteSplitLines:          true,
+						RefactorRewriteAddFieldNames:       true,
+						RefactorRewriteAddFieldNamesAll:    true,
+						RefactorRewriteRemoveFieldNames:    true,
+						RefactorRewriteRemoveFieldNamesAll: true,
+						RefactorRewriteAddStructTagsJSON:   true,
+						RefactorRewriteAddStructTagsYAML:   true,
+						RefactorRewriteAddStructTagsDB:     true,
+						RefactorInlineCall:                 true,
+						RefactorInlineVariable:             true,
+						RefactorExtractConstant:            true,
+						RefactorExtractConstantAll:         true,
+						RefactorExtractFunction:            true,
+						RefactorExtractMethod:              true,
+						RefactorExtractVariable:            true,
+						RefactorExtractVariableAll:         true,
+						RefactorExtractToNewFile:           true,
+						RefactorExtractSplitFile:           true,
+						RefactorExtractParamStruct:         true,
 						// Not GoTest: it must be explicit in CodeActionParams.Context.Only
 					},
 					file.Mod: {
@@ -123,6 +146,12 @@ func DefaultOptions(overrides ...func(*Options)) *Options {
 						CodeLensRunGovulncheck:    false, // TODO(hyangah): enable
 					},
 				},
+				FormattingOptions: FormattingOptions{
+					StructTagCase:    SnakeCase,
+					TestFileTemplate: "{file}_test.go",
+					TestComments:     TestCommentsFull,
+					TestTodoMarker:   "TODO:",
+				},
 			},
 			InternalOptions: InternalOptions{
 				CompleteUnimported:          true,
@@ -62,8 +62,11 @@ type ClientOptions struct {
 	CompletionTags                             bool
 	CompletionDeprecated                       bool
 	SupportedResourceOperations                []protocol.ResourceOperationKind
+	SnippetEditSupported                       bool
 	CodeActionResolveOptions                   []string
+	CodeActionKinds                            []protocol.CodeActionKind // nil => client did not advertise a set
 	ShowDocumentSupported                      bool
+	CodeLensRefreshSupported                   bool
 	// SupportedWorkDoneProgressFormats specifies the formats supported by the
 	// client for handling workdone progress metadata.
 	SupportedWorkDoneProgressFormats map[WorkDoneProgressStyle]bool
@@ -269,6 +272,9 @@ const (
 	//
 	// This codelens source annotates each `Test` and `Benchmark`
 	// function in a `*_test.go` file with a command to run it.
+	// It also annotates each subtest whose name is known statically,
+	// such as the `name` field of each case of a table-driven test,
+	// with a command to run just that subtest.
 	//
 	// This source is off by default because VS Code has
 	// a client-side custom UI for testing, and because progress
@@ -279,6 +285,69 @@ const (
 	//   for an alternative approach.
 	CodeLensTest CodeLensSource = "test"
 
+	// Debug tests
+	//
+	// This codelens source annotates each `Test` function in a
+	// `*_test.go` file, and each subtest whose name is known
+	// statically, with a "debug test" command that returns the
+	// configuration of a debug session of just that test: the
+	// package path and directory, the name of the test, the
+	// filter of the `-test.run` flag that selects the subtest, and
+	// the build flags and environment with which to build it.
+	// Clients that support the Debug Adapter Protocol may use it
+	// to launch the session.
+	//
+	// This source is off by default, like the "test" source.
+	CodeLensDebugTest CodeLensSource = "debug_test"
+
+	// Run and compare benchmarks
+	//
+	// This codelens source annotates each `Benchmark` function in a
+	// `*_test.go` file with a command to run it several times,
+	// record its measurements, and compare them with those of its
+	// previous run, in the manner of
+	// [benchstat](https://pkg.go.dev/golang.org/x/perf/cmd/benchstat):
+	// the median and variation of each metric, such as ns/op, B/op,
+	// and allocs/op, and the change of the median, or `~` if it is
+	// not significant. Once the benchmark has run, the code lens
+	// shows the latest medians and their changes, such as
+	// `1.1µs/op (-10.86%), 48 B/op (-14.29%), 2 allocs/op (~)`, so
+	// that the effect of each edit can be seen without leaving the
+	// editor. The results of a session are kept until it ends.
+	//
+	// This source is off by default, like the "test" source.
+	CodeLensBenchmark CodeLensSource = "benchmark"
+
+	// Add or run the tests of a function
+	//
+	// This codelens source annotates each function and method
+	// declared in a file other than a `*_test.go` file with a
+	// command to run the `Test`, `Fuzz`, and `Example` functions of
+	// the package that exercise it: those named after it, such as
+	// `TestParse` and `TestParseError` for a function `Parse`, or
+	// `TestT_M` for a method `T.M`, and those that call it
+	// directly. If there are none, the command
+	// instead adds a table-driven test for the function, like the
+	// "Add test for F" code action.
+	//
+	// This source is off by default because it annotates every
+	// function.
+	CodeLensFunctionTests CodeLensSource = "function_tests"
+
+	// Go to the tests of a function, or the subjects of a test
+	//
+	// This codelens source annotates each function and method that
+	// has tests with a command to go to them, and each `Test`,
+	// `Benchmark`, `Fuzz`, and `Example` function with a command to
+	// go to the functions that it exercises. A test exercises the
+	// function after which it is named, such as `Parse` for
+	// `TestParse` or `T.M` for `TestT_M`, and each function of the
+	// package under test that it calls directly.
+	//
+	// This source is off by default because it requires type
+	// checking the tests of the package.
+	CodeLensTestNavigation CodeLensSource = "test_navigation"
+
 	// Tidy go.mod file
 	//
 	// This codelens source annotates the `module` directive in a
@@ -400,7 +469,9 @@ type FormattingOptions struct {
 	// Local is the equivalent of the `goimports -local` flag, which puts
 	// imports beginning with this string after third-party packages. It should
 	// be the prefix of the import path whose imports should be grouped
-	// separately.
+	// separately. Like the flag, it may be a comma-separated list of
+	// prefixes, and semicolons separate further groups, in order: for
+	// example, "example.com;example.com/mod".
 	//
 	// It is used when tidying imports (during an LSP Organize
 	// Imports request) or when inserting new ones (for example,
@@ -410,6 +481,44 @@ type FormattingOptions struct {
 
 	// Gofumpt indicates if we should run gofumpt formatting.
 	Gofumpt bool
+
+	// StructTagCase controls the naming convention of the names added by
+	// the "Add struct tags" code actions, which are derived from the
+	// names of the struct fields.
+	StructTagCase StructTagCase
+
+	// StructTagOmitEmpty causes the "Add struct tags" code actions to add
+	// the `omitempty` option to each tag they create or update.
+	StructTagOmitEmpty bool
+
+	// TestFileTemplate controls the name of the file to which the "Add
+	// test" code actions and commands add the tests of a file. It is a
+	// slash-separated path, relative to the directory of the package, in
+	// which `{file}` stands for the name of the file without its `.go`
+	// suffix, and `{package}` for the name of the package; it must end
+	// with `_test.go`. A path that begins with `/` is relative to the
+	// root of the module instead, and may use `{dir}`, the directory of
+	// the package relative to that root, to mirror the layout of the
+	// module.
+	//
+	// For example, `{file}_unit_test.go` adds the tests of foo.go to
+	// foo_unit_test.go, `{package}_test.go` adds all the tests of a
+	// package to a single file, and `/test/{dir}/{file}_test.go` adds
+	// them to a tree of external test packages under the test directory.
+	// Integration tests go to the corresponding `_integration_test.go`
+	// file.
+	TestFileTemplate string `status:"experimental"`
+
+	// TestComments controls the comments of the tests that the "Add
+	// test" code actions and commands generate: all of them, only the
+	// comments that mark the sites the user must complete, or none.
+	TestComments TestComments `status:"experimental"`
+
+	// TestTodoMarker is the marker with which the comments of generated
+	// tests that mark the sites the user must complete begin, such as
+	// `// TODO: Add test cases.`. Teams that track such sites by another
+	// marker may set it to `FIXME(owner):`, for example.
+	TestTodoMarker string `status:"experimental"`
 }
 
 // Note: DiagnosticOptions must be comparable with reflect.DeepEqual.
@@ -791,6 +900,33 @@ const (
 	AllSymbolScope SymbolScope = "all"
 )
 
+// A StructTagCase is the naming convention of the struct tags added
+// by the "Add struct tags" code actions.
+type StructTagCase string
+
+const (
+	// SnakeCase converts field MyField to my_field.
+	SnakeCase StructTagCase = "snake"
+	// CamelCase converts field MyField to myField.
+	CamelCase StructTagCase = "camel"
+	// KebabCase converts field MyField to my-field.
+	KebabCase StructTagCase = "kebab"
+)
+
+// A TestComments value selects the comments of generated tests.
+type TestComments string
+
+const (
+	// TestCommentsFull keeps all the comments, including those that
+	// explain the parts of the test.
+	TestCommentsFull TestComments = "full"
+	// TestCommentsMinimal keeps only the comments that mark the sites
+	// the user must complete.
+	TestCommentsMinimal TestComments = "minimal"
+	// TestCommentsNone removes all the comments.
+	TestCommentsNone TestComments = "none"
+)
+
 type HoverKind string
 
 const (
@@ -888,6 +1024,7 @@ func (o *Options) ForClientCapabilities(clientInfo *protocol.ClientInfo, caps pr
 	}
 	if caps.Workspace.WorkspaceEdit != nil {
 		o.SupportedResourceOperations = caps.Workspace.WorkspaceEdit.ResourceOperations
+		o.SnippetEditSupported = caps.Workspace.WorkspaceEdit.SnippetEditSupport
 	}
 	// Check if the client supports snippets in completion items.
 	if c := caps.TextDocument.Completion; c.CompletionItem.SnippetSupport {
@@ -897,6 +1034,9 @@ func (o *Options) ForClientCapabilities(clientInfo *protocol.ClientInfo, caps pr
 	if caps.Window.ShowDocument != nil {
 		o.ShowDocumentSupported = caps.Window.ShowDocument.Support
 	}
+	if caps.Workspace.CodeLens != nil {
+		o.CodeLensRefreshSupported = caps.Workspace.CodeLens.RefreshSupport
+	}
 	// Check if the client supports configuration messages.
 	o.ConfigurationSupported = caps.Workspace.Configuration
 	o.DynamicConfigurationSupported = caps.Workspace.DidChangeConfiguration.DynamicRegistration
@@ -937,6 +1077,9 @@ func (o *Options) ForClientCapabilities(clientInfo *protocol.ClientInfo, caps pr
 		o.CodeActionResolveOptions = caps.TextDocument.CodeAction.ResolveSupport.Properties
 	}
 
+	// Check which kinds of code action the client supports.
+	o.CodeActionKinds = caps.TextDocument.CodeAction.CodeActionLiteralSupport.CodeActionKind.ValueSet
+
 	// Client experimental capabilities.
 	if experimental, ok := caps.Experimental.(map[string]any); ok {
 		if formats, ok := experimental["progressMessageStyles"].([]any); ok {
@@ -981,6 +1124,33 @@ func validateDirectoryFilter(ifilter string) (string, error) {
 	return strings.TrimRight(filepath.FromSlash(filter), "/"), nil
 }
 
+// validateTestFileTemplate validates the testFileTemplate setting:
+// it must end with _test.go, refer only to the {file}, {package}, and,
+// if it is relative to the module root, {dir} placeholders, and have
+// no empty, ".", or ".." path segments.
+func validateTestFileTemplate(template string) error {
+	if !strings.HasSuffix(template, "_test.go") {
+		return fmt.Errorf("invalid test file template %q, must end with _test.go", template)
+	}
+	placeholders := []string{"{file}", "{package}"}
+	if strings.HasPrefix(template, "/") {
+		placeholders = append(placeholders, "{dir}")
+	}
+	rest := template
+	for _, p := range placeholders {
+		rest = strings.ReplaceAll(rest, p, "")
+	}
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("invalid test file template %q, only %s may be used", template, strings.Join(placeholders, ", "))
+	}
+	for seg := range strings.SplitSeq(strings.TrimPrefix(template, "/"), "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("invalid test file template %q, path segment %q not supported", template, seg)
+		}
+	}
+	return nil
+}
+
 // setOne updates a field of o based on the name and value.
 //
 // The applied result describes the counter values to be updated as a result of
@@ -1111,14 +1281,7 @@ func (o *Options) setOne(name string, value any) (applied []CounterPath, _ error
 			DefinitionShortcut)
 
 	case "analyses":
-		counts, err := setBoolMap(&o.Analyses, value)
-		if err != nil {
-			return nil, err
-		}
-		if o.Analyses["fieldalignment"] {
-			return counts, &SoftError{"the 'fieldalignment' analyzer was removed in gopls/v0.17.0; instead, hover over struct fields to see size/offset information (https://go.dev/issue/66861)"}
-		}
-		return counts, nil
+		return setBoolMap(&o.Analyses, value)
 
 	case "hints":
 		return setBoolMap(&o.Hints, value)
@@ -1170,6 +1333,43 @@ func (o *Options) setOne(name string, value any) (applied []CounterPath, _ error
 	case "gofumpt":
 		return setBool(&o.Gofumpt, value)
 
+	case "structTagCase":
+		return setEnum(&o.StructTagCase, value,
+			SnakeCase,
+			CamelCase,
+			KebabCase)
+
+	case "structTagOmitEmpty":
+		return setBool(&o.StructTagOmitEmpty, value)
+
+	case "testFileTemplate":
+		template, err := asString(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateTestFileTemplate(template); err != nil {
+			return nil, err
+		}
+		o.TestFileTemplate = template
+		return nil, nil
+
+	case "testComments":
+		return setEnum(&o.TestComments, value,
+			TestCommentsFull,
+			TestCommentsMinimal,
+			TestCommentsNone)
+
+	case "testTodoMarker":
+		marker, err := asString(value)
+		if err != nil {
+			return nil, err
+		}
+		if marker == "" || strings.ContainsAny(marker, "\r\n") {
+			return nil, fmt.Errorf("invalid test TODO marker %q, must be a non-empty single line", marker)
+		}
+		o.TestTodoMarker = marker
+		return nil, nil
+
 	case "completeFunctionCalls":
 		return setBool(&o.CompleteFunctionCalls, value)
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/tools/gopls/internal/clonetest"
+	"golang.org/x/tools/gopls/internal/protocol"
 	. "golang.org/x/tools/gopls/internal/settings"
 )
 
@@ -202,6 +203,67 @@ func TestOptions_Set(t *testing.T) {
 				return o.Vulncheck == ModeVulncheckImports
 			},
 		},
+		{
+			name:  "testFileTemplate",
+			value: "/test/{dir}/{file}_test.go",
+			check: func(o Options) bool {
+				return o.TestFileTemplate == "/test/{dir}/{file}_test.go"
+			},
+		},
+		{
+			name:      "testFileTemplate",
+			value:     "{dir}/{file}_test.go",
+			wantError: true,
+			check: func(o Options) bool {
+				return o.TestFileTemplate == ""
+			},
+		},
+		{
+			name:      "testFileTemplate",
+			value:     "{file}_tests.go",
+			wantError: true,
+			check: func(o Options) bool {
+				return o.TestFileTemplate == ""
+			},
+		},
+		{
+			name:      "testFileTemplate",
+			value:     "../{file}_test.go",
+			wantError: true,
+			check: func(o Options) bool {
+				return o.TestFileTemplate == ""
+			},
+		},
+		{
+			name:  "testComments",
+			value: "minimal",
+			check: func(o Options) bool {
+				return o.TestComments == TestCommentsMinimal
+			},
+		},
+		{
+			name:      "testComments",
+			value:     "terse",
+			wantError: true,
+			check: func(o Options) bool {
+				return o.TestComments == ""
+			},
+		},
+		{
+			name:  "testTodoMarker",
+			value: "FIXME(owner):",
+			check: func(o Options) bool {
+				return o.TestTodoMarker == "FIXME(owner):"
+			},
+		},
+		{
+			name:      "testTodoMarker",
+			value:     "",
+			wantError: true,
+			check: func(o Options) bool {
+				return o.TestTodoMarker == ""
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -245,3 +307,35 @@ func TestOptions_Clone(t *testing.T) {
 		t.Errorf("Mutating clone mutated the original (-want +got):\n%s", diff)
 	}
 }
+
+func TestClientCodeActionKind(t *testing.T) {
+	tests := []struct {
+		valueSet []protocol.CodeActionKind
+		want     protocol.CodeActionKind
+	}{
+		{nil, AddTest},
+		{[]protocol.CodeActionKind{""}, AddTest},
+		{[]protocol.CodeActionKind{"quickfix", "source"}, AddTest},
+		{[]protocol.CodeActionKind{"source.generate"}, AddTest},
+		{[]protocol.CodeActionKind{"source.generate.mock"}, AddTest},
+		{[]protocol.CodeActionKind{"quickfix", "refactor"}, "source.addTest"},
+		{[]protocol.CodeActionKind{"source.organizeImports", "source.generated"}, "source.addTest"},
+	}
+	for _, test := range tests {
+		opts := DefaultOptions()
+		opts.CodeActionKinds = test.valueSet
+		if got := opts.ClientCodeActionKind(AddTest); got != test.want {
+			t.Errorf("ClientCodeActionKind(%s) with valueSet %q = %s, want %s", AddTest, test.valueSet, got, test.want)
+		}
+		if got := opts.ClientCodeActionKind(GoDoc); got != GoDoc {
+			t.Errorf("ClientCodeActionKind(%s) with valueSet %q = %s, want it unchanged", GoDoc, test.valueSet, got)
+		}
+	}
+
+	if got := CanonicalCodeActionKind("source.addMock"); got != AddMock {
+		t.Errorf("CanonicalCodeActionKind(source.addMock) = %s, want %s", got, AddMock)
+	}
+	if got := CanonicalCodeActionKind(GoDoc); got != GoDoc {
+		t.Errorf("CanonicalCodeActionKind(%s) = %s, want it unchanged", GoDoc, got)
+	}
+}
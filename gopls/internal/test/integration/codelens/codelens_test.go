@@ -7,6 +7,7 @@ package codelens
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/gopls/internal/server"
@@ -406,3 +407,78 @@ func Foo() {
 		)
 	})
 }
+
+func TestBenchmarkCodeLens(t *testing.T) {
+	const workspace = `
+-- go.mod --
+module example.com
+
+go 1.22
+-- a/a.go --
+package a
+
+func Sum(xs []int) (sum int) {
+	for _, x := range xs {
+		sum += x
+	}
+	return sum
+}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func BenchmarkSum(b *testing.B) {
+	xs := make([]int, 100)
+	for range b.N {
+		Sum(xs)
+	}
+}
+`
+	WithOptions(
+		Settings{"codelenses": map[string]bool{string(settings.CodeLensBenchmark): true}},
+		EnvVars{"GOFLAGS": "-benchtime=10x"}, // keep the runs short
+		Modes(Default),
+	).Run(t, workspace, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a_test.go")
+		title := func() string {
+			t.Helper()
+			for _, lens := range env.CodeLens("a/a_test.go") {
+				if lens.Command.Command == command.RunBenchmark.String() {
+					return lens.Command.Title
+				}
+			}
+			t.Fatal("no benchmark code lens")
+			return ""
+		}
+		if got, want := title(), "run and record benchmark"; got != want {
+			t.Errorf("initial code lens title: got %q, want %q", got, want)
+		}
+
+		// The first run has nothing to compare with.
+		var result command.RunBenchmarkResult
+		env.ExecuteCodeLensCommand("a/a_test.go", command.RunBenchmark, &result)
+		if len(result.Metrics) != 3 {
+			t.Fatalf("first run: got metrics %+v, want ns/op, B/op, and allocs/op", result.Metrics)
+		}
+		for _, m := range result.Metrics {
+			if m.Name != "BenchmarkSum" || m.Old != nil || m.New.N != 5 {
+				t.Errorf("first run: unexpected metric %+v", m)
+			}
+		}
+		if got := title(); !strings.HasPrefix(got, "rerun benchmark: ") || !strings.Contains(got, "ns/op") || strings.Contains(got, "(") {
+			t.Errorf("code lens title after the first run: got %q, want medians", got)
+		}
+
+		// The second run is compared with the first.
+		env.ExecuteCodeLensCommand("a/a_test.go", command.RunBenchmark, &result)
+		for _, m := range result.Metrics {
+			if m.Old == nil {
+				t.Errorf("second run: metric %+v has no previous measurements", m)
+			}
+		}
+		if got := title(); !strings.Contains(got, "0 allocs/op (~)") {
+			t.Errorf("code lens title after the second run: got %q, want a comparison", got)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"golang.org/x/tools/gopls/internal/protocol"
 	. "golang.org/x/tools/gopls/internal/test/integration"
 )
 
@@ -760,3 +761,190 @@ func _() {
 		}
 	})
 }
+
+func TestPostfixTestSnippet(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+
+-- foo.go --
+package foo
+
+import "context"
+
+func Add(a, b int) int { return a + b }
+
+type Stack struct{ items []int }
+
+func NewStack() *Stack { return new(Stack) }
+
+func (s *Stack) Pop(ctx context.Context) (int, error) { return 0, nil }
+`
+
+	cases := []struct {
+		name          string
+		before, after string
+	}{
+		{
+			name: "func",
+			before: `
+package foo
+
+Add.te
+`,
+			after: `
+package foo
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		// Named input parameters for target function.
+		a    int
+		b    int
+		want int
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Add(tt.a, tt.b)
+			// TODO: update the condition below to compare got with tt.want.
+			if true {
+				t.Errorf("Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+`,
+		},
+		{
+			name: "method",
+			before: `
+package foo
+
+import "testing"
+
+Stack.Pop.te
+`,
+			after: `
+package foo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStack_Pop(t *testing.T) {
+	tests := []struct {
+		name    string // description of this test case
+		want    int
+		wantErr bool
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStack()
+			got, gotErr := s.Pop(context.Background())
+			if gotErr != nil {
+				if !tt.wantErr {
+					t.Errorf("Pop() failed: %v", gotErr)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Fatal("Pop() succeeded unexpectedly")
+			}
+			// TODO: update the condition below to compare got with tt.want.
+			if true {
+				t.Errorf("Pop() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+`,
+		},
+		{
+			name: "external",
+			before: `
+package foo_test
+
+import "mod.com"
+
+foo.Add.te
+`,
+			after: `
+package foo_test
+
+import (
+	"testing"
+
+	"mod.com"
+)
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		// Named input parameters for target function.
+		a    int
+		b    int
+		want int
+	}{
+		// TODO: Add test cases.
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := foo.Add(tt.a, tt.b)
+			// TODO: update the condition below to compare got with tt.want.
+			if true {
+				t.Errorf("Add() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+`,
+		},
+	}
+
+	r := WithOptions(
+		Settings{
+			"experimentalPostfixCompletions": true,
+		},
+	)
+	r.Run(t, files, func(t *testing.T, env *Env) {
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				c.before = strings.TrimLeft(c.before, "\n")
+				c.after = strings.TrimLeft(c.after, "\n")
+
+				filename := c.name + "_test.go"
+				env.CreateBuffer(filename, c.before)
+
+				loc := env.RegexpSearch(filename, `\.te()`)
+				completions := env.Completion(loc)
+				var item *protocol.CompletionItem
+				for i := range completions.Items {
+					if completions.Items[i].Label == "test!" {
+						item = &completions.Items[i]
+					}
+				}
+				if item == nil {
+					t.Fatalf("no test! completion among %v", completions.Items)
+				}
+
+				env.AcceptCompletion(loc, *item)
+
+				// The fake editor inserts the snippet without
+				// interpreting its escapes.
+				buf := strings.ReplaceAll(env.BufferText(filename), `\}`, "}")
+				if buf != c.after {
+					t.Errorf("\nGOT:\n%s\nEXPECTED:\n%s", buf, c.after)
+				}
+			})
+		}
+	})
+}
@@ -9,9 +9,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/server"
 	. "golang.org/x/tools/gopls/internal/test/integration"
 	"golang.org/x/tools/gopls/internal/test/integration/fake"
@@ -2232,3 +2234,52 @@ func Foo() {}
 		env.AfterChange(NoDiagnostics())
 	})
 }
+
+func TestDiagnoseAppliedEdits(t *testing.T) {
+	// This test checks that the changes of an edit that gopls applies in
+	// the client, here a change of signature and the calls it rewrites
+	// in other files, are diagnosed once the edit is applied, including
+	// in the packages that depend on them.
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/a.go --
+package a
+
+type T struct{}
+
+func (T) M(x int) {}
+-- a/b.go --
+package a
+
+func _() { T{}.M(1) }
+-- c/c.go --
+package c
+
+import "mod.com/a"
+
+type I interface{ M(int, string) }
+
+var _ I = a.T{}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.OpenFile("c/c.go")
+		env.AfterChange(Diagnostics(env.AtRegexp("c/c.go", "a.T{}")))
+
+		cmd := command.NewChangeSignatureCommand("", command.ChangeSignatureArgs{
+			Location:  env.RegexpSearch("a/a.go", `func \(T\) M`),
+			NewParams: []command.ChangeSignatureParam{{OldIndex: 0}, {NewField: "y string"}},
+		})
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   cmd.Command,
+			Arguments: cmd.Arguments,
+		}, nil)
+		if got, want := env.BufferText("a/b.go"), `M(1, "")`; !strings.Contains(got, want) {
+			t.Fatalf("a/b.go does not contain %s:\n%s", want, got)
+		}
+		env.AfterChange(NoDiagnostics())
+	})
+}
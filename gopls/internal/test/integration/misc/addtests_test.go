@@ -0,0 +1,240 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestAddTests(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func F(x int) int { return x }
+
+func G(x int) int { return x }
+
+type T int
+
+func (T) M() {}
+
+func init() {}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestF(t *testing.T) {}
+-- a/b.go --
+package a
+
+func H(s string) string { return s }
+
+func unexported() {}
+-- a/b_test.go --
+package a
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.AddTests.String(),
+			Arguments: command.MustMarshalArgs(command.AddTestsArgs{URI: env.Sandbox.Workdir.URI("a")}),
+		}, nil)
+
+		for file, want := range map[string]struct{ tests, notTests []string }{
+			"a/a_test.go": {[]string{"func TestF(", "func TestG(", "func TestT_M("}, []string{"func TestF(t *testing.T) {\n\ttests", "Test_init"}},
+			"a/b_test.go": {[]string{"func TestH(", "func Test_unexported("}, nil},
+		} {
+			env.OpenFile(file)
+			got := env.BufferText(file)
+			for _, test := range want.tests {
+				if !strings.Contains(got, test) {
+					t.Errorf("%s does not contain %q:\n%s", file, test, got)
+				}
+			}
+			for _, test := range want.notTests {
+				if strings.Contains(got, test) {
+					t.Errorf("%s contains %q:\n%s", file, test, got)
+				}
+			}
+		}
+	})
+}
+
+// TestAddTestsPackageTemplate checks that, when the test file template
+// maps several files to one test file, the tests of all of them are
+// added to it at once.
+func TestAddTestsPackageTemplate(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func F(x int) int { return x }
+-- a/b.go --
+package a
+
+import "strings"
+
+func G(s string) string { return strings.ToUpper(s) }
+-- a/c.go --
+package a
+
+func H() {}
+-- a/a_test.go --
+package a
+`
+	WithOptions(
+		Settings{"testFileTemplate": "{package}_test.go"},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		var result command.AddTestsResult
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.AddTests.String(),
+			Arguments: command.MustMarshalArgs(command.AddTestsArgs{URI: env.Sandbox.Workdir.URI("a")}),
+		}, &result)
+		if result.Processed != 3 || result.Skipped != 0 {
+			t.Errorf("AddTests processed %d functions and skipped %d, want 3 and 0", result.Processed, result.Skipped)
+		}
+
+		env.OpenFile("a/a_test.go")
+		got := env.BufferText("a/a_test.go")
+		for _, want := range []string{"func TestF(", "func TestG(", "func TestH("} {
+			if strings.Count(got, want) != 1 {
+				t.Errorf("a/a_test.go does not contain %q once:\n%s", want, got)
+			}
+		}
+		if strings.Count("\n"+got, "\npackage ") != 1 {
+			t.Errorf("a/a_test.go has more than one package clause:\n%s", got)
+		}
+	})
+}
+
+// TestAddTestsSharedHelpers checks that the tests of the methods of a
+// type declared in several files all use the helper that the test
+// package declares to construct the receivers, which AddTests finds
+// once for the package.
+func TestAddTestsSharedHelpers(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/server.go --
+package a
+
+type Server struct{}
+
+func NewServer() *Server { return &Server{} }
+
+func (s *Server) Start() error { return nil }
+-- a/client.go --
+package a
+
+func (s *Server) Connect(addr string) int { return 0 }
+-- a/helper_test.go --
+package a
+
+import "testing"
+
+func newTestServer(t testing.TB) *Server { return NewServer() }
+-- a/server_test.go --
+package a
+-- a/client_test.go --
+package a
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/server.go")
+		var result command.AddTestsResult
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.AddTests.String(),
+			Arguments: command.MustMarshalArgs(command.AddTestsArgs{URI: env.Sandbox.Workdir.URI("a")}),
+		}, &result)
+		if result.Processed != 3 || result.Skipped != 0 {
+			t.Errorf("AddTests processed %d functions and skipped %d, want 3 and 0", result.Processed, result.Skipped)
+		}
+
+		for file, test := range map[string]string{
+			"a/server_test.go": "func TestServer_Start(",
+			"a/client_test.go": "func TestServer_Connect(",
+		} {
+			env.OpenFile(file)
+			got := env.BufferText(file)
+			if !strings.Contains(got, test) || !strings.Contains(got, "s := newTestServer(t)") {
+				t.Errorf("%s does not contain %q calling newTestServer:\n%s", file, test, got)
+			}
+			if strings.Contains(got, "func newTestServer") {
+				t.Errorf("%s declares another newTestServer:\n%s", file, got)
+			}
+		}
+	})
+}
+
+func TestAddTestsRequireWorkspaceModule(t *testing.T) {
+	const files = `
+-- go.work --
+go 1.22
+
+use (
+	./a
+	./testutil
+)
+-- a/go.mod --
+module example.com/a
+
+go 1.22
+-- a/a.go --
+package a
+
+import "example.com/testutil"
+
+func F(s testutil.S) int { return 0 }
+-- a/a_test.go --
+package a
+-- testutil/go.mod --
+module example.com/testutil
+
+go 1.22
+-- testutil/testutil.go --
+package testutil
+
+type S struct{}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.AddTests.String(),
+			Arguments: command.MustMarshalArgs(command.AddTestsArgs{URI: env.Sandbox.Workdir.URI("a")}),
+		}, nil)
+
+		env.OpenFile("a/a_test.go")
+		if got := env.BufferText("a/a_test.go"); !strings.Contains(got, `"example.com/testutil"`) {
+			t.Fatalf("a/a_test.go does not import testutil:\n%s", got)
+		}
+		env.OpenFile("a/go.mod")
+		got := env.BufferText("a/go.mod")
+		for _, want := range []string{
+			"require example.com/testutil v0.0.0-00010101000000-000000000000",
+			"replace example.com/testutil => ../testutil",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("a/go.mod does not contain %q:\n%s", want, got)
+			}
+		}
+	})
+}
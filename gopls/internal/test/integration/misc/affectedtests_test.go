@@ -0,0 +1,143 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestAffectedTests(t *testing.T) {
+	const files = `
+-- go.mod --
+module example.com
+
+go 1.21
+-- a/a.go --
+package a
+
+type T int
+
+func Parse() { helper() }
+
+func helper() {}
+
+func internal() { unexported() }
+
+func unexported() {}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestParse(t *testing.T) { Parse() }
+
+func TestInternal(t *testing.T) { internal() }
+
+func TestOther(t *testing.T) {}
+
+func BenchmarkParse(b *testing.B) { Parse() }
+-- b/b.go --
+package b
+
+import "example.com/a"
+
+func B() { a.Parse() }
+-- b/b_test.go --
+package b
+
+import "testing"
+
+func TestB(t *testing.T) { B() }
+-- c/c.go --
+package c
+-- c/c_test.go --
+package c
+
+import "testing"
+
+func TestC(t *testing.T) {}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.OpenFile("a/a_test.go")
+
+		affected := func(args command.AffectedTestsArgs) command.AffectedTestsResult {
+			t.Helper()
+			args.URI = env.Sandbox.Workdir.URI("a/a.go")
+			cmd := command.NewAffectedTestsCommand("", args)
+			var result command.AffectedTestsResult
+			env.ExecuteCommand(&protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			}, &result)
+			return result
+		}
+		summary := func(result command.AffectedTestsResult) []string {
+			var got []string
+			for _, pkg := range result.Packages {
+				got = append(got, fmt.Sprintf("%s %v all=%t", pkg.PkgPath, pkg.Tests, pkg.All))
+			}
+			return got
+		}
+		check := func(args command.AffectedTestsArgs, want ...string) {
+			t.Helper()
+			if diff := cmp.Diff(want, summary(affected(args))); diff != "" {
+				t.Errorf("affected tests of %v: unexpected result (-want +got):\n%s", args.Files, diff)
+			}
+		}
+		files := func(names ...string) command.AffectedTestsArgs {
+			var args command.AffectedTestsArgs
+			for _, name := range names {
+				args.Files = append(args.Files, env.Sandbox.Workdir.URI(name))
+			}
+			return args
+		}
+
+		// No unsaved changes.
+		check(command.AffectedTestsArgs{})
+
+		// An unexported function called only within its package,
+		// and a test.
+		env.RegexpReplace("a/a.go", `func unexported\(\) {}`, `func unexported() { println() }`)
+		env.RegexpReplace("a/a_test.go", `func TestOther\(t \*testing.T\) {}`, `func TestOther(t *testing.T) { t.Log() }`)
+		check(command.AffectedTestsArgs{}, "example.com/a [TestInternal TestOther] all=false")
+
+		// A function called by an exported function affects the
+		// importers.
+		env.RegexpReplace("a/a.go", `func helper\(\) {}`, `func helper() { println() }`)
+		check(command.AffectedTestsArgs{},
+			"example.com/a [TestParse TestInternal TestOther] all=true",
+			"example.com/b [TestB] all=true")
+
+		// A whole file, with a type.
+		check(files("a/a.go"),
+			"example.com/a [TestParse TestInternal TestOther] all=true",
+			"example.com/b [TestB] all=true")
+
+		// A module file.
+		check(files("go.mod"),
+			"example.com/a [TestParse TestInternal TestOther] all=true",
+			"example.com/b [TestB] all=true",
+			"example.com/c [TestC] all=true")
+
+		// Saved changes are not unsaved changes.
+		env.SaveBuffer("a/a.go")
+		env.SaveBuffer("a/a_test.go")
+		check(command.AffectedTestsArgs{})
+
+		// Running the affected tests, which requires saved files.
+		args := files("c/c_test.go")
+		args.Run = true
+		if result := affected(args); len(result.Packages) != 1 || result.Failed != 0 {
+			t.Errorf("running the affected tests of c: got %+v, want 1 package and no failures", result)
+		}
+	})
+}
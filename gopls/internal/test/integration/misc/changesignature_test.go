@@ -0,0 +1,74 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestChangeSignatureResults(t *testing.T) {
+	// This test checks that the gopls.change_signature command rejects
+	// changes to the results, which are not yet supported, while
+	// accepting the identity transformation of the results.
+
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func F(x, y int) (int, error) {
+	return x + y, nil
+}
+
+func _() {
+	F(1, 2)
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		loc := env.RegexpSearch("a/a.go", `func F`)
+		swap := []command.ChangeSignatureParam{{OldIndex: 1}, {OldIndex: 0}}
+
+		changeSignature := func(results []command.ChangeSignatureParam) (*protocol.WorkspaceEdit, error) {
+			cmd := command.NewChangeSignatureCommand("", command.ChangeSignatureArgs{
+				Location:     loc,
+				NewParams:    swap,
+				NewResults:   results,
+				ResolveEdits: true,
+			})
+			var result *protocol.WorkspaceEdit
+			err := env.Editor.ExecuteCommand(env.Ctx, &protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			}, &result)
+			return result, err
+		}
+
+		if edit, err := changeSignature([]command.ChangeSignatureParam{{OldIndex: 0}, {OldIndex: 1}}); err != nil {
+			t.Errorf("with unchanged results: %v", err)
+		} else if edit == nil || len(edit.DocumentChanges) == 0 {
+			t.Errorf("with unchanged results: got no edits")
+		}
+
+		for _, results := range [][]command.ChangeSignatureParam{
+			{{OldIndex: 0}},                      // drop the error
+			{{OldIndex: 1}, {OldIndex: 0}},       // swap the results
+			{{OldIndex: 0}, {NewField: "error"}}, // replace the error
+		} {
+			_, err := changeSignature(results)
+			if err == nil || !strings.Contains(err.Error(), "changing results not yet supported") {
+				t.Errorf("with results %v: got error %v, want changing results not yet supported", results, err)
+			}
+		}
+	})
+}
@@ -7,10 +7,12 @@ package misc
 import (
 	"fmt"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	"golang.org/x/tools/gopls/internal/settings"
 	. "golang.org/x/tools/gopls/internal/test/integration"
 )
@@ -65,6 +67,7 @@ func g() {}
 
 		check("src/a.go",
 			settings.AddTest,
+			settings.AddIntegrationTest,
 			settings.GoAssembly,
 			settings.GoDoc,
 			settings.GoFreeSymbols,
@@ -130,3 +133,161 @@ func Func() int { return 0 }
 		}
 	})
 }
+
+// TestResolveGeneratorActions checks that the code actions that
+// generate code are returned unresolved to a client that supports
+// codeAction/resolve, and that their edits are computed on resolve.
+func TestResolveGeneratorActions(t *testing.T) {
+	const src = `
+-- go.mod --
+module example.com
+go 1.22
+
+-- a/a.go --
+package a
+
+func F(x int) int { return x }
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
+`
+	Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		for _, test := range []struct {
+			re, title, file string
+		}{
+			{"func F", "Add test for F", "a/a_test.go"},
+			{"const", "Generate String method for Color", "a/color_string.go"},
+		} {
+			loc := env.RegexpSearch("a/a.go", test.re)
+			var action *protocol.CodeAction
+			for _, act := range env.CodeAction(loc, nil, protocol.CodeActionInvoked) {
+				if act.Title == test.title {
+					action = &act
+					break
+				}
+			}
+			if action == nil {
+				t.Fatalf("no %q code action", test.title)
+			}
+			if action.Edit != nil || action.Command != nil || action.Data == nil {
+				t.Fatalf("%q: got Edit=%v Command=%v Data=%v, want unresolved action", test.title, action.Edit, action.Command, action.Data)
+			}
+			resolved, err := env.Editor.Server.ResolveCodeAction(env.Ctx, action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resolved.Edit == nil || !slices.ContainsFunc(resolved.Edit.DocumentChanges, func(change protocol.DocumentChange) bool {
+				return change.CreateFile != nil && change.CreateFile.URI == env.Sandbox.Workdir.URI(test.file)
+			}) {
+				t.Errorf("%q: resolved edit does not create %s: %v", test.title, test.file, resolved.Edit)
+			}
+		}
+	})
+}
+
+// TestAddTestLegacyArgs checks that the gopls.add_test command still
+// accepts the bare location that was its argument before gopls v0.19,
+// and applies its edits.
+func TestAddTestLegacyArgs(t *testing.T) {
+	const src = `
+-- go.mod --
+module example.com
+go 1.22
+
+-- a/a.go --
+package a
+
+func F(x int) int { return x }
+
+-- a/a_test.go --
+package a_test
+`
+	Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.AddTest.String(),
+			Arguments: command.MustMarshalArgs(env.RegexpSearch("a/a.go", "func F")),
+		}, nil)
+		env.OpenFile("a/a_test.go")
+		if got := env.BufferText("a/a_test.go"); !strings.Contains(got, "func TestF(") {
+			t.Errorf("a/a_test.go does not contain TestF:\n%s", got)
+		}
+	})
+}
+
+// This test checks that "Convert assertions to testify" requires
+// testify in a module that does not yet require it.
+func TestConvertAssertionsRequiresTestify(t *testing.T) {
+	const proxy = `
+-- github.com/stretchr/testify@v1.0.0/go.mod --
+module github.com/stretchr/testify
+
+go 1.18
+-- github.com/stretchr/testify@v1.0.0/assert/assert.go --
+package assert
+
+type TestingT interface{ Errorf(string, ...any) }
+
+func Equal(t TestingT, expected, actual any, msgAndArgs ...any) bool { return true }
+-- github.com/stretchr/testify@v1.0.0/require/require.go --
+package require
+
+type TestingT interface {
+	Errorf(string, ...any)
+	FailNow()
+}
+
+func NoError(t TestingT, err error, msgAndArgs ...any) {}
+`
+	const src = `
+-- go.mod --
+module example.com
+
+go 1.22
+-- go.sum --
+-- a/a.go --
+package a
+
+func F() int { return 1 }
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestF(t *testing.T) {
+	got := F()
+	if got != 1 {
+		t.Errorf("F() = %d, want 1", got)
+	}
+}
+`
+	WithOptions(ProxyFiles(proxy)).Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a_test.go")
+		env.OpenFile("go.mod")
+		env.OpenFile("go.sum")
+		loc := env.RegexpSearch("a/a_test.go", "TestF")
+		actions, err := env.Editor.CodeActions(env.Ctx, loc, nil, settings.ConvertAssertionsTestify)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actions) != 1 {
+			t.Fatalf("got %d Convert assertions to testify actions, want 1", len(actions))
+		}
+		env.ApplyCodeAction(actions[0])
+
+		if got, want := env.BufferText("a/a_test.go"), "assert.Equal(t, 1, got)"; !strings.Contains(got, want) {
+			t.Errorf("a/a_test.go does not contain %q:\n%s", want, got)
+		}
+		if got, want := env.BufferText("go.mod"), "github.com/stretchr/testify v1.0.0"; !strings.Contains(got, want) {
+			t.Errorf("go.mod does not contain %q:\n%s", want, got)
+		}
+		if got, want := env.BufferText("go.sum"), "github.com/stretchr/testify v1.0.0"; !strings.Contains(got, want) {
+			t.Errorf("go.sum does not contain %q:\n%s", want, got)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestDocumentCoverage(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestAbs(t *testing.T) {
+	if Abs(1) != 1 {
+		t.Fatal("Abs(1) != 1")
+	}
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		coverage := func() command.DocumentCoverageResult {
+			t.Helper()
+			cmd := command.NewDocumentCoverageCommand("", protocol.TextDocumentIdentifier{URI: env.Sandbox.Workdir.URI("a/a.go")})
+			var result command.DocumentCoverageResult
+			env.ExecuteCommand(&protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			}, &result)
+			return result
+		}
+		// texts returns the text of each range, without spaces.
+		texts := func(rngs []protocol.Range) []string {
+			t.Helper()
+			content := []byte(env.BufferText("a/a.go"))
+			m := protocol.NewMapper(env.Sandbox.Workdir.URI("a/a.go"), content)
+			var texts []string
+			for _, rng := range rngs {
+				start, end, err := m.RangeOffsets(rng)
+				if err != nil {
+					t.Fatal(err)
+				}
+				texts = append(texts, strings.Join(strings.Fields(string(content[start:end])), " "))
+			}
+			return texts
+		}
+		check := func(got []protocol.Range, want ...string) {
+			t.Helper()
+			if diff := cmp.Diff(want, texts(got)); diff != "" {
+				t.Errorf("unexpected ranges (-want +got):\n%s", diff)
+			}
+		}
+
+		if result := coverage(); len(result.Covered)+len(result.Uncovered) > 0 {
+			t.Fatalf("coverage before running the tests: %+v", result)
+		}
+
+		// Run the test with coverage.
+		cmd := command.NewRunTestsCommand("", command.RunTestsArgs{
+			URI:      env.Sandbox.Workdir.URI("a/a_test.go"),
+			Tests:    []string{"TestAbs"},
+			Coverage: true,
+		})
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   cmd.Command,
+			Arguments: cmd.Arguments,
+		}, nil)
+		result := coverage()
+		covered, uncovered := texts(result.Covered), texts(result.Uncovered)
+		if len(covered) != 2 || !strings.Contains(covered[0], "if x < 0") || !strings.Contains(covered[1], "return x") {
+			t.Errorf("covered ranges: got %q, want the if statement and return x", covered)
+		}
+		if len(uncovered) != 1 || !strings.Contains(uncovered[0], "return -x") {
+			t.Errorf("uncovered ranges: got %q, want return -x", uncovered)
+		}
+
+		// The ranges follow the edits, without the edited statements.
+		env.RegexpReplace("a/a.go", "package a", "package a\n\n// Abs returns |x|.")
+		env.RegexpReplace("a/a.go", "return -x", "return - x")
+		result = coverage()
+		check(result.Covered, covered...)
+		check(result.Uncovered)
+	})
+}
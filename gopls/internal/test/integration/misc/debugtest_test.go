@@ -0,0 +1,122 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestDebugTestConfig(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestTable(t *testing.T) {
+	t.Run("zero case", func(t *testing.T) {
+		_ = Abs(0)
+	})
+}
+-- a/a_integration_test.go --
+//go:build integration
+
+package a
+
+import "testing"
+
+func TestReal(t *testing.T) {}
+`
+	WithOptions(
+		EnvVars{"FOO": "bar"},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.OpenFile("a/a_test.go")
+		debugTest := func(args command.DebugTestArgs) command.DebugTestResult {
+			t.Helper()
+			var result command.DebugTestResult
+			env.ExecuteCommand(&protocol.ExecuteCommandParams{
+				Command:   command.DebugTest.String(),
+				Arguments: command.MustMarshalArgs(args),
+			}, &result)
+			return result
+		}
+		dir := env.Sandbox.Workdir.URI("a").Path()
+		ignoreEnv := cmpopts.IgnoreFields(command.DebugTestResult{}, "Env")
+
+		// The subtest at the location of its name.
+		got := debugTest(command.DebugTestArgs{Location: env.RegexpSearch("a/a_test.go", `"zero`)})
+		want := command.DebugTestResult{
+			PackagePath: "mod.com/a",
+			Dir:         dir,
+			Test:        "TestTable",
+			Run:         "^TestTable$/^zero_case$",
+			Args:        []string{"-test.run", "^TestTable$/^zero_case$"},
+		}
+		if diff := cmp.Diff(want, got, ignoreEnv); diff != "" {
+			t.Errorf("DebugTest(subtest location) mismatch (-want +got):\n%s", diff)
+		}
+		if !slices.Contains(got.Env, "FOO=bar") {
+			t.Errorf("DebugTest(subtest location): environment %q lacks FOO=bar", got.Env)
+		}
+
+		// The test enclosing the location.
+		got = debugTest(command.DebugTestArgs{Location: env.RegexpSearch("a/a_test.go", `Abs\(0\)`)})
+		if got.Test != "TestTable" || got.Run != "^TestTable$" {
+			t.Errorf("DebugTest(test location) = %+v, want TestTable", got)
+		}
+
+		// An integration test requires its build tag.
+		env.OpenFile("a/a_integration_test.go")
+		got = debugTest(command.DebugTestArgs{URI: env.Sandbox.Workdir.URI("a/a_integration_test.go"), Test: "TestReal"})
+		if diff := cmp.Diff([]string{"-tags=integration"}, got.BuildFlags); diff != "" {
+			t.Errorf("DebugTest(integration test) build flags mismatch (-want +got):\n%s", diff)
+		}
+
+		// Adding a test may return the configuration of the new test.
+		var result command.AddTestResult
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command: command.AddTest.String(),
+			Arguments: command.MustMarshalArgs(command.AddTestArgs{
+				Location:     env.RegexpSearch("a/a.go", "Abs"),
+				Debug:        true,
+				ResolveEdits: true,
+			}),
+		}, &result)
+		if result.Edit == nil {
+			t.Errorf("AddTest: no edits")
+		}
+		want = command.DebugTestResult{
+			PackagePath: "mod.com/a",
+			Dir:         dir,
+			Test:        "TestAbs",
+			Run:         "^TestAbs$",
+			Args:        []string{"-test.run", "^TestAbs$"},
+		}
+		if diff := cmp.Diff(&want, result.Debug, ignoreEnv); diff != "" {
+			t.Errorf("AddTest debug configuration mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -568,6 +568,39 @@ func TestGoToEmbedDefinition(t *testing.T) {
 	})
 }
 
+func TestGoToPackageFileDefinition(t *testing.T) {
+	const src = `
+-- go.mod --
+module mod.com
+
+go 1.18
+
+-- a_test.go --
+package a
+
+import (
+	"os"
+	"testing"
+)
+
+func TestA(t *testing.T) {
+	_, _ = os.ReadFile("testdata/a.golden")
+}
+
+-- testdata/a.golden --
+A
+`
+	Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("a_test.go")
+
+		loc := env.GoToDefinition(env.RegexpSearch("a_test.go", `a\.golden`))
+		name := env.Sandbox.Workdir.URIToPath(loc.URI)
+		if want := "testdata/a.golden"; name != want {
+			t.Errorf("GoToDefinition: got file %q, want %q", name, want)
+		}
+	})
+}
+
 func TestDefinitionOfErrorErrorMethod(t *testing.T) {
 	const src = `Regression test for a panic in definition of error.Error (of course).
 golang/go#64086
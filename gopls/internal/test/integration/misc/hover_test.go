@@ -12,6 +12,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
 	. "golang.org/x/tools/gopls/internal/test/integration"
 	"golang.org/x/tools/gopls/internal/test/integration/fake"
 )
@@ -488,6 +489,88 @@ func TestHoverEmbedDirective(t *testing.T) {
 	})
 }
 
+func TestHoverPackageFile(t *testing.T) {
+	const src = `
+-- go.mod --
+module mod.com
+
+go 1.18
+
+-- a_test.go --
+package a
+
+import (
+	"os"
+	"testing"
+)
+
+func TestA(t *testing.T) {
+	_, _ = os.ReadFile("testdata/a.golden")
+}
+
+-- testdata/a.golden --
+first line
+second line
+`
+	Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("a_test.go")
+
+		got, _ := env.Hover(env.RegexpSearch("a_test.go", `a\.golden`))
+		if got == nil {
+			t.Fatalf("hover over testdata file not found")
+		}
+		for _, want := range []string{"testdata/a.golden", "2 lines", "first line", "second line"} {
+			if !strings.Contains(got.Value, want) {
+				t.Errorf("hover: %q does not contain: %q", got.Value, want)
+			}
+		}
+	})
+}
+
+func TestHoverSubtestName(t *testing.T) {
+	const src = `
+-- go.mod --
+module mod.com
+
+go 1.18
+
+-- a_test.go --
+package a
+
+import "testing"
+
+func TestFoo(t *testing.T) {
+	t.Run("some name", func(t *testing.T) {})
+
+	tests := []struct {
+		name string
+		x    int
+	}{
+		{name: "a.b", x: 1},
+		{"it's", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {})
+	}
+}
+`
+	Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("a_test.go")
+		for _, test := range []struct {
+			re, want string
+		}{
+			{`some name`, `go test -run '^TestFoo$/^some_name$'`},
+			{`a\.b`, `go test -run '^TestFoo$/^a\.b$'`},
+			{`it's`, `go test -run '^TestFoo$/^it'\''s$'`},
+		} {
+			got, _ := env.Hover(env.RegexpSearch("a_test.go", test.re))
+			if got == nil || !strings.Contains(got.Value, test.want) {
+				t.Errorf("hover over %q: got %v, want %q", test.re, got, test.want)
+			}
+		}
+	})
+}
+
 func TestHoverBrokenImport_Issue60592(t *testing.T) {
 	const files = `
 -- go.mod --
@@ -714,3 +797,52 @@ func _() {
 		}
 	})
 }
+
+func TestHoverCoverage(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+-- cover.out --
+mode: set
+mod.com/a/a.go:3.21,4.11 1 1
+mod.com/a/a.go:4.11,6.3 1 0
+mod.com/a/a.go:7.2,7.10 1 1
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		loc := env.RegexpSearch("a/a.go", "Abs")
+		const want = "Test coverage: 60% (3 lines covered, 2 uncovered)"
+		if hover, _ := env.Hover(loc); strings.Contains(hover.Value, want) {
+			t.Errorf("hover before loading coverage: %q contains %q", hover.Value, want)
+		}
+
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.LoadCoverage.String(),
+			Arguments: command.MustMarshalArgs(command.URIArg{URI: env.Sandbox.Workdir.URI("cover.out")}),
+		}, nil)
+		if hover, _ := env.Hover(loc); !strings.Contains(hover.Value, want) {
+			t.Errorf("hover: %q does not contain %q", hover.Value, want)
+		}
+
+		// The coverage of an edited statement is discarded, and that
+		// of the others follows them.
+		env.RegexpReplace("a/a.go", "return x", "return +x")
+		env.RegexpReplace("a/a.go", "func Abs", "// Abs returns |x|.\nfunc Abs")
+		loc = env.RegexpSearch("a/a.go", "Abs\\(")
+		const wantEdited = "Test coverage: 50% (2 lines covered, 2 uncovered)"
+		if hover, _ := env.Hover(loc); !strings.Contains(hover.Value, wantEdited) {
+			t.Errorf("hover after edit: %q does not contain %q", hover.Value, wantEdited)
+		}
+	})
+}
@@ -902,6 +902,80 @@ func main() {
 	})
 }
 
+// TestRenameAcrossWorkspaceModules checks that renaming an exported
+// symbol updates the other modules of a go.work workspace, and warns
+// about packages outside the workspace that it cannot update.
+func TestRenameAcrossWorkspaceModules(t *testing.T) {
+	const proxy = `
+-- example.com/a@v1.0.0/go.mod --
+module example.com/a
+
+go 1.18
+-- example.com/a@v1.0.0/a.go --
+package a
+
+func Foo() {}
+-- example.com/b@v1.0.0/go.mod --
+module example.com/b
+
+go 1.18
+
+require example.com/a v1.0.0
+-- example.com/b@v1.0.0/b.go --
+package b
+
+import "example.com/a"
+
+func B() { a.Foo() }
+`
+	const files = `
+-- go.work --
+go 1.18
+
+use (
+	./a
+	./c
+)
+-- a/go.mod --
+module example.com/a
+
+go 1.18
+-- a/a.go --
+package a
+
+func Foo() {}
+-- c/go.mod --
+module example.com/c
+
+go 1.18
+
+require example.com/b v1.0.0
+-- c/c.go --
+package c
+
+import (
+	"example.com/a"
+	"example.com/b"
+)
+
+func C() {
+	a.Foo()
+	b.B()
+}
+`
+	WithOptions(
+		WriteGoSum("c"),
+		ProxyFiles(proxy),
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.Rename(env.RegexpSearch("a/a.go", "Foo"), "Bar")
+
+		env.RegexpSearch("a/a.go", "func Bar")
+		env.RegexpSearch("c/c.go", `a\.Bar\(\)`)
+		env.Await(ShownMessage("example.com/b"))
+	})
+}
+
 // checkTestdata checks that current buffer contents match their corresponding
 // expected content in the testdata directory.
 func checkTestdata(t *testing.T, env *Env) {
@@ -0,0 +1,86 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestStreamTestsResults(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.21
+-- a/a_test.go --
+package a
+
+import (
+	"testing"
+	"time"
+)
+
+func TestA(t *testing.T) {
+	t.Run("x", func(t *testing.T) {
+		t.Parallel()
+		time.Sleep(10 * time.Millisecond)
+		t.Log("hello x")
+	})
+	t.Run("y", func(t *testing.T) {
+		t.Parallel()
+		t.Error("bad y")
+	})
+}
+
+func TestB(t *testing.T) {
+	t.Skip("not now")
+}
+
+func TestC(t *testing.T) {}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a_test.go")
+		cmd, err := command.NewStreamTestsCommand("", command.StreamTestsArgs{
+			URI:   env.Sandbox.Workdir.URI("a/a_test.go"),
+			Tests: []string{"TestA", "TestB"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result command.StreamTestsResult
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   cmd.Command,
+			Arguments: cmd.Arguments,
+		}, &result)
+
+		if result.Passed != 1 || result.Failed != 2 || result.Skipped != 1 {
+			t.Errorf("StreamTests: got %d passed, %d failed, %d skipped, want 1, 2, 1", result.Passed, result.Failed, result.Skipped)
+		}
+		want := []command.PackageTestResult{{
+			Package: "mod.com/a",
+			Action:  "fail",
+			Tests: []command.TestResult{
+				{
+					Name:   "TestA",
+					Action: "fail",
+					Subtests: []command.TestResult{
+						{Name: "TestA/x", Action: "pass", Output: "    a_test.go:12: hello x\n"},
+						{Name: "TestA/y", Action: "fail", Output: "    a_test.go:16: bad y\n"},
+					},
+				},
+				{Name: "TestB", Action: "skip", Output: "    a_test.go:21: not now\n"},
+			},
+		}}
+		if diff := cmp.Diff(want, result.Packages, cmpopts.IgnoreFields(command.PackageTestResult{}, "Elapsed"), cmpopts.IgnoreFields(command.TestResult{}, "Elapsed")); diff != "" {
+			t.Errorf("StreamTests: unexpected packages (-want +got):\n%s", diff)
+		}
+	})
+}
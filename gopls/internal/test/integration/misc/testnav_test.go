@@ -0,0 +1,138 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	. "golang.org/x/tools/gopls/internal/test/integration"
+)
+
+func TestGoToTestOrSubject(t *testing.T) {
+	const files = `
+-- go.mod --
+module example.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func Parse() {}
+
+func helper() {}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestParseError(t *testing.T) {
+	helper()
+}
+-- a/a_x_test.go --
+package a_test
+
+import (
+	"testing"
+
+	"example.com/a"
+)
+
+func BenchmarkX(b *testing.B) {
+	a.Parse()
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.OpenFile("a/a_test.go")
+
+		goTo := func(loc protocol.Location) []protocol.Location {
+			t.Helper()
+			cmd := command.NewGoToTestOrSubjectCommand("", loc)
+			var result []protocol.Location
+			env.ExecuteCommand(&protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			}, &result)
+			return result
+		}
+		check := func(got []protocol.Location, want ...protocol.Location) {
+			t.Helper()
+			if len(got) != len(want) {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Errorf("location %d: got %v, want %v", i, got[i], want[i])
+				}
+			}
+		}
+
+		parse := env.RegexpSearch("a/a.go", "Parse")
+		helper := env.RegexpSearch("a/a.go", "helper")
+		testParse := env.RegexpSearch("a/a_test.go", "TestParseError")
+		benchX := env.RegexpSearch("a/a_x_test.go", "BenchmarkX")
+
+		// From a function to its tests, by name and by call.
+		check(goTo(parse), testParse, benchX)
+
+		// From a test to its subjects, by name first, then by call.
+		check(goTo(testParse), parse, helper)
+		check(goTo(benchX), parse)
+	})
+}
+
+// TestIndexTests checks that the tests of the workspace packages are
+// indexed in the background once they have been diagnosed, and that
+// only the invalidated relations are indexed again after a change.
+func TestIndexTests(t *testing.T) {
+	const files = `
+-- go.mod --
+module example.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func A() {}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestA(t *testing.T) { A() }
+-- b/b.go --
+package b
+
+func B() {}
+-- b/b_x_test.go --
+package b_test
+
+import (
+	"testing"
+
+	"example.com/b"
+)
+
+func TestB(t *testing.T) { b.B() }
+-- c/c.go --
+package c
+
+func C() {}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.Await(
+			InitialWorkspaceLoad,
+			LogMatching(protocol.Info, `indexed tests of 2 packages`, 1, false),
+		)
+
+		env.OpenFile("a/a_test.go")
+		env.RegexpReplace("a/a_test.go", "TestA", "TestAlpha")
+		env.Await(
+			LogMatching(protocol.Info, `indexed tests of 1 packages`, 1, false),
+		)
+	})
+}
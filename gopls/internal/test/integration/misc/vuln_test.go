@@ -791,6 +791,124 @@ require (
 	})
 }
 
+func TestRunGovulncheckCallSites(t *testing.T) {
+	// This test checks the diagnostics and hover at the calls through
+	// which govulncheck finds the vulnerable symbols reachable.
+	db, opts, err := vulnTestEnv(proxy1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Clean()
+	WithOptions(opts...).Run(t, workspace1, func(t *testing.T, env *Env) {
+		env.OpenFile("go.mod")
+		env.OpenFile("x/x.go")
+		env.OpenFile("y/y.go")
+
+		var result command.RunVulncheckResult
+		env.ExecuteCodeLensCommand("go.mod", command.RunGovulncheck, &result)
+		env.OnceMet(
+			CompletedProgressToken(result.Token, nil),
+			ShownMessage("Found"),
+		)
+		diags := map[string]*protocol.PublishDiagnosticsParams{
+			"x/x.go": {},
+			"y/y.go": {},
+		}
+		env.OnceMet(
+			Diagnostics(env.AtRegexp("x/x.go", `Vuln1`)),
+			ReadDiagnostics("x/x.go", diags["x/x.go"]),
+			ReadDiagnostics("y/y.go", diags["y/y.go"]),
+		)
+
+		for _, test := range []struct {
+			file, re, msg string
+			codeActions   []string
+			hover         []string
+		}{
+			{
+				file: "x/x.go",
+				re:   `Vuln1`,
+				msg:  "call to avuln.VulnData.Vuln1 has a vulnerability: GO-2022-01",
+				codeActions: []string{
+					"Upgrade golang.org/amod to v1.0.4",
+					"Reset govulncheck result",
+				},
+				hover: []string{"GO-2022-01", "Fixed in v1.0.4.", "x.X\n  avuln.VulnData.Vuln1"},
+			},
+			{
+				file: "x/x.go",
+				re:   `C1`,
+				msg:  "call to c.C1 reaches avuln.VulnData.Vuln2, which has a vulnerability: GO-2022-01",
+				codeActions: []string{
+					"Upgrade golang.org/amod to v1.0.4",
+					"Reset govulncheck result",
+				},
+				hover: []string{"GO-2022-01", "x.X\n  c.C1\n    avuln.VulnData.Vuln2"},
+			},
+			{
+				file:        "y/y.go",
+				re:          `c.C2\(\)\(\)`,
+				msg:         "call to bvuln.Vuln has a vulnerability: GO-2022-02",
+				codeActions: []string{"Reset govulncheck result"},
+				hover:       []string{"GO-2022-02", "No fix is available.", "y.Y\n  bvuln.Vuln"},
+			},
+		} {
+			loc := env.RegexpSearch(test.file, test.re)
+			var diag *protocol.Diagnostic
+			for _, d := range diags[test.file].Diagnostics {
+				if d.Range.Start == loc.Range.Start && d.Message == test.msg {
+					diag = &d
+					break
+				}
+			}
+			if diag == nil {
+				t.Errorf("no diagnostic at %s matching %q; got %v", test.re, test.msg, stringify(diags[test.file]))
+				continue
+			}
+			if diag.Severity != protocol.SeverityWarning || diag.Source != string(cache.Govulncheck) {
+				t.Errorf("diagnostic %q has (severity, source) = (%v, %v), want (%v, %v)",
+					test.msg, diag.Severity, diag.Source, protocol.SeverityWarning, cache.Govulncheck)
+			}
+			gotActions := quickFixes(env.CodeActionForFile(test.file, []protocol.Diagnostic{*diag}))
+			if diff := diffCodeActions(gotActions, test.codeActions); diff != "" {
+				t.Errorf("code actions for %q do not match:\n%s", test.msg, diff)
+			}
+			hover, _ := env.Hover(loc)
+			for _, part := range test.hover {
+				if !strings.Contains(hover.Value, part) {
+					t.Errorf("hover at %s does not contain %q:\n%s", test.re, part, hover.Value)
+				}
+			}
+		}
+
+		// Upgrading the module fixes the vulnerability.
+		loc := env.RegexpSearch("x/x.go", `Vuln1`)
+		for _, action := range quickFixes(env.CodeActionForFile("x/x.go", diags["x/x.go"].Diagnostics)) {
+			if action.Title == "Upgrade golang.org/amod to v1.0.4" {
+				env.ApplyCodeAction(action)
+				break
+			}
+		}
+		env.AfterChange(
+			NoDiagnostics(env.AtRegexp("x/x.go", `Vuln1`)),
+		)
+		if hover, _ := env.Hover(loc); strings.Contains(hover.Value, "GO-2022-01") {
+			t.Errorf("hover after upgrade still reports GO-2022-01:\n%s", hover.Value)
+		}
+	})
+}
+
+// quickFixes returns the actions of kind quickfix.
+func quickFixes(actions []protocol.CodeAction) []protocol.CodeAction {
+	var fixes []protocol.CodeAction
+	for _, action := range actions {
+		if action.Kind == protocol.QuickFix {
+			fixes = append(fixes, action)
+		}
+	}
+	return fixes
+}
+
 func diffCodeActions(gotActions []protocol.CodeAction, want []string) string {
 	var gotTitles []string
 	for _, ca := range gotActions {
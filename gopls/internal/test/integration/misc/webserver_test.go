@@ -510,6 +510,135 @@ func f(buf bytes.Buffer, greeting string) {
 }
 
 // TestAssembly is a basic test of the web-based assembly listing.
+func TestMissingTests(t *testing.T) {
+	const files = `
+-- go.mod --
+module example.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func Tested() {}
+
+func Untested() {}
+
+type T int
+
+func (T) M() {}
+-- a/a_test.go --
+package a
+
+import "testing"
+
+func TestTested(t *testing.T) {}
+-- b/b.go --
+package b
+
+func Covered() {}
+-- b/b_test.go --
+package b
+
+import "testing"
+
+func TestCovered(t *testing.T) {}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+
+		// Execute the command.
+		// Its side effect should be a single showDocument request.
+		collectDocs := env.Awaiter.ListenToShownDocuments()
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.MissingTests.String(),
+			Arguments: command.MustMarshalArgs(command.URIArg{URI: env.Sandbox.Workdir.URI("a/a.go")}),
+		}, nil)
+		doc := shownDocument(t, collectDocs(), "http:")
+		if doc == nil {
+			t.Fatalf("no showDocument call had 'http:' prefix")
+		}
+		t.Log("showDocument(missing tests) URL:", doc.URI)
+
+		// Get the report and check that it lists only the
+		// functions without tests, by package.
+		report := get(t, doc.URI)
+		checkMatch(t, true, report, `2 of the 4 functions and methods of the workspace have no tests`)
+		checkMatch(t, true, report, `<h2>example.com/a <span class='count'>\(2 of 3 untested\)</span></h2>`)
+		checkMatch(t, true, report, `<li><a .*>Untested</a> — <a .*>add test</a></li>`)
+		checkMatch(t, true, report, `<li><a .*>T.M</a> — <a .*>add test</a></li>`)
+		checkMatch(t, false, report, `>Tested<`)
+		checkMatch(t, false, report, `example.com/b`)
+
+		// Follow the link that adds a test for Untested.
+		m := regexp.MustCompile(`>Untested</a> — <a href="([^"]*)"`).FindSubmatch(report)
+		if m == nil {
+			t.Fatalf("no add test link for Untested")
+		}
+		get(t, html.UnescapeString(string(m[1])))
+		env.OpenFile("a/a_test.go")
+		if got := env.BufferText("a/a_test.go"); !strings.Contains(got, "func TestUntested(") {
+			t.Errorf("a/a_test.go does not contain TestUntested:\n%s", got)
+		}
+	})
+}
+
+func TestBrowseCoverage(t *testing.T) {
+	const files = `
+-- go.mod --
+module example.com
+
+go 1.21
+-- a/a.go --
+package a
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+-- cover.out --
+mode: set
+example.com/a/a.go:3.21,4.11 1 1
+example.com/a/a.go:4.11,6.3 1 0
+example.com/a/a.go:7.2,7.10 1 1
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("a/a.go")
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.LoadCoverage.String(),
+			Arguments: command.MustMarshalArgs(command.URIArg{URI: env.Sandbox.Workdir.URI("cover.out")}),
+		}, nil)
+
+		// Execute the command.
+		// Its side effect should be a single showDocument request.
+		collectDocs := env.Awaiter.ListenToShownDocuments()
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   command.BrowseCoverage.String(),
+			Arguments: command.MustMarshalArgs(command.URIArg{URI: env.Sandbox.Workdir.URI("a/a.go")}),
+		}, nil)
+		doc := shownDocument(t, collectDocs(), "http:")
+		if doc == nil {
+			t.Fatalf("no showDocument call had 'http:' prefix")
+		}
+		t.Log("showDocument(coverage) URL:", doc.URI)
+
+		// Get the report and check that it highlights the covered
+		// and uncovered statements.
+		report := get(t, doc.URI)
+		checkMatch(t, true, report, `66% of the statements are covered \(2 of 3\)`)
+		checkMatch(t, true, report, `<span class='cov' title='count: 1'>\tif x &lt; 0 </span>`)
+		checkMatch(t, true, report, `<span class='uncov' title='count: 0'>\t\treturn -x</span>`)
+		checkMatch(t, true, report, `<span class='cov' title='count: 1'>return x</span>`)
+
+		// The hover over the function links to the same report.
+		hover, _ := env.Hover(env.RegexpSearch("a/a.go", "Abs"))
+		if !strings.Contains(hover.Value, "([browse]("+doc.URI+"))") {
+			t.Errorf("hover %q does not link to %s", hover.Value, doc.URI)
+		}
+	})
+}
+
 func TestAssembly(t *testing.T) {
 	testenv.NeedsGoCommand1Point(t, 22) // for up-to-date assembly listing
 
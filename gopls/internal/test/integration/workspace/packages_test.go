@@ -165,8 +165,8 @@ func Test(*testing.T)
 						{
 							URI: env.Editor.DocumentURI("foo_test.go"),
 							Tests: []command.TestCase{
-								{Name: "TestFoo"},
-								{Name: "Test_foo"},
+								{Name: "TestFoo", Flags: []string{`-run=^TestFoo$`}},
+								{Name: "Test_foo", Flags: []string{`-run=^Test_foo$`}},
 							},
 						},
 					},
@@ -179,7 +179,7 @@ func Test(*testing.T)
 						{
 							URI: env.Editor.DocumentURI("foo2_test.go"),
 							Tests: []command.TestCase{
-								{Name: "TestBar"},
+								{Name: "TestBar", Flags: []string{`-run=^TestBar$`}},
 							},
 						},
 					},
@@ -208,10 +208,10 @@ func Test(*testing.T)
 						{
 							URI: env.Editor.DocumentURI("baz/baz_test.go"),
 							Tests: []command.TestCase{
-								{Name: "TestBaz"},
-								{Name: "BenchmarkBaz"},
-								{Name: "FuzzBaz"},
-								{Name: "ExampleBaz"},
+								{Name: "TestBaz", Flags: []string{`-run=^TestBaz$`}},
+								{Name: "BenchmarkBaz", Flags: []string{`-run=^$`, `-bench=^BenchmarkBaz$`}},
+								{Name: "FuzzBaz", Flags: []string{`-run=^FuzzBaz$`}},
+								{Name: "ExampleBaz", Flags: []string{`-run=^ExampleBaz$`}},
 							},
 						},
 					},
@@ -245,8 +245,8 @@ func Test(*testing.T)
 						{
 							URI: env.Editor.DocumentURI("foo_test.go"),
 							Tests: []command.TestCase{
-								{Name: "TestFoo"},
-								{Name: "Test_foo"},
+								{Name: "TestFoo", Flags: []string{`-run=^TestFoo$`}},
+								{Name: "Test_foo", Flags: []string{`-run=^Test_foo$`}},
 							},
 						},
 					},
@@ -259,10 +259,10 @@ func Test(*testing.T)
 						{
 							URI: env.Editor.DocumentURI("baz/baz_test.go"),
 							Tests: []command.TestCase{
-								{Name: "TestBaz"},
-								{Name: "BenchmarkBaz"},
-								{Name: "FuzzBaz"},
-								{Name: "ExampleBaz"},
+								{Name: "TestBaz", Flags: []string{`-run=^TestBaz$`}},
+								{Name: "BenchmarkBaz", Flags: []string{`-run=^$`, `-bench=^BenchmarkBaz$`}},
+								{Name: "FuzzBaz", Flags: []string{`-run=^FuzzBaz$`}},
+								{Name: "ExampleBaz", Flags: []string{`-run=^ExampleBaz$`}},
 							},
 						},
 					},
@@ -275,7 +275,7 @@ func Test(*testing.T)
 						{
 							URI: env.Editor.DocumentURI("foo2_test.go"),
 							Tests: []command.TestCase{
-								{Name: "TestBar"},
+								{Name: "TestBar", Flags: []string{`-run=^TestBar$`}},
 							},
 						},
 					},
@@ -312,7 +312,7 @@ func Test(*testing.T)
 						{
 							URI: env.Editor.DocumentURI("bat/bat_test.go"),
 							Tests: []command.TestCase{
-								{Name: "Test"},
+								{Name: "Test", Flags: []string{`-run=^Test$`}},
 							},
 						},
 					},
@@ -379,18 +379,18 @@ func (X) SubtestMethod(t *testing.T) {
 					{
 						URI: env.Editor.DocumentURI("foo_test.go"),
 						Tests: []command.TestCase{
-							{Name: "ExampleFoo"},
-							{Name: "TestFoo"},
-							{Name: "TestFoo/Bar"},
-							{Name: "TestFoo/Bar/Baz"},
-							{Name: "TestFoo/Bar#01"},
-							{Name: "TestFoo/Bar#02"},
-							{Name: "TestFoo/with_space"},
-							{Name: "TestFoo/SubtestFunc"},
-							{Name: "TestFoo/SubtestFunc/FuncSub"},
-							{Name: "TestFoo/SubtestMethod"},
-							{Name: "TestFoo/SubtestMethod/MethodSub"},
-							{Name: "TestFoo/SubtestVar"},
+							{Name: "ExampleFoo", Flags: []string{`-run=^ExampleFoo$`}},
+							{Name: "TestFoo", Flags: []string{`-run=^TestFoo$`}},
+							{Name: "TestFoo/Bar", Flags: []string{`-run=^TestFoo$/^Bar$`}},
+							{Name: "TestFoo/Bar/Baz", Flags: []string{`-run=^TestFoo$/^Bar$/^Baz$`}},
+							{Name: "TestFoo/Bar#01", Flags: []string{`-run=^TestFoo$/^Bar#01$`}},
+							{Name: "TestFoo/Bar#02", Flags: []string{`-run=^TestFoo$/^Bar#02$`}},
+							{Name: "TestFoo/with_space", Flags: []string{`-run=^TestFoo$/^with_space$`}},
+							{Name: "TestFoo/SubtestFunc", Flags: []string{`-run=^TestFoo$/^SubtestFunc$`}},
+							{Name: "TestFoo/SubtestFunc/FuncSub", Flags: []string{`-run=^TestFoo$/^SubtestFunc$/^FuncSub$`}},
+							{Name: "TestFoo/SubtestMethod", Flags: []string{`-run=^TestFoo$/^SubtestMethod$`}},
+							{Name: "TestFoo/SubtestMethod/MethodSub", Flags: []string{`-run=^TestFoo$/^SubtestMethod$/^MethodSub$`}},
+							{Name: "TestFoo/SubtestVar", Flags: []string{`-run=^TestFoo$/^SubtestVar$`}},
 							// {Name: "TestFoo/SubtestVar/VarSub"}, // TODO
 						},
 					},
@@ -433,6 +433,48 @@ func (X) SubtestMethod(t *testing.T) {
 	})
 }
 
+func TestPackagesBuildConstraint(t *testing.T) {
+	const files = `
+-- go.mod --
+module foo
+
+-- foo_test.go --
+//go:build go1.18 && !never
+
+package foo
+
+import "testing"
+
+func TestFoo(t *testing.T) {}
+`
+
+	Run(t, files, func(t *testing.T, env *Env) {
+		checkPackages(t, env, []protocol.DocumentURI{env.Editor.DocumentURI("foo_test.go")}, false, command.NeedTests, []command.Package{
+			{
+				Path:       "foo",
+				ForTest:    "foo",
+				ModulePath: "foo",
+				TestFiles: []command.TestFile{
+					{
+						URI:             env.Editor.DocumentURI("foo_test.go"),
+						BuildConstraint: "go1.18 && !never",
+						Tests: []command.TestCase{
+							{Name: "TestFoo", Flags: []string{`-run=^TestFoo$`}},
+						},
+					},
+				},
+			},
+		}, map[string]command.Module{
+			"foo": {
+				Path:  "foo",
+				GoMod: env.Editor.DocumentURI("go.mod"),
+			},
+		}, []string{
+			"func TestFoo(t *testing.T) {}",
+		})
+	})
+}
+
 func checkPackages(t testing.TB, env *Env, files []protocol.DocumentURI, recursive bool, mode command.PackagesMode, wantPkg []command.Package, wantModule map[string]command.Module, wantSource []string) {
 	t.Helper()
 
@@ -0,0 +1,167 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package structtag derives the names of struct tags, such as those
+// of the json key, from the names of struct fields, and updates them
+// within existing tags.
+package structtag
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Case is a naming convention for the names of struct tags.
+// Its values are those of the StructTagCase option.
+type Case string
+
+const (
+	Snake Case = "snake" // MyField becomes my_field
+	Camel Case = "camel" // MyField becomes myField
+	Kebab Case = "kebab" // MyField becomes my-field
+)
+
+// Update returns the struct tag (in unquoted form) that results
+// from setting the name of key in tag to name, adding the omitempty
+// option if requested. Other keys, and other options of key, are
+// preserved. A key whose name is "-" is left unchanged, as is a tag
+// that is not in the conventional format.
+func Update(tag, key, name string, omitempty bool) string {
+	value := name
+	if omitempty {
+		value += ",omitempty"
+	}
+
+	// Find the value of key, following the logic of reflect.StructTag.Lookup.
+	for rest := tag; rest != ""; {
+		i := 0
+		for i < len(rest) && rest[i] == ' ' {
+			i++
+		}
+		rest = rest[i:]
+		if rest == "" {
+			break
+		}
+		i = 0
+		for i < len(rest) && rest[i] > ' ' && rest[i] != ':' && rest[i] != '"' && rest[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(rest) || rest[i] != ':' || rest[i+1] != '"' {
+			return tag // not in conventional format
+		}
+		k := rest[:i]
+		rest = rest[i+1:]
+
+		i = 1
+		for i < len(rest) && rest[i] != '"' {
+			if rest[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(rest) {
+			return tag // not in conventional format
+		}
+		quoted := rest[:i+1]
+		rest = rest[i+1:]
+		if k != key {
+			continue
+		}
+
+		old, err := strconv.Unquote(quoted)
+		if err != nil {
+			return tag
+		}
+		oldName, opts, _ := strings.Cut(old, ",")
+		if oldName == "-" {
+			return tag
+		}
+		value = name
+		if opts != "" {
+			value += "," + opts
+		}
+		if omitempty && !HasOption(opts, "omitempty") {
+			value += ",omitempty"
+		}
+		valueStart := len(tag) - len(rest) - len(quoted)
+		return tag[:valueStart] + strconv.Quote(value) + tag[valueStart+len(quoted):]
+	}
+
+	// Append a new key.
+	newKey := key + ":" + strconv.Quote(value)
+	if tag := strings.TrimRight(tag, " "); tag != "" {
+		return tag + " " + newKey
+	}
+	return newKey
+}
+
+// HasOption reports whether the comma-separated list opts contains opt.
+func HasOption(opts, opt string) bool {
+	for o := range strings.SplitSeq(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the name of the struct tag for the field name
+// according to the specified naming convention.
+func Name(name string, tagCase Case) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	if tagCase == Camel {
+		// Preserve the case of initialisms after the first word,
+		// as in userID.
+		words[0] = strings.ToLower(words[0])
+		for i, w := range words[1:] {
+			if w != strings.ToUpper(w) {
+				r, size := utf8.DecodeRuneInString(w)
+				words[i+1] = string(unicode.ToUpper(r)) + strings.ToLower(w[size:])
+			}
+		}
+		return strings.Join(words, "")
+	}
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	switch tagCase {
+	case Kebab:
+		return strings.Join(words, "-")
+	default:
+		return strings.Join(words, "_")
+	}
+}
+
+// splitWords splits a Go identifier into words at underscores and
+// case transitions, treating a run of capitals as an initialism:
+// "HTTPServerID" yields ["HTTP", "Server", "ID"].
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := range runes {
+		if runes[i] == '_' {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i > start && unicode.IsUpper(runes[i]) &&
+			(!unicode.IsUpper(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
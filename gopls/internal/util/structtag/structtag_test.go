@@ -0,0 +1,48 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package structtag_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/util/structtag"
+)
+
+func TestName(t *testing.T) {
+	for _, test := range []struct {
+		field string
+		c     structtag.Case
+		want  string
+	}{
+		{"Name", structtag.Snake, "name"},
+		{"HTTPServerID", structtag.Snake, "http_server_id"},
+		{"HTTPServerID", structtag.Camel, "httpServerID"},
+		{"HTTPServerID", structtag.Kebab, "http-server-id"},
+		{"Already_Snake", structtag.Camel, "alreadySnake"},
+	} {
+		if got := structtag.Name(test.field, test.c); got != test.want {
+			t.Errorf("Name(%q, %s) = %q, want %q", test.field, test.c, got, test.want)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	for _, test := range []struct {
+		tag, name string
+		omitempty bool
+		want      string
+	}{
+		{``, "id", false, `json:"id"`},
+		{``, "id", true, `json:"id,omitempty"`},
+		{`db:"x"`, "id", false, `db:"x" json:"id"`},
+		{`json:"ID,string" db:"x"`, "id", true, `json:"id,string,omitempty" db:"x"`},
+		{`json:"-"`, "id", false, `json:"-"`},
+		{`not a tag`, "id", false, `not a tag`},
+	} {
+		if got := structtag.Update(test.tag, "json", test.name, test.omitempty); got != test.want {
+			t.Errorf("Update(%q, json, %q, %t) = %q, want %q", test.tag, test.name, test.omitempty, got, test.want)
+		}
+	}
+}
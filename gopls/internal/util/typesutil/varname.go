@@ -0,0 +1,132 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typesutil
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+)
+
+type objKey struct{ pkg, name string }
+
+// conventionalVarNames specifies conventional names for variables with various
+// standard library types.
+//
+// Keep this up to date with completion.conventionalAcronyms.
+//
+// TODO(rfindley): consider factoring out a "conventions" library.
+var conventionalVarNames = map[objKey]string{
+	{"", "error"}:              "err",
+	{"context", "Context"}:     "ctx",
+	{"sql", "Tx"}:              "tx",
+	{"http", "ResponseWriter"}: "rw", // Note: same as [AbbreviateVarName].
+}
+
+// VarName returns an idiomatic name for a variable of type t, or of
+// the type to which t points, that is neither a keyword nor one of the
+// names to avoid. Code generators should use it so that they choose
+// consistent names, such as for receivers.
+//
+// It prefers the conventional name of the type, if any, such as "ctx"
+// for a context.Context or "err" for an error, then the lower-case
+// initials of its name, such as "fb" for FooBar (see
+// [AbbreviateVarName]), then the first two letters of its name. If all
+// of these are avoided, it appends a number to the first of them.
+// Types that have no name are called "v".
+func VarName(t types.Type, avoid map[string]bool) string {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	candidates := []string{"v"}
+	if name, ok := abbreviatedName(t); ok && name != "" {
+		candidates = []string{name}
+		if typeName := typeName(t); len(typeName) >= 2 {
+			candidates = append(candidates, strings.ToLower(string([]rune(typeName)[:2])))
+		}
+	}
+	for _, name := range candidates {
+		if !token.IsKeyword(name) && !avoid[name] {
+			return name
+		}
+	}
+
+	base := candidates[0]
+	for i := 2; ; i++ {
+		if name := fmt.Sprintf("%s%d", base, i); !avoid[name] {
+			return name
+		}
+	}
+}
+
+// typeName returns the name of t, if it is a named type,
+// alias, type parameter, or basic type.
+func typeName(t types.Type) string {
+	switch t := t.(type) {
+	case interface{ Obj() *types.TypeName }:
+		return t.Obj().Name()
+	case *types.Basic:
+		return t.Name()
+	}
+	return ""
+}
+
+// abbreviatedName chooses a "good" name for a variable with the given type,
+// if possible. Otherwise, it returns "", false.
+//
+// For special types, it uses known conventional names.
+func abbreviatedName(t types.Type) (string, bool) {
+	if tn, ok := t.(interface{ Obj() *types.TypeName }); ok {
+		obj := tn.Obj()
+		k := objKey{name: obj.Name()}
+		if obj.Pkg() != nil {
+			k.pkg = obj.Pkg().Name()
+		}
+		if name, ok := conventionalVarNames[k]; ok {
+			return name, true
+		}
+	}
+	typeName := typeName(t)
+	if typeName == "" {
+		return "", false
+	}
+	return AbbreviateVarName(typeName), true
+}
+
+// AbbreviateVarName returns an abbreviated var name based on the given full
+// name (which may be a type name, for example).
+//
+// See the simple heuristics documented in line.
+func AbbreviateVarName(s string) string {
+	var (
+		b            strings.Builder
+		useNextUpper bool
+	)
+	for i, r := range s {
+		// Stop if we encounter a non-identifier rune.
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			break
+		}
+
+		// Otherwise, take the first letter from word boundaries, assuming
+		// camelCase.
+		if i == 0 {
+			b.WriteRune(unicode.ToLower(r))
+		}
+
+		if unicode.IsUpper(r) {
+			if useNextUpper {
+				b.WriteRune(unicode.ToLower(r))
+				useNextUpper = false
+			}
+		} else {
+			useNextUpper = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typesutil_test
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/util/typesutil"
+)
+
+func TestVarName(t *testing.T) {
+	var (
+		p       = types.NewPackage("example.com/p", "p")
+		context = types.NewPackage("context", "context")
+	)
+	// named returns a new named struct type.
+	named := func(pkg *types.Package, name string) types.Type {
+		obj := types.NewTypeName(token.NoPos, pkg, name, nil)
+		return types.NewNamed(obj, new(types.Struct), nil)
+	}
+	fooBar := named(p, "FooBar")
+
+	for _, test := range []struct {
+		typ   types.Type
+		avoid []string
+		want  string
+	}{
+		{fooBar, nil, "fb"},
+		{types.NewPointer(fooBar), nil, "fb"},
+		{fooBar, []string{"fb"}, "fo"},
+		{fooBar, []string{"fb", "fo"}, "fb2"},
+		{named(context, "Context"), nil, "ctx"},
+		{types.Universe.Lookup("error").Type(), nil, "err"},
+		{types.Typ[types.Int], nil, "i"},
+		{named(p, "IfFoo"), nil, "if2"}, // "if" is a keyword
+		{named(p, "T"), []string{"t"}, "t2"},
+		{types.NewSlice(types.Typ[types.Int]), nil, "v"},
+		{types.NewSlice(types.Typ[types.Int]), []string{"v"}, "v2"},
+	} {
+		avoid := make(map[string]bool)
+		for _, name := range test.avoid {
+			avoid[name] = true
+		}
+		if got := typesutil.VarName(test.typ, avoid); got != test.want {
+			t.Errorf("VarName(%s, %v) = %q, want %q", test.typ, test.avoid, got, test.want)
+		}
+	}
+}
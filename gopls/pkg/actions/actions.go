@@ -0,0 +1,396 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package actions provides headless access to the code actions of
+// gopls, for use by programs such as code-generation bots and
+// migration tools that want the edits gopls would make without
+// running a language server and speaking LSP.
+//
+// A program opens a [Workspace] for a directory, queries the code
+// actions available at a [Location] of one of its files, resolves an
+// action to a list of [FileEdit]s, and optionally applies them:
+//
+//	w, err := actions.Open(ctx, dir)
+//	...
+//	defer w.Close(ctx)
+//	edits, err := w.AddTest(ctx, actions.Location{Filename: "p.go", Start: actions.Point{Line: 10, Column: 6}})
+//	...
+//	err = w.Apply(ctx, edits)
+//
+// The workspace reads files from disk; [Workspace.Apply] writes the
+// edits and informs the workspace of the change. Edits written by
+// other means must be reported using [Workspace.FilesChanged].
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/cache"
+	"golang.org/x/tools/gopls/internal/file"
+	"golang.org/x/tools/gopls/internal/golang"
+	"golang.org/x/tools/gopls/internal/protocol"
+	"golang.org/x/tools/gopls/internal/protocol/command"
+	"golang.org/x/tools/gopls/internal/settings"
+	"golang.org/x/tools/internal/diff"
+)
+
+// A Workspace is a headless gopls session for the Go packages in a
+// directory. It is safe for concurrent use.
+type Workspace struct {
+	session *cache.Session
+}
+
+// Open returns a workspace for the specified directory, which is
+// loaded like a workspace folder of an editor: as a module, a go.work
+// workspace, or a GOPATH directory.
+func Open(ctx context.Context, dir string) (*Workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	uri := protocol.URIFromPath(dir)
+	opts := settings.DefaultOptions()
+	env, err := cache.FetchGoEnv(ctx, uri, opts)
+	if err != nil {
+		return nil, err
+	}
+	session := cache.NewSession(ctx, cache.New(nil))
+	_, _, release, err := session.NewView(ctx, &cache.Folder{
+		Dir:     uri,
+		Name:    filepath.Base(dir),
+		Options: opts,
+		Env:     *env,
+	})
+	if err != nil {
+		session.Shutdown(ctx)
+		return nil, err
+	}
+	release()
+	return &Workspace{session: session}, nil
+}
+
+// Close releases the resources of the workspace.
+func (w *Workspace) Close(ctx context.Context) {
+	w.session.Shutdown(ctx)
+}
+
+// A Point is a position within a file. Line and Column are 1-based;
+// columns are measured in bytes of the UTF-8 encoding.
+type Point struct {
+	Line, Column int
+}
+
+// A Location denotes a range of a file. If End is zero, the range is
+// empty: a point at Start.
+type Location struct {
+	Filename   string
+	Start, End Point
+}
+
+// An Action is a code action available at a location.
+type Action struct {
+	Title string // e.g. "Add test for F"
+	Kind  string // e.g. "source.generate.test"
+
+	uri    protocol.DocumentURI
+	action protocol.CodeAction
+}
+
+// A FileEdit is a change to a single file.
+//
+// If Create is set, the file is created, empty, before the edits are
+// applied; if Delete is set, the file is deleted and there are no
+// edits. Edits are relative to the file's content before the change,
+// which includes any earlier changes to the file in the same list.
+type FileEdit struct {
+	Filename string
+	Create   bool
+	Delete   bool
+	Edits    []TextEdit
+}
+
+// A TextEdit replaces the bytes [Start, End) of a file with NewText.
+type TextEdit struct {
+	Start, End int
+	NewText    string
+}
+
+// CodeActions returns the code actions available at the specified
+// location, restricted to those of the specified kinds, if any.
+// Kinds are hierarchical: "refactor" includes "refactor.inline", and
+// "source.generate" includes all generators of tests and test doubles;
+// legacy generator kinds such as "source.addTest" are also accepted.
+// Actions of kind "source.test" are returned only if requested
+// explicitly.
+//
+// Actions that only fix diagnostics are not included.
+func (w *Workspace) CodeActions(ctx context.Context, loc Location, kinds ...string) ([]Action, error) {
+	snapshot, release, fh, rng, err := w.resolve(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	enabled := func(kind protocol.CodeActionKind) bool {
+		if len(kinds) == 0 {
+			return kind != settings.GoTest
+		}
+		for _, k := range kinds {
+			k := string(settings.CanonicalCodeActionKind(protocol.CodeActionKind(k)))
+			if string(kind) == k ||
+				kind != settings.GoTest && (k == "" || strings.HasPrefix(string(kind), k+".")) {
+				return true
+			}
+		}
+		return false
+	}
+	codeActions, err := golang.CodeActions(ctx, snapshot, fh, rng, nil, enabled, protocol.CodeActionInvoked)
+	if err != nil {
+		return nil, err
+	}
+	var actions []Action
+	for _, act := range codeActions {
+		if act.Disabled != nil {
+			continue
+		}
+		actions = append(actions, Action{
+			Title:  act.Title,
+			Kind:   string(act.Kind),
+			uri:    fh.URI(),
+			action: act,
+		})
+	}
+	return actions, nil
+}
+
+// Edits returns the edits of the code action.
+//
+// It returns an error if the action does not edit files, such as one
+// that displays documentation in a web browser.
+func (w *Workspace) Edits(ctx context.Context, action Action) ([]FileEdit, error) {
+	snapshot, release, err := w.session.SnapshotOf(ctx, action.uri)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	act := action.action
+	if act.Edit != nil {
+		return fileEdits(ctx, snapshot, act.Edit.DocumentChanges)
+	}
+	if act.Command == nil {
+		return nil, fmt.Errorf("action %q has neither edits nor a command", act.Title)
+	}
+
+	var changes []protocol.DocumentChange
+	switch command.Command(act.Command.Command) {
+	case command.ApplyFix:
+		var args command.ApplyFixArgs
+		if err := command.UnmarshalArgs(act.Command.Arguments, &args); err != nil {
+			return nil, err
+		}
+		fh, err := snapshot.ReadFile(ctx, args.Location.URI)
+		if err != nil {
+			return nil, err
+		}
+		changes, err = golang.ApplyFix(ctx, args.Fix, snapshot, fh, args.Location.Range)
+		if err != nil {
+			return nil, err
+		}
+
+	case command.AddTest:
+		var args command.AddTestArgs
+		if err := command.UnmarshalArgs(act.Command.Arguments, &args); err != nil {
+			return nil, err
+		}
+		changes, err = golang.AddTestForFunc(ctx, snapshot, args.Location, args.Integration, golang.TestOptions{
+			ReceiverForms:   args.ReceiverForms,
+			FieldAssertions: args.FieldAssertions,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	case command.AddStringMethod:
+		var args command.AddStringMethodArgs
+		if err := command.UnmarshalArgs(act.Command.Arguments, &args); err != nil {
+			return nil, err
+		}
+		changes, err = golang.AddStringMethod(ctx, snapshot, args.Location)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("action %q does not edit files (command %s)", act.Title, act.Command.Command)
+	}
+	return fileEdits(ctx, snapshot, changes)
+}
+
+// AddTest returns the edits that add a table-driven test for the
+// function or method at the specified location to the corresponding
+// _test.go file, like the "Add test for F" code action.
+func (w *Workspace) AddTest(ctx context.Context, loc Location) ([]FileEdit, error) {
+	snapshot, release, fh, rng, err := w.resolve(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	changes, err := golang.AddTestForFunc(ctx, snapshot, protocol.Location{URI: fh.URI(), Range: rng}, false, golang.TestOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fileEdits(ctx, snapshot, changes)
+}
+
+// Apply applies the edits, in order, to the files on disk, and informs
+// the workspace of the change.
+func (w *Workspace) Apply(ctx context.Context, edits []FileEdit) error {
+	var filenames []string
+	for _, fe := range edits {
+		switch {
+		case fe.Delete:
+			if err := os.Remove(fe.Filename); err != nil {
+				return err
+			}
+		default:
+			var content []byte
+			if !fe.Create {
+				var err error
+				content, err = os.ReadFile(fe.Filename)
+				if err != nil {
+					return err
+				}
+			}
+			diffEdits := make([]diff.Edit, len(fe.Edits))
+			for i, e := range fe.Edits {
+				diffEdits[i] = diff.Edit{Start: e.Start, End: e.End, New: e.NewText}
+			}
+			content, err := diff.ApplyBytes(content, diffEdits)
+			if err != nil {
+				return fmt.Errorf("%s: %v", fe.Filename, err)
+			}
+			if err := os.WriteFile(fe.Filename, content, 0666); err != nil {
+				return err
+			}
+		}
+		filenames = append(filenames, fe.Filename)
+	}
+	return w.FilesChanged(ctx, filenames...)
+}
+
+// FilesChanged informs the workspace that the specified files were
+// created, changed, or deleted on disk.
+func (w *Workspace) FilesChanged(ctx context.Context, filenames ...string) error {
+	var mods []file.Modification
+	for _, filename := range filenames {
+		filename, err := filepath.Abs(filename)
+		if err != nil {
+			return err
+		}
+		action := file.Change
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			action = file.Delete
+		}
+		mods = append(mods, file.Modification{
+			URI:    protocol.URIFromPath(filename),
+			Action: action,
+			OnDisk: true,
+		})
+	}
+	_, err := w.session.DidModifyFiles(ctx, mods)
+	return err
+}
+
+// resolve returns a snapshot for the file of the location, along with
+// its handle and the protocol range of the location.
+func (w *Workspace) resolve(ctx context.Context, loc Location) (*cache.Snapshot, func(), file.Handle, protocol.Range, error) {
+	filename, err := filepath.Abs(loc.Filename)
+	if err != nil {
+		return nil, nil, nil, protocol.Range{}, err
+	}
+	uri := protocol.URIFromPath(filename)
+	snapshot, release, err := w.session.SnapshotOf(ctx, uri)
+	if err != nil {
+		return nil, nil, nil, protocol.Range{}, err
+	}
+	fh, err := snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		release()
+		return nil, nil, nil, protocol.Range{}, err
+	}
+	content, err := fh.Content()
+	if err != nil {
+		release()
+		return nil, nil, nil, protocol.Range{}, err
+	}
+	mapper := protocol.NewMapper(uri, content)
+	end := loc.End
+	if end == (Point{}) {
+		end = loc.Start
+	}
+	start, err := mapper.LineCol8Position(loc.Start.Line, loc.Start.Column)
+	if err == nil {
+		var endPos protocol.Position
+		endPos, err = mapper.LineCol8Position(end.Line, end.Column)
+		if err == nil {
+			return snapshot, release, fh, protocol.Range{Start: start, End: endPos}, nil
+		}
+	}
+	release()
+	return nil, nil, nil, protocol.Range{}, fmt.Errorf("%s: %v", loc.Filename, err)
+}
+
+// fileEdits converts protocol document changes to FileEdits.
+func fileEdits(ctx context.Context, snapshot *cache.Snapshot, changes []protocol.DocumentChange) ([]FileEdit, error) {
+	// contents holds the content of each file after the changes so far.
+	contents := make(map[protocol.DocumentURI][]byte)
+	var edits []FileEdit
+	for _, change := range changes {
+		switch {
+		case change.TextDocumentEdit != nil:
+			uri := change.TextDocumentEdit.TextDocument.URI
+			content, ok := contents[uri]
+			if !ok {
+				fh, err := snapshot.ReadFile(ctx, uri)
+				if err != nil {
+					return nil, err
+				}
+				content, err = fh.Content()
+				if err != nil {
+					return nil, err
+				}
+			}
+			diffEdits, err := protocol.EditsToDiffEdits(protocol.NewMapper(uri, content), protocol.AsTextEdits(change.TextDocumentEdit.Edits))
+			if err != nil {
+				return nil, err
+			}
+			if contents[uri], err = diff.ApplyBytes(content, diffEdits); err != nil {
+				return nil, err
+			}
+			fe := FileEdit{Filename: uri.Path()}
+			for _, e := range diffEdits {
+				fe.Edits = append(fe.Edits, TextEdit{Start: e.Start, End: e.End, NewText: e.New})
+			}
+			edits = append(edits, fe)
+
+		case change.CreateFile != nil:
+			contents[change.CreateFile.URI] = []byte{}
+			edits = append(edits, FileEdit{Filename: change.CreateFile.URI.Path(), Create: true})
+
+		case change.DeleteFile != nil:
+			contents[change.DeleteFile.URI] = nil
+			edits = append(edits, FileEdit{Filename: change.DeleteFile.URI.Path(), Delete: true})
+
+		default:
+			return nil, fmt.Errorf("unsupported change: %v", change)
+		}
+	}
+	return edits, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package actions_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/pkg/actions"
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/txtar"
+)
+
+func TestWorkspace(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	dir := t.TempDir()
+	archive := txtar.Parse([]byte(`
+-- go.mod --
+module example.com
+go 1.18
+
+-- a/a.go --
+package a
+
+func Parse(s string) int { return len(s) }
+
+func F() (int, string) { return }
+`))
+	for _, f := range archive.Files {
+		filename := filepath.Join(dir, f.Name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, f.Data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	w, err := actions.Open(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close(ctx)
+
+	aGo := filepath.Join(dir, "a/a.go")
+
+	// Add a test directly.
+	edits, err := w.AddTest(ctx, actions.Location{Filename: aGo, Start: actions.Point{Line: 3, Column: 6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Apply(ctx, edits); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "a/a_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "func TestParse(t *testing.T) {"; !strings.Contains(got, want) {
+		t.Errorf("after AddTest, a/a_test.go does not contain %q:\n%s", want, got)
+	}
+
+	// Query and resolve a code action, which sees the test added above.
+	loc := actions.Location{Filename: aGo, Start: actions.Point{Line: 5, Column: 6}}
+	// The legacy kind selects the family of test generators,
+	// "Add test for F" first.
+	acts, err := w.CodeActions(ctx, loc, "source.addTest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acts) == 0 || acts[0].Title != "Add test for F" || acts[0].Kind != "source.generate.test" {
+		t.Fatalf("CodeActions(source.addTest) = %v, want Add test for F first", acts)
+	}
+	for _, act := range acts {
+		if !strings.HasPrefix(act.Kind, "source.generate.test") {
+			t.Errorf("CodeActions(source.addTest) includes %q of kind %s", act.Title, act.Kind)
+		}
+	}
+	edits, err = w.Edits(ctx, acts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Apply(ctx, edits); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "a/a_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.Contains(got, "func TestParse(") || !strings.Contains(got, "func TestF(") {
+		t.Errorf("after Edits, a/a_test.go lacks a test:\n%s", got)
+	}
+
+	// An action that does not edit files.
+	acts, err = w.CodeActions(ctx, loc, "source.doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acts) != 1 {
+		t.Fatalf("CodeActions(source.doc) = %v, want one action", acts)
+	}
+	if _, err := w.Edits(ctx, acts[0]); err == nil {
+		t.Errorf("Edits(%q) succeeded unexpectedly", acts[0].Title)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports_test
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"golang.org/x/tools/imports"
+)
+
+func ExampleImportEdits() {
+	src := []byte(`package gen
+
+import "os"
+
+func f() { fmt.Println(os.Args, b.B) }
+`)
+	edits, err := imports.ImportEdits(src, "example.com", []imports.Import{
+		{Path: "example.com/b"},
+		{Path: "fmt"},
+	}, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Apply the edits, from last to first.
+	for _, edit := range slices.Backward(edits) {
+		src = slices.Concat(src[:edit.Start], []byte(edit.New), src[edit.End:])
+	}
+	fmt.Print(string(src))
+
+	// Output:
+	// package gen
+	//
+	// import (
+	// 	"fmt"
+	// 	"os"
+	//
+	// 	"example.com/b"
+	// )
+	//
+	// func f() { fmt.Println(os.Args, b.B) }
+}
@@ -32,6 +32,12 @@ var Debug = false
 // LocalPrefix is a comma-separated string of import path prefixes, which, if
 // set, instructs Process to sort the import paths with the given prefixes
 // into another group after 3rd-party packages.
+//
+// Several such lists, separated by semicolons, define several groups,
+// in that order. An import path belongs to the group of its longest
+// matching prefix. For example, "example.com;example.com/mod" puts
+// the imports of module example.com/mod after those of the rest of
+// example.com.
 var LocalPrefix string
 
 // Process formats and adjusts imports for the provided file.
@@ -75,3 +81,63 @@ func Process(filename string, src []byte, opt *Options) ([]byte, error) {
 func VendorlessPath(ipath string) string {
 	return intimp.VendorlessPath(ipath)
 }
+
+// An Import describes an import of a package.
+type Import struct {
+	Path string // import path, e.g. "crypto/rand"
+	Name string // import name, e.g. "crand", or "" if none
+}
+
+// An Edit is the replacement of the bytes src[Start:End] of a file's
+// content by New.
+type Edit struct {
+	Start, End int
+	New        string
+}
+
+// ImportEdits returns the edits to src, the content of a Go file, that
+// add the imports of add and delete those of del. The imports are
+// merged into a single declaration if possible, sorted, and grouped as
+// by [Process], with the imports of paths that have one of the
+// prefixes of localPrefix in groups after those of third-party
+// packages, as described at [LocalPrefix]. An import of del is deleted
+// only if it has the same name.
+//
+// Unlike Process, ImportEdits neither formats the rest of the file nor
+// consults the file system, so it is suitable for code generators that
+// know the imports their code requires. The edits are confined to the
+// file's package clause and import declarations, and the comments
+// among them. They are sorted and do not overlap, and may be applied
+// by replacing each range of src, from last to first.
+func ImportEdits(src []byte, localPrefix string, add, del []Import) ([]Edit, error) {
+	var fixes []*intimp.ImportFix
+	for _, imp := range del {
+		fixes = append(fixes, &intimp.ImportFix{
+			StmtInfo: intimp.ImportInfo{ImportPath: imp.Path, Name: imp.Name},
+			FixType:  intimp.DeleteImport,
+		})
+	}
+	for _, imp := range add {
+		fixes = append(fixes, &intimp.ImportFix{
+			StmtInfo: intimp.ImportInfo{ImportPath: imp.Path, Name: imp.Name},
+			FixType:  intimp.AddImport,
+		})
+	}
+	opt := &intimp.Options{
+		LocalPrefix: localPrefix,
+		AllErrors:   true,
+		Comments:    true,
+		Fragment:    true,
+		TabIndent:   true,
+		TabWidth:    8,
+	}
+	diffs, err := intimp.ComputeFixEdits(src, opt, fixes)
+	if err != nil {
+		return nil, err
+	}
+	edits := make([]Edit, len(diffs))
+	for i, d := range diffs {
+		edits[i] = Edit{Start: d.Start, End: d.End, New: d.New}
+	}
+	return edits, nil
+}
@@ -387,18 +387,18 @@ func ValidateFixes(fset *token.FileSet, a *analysis.Analyzer, fixes []analysis.S
 			return fmt.Errorf("analyzer %q suggests two fixes with same Message (%s)", a.Name, fix.Message)
 		}
 		fixMessages[fix.Message] = true
-		if err := validateFix(fset, fix); err != nil {
+		if err := ValidateFix(fset, fix); err != nil {
 			return fmt.Errorf("analyzer %q suggests invalid fix (%s): %v", a.Name, fix.Message, err)
 		}
 	}
 	return nil
 }
 
-// validateFix validates a single fix.
+// ValidateFix validates a single fix.
 // Any error indicates a bug in the originating analyzer.
 //
 // It updates fix so that fix.End.IsValid().
-func validateFix(fset *token.FileSet, fix *analysis.SuggestedFix) error {
+func ValidateFix(fset *token.FileSet, fix *analysis.SuggestedFix) error {
 
 	// Stably sort edits by Pos. This ordering puts insertions
 	// (end = start) before deletions (end > start) at the same
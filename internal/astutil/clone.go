@@ -6,6 +6,7 @@ package astutil
 
 import (
 	"go/ast"
+	"go/token"
 	"reflect"
 )
 
@@ -69,3 +70,45 @@ func cloneNode(n ast.Node) ast.Node {
 	}
 	return clone(reflect.ValueOf(n)).Interface().(ast.Node)
 }
+
+// ClearPositions destroys token.Pos information within the tree rooted at root,
+// as positions in trees formatted as part of another file, such as
+// callee trees in the inliner or syntax parsed from a string, may cause
+// the comments of that file to be emitted prematurely.
+//
+// In general it isn't safe to clear a valid Pos because some of them
+// (e.g. CallExpr.Ellipsis, TypeSpec.Assign) are significant to
+// go/printer, so this function sets each non-zero Pos to 1, which
+// suffices to avoid advancing the printer's comment cursor.
+//
+// This function mutates its argument; do not invoke on the syntax of
+// the file into which the tree is formatted.
+//
+// TODO(adonovan): remove this horrendous workaround when #20744 is finally fixed.
+func ClearPositions(root ast.Node) {
+	posType := reflect.TypeOf(token.NoPos)
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n != nil {
+			v := reflect.ValueOf(n).Elem() // deref the pointer to struct
+			fields := v.Type().NumField()
+			for i := 0; i < fields; i++ {
+				f := v.Field(i)
+				// Clearing Pos arbitrarily is destructive,
+				// as its presence may be semantically significant
+				// (e.g. CallExpr.Ellipsis, TypeSpec.Assign)
+				// or affect formatting preferences (e.g. GenDecl.Lparen).
+				//
+				// Note: for proper formatting, it may be necessary to be selective
+				// about which positions we set to 1 vs which we set to token.NoPos.
+				// (e.g. we can set most to token.NoPos, save the few that are
+				// significant).
+				if f.Type() == posType {
+					if f.Interface() != token.NoPos {
+						f.Set(reflect.ValueOf(token.Pos(1)))
+					}
+				}
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"text/scanner"
+
+	"golang.org/x/tools/internal/diff"
+)
+
+// ComputeFixEdits returns the edits to src, the content of a Go file,
+// that apply the fixes and format the resulting import declarations.
+// Unlike [ApplyFixes], it does not format the rest of the file: the
+// edits are confined to the prefix of the file through its last import
+// declaration. The edits are sorted and do not overlap.
+func ComputeFixEdits(src []byte, opt *Options, fixes []*ImportFix) ([]diff.Edit, error) {
+	// trim the original data to match fixedData
+	left, err := importPrefix(src)
+	if err != nil {
+		return nil, err
+	}
+	extra := !strings.Contains(left, "\n") // one line may have more than imports
+	if extra {
+		left = string(src)
+	}
+	if len(left) > 0 && left[len(left)-1] != '\n' {
+		// Include the line terminator of src, if any, so that
+		// left remains a prefix of src.
+		if rest := src[len(left):]; bytes.HasPrefix(rest, []byte("\r\n")) {
+			left += "\r\n"
+		} else {
+			left += "\n"
+		}
+	}
+	// Apply the fixes and re-parse the file so that we can locate the
+	// new imports.
+	flags := parser.ImportsOnly
+	if extra {
+		// used all of origData above, use all of it here too
+		flags = 0
+	}
+	fixedData, err := ApplyFixes(fixes, "", src, opt, flags)
+	if err != nil {
+		return nil, err
+	}
+	if fixedData == nil || fixedData[len(fixedData)-1] != '\n' {
+		fixedData = append(fixedData, '\n') // ApplyFixes may miss the newline, go figure.
+	}
+	edits := diff.Strings(left, string(fixedData))
+
+	// If a newline was appended to the prefix of a file that lacks
+	// one, an edit may extend beyond the end of src; express it in
+	// terms of src.
+	for i := range edits {
+		edit := &edits[i]
+		if edit.Start > len(src) {
+			edit.New = left[len(src):edit.Start] + edit.New
+			edit.Start = len(src)
+		}
+		if edit.End > len(src) {
+			edit.End = len(src)
+		}
+	}
+	return edits, nil
+}
+
+// importPrefix returns the prefix of the given file content through the final
+// import statement. If there are no imports, the prefix is the package
+// statement and any comment groups below it.
+func importPrefix(src []byte) (string, error) {
+	fset := token.NewFileSet()
+	// do as little parsing as possible
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil { // This can happen if 'package' is misspelled
+		return "", fmt.Errorf("importPrefix: failed to parse: %s", err)
+	}
+	tok := fset.File(f.FileStart)
+
+	// offset returns the offset of pos within tok.
+	offset := func(pos token.Pos) (int, error) {
+		if !(tok.Base() <= int(pos) && int(pos) <= tok.Base()+tok.Size()) {
+			return -1, fmt.Errorf("importPrefix: position %d outside file [%d, %d]", pos, tok.Base(), tok.Base()+tok.Size())
+		}
+		return int(pos) - tok.Base(), nil
+	}
+	// line returns the line of pos, ignoring //line directives.
+	line := func(pos token.Pos) int {
+		return tok.PositionFor(pos, false).Line
+	}
+
+	var importEnd int
+	for _, d := range f.Decls {
+		if x, ok := d.(*ast.GenDecl); ok && x.Tok == token.IMPORT {
+			if e, err := offset(d.End()); err != nil {
+				return "", err
+			} else if e > importEnd {
+				importEnd = e
+			}
+		}
+	}
+
+	maybeAdjustToLineEnd := func(pos token.Pos, isCommentNode bool) int {
+		offset, err := offset(pos)
+		if err != nil {
+			return -1
+		}
+
+		// Don't go past the end of the file.
+		if offset > len(src) {
+			offset = len(src)
+		}
+		// The go/ast package does not account for different line endings, and
+		// specifically, in the text of a comment, it will strip out \r\n line
+		// endings in favor of \n. To account for these differences, we try to
+		// return a position on the next line whenever possible.
+		switch line := line(tok.Pos(offset)); {
+		case line < tok.LineCount():
+			nextLineOffset := tok.Offset(tok.LineStart(line + 1))
+			// If we found a position that is at the end of a line, move the
+			// offset to the start of the next line.
+			if offset+1 == nextLineOffset {
+				offset = nextLineOffset
+			}
+		case isCommentNode, offset+1 == tok.Size():
+			// If the last line of the file is a comment, or we are at the end
+			// of the file, the prefix is the entire file.
+			offset = len(src)
+		}
+		return offset
+	}
+	if importEnd == 0 {
+		pkgEnd := f.Name.End()
+		importEnd = maybeAdjustToLineEnd(pkgEnd, false)
+	}
+	for _, cgroup := range f.Comments {
+		for _, c := range cgroup.List {
+			if end, err := offset(c.End()); err != nil {
+				return "", err
+			} else if end > importEnd {
+				startLine := line(c.Pos())
+				endLine := line(c.End())
+
+				// Work around golang/go#41197 by checking if the comment might
+				// contain "\r", and if so, find the actual end position of the
+				// comment by scanning the content of the file.
+				startOffset, err := offset(c.Pos())
+				if err != nil {
+					return "", err
+				}
+				if startLine != endLine && bytes.Contains(src[startOffset:], []byte("\r")) {
+					if commentEnd := scanForCommentEnd(src[startOffset:]); commentEnd > 0 {
+						end = startOffset + commentEnd
+					}
+				}
+				importEnd = maybeAdjustToLineEnd(tok.Pos(end), true)
+			}
+		}
+	}
+	if importEnd > len(src) {
+		importEnd = len(src)
+	}
+	return string(src[:importEnd]), nil
+}
+
+// scanForCommentEnd returns the offset of the end of the multi-line comment
+// at the start of the given byte slice.
+func scanForCommentEnd(src []byte) int {
+	var s scanner.Scanner
+	s.Init(bytes.NewReader(src))
+	s.Mode ^= scanner.SkipComments
+
+	t := s.Scan()
+	if t == scanner.Comment {
+		return s.Pos().Offset
+	}
+	return 0
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imports
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/diff"
+)
+
+func TestImportPrefix(t *testing.T) {
+	for i, tt := range []struct {
+		input, want string
+	}{
+		{"package foo", "package foo"},
+		{"package foo\n", "package foo\n"},
+		{"package foo\n\nfunc f(){}\n", "package foo\n"},
+		{"package foo\n\nimport \"fmt\"\n", "package foo\n\nimport \"fmt\""},
+		{"package foo\nimport (\n\"fmt\"\n)\n", "package foo\nimport (\n\"fmt\"\n)"},
+		{"\n\n\npackage foo\n", "\n\n\npackage foo\n"},
+		{"// hi \n\npackage foo //xx\nfunc _(){}\n", "// hi \n\npackage foo //xx\n"},
+		{"package foo //hi\n", "package foo //hi\n"},
+		{"//hi\npackage foo\n//a\n\n//b\n", "//hi\npackage foo\n//a\n\n//b\n"},
+		{
+			"package a\n\nimport (\n  \"fmt\"\n)\n//hi\n",
+			"package a\n\nimport (\n  \"fmt\"\n)\n//hi\n",
+		},
+		{`package a /*hi*/`, `package a /*hi*/`},
+		{"package main\r\n\r\nimport \"go/types\"\r\n\r\n/*\r\n\r\n */\r\n", "package main\r\n\r\nimport \"go/types\"\r\n\r\n/*\r\n\r\n */\r\n"},
+		{"package x; import \"os\"; func f() {}\n\n", "package x; import \"os\""},
+		{"package x; func f() {fmt.Println()}\n\n", "package x"},
+	} {
+		got, err := importPrefix([]byte(tt.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%d: failed for %q:\n%s", i, tt.input, diff.Unified("want", "got", tt.want, got))
+		}
+	}
+}
+
+func TestCRLFFile(t *testing.T) {
+	for i, tt := range []struct {
+		input, want string
+	}{
+		{
+			input: `package main
+
+/*
+Hi description
+*/
+func Hi() {
+}
+`,
+			want: `package main
+
+/*
+Hi description
+*/`,
+		},
+	} {
+		got, err := importPrefix([]byte(strings.ReplaceAll(tt.input, "\n", "\r\n")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.ReplaceAll(tt.want, "\n", "\r\n")
+		if got != want {
+			t.Errorf("%d: failed for %q:\n%s", i, tt.input, diff.Unified("want", "got", want, got))
+		}
+	}
+}
+
+func TestComputeFixEdits(t *testing.T) {
+	opt := &Options{
+		LocalPrefix: "example.com",
+		AllErrors:   true,
+		Comments:    true,
+		Fragment:    true,
+		TabIndent:   true,
+		TabWidth:    8,
+	}
+	add := func(path string) *ImportFix {
+		return &ImportFix{StmtInfo: ImportInfo{ImportPath: path}, FixType: AddImport}
+	}
+	for i, tt := range []struct {
+		input string
+		fixes []*ImportFix
+		want  string
+	}{
+		{
+			// The rest of the file is not formatted.
+			"package a\n\nimport \"os\"\n\nvar  _ = os.Args\n",
+			[]*ImportFix{add("example.com/b"), add("fmt")},
+			"package a\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\n\t\"example.com/b\"\n)\n\nvar  _ = os.Args\n",
+		},
+		{
+			"package a\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n",
+			[]*ImportFix{{StmtInfo: ImportInfo{ImportPath: "fmt"}, FixType: DeleteImport}},
+			"package a\n\nimport (\n\t\"os\"\n)\n",
+		},
+		{
+			// No final newline.
+			"package a",
+			[]*ImportFix{add("fmt")},
+			"package a\n\nimport \"fmt\"\n",
+		},
+		{
+			// Windows line endings.
+			"package a\r\n\r\nimport \"os\"\r\n\r\nvar _ = os.Args\r\n",
+			[]*ImportFix{add("fmt")},
+			"package a\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\r\nvar _ = os.Args\r\n",
+		},
+		{
+			// Line directives do not affect the prefix.
+			"//line a.y:102\npackage a\n\nimport \"os\"\n\n//comment\nvar _ = os.Args\n",
+			nil,
+			"//line a.y:102\npackage a\n\nimport \"os\"\n\n//comment\nvar _ = os.Args\n",
+		},
+	} {
+		edits, err := ComputeFixEdits([]byte(tt.input), opt, tt.fixes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := diff.Apply(tt.input, edits)
+		if err != nil {
+			t.Fatalf("%d: invalid edits %v: %v", i, edits, err)
+		}
+		if got != tt.want {
+			t.Errorf("%d: failed for %q:\n%s", i, tt.input, diff.Unified("want", "got", tt.want, got))
+		}
+	}
+}
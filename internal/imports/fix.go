@@ -41,9 +41,19 @@ var importToGroup = []func(localPrefix, importPath string) (num int, ok bool){
 		if localPrefix == "" {
 			return
 		}
-		for _, p := range strings.Split(localPrefix, ",") {
-			if strings.HasPrefix(importPath, p) || strings.TrimSuffix(p, "/") == importPath {
-				return 3, true
+		// Each semicolon-separated group of comma-separated prefixes
+		// gets its own group number, in order. The longest matching
+		// prefix determines the group.
+		longest := -1
+		for i, group := range strings.Split(localPrefix, ";") {
+			for _, p := range strings.Split(group, ",") {
+				if p == "" || len(p) <= longest {
+					continue
+				}
+				if strings.HasPrefix(importPath, p) || strings.TrimSuffix(p, "/") == importPath {
+					longest = len(p)
+					num, ok = 3+i, true
+				}
 			}
 		}
 		return
@@ -2058,6 +2058,50 @@ const _ = runtime.GOOS
 	}
 }
 
+// Tests that semicolons in the LocalPrefix option separate
+// further groups, and that the longest matching prefix wins.
+func TestLocalPrefixGroups(t *testing.T) {
+	const src = `package main
+
+import (
+	"example.com/mod/a"
+	"example.com/other"
+	"fmt"
+	"github.com/x/y"
+	"example.com/mod"
+	"example.org/z"
+)
+`
+	const want = `package main
+
+import (
+	"fmt"
+
+	"github.com/x/y"
+
+	"example.com/other"
+	"example.org/z"
+
+	"example.com/mod"
+	"example.com/mod/a"
+)
+`
+	options := &Options{
+		LocalPrefix: "example.com,example.org;example.com/mod",
+		TabWidth:    8,
+		TabIndent:   true,
+		Comments:    true,
+		FormatOnly:  true,
+	}
+	got, err := Process("main.go", []byte(src), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("Process with LocalPrefix %q:\ngot:\n%s\nwant:\n%s", options.LocalPrefix, got, want)
+	}
+}
+
 // Tests that "package documentation" files are ignored.
 func TestIgnoreDocumentationPackage(t *testing.T) {
 	const input = `package x
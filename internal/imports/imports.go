@@ -32,6 +32,12 @@ type Options struct {
 	// LocalPrefix is a comma-separated string of import path prefixes, which, if
 	// set, instructs Process to sort the import paths with the given prefixes
 	// into another group after 3rd-party packages.
+	//
+	// Several such lists, separated by semicolons, define several groups,
+	// in that order. An import path belongs to the group of its longest
+	// matching prefix. For example, "example.com;example.com/mod" puts
+	// the imports of module example.com/mod after those of the rest of
+	// example.com.
 	LocalPrefix string
 
 	Fragment  bool // Accept fragment of a source file (no package statement)
@@ -1101,7 +1101,7 @@ func (st *state) inlineCall() (*inlineCallResult, error) {
 		if stmt, ok := parent.(*ast.ExprStmt); ok &&
 			(!needBindingDecl || bindingDecl != nil) {
 			logf("strategy: reduce stmt-context call to { return exprs }")
-			clearPositions(calleeDecl.Body)
+			internalastutil.ClearPositions(calleeDecl.Body)
 
 			if callee.ValidForCallStmt {
 				logf("callee body is valid as statement")
@@ -1160,7 +1160,7 @@ func (st *state) inlineCall() (*inlineCallResult, error) {
 			if newStmts, ok := st.assignStmts(stmt, results, importName); ok {
 				logf("strategy: reduce assign-context call to { return exprs }")
 
-				clearPositions(calleeDecl.Body)
+				internalastutil.ClearPositions(calleeDecl.Body)
 
 				block := &ast.BlockStmt{
 					List: newStmts,
@@ -1181,7 +1181,7 @@ func (st *state) inlineCall() (*inlineCallResult, error) {
 
 		// expression context
 		if !needBindingDecl {
-			clearPositions(calleeDecl.Body)
+			internalastutil.ClearPositions(calleeDecl.Body)
 
 			anyNonTrivialReturns := hasNonTrivialReturn(callee.Returns)
 
@@ -1280,7 +1280,7 @@ func (st *state) inlineCall() (*inlineCallResult, error) {
 		allResultsUnreferenced {
 		logf("strategy: reduce tail-call")
 		body := calleeDecl.Body
-		clearPositions(body)
+		internalastutil.ClearPositions(body)
 		if needBindingDecl {
 			body.List = prepend(bindingDecl.stmt, body.List...)
 		}
@@ -1313,7 +1313,7 @@ func (st *state) inlineCall() (*inlineCallResult, error) {
 		logf("strategy: reduce stmt-context call to { stmts }")
 		body := calleeDecl.Body
 		var repl ast.Stmt = body
-		clearPositions(repl)
+		internalastutil.ClearPositions(repl)
 		if needBindingDecl {
 			body.List = prepend(bindingDecl.stmt, body.List...)
 		}
@@ -1363,7 +1363,7 @@ func (st *state) inlineCall() (*inlineCallResult, error) {
 	// clear positions before prepending the binding decl below, since the
 	// binding decl contains syntax from the caller and we must not mutate the
 	// caller. (This was a prior bug.)
-	clearPositions(funcLit)
+	internalastutil.ClearPositions(funcLit)
 
 	// Literalization can still make use of a binding
 	// decl as it gives a more natural reading order:
@@ -3064,7 +3064,7 @@ func replaceNode(root ast.Node, from, to ast.Node) {
 // file set.
 func cleanNode[T ast.Node](node T) T {
 	clone := internalastutil.CloneNode(node)
-	clearPositions(clone)
+	internalastutil.ClearPositions(clone)
 	return clone
 }
 
@@ -3076,45 +3076,6 @@ func cleanNodes[T ast.Node](nodes []T) []T {
 	return clean
 }
 
-// clearPositions destroys token.Pos information within the tree rooted at root,
-// as positions in callee trees may cause caller comments to be emitted prematurely.
-//
-// In general it isn't safe to clear a valid Pos because some of them
-// (e.g. CallExpr.Ellipsis, TypeSpec.Assign) are significant to
-// go/printer, so this function sets each non-zero Pos to 1, which
-// suffices to avoid advancing the printer's comment cursor.
-//
-// This function mutates its argument; do not invoke on caller syntax.
-//
-// TODO(adonovan): remove this horrendous workaround when #20744 is finally fixed.
-func clearPositions(root ast.Node) {
-	posType := reflect.TypeOf(token.NoPos)
-	ast.Inspect(root, func(n ast.Node) bool {
-		if n != nil {
-			v := reflect.ValueOf(n).Elem() // deref the pointer to struct
-			fields := v.Type().NumField()
-			for i := 0; i < fields; i++ {
-				f := v.Field(i)
-				// Clearing Pos arbitrarily is destructive,
-				// as its presence may be semantically significant
-				// (e.g. CallExpr.Ellipsis, TypeSpec.Assign)
-				// or affect formatting preferences (e.g. GenDecl.Lparen).
-				//
-				// Note: for proper formatting, it may be necessary to be selective
-				// about which positions we set to 1 vs which we set to token.NoPos.
-				// (e.g. we can set most to token.NoPos, save the few that are
-				// significant).
-				if f.Type() == posType {
-					if f.Interface() != token.NoPos {
-						f.Set(reflect.ValueOf(token.Pos(1)))
-					}
-				}
-			}
-		}
-		return true
-	})
-}
-
 // findIdent finds the Ident beneath root that has the given pos.
 // It returns the path to the ident (excluding the ident), and the ident
 // itself, where the path is the sequence of ast.Nodes encountered in a
@@ -3433,7 +3394,7 @@ func (st *state) assignStmts(callerStmt *ast.AssignStmt, returnOperands []ast.Ex
 		} else {
 			// We must clone before clearing positions, since e came from the caller.
 			expr = internalastutil.CloneNode(expr)
-			clearPositions(expr)
+			internalastutil.ClearPositions(expr)
 			freeishNames(freeNames, expr)
 			rhs = append(rhs, expr)
 		}
@@ -10,6 +10,8 @@ import (
 	"go/token"
 	"go/types"
 	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
 )
 
 // ZeroString returns the string representation of the zero value for any type t.
@@ -30,65 +32,12 @@ import (
 // Exception: This does not apply to tuples. Their string representation is
 // informational only and cannot be used in an assignment.
 //
+// Apart from tuples, ZeroString is [typeutil.ZeroValue] with the
+// qualifier qual and no package: every type is assumed accessible.
+//
 // See [ZeroExpr] for a variant that returns an [ast.Expr].
 func ZeroString(t types.Type, qual types.Qualifier) (_ string, isValid bool) {
-	switch t := t.(type) {
-	case *types.Basic:
-		switch {
-		case t.Info()&types.IsBoolean != 0:
-			return "false", true
-		case t.Info()&types.IsNumeric != 0:
-			return "0", true
-		case t.Info()&types.IsString != 0:
-			return `""`, true
-		case t.Kind() == types.UnsafePointer:
-			fallthrough
-		case t.Kind() == types.UntypedNil:
-			return "nil", true
-		case t.Kind() == types.Invalid:
-			return "invalid", false
-		default:
-			panic(fmt.Sprintf("ZeroString for unexpected type %v", t))
-		}
-
-	case *types.Pointer, *types.Slice, *types.Chan, *types.Map, *types.Signature:
-		return "nil", true
-
-	case *types.Interface:
-		if !t.IsMethodSet() {
-			return "invalid", false
-		}
-		return "nil", true
-
-	case *types.Named:
-		switch under := t.Underlying().(type) {
-		case *types.Struct, *types.Array:
-			return types.TypeString(t, qual) + "{}", true
-		default:
-			return ZeroString(under, qual)
-		}
-
-	case *types.Alias:
-		switch t.Underlying().(type) {
-		case *types.Struct, *types.Array:
-			return types.TypeString(t, qual) + "{}", true
-		default:
-			// A type parameter can have alias but alias type's underlying type
-			// can never be a type parameter.
-			// Use types.Unalias to preserve the info of type parameter instead
-			// of call Underlying() going right through and get the underlying
-			// type of the type parameter which is always an interface.
-			return ZeroString(types.Unalias(t), qual)
-		}
-
-	case *types.Array, *types.Struct:
-		return types.TypeString(t, qual) + "{}", true
-
-	case *types.TypeParam:
-		// Assumes func new is not shadowed.
-		return "*new(" + types.TypeString(t, qual) + ")", true
-
-	case *types.Tuple:
+	if t, ok := t.(*types.Tuple); ok {
 		// Tuples are not normal values.
 		// We are currently format as "(t[0], ..., t[n])". Could be something else.
 		isValid := true
@@ -100,15 +49,8 @@ func ZeroString(t types.Type, qual types.Qualifier) (_ string, isValid bool) {
 			isValid = isValid && ok
 		}
 		return "(" + strings.Join(components, ", ") + ")", isValid
-
-	case *types.Union:
-		// Variables of these types cannot be created, so it makes
-		// no sense to ask for their zero value.
-		panic(fmt.Sprintf("invalid type for a variable: %v", t))
-
-	default:
-		panic(t) // unreachable.
 	}
+	return typeutil.ZeroValue(t, &typeutil.ValueConfig{Qualifier: qual})
 }
 
 // ZeroExpr returns the ast.Expr representation of the zero value for any type t.
@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Extract writes the files of the archive a to the directory dir,
+// creating dir and any subdirectories as needed, and replacing any
+// existing files of the same names. It returns an error if any of the
+// file names in the archive are not valid file system names (see
+// [fs.ValidPath]).
+//
+// The format has no notion of file modes, so files are created with
+// mode 0o666, and directories with mode 0o777, before the umask. The
+// files whose archive names are listed in exec are instead created
+// with mode 0o777, that is, executable.
+func Extract(a *Archive, dir string, exec ...string) error {
+	for _, f := range a.Files {
+		if !fs.ValidPath(f.Name) {
+			return fmt.Errorf("cannot extract file %q: invalid path", f.Name)
+		}
+	}
+	for _, name := range exec {
+		if !slices.ContainsFunc(a.Files, func(f File) bool { return f.Name == name }) {
+			return fmt.Errorf("cannot make %q executable: no such file in archive", name)
+		}
+	}
+	for _, f := range a.Files {
+		filename := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o777); err != nil {
+			return err
+		}
+		perm := fs.FileMode(0o666)
+		if slices.Contains(exec, f.Name) {
+			perm = 0o777
+		}
+		// WriteFile does not change the mode of an existing file.
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.WriteFile(filename, f.Data, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FromDir returns an archive of the regular files in the tree rooted
+// at dir, in the order visited by [filepath.WalkDir]. Their names are
+// slash-separated and relative to dir. Empty directories are not
+// recorded. FromDir returns an error if the tree contains other kinds
+// of files, such as symbolic links.
+//
+// FromDir also returns the names of the files that are executable by
+// their owner, so that Extract(a, dir2, exec...) recreates the tree in
+// dir2. (On Windows, which has no execute permission bits, the list is
+// empty.)
+//
+// The archive is not necessarily well-formed in the sense of [Format]:
+// the serialized form does not round-trip files that lack a final
+// newline or whose content contains file marker lines.
+func FromDir(dir string) (a *Archive, exec []string, err error) {
+	a = new(Archive)
+	err = filepath.WalkDir(dir, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("cannot archive %q: not a regular file", name)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		a.Files = append(a.Files, File{Name: name, Data: data})
+		if info.Mode().Perm()&0o100 != 0 {
+			exec = append(exec, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, exec, nil
+}
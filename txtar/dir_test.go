@@ -0,0 +1,76 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtar_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"golang.org/x/tools/txtar"
+)
+
+func TestExtractFromDir(t *testing.T) {
+	a := txtar.Parse([]byte(`comment
+-- a.txt --
+a
+-- b/c/d.txt --
+d
+-- b/run.sh --
+#!/bin/sh
+`))
+	dir := t.TempDir()
+
+	// Extract replaces existing files, including their modes.
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("old"), 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := txtar.Extract(a, dir, "b/run.sh"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "b", "c", "d.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "d\n" {
+		t.Errorf("b/c/d.txt = %q, want %q", data, "d\n")
+	}
+
+	got, exec, err := txtar.FromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&txtar.Archive{Files: a.Files}); !reflect.DeepEqual(got, want) {
+		t.Errorf("FromDir(Extract(a)) = %q, want %q", txtar.Format(got), txtar.Format(want))
+	}
+	wantExec := []string{"b/run.sh"}
+	if runtime.GOOS == "windows" {
+		wantExec = nil
+	}
+	if !reflect.DeepEqual(exec, wantExec) {
+		t.Errorf("FromDir returned executables %q, want %q", exec, wantExec)
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		a    *txtar.Archive
+		exec []string
+	}{
+		{"invalid path", &txtar.Archive{Files: []txtar.File{{Name: "../a.txt"}}}, nil},
+		{"missing exec", &txtar.Archive{Files: []txtar.File{{Name: "a.txt"}}}, []string{"b.sh"}},
+	} {
+		dir := t.TempDir()
+		if err := txtar.Extract(test.a, dir, test.exec...); err == nil {
+			t.Errorf("%s: Extract succeeded unexpectedly", test.name)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+			t.Errorf("%s: Extract wrote files despite error", test.name)
+		}
+	}
+}